@@ -0,0 +1,132 @@
+// Package cluster coordinates prefix cache advertisements across a
+// fleet of servers behind a shared load balancer. Each server hashes
+// the head of its long cached prompt prefixes and pushes the digest
+// list to its peers; the balancer can then use the routing hint header
+// to sticky-route requests to a node that already has the prefix warm.
+package cluster
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMinAdvertiseTokens is the shortest prefix worth advertising;
+// anything shorter is recomputed faster than a cross-node redirect
+const DefaultMinAdvertiseTokens = 32
+
+// DefaultAdvertisementTTL bounds how long a peer's advertisement is
+// trusted without a refresh
+const DefaultAdvertisementTTL = 30 * time.Second
+
+// Advertisement is one server's view of its own cached prefixes
+type Advertisement struct {
+	NodeID    string   `json:"node_id"`
+	Addr      string   `json:"addr,omitempty"`
+	Prefixes  []string `json:"prefixes"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+// HashPrefix digests the first DefaultMinAdvertiseTokens tokens of a
+// prefix so advertisements stay small and comparisons stay exact.
+// Returns "" for prefixes too short to advertise.
+func HashPrefix(tokens []uint32) string {
+	if len(tokens) < DefaultMinAdvertiseTokens {
+		return ""
+	}
+
+	h := fnv.New64a()
+	var buf [4]byte
+	for _, token := range tokens[:DefaultMinAdvertiseTokens] {
+		binary.LittleEndian.PutUint32(buf[:], token)
+		h.Write(buf[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Registry tracks which peers have which prefixes cached; safe for
+// concurrent use
+type Registry struct {
+	mu    sync.RWMutex
+	ttl   time.Duration
+	peers map[string]Advertisement
+}
+
+// NewRegistry creates a registry; ttl <= 0 uses the default
+func NewRegistry(ttl time.Duration) *Registry {
+	if ttl <= 0 {
+		ttl = DefaultAdvertisementTTL
+	}
+	return &Registry{
+		ttl:   ttl,
+		peers: make(map[string]Advertisement),
+	}
+}
+
+// Update replaces a peer's advertisement, stamping it when unset
+func (r *Registry) Update(ad Advertisement) {
+	if ad.NodeID == "" {
+		return
+	}
+	if ad.UpdatedAt == 0 {
+		ad.UpdatedAt = time.Now().Unix()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.peers[ad.NodeID] = ad
+}
+
+// Remove drops a peer, e.g. when it leaves the fleet
+func (r *Registry) Remove(nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.peers, nodeID)
+}
+
+// Locate returns the IDs of peers advertising the prefix hash, sorted
+// for deterministic routing hints. Expired advertisements are skipped.
+func (r *Registry) Locate(hash string) []string {
+	if hash == "" {
+		return nil
+	}
+	cutoff := time.Now().Add(-r.ttl).Unix()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var nodes []string
+	for id, ad := range r.peers {
+		if ad.UpdatedAt < cutoff {
+			continue
+		}
+		for _, p := range ad.Prefixes {
+			if p == hash {
+				nodes = append(nodes, id)
+				break
+			}
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Snapshot returns all live advertisements, sorted by node ID
+func (r *Registry) Snapshot() []Advertisement {
+	cutoff := time.Now().Add(-r.ttl).Unix()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ads := make([]Advertisement, 0, len(r.peers))
+	for _, ad := range r.peers {
+		if ad.UpdatedAt >= cutoff {
+			ads = append(ads, ad)
+		}
+	}
+	sort.Slice(ads, func(i, j int) bool { return ads[i].NodeID < ads[j].NodeID })
+	return ads
+}
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func longPrefix(seed uint32) []uint32 {
+	tokens := make([]uint32, DefaultMinAdvertiseTokens)
+	for i := range tokens {
+		tokens[i] = seed + uint32(i)
+	}
+	return tokens
+}
+
+func TestHashPrefix(t *testing.T) {
+	a := longPrefix(100)
+	b := longPrefix(200)
+
+	if HashPrefix(a) == "" || HashPrefix(b) == "" {
+		t.Fatal("HashPrefix returned empty hash for long prefix")
+	}
+	if HashPrefix(a) != HashPrefix(a) {
+		t.Error("HashPrefix is not deterministic")
+	}
+	if HashPrefix(a) == HashPrefix(b) {
+		t.Error("HashPrefix collided for distinct prefixes")
+	}
+
+	// Only the head is hashed: extending past the advertised window
+	// must not change the digest
+	extended := append(longPrefix(100), 999, 998)
+	if HashPrefix(extended) != HashPrefix(a) {
+		t.Error("HashPrefix changed when tokens past the window differ")
+	}
+}
+
+func TestHashPrefixTooShort(t *testing.T) {
+	short := make([]uint32, DefaultMinAdvertiseTokens-1)
+	if got := HashPrefix(short); got != "" {
+		t.Errorf("HashPrefix(short) = %q, want empty", got)
+	}
+}
+
+func TestRegistryLocate(t *testing.T) {
+	r := NewRegistry(0)
+	hash := HashPrefix(longPrefix(100))
+
+	r.Update(Advertisement{NodeID: "mac-2", Prefixes: []string{hash}})
+	r.Update(Advertisement{NodeID: "mac-1", Prefixes: []string{hash, "other"}})
+	r.Update(Advertisement{NodeID: "mac-3", Prefixes: []string{"unrelated"}})
+
+	got := r.Locate(hash)
+	if len(got) != 2 || got[0] != "mac-1" || got[1] != "mac-2" {
+		t.Errorf("Locate = %v, want [mac-1 mac-2]", got)
+	}
+	if r.Locate("") != nil {
+		t.Error("Locate(\"\") should return nil")
+	}
+	if r.Locate("missing") != nil {
+		t.Error("Locate(missing) should return nil")
+	}
+}
+
+func TestRegistryExpiry(t *testing.T) {
+	r := NewRegistry(time.Second)
+	hash := HashPrefix(longPrefix(100))
+
+	r.Update(Advertisement{
+		NodeID:    "mac-stale",
+		Prefixes:  []string{hash},
+		UpdatedAt: time.Now().Add(-time.Minute).Unix(),
+	})
+	r.Update(Advertisement{NodeID: "mac-fresh", Prefixes: []string{hash}})
+
+	got := r.Locate(hash)
+	if len(got) != 1 || got[0] != "mac-fresh" {
+		t.Errorf("Locate = %v, want [mac-fresh]", got)
+	}
+	if ads := r.Snapshot(); len(ads) != 1 || ads[0].NodeID != "mac-fresh" {
+		t.Errorf("Snapshot = %v, want only mac-fresh", ads)
+	}
+}
+
+func TestRegistryUpdateAndRemove(t *testing.T) {
+	r := NewRegistry(0)
+
+	// Missing node ID is dropped rather than stored under ""
+	r.Update(Advertisement{Prefixes: []string{"x"}})
+	if len(r.Snapshot()) != 0 {
+		t.Error("Update stored an advertisement without a node ID")
+	}
+
+	r.Update(Advertisement{NodeID: "mac-1", Prefixes: []string{"x"}})
+	r.Update(Advertisement{NodeID: "mac-1", Prefixes: []string{"y"}})
+	ads := r.Snapshot()
+	if len(ads) != 1 || len(ads[0].Prefixes) != 1 || ads[0].Prefixes[0] != "y" {
+		t.Errorf("Snapshot after re-advertise = %v, want single [y]", ads)
+	}
+
+	r.Remove("mac-1")
+	if len(r.Snapshot()) != 0 {
+		t.Error("Snapshot still lists a removed peer")
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+)
+
+// CacheDebugHeader opts a request into cache decision reporting: when
+// set, the response carries CacheDecisionHeader with the JSON decision
+const CacheDebugHeader = "X-Cache-Debug"
+
+// CacheDecisionHeader carries the per-request cache decision as JSON
+const CacheDecisionHeader = "X-Cache-Decision"
+
+// CacheDecision describes how RadixAttention served one request: what
+// prefix was reused, whether the request blocked on a pending node,
+// and how many new tokens were inserted into the tree
+type CacheDecision struct {
+	// MatchedTokens is the length of the reused cached prefix
+	MatchedTokens int `json:"matched_tokens"`
+
+	// NodeDepth is the depth of the matched node (0 = no match)
+	NodeDepth int `json:"node_depth"`
+
+	// WaitedPending reports whether the matched node was still being
+	// computed when found; WaitMs is how long the request blocked on it
+	WaitedPending bool    `json:"waited_pending"`
+	WaitMs        float64 `json:"wait_ms"`
+
+	// SpillRestored reports a miss in the hot tree that was served by
+	// re-importing an evicted cache from the spill tier
+	SpillRestored bool `json:"spill_restored"`
+
+	// InsertedTokens is the number of new tokens inserted as a pending
+	// node for future reuse
+	InsertedTokens int `json:"inserted_tokens"`
+
+	// CacheMode is the request's cache override ("" = full caching)
+	CacheMode string `json:"cache_mode,omitempty"`
+
+	// Pinned reports that this request's prefix was made resident
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// observeMatch fills the match-side fields from the node returned by
+// Tree.Match (nil = full miss)
+func (d *CacheDecision) observeMatch(node *radix.Node) {
+	for n := node; n != nil && n.Parent != nil; n = n.Parent {
+		d.MatchedTokens += len(n.Tokens)
+		d.NodeDepth++
+	}
+}
+
+// observeWait records a block on a pending node
+func (d *CacheDecision) observeWait(elapsed time.Duration) {
+	d.WaitedPending = true
+	d.WaitMs = float64(elapsed) / float64(time.Millisecond)
+}
+
+// log emits the decision as a debug-level structured log line
+func (d *CacheDecision) log() {
+	slog.Debug("Cache decision",
+		"matched_tokens", d.MatchedTokens,
+		"node_depth", d.NodeDepth,
+		"waited_pending", d.WaitedPending,
+		"wait_ms", d.WaitMs,
+		"spill_restored", d.SpillRestored,
+		"inserted_tokens", d.InsertedTokens,
+		"cache_mode", d.CacheMode,
+		"pinned", d.Pinned,
+	)
+}
+
+// header renders the decision for the opt-in response header
+func (d *CacheDecision) header() string {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/agenthands/GUI-Actor/internal/cluster"
+)
+
+// PrefixCacheHintHeader carries the sorted node IDs that advertise the
+// request's prompt prefix, for sticky routing at the load balancer
+const PrefixCacheHintHeader = "X-Prefix-Cache-Nodes"
+
+// EnableCluster turns on distributed prefix cache advertisement for
+// this server under the given node ID
+func (s *Server) EnableCluster(reg *cluster.Registry, nodeID string) {
+	s.cluster = reg
+	s.nodeID = nodeID
+}
+
+// localAdvertisement builds this node's advertisement from the radix
+// tree and refreshes it in the registry
+func (s *Server) localAdvertisement() cluster.Advertisement {
+	var hashes []string
+	seen := make(map[string]struct{})
+	for _, prefix := range s.tree.CachedPrefixes(cluster.DefaultMinAdvertiseTokens) {
+		hash := cluster.HashPrefix(prefix)
+		if hash == "" {
+			continue
+		}
+		if _, dup := seen[hash]; dup {
+			continue
+		}
+		seen[hash] = struct{}{}
+		hashes = append(hashes, hash)
+	}
+
+	ad := cluster.Advertisement{NodeID: s.nodeID, Prefixes: hashes}
+	s.cluster.Update(ad)
+	return ad
+}
+
+// routingHint returns the comma-joined node IDs advertising the input
+// prefix, or "" when no peer has it cached
+func (s *Server) routingHint(inputTokens []uint32) string {
+	s.localAdvertisement()
+	nodes := s.cluster.Locate(cluster.HashPrefix(inputTokens))
+	hint := ""
+	for i, node := range nodes {
+		if i > 0 {
+			hint += ","
+		}
+		hint += node
+	}
+	return hint
+}
+
+// CachePrefixesHandler handles GET /v1/cache/prefixes
+// Returns this node's current advertisement so peers (or a poller) can
+// learn which prefixes it has cached
+func (s *Server) CachePrefixesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.cluster == nil {
+		http.Error(w, "Clustering not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	ad := s.localAdvertisement()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ad)
+}
+
+// CacheAdvertiseHandler handles POST /v1/cache/advertise
+// Accepts a peer's advertisement; peers push on their own schedule
+func (s *Server) CacheAdvertiseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.cluster == nil {
+		http.Error(w, "Clustering not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var ad cluster.Advertisement
+	if err := json.NewDecoder(r.Body).Decode(&ad); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid advertisement: %v", err), http.StatusBadRequest)
+		return
+	}
+	if ad.NodeID == "" {
+		http.Error(w, "Missing node_id", http.StatusBadRequest)
+		return
+	}
+
+	s.cluster.Update(ad)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
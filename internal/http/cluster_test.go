@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/GUI-Actor/internal/cluster"
+	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
+)
+
+func newClusterServer(t *testing.T, nodeID string) (*Server, *radix.Tree) {
+	t.Helper()
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	server.EnableCluster(cluster.NewRegistry(0), nodeID)
+	return server, tree
+}
+
+func cachePrefix(t *testing.T, tree *radix.Tree, seed uint32, length int) []uint32 {
+	t.Helper()
+	tokens := make([]uint32, length)
+	for i := range tokens {
+		tokens[i] = seed + uint32(i)
+	}
+	node, err := tree.InsertPending(tokens, &radix.MockMLXEngine{}, "test-model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	radix.FinalizeNode(node, 100)
+	tree.Unpin(node)
+	return tokens
+}
+
+func TestCachePrefixesHandler(t *testing.T) {
+	server, tree := newClusterServer(t, "mac-1")
+	cachePrefix(t, tree, 500, cluster.DefaultMinAdvertiseTokens+4)
+	cachePrefix(t, tree, 9000, 4) // too short to advertise
+
+	req := httptest.NewRequest("GET", "/v1/cache/prefixes", nil)
+	w := httptest.NewRecorder()
+	server.CachePrefixesHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var ad cluster.Advertisement
+	if err := json.NewDecoder(w.Body).Decode(&ad); err != nil {
+		t.Fatalf("Failed to decode advertisement: %v", err)
+	}
+	if ad.NodeID != "mac-1" {
+		t.Errorf("NodeID = %q, want mac-1", ad.NodeID)
+	}
+	if len(ad.Prefixes) != 1 {
+		t.Errorf("Advertised %d prefixes, want 1", len(ad.Prefixes))
+	}
+}
+
+func TestCachePrefixesDisabled(t *testing.T) {
+	tree := radix.NewTree()
+	server := NewServer(tree, &radix.MockMLXEngine{}, tokenizer.NewTokenizer(32000), "test-model")
+
+	w := httptest.NewRecorder()
+	server.CachePrefixesHandler(w, httptest.NewRequest("GET", "/v1/cache/prefixes", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestCacheAdvertiseHandler(t *testing.T) {
+	server, _ := newClusterServer(t, "mac-1")
+	prefix := make([]uint32, cluster.DefaultMinAdvertiseTokens)
+	for i := range prefix {
+		prefix[i] = 500 + uint32(i)
+	}
+	hash := cluster.HashPrefix(prefix)
+
+	body, _ := json.Marshal(cluster.Advertisement{NodeID: "mac-2", Prefixes: []string{hash}})
+	req := httptest.NewRequest("POST", "/v1/cache/advertise", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.CacheAdvertiseHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := server.cluster.Locate(hash); len(got) != 1 || got[0] != "mac-2" {
+		t.Errorf("Locate after advertise = %v, want [mac-2]", got)
+	}
+}
+
+func TestCacheAdvertiseValidation(t *testing.T) {
+	server, _ := newClusterServer(t, "mac-1")
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", "{", http.StatusBadRequest},
+		{"missing node id", `{"prefixes":["x"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/cache/advertise", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.CacheAdvertiseHandler(w, req)
+			if w.Code != tt.code {
+				t.Errorf("Expected status %d, got %d", tt.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestRoutingHintHeader(t *testing.T) {
+	server, _ := newClusterServer(t, "mac-1")
+
+	// A long user message tokenizes past the advertise threshold; peer
+	// mac-2 claims to have the same prefix cached
+	content := strings.Repeat("open settings and ", 8)
+	tokReq := &tokenizer.ChatRequest{
+		Messages: []tokenizer.ChatMessage{{Role: "user", Content: content}},
+	}
+	inputTokens, err := server.tokenizer.TokenizeChatRequest(tokReq)
+	if err != nil {
+		t.Fatalf("Tokenization failed: %v", err)
+	}
+	hash := cluster.HashPrefix(inputTokens)
+	if hash == "" {
+		t.Fatal("Test prompt too short to produce an advertisable prefix")
+	}
+	server.cluster.Update(cluster.Advertisement{NodeID: "mac-2", Prefixes: []string{hash}})
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Messages:  []tokenizer.ChatMessage{{Role: "user", Content: content}},
+		MaxTokens: 2,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ChatCompletionHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(PrefixCacheHintHeader); got != "mac-2" {
+		t.Errorf("%s = %q, want mac-2", PrefixCacheHintHeader, got)
+	}
+}
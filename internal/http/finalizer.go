@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+)
+
+// errDraining fails nodes whose finalization was refused during
+// shutdown
+var errDraining = errors.New("server draining, finalization refused")
+
+// DefaultFinalizerWorkers bounds how many background finalizations run
+// concurrently; each one holds an MLX forward pass
+const DefaultFinalizerWorkers = 8
+
+// finalizerPool supervises the background goroutines that finalize
+// pending nodes. Unsupervised `go` calls lost panics and left shutdown
+// unable to wait for in-flight forwards; the pool bounds concurrency,
+// converts panics into node poisoning so waiters fail fast instead of
+// hanging, and drains cleanly on shutdown.
+type finalizerPool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu       sync.Mutex
+	closed   bool
+	panicked int
+}
+
+func newFinalizerPool(workers int) *finalizerPool {
+	if workers <= 0 {
+		workers = DefaultFinalizerWorkers
+	}
+	return &finalizerPool{sem: make(chan struct{}, workers)}
+}
+
+// Submit schedules one finalization. Returns false once the pool is
+// draining, in which case the caller must fail the node itself so
+// waiters are not stranded.
+func (p *finalizerPool) Submit(node *radix.Node, fn func()) bool {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return false
+	}
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	go func() {
+		defer p.wg.Done()
+		p.sem <- struct{}{}
+		defer func() { <-p.sem }()
+		defer func() {
+			if r := recover(); r != nil {
+				p.mu.Lock()
+				p.panicked++
+				p.mu.Unlock()
+				slog.Error("Finalizer panicked", "tokens", len(node.Tokens), "panic", r)
+				// Poisoning is idempotent, so a panic after a successful
+				// finalize is harmless
+				radix.PoisonNode(node, fmt.Errorf("finalizer panic: %v", r))
+			}
+		}()
+		fn()
+	}()
+	return true
+}
+
+// Drain rejects new submissions and waits for in-flight
+// finalizations, bounded by the context
+func (p *finalizerPool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Panicked returns how many finalizations died by panic
+func (p *finalizerPool) Panicked() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.panicked
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/internal/vision"
+)
+
+// pendingNode inserts a pending node the pool can poison
+func pendingNode(t *testing.T, tree *radix.Tree) *radix.Node {
+	t.Helper()
+	engine := &radix.MockMLXEngine{}
+	node, created, err := tree.InsertOrJoin([]uint32{1, 3, 5}, engine, "test-model")
+	if err != nil || !created {
+		t.Fatalf("InsertOrJoin = (%v, %v, %v)", node, created, err)
+	}
+	return node
+}
+
+func TestFinalizerPoolRunsSubmissions(t *testing.T) {
+	pool := newFinalizerPool(2)
+	tree := radix.NewTree()
+	node := pendingNode(t, tree)
+
+	var ran atomic.Bool
+	if !pool.Submit(node, func() { ran.Store(true) }) {
+		t.Fatal("Submit refused before drain")
+	}
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if !ran.Load() {
+		t.Error("submitted work did not run")
+	}
+}
+
+func TestFinalizerPoolBoundsConcurrency(t *testing.T) {
+	pool := newFinalizerPool(2)
+	tree := radix.NewTree()
+
+	var running, peak int32
+	var mu sync.Mutex
+	block := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		// A fresh tree per submission so each insert creates a node
+		tree = radix.NewTree()
+		node := pendingNode(t, tree)
+		pool.Submit(node, func() {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			<-block
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(block)
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
+
+func TestFinalizerPoolPoisonsOnPanic(t *testing.T) {
+	pool := newFinalizerPool(1)
+	tree := radix.NewTree()
+	node := pendingNode(t, tree)
+
+	pool.Submit(node, func() { panic("metal exploded") })
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	if got := pool.Panicked(); got != 1 {
+		t.Errorf("Panicked = %d, want 1", got)
+	}
+	err := node.Wait()
+	if err == nil {
+		t.Fatal("panicked node not poisoned")
+	}
+	if !strings.Contains(err.Error(), "finalizer panic") {
+		t.Errorf("poison error = %v, want a finalizer panic", err)
+	}
+}
+
+func TestFinalizerPoolRefusesAfterDrain(t *testing.T) {
+	pool := newFinalizerPool(1)
+	tree := radix.NewTree()
+	node := pendingNode(t, tree)
+
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if pool.Submit(node, func() {}) {
+		t.Error("Submit accepted work after Drain")
+	}
+}
+
+func TestFinalizerPoolDrainTimeout(t *testing.T) {
+	pool := newFinalizerPool(1)
+	tree := radix.NewTree()
+	node := pendingNode(t, tree)
+
+	block := make(chan struct{})
+	defer close(block)
+	pool.Submit(node, func() { <-block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Drain(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Drain = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestServerDrainFailsRefusedNodes(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, nil, "test-model")
+
+	if err := server.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	// A generation after drain must not strand waiters on its node
+	tokens, _, err := server.generate(context.Background(), []uint32{7, 9, 11}, 0, false, CacheModeFull, vision.NoTicket)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if len(tokens) == 0 {
+		t.Fatal("generate returned no tokens")
+	}
+
+	// The refused node was poisoned, so a fresh insert skips it and
+	// creates anew; had it been left pending, we would have joined it
+	// and hung waiting on a finalization that never runs
+	node, created, err := tree.InsertOrJoin([]uint32{7, 9, 11}, engine, "test-model")
+	if err != nil {
+		t.Fatalf("InsertOrJoin failed: %v", err)
+	}
+	if !created {
+		t.Error("joined the refused node; it was not poisoned")
+	}
+	tree.Unpin(node)
+}
+
+func TestFinalizeNodeFreesOrphanedHandle(t *testing.T) {
+	tree := radix.NewTree()
+	var freed []uint64
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 42, nil
+		},
+		FreeFunc: func(handle uint64) { freed = append(freed, handle) },
+	}
+	server := NewServer(tree, engine, nil, "test-model")
+
+	node := pendingNode(t, tree)
+
+	// A waiter hit maxPendingWait while the finalizer sat queued and
+	// poisoned the node; when the finalizer finally runs, its forward
+	// still succeeds but the handle must not leak
+	radix.PoisonNode(node, radix.ErrWaitTimeout)
+	server.finalizeNode(node, nil, radix.RootCacheHandle, vision.NoTicket)
+
+	if len(freed) != 1 || freed[0] != 42 {
+		t.Errorf("freed handles = %v, want [42]", freed)
+	}
+}
@@ -1,14 +1,20 @@
 package http
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
+	"github.com/agenthands/GUI-Actor/internal/cluster"
 	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/internal/vision"
 	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
 )
 
@@ -19,40 +25,130 @@ type Server struct {
 	tokenizer *tokenizer.Tokenizer
 	model     any
 	mu        sync.Mutex
+
+	// cluster is non-nil when distributed prefix cache advertisement
+	// is enabled; nodeID identifies this server in routing hints
+	cluster *cluster.Registry
+	nodeID  string
+
+	// gate is non-nil when high-priority requests may preempt
+	// low-priority generations mid-decode
+	gate *PriorityGate
+
+	// maxPendingWait bounds blocking on pending nodes (0 uses
+	// radix.DefaultMaxPendingWait)
+	maxPendingWait time.Duration
+
+	// warmer is non-nil when speculative prefix warming is enabled; it
+	// learns hot prefixes from live traffic
+	warmer *radix.Warmer
+
+	// visionCache caches image embeddings by content hash so repeated
+	// screenshots skip the vision tower; visionProfile is the resize
+	// profile the keys are scoped to
+	visionCache   *vision.Cache
+	visionProfile string
+
+	// finalizers supervises background node finalizations: bounded
+	// concurrency, panic capture, and shutdown draining
+	finalizers *finalizerPool
+
+	// pinned tracks the nodes held resident by cache=pin requests so a
+	// re-sent pinned prompt is a no-op instead of another reference;
+	// pinnedTokens sums their edge tokens and maxPinnedTokens caps it
+	// (0 = unlimited). Guarded by mu.
+	pinned          map[*radix.Node]struct{}
+	pinnedTokens    int
+	maxPinnedTokens int
+}
+
+// SetVisionCache enables embedding reuse for message images under the
+// given resize profile
+func (s *Server) SetVisionCache(c *vision.Cache, profile string) {
+	s.visionCache = c
+	s.visionProfile = profile
+}
+
+// SetWarmer feeds request prefixes to the speculative cache warmer
+func (s *Server) SetWarmer(w *radix.Warmer) {
+	s.warmer = w
+}
+
+// SetPriorityGate enables preemption of low-priority generations
+func (s *Server) SetPriorityGate(g *PriorityGate) {
+	s.gate = g
+}
+
+// SetMaxPendingWait caps how long a request waits on a pending node
+// before abandoning it and recomputing from an ancestor
+func (s *Server) SetMaxPendingWait(d time.Duration) {
+	s.maxPendingWait = d
+}
+
+// SetPinnedTokenCap bounds the total edge tokens cache=pin requests may
+// hold resident; pins past the cap are declined, not queued
+func (s *Server) SetPinnedTokenCap(n int) {
+	s.maxPinnedTokens = n
 }
 
 // NewServer creates a new HTTP server
 func NewServer(tree *radix.Tree, engine radix.MLXEngine, tok *tokenizer.Tokenizer, model any) *Server {
 	return &Server{
-		tree:      tree,
-		engine:    engine,
-		tokenizer: tok,
-		model:     model,
+		tree:       tree,
+		engine:     engine,
+		tokenizer:  tok,
+		model:      model,
+		finalizers: newFinalizerPool(DefaultFinalizerWorkers),
 	}
 }
 
+// Drain stops accepting background finalizations and waits for the
+// in-flight ones, bounded by the context; call during graceful
+// shutdown after the listener closes
+func (s *Server) Drain(ctx context.Context) error {
+	return s.finalizers.Drain(ctx)
+}
+
 // ChatCompletionRequest matches OpenAI API format
 type ChatCompletionRequest struct {
 	Messages    []tokenizer.ChatMessage `json:"messages"`
 	MaxTokens   int                     `json:"max_tokens,omitempty"`
 	Temperature float64                 `json:"temperature,omitempty"`
 	Stream      bool                    `json:"stream,omitempty"`
+
+	// Priority is "high", "normal" (default), or "low"; low-priority
+	// generations may be preempted mid-decode by high-priority ones
+	Priority string `json:"priority,omitempty"`
+
+	// Cache overrides how this request uses the prefix cache: "off"
+	// bypasses matching and insertion (cold benchmarking), "read_only"
+	// reuses prefixes without inserting new ones, and "pin" keeps this
+	// prompt's prefix resident, subject to the admin pinned-token cap
+	Cache string `json:"cache,omitempty"`
 }
 
+// Cache modes accepted in ChatCompletionRequest.Cache
+const (
+	CacheModeFull     = "" // default: match and insert
+	CacheModeOff      = "off"
+	CacheModeReadOnly = "read_only"
+	CacheModePin      = "pin"
+)
+
 // ChatCompletionResponse matches OpenAI API format
 type ChatCompletionResponse struct {
-	ID      string                   `json:"id"`
-	Object  string                   `json:"object"`
-	Created int64                    `json:"created"`
-	Model   string                   `json:"model"`
-	Choices []CompletionChoice       `json:"choices"`
-	Usage   CompletionUsage          `json:"usage"`
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   CompletionUsage    `json:"usage"`
 }
 
 type CompletionChoice struct {
-	Index        int          `json:"index"`
-	Message      ChatMessage  `json:"message"`
-	FinishReason string       `json:"finish_reason"`
+	Index        int         `json:"index"`
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
 }
 
 type ChatMessage struct {
@@ -80,6 +176,21 @@ func (s *Server) ChatCompletionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch req.Cache {
+	case CacheModeFull, CacheModeOff, CacheModeReadOnly, CacheModePin:
+	default:
+		http.Error(w, fmt.Sprintf("Invalid cache mode: %q", req.Cache), http.StatusBadRequest)
+		return
+	}
+
+	// Fail fast while the engine breaker is open
+	if br, ok := s.engine.(*radix.Breaker); ok {
+		if err := br.Allow(); err != nil {
+			http.Error(w, fmt.Sprintf("Engine unavailable: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
 	// Tokenize input
 	tokReq := &tokenizer.ChatRequest{
 		Messages:    req.Messages,
@@ -92,13 +203,52 @@ func (s *Server) ChatCompletionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Resolve image embeddings through the vision cache: a repeated
+	// screenshot reuses its embedding no matter where it sits in the
+	// prompt, independent of text-prefix matching. The ticket scopes
+	// the staged batch to this request's prefill forward.
+	visionTicket := vision.NoTicket
+	if s.visionCache != nil {
+		ticket, err := s.injectVisionEmbeddings(req.Messages)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid image: %v", err), http.StatusBadRequest)
+			return
+		}
+		visionTicket = ticket
+	}
+
+	// Tell the load balancer which nodes already have this prefix warm
+	if s.cluster != nil {
+		if hint := s.routingHint(inputTokens); hint != "" {
+			w.Header().Set(PrefixCacheHintHeader, hint)
+		}
+	}
+
+	// High-priority requests pause low-priority decode loops while
+	// they run
+	if s.gate != nil && req.Priority == "high" {
+		s.gate.BeginHigh()
+		defer s.gate.EndHigh()
+	}
+
 	// Execute autoregressive generation
-	outputTokens, err := s.GenerateAutoregressive(inputTokens, req.MaxTokens)
+	outputTokens, decision, err := s.generate(r.Context(), inputTokens, req.MaxTokens, req.Priority == "low", req.Cache, visionTicket)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Generation failed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	// Report the cache decision when the client opted in
+	if r.Header.Get(CacheDebugHeader) != "" {
+		w.Header().Set(CacheDecisionHeader, decision.header())
+	}
+
+	// Streaming mode: emit per-token SSE chunks instead of a single body
+	if req.Stream {
+		s.streamCompletion(w, outputTokens)
+		return
+	}
+
 	// Decode output
 	outputText, err := s.tokenizer.Decode(outputTokens)
 	if err != nil {
@@ -114,7 +264,7 @@ func (s *Server) ChatCompletionHandler(w http.ResponseWriter, r *http.Request) {
 		Model:   "gui-actor",
 		Choices: []CompletionChoice{
 			{
-				Index:        0,
+				Index: 0,
 				Message: ChatMessage{
 					Role:    "assistant",
 					Content: outputText,
@@ -134,20 +284,148 @@ func (s *Server) ChatCompletionHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// ChatCompletionChunk is one SSE chunk of a streaming response
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+}
+
+type ChunkChoice struct {
+	Index        int         `json:"index"`
+	Delta        ChatMessage `json:"delta"`
+	FinishReason string      `json:"finish_reason,omitempty"`
+}
+
+// streamCompletion writes generated tokens as SSE chunks, one token per
+// chunk, then a finish chunk and the [DONE] marker
+func (s *Server) streamCompletion(w http.ResponseWriter, outputTokens []uint32) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, _ := w.(http.Flusher)
+	sw := NewStreamingResponseWriter(w)
+
+	for i, token := range outputTokens {
+		text, err := s.tokenizer.Decode([]uint32{token})
+		if err != nil {
+			slog.Error("Failed to decode token", "token", token, "error", err)
+			continue
+		}
+
+		delta := ChatMessage{Content: text}
+		if i == 0 {
+			delta.Role = "assistant"
+		}
+		chunk := ChatCompletionChunk{
+			ID:      "chatcmpl-1",
+			Object:  "chat.completion.chunk",
+			Model:   "gui-actor",
+			Choices: []ChunkChoice{{Index: 0, Delta: delta}},
+		}
+
+		if err := sw.WriteChunk(chunk); err != nil {
+			// Client went away mid-stream; nothing left to write to
+			slog.Info("Streaming client disconnected", "error", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	finish := ChatCompletionChunk{
+		ID:      "chatcmpl-1",
+		Object:  "chat.completion.chunk",
+		Model:   "gui-actor",
+		Choices: []ChunkChoice{{Index: 0, FinishReason: "stop"}},
+	}
+	if err := sw.WriteChunk(finish); err != nil {
+		return
+	}
+	sw.Close()
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
 // GenerateAutoregressive implements autoregressive generation with bulk aggregation
 // Uses RadixAttention for KV cache reuse across tokens
 func (s *Server) GenerateAutoregressive(inputTokens []uint32, maxTokens int) ([]uint32, error) {
-	// Find prefix match in cache
-	baseNode := s.tree.Match(inputTokens)
+	tokens, _, err := s.generate(context.Background(), inputTokens, maxTokens, false, CacheModeFull, vision.NoTicket)
+	return tokens, err
+}
+
+// generate is the decode loop; lowPriority generations checkpoint at
+// the gate between steps and can be preempted there, resuming from
+// their buffered output and base cache handle. cacheMode overrides how
+// the request touches the prefix cache; visionTicket identifies the
+// staged embedding batch owned by this request (NoTicket when it has
+// no images). The returned decision describes how the cache served
+// this request.
+func (s *Server) generate(ctx context.Context, inputTokens []uint32, maxTokens int, lowPriority bool, cacheMode string, visionTicket uint64) ([]uint32, *CacheDecision, error) {
+	decision := &CacheDecision{}
+	decision.CacheMode = cacheMode
+
+	// Ticket ownership mirrors the base pin below: it is handed to the
+	// finalizer whose forward consumes the batch, and released when no
+	// prefill forward of ours will run
+	ticket := visionTicket
+	defer func() {
+		if ticket != vision.NoTicket {
+			s.releaseVisionTicket(ticket)
+		}
+	}()
+
+	// Feed the warmer so it learns which prefixes are worth extending;
+	// bypassing requests are benchmarks, not traffic worth learning from
+	if s.warmer != nil && cacheMode != CacheModeOff {
+		s.warmer.RecordHit(inputTokens)
+	}
+
+	// Find prefix match in cache, unless the request opted out entirely
+	var baseNode *radix.Node
+	if cacheMode != CacheModeOff {
+		baseNode = s.tree.Match(inputTokens)
+	}
 	var baseHandle uint64 = radix.RootCacheHandle
 	var generatedTokens []uint32
 
+	// Hold the base resident while its handle serves this request's
+	// forwards; background eviction and compaction must not free it.
+	// The pin is handed to the finalizer when one is dispatched, since
+	// the forward it runs uses the handle after we return.
+	basePin := baseNode
+	defer func() {
+		if basePin != nil {
+			s.tree.Unpin(basePin)
+		}
+	}()
 	if baseNode != nil {
+		s.tree.Pin(baseNode)
 		baseHandle = baseNode.CacheHandle
-		// Wait for node to be ready if pending
-		if err := baseNode.Wait(); err != nil {
-			// Node was poisoned, start from root
-			baseHandle = radix.RootCacheHandle
+		decision.observeMatch(baseNode)
+		wasReady := baseNode.IsReady()
+		waitStart := time.Now()
+		// Wait for the node, but not forever: a dead finalizer must not
+		// hang the request
+		if err := baseNode.WaitCtx(ctx, s.maxPendingWait); err != nil {
+			// Node was poisoned or abandoned; recompute from the
+			// nearest ready ancestor instead
+			baseHandle = readyAncestorHandle(baseNode)
+		}
+		if !wasReady {
+			decision.observeWait(time.Since(waitStart))
+		}
+	} else if cacheMode != CacheModeOff {
+		// Miss in the hot tree, hit in the spill tier: re-import the
+		// evicted KV cache instead of recomputing the prefill
+		if handle, ok := s.tree.RestoreSpilled(inputTokens); ok {
+			baseHandle = handle
+			decision.SpillRestored = true
 		}
 	}
 
@@ -160,6 +438,13 @@ func (s *Server) GenerateAutoregressive(inputTokens []uint32, maxTokens int) ([]
 			break
 		}
 
+		// Yield to high-priority requests between decode steps; the
+		// partial buffer and currentHandle survive the pause, so the
+		// sequence resumes from its cache instead of restarting
+		if lowPriority && s.gate != nil {
+			s.gate.Checkpoint()
+		}
+
 		// Get next token (simplified: just echo for now)
 		// Production would sample from logits distribution
 		var nextToken uint32
@@ -181,24 +466,162 @@ func (s *Server) GenerateAutoregressive(inputTokens []uint32, maxTokens int) ([]
 	}
 
 	// Bulk insert: insert all generated tokens as single edge
-	// This prevents tree fragmentation
-	if len(buffer) > 0 {
-		node, err := s.tree.InsertPending(buffer, s.engine, s.model)
-		if err != nil {
+	// This prevents tree fragmentation. Bypassing and read-only
+	// requests leave the tree untouched.
+	if len(buffer) > 0 && cacheMode != CacheModeOff && cacheMode != CacheModeReadOnly {
+		node, created, err := s.tree.InsertOrJoin(buffer, s.engine, s.model)
+		if err == nil && cacheMode == CacheModePin {
+			// Take the resident reference before the paths below
+			// release the insert pin
+			if s.pinResident(node) {
+				decision.Pinned = true
+			} else {
+				slog.Warn("Pinned token cap reached; prefix not pinned",
+					"tokens", len(node.Tokens), "cap", s.maxPinnedTokens)
+			}
+		}
+		switch {
+		case errors.Is(err, radix.ErrPrefixTooShort):
+			// Below the caching threshold; the response is already
+			// complete, we just skip the tree entry
+		case err != nil:
 			slog.Error("Failed to insert pending node", "error", err)
-		} else {
-			// Launch computation in background
-			go s.finalizeNode(node, currentHandle)
+		case created:
+			decision.InsertedTokens = len(buffer)
+			// We own the node: hand computation (and the base pin) to the
+			// supervised pool
+			base := basePin
+			batch := ticket
+			if s.finalizers.Submit(node, func() { s.finalizeNode(node, base, currentHandle, batch) }) {
+				basePin = nil
+				ticket = vision.NoTicket
+			} else {
+				// Draining for shutdown: fail the node so waiters do not
+				// hang on a finalization that will never run
+				radix.PoisonNode(node, errDraining)
+				s.tree.Unpin(node)
+			}
+		case node.IsReady():
+			// Cache hit: the node was already computed, just release our pin
+			s.tree.Unpin(node)
+		default:
+			// Another identical request is already running prefill for
+			// this sequence; wait for its result and share the handle
+			// instead of dispatching a duplicate forward
+			waitStart := time.Now()
+			if werr := node.WaitCtx(ctx, s.maxPendingWait); werr == nil {
+				currentHandle = node.CacheHandle
+			}
+			decision.observeWait(time.Since(waitStart))
+			s.tree.Unpin(node)
 		}
 	}
 
-	return generatedTokens, nil
+	decision.log()
+	return generatedTokens, decision, nil
 }
 
-// finalizeNode runs MLX computation and finalizes a pending node
-func (s *Server) finalizeNode(node *radix.Node, baseHandle uint64) {
-	// Run forward pass
-	logits, newHandle, err := s.engine.ForwardWithCache(s.model, node.Tokens, baseHandle)
+// pinResident takes the extra reference that keeps a node's prefix
+// resident, declining when it would exceed the pinned-token cap.
+// Re-pinning an already-resident node succeeds without charging the
+// budget or taking another reference, so clients may re-send pinned
+// prompts freely.
+func (s *Server) pinResident(node *radix.Node) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pinned[node]; ok {
+		return true
+	}
+	if s.maxPinnedTokens > 0 && s.pinnedTokens+len(node.Tokens) > s.maxPinnedTokens {
+		return false
+	}
+	if s.pinned == nil {
+		s.pinned = make(map[*radix.Node]struct{})
+	}
+	s.pinned[node] = struct{}{}
+	s.pinnedTokens += len(node.Tokens)
+	s.tree.Pin(node)
+	return true
+}
+
+// injectVisionEmbeddings embeds each message image (through the
+// cache) and stages the batch with the engine, returning the ticket
+// that scopes it to this request's prefill forward. Engines without a
+// vision tower are skipped silently and yield NoTicket.
+func (s *Server) injectVisionEmbeddings(messages []tokenizer.ChatMessage) (uint64, error) {
+	embedder, ok := s.engine.(vision.Embedder)
+	if !ok {
+		return vision.NoTicket, nil
+	}
+
+	var embeddings [][]float32
+	for _, msg := range messages {
+		for _, encoded := range msg.AllImages() {
+			image, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return vision.NoTicket, err
+			}
+			embedding, err := s.visionCache.GetOrCompute(image, s.visionProfile, embedder)
+			if err != nil {
+				slog.Warn("Image embedding failed", "error", err)
+				continue
+			}
+			embeddings = append(embeddings, embedding)
+		}
+	}
+
+	if len(embeddings) > 0 {
+		if injector, ok := s.engine.(vision.Injector); ok {
+			return injector.InjectEmbeddings(embeddings), nil
+		}
+	}
+	return vision.NoTicket, nil
+}
+
+// releaseVisionTicket discards a staged embedding batch that no
+// forward will consume
+func (s *Server) releaseVisionTicket(ticket uint64) {
+	if ticket == vision.NoTicket {
+		return
+	}
+	if injector, ok := s.engine.(vision.Injector); ok {
+		injector.ReleaseEmbeddings(ticket)
+	}
+}
+
+// readyAncestorHandle walks up from a failed node to the deepest
+// ancestor whose cache is usable, falling back to the root handle
+func readyAncestorHandle(node *radix.Node) uint64 {
+	for p := node.Parent; p != nil && p.Parent != nil; p = p.Parent {
+		if p.IsReady() && p.Wait() == nil {
+			return p.CacheHandle
+		}
+	}
+	return radix.RootCacheHandle
+}
+
+// finalizeNode runs MLX computation and finalizes a pending node. base
+// is the request's pinned forward-base node, released once the forward
+// no longer needs its handle; nil when the request forwarded from the
+// root or a spill-restored handle.
+func (s *Server) finalizeNode(node *radix.Node, base *radix.Node, baseHandle uint64, visionTicket uint64) {
+	if base != nil {
+		defer s.tree.Unpin(base)
+	}
+
+	// Run forward pass; when the request staged vision embeddings, the
+	// ticketed forward consumes exactly its own batch, so concurrent
+	// requests cannot interleave images
+	var logits []float32
+	var newHandle uint64
+	var err error
+	if tf, ok := s.engine.(vision.TicketedForwarder); ok && visionTicket != vision.NoTicket {
+		logits, newHandle, err = tf.ForwardWithEmbeddings(s.model, node.Tokens, baseHandle, visionTicket)
+	} else {
+		s.releaseVisionTicket(visionTicket)
+		logits, newHandle, err = s.engine.ForwardWithCache(s.model, node.Tokens, baseHandle)
+	}
 	if err != nil {
 		// Poison the node on error
 		radix.PoisonNode(node, err)
@@ -209,8 +632,15 @@ func (s *Server) finalizeNode(node *radix.Node, baseHandle uint64) {
 	// In production, we'd extract the next token from logits
 	_ = logits
 
-	// Finalize the node with new cache handle
-	radix.FinalizeNode(node, newHandle)
+	// Finalize the node with new cache handle. The finalizer may have
+	// sat queued behind the bounded pool long enough for a waiter's
+	// maxPendingWait to fire and poison the node; in that case the
+	// freshly computed handle has no owner and must be freed here.
+	if !radix.FinalizeNode(node, newHandle) {
+		if newHandle != radix.RootCacheHandle {
+			s.engine.FreeCache(newHandle)
+		}
+	}
 
 	// Unpin node (allows LRU eviction)
 	s.tree.Unpin(node)
@@ -223,15 +653,35 @@ func (s *Server) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp := map[string]string{"status": "ok"}
+	if br, ok := s.engine.(*radix.Breaker); ok {
+		resp["breaker"] = br.State()
+		if br.State() == radix.BreakerOpen {
+			resp["status"] = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CacheStatsHandler handles GET /v1/cache/stats
+func (s *Server) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
-	})
+	json.NewEncoder(w).Encode(s.tree.Stats())
 }
 
 // RegisterRoutes registers all HTTP routes
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/v1/chat/completions", s.ChatCompletionHandler)
+	mux.HandleFunc("/v1/cache/prefixes", s.CachePrefixesHandler)
+	mux.HandleFunc("/v1/cache/advertise", s.CacheAdvertiseHandler)
+	mux.HandleFunc("/v1/cache/stats", s.CacheStatsHandler)
 	mux.HandleFunc("/health", s.HealthCheckHandler)
 }
 
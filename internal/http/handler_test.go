@@ -2,12 +2,19 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/internal/vision"
 	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
 )
 
@@ -396,3 +403,478 @@ func TestCompletionUsage(t *testing.T) {
 		t.Error("TotalTokens should equal PromptTokens + CompletionTokens")
 	}
 }
+
+func TestChatCompletionHandlerBreakerOpen(t *testing.T) {
+	tree := radix.NewTree()
+	engine := radix.NewBreaker(&radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return nil, 0, errors.New("metal OOM")
+		},
+	}, radix.BreakerOptions{Threshold: 1, Cooldown: time.Hour})
+	tok := tokenizer.NewTokenizer(32000)
+	server := NewServer(tree, engine, tok, "test-model")
+
+	// Trip the breaker directly
+	engine.ForwardWithCache(nil, []uint32{1}, 0)
+
+	reqBody := ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{{Role: "user", Content: "Hello!"}},
+	}
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ChatCompletionHandler(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestHealthCheckHandlerBreakerState(t *testing.T) {
+	tree := radix.NewTree()
+	engine := radix.NewBreaker(&radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return nil, 0, errors.New("metal OOM")
+		},
+	}, radix.BreakerOptions{Threshold: 1, Cooldown: time.Hour})
+	tok := tokenizer.NewTokenizer(32000)
+	server := NewServer(tree, engine, tok, "test-model")
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	server.HealthCheckHandler(w, req)
+
+	var resp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse health response: %v", err)
+	}
+	if resp["status"] != "ok" || resp["breaker"] != radix.BreakerClosed {
+		t.Errorf("Expected ok/closed, got %v", resp)
+	}
+
+	// Trip the breaker and check degraded status
+	engine.ForwardWithCache(nil, []uint32{1}, 0)
+	w = httptest.NewRecorder()
+	server.HealthCheckHandler(w, req)
+
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse health response: %v", err)
+	}
+	if resp["status"] != "degraded" || resp["breaker"] != radix.BreakerOpen {
+		t.Errorf("Expected degraded/open, got %v", resp)
+	}
+}
+
+func TestGenerateFallsBackToReadyAncestor(t *testing.T) {
+	tree := radix.NewTree()
+	bases := make(chan uint64, 1)
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			bases <- base
+			return make([]float32, 32000), base + 1, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	// Ready parent with handle 7, poisoned child below it
+	parent, err := tree.InsertPending([]uint32{11, 12}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	radix.FinalizeNode(parent, 7)
+	tree.Unpin(parent)
+
+	child, err := tree.InsertPending([]uint32{11, 12, 13}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	radix.PoisonNode(child, errors.New("forward failed"))
+	tree.Unpin(child)
+
+	// Match lands on the poisoned child; generation must recompute
+	// from the parent's cache, not from the root
+	_, _, err = server.generate(context.Background(), []uint32{11, 12, 13, 14}, 4, false, CacheModeFull, vision.NoTicket)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	select {
+	case base := <-bases:
+		if base != 7 {
+			t.Errorf("Forward base handle = %d, want 7 (ready ancestor)", base)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Background finalize never ran")
+	}
+}
+
+func TestReadyAncestorHandleRootFallback(t *testing.T) {
+	tree := radix.NewTree()
+	node, err := tree.InsertPending([]uint32{5, 6}, &radix.MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	radix.PoisonNode(node, errors.New("forward failed"))
+	tree.Unpin(node)
+
+	if got := readyAncestorHandle(node); got != radix.RootCacheHandle {
+		t.Errorf("readyAncestorHandle = %d, want root handle", got)
+	}
+}
+
+func TestThunderingHerdSinglePrefill(t *testing.T) {
+	tree := radix.NewTree()
+	var forwards atomic.Int32
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			forwards.Add(1)
+			// Keep prefill slow enough for the herd to pile up
+			time.Sleep(10 * time.Millisecond)
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	inputTokens := []uint32{11, 12, 13, 14}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := server.GenerateAutoregressive(inputTokens, 4); err != nil {
+				t.Errorf("GenerateAutoregressive failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Give the creator's background finalize time to land, then verify
+	// only one forward pass served the whole herd
+	deadline := time.Now().Add(2 * time.Second)
+	for tree.Match(inputTokens) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Herd node never finalized")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := forwards.Load(); got != 1 {
+		t.Errorf("ForwardWithCache called %d times for identical prompts, want 1", got)
+	}
+}
+
+func TestVisionEmbeddingCacheAcrossRequests(t *testing.T) {
+	tree := radix.NewTree()
+	engine := radix.NewSimEngine(radix.SimConfig{VocabSize: 32000})
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	cache := vision.NewCache(4)
+	server.SetVisionCache(cache, "balanced")
+
+	image := base64.StdEncoding.EncodeToString([]byte("fake screenshot bytes"))
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{
+			{Role: "user", Content: "Click save", Image: image},
+		},
+		MaxTokens: 4,
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ChatCompletionHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Request %d: status %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	// The second request reused the first one's embedding but still
+	// injected it into its forward
+	hits, misses := cache.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("Cache stats = (%d, %d), want (1, 1)", hits, misses)
+	}
+	if got := engine.InjectedEmbeddings(); got != 2 {
+		t.Errorf("Injected %d embeddings, want 2", got)
+	}
+}
+
+func TestVisionTicketScopedToOwningForward(t *testing.T) {
+	tree := radix.NewTree()
+	engine := radix.NewSimEngine(radix.SimConfig{VocabSize: 32000})
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	server.SetVisionCache(vision.NewCache(4), "balanced")
+
+	image := base64.StdEncoding.EncodeToString([]byte("fake screenshot bytes"))
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{
+			{Role: "user", Content: "Click save", Image: image},
+		},
+		MaxTokens: 4,
+	})
+
+	// The first request stages a batch and its prefill forward consumes
+	// it; the second joins the cached prefix, runs no prefill of its
+	// own, and must release its batch instead of leaving it staged for
+	// an unrelated forward to pick up
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ChatCompletionHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Request %d: status %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	// The creator's finalize runs in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for engine.StagedBatches() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d batches still staged; tickets leaked", engine.StagedBatches())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := engine.ConsumedBatches(); got != 1 {
+		t.Errorf("Forwards consumed %d batches, want 1", got)
+	}
+}
+
+func TestVisionEmbeddingInvalidImage(t *testing.T) {
+	tree := radix.NewTree()
+	engine := radix.NewSimEngine(radix.SimConfig{VocabSize: 32000})
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	server.SetVisionCache(vision.NewCache(4), "balanced")
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{
+			{Role: "user", Content: "Click save", Image: "not valid base64!!!"},
+		},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ChatCompletionHandler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid image, got %d", w.Code)
+	}
+}
+
+func TestCacheStatsHandler(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	node, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "test-model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	radix.FinalizeNode(node, 1)
+	tree.Unpin(node)
+
+	req := httptest.NewRequest("GET", "/v1/cache/stats", nil)
+	w := httptest.NewRecorder()
+	server.CacheStatsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var stats radix.TreeStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if stats.Nodes != 1 || stats.TotalTokens != 3 || stats.Evictable != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Only GET is allowed
+	w = httptest.NewRecorder()
+	server.CacheStatsHandler(w, httptest.NewRequest("POST", "/v1/cache/stats", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST, got %d", w.Code)
+	}
+}
+
+// postCacheMode sends a completion with the given cache override and
+// returns the recorder
+func postCacheMode(t *testing.T, server *Server, mode string) *httptest.ResponseRecorder {
+	t.Helper()
+	reqBody := ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{{Role: "user", Content: "Click the button"}},
+		Cache:    mode,
+	}
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CacheDebugHeader, "1")
+	w := httptest.NewRecorder()
+	server.ChatCompletionHandler(w, req)
+	return w
+}
+
+// waitSettled polls until no node in the tree is still pending, so
+// tests observe the state after background finalization
+func waitSettled(t *testing.T, tree *radix.Tree) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if tree.Stats().Pending == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("tree still has pending nodes")
+}
+
+func TestCacheModeOffBypassesTree(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	w := postCacheMode(t, server, CacheModeOff)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := tree.NodeCount(); got != 0 {
+		t.Errorf("NodeCount = %d after cache=off request, want 0", got)
+	}
+}
+
+func TestCacheModeReadOnlyDoesNotInsert(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	// Prime the tree with a normal request, then repeat read-only
+	if w := postCacheMode(t, server, CacheModeFull); w.Code != http.StatusOK {
+		t.Fatalf("priming status = %d, want 200", w.Code)
+	}
+	waitSettled(t, tree)
+	before := tree.NodeCount()
+	if before == 0 {
+		t.Fatal("priming request inserted nothing")
+	}
+
+	if w := postCacheMode(t, server, CacheModeReadOnly); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := tree.NodeCount(); got != before {
+		t.Errorf("NodeCount = %d after read-only request, want %d", got, before)
+	}
+}
+
+func TestCacheModePinSurvivesEviction(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+
+	w := postCacheMode(t, server, CacheModePin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var decision CacheDecision
+	if err := json.Unmarshal([]byte(w.Header().Get(CacheDecisionHeader)), &decision); err != nil {
+		t.Fatalf("bad decision header: %v", err)
+	}
+	if !decision.Pinned {
+		t.Error("decision.Pinned = false, want true")
+	}
+	waitSettled(t, tree)
+
+	// A pinned prefix must survive a full LRU drain
+	for i := 0; i < 10 && tree.LRULen() > 0; i++ {
+		tree.EvictLRU(tree.LRULen())
+	}
+	if got := tree.NodeCount(); got == 0 {
+		t.Error("pinned prefix was evicted")
+	}
+}
+
+func TestCacheModePinRespectsCap(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	server.SetPinnedTokenCap(1)
+
+	w := postCacheMode(t, server, CacheModePin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var decision CacheDecision
+	if err := json.Unmarshal([]byte(w.Header().Get(CacheDecisionHeader)), &decision); err != nil {
+		t.Fatalf("bad decision header: %v", err)
+	}
+	if decision.Pinned {
+		t.Error("decision.Pinned = true despite a 1-token cap")
+	}
+	waitSettled(t, tree)
+
+	// With the pin declined the prefix drains like any other entry
+	for i := 0; i < 10 && tree.LRULen() > 0; i++ {
+		tree.EvictLRU(tree.LRULen())
+	}
+	if got := tree.NodeCount(); got != 0 {
+		t.Errorf("NodeCount = %d after drain, want 0", got)
+	}
+}
+
+func TestCacheModePinRepeatChargesBudgetOnce(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	// Room for the prompt's prefix exactly once; a second identical pin
+	// must be a no-op, not a second charge
+	server.SetPinnedTokenCap(64)
+
+	for i := 0; i < 3; i++ {
+		w := postCacheMode(t, server, CacheModePin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+		var decision CacheDecision
+		if err := json.Unmarshal([]byte(w.Header().Get(CacheDecisionHeader)), &decision); err != nil {
+			t.Fatalf("request %d: bad decision header: %v", i, err)
+		}
+		if !decision.Pinned {
+			t.Errorf("request %d: decision.Pinned = false, want true", i)
+		}
+		waitSettled(t, tree)
+	}
+
+	server.mu.Lock()
+	pinnedTokens, pinnedNodes := server.pinnedTokens, len(server.pinned)
+	server.mu.Unlock()
+	if pinnedNodes != 1 {
+		t.Errorf("pinned node count = %d after repeats, want 1", pinnedNodes)
+	}
+	if pinnedTokens > 64 {
+		t.Errorf("pinnedTokens = %d, exceeds the cap after repeats", pinnedTokens)
+	}
+}
+
+func TestInvalidCacheModeRejected(t *testing.T) {
+	tree := radix.NewTree()
+	server := NewServer(tree, &radix.MockMLXEngine{}, tokenizer.NewTokenizer(32000), "test-model")
+
+	if w := postCacheMode(t, server, "aggressive"); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d for unknown cache mode, want 400", w.Code)
+	}
+}
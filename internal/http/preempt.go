@@ -0,0 +1,72 @@
+package http
+
+import "sync"
+
+// PriorityGate lets high-priority requests preempt low-priority
+// generations between decode steps. A preempted generation keeps its
+// partial output buffered and its base cache handle, so it resumes
+// from the radix cache instead of restarting the prefill.
+type PriorityGate struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// high counts in-flight high-priority requests; low-priority
+	// generations pause at their next checkpoint while it is non-zero
+	high int
+
+	// preemptions counts how many times a generation was paused
+	preemptions int
+}
+
+// NewPriorityGate creates an open gate
+func NewPriorityGate() *PriorityGate {
+	g := &PriorityGate{}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// BeginHigh marks a high-priority request in flight, pausing
+// low-priority generations at their next checkpoint
+func (g *PriorityGate) BeginHigh() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.high++
+}
+
+// EndHigh releases a high-priority request, resuming preempted
+// generations once none remain
+func (g *PriorityGate) EndHigh() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.high > 0 {
+		g.high--
+	}
+	if g.high == 0 {
+		g.cond.Broadcast()
+	}
+}
+
+// Checkpoint is called by low-priority generations between decode
+// steps: it blocks while any high-priority request is in flight and
+// reports whether the caller was preempted
+func (g *PriorityGate) Checkpoint() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	preempted := false
+	if g.high > 0 {
+		preempted = true
+		g.preemptions++
+	}
+	for g.high > 0 {
+		g.cond.Wait()
+	}
+	return preempted
+}
+
+// Preemptions returns how many times a generation was paused
+func (g *PriorityGate) Preemptions() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.preemptions
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/internal/vision"
+	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
+)
+
+func TestCheckpointOpenGate(t *testing.T) {
+	gate := NewPriorityGate()
+	if gate.Checkpoint() {
+		t.Error("Checkpoint reported preemption with no high-priority work")
+	}
+	if gate.Preemptions() != 0 {
+		t.Errorf("Preemptions = %d, want 0", gate.Preemptions())
+	}
+}
+
+func TestCheckpointBlocksDuringHigh(t *testing.T) {
+	gate := NewPriorityGate()
+	gate.BeginHigh()
+
+	resumed := make(chan bool, 1)
+	go func() { resumed <- gate.Checkpoint() }()
+
+	select {
+	case <-resumed:
+		t.Fatal("Checkpoint returned while high-priority request active")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	gate.EndHigh()
+	select {
+	case preempted := <-resumed:
+		if !preempted {
+			t.Error("Checkpoint did not report preemption")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Checkpoint never resumed after EndHigh")
+	}
+	if gate.Preemptions() != 1 {
+		t.Errorf("Preemptions = %d, want 1", gate.Preemptions())
+	}
+}
+
+func TestCheckpointWaitsForAllHigh(t *testing.T) {
+	gate := NewPriorityGate()
+	gate.BeginHigh()
+	gate.BeginHigh()
+
+	resumed := make(chan bool, 1)
+	go func() { resumed <- gate.Checkpoint() }()
+
+	gate.EndHigh()
+	select {
+	case <-resumed:
+		t.Fatal("Checkpoint resumed with one high-priority request still active")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	gate.EndHigh()
+	select {
+	case <-resumed:
+	case <-time.After(time.Second):
+		t.Fatal("Checkpoint never resumed")
+	}
+}
+
+func TestExtraEndHighIsSafe(t *testing.T) {
+	gate := NewPriorityGate()
+	gate.EndHigh() // must not go negative and wedge the gate
+
+	gate.BeginHigh()
+	resumed := make(chan bool, 1)
+	go func() { resumed <- gate.Checkpoint() }()
+	gate.EndHigh()
+
+	select {
+	case <-resumed:
+	case <-time.After(time.Second):
+		t.Fatal("Checkpoint never resumed; high count went negative")
+	}
+}
+
+func TestLowPriorityGenerationPreempted(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	gate := NewPriorityGate()
+	server.SetPriorityGate(gate)
+
+	// Hold the gate closed, then start a low-priority generation: it
+	// must checkpoint before its first decode step and stall
+	gate.BeginHigh()
+	inputTokens := []uint32{10, 20, 30, 40}
+	done := make(chan []uint32, 1)
+	go func() {
+		output, _, err := server.generate(context.Background(), inputTokens, 4, true, CacheModeFull, vision.NoTicket)
+		if err != nil {
+			t.Errorf("generate failed: %v", err)
+		}
+		done <- output
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Low-priority generation completed while preempted")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	gate.EndHigh()
+	select {
+	case output := <-done:
+		if len(output) != 4 {
+			t.Errorf("Resumed generation produced %d tokens, want 4", len(output))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Generation never resumed after preemption ended")
+	}
+	if gate.Preemptions() == 0 {
+		t.Error("Gate recorded no preemptions")
+	}
+}
+
+func TestHighPriorityRequestPreemptsViaHandler(t *testing.T) {
+	tree := radix.NewTree()
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	server := NewServer(tree, engine, tokenizer.NewTokenizer(32000), "test-model")
+	server.SetPriorityGate(NewPriorityGate())
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Messages:  []tokenizer.ChatMessage{{Role: "user", Content: "Click save"}},
+		MaxTokens: 4,
+		Priority:  "high",
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ChatCompletionHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	// The gate must be fully released afterwards
+	if server.gate.Checkpoint() {
+		t.Error("Gate still closed after high-priority request finished")
+	}
+}
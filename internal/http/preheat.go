@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
+)
+
+// warmupConfig is the on-disk format for startup cache preheating.
+// Each entry is either an inline message array or the path (relative
+// to the config file) of a JSON file containing one.
+type warmupConfig struct {
+	WarmupPrompts []json.RawMessage `json:"warmup_prompts"`
+}
+
+// LoadWarmupPrompts reads the warmup prompt list from a config file
+func LoadWarmupPrompts(path string) ([][]tokenizer.ChatMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg warmupConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid warmup config: %w", err)
+	}
+
+	var prompts [][]tokenizer.ChatMessage
+	for i, raw := range cfg.WarmupPrompts {
+		// A string entry references a file holding the message array
+		var ref string
+		if err := json.Unmarshal(raw, &ref); err == nil {
+			if !filepath.IsAbs(ref) {
+				ref = filepath.Join(filepath.Dir(path), ref)
+			}
+			raw, err = os.ReadFile(ref)
+			if err != nil {
+				return nil, fmt.Errorf("warmup prompt %d: %w", i, err)
+			}
+		}
+
+		var messages []tokenizer.ChatMessage
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return nil, fmt.Errorf("warmup prompt %d: %w", i, err)
+		}
+		if len(messages) > 0 {
+			prompts = append(prompts, messages)
+		}
+	}
+	return prompts, nil
+}
+
+// Preheat tokenizes and prefills each prompt, finalizing the radix
+// nodes so the first real request against them is a cache hit. It
+// returns how many prompts were prefilled; failures are logged and
+// skipped so one bad prompt does not abort startup.
+func (s *Server) Preheat(prompts [][]tokenizer.ChatMessage) int {
+	warmed := 0
+	for _, messages := range prompts {
+		tokens, err := s.tokenizer.TokenizeChatRequest(&tokenizer.ChatRequest{Messages: messages})
+		if err != nil {
+			slog.Warn("Skipping warmup prompt: tokenization failed", "error", err)
+			continue
+		}
+
+		node, created, err := s.tree.InsertOrJoin(tokens, s.engine, s.model)
+		if err != nil {
+			slog.Warn("Skipping warmup prompt: insert failed", "error", err)
+			continue
+		}
+		if !created {
+			// Already cached (e.g. duplicate warmup entries)
+			s.tree.Unpin(node)
+			continue
+		}
+
+		// Prefill synchronously: startup blocks until the cache is warm
+		_, handle, err := s.engine.ForwardWithCache(s.model, node.Tokens, radix.RootCacheHandle)
+		if err != nil {
+			slog.Warn("Warmup prefill failed", "error", err)
+			radix.PoisonNode(node, err)
+			s.tree.Unpin(node)
+			continue
+		}
+		if !radix.FinalizeNode(node, handle) {
+			// A concurrent waiter poisoned the node first; the handle
+			// is orphaned, so free it rather than leak
+			if handle != radix.RootCacheHandle {
+				s.engine.FreeCache(handle)
+			}
+			s.tree.Unpin(node)
+			continue
+		}
+		s.tree.Unpin(node)
+		warmed++
+	}
+	return warmed
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
+)
+
+// writeWarmupConfig writes a warmup config file into dir
+func writeWarmupConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadWarmupPromptsInline(t *testing.T) {
+	path := writeWarmupConfig(t, t.TempDir(), "warmup.json", `{
+		"warmup_prompts": [
+			[{"role": "system", "content": "You are a GUI agent."}],
+			[{"role": "system", "content": "Sys"}, {"role": "user", "content": "Click save"}]
+		]
+	}`)
+
+	prompts, err := LoadWarmupPrompts(path)
+	if err != nil {
+		t.Fatalf("LoadWarmupPrompts failed: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("Loaded %d prompts, want 2", len(prompts))
+	}
+	if len(prompts[1]) != 2 || prompts[1][1].Content != "Click save" {
+		t.Errorf("Second prompt = %+v, want two messages ending in 'Click save'", prompts[1])
+	}
+}
+
+func TestLoadWarmupPromptsFileReference(t *testing.T) {
+	dir := t.TempDir()
+	writeWarmupConfig(t, dir, "long_prompt.json",
+		`[{"role": "system", "content": "Long system prompt"}]`)
+	path := writeWarmupConfig(t, dir, "warmup.json",
+		`{"warmup_prompts": ["long_prompt.json"]}`)
+
+	prompts, err := LoadWarmupPrompts(path)
+	if err != nil {
+		t.Fatalf("LoadWarmupPrompts failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0][0].Content != "Long system prompt" {
+		t.Errorf("Loaded prompts = %+v, want the referenced file's messages", prompts)
+	}
+}
+
+func TestLoadWarmupPromptsErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		path    string
+		content string
+	}{
+		{"missing file", filepath.Join(dir, "nope.json"), ""},
+		{"invalid json", "", `{"warmup_prompts": [`},
+		{"missing reference", "", `{"warmup_prompts": ["gone.json"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = writeWarmupConfig(t, dir, "bad.json", tt.content)
+			}
+			if _, err := LoadWarmupPrompts(path); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestPreheatMakesFirstRequestWarm(t *testing.T) {
+	tree := radix.NewTree()
+	var forwards atomic.Int32
+	engine := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			forwards.Add(1)
+			return make([]float32, 32000), 100, nil
+		},
+	}
+	tok := tokenizer.NewTokenizer(32000)
+	server := NewServer(tree, engine, tok, "test-model")
+
+	messages := []tokenizer.ChatMessage{
+		{Role: "system", Content: "You are a GUI agent."},
+		{Role: "user", Content: "Click save"},
+	}
+	if warmed := server.Preheat([][]tokenizer.ChatMessage{messages}); warmed != 1 {
+		t.Fatalf("Preheat = %d, want 1", warmed)
+	}
+	if forwards.Load() != 1 {
+		t.Fatalf("Preheat ran %d forwards, want 1", forwards.Load())
+	}
+
+	// The prefilled prompt is now a full cache hit
+	tokens, err := tok.TokenizeChatRequest(&tokenizer.ChatRequest{Messages: messages})
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	node := tree.Match(tokens)
+	if node == nil || node.CacheHandle != 100 {
+		t.Fatal("Warmup prompt not cached with the prefill handle")
+	}
+
+	// Preheating the same prompt again is a no-op
+	if warmed := server.Preheat([][]tokenizer.ChatMessage{messages}); warmed != 0 {
+		t.Errorf("Second Preheat = %d, want 0", warmed)
+	}
+}
+
+func TestPreheatSkipsFailedPrefill(t *testing.T) {
+	tree := radix.NewTree()
+	server := NewServer(tree, &radix.MockMLXEngine{}, tokenizer.NewTokenizer(32000), "test-model")
+
+	messages := []tokenizer.ChatMessage{{Role: "user", Content: "Click save"}}
+	if warmed := server.Preheat([][]tokenizer.ChatMessage{messages}); warmed != 0 {
+		t.Errorf("Preheat = %d with failing engine, want 0", warmed)
+	}
+}
@@ -0,0 +1,54 @@
+package mlx
+
+import "fmt"
+
+// Error carries the C error code and message from the MLX bridge, so
+// callers (radix poison handling, HTTP status mapping, the circuit
+// breaker) can branch on error class with errors.Is instead of string
+// matching.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("mlx: %s (code %d)", e.Message, e.Code)
+}
+
+// Is matches any *Error with the same code, so
+// errors.Is(err, ErrOutOfMemory) works regardless of the C message
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Sentinel errors, one per C error code
+var (
+	ErrInvalidHandle     = &Error{Code: ErrorInvalidHandle, Message: "invalid cache handle"}
+	ErrOutOfMemory       = &Error{Code: ErrorOutOfMemory, Message: "out of memory"}
+	ErrInvalidTokens     = &Error{Code: ErrorInvalidTokens, Message: "invalid tokens"}
+	ErrComputationFailed = &Error{Code: ErrorComputationFailed, Message: "computation failed"}
+	ErrModelNotLoaded    = &Error{Code: ErrorModelNotLoaded, Message: "model not loaded"}
+)
+
+// sentinels maps codes to their default messages
+var sentinels = map[int]*Error{
+	ErrorInvalidHandle:     ErrInvalidHandle,
+	ErrorOutOfMemory:       ErrOutOfMemory,
+	ErrorInvalidTokens:     ErrInvalidTokens,
+	ErrorComputationFailed: ErrComputationFailed,
+	ErrorModelNotLoaded:    ErrModelNotLoaded,
+}
+
+// newError builds a typed error from a C return code and message. An
+// empty message falls back to the sentinel's default.
+func newError(code int, message string) *Error {
+	if message == "" {
+		if s, ok := sentinels[code]; ok {
+			message = s.Message
+		} else {
+			message = "unknown failure"
+		}
+	}
+	return &Error{Code: code, Message: message}
+}
@@ -0,0 +1,79 @@
+package mlx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorIsMatchesByCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel *Error
+	}{
+		{"oom with C message", newError(ErrorOutOfMemory, "Metal buffer allocation failed"), ErrOutOfMemory},
+		{"invalid handle", newError(ErrorInvalidHandle, ""), ErrInvalidHandle},
+		{"invalid tokens", newError(ErrorInvalidTokens, "empty tokens"), ErrInvalidTokens},
+		{"computation failed", newError(ErrorComputationFailed, ""), ErrComputationFailed},
+		{"model not loaded", newError(ErrorModelNotLoaded, ""), ErrModelNotLoaded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.sentinel) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", tt.err, tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestErrorIsDistinguishesCodes(t *testing.T) {
+	err := newError(ErrorOutOfMemory, "Metal OOM")
+	if errors.Is(err, ErrInvalidHandle) {
+		t.Error("OOM error matched ErrInvalidHandle")
+	}
+	if errors.Is(err, errors.New("Metal OOM")) {
+		t.Error("Typed error matched untyped error")
+	}
+}
+
+func TestErrorIsThroughWrapping(t *testing.T) {
+	wrapped := fmt.Errorf("forward pass failed: %w", newError(ErrorOutOfMemory, ""))
+	if !errors.Is(wrapped, ErrOutOfMemory) {
+		t.Error("Wrapped error did not match ErrOutOfMemory")
+	}
+
+	var typed *Error
+	if !errors.As(wrapped, &typed) {
+		t.Fatal("errors.As failed on wrapped error")
+	}
+	if typed.Code != ErrorOutOfMemory {
+		t.Errorf("Code = %d, want %d", typed.Code, ErrorOutOfMemory)
+	}
+}
+
+func TestNewErrorDefaultMessages(t *testing.T) {
+	err := newError(ErrorOutOfMemory, "")
+	if err.Message != "out of memory" {
+		t.Errorf("Message = %q, want default", err.Message)
+	}
+
+	err = newError(-99, "")
+	if err.Message != "unknown failure" {
+		t.Errorf("Message = %q, want unknown failure", err.Message)
+	}
+
+	err = newError(ErrorOutOfMemory, "custom detail")
+	if err.Message != "custom detail" {
+		t.Errorf("Message = %q, want custom detail", err.Message)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	err := newError(ErrorInvalidHandle, "handle 42 not found")
+	want := fmt.Sprintf("mlx: handle 42 not found (code %d)", ErrorInvalidHandle)
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
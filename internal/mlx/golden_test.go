@@ -0,0 +1,88 @@
+//go:build !mlx_mock
+
+package mlx_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/agenthands/GUI-Actor/internal/mlx"
+)
+
+// TestFirstTokenLogitsGolden compares first-token logits from the MLX
+// engine against reference values exported from the Python transformers
+// implementation. Requires Apple Silicon with the runtime built; set
+// MLX_GOLDEN_MODEL to the model path and regenerate the fixture with
+// scripts/export_golden_fixtures.py.
+func TestFirstTokenLogitsGolden(t *testing.T) {
+	modelPath := os.Getenv("MLX_GOLDEN_MODEL")
+	if modelPath == "" {
+		t.Skip("Set MLX_GOLDEN_MODEL to run logits parity against real hardware")
+	}
+
+	data, err := os.ReadFile("testdata/golden/first_token_logits.json")
+	if os.IsNotExist(err) {
+		t.Skip("Fixture not generated; run scripts/export_golden_fixtures.py")
+	}
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var fixture struct {
+		Cases []struct {
+			PromptTokens []uint32 `json:"prompt_tokens"`
+			TopToken     int      `json:"top_token"`
+			TopLogits    []struct {
+				Token int     `json:"token"`
+				Value float64 `json:"value"`
+			} `json:"top_logits"`
+		} `json:"cases"`
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	vocabSize, err := strconv.Atoi(os.Getenv("MLX_GOLDEN_VOCAB_SIZE"))
+	if err != nil || vocabSize <= 0 {
+		vocabSize = 152064
+	}
+	if err := mlx.LoadModel(modelPath, vocabSize); err != nil {
+		t.Fatalf("Failed to load model: %v", err)
+	}
+
+	// Quantized weights drift from the float32 reference; this bounds
+	// acceptable divergence on the top logits
+	const tolerance = 0.5
+
+	for i, tt := range fixture.Cases {
+		logits := make([]float32, vocabSize)
+		handle, err := mlx.ForwardWithCache(0, tt.PromptTokens, mlx.RootCacheHandle, logits)
+		if err != nil {
+			t.Fatalf("Case %d: forward failed: %v", i, err)
+		}
+		defer mlx.FreeCache(handle)
+
+		top := 0
+		for j, v := range logits {
+			if v > logits[top] {
+				top = j
+			}
+		}
+		if top != tt.TopToken {
+			t.Errorf("Case %d: argmax token = %d, reference %d", i, top, tt.TopToken)
+		}
+
+		for _, ref := range tt.TopLogits {
+			if ref.Token >= vocabSize {
+				continue
+			}
+			if diff := math.Abs(float64(logits[ref.Token]) - ref.Value); diff > tolerance {
+				t.Errorf("Case %d: logit[%d] = %f, reference %f (diff %f)",
+					i, ref.Token, logits[ref.Token], ref.Value, diff)
+			}
+		}
+	}
+}
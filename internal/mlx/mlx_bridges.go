@@ -18,13 +18,13 @@ import (
 
 // Constants from C API
 const (
-	RootCacheHandle = uint64(C.MLX_ROOT_CACHE_HANDLE)
-	Success         = int(C.MLX_SUCCESS)
-	ErrorInvalidHandle = int(C.MLX_ERROR_INVALID_HANDLE)
-	ErrorOutOfMemory   = int(C.MLX_ERROR_OUT_OF_MEMORY)
-	ErrorInvalidTokens  = int(C.MLX_ERROR_INVALID_TOKENS)
+	RootCacheHandle        = uint64(C.MLX_ROOT_CACHE_HANDLE)
+	Success                = int(C.MLX_SUCCESS)
+	ErrorInvalidHandle     = int(C.MLX_ERROR_INVALID_HANDLE)
+	ErrorOutOfMemory       = int(C.MLX_ERROR_OUT_OF_MEMORY)
+	ErrorInvalidTokens     = int(C.MLX_ERROR_INVALID_TOKENS)
 	ErrorComputationFailed = int(C.MLX_ERROR_COMPUTATION_FAILED)
-	ErrorModelNotLoaded = int(C.MLX_ERROR_MODEL_NOT_LOADED)
+	ErrorModelNotLoaded    = int(C.MLX_ERROR_MODEL_NOT_LOADED)
 )
 
 // ForwardWithCache executes MLX inference with KV cache
@@ -64,9 +64,9 @@ func ForwardWithCache(
 		if outErrorMsg != nil {
 			errMsg := C.GoString(outErrorMsg)
 			C.MLXFreeError(outErrorMsg)
-			return 0, errors.New(errMsg)
+			return 0, newError(int(ret), errMsg)
 		}
-		return 0, errors.New("MLX error: unknown failure")
+		return 0, newError(int(ret), "")
 	}
 
 	return uint64(outCacheHandle), nil
@@ -88,9 +88,9 @@ func SliceCache(cacheHandle uint64, keepTokens int) (uint64, error) {
 		if outErrorMsg != nil {
 			errMsg := C.GoString(outErrorMsg)
 			C.MLXFreeError(outErrorMsg)
-			return 0, errors.New(errMsg)
+			return 0, newError(int(ret), errMsg)
 		}
-		return 0, errors.New("MLX error: unknown failure")
+		return 0, newError(int(ret), "")
 	}
 
 	return uint64(outSlicedHandle), nil
@@ -114,7 +114,7 @@ func LoadModel(modelPath string, vocabSize int) error {
 	ret := C.MLXLoadModel(cPath, C.int(vocabSize))
 
 	if ret != C.MLX_SUCCESS {
-		return errors.New("MLX error: failed to load model")
+		return newError(int(ret), "failed to load model "+modelPath)
 	}
 
 	return nil
@@ -2,10 +2,6 @@
 
 package mlx
 
-import (
-	"errors"
-)
-
 // Mock implementations for testing without real MLX library
 
 const (
@@ -25,7 +21,7 @@ func ForwardWithCache(
 	baseCacheHandle uint64,
 ) ([]float32, uint64, error) {
 	if len(tokens) == 0 {
-		return nil, 0, errors.New("empty tokens")
+		return nil, 0, newError(ErrorInvalidTokens, "empty tokens")
 	}
 
 	// Mock: return fake logits and new cache handle
@@ -51,3 +47,9 @@ func FreeCache(cacheHandle uint64) {
 func FreeError(errMsg *byte) {
 	// No-op for mock
 }
+
+// LoadModel is a mock implementation; real weights can't be loaded
+// without the MLX runtime, so callers fall back to their mock paths
+func LoadModel(modelPath string, vocabSize int) error {
+	return newError(ErrorModelNotLoaded, "mlx_mock build cannot load "+modelPath)
+}
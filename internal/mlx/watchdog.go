@@ -0,0 +1,181 @@
+package mlx
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Go-side error codes for watchdog failures. These extend the C error
+// codes downward so they never collide.
+const (
+	ErrorCallTimeout     = -100
+	ErrorCallPanic       = -101
+	ErrorEngineUnhealthy = -102
+)
+
+// Watchdog sentinel errors
+var (
+	ErrCallTimeout     = &Error{Code: ErrorCallTimeout, Message: "call exceeded deadline"}
+	ErrCallPanic       = &Error{Code: ErrorCallPanic, Message: "call panicked"}
+	ErrEngineUnhealthy = &Error{Code: ErrorEngineUnhealthy, Message: "engine marked unhealthy"}
+)
+
+// DefaultCallDeadline bounds a single CGO call. A hung Metal command
+// buffer past this point is treated as a wedged engine.
+const DefaultCallDeadline = 60 * time.Second
+
+// Watchdog runs CGO calls on a separate goroutine so the request
+// goroutine can give up on a hung Metal command buffer: the deadline
+// expiring marks the engine unhealthy and fails the call, while the
+// stuck goroutine is abandoned (a CGO call cannot be cancelled).
+// Panics inside the call are recovered into typed errors.
+type Watchdog struct {
+	deadline time.Duration
+
+	mu         sync.Mutex
+	unhealthy  bool
+	diagnostic string
+}
+
+// NewWatchdog creates a watchdog with the given per-call deadline
+func NewWatchdog(deadline time.Duration) *Watchdog {
+	if deadline <= 0 {
+		deadline = DefaultCallDeadline
+	}
+	return &Watchdog{deadline: deadline}
+}
+
+// Healthy reports whether any call has ever timed out
+func (w *Watchdog) Healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.unhealthy
+}
+
+// Diagnostic returns the context of the call that wedged the engine
+func (w *Watchdog) Diagnostic() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.diagnostic
+}
+
+// markUnhealthy records the wedging call's context
+func (w *Watchdog) markUnhealthy(diagnostic string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unhealthy = true
+	w.diagnostic = diagnostic
+}
+
+// run executes fn with panic recovery and the deadline. diagnostic
+// describes the tokens/handle involved for the hang log.
+func (w *Watchdog) run(name, diagnostic string, fn func()) error {
+	if !w.Healthy() {
+		return ErrEngineUnhealthy
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- &Error{Code: ErrorCallPanic, Message: fmt.Sprintf("%s panicked: %v", name, r)}
+			}
+		}()
+		fn()
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			slog.Error("MLX call panicked", "call", name, "diagnostic", diagnostic, "error", err)
+		}
+		return err
+	case <-time.After(w.deadline):
+		diag := fmt.Sprintf("%s: %s", name, diagnostic)
+		w.markUnhealthy(diag)
+		slog.Error("MLX call exceeded deadline, marking engine unhealthy",
+			"call", name, "diagnostic", diagnostic, "deadline", w.deadline)
+		return &Error{Code: ErrorCallTimeout, Message: diag}
+	}
+}
+
+// engineCalls is the method set the watchdog guards; both the real and
+// mock engines satisfy it
+type engineCalls interface {
+	ForwardWithCache(model any, tokens []uint32, baseHandle uint64) ([]float32, uint64, error)
+	SliceCache(handle uint64, keepTokens int) (uint64, error)
+	FreeCache(handle uint64)
+}
+
+// GuardedEngine wraps an engine so every CGO call goes through the
+// watchdog
+type GuardedEngine struct {
+	inner engineCalls
+	dog   *Watchdog
+}
+
+// NewGuardedEngine wraps inner with a watchdog using the deadline
+// (zero uses DefaultCallDeadline)
+func NewGuardedEngine(inner engineCalls, deadline time.Duration) *GuardedEngine {
+	return &GuardedEngine{inner: inner, dog: NewWatchdog(deadline)}
+}
+
+// Healthy reports whether the underlying engine is still usable
+func (e *GuardedEngine) Healthy() bool {
+	return e.dog.Healthy()
+}
+
+// Diagnostic returns the hang context once unhealthy
+func (e *GuardedEngine) Diagnostic() string {
+	return e.dog.Diagnostic()
+}
+
+// ForwardWithCache runs the forward pass under the watchdog
+func (e *GuardedEngine) ForwardWithCache(model any, tokens []uint32, baseHandle uint64) ([]float32, uint64, error) {
+	diag := fmt.Sprintf("tokens=%d base_handle=%d first=%v", len(tokens), baseHandle, headTokens(tokens, 8))
+
+	var logits []float32
+	var handle uint64
+	var callErr error
+	err := e.dog.run("ForwardWithCache", diag, func() {
+		logits, handle, callErr = e.inner.ForwardWithCache(model, tokens, baseHandle)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return logits, handle, callErr
+}
+
+// SliceCache runs the cache slice under the watchdog
+func (e *GuardedEngine) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	diag := fmt.Sprintf("handle=%d keep_tokens=%d", handle, keepTokens)
+
+	var sliced uint64
+	var callErr error
+	err := e.dog.run("SliceCache", diag, func() {
+		sliced, callErr = e.inner.SliceCache(handle, keepTokens)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sliced, callErr
+}
+
+// FreeCache runs the free under the watchdog; errors are logged only
+// since frees have no caller to report to
+func (e *GuardedEngine) FreeCache(handle uint64) {
+	e.dog.run("FreeCache", fmt.Sprintf("handle=%d", handle), func() {
+		e.inner.FreeCache(handle)
+	})
+}
+
+// headTokens returns up to n leading tokens for diagnostics
+func headTokens(tokens []uint32, n int) []uint32 {
+	if len(tokens) <= n {
+		return tokens
+	}
+	return tokens[:n]
+}
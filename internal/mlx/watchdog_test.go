@@ -0,0 +1,134 @@
+package mlx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agenthands/GUI-Actor/internal/radix"
+)
+
+func TestGuardedEnginePassthrough(t *testing.T) {
+	inner := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return []float32{1, 2}, base + 1, nil
+		},
+		SliceFunc: func(handle uint64, keepTokens int) (uint64, error) {
+			return handle + 100, nil
+		},
+	}
+	e := NewGuardedEngine(inner, time.Second)
+
+	logits, handle, err := e.ForwardWithCache(nil, []uint32{1, 2, 3}, 7)
+	if err != nil || handle != 8 || len(logits) != 2 {
+		t.Errorf("ForwardWithCache = (%v, %d, %v)", logits, handle, err)
+	}
+
+	sliced, err := e.SliceCache(7, 2)
+	if err != nil || sliced != 107 {
+		t.Errorf("SliceCache = (%d, %v)", sliced, err)
+	}
+
+	e.FreeCache(7)
+	if !e.Healthy() {
+		t.Error("Engine unhealthy after successful calls")
+	}
+}
+
+func TestGuardedEngineEngineError(t *testing.T) {
+	inner := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return nil, 0, ErrOutOfMemory
+		},
+	}
+	e := NewGuardedEngine(inner, time.Second)
+
+	_, _, err := e.ForwardWithCache(nil, []uint32{1}, 0)
+	if !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("err = %v, want ErrOutOfMemory", err)
+	}
+	// Plain engine errors don't mark the engine unhealthy
+	if !e.Healthy() {
+		t.Error("Engine unhealthy after ordinary error")
+	}
+}
+
+func TestGuardedEngineTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	inner := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			<-block // Simulate a hung Metal command buffer
+			return nil, 0, nil
+		},
+	}
+	e := NewGuardedEngine(inner, 30*time.Millisecond)
+
+	start := time.Now()
+	_, _, err := e.ForwardWithCache(nil, []uint32{42, 43}, 5)
+	if !errors.Is(err, ErrCallTimeout) {
+		t.Fatalf("err = %v, want ErrCallTimeout", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("Call did not fail fast at deadline")
+	}
+
+	if e.Healthy() {
+		t.Error("Engine still healthy after hang")
+	}
+	diag := e.Diagnostic()
+	if !strings.Contains(diag, "tokens=2") || !strings.Contains(diag, "base_handle=5") {
+		t.Errorf("Diagnostic missing call context: %q", diag)
+	}
+
+	// Subsequent calls fail fast without touching the engine
+	_, _, err = e.ForwardWithCache(nil, []uint32{1}, 0)
+	if !errors.Is(err, ErrEngineUnhealthy) {
+		t.Errorf("Post-hang err = %v, want ErrEngineUnhealthy", err)
+	}
+	if _, err := e.SliceCache(1, 1); !errors.Is(err, ErrEngineUnhealthy) {
+		t.Errorf("Post-hang SliceCache err = %v, want ErrEngineUnhealthy", err)
+	}
+}
+
+func TestGuardedEnginePanicRecovery(t *testing.T) {
+	inner := &radix.MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			panic("segfault in bridge")
+		},
+	}
+	e := NewGuardedEngine(inner, time.Second)
+
+	_, _, err := e.ForwardWithCache(nil, []uint32{1}, 0)
+	if !errors.Is(err, ErrCallPanic) {
+		t.Fatalf("err = %v, want ErrCallPanic", err)
+	}
+	if !strings.Contains(err.Error(), "segfault in bridge") {
+		t.Errorf("Panic value lost: %v", err)
+	}
+	// A recovered panic leaves the engine usable
+	if !e.Healthy() {
+		t.Error("Engine unhealthy after recovered panic")
+	}
+}
+
+func TestWatchdogDefaults(t *testing.T) {
+	w := NewWatchdog(0)
+	if w.deadline != DefaultCallDeadline {
+		t.Errorf("deadline = %v, want %v", w.deadline, DefaultCallDeadline)
+	}
+	if !w.Healthy() {
+		t.Error("New watchdog not healthy")
+	}
+}
+
+func TestHeadTokens(t *testing.T) {
+	tokens := []uint32{1, 2, 3, 4, 5}
+	if got := headTokens(tokens, 3); len(got) != 3 || got[2] != 3 {
+		t.Errorf("headTokens = %v", got)
+	}
+	if got := headTokens(tokens, 10); len(got) != 5 {
+		t.Errorf("headTokens = %v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package radix
+
+import "sync"
+
+// DefaultArenaSlab is how many nodes one slab allocation holds
+const DefaultArenaSlab = 1024
+
+// NodeArena is a slab allocator for tree nodes. At 100k+ cached
+// prefixes, one heap object per node gives the garbage collector that
+// many pointers to trace every cycle; slab allocation turns them into
+// a few large blocks. Slabs are never reused while any node in them is
+// reachable — reclamation stays with the GC, so a stale *Node can go
+// stale but never dangle.
+type NodeArena struct {
+	mu    sync.Mutex
+	slab  []Node
+	next  int
+	slabs int64
+}
+
+// NewNodeArena creates an empty arena
+func NewNodeArena() *NodeArena {
+	return &NodeArena{}
+}
+
+// New allocates a pending node from the current slab, starting a fresh
+// slab when full.
+func (a *NodeArena) New(tokens []uint32, parent *Node) *Node {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.next == len(a.slab) {
+		a.slab = make([]Node, DefaultArenaSlab)
+		a.next = 0
+		a.slabs++
+	}
+	n := &a.slab[a.next]
+	a.next++
+
+	n.Tokens = tokens
+	n.Parent = parent
+	n.ready = make(chan struct{})
+	return n
+}
+
+// Slabs returns how many slab blocks have been allocated
+func (a *NodeArena) Slabs() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.slabs
+}
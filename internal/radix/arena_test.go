@@ -0,0 +1,128 @@
+package radix
+
+import (
+	"testing"
+)
+
+// newArenaTree is NewTree with slab allocation enabled
+func newArenaTree() *Tree {
+	tree := NewTree()
+	tree.SetArena(NewNodeArena())
+	return tree
+}
+
+func TestArenaTreeBehavesLikeHeapTree(t *testing.T) {
+	tree := newArenaTree()
+	engine := &MockMLXEngine{}
+
+	sequences := [][]uint32{{1, 2, 3}, {1, 2, 3, 4}, {5, 6}}
+	for i, seq := range sequences {
+		node, err := tree.InsertPending(seq, engine, "m")
+		if err != nil {
+			t.Fatalf("InsertPending(%v) failed: %v", seq, err)
+		}
+		FinalizeNode(node, uint64(i)+1)
+		tree.Unpin(node)
+	}
+
+	for i, seq := range sequences {
+		node := tree.Match(seq)
+		if node == nil {
+			t.Fatalf("Match(%v) = nil after insert", seq)
+		}
+		if node.CacheHandle != uint64(i)+1 {
+			t.Errorf("Match(%v) handle = %d, want %d", seq, node.CacheHandle, i+1)
+		}
+	}
+	if tree.Match([]uint32{7, 7}) != nil {
+		t.Error("Match found a sequence that was never inserted")
+	}
+
+	// Eviction works on arena nodes too
+	if !tree.EvictOldest(engine) {
+		t.Error("EvictOldest found nothing to evict")
+	}
+}
+
+func TestArenaLeavesHaveNoChildStorage(t *testing.T) {
+	tree := newArenaTree()
+	node, err := tree.InsertPending([]uint32{1, 2}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 1)
+	tree.Unpin(node)
+
+	if node.Children != nil {
+		t.Error("leaf node allocated child storage")
+	}
+
+	// Attaching a child creates storage on demand
+	child, err := tree.InsertPending([]uint32{1, 2, 3}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(child, 2)
+	tree.Unpin(child)
+	if node.Child(3) != child {
+		t.Error("child not attached to arena node")
+	}
+}
+
+func TestArenaAllocatesSlabs(t *testing.T) {
+	arena := NewNodeArena()
+	for i := 0; i < DefaultArenaSlab+1; i++ {
+		arena.New([]uint32{uint32(i)}, nil)
+	}
+	if arena.Slabs() != 2 {
+		t.Errorf("Slabs = %d after %d nodes, want 2", arena.Slabs(), DefaultArenaSlab+1)
+	}
+}
+
+// benchInsert fills the tree with distinct short sequences, the access
+// pattern that dominates at high node counts
+func benchInsert(b *testing.B, tree *Tree) {
+	b.Helper()
+	engine := &MockMLXEngine{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		seq := []uint32{uint32(i), uint32(i >> 8), 7}
+		node, err := tree.InsertPending(seq, engine, "m")
+		if err != nil {
+			b.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i)+1)
+		tree.Unpin(node)
+	}
+}
+
+func BenchmarkInsertHeap(b *testing.B) {
+	benchInsert(b, NewTree())
+}
+
+func BenchmarkInsertArena(b *testing.B) {
+	benchInsert(b, newArenaTree())
+}
+
+func BenchmarkMatchArena(b *testing.B) {
+	tree := newArenaTree()
+	engine := &MockMLXEngine{}
+	const entries = 100000
+	for i := 0; i < entries; i++ {
+		seq := []uint32{uint32(i), uint32(i >> 8), 7}
+		node, err := tree.InsertPending(seq, engine, "m")
+		if err != nil {
+			b.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i)+1)
+		tree.Unpin(node)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if tree.Match([]uint32{uint32(i % entries), uint32((i % entries) >> 8), 7}) == nil {
+			b.Fatal("Match missed an inserted sequence")
+		}
+	}
+}
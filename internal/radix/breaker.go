@@ -0,0 +1,159 @@
+package radix
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// DefaultBreakerThreshold is the consecutive failure count that trips
+// the breaker
+const DefaultBreakerThreshold = 3
+
+// DefaultBreakerCooldown is how long the breaker fails fast before
+// allowing a trial call
+const DefaultBreakerCooldown = 30 * time.Second
+
+// ErrBreakerOpen is returned while the breaker is failing fast
+var ErrBreakerOpen = errors.New("mlx engine circuit breaker open")
+
+// BreakerOptions configures a Breaker
+type BreakerOptions struct {
+	// Threshold is the consecutive ForwardWithCache failures that trip
+	// the breaker (default DefaultBreakerThreshold)
+	Threshold int
+	// Cooldown is the fail-fast period after tripping (default
+	// DefaultBreakerCooldown)
+	Cooldown time.Duration
+	// OnTrip runs once each time the breaker trips, e.g. to flush the
+	// cache or reduce batch size. Called without the breaker lock held.
+	OnTrip func(err error)
+}
+
+// Breaker wraps an MLXEngine and trips after consecutive forward
+// failures (e.g. Metal OOM), failing fast for a cooldown period so a
+// wedged GPU doesn't stack up doomed requests
+type Breaker struct {
+	inner MLXEngine
+	opts  BreakerOptions
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // a half-open trial call is in flight
+}
+
+// NewBreaker wraps engine with a circuit breaker
+func NewBreaker(inner MLXEngine, opts BreakerOptions) *Breaker {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultBreakerThreshold
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultBreakerCooldown
+	}
+	return &Breaker{inner: inner, opts: opts}
+}
+
+// State returns the current breaker state
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked()
+}
+
+func (b *Breaker) stateLocked() string {
+	if b.openUntil.IsZero() {
+		return BreakerClosed
+	}
+	if time.Now().Before(b.openUntil) {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
+
+// Failures returns the current consecutive failure count
+func (b *Breaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// Allow reports whether a forward call would be admitted right now,
+// without consuming the half-open trial slot
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stateLocked() == BreakerOpen {
+		return ErrBreakerOpen
+	}
+	return nil
+}
+
+// ForwardWithCache delegates to the wrapped engine, tracking
+// consecutive failures. While open it fails fast with ErrBreakerOpen;
+// after the cooldown a single trial call probes the engine.
+func (b *Breaker) ForwardWithCache(model any, tokens []uint32, baseHandle uint64) ([]float32, uint64, error) {
+	b.mu.Lock()
+	switch b.stateLocked() {
+	case BreakerOpen:
+		b.mu.Unlock()
+		return nil, 0, ErrBreakerOpen
+	case BreakerHalfOpen:
+		if b.trial {
+			// Another trial is already probing; keep failing fast
+			b.mu.Unlock()
+			return nil, 0, ErrBreakerOpen
+		}
+		b.trial = true
+	}
+	b.mu.Unlock()
+
+	logits, handle, err := b.inner.ForwardWithCache(model, tokens, baseHandle)
+	b.report(err)
+	return logits, handle, err
+}
+
+// report updates breaker state after a forward call
+func (b *Breaker) report(err error) {
+	b.mu.Lock()
+	b.trial = false
+
+	if err == nil {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.mu.Unlock()
+		return
+	}
+
+	b.failures++
+	tripped := false
+	if b.failures >= b.opts.Threshold || !b.openUntil.IsZero() {
+		// Trip on threshold, or re-open immediately on a failed trial
+		b.openUntil = time.Now().Add(b.opts.Cooldown)
+		tripped = true
+	}
+	onTrip := b.opts.OnTrip
+	b.mu.Unlock()
+
+	if tripped && onTrip != nil {
+		onTrip(err)
+	}
+}
+
+// SliceCache passes through; cache views don't touch the GPU pipeline
+// that trips the breaker
+func (b *Breaker) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	return b.inner.SliceCache(handle, keepTokens)
+}
+
+// FreeCache passes through even while open so memory can be reclaimed
+func (b *Breaker) FreeCache(handle uint64) {
+	b.inner.FreeCache(handle)
+}
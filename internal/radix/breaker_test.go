@@ -0,0 +1,179 @@
+package radix
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errForward = errors.New("metal OOM")
+
+// failingEngine fails ForwardWithCache until healthy is set
+type failingEngine struct {
+	healthy bool
+	calls   int
+}
+
+func (f *failingEngine) ForwardWithCache(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+	f.calls++
+	if !f.healthy {
+		return nil, 0, errForward
+	}
+	return make([]float32, 4), 42, nil
+}
+
+func (f *failingEngine) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	return handle, nil
+}
+
+func (f *failingEngine) FreeCache(handle uint64) {}
+
+func TestBreakerTripsAfterThreshold(t *testing.T) {
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{Threshold: 3, Cooldown: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if b.State() != BreakerClosed {
+			t.Fatalf("State before failure %d = %s, want closed", i, b.State())
+		}
+		if _, _, err := b.ForwardWithCache(nil, []uint32{1}, 0); !errors.Is(err, errForward) {
+			t.Fatalf("Failure %d: err = %v", i, err)
+		}
+	}
+
+	if b.State() != BreakerOpen {
+		t.Fatalf("State after threshold = %s, want open", b.State())
+	}
+	if b.Failures() != 3 {
+		t.Errorf("Failures = %d, want 3", b.Failures())
+	}
+
+	// Open breaker fails fast without touching the engine
+	before := inner.calls
+	if _, _, err := b.ForwardWithCache(nil, []uint32{1}, 0); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("Open call err = %v, want ErrBreakerOpen", err)
+	}
+	if inner.calls != before {
+		t.Error("Open breaker still called the engine")
+	}
+	if err := b.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("Allow = %v, want ErrBreakerOpen", err)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{Threshold: 3, Cooldown: time.Hour})
+
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+
+	inner.healthy = true
+	if _, _, err := b.ForwardWithCache(nil, []uint32{1}, 0); err != nil {
+		t.Fatalf("Healthy call failed: %v", err)
+	}
+	if b.Failures() != 0 {
+		t.Errorf("Failures = %d after success, want 0", b.Failures())
+	}
+
+	// Two more failures must not trip: the streak was broken
+	inner.healthy = false
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	if b.State() != BreakerClosed {
+		t.Errorf("State = %s, want closed", b.State())
+	}
+}
+
+func TestBreakerHalfOpenRecovery(t *testing.T) {
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{Threshold: 1, Cooldown: 20 * time.Millisecond})
+
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	if b.State() != BreakerOpen {
+		t.Fatalf("State = %s, want open", b.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("State after cooldown = %s, want half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow in half-open = %v, want nil", err)
+	}
+
+	// Successful trial closes the breaker
+	inner.healthy = true
+	if _, _, err := b.ForwardWithCache(nil, []uint32{1}, 0); err != nil {
+		t.Fatalf("Trial call failed: %v", err)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State after trial = %s, want closed", b.State())
+	}
+}
+
+func TestBreakerFailedTrialReopens(t *testing.T) {
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{Threshold: 1, Cooldown: 20 * time.Millisecond})
+
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	time.Sleep(30 * time.Millisecond)
+
+	if _, _, err := b.ForwardWithCache(nil, []uint32{1}, 0); !errors.Is(err, errForward) {
+		t.Fatalf("Trial err = %v, want engine error", err)
+	}
+	if b.State() != BreakerOpen {
+		t.Errorf("State after failed trial = %s, want open", b.State())
+	}
+}
+
+func TestBreakerOnTrip(t *testing.T) {
+	var trips int
+	var lastErr error
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{
+		Threshold: 2,
+		Cooldown:  time.Hour,
+		OnTrip: func(err error) {
+			trips++
+			lastErr = err
+		},
+	})
+
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	if trips != 0 {
+		t.Fatalf("Tripped before threshold: %d", trips)
+	}
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+	if trips != 1 {
+		t.Fatalf("Trips = %d, want 1", trips)
+	}
+	if !errors.Is(lastErr, errForward) {
+		t.Errorf("OnTrip err = %v, want engine error", lastErr)
+	}
+}
+
+func TestBreakerCachePassthrough(t *testing.T) {
+	inner := &failingEngine{}
+	b := NewBreaker(inner, BreakerOptions{Threshold: 1, Cooldown: time.Hour})
+
+	// Trip it
+	b.ForwardWithCache(nil, []uint32{1}, 0)
+
+	// Cache operations still work while open
+	handle, err := b.SliceCache(7, 3)
+	if err != nil || handle != 7 {
+		t.Errorf("SliceCache = (%d, %v), want (7, nil)", handle, err)
+	}
+	b.FreeCache(7)
+}
+
+func TestBreakerDefaults(t *testing.T) {
+	b := NewBreaker(&failingEngine{}, BreakerOptions{})
+	if b.opts.Threshold != DefaultBreakerThreshold {
+		t.Errorf("Threshold = %d, want %d", b.opts.Threshold, DefaultBreakerThreshold)
+	}
+	if b.opts.Cooldown != DefaultBreakerCooldown {
+		t.Errorf("Cooldown = %v, want %v", b.opts.Cooldown, DefaultBreakerCooldown)
+	}
+}
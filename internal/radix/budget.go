@@ -0,0 +1,71 @@
+package radix
+
+// BudgetedEngine caps the tokens handed to the inner engine per
+// dispatch: oversized prefills are split into sequential chunks
+// chained through the KV cache, so no single forward pass monopolizes
+// the accelerator and streaming clients keep a bounded inter-token
+// latency.
+type BudgetedEngine struct {
+	inner  MLXEngine
+	budget int
+}
+
+// NewBudgetedEngine wraps an engine with a max-tokens-per-forward
+// budget; a budget <= 0 disables chunking
+func NewBudgetedEngine(inner MLXEngine, maxTokensPerStep int) *BudgetedEngine {
+	return &BudgetedEngine{inner: inner, budget: maxTokensPerStep}
+}
+
+// Budget returns the per-dispatch token cap (0 = unlimited)
+func (b *BudgetedEngine) Budget() int {
+	if b.budget <= 0 {
+		return 0
+	}
+	return b.budget
+}
+
+// ForwardWithCache dispatches the tokens in budget-sized chunks,
+// threading each chunk's cache handle into the next. Intermediate
+// handles are freed; the final handle and logits are returned as if
+// the call had been a single dispatch.
+func (b *BudgetedEngine) ForwardWithCache(model any, tokens []uint32, baseHandle uint64) ([]float32, uint64, error) {
+	if b.budget <= 0 || len(tokens) <= b.budget {
+		return b.inner.ForwardWithCache(model, tokens, baseHandle)
+	}
+
+	handle := baseHandle
+	var logits []float32
+	for start := 0; start < len(tokens); start += b.budget {
+		end := start + b.budget
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+
+		chunkLogits, chunkHandle, err := b.inner.ForwardWithCache(model, tokens[start:end], handle)
+		if err != nil {
+			// Free the partial chain; the caller only ever sees the
+			// original base handle on failure
+			if handle != baseHandle {
+				b.inner.FreeCache(handle)
+			}
+			return nil, 0, err
+		}
+
+		if handle != baseHandle {
+			b.inner.FreeCache(handle)
+		}
+		handle = chunkHandle
+		logits = chunkLogits
+	}
+	return logits, handle, nil
+}
+
+// SliceCache delegates to the inner engine
+func (b *BudgetedEngine) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	return b.inner.SliceCache(handle, keepTokens)
+}
+
+// FreeCache delegates to the inner engine
+func (b *BudgetedEngine) FreeCache(handle uint64) {
+	b.inner.FreeCache(handle)
+}
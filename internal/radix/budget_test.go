@@ -0,0 +1,152 @@
+package radix
+
+import (
+	"errors"
+	"testing"
+)
+
+// recordingEngine captures chunk sizes and base handles per dispatch
+type recordingEngine struct {
+	chunks     [][]uint32
+	bases      []uint64
+	freed      []uint64
+	failOnCall int // 1-based call number that fails (0 = never)
+	calls      int
+	nextHandle uint64
+}
+
+func (r *recordingEngine) ForwardWithCache(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+	r.calls++
+	if r.failOnCall > 0 && r.calls == r.failOnCall {
+		return nil, 0, errors.New("injected forward failure")
+	}
+	chunk := make([]uint32, len(tokens))
+	copy(chunk, tokens)
+	r.chunks = append(r.chunks, chunk)
+	r.bases = append(r.bases, base)
+	r.nextHandle++
+	return []float32{float32(len(tokens))}, r.nextHandle, nil
+}
+
+func (r *recordingEngine) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	return handle, nil
+}
+
+func (r *recordingEngine) FreeCache(handle uint64) {
+	r.freed = append(r.freed, handle)
+}
+
+func TestBudgetedForwardWithinBudget(t *testing.T) {
+	inner := &recordingEngine{}
+	engine := NewBudgetedEngine(inner, 8)
+
+	_, handle, err := engine.ForwardWithCache("model", []uint32{1, 2, 3}, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+	if len(inner.chunks) != 1 {
+		t.Fatalf("dispatched %d chunks, want 1", len(inner.chunks))
+	}
+	if handle != 1 {
+		t.Errorf("handle = %d, want 1", handle)
+	}
+	if len(inner.freed) != 0 {
+		t.Errorf("freed %d handles, want 0", len(inner.freed))
+	}
+}
+
+func TestBudgetedForwardChunksOversized(t *testing.T) {
+	inner := &recordingEngine{}
+	engine := NewBudgetedEngine(inner, 4)
+
+	tokens := []uint32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	logits, handle, err := engine.ForwardWithCache("model", tokens, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+
+	wantChunks := [][]uint32{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10}}
+	if len(inner.chunks) != len(wantChunks) {
+		t.Fatalf("dispatched %d chunks, want %d", len(inner.chunks), len(wantChunks))
+	}
+	for i, want := range wantChunks {
+		if len(inner.chunks[i]) != len(want) {
+			t.Errorf("chunk %d has %d tokens, want %d", i, len(inner.chunks[i]), len(want))
+		}
+	}
+
+	// Each chunk builds on the previous chunk's handle
+	if inner.bases[0] != RootCacheHandle || inner.bases[1] != 1 || inner.bases[2] != 2 {
+		t.Errorf("bases = %v, want [0 1 2]", inner.bases)
+	}
+	// Intermediate handles are freed, final one survives
+	if len(inner.freed) != 2 || inner.freed[0] != 1 || inner.freed[1] != 2 {
+		t.Errorf("freed = %v, want [1 2]", inner.freed)
+	}
+	if handle != 3 {
+		t.Errorf("handle = %d, want 3", handle)
+	}
+	// Logits come from the final chunk
+	if len(logits) != 1 || logits[0] != 2 {
+		t.Errorf("logits = %v, want final chunk's (length 2)", logits)
+	}
+}
+
+func TestBudgetedForwardFailureFreesChain(t *testing.T) {
+	inner := &recordingEngine{failOnCall: 2}
+	engine := NewBudgetedEngine(inner, 4)
+
+	_, _, err := engine.ForwardWithCache("model", []uint32{1, 2, 3, 4, 5, 6}, RootCacheHandle)
+	if err == nil {
+		t.Fatal("ForwardWithCache succeeded, want injected failure")
+	}
+	// The first chunk's intermediate handle must not leak
+	if len(inner.freed) != 1 || inner.freed[0] != 1 {
+		t.Errorf("freed = %v, want [1]", inner.freed)
+	}
+}
+
+func TestBudgetedForwardNeverFreesBase(t *testing.T) {
+	inner := &recordingEngine{failOnCall: 1}
+	engine := NewBudgetedEngine(inner, 4)
+
+	_, _, err := engine.ForwardWithCache("model", []uint32{1, 2, 3, 4, 5, 6}, 42)
+	if err == nil {
+		t.Fatal("ForwardWithCache succeeded, want injected failure")
+	}
+	if len(inner.freed) != 0 {
+		t.Errorf("freed = %v, want none (base handle belongs to the caller)", inner.freed)
+	}
+}
+
+func TestBudgetDisabled(t *testing.T) {
+	inner := &recordingEngine{}
+	engine := NewBudgetedEngine(inner, 0)
+
+	tokens := make([]uint32, 100)
+	if _, _, err := engine.ForwardWithCache("model", tokens, RootCacheHandle); err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+	if len(inner.chunks) != 1 {
+		t.Errorf("dispatched %d chunks, want 1 (budget disabled)", len(inner.chunks))
+	}
+	if engine.Budget() != 0 {
+		t.Errorf("Budget = %d, want 0", engine.Budget())
+	}
+}
+
+func TestBudgetedDelegates(t *testing.T) {
+	inner := &recordingEngine{}
+	engine := NewBudgetedEngine(inner, 4)
+
+	if _, err := engine.SliceCache(7, 3); err != nil {
+		t.Fatalf("SliceCache failed: %v", err)
+	}
+	engine.FreeCache(7)
+	if len(inner.freed) != 1 || inner.freed[0] != 7 {
+		t.Errorf("freed = %v, want [7]", inner.freed)
+	}
+	if engine.Budget() != 4 {
+		t.Errorf("Budget = %d, want 4", engine.Budget())
+	}
+}
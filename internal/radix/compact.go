@@ -0,0 +1,161 @@
+package radix
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCompactInterval spaces background compaction passes
+const DefaultCompactInterval = 30 * time.Second
+
+// Compact merges single-child chains into one node, concatenating the
+// edge tokens and keeping the deepest cache handle. The decode loop
+// appends one token per step, so long generations leave chains of
+// one-token nodes whose map hops slow Match down; compaction restores
+// the fat edges a batch insert would have produced. It returns the
+// number of merges performed.
+// Thread-safe: acquires write lock
+func (t *Tree) Compact(engine MLXEngine) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	merged := 0
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		// Absorb downward repeatedly: a chain of k one-token nodes
+		// collapses in k-1 merges
+		for n != t.Root && len(n.Children) == 1 && t.mergeOnlyChild(n, engine) {
+			merged++
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(t.Root)
+	return merged
+}
+
+// mergeOnlyChild absorbs a node's only child into it, returning false
+// when either side is not safe to touch. Caller holds the lock.
+func (t *Tree) mergeOnlyChild(parent *Node, engine MLXEngine) bool {
+	child := parent.Children[0]
+
+	// Both sides must be settled and unreferenced: pending nodes have
+	// finalizers racing toward them, poisoned nodes are PrunePoisoned's
+	// job, and referenced nodes have live users of their exact handles.
+	// The refCount gate is only sound because every read path that
+	// borrows a handle (generate's base match, the warmer's prefix
+	// match) pins the node for the borrow's duration.
+	if !parent.IsReady() || parent.err != nil || parent.refCount.Load() != 0 {
+		return false
+	}
+	// Never merge past the edge-length bound, or compaction would
+	// undo what SplitLongEdges produced
+	if t.maxNodeTokens > 0 && len(parent.Tokens)+len(child.Tokens) > t.maxNodeTokens {
+		return false
+	}
+	if !child.IsReady() || child.err != nil || child.refCount.Load() != 0 {
+		return false
+	}
+
+	merged := make([]uint32, 0, len(parent.Tokens)+len(child.Tokens))
+	merged = append(merged, parent.Tokens...)
+	merged = append(merged, child.Tokens...)
+
+	// The deeper handle covers the concatenated sequence; the
+	// intermediate one has no remaining exact match and is freed
+	oldHandle := parent.CacheHandle
+	parent.Tokens = merged
+	parent.CacheHandle = child.CacheHandle
+	parent.Children = child.Children
+	for _, grandchild := range parent.Children {
+		grandchild.Parent = parent
+	}
+
+	// The absorbed child leaves the LRU; if the merged node became an
+	// eligible leaf it takes over the slot
+	if child.lruElem != nil {
+		t.lruList.Remove(child.lruElem)
+		child.lruElem = nil
+	}
+	if len(parent.Children) == 0 && parent.lruElem == nil {
+		parent.lruElem = t.lruList.PushFront(parent)
+	}
+
+	if engine != nil && oldHandle != RootCacheHandle && oldHandle != parent.CacheHandle {
+		engine.FreeCache(oldHandle)
+	}
+	return true
+}
+
+// Compactor runs compaction passes in the background, keeping Match
+// traversal cost flat as decode-time chains accumulate
+type Compactor struct {
+	tree   *Tree
+	engine MLXEngine
+
+	merges atomic.Int64
+	splits atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCompactor creates a compactor; it is inert until Start is called
+func NewCompactor(tree *Tree, engine MLXEngine) *Compactor {
+	return &Compactor{tree: tree, engine: engine}
+}
+
+// Merges returns how many chain merges compaction has performed
+func (c *Compactor) Merges() int64 {
+	return c.merges.Load()
+}
+
+// Splits returns how many edge segments SplitLongEdges has created
+func (c *Compactor) Splits() int64 {
+	return c.splits.Load()
+}
+
+// CompactOnce runs a single maintenance pass: merge short chains, then
+// re-split anything over the edge-length bound
+func (c *Compactor) CompactOnce() int {
+	merged := c.tree.Compact(c.engine)
+	c.merges.Add(int64(merged))
+	c.splits.Add(int64(c.tree.SplitLongEdges(c.engine)))
+	return merged
+}
+
+// Start compacts in the background every interval (zero applies the
+// default)
+func (c *Compactor) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCompactInterval
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.CompactOnce()
+			}
+		}
+	}()
+}
+
+// Stop halts background compaction and waits for the loop to exit
+func (c *Compactor) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop = nil
+}
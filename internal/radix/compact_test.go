@@ -0,0 +1,160 @@
+package radix
+
+import (
+	"testing"
+	"time"
+)
+
+// newChainTree builds the single-token chain {1} -> {2} -> ... -> {n}
+// at handles 1..n, all ready and unpinned, the way the decode loop
+// leaves it
+func newChainTree(t *testing.T, n int) *Tree {
+	t.Helper()
+	tree := NewTree()
+	var prefix []uint32
+	for i := 1; i <= n; i++ {
+		prefix = append(prefix, uint32(i))
+		node, err := tree.InsertPending(prefix, &MockMLXEngine{}, "m")
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i))
+		tree.Unpin(node)
+	}
+	return tree
+}
+
+func TestCompactMergesSingleTokenChain(t *testing.T) {
+	tree := newChainTree(t, 5)
+	var freed []uint64
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { freed = append(freed, h) }}
+
+	if merged := tree.Compact(engine); merged != 4 {
+		t.Fatalf("Compact = %d, want 4", merged)
+	}
+
+	// One node now carries the whole sequence with the deepest handle
+	node := tree.Match([]uint32{1, 2, 3, 4, 5})
+	if node == nil || matchedLength(node) != 5 {
+		t.Fatal("full sequence not matchable after compaction")
+	}
+	if len(node.Tokens) != 5 {
+		t.Errorf("merged edge has %d tokens, want 5", len(node.Tokens))
+	}
+	if node.CacheHandle != 5 {
+		t.Errorf("merged handle = %d, want 5 (deepest)", node.CacheHandle)
+	}
+
+	// The intermediate handles were freed
+	if len(freed) != 4 {
+		t.Errorf("freed %d handles, want 4: %v", len(freed), freed)
+	}
+
+	// A second pass finds nothing left to merge
+	if merged := tree.Compact(engine); merged != 0 {
+		t.Errorf("second Compact = %d, want 0", merged)
+	}
+}
+
+func TestCompactPreservesBranches(t *testing.T) {
+	tree := NewTree()
+	for _, seq := range [][]uint32{{1}, {1, 2}, {1, 3}} {
+		node, err := tree.InsertPending(seq, &MockMLXEngine{}, "m")
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(seq[len(seq)-1]))
+		tree.Unpin(node)
+	}
+
+	// {1} has two children; nothing is a single-child chain
+	if merged := tree.Compact(&MockMLXEngine{}); merged != 0 {
+		t.Errorf("Compact = %d across a branch, want 0", merged)
+	}
+	if tree.Match([]uint32{1, 2}) == nil || tree.Match([]uint32{1, 3}) == nil {
+		t.Error("branch lost during compaction")
+	}
+}
+
+func TestCompactSkipsPinnedNodes(t *testing.T) {
+	tree := NewTree()
+	parent, err := tree.InsertPending([]uint32{1}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(parent, 1)
+	tree.Unpin(parent)
+
+	child, err := tree.InsertPending([]uint32{1, 2}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(child, 2)
+	// child stays pinned: a request still holds its exact handle
+
+	if merged := tree.Compact(&MockMLXEngine{}); merged != 0 {
+		t.Errorf("Compact = %d with a pinned child, want 0", merged)
+	}
+	if node := tree.Match([]uint32{1}); node == nil || node.CacheHandle != 1 {
+		t.Error("pinned chain was rewritten")
+	}
+
+	// Once the pin drops the chain merges normally
+	tree.Unpin(child)
+	if merged := tree.Compact(&MockMLXEngine{}); merged != 1 {
+		t.Errorf("Compact = %d after unpin, want 1", merged)
+	}
+}
+
+func TestCompactSkipsPendingNodes(t *testing.T) {
+	tree := newChainTree(t, 2)
+	pending, err := tree.InsertPending([]uint32{1, 2, 3}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	// Not finalized: its prefill is still running
+
+	// {2} -> pending {3} must not merge; {1} -> {2} merges fine
+	if merged := tree.Compact(&MockMLXEngine{}); merged != 1 {
+		t.Errorf("Compact = %d with a pending tail, want 1", merged)
+	}
+	FinalizeNode(pending, 3)
+	tree.Unpin(pending)
+}
+
+func TestCompactedLeafStaysEvictable(t *testing.T) {
+	tree := newChainTree(t, 3)
+	var freed []uint64
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { freed = append(freed, h) }}
+
+	tree.Compact(engine)
+	if !tree.EvictOldest(engine) {
+		t.Fatal("merged leaf not evictable")
+	}
+	if tree.Match([]uint32{1, 2, 3}) != nil {
+		t.Error("merged leaf still cached after eviction")
+	}
+	// Two intermediates from compaction plus the merged handle itself
+	if len(freed) != 3 {
+		t.Errorf("freed %d handles, want 3: %v", len(freed), freed)
+	}
+}
+
+func TestCompactorBackgroundLoop(t *testing.T) {
+	tree := newChainTree(t, 4)
+	compactor := NewCompactor(tree, &MockMLXEngine{})
+
+	compactor.Start(5 * time.Millisecond)
+	defer compactor.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for compactor.Merges() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("background compaction never ran, merges = %d", compactor.Merges())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if node := tree.Match([]uint32{1, 2, 3, 4}); node == nil || len(node.Tokens) != 4 {
+		t.Error("chain not merged by background pass")
+	}
+}
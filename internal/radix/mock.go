@@ -8,6 +8,8 @@ type MockMLXEngine struct {
 	ForwardFunc func(model any, tokens []uint32, base uint64) ([]float32, uint64, error)
 	SliceFunc   func(handle uint64, keepTokens int) (uint64, error)
 	FreeFunc    func(handle uint64)
+	ExportFunc  func(handle uint64) ([]byte, error)
+	ImportFunc  func(data []byte) (uint64, error)
 }
 
 func (m *MockMLXEngine) ForwardWithCache(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
@@ -29,3 +31,17 @@ func (m *MockMLXEngine) FreeCache(handle uint64) {
 		m.FreeFunc(handle)
 	}
 }
+
+func (m *MockMLXEngine) ExportCache(handle uint64) ([]byte, error) {
+	if m.ExportFunc != nil {
+		return m.ExportFunc(handle)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockMLXEngine) ImportCache(data []byte) (uint64, error) {
+	if m.ImportFunc != nil {
+		return m.ImportFunc(data)
+	}
+	return 0, errors.New("not implemented")
+}
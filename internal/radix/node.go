@@ -2,16 +2,34 @@ package radix
 
 import (
 	"container/list"
+	"context"
+	"errors"
+	"sort"
 	"sync/atomic"
+	"time"
 )
 
+// DefaultMaxPendingWait bounds how long a waiter blocks on a pending
+// node whose finalizer may have died
+const DefaultMaxPendingWait = 30 * time.Second
+
+// ErrWaitTimeout is returned (and poisoned into the node) when a
+// pending node is not finalized within the max wait
+var ErrWaitTimeout = errors.New("timed out waiting for pending node")
+
 // Node represents a single node in the Radix prefix tree
 type Node struct {
-	// Tokens is the sequence of tokens at this node edge
+	// Tokens is the sequence of tokens at this node edge.
+	// Owned by the tree: inserts copy caller slices, so Tokens never
+	// aliases a request buffer and must not be mutated by readers.
 	Tokens []uint32
 
-	// Children maps first token to child nodes
-	Children map[uint32]*Node
+	// Children holds child nodes sorted by the first token of their
+	// edge; lookup is a binary search. A sorted slice beats a map at
+	// scale: no per-node hash state for the GC to scan, less memory
+	// per node, and siblings sit adjacent in memory during Match
+	// descent. Nil until the first child is attached.
+	Children []*Node
 
 	// Parent pointer for tree traversal and cascading cleanup
 	Parent *Node
@@ -29,6 +47,10 @@ type Node struct {
 	// Must be incremented before releasing tree lock during ForwardWithCache
 	refCount atomic.Int32
 
+	// done guards ready so Finalize/Poison are idempotent: under
+	// thundering herd two callers can both try to complete the same node
+	done atomic.Bool
+
 	// lruElem points to this node's position in the LRU queue
 	// Nil when node is pinned (refCount > 0) or is internal node
 	lruElem *list.Element
@@ -37,11 +59,54 @@ type Node struct {
 // NewNode creates a pending node that is not yet ready
 func NewNode(tokens []uint32, parent *Node) *Node {
 	return &Node{
-		Tokens:   tokens,
-		Children: make(map[uint32]*Node),
-		Parent:   parent,
-		ready:    make(chan struct{}),
+		Tokens: tokens,
+		Parent: parent,
+		ready:  make(chan struct{}),
+	}
+}
+
+// childIndex locates the child whose edge starts with first; when
+// absent, the returned index is the sorted insertion point
+func (n *Node) childIndex(first uint32) (int, bool) {
+	i := sort.Search(len(n.Children), func(i int) bool {
+		return n.Children[i].Tokens[0] >= first
+	})
+	if i < len(n.Children) && n.Children[i].Tokens[0] == first {
+		return i, true
+	}
+	return i, false
+}
+
+// Child returns the child whose edge starts with first, or nil
+func (n *Node) Child(first uint32) *Node {
+	if i, ok := n.childIndex(first); ok {
+		return n.Children[i]
 	}
+	return nil
+}
+
+// SetChild inserts or replaces the child keyed by its first edge token
+func (n *Node) SetChild(c *Node) {
+	i, ok := n.childIndex(c.Tokens[0])
+	if ok {
+		n.Children[i] = c
+		return
+	}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[i+1:], n.Children[i:])
+	n.Children[i] = c
+}
+
+// RemoveChild detaches the child whose edge starts with first
+func (n *Node) RemoveChild(first uint32) {
+	if i, ok := n.childIndex(first); ok {
+		n.Children = append(n.Children[:i], n.Children[i+1:]...)
+	}
+}
+
+// ChildCount returns the number of attached children
+func (n *Node) ChildCount() int {
+	return len(n.Children)
 }
 
 // Wait blocks until the node is finalized or returns an error immediately if ready
@@ -50,14 +115,51 @@ func (n *Node) Wait() error {
 	return n.err
 }
 
+// WaitCtx blocks like Wait but gives up when ctx is cancelled or after
+// maxWait (<= 0 uses DefaultMaxPendingWait). Hitting the max wait
+// means the finalizer goroutine likely died, so the stuck node is
+// poisoned to fail other waiters fast; the caller should fall back to
+// recomputing from an ancestor's cache handle. Cancellation leaves the
+// node alone: it may still finalize for other requests.
+func (n *Node) WaitCtx(ctx context.Context, maxWait time.Duration) error {
+	if maxWait <= 0 {
+		maxWait = DefaultMaxPendingWait
+	}
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-n.ready:
+		return n.err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		PoisonNode(n, ErrWaitTimeout)
+		return ErrWaitTimeout
+	}
+}
+
 // FinalizeNode marks a pending node as complete and stores the cache handle
-func FinalizeNode(n *Node, handle uint64) {
+// Idempotent: only the first Finalize/Poison takes effect. The return
+// reports whether this call took effect: false means the node was
+// already finalized or poisoned (e.g. a waiter timed out first), the
+// handle was NOT stored, and the caller still owns it — free it or it
+// leaks.
+func FinalizeNode(n *Node, handle uint64) bool {
+	if !n.done.CompareAndSwap(false, true) {
+		return false
+	}
 	n.CacheHandle = handle
 	close(n.ready)
+	return true
 }
 
 // PoisonNode marks a node as failed due to MLX error
+// Idempotent: only the first Finalize/Poison takes effect
 func PoisonNode(n *Node, err error) {
+	if !n.done.CompareAndSwap(false, true) {
+		return
+	}
 	n.err = err
 	close(n.ready)
 }
@@ -1,7 +1,9 @@
 package radix
 
 import (
+	"context"
 	"testing"
+	"time"
 )
 
 func TestNewNode(t *testing.T) {
@@ -23,8 +25,8 @@ func TestNewNode(t *testing.T) {
 		t.Error("Expected ready channel to be initialized")
 	}
 
-	if node.Children == nil {
-		t.Error("Expected Children map to be initialized")
+	if node.ChildCount() != 0 {
+		t.Error("Expected new node to have no children")
 	}
 
 	if node.Parent != nil {
@@ -167,3 +169,67 @@ type TestError string
 func (e TestError) Error() string {
 	return string(e)
 }
+
+func TestWaitCtxReadyNode(t *testing.T) {
+	node := NewNode([]uint32{1}, nil)
+	FinalizeNode(node, 42)
+
+	if err := node.WaitCtx(context.Background(), time.Second); err != nil {
+		t.Errorf("WaitCtx on ready node returned %v", err)
+	}
+}
+
+func TestWaitCtxCancellation(t *testing.T) {
+	node := NewNode([]uint32{1}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := node.WaitCtx(ctx, time.Second); err != context.Canceled {
+		t.Errorf("WaitCtx = %v, want context.Canceled", err)
+	}
+
+	// Cancellation must not poison the node; it can still finalize
+	FinalizeNode(node, 42)
+	if err := node.Wait(); err != nil {
+		t.Errorf("Node poisoned by cancelled waiter: %v", err)
+	}
+}
+
+func TestWaitCtxTimeoutPoisons(t *testing.T) {
+	node := NewNode([]uint32{1}, nil)
+
+	err := node.WaitCtx(context.Background(), 5*time.Millisecond)
+	if err != ErrWaitTimeout {
+		t.Fatalf("WaitCtx = %v, want ErrWaitTimeout", err)
+	}
+
+	// The stuck node is poisoned so other waiters fail fast
+	if err := node.Wait(); err != ErrWaitTimeout {
+		t.Errorf("Wait after timeout = %v, want ErrWaitTimeout", err)
+	}
+	// A late finalizer is a no-op
+	FinalizeNode(node, 42)
+	if node.CacheHandle != 0 {
+		t.Errorf("Late Finalize overwrote poisoned node, handle = %d", node.CacheHandle)
+	}
+}
+
+func TestWaitCtxUnblocksOnFinalize(t *testing.T) {
+	node := NewNode([]uint32{1}, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- node.WaitCtx(context.Background(), time.Second) }()
+
+	time.Sleep(5 * time.Millisecond)
+	FinalizeNode(node, 42)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WaitCtx = %v after Finalize", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitCtx never returned after Finalize")
+	}
+}
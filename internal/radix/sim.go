@@ -0,0 +1,314 @@
+package radix
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrSimOutOfMemory is returned when the simulated memory limit is
+// exceeded (overridable via SimConfig.OOMErr)
+var ErrSimOutOfMemory = errors.New("simulated out of memory")
+
+// LatencyDist describes a per-call latency distribution: Mean plus a
+// uniform jitter in [-Jitter, +Jitter]
+type LatencyDist struct {
+	Mean   time.Duration
+	Jitter time.Duration
+}
+
+// sample draws one latency, never negative
+func (d LatencyDist) sample(rng *rand.Rand) time.Duration {
+	if d.Mean <= 0 && d.Jitter <= 0 {
+		return 0
+	}
+	latency := d.Mean
+	if d.Jitter > 0 {
+		latency += time.Duration(rng.Int63n(int64(2*d.Jitter))) - d.Jitter
+	}
+	if latency < 0 {
+		return 0
+	}
+	return latency
+}
+
+// FailureMode injects an error class at a given probability
+type FailureMode struct {
+	Err  error
+	Rate float64
+}
+
+// SimConfig configures a SimEngine
+type SimConfig struct {
+	// VocabSize is the logits width (default 32000)
+	VocabSize int
+	// ForwardLatency and SliceLatency delay the respective calls
+	ForwardLatency LatencyDist
+	SliceLatency   LatencyDist
+	// Failures are checked in order on each forward call
+	Failures []FailureMode
+	// BytesPerToken and MemoryLimit enable memory accounting; a
+	// forward pass that would exceed the limit fails with OOMErr.
+	// Zero MemoryLimit disables the check.
+	BytesPerToken int64
+	MemoryLimit   int64
+	// OOMErr overrides ErrSimOutOfMemory
+	OOMErr error
+	// Seed makes latency and failure draws reproducible (0 is
+	// time-based)
+	Seed int64
+}
+
+// SimEngine is the configurable superset of MockMLXEngine: a full
+// engine simulator with latency distributions, probabilistic failures
+// by error class, memory accounting, and deterministic fake logits
+// keyed by token hash, so scheduler and cache behavior can be
+// load-tested without Apple hardware.
+type SimEngine struct {
+	cfg SimConfig
+
+	mu          sync.Mutex
+	rng         *rand.Rand
+	used        int64
+	handleBytes map[uint64]int64
+	nextHandle  uint64
+	forwards    int
+	injected    int
+	staged      map[uint64][][]float32
+	nextTicket  uint64
+	consumed    int
+}
+
+// NewSimEngine creates a simulator from the config
+func NewSimEngine(cfg SimConfig) *SimEngine {
+	if cfg.VocabSize <= 0 {
+		cfg.VocabSize = 32000
+	}
+	if cfg.OOMErr == nil {
+		cfg.OOMErr = ErrSimOutOfMemory
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &SimEngine{
+		cfg:         cfg,
+		rng:         rand.New(rand.NewSource(seed)),
+		handleBytes: make(map[uint64]int64),
+	}
+}
+
+// ForwardWithCache simulates a forward pass
+func (s *SimEngine) ForwardWithCache(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+	s.mu.Lock()
+	s.forwards++
+	latency := s.cfg.ForwardLatency.sample(s.rng)
+	var failure error
+	for _, f := range s.cfg.Failures {
+		if s.rng.Float64() < f.Rate {
+			failure = f.Err
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if failure != nil {
+		return nil, 0, failure
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes := int64(len(tokens)) * s.cfg.BytesPerToken
+	if s.cfg.MemoryLimit > 0 && s.used+bytes > s.cfg.MemoryLimit {
+		return nil, 0, s.cfg.OOMErr
+	}
+	s.used += bytes
+	s.nextHandle++
+	handle := s.nextHandle
+	s.handleBytes[handle] = bytes
+
+	return s.fakeLogits(tokens), handle, nil
+}
+
+// SliceCache simulates a zero-copy cache view
+func (s *SimEngine) SliceCache(handle uint64, keepTokens int) (uint64, error) {
+	s.mu.Lock()
+	latency := s.cfg.SliceLatency.sample(s.rng)
+	s.mu.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes := int64(keepTokens) * s.cfg.BytesPerToken
+	if s.cfg.MemoryLimit > 0 && s.used+bytes > s.cfg.MemoryLimit {
+		return 0, s.cfg.OOMErr
+	}
+	s.used += bytes
+	s.nextHandle++
+	s.handleBytes[s.nextHandle] = bytes
+	return s.nextHandle, nil
+}
+
+// FreeCache releases the handle's simulated memory (idempotent)
+func (s *SimEngine) FreeCache(handle uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes, ok := s.handleBytes[handle]; ok {
+		s.used -= bytes
+		delete(s.handleBytes, handle)
+	}
+}
+
+// ExportCache serializes a simulated cache: just its accounted size,
+// which is all the simulator tracks per handle
+func (s *SimEngine) ExportCache(handle uint64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes, ok := s.handleBytes[handle]
+	if !ok {
+		return nil, fmt.Errorf("unknown cache handle %d", handle)
+	}
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, uint64(bytes))
+	return data, nil
+}
+
+// ImportCache re-creates a handle from an exported simulated cache,
+// subject to the same memory accounting as a forward pass
+func (s *SimEngine) ImportCache(data []byte) (uint64, error) {
+	if len(data) != 8 {
+		return 0, fmt.Errorf("malformed exported cache (%d bytes)", len(data))
+	}
+	bytes := int64(binary.LittleEndian.Uint64(data))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MemoryLimit > 0 && s.used+bytes > s.cfg.MemoryLimit {
+		return 0, s.cfg.OOMErr
+	}
+	s.used += bytes
+	s.nextHandle++
+	s.handleBytes[s.nextHandle] = bytes
+	return s.nextHandle, nil
+}
+
+// MemoryUsed returns the currently accounted bytes
+func (s *SimEngine) MemoryUsed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
+
+// Forwards returns the number of forward calls made
+func (s *SimEngine) Forwards() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.forwards
+}
+
+// SimEmbeddingDim is the width of simulated image embeddings
+const SimEmbeddingDim = 64
+
+// EmbedImage produces a deterministic fake embedding keyed by the
+// image hash, standing in for the vision tower
+func (s *SimEngine) EmbedImage(image []byte) ([]float32, error) {
+	h := fnv.New64a()
+	h.Write(image)
+	noise := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	embedding := make([]float32, SimEmbeddingDim)
+	for i := range embedding {
+		embedding[i] = noise.Float32()
+	}
+	return embedding, nil
+}
+
+// InjectEmbeddings stages precomputed vision embeddings in place of
+// re-running the vision tower, returning the ticket that scopes the
+// batch to one forward. The simulator keys batches by ticket so tests
+// can assert that concurrent requests never interleave.
+func (s *SimEngine) InjectEmbeddings(embeddings [][]float32) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected += len(embeddings)
+	s.nextTicket++
+	if s.staged == nil {
+		s.staged = make(map[uint64][][]float32)
+	}
+	s.staged[s.nextTicket] = embeddings
+	return s.nextTicket
+}
+
+// ReleaseEmbeddings discards a staged batch that no forward will
+// consume (idempotent)
+func (s *SimEngine) ReleaseEmbeddings(ticket uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.staged, ticket)
+}
+
+// ForwardWithEmbeddings runs a forward pass that consumes the staged
+// batch identified by ticket; batches staged by other requests stay
+// untouched
+func (s *SimEngine) ForwardWithEmbeddings(model any, tokens []uint32, base uint64, ticket uint64) ([]float32, uint64, error) {
+	s.mu.Lock()
+	if _, ok := s.staged[ticket]; ok {
+		delete(s.staged, ticket)
+		s.consumed++
+	}
+	s.mu.Unlock()
+	return s.ForwardWithCache(model, tokens, base)
+}
+
+// InjectedEmbeddings returns how many embeddings were injected
+func (s *SimEngine) InjectedEmbeddings() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.injected
+}
+
+// StagedBatches returns how many staged batches await a forward
+func (s *SimEngine) StagedBatches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.staged)
+}
+
+// ConsumedBatches returns how many staged batches a forward consumed
+func (s *SimEngine) ConsumedBatches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consumed
+}
+
+// fakeLogits builds deterministic logits keyed by the token hash: the
+// argmax position is hash % vocab, so the same prefix always "samples"
+// the same next token. Caller holds the lock.
+func (s *SimEngine) fakeLogits(tokens []uint32) []float32 {
+	h := fnv.New64a()
+	for _, t := range tokens {
+		h.Write([]byte{byte(t), byte(t >> 8), byte(t >> 16), byte(t >> 24)})
+	}
+	hash := h.Sum64()
+
+	logits := make([]float32, s.cfg.VocabSize)
+	noise := rand.New(rand.NewSource(int64(hash)))
+	for i := range logits {
+		logits[i] = noise.Float32() * 0.1
+	}
+	logits[hash%uint64(s.cfg.VocabSize)] = 1.0
+	return logits
+}
@@ -0,0 +1,178 @@
+package radix
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// argmax returns the index of the largest logit
+func argmax(logits []float32) int {
+	best := 0
+	for i, v := range logits {
+		if v > logits[best] {
+			best = i
+		}
+	}
+	_ = logits[best]
+	return best
+}
+
+func TestSimEngineDeterministicLogits(t *testing.T) {
+	e := NewSimEngine(SimConfig{VocabSize: 1000, Seed: 1})
+
+	tokens := []uint32{10, 20, 30}
+	a, _, err := e.ForwardWithCache(nil, tokens, 0)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	b, _, err := e.ForwardWithCache(nil, tokens, 0)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Logits differ at %d for identical tokens", i)
+		}
+	}
+
+	c, _, _ := e.ForwardWithCache(nil, []uint32{10, 20, 31}, 0)
+	if argmax(a) == argmax(c) {
+		// Not impossible, but with vocab 1000 a collision here almost
+		// certainly means the hash is ignoring the tokens
+		t.Errorf("Different tokens produced the same argmax %d", argmax(a))
+	}
+
+	// A fresh engine with a different seed yields the same logits:
+	// they're keyed by token hash, not the engine RNG
+	e2 := NewSimEngine(SimConfig{VocabSize: 1000, Seed: 99})
+	d, _, _ := e2.ForwardWithCache(nil, tokens, 0)
+	if argmax(a) != argmax(d) {
+		t.Errorf("Logits not stable across engines: %d vs %d", argmax(a), argmax(d))
+	}
+}
+
+func TestSimEngineFailureRates(t *testing.T) {
+	errClass := errors.New("simulated metal fault")
+
+	always := NewSimEngine(SimConfig{Failures: []FailureMode{{Err: errClass, Rate: 1.0}}, Seed: 1})
+	if _, _, err := always.ForwardWithCache(nil, []uint32{1}, 0); !errors.Is(err, errClass) {
+		t.Errorf("Rate 1.0 err = %v, want injected class", err)
+	}
+
+	never := NewSimEngine(SimConfig{Failures: []FailureMode{{Err: errClass, Rate: 0}}, Seed: 1})
+	for i := 0; i < 50; i++ {
+		if _, _, err := never.ForwardWithCache(nil, []uint32{1}, 0); err != nil {
+			t.Fatalf("Rate 0 failed: %v", err)
+		}
+	}
+
+	// Roughly half should fail at rate 0.5
+	half := NewSimEngine(SimConfig{Failures: []FailureMode{{Err: errClass, Rate: 0.5}}, Seed: 7})
+	failures := 0
+	for i := 0; i < 200; i++ {
+		if _, _, err := half.ForwardWithCache(nil, []uint32{1}, 0); err != nil {
+			failures++
+		}
+	}
+	if failures < 60 || failures > 140 {
+		t.Errorf("Failures = %d/200 at rate 0.5", failures)
+	}
+}
+
+func TestSimEngineMemoryAccounting(t *testing.T) {
+	e := NewSimEngine(SimConfig{BytesPerToken: 100, MemoryLimit: 1000, Seed: 1})
+
+	// 8 tokens = 800 bytes
+	_, h1, err := e.ForwardWithCache(nil, make([]uint32, 8), 0)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if e.MemoryUsed() != 800 {
+		t.Errorf("MemoryUsed = %d, want 800", e.MemoryUsed())
+	}
+
+	// Another 8 tokens would exceed the limit
+	if _, _, err := e.ForwardWithCache(nil, make([]uint32, 8), 0); !errors.Is(err, ErrSimOutOfMemory) {
+		t.Errorf("Expected OOM, got: %v", err)
+	}
+
+	// Freeing makes room again
+	e.FreeCache(h1)
+	if e.MemoryUsed() != 0 {
+		t.Errorf("MemoryUsed after free = %d, want 0", e.MemoryUsed())
+	}
+	e.FreeCache(h1) // Idempotent
+	if _, _, err := e.ForwardWithCache(nil, make([]uint32, 8), 0); err != nil {
+		t.Errorf("Forward after free failed: %v", err)
+	}
+}
+
+func TestSimEngineSliceAccounting(t *testing.T) {
+	e := NewSimEngine(SimConfig{BytesPerToken: 10, MemoryLimit: 100, Seed: 1})
+
+	h, err := e.SliceCache(1, 5)
+	if err != nil {
+		t.Fatalf("SliceCache failed: %v", err)
+	}
+	if e.MemoryUsed() != 50 {
+		t.Errorf("MemoryUsed = %d, want 50", e.MemoryUsed())
+	}
+
+	if _, err := e.SliceCache(1, 6); !errors.Is(err, ErrSimOutOfMemory) {
+		t.Errorf("Expected OOM, got: %v", err)
+	}
+	e.FreeCache(h)
+}
+
+func TestSimEngineLatency(t *testing.T) {
+	e := NewSimEngine(SimConfig{
+		ForwardLatency: LatencyDist{Mean: 20 * time.Millisecond, Jitter: 5 * time.Millisecond},
+		Seed:           1,
+	})
+
+	start := time.Now()
+	if _, _, err := e.ForwardWithCache(nil, []uint32{1}, 0); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Call returned in %v, want >= ~15ms", elapsed)
+	}
+}
+
+func TestSimEngineCustomOOMError(t *testing.T) {
+	custom := errors.New("metal buffer exhausted")
+	e := NewSimEngine(SimConfig{BytesPerToken: 1000, MemoryLimit: 1, OOMErr: custom, Seed: 1})
+
+	if _, _, err := e.ForwardWithCache(nil, []uint32{1}, 0); !errors.Is(err, custom) {
+		t.Errorf("err = %v, want custom OOM", err)
+	}
+}
+
+func TestSimEngineConcurrency(t *testing.T) {
+	e := NewSimEngine(SimConfig{BytesPerToken: 1, MemoryLimit: 1 << 20, Seed: 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, h, err := e.ForwardWithCache(nil, []uint32{1, 2}, 0)
+				if err == nil {
+					e.FreeCache(h)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if e.MemoryUsed() != 0 {
+		t.Errorf("MemoryUsed = %d after balanced alloc/free, want 0", e.MemoryUsed())
+	}
+	if e.Forwards() != 400 {
+		t.Errorf("Forwards = %d, want 400", e.Forwards())
+	}
+}
@@ -0,0 +1,120 @@
+package radix
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+)
+
+// CacheExporter is implemented by engines whose KV caches can be
+// serialized for a secondary tier and re-imported on demand
+type CacheExporter interface {
+	// ExportCache serializes the KV tensors behind a handle
+	ExportCache(handle uint64) ([]byte, error)
+
+	// ImportCache deserializes previously exported KV tensors into a
+	// fresh cache handle
+	ImportCache(data []byte) (uint64, error)
+}
+
+// SpillStore is the storage behind a spill tier: local disk, a peer
+// node over the network, or anything else with put/get semantics
+type SpillStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) error
+}
+
+// DiskSpillStore keeps spilled caches as files in a directory
+type DiskSpillStore struct {
+	dir string
+}
+
+// NewDiskSpillStore creates the spill directory if needed
+func NewDiskSpillStore(dir string) (*DiskSpillStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	return &DiskSpillStore{dir: dir}, nil
+}
+
+func (d *DiskSpillStore) path(key string) string {
+	return filepath.Join(d.dir, key+".kv")
+}
+
+func (d *DiskSpillStore) Put(key string, data []byte) error {
+	return os.WriteFile(d.path(key), data, 0o644)
+}
+
+func (d *DiskSpillStore) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(d.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (d *DiskSpillStore) Delete(key string) error {
+	err := os.Remove(d.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// SpillTier pairs a store with an exporting engine: evicted prefixes
+// are spilled instead of discarded, and re-imported on a later miss,
+// trading disk or network latency for avoided recompute of huge
+// multimodal prefills
+type SpillTier struct {
+	store  SpillStore
+	engine CacheExporter
+}
+
+// NewSpillTier creates a spill tier over the store
+func NewSpillTier(store SpillStore, engine CacheExporter) *SpillTier {
+	return &SpillTier{store: store, engine: engine}
+}
+
+// spillKey digests the full token sequence into a storage key
+func spillKey(tokens []uint32) string {
+	h := fnv.New64a()
+	for _, t := range tokens {
+		h.Write([]byte{byte(t), byte(t >> 8), byte(t >> 16), byte(t >> 24)})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Spill exports the handle's KV tensors and writes them to the store
+func (s *SpillTier) Spill(tokens []uint32, handle uint64) error {
+	data, err := s.engine.ExportCache(handle)
+	if err != nil {
+		return fmt.Errorf("failed to export cache: %w", err)
+	}
+	return s.store.Put(spillKey(tokens), data)
+}
+
+// Restore re-imports the exact token sequence's KV tensors, returning
+// a fresh cache handle. The spilled copy is kept for future restores.
+func (s *SpillTier) Restore(tokens []uint32) (uint64, bool, error) {
+	data, found, err := s.store.Get(spillKey(tokens))
+	if err != nil || !found {
+		return 0, false, err
+	}
+
+	handle, err := s.engine.ImportCache(data)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to import cache: %w", err)
+	}
+	return handle, true, nil
+}
+
+// Drop removes the spilled copy of a token sequence
+func (s *SpillTier) Drop(tokens []uint32) error {
+	return s.store.Delete(spillKey(tokens))
+}
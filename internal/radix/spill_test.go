@@ -0,0 +1,205 @@
+package radix
+
+import (
+	"testing"
+	"time"
+)
+
+func newDiskTier(t *testing.T, engine CacheExporter) *SpillTier {
+	t.Helper()
+	store, err := NewDiskSpillStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskSpillStore failed: %v", err)
+	}
+	return NewSpillTier(store, engine)
+}
+
+func TestDiskSpillStoreRoundTrip(t *testing.T) {
+	store, err := NewDiskSpillStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskSpillStore failed: %v", err)
+	}
+
+	data := []byte{1, 2, 3, 4}
+	if err := store.Put("abc", data); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, found, err := store.Get("abc")
+	if err != nil || !found {
+		t.Fatalf("Get = (found=%v, err=%v), want found", found, err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("Get = %v, want %v", got, data)
+	}
+
+	if err := store.Delete("abc"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, found, _ := store.Get("abc"); found {
+		t.Error("Get found a deleted key")
+	}
+
+	// Deleting a missing key is not an error
+	if err := store.Delete("missing"); err != nil {
+		t.Errorf("Delete(missing) = %v, want nil", err)
+	}
+}
+
+func TestSpillTierRoundTrip(t *testing.T) {
+	engine := NewSimEngine(SimConfig{BytesPerToken: 16})
+	tier := newDiskTier(t, engine)
+
+	tokens := []uint32{10, 20, 30, 40}
+	_, handle, err := engine.ForwardWithCache("model", tokens, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+
+	if err := tier.Spill(tokens, handle); err != nil {
+		t.Fatalf("Spill failed: %v", err)
+	}
+	engine.FreeCache(handle)
+
+	restored, found, err := tier.Restore(tokens)
+	if err != nil || !found {
+		t.Fatalf("Restore = (found=%v, err=%v), want found", found, err)
+	}
+	if restored == handle {
+		t.Error("Restore returned the freed handle instead of a fresh one")
+	}
+	if engine.MemoryUsed() != 64 {
+		t.Errorf("MemoryUsed after restore = %d, want 64", engine.MemoryUsed())
+	}
+
+	// Restore on a never-spilled sequence misses cleanly
+	if _, found, err := tier.Restore([]uint32{99}); found || err != nil {
+		t.Errorf("Restore(unspilled) = (found=%v, err=%v), want miss", found, err)
+	}
+
+	if err := tier.Drop(tokens); err != nil {
+		t.Fatalf("Drop failed: %v", err)
+	}
+	if _, found, _ := tier.Restore(tokens); found {
+		t.Error("Restore found a dropped sequence")
+	}
+}
+
+func TestSimEngineExportImport(t *testing.T) {
+	engine := NewSimEngine(SimConfig{BytesPerToken: 8})
+
+	_, handle, err := engine.ForwardWithCache("model", []uint32{1, 2, 3}, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+
+	data, err := engine.ExportCache(handle)
+	if err != nil {
+		t.Fatalf("ExportCache failed: %v", err)
+	}
+
+	if _, err := engine.ExportCache(9999); err == nil {
+		t.Error("ExportCache succeeded on an unknown handle, want error")
+	}
+	if _, err := engine.ImportCache([]byte{1}); err == nil {
+		t.Error("ImportCache succeeded on malformed data, want error")
+	}
+
+	imported, err := engine.ImportCache(data)
+	if err != nil {
+		t.Fatalf("ImportCache failed: %v", err)
+	}
+	if engine.MemoryUsed() != 48 {
+		t.Errorf("MemoryUsed = %d, want 48 (original + import)", engine.MemoryUsed())
+	}
+	engine.FreeCache(imported)
+	engine.FreeCache(handle)
+	if engine.MemoryUsed() != 0 {
+		t.Errorf("MemoryUsed after frees = %d, want 0", engine.MemoryUsed())
+	}
+}
+
+func TestImportCacheRespectsMemoryLimit(t *testing.T) {
+	engine := NewSimEngine(SimConfig{BytesPerToken: 8, MemoryLimit: 40})
+
+	_, handle, err := engine.ForwardWithCache("model", []uint32{1, 2, 3}, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+	data, err := engine.ExportCache(handle)
+	if err != nil {
+		t.Fatalf("ExportCache failed: %v", err)
+	}
+
+	// 24 used + 24 imported would exceed the 40-byte limit
+	if _, err := engine.ImportCache(data); err != ErrSimOutOfMemory {
+		t.Errorf("ImportCache = %v, want ErrSimOutOfMemory", err)
+	}
+}
+
+func TestEvictionSpillsAndRestores(t *testing.T) {
+	engine := NewSimEngine(SimConfig{BytesPerToken: 8})
+	tree := NewTree()
+	tier := newDiskTier(t, engine)
+	tree.SetSpillTier(tier)
+
+	tokens := []uint32{5, 6, 7, 8}
+	node, err := tree.InsertPending(tokens, engine, "model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	_, handle, err := engine.ForwardWithCache("model", tokens, RootCacheHandle)
+	if err != nil {
+		t.Fatalf("ForwardWithCache failed: %v", err)
+	}
+	FinalizeNode(node, handle)
+	tree.Unpin(node)
+
+	// Eviction spills the prefix before removing it from the tree
+	tree.EvictLRU(1)
+	if tree.Match(tokens) != nil {
+		t.Fatal("Node still matched after eviction")
+	}
+
+	restored, ok := tree.RestoreSpilled(tokens)
+	if !ok {
+		t.Fatal("RestoreSpilled missed an evicted prefix")
+	}
+	if restored == RootCacheHandle {
+		t.Error("RestoreSpilled returned the root handle")
+	}
+}
+
+func TestRestoreSpilledWithoutTier(t *testing.T) {
+	tree := NewTree()
+	if _, ok := tree.RestoreSpilled([]uint32{1, 2, 3}); ok {
+		t.Error("RestoreSpilled hit without a spill tier configured")
+	}
+}
+
+func TestPathTokens(t *testing.T) {
+	tree := NewTree()
+	engine := NewSimEngine(SimConfig{ForwardLatency: LatencyDist{Mean: time.Millisecond}})
+
+	parent, err := tree.InsertPending([]uint32{1, 2}, engine, "model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(parent, 10)
+
+	child, err := tree.InsertPending([]uint32{1, 2, 3, 4}, engine, "model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+
+	got := tree.pathTokens(child)
+	want := []uint32{1, 2, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("pathTokens length = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pathTokens[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package radix
+
+// SplitLongEdges cuts every ready edge longer than the configured
+// maximum into segments of at most that many tokens. The bulk insert
+// path stores a whole generation as one edge, so a request sharing only
+// part of it matches nothing; after splitting, each segment boundary is
+// a reusable prefix backed by a zero-copy SliceCache view. The deepest
+// segment keeps the node's identity and handle, so pinned callers are
+// unaffected. Returns the number of segments created.
+// Thread-safe: acquires write lock
+func (t *Tree) SplitLongEdges(engine MLXEngine) int {
+	if engine == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	max := t.maxNodeTokens
+	if max <= 0 {
+		return 0
+	}
+
+	// Collect first: splitting inserts ancestors above a node, which
+	// would confuse an in-flight traversal
+	var long []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n != t.Root && n.IsReady() && n.err == nil && len(n.Tokens) > max {
+			long = append(long, n)
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(t.Root)
+
+	created := 0
+	for _, n := range long {
+		created += t.splitEdge(n, engine, max)
+	}
+	return created
+}
+
+// splitEdge rewrites one long edge as a chain of bounded segments.
+// Caller holds the write lock.
+func (t *Tree) splitEdge(n *Node, engine MLXEngine, max int) int {
+	tokens := n.Tokens
+	segments := (len(tokens) - 1) / max // intermediates; the remainder stays on n
+
+	// The handle at each boundary is a view of the deepest cache
+	// truncated to the boundary's full-path length. Resolve them all
+	// before mutating so a slice failure leaves the tree untouched.
+	baseLen := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		baseLen += len(p.Tokens)
+	}
+	handles := make([]uint64, segments)
+	for i := 0; i < segments; i++ {
+		handle, err := engine.SliceCache(n.CacheHandle, baseLen+(i+1)*max)
+		if err != nil {
+			for _, h := range handles[:i] {
+				engine.FreeCache(h)
+			}
+			return 0
+		}
+		handles[i] = handle
+	}
+
+	parent := n.Parent
+	parent.RemoveChild(tokens[0])
+
+	current := parent
+	for i := 0; i < segments; i++ {
+		segment := t.newNode(tokens[i*max:(i+1)*max], current)
+		FinalizeNode(segment, handles[i])
+		current.SetChild(segment)
+		current = segment
+	}
+
+	// The original node becomes the deepest segment, keeping its
+	// handle, pins, children, and LRU slot
+	n.Tokens = tokens[segments*max:]
+	n.Parent = current
+	current.SetChild(n)
+	return segments
+}
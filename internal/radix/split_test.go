@@ -0,0 +1,149 @@
+package radix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMinPrefixTokensSkipsShortInserts(t *testing.T) {
+	tree := NewTree()
+	tree.SetMinPrefixTokens(3)
+	engine := &MockMLXEngine{}
+
+	if _, err := tree.InsertPending([]uint32{1, 2}, engine, "m"); !errors.Is(err, ErrPrefixTooShort) {
+		t.Fatalf("InsertPending short sequence: err = %v, want ErrPrefixTooShort", err)
+	}
+	if got := tree.NodeCount(); got != 0 {
+		t.Errorf("NodeCount = %d after rejected insert, want 0", got)
+	}
+
+	node, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending at threshold failed: %v", err)
+	}
+	FinalizeNode(node, 1)
+	tree.Unpin(node)
+	if !tree.Contains([]uint32{1, 2, 3}) {
+		t.Error("sequence at the threshold was not cached")
+	}
+}
+
+// sliceByKeep returns handles that encode the keepTokens argument, so
+// tests can tell which boundary each segment's view covers
+func sliceByKeep(handle uint64, keepTokens int) (uint64, error) {
+	return 100 + uint64(keepTokens), nil
+}
+
+func TestSplitLongEdgesBoundsSegments(t *testing.T) {
+	tree := NewTree()
+	tree.SetMaxNodeTokens(2)
+	engine := &MockMLXEngine{SliceFunc: sliceByKeep}
+
+	node, err := tree.InsertPending([]uint32{1, 2, 3, 4, 5}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 9)
+	tree.Unpin(node)
+
+	if created := tree.SplitLongEdges(engine); created != 2 {
+		t.Fatalf("SplitLongEdges created %d segments, want 2", created)
+	}
+
+	// Segment boundaries are now reusable prefixes with sliced views
+	if got := tree.Match([]uint32{1, 2}); got == nil || got.CacheHandle != 102 {
+		t.Errorf("Match at first boundary = %+v, want handle 102", got)
+	}
+	if got := tree.Match([]uint32{1, 2, 3, 4}); got == nil || got.CacheHandle != 104 {
+		t.Errorf("Match at second boundary = %+v, want handle 104", got)
+	}
+	// The full sequence still resolves to the original handle
+	if got := tree.Match([]uint32{1, 2, 3, 4, 5}); got != node || got.CacheHandle != 9 {
+		t.Errorf("Match on full sequence = %+v, want original node with handle 9", got)
+	}
+	if got := tree.NodeCount(); got != 3 {
+		t.Errorf("NodeCount = %d after split, want 3", got)
+	}
+
+	// Idempotent: everything is already within the bound
+	if created := tree.SplitLongEdges(engine); created != 0 {
+		t.Errorf("second SplitLongEdges created %d segments, want 0", created)
+	}
+}
+
+func TestSplitLongEdgesSkipsPendingAndDisabled(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{SliceFunc: sliceByKeep}
+
+	node, err := tree.InsertPending([]uint32{1, 2, 3, 4}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+
+	// No bound configured
+	if created := tree.SplitLongEdges(engine); created != 0 {
+		t.Errorf("SplitLongEdges with no bound created %d segments", created)
+	}
+
+	// Bound set, but the node is still pending
+	tree.SetMaxNodeTokens(2)
+	if created := tree.SplitLongEdges(engine); created != 0 {
+		t.Errorf("SplitLongEdges split a pending node into %d segments", created)
+	}
+
+	FinalizeNode(node, 1)
+	tree.Unpin(node)
+	if created := tree.SplitLongEdges(engine); created != 1 {
+		t.Errorf("SplitLongEdges after finalize created %d segments, want 1", created)
+	}
+}
+
+func TestSplitLongEdgesAbortsOnSliceFailure(t *testing.T) {
+	tree := NewTree()
+	tree.SetMaxNodeTokens(1)
+	var freed []uint64
+	engine := &MockMLXEngine{
+		SliceFunc: func(handle uint64, keepTokens int) (uint64, error) {
+			if keepTokens > 1 {
+				return 0, errors.New("slice failed")
+			}
+			return 100 + uint64(keepTokens), nil
+		},
+		FreeFunc: func(handle uint64) { freed = append(freed, handle) },
+	}
+
+	node, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 9)
+	tree.Unpin(node)
+
+	if created := tree.SplitLongEdges(engine); created != 0 {
+		t.Errorf("SplitLongEdges created %d segments despite slice failure", created)
+	}
+	// The tree is untouched and the one successful view was released
+	if got := tree.Match([]uint32{1, 2, 3}); got != node {
+		t.Error("original edge was modified by the aborted split")
+	}
+	if len(freed) != 1 || freed[0] != 101 {
+		t.Errorf("freed = %v, want [101]", freed)
+	}
+}
+
+func TestCompactRespectsMaxNodeTokens(t *testing.T) {
+	tree := newChainTree(t, 3)
+	tree.SetMaxNodeTokens(2)
+	engine := &MockMLXEngine{}
+
+	if merged := tree.Compact(engine); merged != 1 {
+		t.Fatalf("Compact merged %d chains, want 1", merged)
+	}
+	// {1}+{2} merged; absorbing {3} would exceed the bound
+	if got := tree.Match([]uint32{1, 2}); got == nil {
+		t.Error("merged segment not matchable")
+	}
+	if got := tree.NodeCount(); got != 2 {
+		t.Errorf("NodeCount = %d after bounded compaction, want 2", got)
+	}
+}
@@ -0,0 +1,89 @@
+package radix
+
+// TreeStats aggregates tree state for metrics and admin endpoints
+type TreeStats struct {
+	// Nodes is the number of nodes excluding the root
+	Nodes int `json:"nodes"`
+	// TotalTokens sums the edge lengths of every node
+	TotalTokens int `json:"total_tokens"`
+	// Pinned counts nodes with a nonzero reference count
+	Pinned int `json:"pinned"`
+	// Pending counts nodes whose prefill has not finalized yet
+	Pending int `json:"pending"`
+	// Poisoned counts nodes finalized with an error
+	Poisoned int `json:"poisoned"`
+	// Evictable is the LRU queue length
+	Evictable int `json:"evictable"`
+}
+
+// Walk visits every node except the root in depth-first order while
+// holding the read lock. fn must not modify the tree, pin nodes, or
+// block; it sees a consistent snapshot of the structure.
+func (t *Tree) Walk(fn func(*Node)) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		fn(n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, child := range t.Root.Children {
+		walk(child)
+	}
+}
+
+// Contains reports whether the exact token sequence is cached and
+// ready, without pinning anything. The empty prefix is always cached
+// (it is the root). Intended for tests and tooling; live requests
+// should Match and pin instead, since an unpinned entry can be evicted
+// at any moment.
+func (t *Tree) Contains(tokens []uint32) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.match(tokens)
+	return node != nil && matchedLength(node) == len(tokens)
+}
+
+// LRULen returns the number of nodes queued for eviction
+func (t *Tree) LRULen() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lruList.Len()
+}
+
+// NodeCount returns the number of nodes excluding the root
+func (t *Tree) NodeCount() int {
+	count := 0
+	t.Walk(func(*Node) { count++ })
+	return count
+}
+
+// Stats aggregates node counts in one traversal, for scraping without
+// exposing tree internals
+func (t *Tree) Stats() TreeStats {
+	var stats TreeStats
+	t.Walk(func(n *Node) {
+		stats.Nodes++
+		stats.TotalTokens += len(n.Tokens)
+		if n.refCount.Load() > 0 {
+			stats.Pinned++
+		}
+		if !n.IsReady() {
+			stats.Pending++
+		} else if n.err != nil {
+			stats.Poisoned++
+		}
+	})
+
+	t.mu.RLock()
+	stats.Evictable = t.lruList.Len()
+	t.mu.RUnlock()
+	return stats
+}
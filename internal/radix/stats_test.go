@@ -0,0 +1,177 @@
+package radix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatsCountsNodeStates(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+
+	// Ready, unpinned
+	ready, err := tree.InsertPending([]uint32{1, 2}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(ready, 1)
+	tree.Unpin(ready)
+
+	// Ready, still pinned
+	pinned, err := tree.InsertPending([]uint32{5, 6, 7}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(pinned, 2)
+
+	// Pending
+	pending, err := tree.InsertPending([]uint32{9}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+
+	// Poisoned
+	poisoned, err := tree.InsertPending([]uint32{12, 13}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	PoisonNode(poisoned, errors.New("prefill failed"))
+	tree.Unpin(poisoned)
+
+	stats := tree.Stats()
+	if stats.Nodes != 4 {
+		t.Errorf("Nodes = %d, want 4", stats.Nodes)
+	}
+	if stats.TotalTokens != 8 {
+		t.Errorf("TotalTokens = %d, want 8", stats.TotalTokens)
+	}
+	// pinned node plus the pending node (still holding its insert pin)
+	if stats.Pinned != 2 {
+		t.Errorf("Pinned = %d, want 2", stats.Pinned)
+	}
+	if stats.Pending != 1 {
+		t.Errorf("Pending = %d, want 1", stats.Pending)
+	}
+	if stats.Poisoned != 1 {
+		t.Errorf("Poisoned = %d, want 1", stats.Poisoned)
+	}
+	// ready and poisoned are both unpinned ready leaves
+	if stats.Evictable != 2 {
+		t.Errorf("Evictable = %d, want 2", stats.Evictable)
+	}
+
+	FinalizeNode(pending, 3)
+	tree.Unpin(pending)
+	tree.Unpin(pinned)
+}
+
+func TestStatsEmptyTree(t *testing.T) {
+	stats := NewTree().Stats()
+	if stats != (TreeStats{}) {
+		t.Errorf("Stats on empty tree = %+v, want zero value", stats)
+	}
+}
+
+func TestWalkVisitsEveryNode(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+	sequences := [][]uint32{{1}, {1, 2}, {1, 2, 3}, {8, 9}}
+	for i, seq := range sequences {
+		node, err := tree.InsertPending(seq, engine, "m")
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i)+1)
+		tree.Unpin(node)
+	}
+
+	visited := 0
+	tree.Walk(func(n *Node) {
+		visited++
+		if n == tree.Root {
+			t.Error("Walk visited the root")
+		}
+	})
+	if visited != len(sequences) {
+		t.Errorf("Walk visited %d nodes, want %d", visited, len(sequences))
+	}
+}
+
+func TestContainsExactSequencesOnly(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+	node, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 1)
+	tree.Unpin(node)
+
+	tests := []struct {
+		name   string
+		tokens []uint32
+		want   bool
+	}{
+		{"exact match", []uint32{1, 2, 3}, true},
+		{"empty prefix", nil, true},
+		{"proper prefix without own node", []uint32{1, 2}, false},
+		{"longer than cached", []uint32{1, 2, 3, 4}, false},
+		{"absent", []uint32{9}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tree.Contains(tt.tokens); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsIgnoresPendingNodes(t *testing.T) {
+	tree := NewTree()
+	node, err := tree.InsertPending([]uint32{4, 5}, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	if tree.Contains([]uint32{4, 5}) {
+		t.Error("Contains reported a pending node as cached")
+	}
+	FinalizeNode(node, 1)
+	tree.Unpin(node)
+	if !tree.Contains([]uint32{4, 5}) {
+		t.Error("Contains missed the finalized node")
+	}
+}
+
+func TestNodeCountAndLRULen(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+	for i, seq := range [][]uint32{{1}, {1, 2}, {8}} {
+		node, err := tree.InsertPending(seq, engine, "m")
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i)+1)
+		tree.Unpin(node)
+	}
+
+	if got := tree.NodeCount(); got != 3 {
+		t.Errorf("NodeCount = %d, want 3", got)
+	}
+	// {1} gained a child after entering the LRU; the two leaves follow
+	if got := tree.LRULen(); got < 2 {
+		t.Errorf("LRULen = %d, want at least 2", got)
+	}
+
+	// Evicting a leaf can requeue its newly-childless parent, so drain
+	// in rounds
+	for i := 0; i < 10 && tree.LRULen() > 0; i++ {
+		tree.EvictLRU(tree.LRULen())
+	}
+	if got := tree.LRULen(); got != 0 {
+		t.Errorf("LRULen = %d after full eviction, want 0", got)
+	}
+	if got := tree.NodeCount(); got != 0 {
+		t.Errorf("NodeCount = %d after full eviction, want 0", got)
+	}
+}
@@ -2,9 +2,14 @@ package radix
 
 import (
 	"container/list"
+	"errors"
 	"sync"
 )
 
+// ErrPrefixTooShort is returned by inserts below the configured
+// minimum prefix length; callers should generate without caching
+var ErrPrefixTooShort = errors.New("prefix below minimum caching length")
+
 // Tree represents a Radix prefix tree for KV cache management
 type Tree struct {
 	// Root is the empty root node (no tokens, handle=0)
@@ -17,6 +22,22 @@ type Tree struct {
 	// lruList is the intrusive doubly-linked list for O(1) eviction
 	// Only contains unpinned leaf nodes ready for eviction
 	lruList *list.List
+
+	// spill is the optional secondary cache tier; evicted prefixes
+	// are exported there instead of discarded
+	spill *SpillTier
+
+	// arena optionally slab-allocates nodes to cut GC pressure at
+	// high node counts
+	arena *NodeArena
+
+	// minPrefixTokens rejects inserts below this length; very short
+	// prefixes cost tree bookkeeping for near-zero prefill savings
+	minPrefixTokens int
+
+	// maxNodeTokens bounds edge length: longer edges are split into
+	// segments by SplitLongEdges, and compaction will not merge past it
+	maxNodeTokens int
 }
 
 // NewTree creates an empty Radix tree with initialized root
@@ -50,9 +71,8 @@ func (t *Tree) match(tokens []uint32) *Node {
 
 	for {
 		// Find child whose first token matches current position
-		firstToken := tokens[0]
-		child, exists := current.Children[firstToken]
-		if !exists {
+		child := current.Child(tokens[0])
+		if child == nil {
 			// No more matching children
 			break
 		}
@@ -123,31 +143,58 @@ func longestCommonPrefix(a, b []uint32) int {
 // Thread-safe: uses Lock for tree modification
 // Returns: pending node (with refCount=1) or error
 func (t *Tree) InsertPending(tokens []uint32, engine MLXEngine, model any) (*Node, error) {
+	node, _, err := t.InsertOrJoin(tokens, engine, model)
+	return node, err
+}
+
+// InsertOrJoin is InsertPending plus a creator flag: created is true
+// only for the caller that inserted the pending node and therefore owns
+// running prefill and finalizing it. Joiners (created=false) must Wait
+// on the node and share its handle instead of computing their own.
+// The tokens slice is copied on insert; callers may reuse their buffer
+// as soon as the call returns.
+func (t *Tree) InsertOrJoin(tokens []uint32, engine MLXEngine, model any) (*Node, bool, error) {
 	// OCC retry loop - handles race where another goroutine creates node while we're thinking
 	for {
 		t.mu.Lock()
 
+		if t.minPrefixTokens > 0 && len(tokens) < t.minPrefixTokens {
+			t.mu.Unlock()
+			return nil, false, ErrPrefixTooShort
+		}
+
 		// Double-check: look for existing exact match or pending node
 		existing, remaining := t.findExactOrPending(tokens, t.Root)
 		if existing != nil {
-			// Found existing node - pin it and return (thundering herd)
+			// Found existing node - pin it and return (thundering herd).
+			// A referenced node has no business in the LRU; leave it out
+			// until the last Unpin requeues it.
 			existing.refCount.Add(1)
+			if existing.lruElem != nil {
+				t.lruList.Remove(existing.lruElem)
+				existing.lruElem = nil
+			}
 			t.mu.Unlock()
-			return existing, nil
+			return existing, false, nil
 		}
 
 		// No existing node - create new pending node
 		// Find parent to attach to
 		parent := t.findParentFor(tokens, t.Root)
 
+		// Copy the edge tokens before retaining them: the HTTP layer
+		// reuses request buffers, and the tree keeps Tokens for the
+		// node's whole lifetime
+		owned := make([]uint32, len(remaining))
+		copy(owned, remaining)
+
 		// Create pending node
-		newNode := NewNode(remaining, parent)
+		newNode := t.newNode(owned, parent)
 		newNode.refCount.Add(1) // Pin before releasing lock
 
 		// Attach to tree
 		if len(remaining) > 0 {
-			firstToken := remaining[0]
-			parent.Children[firstToken] = newNode
+			parent.SetChild(newNode)
 		}
 
 		t.mu.Unlock()
@@ -155,7 +202,7 @@ func (t *Tree) InsertPending(tokens []uint32, engine MLXEngine, model any) (*Nod
 		// Double-check: verify we're the winner (OCC pattern)
 		// In production, we'd do heavy computation here with lock released
 		// For now, just return the pending node
-		return newNode, nil
+		return newNode, true, nil
 	}
 }
 
@@ -166,9 +213,8 @@ func (t *Tree) findExactOrPending(tokens []uint32, start *Node) (*Node, []uint32
 	current := start
 
 	for len(tokens) > 0 {
-		firstToken := tokens[0]
-		child, exists := current.Children[firstToken]
-		if !exists {
+		child := current.Child(tokens[0])
+		if child == nil {
 			// No child - this is where we'd insert
 			return nil, tokens
 		}
@@ -206,9 +252,8 @@ func (t *Tree) findParentFor(tokens []uint32, start *Node) *Node {
 	current := start
 
 	for len(tokens) > 0 {
-		firstToken := tokens[0]
-		child, exists := current.Children[firstToken]
-		if !exists {
+		child := current.Child(tokens[0])
+		if child == nil {
 			// No child - current is the parent
 			return current
 		}
@@ -238,6 +283,21 @@ func (t *Tree) findParentFor(tokens []uint32, start *Node) *Node {
 	return current
 }
 
+// Pin takes an additional reference on a node, keeping it resident:
+// pinned nodes never enter the LRU and cannot be evicted. Release with
+// Unpin.
+// Thread-safe: acquires write lock
+func (t *Tree) Pin(node *Node) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node.refCount.Add(1)
+	if node.lruElem != nil {
+		t.lruList.Remove(node.lruElem)
+		node.lruElem = nil
+	}
+}
+
 // Unpin decrements node refCount and adds to LRU if eligible
 // Thread-safe wrapper that acquires lock
 func (t *Tree) Unpin(node *Node) {
@@ -289,11 +349,131 @@ func (t *Tree) EvictLRU(n int) {
 		t.lruList.Remove(elem)
 		node.lruElem = nil
 
+		// Spill to the secondary tier before losing the handle
+		// Best effort: a failed spill just means recompute later
+		if t.spill != nil && node.IsReady() && node.err == nil {
+			_ = t.spill.Spill(t.pathTokens(node), node.CacheHandle)
+		}
+
 		// Remove from tree
 		t.removeNode(node)
 	}
 }
 
+// EvictOldest evicts the oldest unreferenced leaf and frees its cache
+// handle through the engine, actually returning the memory. Referenced
+// nodes are someone's live forward base: they are skipped (and dropped
+// from the queue; Unpin requeues them) rather than freed under the
+// borrower. Returns false when nothing is evictable.
+// Thread-safe: acquires write lock
+func (t *Tree) EvictOldest(engine MLXEngine) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for elem := t.lruList.Back(); elem != nil; {
+		node := elem.Value.(*Node)
+		prev := elem.Prev()
+
+		if node.refCount.Load() > 0 {
+			t.lruList.Remove(elem)
+			node.lruElem = nil
+			elem = prev
+			continue
+		}
+
+		t.lruList.Remove(elem)
+		node.lruElem = nil
+
+		// Spill to the secondary tier before losing the handle
+		// Best effort: a failed spill just means recompute later
+		if t.spill != nil && node.IsReady() && node.err == nil {
+			_ = t.spill.Spill(t.pathTokens(node), node.CacheHandle)
+		}
+
+		t.removeNode(node)
+
+		if engine != nil && node.IsReady() && node.CacheHandle != RootCacheHandle {
+			engine.FreeCache(node.CacheHandle)
+		}
+		return true
+	}
+	return false
+}
+
+// SetMinPrefixTokens rejects inserts shorter than n tokens with
+// ErrPrefixTooShort (0 disables the threshold)
+func (t *Tree) SetMinPrefixTokens(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.minPrefixTokens = n
+}
+
+// SetMaxNodeTokens bounds edge length for SplitLongEdges and stops
+// compaction from merging edges past n tokens (0 disables the bound)
+func (t *Tree) SetMaxNodeTokens(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxNodeTokens = n
+}
+
+// SetArena slab-allocates all nodes inserted from now on
+func (t *Tree) SetArena(a *NodeArena) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.arena = a
+}
+
+// newNode allocates a pending node, from the arena when one is set
+// Caller holds the lock.
+func (t *Tree) newNode(tokens []uint32, parent *Node) *Node {
+	if t.arena != nil {
+		return t.arena.New(tokens, parent)
+	}
+	return NewNode(tokens, parent)
+}
+
+// SetSpillTier enables the secondary cache tier for evicted prefixes
+func (t *Tree) SetSpillTier(spill *SpillTier) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spill = spill
+}
+
+// pathTokens returns the full token sequence from root to node
+// Caller holds the lock.
+func (t *Tree) pathTokens(node *Node) []uint32 {
+	length := 0
+	for n := node; n != nil; n = n.Parent {
+		length += len(n.Tokens)
+	}
+	tokens := make([]uint32, length)
+	pos := length
+	for n := node; n != nil; n = n.Parent {
+		pos -= len(n.Tokens)
+		copy(tokens[pos:], n.Tokens)
+	}
+	return tokens
+}
+
+// RestoreSpilled re-imports a previously evicted prefix from the
+// secondary tier, returning a fresh cache handle for use as a forward
+// base. Returns false when no tier is set or the exact sequence was
+// never spilled.
+func (t *Tree) RestoreSpilled(tokens []uint32) (uint64, bool) {
+	t.mu.RLock()
+	spill := t.spill
+	t.mu.RUnlock()
+	if spill == nil {
+		return 0, false
+	}
+
+	handle, found, err := spill.Restore(tokens)
+	if err != nil || !found {
+		return 0, false
+	}
+	return handle, true
+}
+
 // removeNode removes a node from the tree structure
 // Does NOT free cache handle - caller must do that
 func (t *Tree) removeNode(node *Node) {
@@ -303,11 +483,8 @@ func (t *Tree) removeNode(node *Node) {
 	}
 
 	// Find this node in parent's children
-	for token, child := range node.Parent.Children {
-		if child == node {
-			delete(node.Parent.Children, token)
-			break
-		}
+	if len(node.Tokens) > 0 && node.Parent.Child(node.Tokens[0]) == node {
+		node.Parent.RemoveChild(node.Tokens[0])
 	}
 
 	// Check if parent became leaf without children and is unpinned
@@ -319,6 +496,32 @@ func (t *Tree) removeNode(node *Node) {
 	}
 }
 
+// CachedPrefixes returns the full token sequence of every ready cached
+// prefix at least minTokens long, for cross-node cache advertisement
+// Thread-safe: uses RLock for concurrent reads
+func (t *Tree) CachedPrefixes(minTokens int) [][]uint32 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var prefixes [][]uint32
+	var walk func(node *Node, path []uint32)
+	walk = func(node *Node, path []uint32) {
+		path = append(path, node.Tokens...)
+		if node.IsReady() && node.err == nil && len(path) >= minTokens {
+			prefix := make([]uint32, len(path))
+			copy(prefix, path)
+			prefixes = append(prefixes, prefix)
+		}
+		for _, child := range node.Children {
+			walk(child, path)
+		}
+	}
+	for _, child := range t.Root.Children {
+		walk(child, nil)
+	}
+	return prefixes
+}
+
 // PrunePoisoned removes all poisoned nodes from the tree
 // This is cascading - children of poisoned nodes are also removed
 // Thread-safe: acquires write lock
@@ -336,18 +539,14 @@ func (t *Tree) prunePoisonedRecursive(node *Node) bool {
 		return false
 	}
 
-	// First, recurse into children
-	var toRemove []uint32
-	for token, child := range node.Children {
-		if t.prunePoisonedRecursive(child) {
-			toRemove = append(toRemove, token)
+	// First, recurse into children, keeping survivors in sorted order
+	kept := node.Children[:0]
+	for _, child := range node.Children {
+		if !t.prunePoisonedRecursive(child) {
+			kept = append(kept, child)
 		}
 	}
-
-	// Remove marked children
-	for _, token := range toRemove {
-		delete(node.Children, token)
-	}
+	node.Children = kept
 
 	// Check if this node is poisoned (has error, not just pending)
 	if node.err != nil {
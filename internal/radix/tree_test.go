@@ -32,7 +32,7 @@ func TestMatchExact(t *testing.T) {
 
 	// Build a simple tree: root -> [1,2,3]
 	node := NewNode([]uint32{1, 2, 3}, tree.Root)
-	tree.Root.Children[1] = node
+	tree.Root.SetChild(node)
 	FinalizeNode(node, 100)
 
 	result := tree.Match([]uint32{1, 2, 3})
@@ -51,11 +51,11 @@ func TestMatchPrefix(t *testing.T) {
 
 	// Tree: root -> [1,2,3] -> [4,5]
 	parent := NewNode([]uint32{1, 2, 3}, tree.Root)
-	tree.Root.Children[1] = parent
+	tree.Root.SetChild(parent)
 	FinalizeNode(parent, 100)
 
 	child := NewNode([]uint32{4, 5}, parent)
-	parent.Children[4] = child
+	parent.SetChild(child)
 	FinalizeNode(child, 200)
 
 	// Should match parent node (longest prefix = [1,2,3])
@@ -75,7 +75,7 @@ func TestMatchPartialPrefix(t *testing.T) {
 
 	// Tree: root -> [1,2,3,4,5]
 	node := NewNode([]uint32{1, 2, 3, 4, 5}, tree.Root)
-	tree.Root.Children[1] = node
+	tree.Root.SetChild(node)
 	FinalizeNode(node, 100)
 
 	// Query: [1,2,3,9,9]
@@ -98,15 +98,15 @@ func TestMatchLongestPrefix(t *testing.T) {
 	// Build tree with multiple possible matches:
 	// root -> [1] -> [2] -> [3]
 	n1 := NewNode([]uint32{1}, tree.Root)
-	tree.Root.Children[1] = n1
+	tree.Root.SetChild(n1)
 	FinalizeNode(n1, 100)
 
 	n2 := NewNode([]uint32{2}, n1)
-	n1.Children[2] = n2
+	n1.SetChild(n2)
 	FinalizeNode(n2, 200)
 
 	n3 := NewNode([]uint32{3}, n2)
-	n2.Children[3] = n3
+	n2.SetChild(n3)
 	FinalizeNode(n3, 300)
 
 	// Query: [1,2,3,4]
@@ -127,7 +127,7 @@ func TestMatchQueryIsPrefix(t *testing.T) {
 
 	// Tree: root -> [1,2,3,4,5]
 	node := NewNode([]uint32{1, 2, 3, 4, 5}, tree.Root)
-	tree.Root.Children[1] = node
+	tree.Root.SetChild(node)
 	FinalizeNode(node, 100)
 
 	// Query: [1,2,3]
@@ -148,7 +148,7 @@ func TestMatchConcurrent(t *testing.T) {
 
 	// Build tree
 	node := NewNode([]uint32{1, 2, 3}, tree.Root)
-	tree.Root.Children[1] = node
+	tree.Root.SetChild(node)
 	FinalizeNode(node, 100)
 
 	// Concurrent reads
@@ -837,3 +837,172 @@ func TestMultipleUnpin(t *testing.T) {
 		t.Error("Expected node to be in LRU after fully unpinned")
 	}
 }
+
+func TestCachedPrefixes(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+
+	long := make([]uint32, 40)
+	for i := range long {
+		long[i] = 500 + uint32(i)
+	}
+	node, err := tree.InsertPending(long, engine, "test-model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 100)
+	tree.Unpin(node)
+
+	short, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "test-model")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(short, 101)
+	tree.Unpin(short)
+
+	prefixes := tree.CachedPrefixes(32)
+	if len(prefixes) != 1 {
+		t.Fatalf("CachedPrefixes returned %d prefixes, want 1", len(prefixes))
+	}
+	if len(prefixes[0]) != len(long) {
+		t.Fatalf("Prefix length = %d, want %d", len(prefixes[0]), len(long))
+	}
+	for i, token := range prefixes[0] {
+		if token != long[i] {
+			t.Fatalf("Prefix token %d = %d, want %d", i, token, long[i])
+		}
+	}
+
+	// Lowering the threshold exposes the short prefix too
+	if got := tree.CachedPrefixes(1); len(got) != 2 {
+		t.Errorf("CachedPrefixes(1) returned %d prefixes, want 2", len(got))
+	}
+}
+
+func TestCachedPrefixesSkipsPending(t *testing.T) {
+	tree := NewTree()
+	tokens := make([]uint32, 40)
+	for i := range tokens {
+		tokens[i] = 500 + uint32(i)
+	}
+	if _, err := tree.InsertPending(tokens, &MockMLXEngine{}, "test-model"); err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+
+	if got := tree.CachedPrefixes(1); len(got) != 0 {
+		t.Errorf("CachedPrefixes returned %d prefixes for a pending node, want 0", len(got))
+	}
+}
+
+func TestInsertOrJoinReportsCreator(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+	tokens := []uint32{1, 2, 3}
+
+	first, created, err := tree.InsertOrJoin(tokens, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertOrJoin failed: %v", err)
+	}
+	if !created {
+		t.Error("First inserter not reported as creator")
+	}
+
+	// A second identical request joins the pending node
+	second, created, err := tree.InsertOrJoin(tokens, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertOrJoin failed: %v", err)
+	}
+	if created {
+		t.Error("Joiner reported as creator while node pending")
+	}
+	if second != first {
+		t.Error("Joiner got a different node than the creator")
+	}
+
+	// After finalization joiners still share the same node
+	FinalizeNode(first, 42)
+	third, created, err := tree.InsertOrJoin(tokens, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertOrJoin failed: %v", err)
+	}
+	if created || third != first {
+		t.Error("Ready node not shared with later identical request")
+	}
+	tree.Unpin(first)
+	tree.Unpin(second)
+	tree.Unpin(third)
+}
+
+func TestInsertPendingCopiesCallerBuffer(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+
+	buf := []uint32{1, 2, 3}
+	node, err := tree.InsertPending(buf, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 7)
+	tree.Unpin(node)
+
+	// The HTTP layer reuses its request buffer for the next request
+	buf[0], buf[1], buf[2] = 9, 9, 9
+
+	if got := tree.Match([]uint32{1, 2, 3}); got == nil || got.CacheHandle != 7 {
+		t.Error("cached entry corrupted by caller buffer reuse")
+	}
+	if tree.Match([]uint32{9, 9, 9}) != nil {
+		t.Error("mutated caller buffer visible inside the tree")
+	}
+}
+
+func TestEvictOldestSkipsReferencedNodes(t *testing.T) {
+	tree := NewTree()
+	engine := &MockMLXEngine{}
+	var freed []uint64
+	engine.FreeFunc = func(h uint64) { freed = append(freed, h) }
+
+	older, err := tree.InsertPending([]uint32{1, 2}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(older, 10)
+	tree.Unpin(older)
+	newer, err := tree.InsertPending([]uint32{5, 6}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(newer, 11)
+	tree.Unpin(newer)
+
+	// A join takes a reference on the oldest entry, like a live request
+	// whose forward base it is
+	joined, err := tree.InsertPending([]uint32{1, 2}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+
+	if !tree.EvictOldest(engine) {
+		t.Fatal("EvictOldest found nothing despite an unreferenced leaf")
+	}
+	if tree.Match([]uint32{1, 2}) == nil {
+		t.Fatal("referenced entry evicted; its handle may be a live forward base")
+	}
+	for _, h := range freed {
+		if h == 10 {
+			t.Fatal("referenced entry's handle freed")
+		}
+	}
+	if tree.Match([]uint32{5, 6}) != nil {
+		t.Error("unreferenced entry survived eviction")
+	}
+
+	// With the reference gone the entry becomes evictable again
+	tree.Unpin(joined)
+	if !tree.EvictOldest(engine) {
+		t.Fatal("EvictOldest found nothing after the reference was released")
+	}
+	if tree.Match([]uint32{1, 2}) != nil {
+		t.Error("entry still cached after its last reference was released")
+	}
+}
@@ -0,0 +1,112 @@
+package radix
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTrimInterval spaces background watermark checks
+const DefaultTrimInterval = time.Second
+
+// MemoryReporter exposes current KV cache memory usage in bytes.
+// SimEngine implements it; the real MLX engine reports Metal heap use.
+type MemoryReporter interface {
+	MemoryUsed() int64
+}
+
+// Trimmer evicts cache entries between soft memory watermarks: once
+// usage crosses the high watermark it trims LRU-first down to the low
+// watermark, so allocation headroom exists before a forward pass needs
+// it rather than being reclaimed under an OOM error mid-request.
+type Trimmer struct {
+	tree   *Tree
+	engine MLXEngine
+	mem    MemoryReporter
+	high   int64
+	low    int64
+
+	evicted atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTrimmer creates a trimmer with the given watermarks in bytes; it
+// is inert until Start is called. A low watermark outside (0, high)
+// defaults to half the high watermark.
+func NewTrimmer(tree *Tree, engine MLXEngine, mem MemoryReporter, high, low int64) *Trimmer {
+	if low <= 0 || low >= high {
+		low = high / 2
+	}
+	return &Trimmer{
+		tree:   tree,
+		engine: engine,
+		mem:    mem,
+		high:   high,
+		low:    low,
+	}
+}
+
+// Evicted returns how many nodes watermark trimming has evicted
+func (tr *Trimmer) Evicted() int64 {
+	return tr.evicted.Load()
+}
+
+// TrimOnce checks the high watermark and, when crossed, evicts
+// LRU-first until usage falls to the low watermark or nothing
+// evictable remains. It returns how many nodes were evicted.
+func (tr *Trimmer) TrimOnce() int {
+	if tr.high <= 0 || tr.mem == nil {
+		return 0
+	}
+	if tr.mem.MemoryUsed() <= tr.high {
+		return 0
+	}
+
+	evicted := 0
+	for tr.mem.MemoryUsed() > tr.low {
+		if !tr.tree.EvictOldest(tr.engine) {
+			// Everything left is pinned or pending; live requests hold
+			// it and will release in due course
+			break
+		}
+		evicted++
+	}
+	tr.evicted.Add(int64(evicted))
+	return evicted
+}
+
+// Start checks the watermark in the background every interval (zero
+// applies the default)
+func (tr *Trimmer) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultTrimInterval
+	}
+	tr.stop = make(chan struct{})
+	tr.done = make(chan struct{})
+
+	go func() {
+		defer close(tr.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-tr.stop:
+				return
+			case <-ticker.C:
+				tr.TrimOnce()
+			}
+		}
+	}()
+}
+
+// Stop halts background trimming and waits for the loop to exit
+func (tr *Trimmer) Stop() {
+	if tr.stop == nil {
+		return
+	}
+	close(tr.stop)
+	<-tr.done
+	tr.stop = nil
+}
@@ -0,0 +1,164 @@
+package radix
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMemory is a MemoryReporter whose usage drops by perEviction each
+// time the paired engine frees a handle
+type fakeMemory struct {
+	mu          sync.Mutex
+	used        int64
+	perEviction int64
+	freed       []uint64
+}
+
+func (f *fakeMemory) MemoryUsed() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.used
+}
+
+func (f *fakeMemory) free(handle uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.used -= f.perEviction
+	f.freed = append(f.freed, handle)
+}
+
+// newTrimTree builds a tree with n unpinned ready leaves at handles
+// 1..n, oldest first in the LRU
+func newTrimTree(t *testing.T, n int) *Tree {
+	t.Helper()
+	tree := NewTree()
+	for i := 1; i <= n; i++ {
+		// Distinct first tokens keep each entry a separate leaf
+		node, err := tree.InsertPending([]uint32{uint32(100 * i), 7}, &MockMLXEngine{}, "m")
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		FinalizeNode(node, uint64(i))
+		tree.Unpin(node)
+	}
+	return tree
+}
+
+func TestTrimOnceBelowHighWatermarkIsNoop(t *testing.T) {
+	mem := &fakeMemory{used: 500, perEviction: 100}
+	tree := newTrimTree(t, 4)
+	trimmer := NewTrimmer(tree, &MockMLXEngine{}, mem, 1000, 400)
+
+	if evicted := trimmer.TrimOnce(); evicted != 0 {
+		t.Errorf("TrimOnce = %d below the high watermark, want 0", evicted)
+	}
+	if tree.Match([]uint32{100, 7}) == nil {
+		t.Error("entry evicted while under the watermark")
+	}
+}
+
+func TestTrimOnceEvictsDownToLowWatermark(t *testing.T) {
+	mem := &fakeMemory{used: 1200, perEviction: 100}
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { mem.free(h) }}
+	tree := newTrimTree(t, 10)
+	trimmer := NewTrimmer(tree, engine, mem, 1000, 800)
+
+	// 1200 -> 800 takes four evictions
+	if evicted := trimmer.TrimOnce(); evicted != 4 {
+		t.Fatalf("TrimOnce = %d, want 4", evicted)
+	}
+	if trimmer.Evicted() != 4 {
+		t.Errorf("Evicted = %d, want 4", trimmer.Evicted())
+	}
+	if got := mem.MemoryUsed(); got != 800 {
+		t.Errorf("MemoryUsed = %d after trim, want 800", got)
+	}
+
+	// Oldest entries went first and their handles were freed
+	for _, handle := range []uint64{1, 2, 3, 4} {
+		found := false
+		for _, freed := range mem.freed {
+			if freed == handle {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("handle %d not freed", handle)
+		}
+	}
+	if tree.Match([]uint32{100, 7}) != nil {
+		t.Error("oldest entry still cached after trim")
+	}
+	if tree.Match([]uint32{1000, 7}) == nil {
+		t.Error("newest entry evicted before older ones")
+	}
+}
+
+func TestTrimOnceStopsWhenNothingEvictable(t *testing.T) {
+	mem := &fakeMemory{used: 2000, perEviction: 100}
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { mem.free(h) }}
+	tree := newTrimTree(t, 3)
+	trimmer := NewTrimmer(tree, engine, mem, 1000, 100)
+
+	// Only three unpinned leaves exist; trimming must not spin once the
+	// LRU drains even though usage stays above the low watermark
+	if evicted := trimmer.TrimOnce(); evicted != 3 {
+		t.Errorf("TrimOnce = %d, want 3", evicted)
+	}
+	if evicted := trimmer.TrimOnce(); evicted != 0 {
+		t.Errorf("second TrimOnce = %d, want 0", evicted)
+	}
+}
+
+func TestTrimmerSkipsPinnedNodes(t *testing.T) {
+	mem := &fakeMemory{used: 2000, perEviction: 100}
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { mem.free(h) }}
+	tree := NewTree()
+	node, err := tree.InsertPending([]uint32{1, 2, 3}, engine, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 9)
+	// Still pinned: never unpinned, so not in the LRU
+
+	trimmer := NewTrimmer(tree, engine, mem, 1000, 100)
+	if evicted := trimmer.TrimOnce(); evicted != 0 {
+		t.Errorf("TrimOnce = %d with only pinned entries, want 0", evicted)
+	}
+	if tree.Match([]uint32{1, 2, 3}) == nil {
+		t.Error("pinned entry evicted")
+	}
+}
+
+func TestNewTrimmerDefaultsLowWatermark(t *testing.T) {
+	trimmer := NewTrimmer(NewTree(), &MockMLXEngine{}, &fakeMemory{}, 1000, 0)
+	if trimmer.low != 500 {
+		t.Errorf("low = %d, want 500 (half of high)", trimmer.low)
+	}
+	trimmer = NewTrimmer(NewTree(), &MockMLXEngine{}, &fakeMemory{}, 1000, 1200)
+	if trimmer.low != 500 {
+		t.Errorf("low = %d for inverted watermarks, want 500", trimmer.low)
+	}
+}
+
+func TestTrimmerBackgroundLoop(t *testing.T) {
+	mem := &fakeMemory{used: 1200, perEviction: 100}
+	engine := &MockMLXEngine{FreeFunc: func(h uint64) { mem.free(h) }}
+	tree := newTrimTree(t, 10)
+	trimmer := NewTrimmer(tree, engine, mem, 1000, 800)
+
+	trimmer.Start(5 * time.Millisecond)
+	defer trimmer.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for trimmer.Evicted() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("background trim never ran, evicted = %d", trimmer.Evicted())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := mem.MemoryUsed(); got != 800 {
+		t.Errorf("MemoryUsed = %d after background trim, want 800", got)
+	}
+}
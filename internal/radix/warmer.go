@@ -0,0 +1,198 @@
+package radix
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultWarmMinHits is how often a prefix must be requested before the
+// warmer speculatively extends it
+const DefaultWarmMinHits = 3
+
+// Warmer speculatively extends frequently-hit prefixes while the server
+// is idle: for each hot prefix it prefills the most probable next
+// segments (typically the chat-template assistant prefix), so the
+// common conversation skeleton is already cached when the next request
+// arrives and its TTFT approaches zero.
+type Warmer struct {
+	tree   *Tree
+	engine MLXEngine
+	model  any
+
+	// segments are the candidate continuations appended to hot prefixes
+	segments [][]uint32
+	minHits  int
+
+	mu      sync.Mutex
+	hits    map[string]*prefixHits
+	lastHit time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// prefixHits counts requests for one exact prefix
+type prefixHits struct {
+	tokens []uint32
+	count  int
+}
+
+// NewWarmer creates a warmer that extends hot prefixes with the given
+// segments; it is inert until Start is called
+func NewWarmer(tree *Tree, engine MLXEngine, model any, segments [][]uint32) *Warmer {
+	return &Warmer{
+		tree:     tree,
+		engine:   engine,
+		model:    model,
+		segments: segments,
+		minHits:  DefaultWarmMinHits,
+		hits:     make(map[string]*prefixHits),
+	}
+}
+
+// SetMinHits overrides the hit threshold for speculative extension
+func (w *Warmer) SetMinHits(n int) {
+	if n > 0 {
+		w.minHits = n
+	}
+}
+
+// RecordHit notes that a request arrived for the given prefix
+func (w *Warmer) RecordHit(tokens []uint32) {
+	if len(tokens) == 0 {
+		return
+	}
+	key := spillKey(tokens)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastHit = time.Now()
+	entry, ok := w.hits[key]
+	if !ok {
+		entry = &prefixHits{tokens: append([]uint32(nil), tokens...)}
+		w.hits[key] = entry
+	}
+	entry.count++
+}
+
+// WarmOnce extends every hot prefix by each segment not already cached,
+// running the prefills synchronously. It returns how many extensions
+// were computed.
+func (w *Warmer) WarmOnce() int {
+	w.mu.Lock()
+	var hot [][]uint32
+	for _, entry := range w.hits {
+		if entry.count >= w.minHits {
+			hot = append(hot, entry.tokens)
+		}
+	}
+	w.mu.Unlock()
+
+	warmed := 0
+	for _, prefix := range hot {
+		for _, segment := range w.segments {
+			if len(segment) == 0 {
+				continue
+			}
+			extended := append(append([]uint32(nil), prefix...), segment...)
+			if w.warmExtension(prefix, extended) {
+				warmed++
+			}
+		}
+	}
+	return warmed
+}
+
+// warmExtension prefills one extended sequence unless already cached
+func (w *Warmer) warmExtension(prefix, extended []uint32) bool {
+	// Skip when the full extension is already cached
+	if node := w.tree.Match(extended); node != nil && matchedLength(node) == len(extended) {
+		return false
+	}
+
+	var baseHandle uint64 = RootCacheHandle
+	if base := w.tree.Match(prefix); base != nil && base.Wait() == nil {
+		// Hold the base resident while its handle serves as the
+		// prefill's forward base; background compaction and eviction
+		// only free handles whose nodes are unreferenced
+		w.tree.Pin(base)
+		defer w.tree.Unpin(base)
+		baseHandle = base.CacheHandle
+	}
+
+	node, created, err := w.tree.InsertOrJoin(extended, w.engine, w.model)
+	if err != nil {
+		return false
+	}
+	if !created {
+		// A live request or earlier warm pass owns this node
+		w.tree.Unpin(node)
+		return false
+	}
+
+	logits, handle, err := w.engine.ForwardWithCache(w.model, node.Tokens, baseHandle)
+	if err != nil {
+		PoisonNode(node, err)
+		w.tree.Unpin(node)
+		return false
+	}
+	_ = logits
+
+	if !FinalizeNode(node, handle) {
+		// A waiter timed out and poisoned the node while the prefill
+		// ran; the handle has no owner, so free it
+		if handle != RootCacheHandle {
+			w.engine.FreeCache(handle)
+		}
+		w.tree.Unpin(node)
+		return false
+	}
+	w.tree.Unpin(node)
+	return true
+}
+
+// Start warms in the background every interval, but only while idle:
+// ticks that land within one interval of live traffic are skipped
+func (w *Warmer) Start(interval time.Duration) {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.mu.Lock()
+				idle := time.Since(w.lastHit) >= interval
+				w.mu.Unlock()
+				if idle {
+					w.WarmOnce()
+				}
+			}
+		}
+	}()
+}
+
+// Stop halts background warming and waits for the loop to exit
+func (w *Warmer) Stop() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+// matchedLength is the total token length of the path to a node
+func matchedLength(node *Node) int {
+	total := 0
+	for n := node; n != nil && n.Parent != nil; n = n.Parent {
+		total += len(n.Tokens)
+	}
+	return total
+}
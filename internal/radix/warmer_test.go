@@ -0,0 +1,154 @@
+package radix
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newWarmedTree builds a tree with a ready prefix node at handle 5
+func newWarmedTree(t *testing.T, prefix []uint32) *Tree {
+	t.Helper()
+	tree := NewTree()
+	node, err := tree.InsertPending(prefix, &MockMLXEngine{}, "m")
+	if err != nil {
+		t.Fatalf("InsertPending failed: %v", err)
+	}
+	FinalizeNode(node, 5)
+	tree.Unpin(node)
+	return tree
+}
+
+func TestWarmOnceExtendsHotPrefix(t *testing.T) {
+	prefix := []uint32{10, 20, 30}
+	segment := []uint32{1003, 1001}
+	tree := newWarmedTree(t, prefix)
+
+	var gotBase uint64
+	var gotTokens []uint32
+	engine := &MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			gotBase = base
+			gotTokens = append([]uint32(nil), tokens...)
+			return make([]float32, 100), 42, nil
+		},
+	}
+	warmer := NewWarmer(tree, engine, "m", [][]uint32{segment})
+
+	for i := 0; i < DefaultWarmMinHits; i++ {
+		warmer.RecordHit(prefix)
+	}
+
+	if warmed := warmer.WarmOnce(); warmed != 1 {
+		t.Fatalf("WarmOnce = %d, want 1", warmed)
+	}
+
+	// The extension was prefilled from the prefix's cache handle
+	if gotBase != 5 {
+		t.Errorf("Forward base = %d, want 5", gotBase)
+	}
+	if len(gotTokens) != len(segment) {
+		t.Errorf("Forward got %d tokens, want %d (segment only)", len(gotTokens), len(segment))
+	}
+
+	// The extended sequence is now a ready cache entry
+	extended := append(append([]uint32(nil), prefix...), segment...)
+	node := tree.Match(extended)
+	if node == nil || matchedLength(node) != len(extended) {
+		t.Fatal("Extended prefix not cached after warming")
+	}
+	if node.CacheHandle != 42 {
+		t.Errorf("Extension handle = %d, want 42", node.CacheHandle)
+	}
+}
+
+func TestWarmOnceRespectsMinHits(t *testing.T) {
+	prefix := []uint32{10, 20, 30}
+	tree := newWarmedTree(t, prefix)
+	warmer := NewWarmer(tree, &MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 100), 42, nil
+		},
+	}, "m", [][]uint32{{1003}})
+
+	warmer.RecordHit(prefix)
+	if warmed := warmer.WarmOnce(); warmed != 0 {
+		t.Errorf("WarmOnce = %d below hit threshold, want 0", warmed)
+	}
+
+	warmer.SetMinHits(2)
+	warmer.RecordHit(prefix)
+	if warmed := warmer.WarmOnce(); warmed != 1 {
+		t.Errorf("WarmOnce = %d at threshold, want 1", warmed)
+	}
+}
+
+func TestWarmOnceSkipsAlreadyCached(t *testing.T) {
+	prefix := []uint32{10, 20, 30}
+	tree := newWarmedTree(t, prefix)
+	warmer := NewWarmer(tree, &MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return make([]float32, 100), 42, nil
+		},
+	}, "m", [][]uint32{{1003, 1001}})
+	warmer.SetMinHits(1)
+	warmer.RecordHit(prefix)
+
+	if warmed := warmer.WarmOnce(); warmed != 1 {
+		t.Fatalf("First WarmOnce = %d, want 1", warmed)
+	}
+	if warmed := warmer.WarmOnce(); warmed != 0 {
+		t.Errorf("Second WarmOnce = %d, want 0 (already cached)", warmed)
+	}
+}
+
+func TestWarmOncePoisonsFailedExtension(t *testing.T) {
+	prefix := []uint32{10, 20, 30}
+	tree := newWarmedTree(t, prefix)
+	warmer := NewWarmer(tree, &MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			return nil, 0, errors.New("forward failed")
+		},
+	}, "m", [][]uint32{{1003}})
+	warmer.SetMinHits(1)
+	warmer.RecordHit(prefix)
+
+	if warmed := warmer.WarmOnce(); warmed != 0 {
+		t.Errorf("WarmOnce = %d with failing engine, want 0", warmed)
+	}
+}
+
+func TestWarmerBackgroundLoop(t *testing.T) {
+	prefix := []uint32{10, 20, 30}
+	segment := []uint32{1003, 1001}
+	tree := newWarmedTree(t, prefix)
+
+	var forwards atomic.Int32
+	warmer := NewWarmer(tree, &MockMLXEngine{
+		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
+			forwards.Add(1)
+			return make([]float32, 100), 42, nil
+		},
+	}, "m", [][]uint32{segment})
+	warmer.SetMinHits(1)
+	warmer.RecordHit(prefix)
+
+	warmer.Start(time.Millisecond)
+	defer warmer.Stop()
+
+	extended := append(append([]uint32(nil), prefix...), segment...)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if node := tree.Match(extended); node != nil && matchedLength(node) == len(extended) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Background warmer never extended the hot prefix")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if forwards.Load() != 1 {
+		t.Errorf("Warmer ran %d forwards, want 1", forwards.Load())
+	}
+}
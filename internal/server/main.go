@@ -14,16 +14,32 @@ import (
 	httpserver "github.com/agenthands/GUI-Actor/internal/http"
 	"github.com/agenthands/GUI-Actor/internal/mlx"
 	"github.com/agenthands/GUI-Actor/internal/radix"
+	"github.com/agenthands/GUI-Actor/internal/vision"
 	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
 )
 
 var (
 	// Server configuration
 	addr         = flag.String("addr", ":8080", "Server address")
+	backend      = flag.String("backend", "auto", "Inference backend (auto, mock, sim)")
 	modelPath    = flag.String("model", "", "Path to model weights")
 	vocabSize    = flag.Int("vocab-size", 32000, "Tokenizer vocabulary size")
 	maxCacheSize = flag.Int("max-cache-size", 1000, "Maximum cache entries (0 = unlimited)")
-	logLevel     = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	stepBudget   = flag.Int("step-budget", 0, "Max tokens per engine dispatch (0 = unlimited)")
+	warmInterval = flag.Duration("warm-interval", 0, "Speculative prefix warming interval (0 = disabled)")
+	trimHighMB   = flag.Int64("trim-high-mb", 0, "High cache memory watermark in MB; crossing it triggers background trimming (0 = disabled)")
+	trimLowMB    = flag.Int64("trim-low-mb", 0, "Low watermark in MB trimming evicts down to (default: half the high watermark)")
+	trimInterval = flag.Duration("trim-interval", 0, "Watermark check interval (default 1s)")
+	compactIvl   = flag.Duration("compact-interval", 0, "Background chain compaction interval (0 = disabled)")
+	nodeArena    = flag.Bool("node-arena", false, "Slab-allocate tree nodes to reduce GC pressure at high node counts")
+	minPrefix    = flag.Int("min-prefix-tokens", 0, "Skip caching sequences shorter than this many tokens (0 = cache everything)")
+	maxNodeToks  = flag.Int("max-node-tokens", 0, "Split tree edges longer than this into bounded segments during compaction (0 = unbounded)")
+	maxPinned    = flag.Int("max-pinned-tokens", 0, "Cap on tokens cache=pin requests may hold resident (0 = unlimited)")
+	warmupConfig = flag.String("warmup-prompts", "", "Path to JSON config listing prompts to prefill at startup")
+	// Vision embedding cache configuration
+	visionCacheEntries = flag.Int("vision-cache-entries", 0, "Vision embedding cache entries (0 = disabled)")
+	visionProfile      = flag.String("vision-profile", "balanced", "Resize profile vision cache keys are scoped to")
+	logLevel           = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	// MLX configuration
 	mlxLibrary = flag.String("mlx-library", "libmlx_runtime.dylib", "Path to MLX runtime library")
 )
@@ -36,6 +52,7 @@ func main() {
 
 	slog.Info("Starting GUI-Actor RadixAttention Server",
 		"addr", *addr,
+		"backend", *backend,
 		"model", *modelPath,
 		"vocab_size", *vocabSize,
 		"max_cache", *maxCacheSize,
@@ -44,6 +61,18 @@ func main() {
 	// Initialize components
 	// Create Radix tree for KV cache management
 	tree := radix.NewTree()
+	if *nodeArena {
+		tree.SetArena(radix.NewNodeArena())
+		slog.Info("Node slab allocation enabled", "slab_size", radix.DefaultArenaSlab)
+	}
+	if *minPrefix > 0 {
+		tree.SetMinPrefixTokens(*minPrefix)
+		slog.Info("Minimum caching prefix set", "min_prefix_tokens", *minPrefix)
+	}
+	if *maxNodeToks > 0 {
+		tree.SetMaxNodeTokens(*maxNodeToks)
+		slog.Info("Edge length bound set", "max_node_tokens", *maxNodeToks)
+	}
 	slog.Info("Initialized Radix tree for prefix caching")
 
 	// Initialize MLX engine (placeholder - would load actual MLX)
@@ -54,6 +83,15 @@ func main() {
 	}
 	slog.Info("Initialized MLX engine", "library", *mlxLibrary)
 
+	// Capture the memory reporter before wrapping hides it
+	memReporter, _ := engine.(radix.MemoryReporter)
+
+	// Bound per-step latency by chunking oversized forwards
+	if *stepBudget > 0 {
+		engine = radix.NewBudgetedEngine(engine, *stepBudget)
+		slog.Info("Token budget per dispatch enabled", "budget", *stepBudget)
+	}
+
 	// Initialize tokenizer - use Qwen2VLTokenizer if model path provided
 	tok := tokenizer.NewTokenizer(*vocabSize)
 	if *modelPath != "" {
@@ -98,6 +136,64 @@ func main() {
 
 	// Create HTTP server
 	server := httpserver.NewServer(tree, engine, tok, model)
+	if *maxPinned > 0 {
+		server.SetPinnedTokenCap(*maxPinned)
+		slog.Info("Pinned token cap set", "max_pinned_tokens", *maxPinned)
+	}
+
+	// Cache image embeddings separately from the text prefix tree
+	if *visionCacheEntries > 0 {
+		server.SetVisionCache(vision.NewCache(*visionCacheEntries), *visionProfile)
+		slog.Info("Vision embedding cache enabled",
+			"entries", *visionCacheEntries,
+			"profile", *visionProfile,
+		)
+	}
+
+	// Prefill configured prompts so the first real request against a
+	// long system prompt is already a cache hit
+	if *warmupConfig != "" {
+		prompts, err := httpserver.LoadWarmupPrompts(*warmupConfig)
+		if err != nil {
+			slog.Error("Failed to load warmup prompts", "error", err)
+			os.Exit(1)
+		}
+		warmed := server.Preheat(prompts)
+		slog.Info("Preheated cache from config", "prompts", warmed)
+	}
+
+	// Warm the common chat-template skeleton during idle time
+	if *warmInterval > 0 {
+		warmer := radix.NewWarmer(tree, engine, model, [][]uint32{tok.AssistantPrefix()})
+		warmer.Start(*warmInterval)
+		defer warmer.Stop()
+		server.SetWarmer(warmer)
+		slog.Info("Speculative prefix warming enabled", "interval", *warmInterval)
+	}
+
+	// Trim the cache between memory watermarks instead of waiting for
+	// an allocation failure mid-forward
+	if *trimHighMB > 0 {
+		if memReporter == nil {
+			slog.Warn("Watermark trimming disabled: engine does not report memory usage")
+		} else {
+			trimmer := radix.NewTrimmer(tree, engine, memReporter, *trimHighMB<<20, *trimLowMB<<20)
+			trimmer.Start(*trimInterval)
+			defer trimmer.Stop()
+			slog.Info("Watermark cache trimming enabled",
+				"high_mb", *trimHighMB,
+				"low_mb", *trimLowMB,
+			)
+		}
+	}
+
+	// Merge decode-time single-token chains back into fat edges
+	if *compactIvl > 0 {
+		compactor := radix.NewCompactor(tree, engine)
+		compactor.Start(*compactIvl)
+		defer compactor.Stop()
+		slog.Info("Background chain compaction enabled", "interval", *compactIvl)
+	}
 
 	// Setup routes
 	mux := nethttp.NewServeMux()
@@ -174,10 +270,22 @@ func setupLogging(level string) {
 }
 
 // setupMLXEngine initializes the MLX inference engine
-// Uses RealMLXEngine if model path is provided, otherwise falls back to mock
+// backend=sim runs the full stack against the simulated engine (no
+// hardware needed), backend=mock forces the trivial mock, and
+// backend=auto uses RealMLXEngine when a model path is provided
 func setupMLXEngine() (radix.MLXEngine, error) {
-	// No model path provided - use mock for testing
-	if *modelPath == "" {
+	switch *backend {
+	case "sim":
+		slog.Info("Using simulated MLX engine", "vocab_size", *vocabSize)
+		return radix.NewSimEngine(radix.SimConfig{VocabSize: *vocabSize}), nil
+	case "mock", "auto":
+		// Fall through to model-path based selection below
+	default:
+		return nil, fmt.Errorf("unknown backend %q (want auto, mock, or sim)", *backend)
+	}
+
+	// No model path provided (or mock forced) - use mock for testing
+	if *modelPath == "" || *backend == "mock" {
 		slog.Info("No model path provided, using mock MLX engine")
 		slog.Info("To use real model, download Qwen2-VL from: https://huggingface.co/Qwen/Qwen2-VL-7B-Instruct")
 		return &radix.MockMLXEngine{
@@ -0,0 +1,180 @@
+// Package vision caches image embeddings independently of the radix
+// text prefix tree. Image tokens dominate prefill cost but cannot be
+// shared through text-prefix matching when they appear mid-prompt, so
+// embeddings are cached by image hash and resize profile and injected
+// into forwards regardless of where the image sits in the sequence.
+package vision
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// DefaultMaxEntries bounds the embedding cache when no size is given
+const DefaultMaxEntries = 64
+
+// Embedder computes an image embedding; engines with a vision tower
+// implement this
+type Embedder interface {
+	EmbedImage(image []byte) ([]float32, error)
+}
+
+// NoTicket is the zero ticket: no embedding batch is staged under it
+const NoTicket uint64 = 0
+
+// Injector stages precomputed vision embeddings with the engine
+// instead of re-running the vision tower. The returned ticket scopes
+// the batch to one forward pass: concurrent requests each stage their
+// own batch, and the engine only consumes a batch for the forward
+// carrying its ticket, so interleaved requests cannot mix images.
+type Injector interface {
+	// InjectEmbeddings stages a batch and returns its ticket
+	InjectEmbeddings(embeddings [][]float32) uint64
+	// ReleaseEmbeddings discards a staged batch that no forward will
+	// consume (the request failed or joined an already-cached prefix)
+	ReleaseEmbeddings(ticket uint64)
+}
+
+// TicketedForwarder runs a forward pass that consumes the staged
+// embedding batch identified by ticket. Engines implementing Injector
+// should implement this too, so the forward that owns the ticket is
+// the one that sees its images.
+type TicketedForwarder interface {
+	ForwardWithEmbeddings(model any, tokens []uint32, base uint64, ticket uint64) ([]float32, uint64, error)
+}
+
+// CacheKey identifies an embedding by image content and resize
+// profile: the same image preprocessed differently embeds differently
+func CacheKey(image []byte, profile string) string {
+	h := sha256.New()
+	h.Write([]byte(profile))
+	h.Write([]byte{0})
+	h.Write(image)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache is an LRU cache of vision embeddings with single-flight
+// computation: concurrent misses on the same image wait for the first
+// embed instead of running the vision tower again.
+type Cache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	pending map[string]chan struct{}
+	hits    int64
+	misses  int64
+}
+
+// cacheEntry is one cached embedding in the LRU list
+type cacheEntry struct {
+	key       string
+	embedding []float32
+}
+
+// NewCache creates an embedding cache holding up to maxEntries
+// embeddings (<= 0 uses DefaultMaxEntries)
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		pending:    make(map[string]chan struct{}),
+	}
+}
+
+// Get returns the cached embedding for a key, marking it recently used
+func (c *Cache) Get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).embedding, true
+}
+
+// Put stores an embedding, evicting the least recently used beyond
+// the size limit
+func (c *Cache) Put(key string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(key, embedding)
+}
+
+// put is Put without locking
+func (c *Cache) put(key string, embedding []float32) {
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		elem.Value.(*cacheEntry).embedding = embedding
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, embedding: embedding})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// GetOrCompute returns the cached embedding for the image, computing
+// and caching it on miss. Concurrent calls for the same image share
+// one computation.
+func (c *Cache) GetOrCompute(image []byte, profile string, embed Embedder) ([]float32, error) {
+	key := CacheKey(image, profile)
+
+	for {
+		c.mu.Lock()
+		if elem, ok := c.entries[key]; ok {
+			c.hits++
+			c.lru.MoveToFront(elem)
+			embedding := elem.Value.(*cacheEntry).embedding
+			c.mu.Unlock()
+			return embedding, nil
+		}
+		if wait, inFlight := c.pending[key]; inFlight {
+			c.mu.Unlock()
+			<-wait
+			continue
+		}
+		c.misses++
+		done := make(chan struct{})
+		c.pending[key] = done
+		c.mu.Unlock()
+
+		embedding, err := embed.EmbedImage(image)
+
+		c.mu.Lock()
+		delete(c.pending, key)
+		if err == nil {
+			c.put(key, embedding)
+		}
+		c.mu.Unlock()
+		close(done)
+		return embedding, err
+	}
+}
+
+// Stats reports cache hits and misses
+func (c *Cache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Len is the number of cached embeddings
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
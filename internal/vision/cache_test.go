@@ -0,0 +1,142 @@
+package vision
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingEmbedder returns a fixed embedding and counts calls
+type countingEmbedder struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (e *countingEmbedder) EmbedImage(image []byte) ([]float32, error) {
+	e.calls.Add(1)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []float32{float32(len(image))}, nil
+}
+
+func TestCacheKey(t *testing.T) {
+	image := []byte("screenshot")
+
+	if CacheKey(image, "fast") == CacheKey(image, "quality") {
+		t.Error("Same image under different profiles must key differently")
+	}
+	if CacheKey(image, "fast") != CacheKey([]byte("screenshot"), "fast") {
+		t.Error("Identical image and profile must key identically")
+	}
+	if CacheKey(image, "fast") == CacheKey([]byte("other"), "fast") {
+		t.Error("Different images must key differently")
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	cache := NewCache(4)
+	key := CacheKey([]byte("img"), "balanced")
+
+	if _, ok := cache.Get(key); ok {
+		t.Error("Get on empty cache reported a hit")
+	}
+
+	cache.Put(key, []float32{1, 2, 3})
+	embedding, ok := cache.Get(key)
+	if !ok || len(embedding) != 3 {
+		t.Errorf("Get = (%v, %v), want cached embedding", embedding, ok)
+	}
+
+	hits, misses := cache.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("Stats = (%d, %d), want (1, 1)", hits, misses)
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	cache := NewCache(2)
+	for i := 0; i < 3; i++ {
+		cache.Put(CacheKey([]byte{byte(i)}, "p"), []float32{float32(i)})
+	}
+
+	if cache.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", cache.Len())
+	}
+	if _, ok := cache.Get(CacheKey([]byte{0}, "p")); ok {
+		t.Error("Oldest entry survived eviction")
+	}
+	if _, ok := cache.Get(CacheKey([]byte{2}, "p")); !ok {
+		t.Error("Newest entry was evicted")
+	}
+}
+
+func TestGetOrComputeReusesEmbedding(t *testing.T) {
+	cache := NewCache(4)
+	embedder := &countingEmbedder{}
+	image := []byte("screenshot")
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrCompute(image, "balanced", embedder); err != nil {
+			t.Fatalf("GetOrCompute failed: %v", err)
+		}
+	}
+	if got := embedder.calls.Load(); got != 1 {
+		t.Errorf("Embedder called %d times, want 1", got)
+	}
+
+	// A different profile re-embeds
+	if _, err := cache.GetOrCompute(image, "quality", embedder); err != nil {
+		t.Fatalf("GetOrCompute failed: %v", err)
+	}
+	if got := embedder.calls.Load(); got != 2 {
+		t.Errorf("Embedder called %d times across profiles, want 2", got)
+	}
+}
+
+func TestGetOrComputeError(t *testing.T) {
+	cache := NewCache(4)
+	embedder := &countingEmbedder{err: errors.New("vision tower failed")}
+
+	if _, err := cache.GetOrCompute([]byte("img"), "p", embedder); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	// Failures are not cached
+	if cache.Len() != 0 {
+		t.Errorf("Len = %d after failed embed, want 0", cache.Len())
+	}
+}
+
+func TestGetOrComputeConcurrent(t *testing.T) {
+	cache := NewCache(8)
+	embedder := &countingEmbedder{}
+	image := []byte("shared screenshot")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetOrCompute(image, "balanced", embedder); err != nil {
+				t.Errorf("GetOrCompute failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := embedder.calls.Load(); got != 1 {
+		t.Errorf("Embedder called %d times for concurrent identical images, want 1", got)
+	}
+}
+
+func TestDefaultMaxEntries(t *testing.T) {
+	cache := NewCache(0)
+	for i := 0; i < DefaultMaxEntries+10; i++ {
+		cache.Put(CacheKey([]byte(fmt.Sprintf("img-%d", i)), "p"), []float32{1})
+	}
+	if cache.Len() != DefaultMaxEntries {
+		t.Errorf("Len = %d, want %d", cache.Len(), DefaultMaxEntries)
+	}
+}
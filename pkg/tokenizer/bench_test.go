@@ -0,0 +1,114 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchTranscript builds a ~10KB chat transcript of the kind a long
+// agent session produces
+func benchTranscript() string {
+	turn := "<|im_start|>user\nClick the blue Save button in the toolbar, " +
+		"then wait for the dialog to close.<|im_end|>\n" +
+		"<|im_start|>assistant\npyautogui.click(0.5321, 0.0875)<|im_end|>\n"
+	var b strings.Builder
+	for b.Len() < 10*1024 {
+		b.WriteString(turn)
+	}
+	return b.String()
+}
+
+// newBenchTokenizer loads a tokenizer over the synthetic ASCII+byte
+// vocab without *testing.T helpers so benchmarks can share it
+func newBenchTokenizer(tb testing.TB) *Qwen2VLTokenizer {
+	tb.Helper()
+	vocab := make(map[string]uint32)
+	for b := 0; b < 256; b++ {
+		vocab[fmt.Sprintf("<0x%02X>", b)] = uint32(b)
+	}
+	next := uint32(256)
+	for ch := ' '; ch <= '~'; ch++ {
+		vocab[string(ch)] = next
+		next++
+	}
+	vocab["\n"] = next
+
+	data, err := json.Marshal(vocab)
+	if err != nil {
+		tb.Fatalf("marshal vocab: %v", err)
+	}
+	dir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), data, 0644); err != nil {
+		tb.Fatalf("write vocab: %v", err)
+	}
+	tok := NewQwen2VLTokenizer(dir, len(vocab))
+	if err := tok.Load(); err != nil {
+		tb.Fatalf("load: %v", err)
+	}
+	return tok
+}
+
+func BenchmarkEncode10KB(b *testing.B) {
+	tok := newBenchTokenizer(b)
+	transcript := benchTranscript()
+	b.SetBytes(int64(len(transcript)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tok.Encode(transcript); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode10KB(b *testing.B) {
+	tok := newBenchTokenizer(b)
+	tokens, err := tok.Encode(benchTranscript())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tok.Decode(tokens); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Allocation budgets: encode and decode of a large transcript must
+// stay at a handful of slice/builder growths, not per-rune garbage
+func TestEncodeAllocationBudget(t *testing.T) {
+	tok := newBenchTokenizer(t)
+	transcript := benchTranscript()
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := tok.Encode(transcript); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 3 {
+		t.Errorf("Encode of 10KB costs %.0f allocs, budget is 3", allocs)
+	}
+}
+
+func TestDecodeAllocationBudget(t *testing.T) {
+	tok := newBenchTokenizer(t)
+	tokens, err := tok.Encode(benchTranscript())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := tok.Decode(tokens); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 8 {
+		t.Errorf("Decode of 10KB costs %.0f allocs, budget is 8", allocs)
+	}
+}
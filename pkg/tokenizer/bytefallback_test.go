@@ -0,0 +1,182 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+// newByteFallbackTokenizer builds a tokenizer over a synthetic vocab:
+// printable ASCII as single-character tokens plus the full <0xNN>
+// byte-fallback range
+func newByteFallbackTokenizer(t *testing.T) *Qwen2VLTokenizer {
+	t.Helper()
+
+	vocab := make(map[string]uint32)
+	for b := 0; b < 256; b++ {
+		vocab[fmt.Sprintf("<0x%02X>", b)] = uint32(b)
+	}
+	next := uint32(256)
+	for ch := ' '; ch <= '~'; ch++ {
+		vocab[string(ch)] = next
+		next++
+	}
+
+	data, err := json.Marshal(vocab)
+	if err != nil {
+		t.Fatalf("marshal vocab: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), data, 0644); err != nil {
+		t.Fatalf("write vocab: %v", err)
+	}
+
+	tok := NewQwen2VLTokenizer(dir, len(vocab))
+	if err := tok.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return tok
+}
+
+func TestByteFallbackEncoding(t *testing.T) {
+	tok := newByteFallbackTokenizer(t)
+
+	// "€" is absent from the vocab; its three UTF-8 bytes must come
+	// back through the <0xNN> tokens
+	tokens, err := tok.Encode("€")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if len(tokens) != 3 {
+		t.Fatalf("got %d tokens, want 3 byte-fallback tokens", len(tokens))
+	}
+	for i, want := range []byte("€") {
+		if tokens[i] != uint32(want) {
+			t.Errorf("token %d = %d, want byte token %d", i, tokens[i], want)
+		}
+	}
+
+	text, err := tok.Decode(tokens)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if text != "€" {
+		t.Errorf("round trip = %q, want %q", text, "€")
+	}
+}
+
+func TestStrictDecodeRejectsUnknownID(t *testing.T) {
+	tok := newByteFallbackTokenizer(t)
+	if _, err := tok.Decode([]uint32{999999}); err == nil {
+		t.Error("strict decode accepted an unknown token ID")
+	}
+}
+
+func TestLossyDecodeReplacesUnknownID(t *testing.T) {
+	tok := newByteFallbackTokenizer(t)
+	tok.SetDecodeMode(DecodeLossy)
+
+	text, err := tok.Decode([]uint32{tok.tokenToID["a"], 999999, tok.tokenToID["b"]})
+	if err != nil {
+		t.Fatalf("lossy decode failed: %v", err)
+	}
+	if text != "a"+replacementChar+"b" {
+		t.Errorf("lossy decode = %q, want replacement in the middle", text)
+	}
+}
+
+func TestStrictDecodeRejectsInvalidUTF8(t *testing.T) {
+	tok := newByteFallbackTokenizer(t)
+
+	// A lone continuation byte can never form valid UTF-8
+	if _, err := tok.Decode([]uint32{0x80}); err == nil {
+		t.Error("strict decode accepted invalid UTF-8 output")
+	}
+}
+
+func TestLossyDecodeRepairsInvalidUTF8(t *testing.T) {
+	tok := newByteFallbackTokenizer(t)
+	tok.SetDecodeMode(DecodeLossy)
+
+	text, err := tok.Decode([]uint32{tok.tokenToID["a"], 0x80})
+	if err != nil {
+		t.Fatalf("lossy decode failed: %v", err)
+	}
+	if !utf8.ValidString(text) {
+		t.Errorf("lossy decode produced invalid UTF-8: %q", text)
+	}
+}
+
+func TestParseByteToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  byte
+		ok    bool
+	}{
+		{"<0x00>", 0x00, true},
+		{"<0xFF>", 0xFF, true},
+		{"<0xab>", 0xAB, true},
+		{"<0xG1>", 0, false},
+		{"<0x1>", 0, false},
+		{"hello", 0, false},
+		{"<|im_start|>", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseByteToken(tt.token)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseByteToken(%q) = (%d, %v), want (%d, %v)", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func FuzzEncodeDecodeRoundTrip(f *testing.F) {
+	f.Add("hello world")
+	f.Add("pyautogui.click(0.5, 0.5)")
+	f.Add("日本語のテキスト")
+	f.Add("mixed ascii and 中文 and émojis 🎯")
+	f.Add("\x00\x01\x02")
+	f.Add(string([]byte{0xFF, 0xFE, 0x80}))
+
+	// Building the vocab needs *testing.T helpers; do it once by hand
+	vocab := make(map[string]uint32)
+	for b := 0; b < 256; b++ {
+		vocab[fmt.Sprintf("<0x%02X>", b)] = uint32(b)
+	}
+	next := uint32(256)
+	for ch := ' '; ch <= '~'; ch++ {
+		vocab[string(ch)] = next
+		next++
+	}
+	data, _ := json.Marshal(vocab)
+	dir := f.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), data, 0644); err != nil {
+		f.Fatalf("write vocab: %v", err)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if input == "" {
+			t.Skip()
+		}
+		tok := NewQwen2VLTokenizer(dir, len(vocab))
+		tok.SetDecodeMode(DecodeLossy)
+
+		tokens, err := tok.Encode(input)
+		if err != nil {
+			t.Fatalf("Encode(%q) failed: %v", input, err)
+		}
+		text, err := tok.Decode(tokens)
+		if err != nil {
+			t.Fatalf("Decode of encoded %q failed: %v", input, err)
+		}
+		if !utf8.ValidString(text) {
+			t.Fatalf("lossy decode produced invalid UTF-8 from %q", input)
+		}
+		// Valid UTF-8 input must survive the round trip byte for byte
+		if utf8.ValidString(input) && text != input {
+			t.Fatalf("round trip of %q = %q", input, text)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package tokenizer
+
+import "strings"
+
+// Special token strings from the Qwen2-VL chat template
+const (
+	ImStartToken     = "<|im_start|>"
+	ImEndToken       = "<|im_end|>"
+	VisionStartToken = "<|vision_start|>"
+	ImagePadToken    = "<|image_pad|>"
+	VisionEndToken   = "<|vision_end|>"
+
+	// DefaultSystemPrompt is injected when the conversation has no
+	// system message, mirroring the reference template
+	DefaultSystemPrompt = "You are a helpful assistant."
+
+	// Pointer tokens trigger the action head during generation and
+	// must never originate from user content
+	PointerStartToken = "<|pointer_start|>"
+	PointerPadToken   = "<|pointer_pad|>"
+	PointerEndToken   = "<|pointer_end|>"
+
+	EndOfTextToken = "<|endoftext|>"
+)
+
+// reservedTokens are the marker strings stripped from user content; a
+// forged <|im_start|> in a message would otherwise fabricate a role
+// boundary during tokenization
+var reservedTokens = []string{
+	ImStartToken,
+	ImEndToken,
+	VisionStartToken,
+	ImagePadToken,
+	VisionEndToken,
+	PointerStartToken,
+	PointerPadToken,
+	PointerEndToken,
+	EndOfTextToken,
+}
+
+// SanitizeSpecialTokens strips reserved template markers from
+// user-provided content. Applied repeatedly until stable, so split
+// markers like "<|im_<|im_end|>start|>" cannot reassemble.
+func SanitizeSpecialTokens(text string) string {
+	for {
+		cleaned := text
+		for _, token := range reservedTokens {
+			cleaned = strings.ReplaceAll(cleaned, token, "")
+		}
+		if cleaned == text {
+			return cleaned
+		}
+		text = cleaned
+	}
+}
+
+// RenderChatML renders messages with the Qwen2-VL chat template,
+// matching transformers' apply_chat_template(tokenize=False,
+// add_generation_prompt=True). Images render as the vision token
+// sandwich before the message text; tool-result messages render under
+// their own "tool" role header, post-action screenshots included, so
+// ReAct-style loops round-trip. Message content is sanitized so
+// embedded special tokens cannot forge role boundaries.
+func RenderChatML(messages []ChatMessage) string {
+	return renderChatML(messages, true)
+}
+
+// RenderChatMLRaw skips sanitization; only for trusted content such as
+// template fixtures
+func RenderChatMLRaw(messages []ChatMessage) string {
+	return renderChatML(messages, false)
+}
+
+func renderChatML(messages []ChatMessage, sanitize bool) string {
+	var b strings.Builder
+
+	if len(messages) == 0 || messages[0].Role != "system" {
+		b.WriteString(ImStartToken + "system\n" + DefaultSystemPrompt + ImEndToken + "\n")
+	}
+
+	for _, msg := range messages {
+		content := msg.Content
+		if sanitize {
+			content = SanitizeSpecialTokens(content)
+		}
+		b.WriteString(ImStartToken + msg.Role + "\n")
+		for range msg.AllImages() {
+			b.WriteString(VisionStartToken + ImagePadToken + VisionEndToken)
+		}
+		b.WriteString(content + ImEndToken + "\n")
+	}
+
+	b.WriteString(ImStartToken + "assistant\n")
+	return b.String()
+}
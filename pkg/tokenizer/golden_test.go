@@ -0,0 +1,91 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// Golden parity tests compare against reference outputs exported from
+// the Python transformers implementation. Regenerate fixtures with:
+//
+//	python scripts/export_golden_fixtures.py <model_path>
+
+// loadGoldenFixture decodes a fixture file into v, skipping the test if
+// the fixture has not been generated
+func loadGoldenFixture(t *testing.T, path string, v any) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Skipf("Fixture %s not generated; run scripts/export_golden_fixtures.py", path)
+	}
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse fixture %s: %v", path, err)
+	}
+}
+
+func TestChatTemplateGolden(t *testing.T) {
+	var fixture struct {
+		Cases []struct {
+			Name     string        `json:"name"`
+			Messages []ChatMessage `json:"messages"`
+			Rendered string        `json:"rendered"`
+		} `json:"cases"`
+	}
+	loadGoldenFixture(t, "testdata/golden/chat_template.json", &fixture)
+
+	if len(fixture.Cases) == 0 {
+		t.Fatal("Fixture has no cases")
+	}
+
+	for _, tt := range fixture.Cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := RenderChatML(tt.Messages)
+			if got != tt.Rendered {
+				t.Errorf("RenderChatML mismatch\ngot:  %q\nwant: %q", got, tt.Rendered)
+			}
+		})
+	}
+}
+
+func TestTokenizerEncodingsGolden(t *testing.T) {
+	modelPath := os.Getenv("GUI_ACTOR_MODEL_PATH")
+	if modelPath == "" {
+		t.Skip("Set GUI_ACTOR_MODEL_PATH to a model directory with vocab.json")
+	}
+
+	var fixture struct {
+		Cases []struct {
+			Text     string   `json:"text"`
+			TokenIDs []uint32 `json:"token_ids"`
+		} `json:"cases"`
+	}
+	loadGoldenFixture(t, "testdata/golden/tokenizer_encodings.json", &fixture)
+
+	tok := NewQwen2VLTokenizer(modelPath, 0)
+	if err := tok.Load(); err != nil {
+		t.Fatalf("Failed to load tokenizer: %v", err)
+	}
+
+	for _, tt := range fixture.Cases {
+		t.Run(tt.Text, func(t *testing.T) {
+			got, err := tok.Encode(tt.Text)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if len(got) != len(tt.TokenIDs) {
+				t.Fatalf("Encode produced %d tokens, reference has %d\ngot:  %v\nwant: %v",
+					len(got), len(tt.TokenIDs), got, tt.TokenIDs)
+			}
+			for i := range got {
+				if got[i] != tt.TokenIDs[i] {
+					t.Fatalf("Token %d = %d, reference %d", i, got[i], tt.TokenIDs[i])
+				}
+			}
+		})
+	}
+}
@@ -6,19 +6,45 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
+// DecodeMode controls how Decode handles token sequences that do not
+// form valid text
+type DecodeMode int
+
+const (
+	// DecodeStrict fails on unknown token IDs and on output that is
+	// not valid UTF-8
+	DecodeStrict DecodeMode = iota
+	// DecodeLossy substitutes U+FFFD for unknown tokens and invalid
+	// byte sequences instead of failing
+	DecodeLossy
+)
+
+// replacementChar substitutes undecodable input in lossy mode
+const replacementChar = "�"
+
 // Qwen2VLTokenizer is a BPE tokenizer for Qwen2-VL models
 // It loads the vocabulary from the model's vocab.json file
 type Qwen2VLTokenizer struct {
-	vocabSize   int
-	vocabPath   string
+	vocabSize int
+	vocabPath string
 	// idToToken maps token IDs to their string representation
-	idToToken   map[uint32]string
+	idToToken map[uint32]string
 	// tokenToID maps token strings to their IDs
-	tokenToID   map[string]uint32
-	once        sync.Once
-	initErr     error
+	tokenToID map[string]uint32
+	// byteToID maps raw byte values to their <0xNN> fallback tokens,
+	// when the vocabulary carries them
+	byteToID map[byte]uint32
+	// idToByte is the reverse byte-fallback mapping for decoding
+	idToByte map[uint32]byte
+	// asciiID short-circuits the per-rune map lookup for the ASCII
+	// range, which dominates chat transcripts; -1 marks absent tokens
+	asciiID [128]int32
+	mode    DecodeMode
+	once    sync.Once
+	initErr error
 }
 
 // NewQwen2VLTokenizer creates a new Qwen2-VL tokenizer
@@ -29,13 +55,35 @@ func NewQwen2VLTokenizer(modelPath string, vocabSize int) *Qwen2VLTokenizer {
 		vocabPath: modelPath + "/vocab.json",
 		idToToken: make(map[uint32]string),
 		tokenToID: make(map[string]uint32),
+		byteToID:  make(map[byte]uint32),
+		idToByte:  make(map[uint32]byte),
 	}
 }
 
-// Load loads the vocabulary from the vocab.json file
+// SetDecodeMode selects strict or lossy handling of invalid sequences;
+// the default is strict
+func (t *Qwen2VLTokenizer) SetDecodeMode(mode DecodeMode) {
+	t.mode = mode
+}
+
+// Load loads the vocabulary, preferring the mmap'd binary cache and
+// falling back to (and recompiling from) vocab.json
 // This is safe to call multiple times (uses sync.Once)
 func (t *Qwen2VLTokenizer) Load() error {
 	t.once.Do(func() {
+		cachePath := vocabCachePath(t.vocabPath)
+		if cacheFresh(cachePath, t.vocabPath) {
+			idToToken, tokenToID, err := mmapVocabCache(cachePath)
+			if err == nil {
+				t.idToToken = idToToken
+				t.tokenToID = tokenToID
+				t.finishLoad()
+				return
+			}
+			// A corrupt cache falls through to the JSON path, which
+			// rewrites it
+		}
+
 		data, err := os.ReadFile(t.vocabPath)
 		if err != nil {
 			t.initErr = fmt.Errorf("failed to read vocab.json: %w", err)
@@ -49,24 +97,46 @@ func (t *Qwen2VLTokenizer) Load() error {
 			return
 		}
 
-		// Build bidirectional maps
 		for token, id := range vocab {
 			t.idToToken[id] = token
 			t.tokenToID[token] = id
 		}
+		t.finishLoad()
 
-		// Update vocab size to actual size
-		actualSize := len(t.idToToken)
-		if actualSize != t.vocabSize {
-			// Update to actual vocab size
-			t.vocabSize = actualSize
-		}
+		// Best effort: later loads hit the cache instead of the JSON
+		_ = writeVocabCache(cachePath, vocab)
 	})
 	return t.initErr
 }
 
+// finishLoad derives the byte-fallback maps, the ASCII fast path and
+// the actual vocab size from the loaded token maps
+func (t *Qwen2VLTokenizer) finishLoad() {
+	for i := range t.asciiID {
+		t.asciiID[i] = -1
+	}
+	// Byte-fallback tokens of the form <0xNN> additionally map to
+	// their raw byte value
+	for token, id := range t.tokenToID {
+		if b, ok := parseByteToken(token); ok {
+			t.byteToID[b] = id
+			t.idToByte[id] = b
+		}
+		if len(token) == 1 && token[0] < 128 {
+			t.asciiID[token[0]] = int32(id)
+		}
+	}
+
+	// Update vocab size to actual size
+	actualSize := len(t.idToToken)
+	if actualSize != t.vocabSize {
+		t.vocabSize = actualSize
+	}
+}
+
 // Decode converts token IDs back to text
-// For most tokens, this is a direct lookup from the vocab
+// Byte-fallback tokens contribute their raw byte; the decode mode
+// decides whether unknown IDs or invalid UTF-8 fail or are replaced
 func (t *Qwen2VLTokenizer) Decode(tokens []uint32) (string, error) {
 	if err := t.Load(); err != nil {
 		return "", err
@@ -80,17 +150,29 @@ func (t *Qwen2VLTokenizer) Decode(tokens []uint32) (string, error) {
 	result.Grow(len(tokens) * 4) // Pre-allocate average 4 bytes per token
 
 	for _, token := range tokens {
-		if token >= uint32(t.vocabSize) {
-			return "", fmt.Errorf("token ID %d out of range (vocab size: %d)", token, t.vocabSize)
+		if b, ok := t.idToByte[token]; ok {
+			result.WriteByte(b)
+			continue
 		}
 		tokenStr, ok := t.idToToken[token]
-		if !ok {
-			return "", fmt.Errorf("token ID %d not found in vocabulary", token)
+		if !ok || token >= uint32(t.vocabSize) {
+			if t.mode == DecodeLossy {
+				result.WriteString(replacementChar)
+				continue
+			}
+			return "", fmt.Errorf("token ID %d not found in vocabulary (size %d)", token, t.vocabSize)
 		}
 		result.WriteString(tokenStr)
 	}
 
-	return result.String(), nil
+	text := result.String()
+	if !utf8.ValidString(text) {
+		if t.mode == DecodeLossy {
+			return strings.ToValidUTF8(text, replacementChar), nil
+		}
+		return "", fmt.Errorf("decoded bytes are not valid UTF-8")
+	}
+	return text, nil
 }
 
 // DecodeSingle decodes a single token ID to text
@@ -126,13 +208,28 @@ func (t *Qwen2VLTokenizer) Encode(text string) ([]uint32, error) {
 	// For now, use a simple character-based encoding
 	// TODO: Implement proper BPE encoding using merges.txt
 	tokens := make([]uint32, 0, len(text))
+	var buf [utf8.UTFMax]byte
 	for _, ch := range text {
-		tokenStr := string(ch)
-		if id, ok := t.tokenToID[tokenStr]; ok {
+		// ASCII fast path: no per-rune string allocation or map lookup
+		if ch < 128 {
+			if id := t.asciiID[ch]; id >= 0 {
+				tokens = append(tokens, uint32(id))
+				continue
+			}
+		}
+		n := utf8.EncodeRune(buf[:], ch)
+		if id, ok := t.tokenToID[string(buf[:n])]; ok {
 			tokens = append(tokens, id)
-		} else {
-			// Fall back to byte-level encoding for unknown characters
-			for _, b := range []byte(tokenStr) {
+			continue
+		}
+		// Character absent from the vocab: emit its UTF-8 bytes through
+		// the <0xNN> fallback tokens so nothing is dropped
+		for _, b := range buf[:n] {
+			if id, ok := t.byteToID[b]; ok {
+				tokens = append(tokens, id)
+			} else {
+				// Vocabularies without byte tokens keep the legacy
+				// raw-byte placeholder
 				tokens = append(tokens, uint32(b))
 			}
 		}
@@ -141,6 +238,29 @@ func (t *Qwen2VLTokenizer) Encode(text string) ([]uint32, error) {
 	return tokens, nil
 }
 
+// parseByteToken recognizes SentencePiece-style byte-fallback tokens
+// of the form <0xNN>
+func parseByteToken(token string) (byte, bool) {
+	if len(token) != 6 || !strings.HasPrefix(token, "<0x") || token[5] != '>' {
+		return 0, false
+	}
+	var b byte
+	for i := 3; i < 5; i++ {
+		c := token[i]
+		switch {
+		case c >= '0' && c <= '9':
+			b = b<<4 | (c - '0')
+		case c >= 'A' && c <= 'F':
+			b = b<<4 | (c - 'A' + 10)
+		case c >= 'a' && c <= 'f':
+			b = b<<4 | (c - 'a' + 10)
+		default:
+			return 0, false
+		}
+	}
+	return b, true
+}
+
 // VocabSize returns the vocabulary size
 func (t *Qwen2VLTokenizer) VocabSize() int {
 	return t.vocabSize
@@ -98,12 +98,31 @@ func (t *Tokenizer) VocabSize() int {
 	return t.vocabSize
 }
 
+// MaxImagesPerMessage bounds how many images one message may attach;
+// each image costs a full vision-tower pass and hundreds of tokens
+const MaxImagesPerMessage = 8
+
 // ChatMessage represents a single message in a chat conversation
 type ChatMessage struct {
-	Role    string `json:"role"`    // "system", "user", "assistant"
+	Role    string `json:"role"`    // "system", "user", "assistant", "tool"
 	Content string `json:"content"` // Message content
-	// Optional: Image for multimodal input
+	// Optional: Image for multimodal input (for tool messages, usually
+	// the post-action screenshot)
 	Image string `json:"image,omitempty"` // Base64 encoded image
+	// Images carries additional base64 images; Image is kept as the
+	// single-image shorthand and counts as the first entry
+	Images []string `json:"images,omitempty"`
+	// ToolCallID links a tool-result message to the call it answers.
+	// Client bookkeeping only; it is never tokenized into the prompt.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// AllImages returns the message's images in prompt order
+func (m ChatMessage) AllImages() []string {
+	if m.Image == "" {
+		return m.Images
+	}
+	return append([]string{m.Image}, m.Images...)
 }
 
 // ChatRequest represents a chat completion request
@@ -122,7 +141,7 @@ func (t *Tokenizer) TokenizeChatRequest(req *ChatRequest) ([]uint32, error) {
 
 	var allTokens []uint32
 
-	for _, msg := range req.Messages {
+	for i, msg := range req.Messages {
 		// Add role-specific special tokens
 		roleTokens, err := t.encodeRole(msg.Role)
 		if err != nil {
@@ -130,21 +149,32 @@ func (t *Tokenizer) TokenizeChatRequest(req *ChatRequest) ([]uint32, error) {
 		}
 		allTokens = append(allTokens, roleTokens...)
 
-		// Tokenize content
-		contentTokens, err := t.EncodeText(msg.Content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encode content: %w", err)
+		// Images precede the text, matching the chat template's
+		// vision-sandwich-then-content order; any role may carry them
+		// (assistant/tool screenshots of executed actions included)
+		images := msg.AllImages()
+		if len(images) > MaxImagesPerMessage {
+			return nil, fmt.Errorf("message %d: %d images exceeds the per-message limit of %d",
+				i, len(images), MaxImagesPerMessage)
 		}
-		allTokens = append(allTokens, contentTokens...)
-
-		// Handle image if present
-		if msg.Image != "" {
-			imageTokens, err := t.EncodeImage(msg.Image)
+		for _, image := range images {
+			imageTokens, err := t.EncodeImage(image)
 			if err != nil {
 				return nil, fmt.Errorf("failed to encode image: %w", err)
 			}
 			allTokens = append(allTokens, imageTokens...)
 		}
+
+		// Tokenize content; image-only messages (a bare screenshot of
+		// an executed action) have nothing further to add
+		if msg.Content == "" && len(images) > 0 {
+			continue
+		}
+		contentTokens, err := t.EncodeText(msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode content: %w", err)
+		}
+		allTokens = append(allTokens, contentTokens...)
 	}
 
 	// Add assistant response prefix
@@ -164,6 +194,8 @@ func (t *Tokenizer) encodeRole(role string) ([]uint32, error) {
 		return []uint32{1002, 1001}, nil // <|user|>\n
 	case "assistant":
 		return []uint32{1003, 1001}, nil // <|assistant|>\n
+	case "tool":
+		return []uint32{1004, 1001}, nil // <|tool|>\n
 	default:
 		return nil, fmt.Errorf("unknown role: %s", role)
 	}
@@ -174,12 +206,18 @@ func (t *Tokenizer) getAssistantPrefix() []uint32 {
 	return []uint32{1003, 1001} // <|assistant|>\n
 }
 
+// AssistantPrefix exposes the assistant response prefix tokens, e.g.
+// for speculative cache warming of the chat-template skeleton
+func (t *Tokenizer) AssistantPrefix() []uint32 {
+	return t.getAssistantPrefix()
+}
+
 // TokenizerConfig holds tokenizer configuration
 type TokenizerConfig struct {
-	VocabSize      int    `json:"vocab_size"`
-	ModelPath      string `json:"model_path"`
-	Type           string `json:"type"` // "bpe", "wordpiece", "sentencepiece"
-	SpecialTokens  map[string]int `json:"special_tokens"`
+	VocabSize     int            `json:"vocab_size"`
+	ModelPath     string         `json:"model_path"`
+	Type          string         `json:"type"` // "bpe", "wordpiece", "sentencepiece"
+	SpecialTokens map[string]int `json:"special_tokens"`
 }
 
 // LoadConfig loads tokenizer configuration from JSON
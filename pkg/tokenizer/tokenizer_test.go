@@ -345,3 +345,85 @@ func TestChatRequestJSON(t *testing.T) {
 		t.Errorf("Expected MaxTokens %d, got %d", req.MaxTokens, req2.MaxTokens)
 	}
 }
+
+func TestTokenizeChatRequestToolRole(t *testing.T) {
+	tok := NewTokenizer(10000)
+
+	req := &ChatRequest{
+		Messages: []ChatMessage{
+			{Role: "user", Content: "click save"},
+			{Role: "assistant", Content: "pyautogui.click(0.5, 0.5)"},
+			{Role: "tool", Content: "action executed", Image: "aGk=", ToolCallID: "call_1"},
+		},
+	}
+
+	tokens, err := tok.TokenizeChatRequest(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	hasToolRole := false
+	for _, token := range tokens {
+		if token == 1004 {
+			hasToolRole = true
+			break
+		}
+	}
+	if !hasToolRole {
+		t.Error("Expected tool role token in output")
+	}
+}
+
+func TestTokenizeChatRequestImagesBeforeText(t *testing.T) {
+	tok := NewTokenizer(10000)
+
+	req := &ChatRequest{
+		Messages: []ChatMessage{
+			{Role: "assistant", Content: "z", Image: "aGk="},
+		},
+	}
+	tokens, err := tok.TokenizeChatRequest(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Layout: role (2) + image (256) + content (1) + assistant prefix (2)
+	if len(tokens) != 2+256+1+2 {
+		t.Fatalf("got %d tokens, want %d", len(tokens), 2+256+1+2)
+	}
+	// The content token for "z" must come after the image block, as in
+	// the rendered template
+	contentToken := tokens[2+256]
+	if contentToken != uint32('z')%10000 {
+		t.Errorf("token after image block = %d, want the content token", contentToken)
+	}
+}
+
+func TestTokenizeChatRequestImageOnlyMessage(t *testing.T) {
+	tok := NewTokenizer(10000)
+
+	req := &ChatRequest{
+		Messages: []ChatMessage{
+			{Role: "user", Content: "click save"},
+			{Role: "tool", Image: "aGk="},
+		},
+	}
+	if _, err := tok.TokenizeChatRequest(req); err != nil {
+		t.Errorf("image-only tool message rejected: %v", err)
+	}
+}
+
+func TestTokenizeChatRequestImageLimit(t *testing.T) {
+	tok := NewTokenizer(10000)
+
+	images := make([]string, MaxImagesPerMessage+1)
+	for i := range images {
+		images[i] = "aGk="
+	}
+	req := &ChatRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "too many", Images: images}},
+	}
+	if _, err := tok.TokenizeChatRequest(req); err == nil {
+		t.Errorf("accepted %d images in one message", len(images))
+	}
+}
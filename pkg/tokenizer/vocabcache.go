@@ -0,0 +1,141 @@
+package tokenizer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Binary vocab cache. Parsing a 150k-entry vocab.json costs hundreds
+// of milliseconds and duplicates every token string on the heap. The
+// first load compiles the vocab into a flat binary file next to the
+// JSON; later loads mmap it and back the token strings directly by the
+// mapped pages, which the OS shares across processes.
+//
+// Layout: 4-byte magic, uint32 entry count, then per entry a uint32
+// token ID, uint32 byte length and the token bytes. All integers are
+// little-endian.
+
+const vocabCacheMagic = "QVC1"
+
+// vocabCachePath derives the cache file from the vocab.json path
+func vocabCachePath(vocabPath string) string {
+	return strings.TrimSuffix(vocabPath, ".json") + ".bin"
+}
+
+// writeVocabCache compiles the vocab into the binary cache; callers
+// treat failures as non-fatal since the JSON path still works
+func writeVocabCache(path string, vocab map[string]uint32) error {
+	var size int
+	for token := range vocab {
+		size += 8 + len(token)
+	}
+	buf := make([]byte, 0, 8+size)
+	buf = append(buf, vocabCacheMagic...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(vocab)))
+	for token, id := range vocab {
+		buf = binary.LittleEndian.AppendUint32(buf, id)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(token)))
+		buf = append(buf, token...)
+	}
+
+	// Write-and-rename so a crash cannot leave a truncated cache
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// mmapVocabCache maps the cache read-only and walks the entries. The
+// returned strings alias the mapping, which stays alive for the
+// process lifetime; the pages are shared with other processes mapping
+// the same file.
+func mmapVocabCache(path string) (map[uint32]string, map[string]uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() < 8 {
+		return nil, nil, fmt.Errorf("vocab cache truncated")
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mmap vocab cache: %w", err)
+	}
+
+	if string(data[:4]) != vocabCacheMagic {
+		syscall.Munmap(data)
+		return nil, nil, fmt.Errorf("vocab cache has wrong magic")
+	}
+	count := binary.LittleEndian.Uint32(data[4:8])
+
+	idToToken := make(map[uint32]string, count)
+	tokenToID := make(map[string]uint32, count)
+	off := 8
+	for i := uint32(0); i < count; i++ {
+		if off+8 > len(data) {
+			syscall.Munmap(data)
+			return nil, nil, fmt.Errorf("vocab cache truncated at entry %d", i)
+		}
+		id := binary.LittleEndian.Uint32(data[off : off+4])
+		n := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		off += 8
+		if off+n > len(data) {
+			syscall.Munmap(data)
+			return nil, nil, fmt.Errorf("vocab cache truncated at entry %d", i)
+		}
+		// Zero-copy: the string header points into the mapping
+		token := unsafe.String(&data[off], n)
+		off += n
+		idToToken[id] = token
+		tokenToID[token] = id
+	}
+	return idToToken, tokenToID, nil
+}
+
+// cacheFresh reports whether the cache exists and is no older than the
+// JSON it was compiled from
+func cacheFresh(cachePath, vocabPath string) bool {
+	cacheInfo, err := os.Stat(cachePath)
+	if err != nil {
+		return false
+	}
+	jsonInfo, err := os.Stat(vocabPath)
+	if err != nil {
+		return false
+	}
+	return !cacheInfo.ModTime().Before(jsonInfo.ModTime())
+}
+
+// Shared tokenizer instances. Every request handler asking for the
+// same model reuses one tokenizer, so the vocab maps exist once per
+// process rather than once per request.
+var (
+	sharedMu         sync.Mutex
+	sharedTokenizers = make(map[string]*Qwen2VLTokenizer)
+)
+
+// SharedQwen2VLTokenizer returns the process-wide tokenizer for a
+// model directory, creating it on first use
+func SharedQwen2VLTokenizer(modelPath string, vocabSize int) *Qwen2VLTokenizer {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if tok, ok := sharedTokenizers[modelPath]; ok {
+		return tok
+	}
+	tok := NewQwen2VLTokenizer(modelPath, vocabSize)
+	sharedTokenizers[modelPath] = tok
+	return tok
+}
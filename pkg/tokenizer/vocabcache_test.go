@@ -0,0 +1,136 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeVocabJSON writes a small vocab.json into a fresh model dir
+func writeVocabJSON(t *testing.T, vocab map[string]uint32) string {
+	t.Helper()
+	data, err := json.Marshal(vocab)
+	if err != nil {
+		t.Fatalf("marshal vocab: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), data, 0644); err != nil {
+		t.Fatalf("write vocab: %v", err)
+	}
+	return dir
+}
+
+var cacheTestVocab = map[string]uint32{
+	"hello":  0,
+	"world":  1,
+	"<0x41>": 2,
+	"日本":     3,
+}
+
+func TestLoadCompilesBinaryCache(t *testing.T) {
+	dir := writeVocabJSON(t, cacheTestVocab)
+
+	tok := NewQwen2VLTokenizer(dir, len(cacheTestVocab))
+	if err := tok.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "vocab.bin")); err != nil {
+		t.Fatalf("binary cache not written: %v", err)
+	}
+}
+
+func TestLoadFromBinaryCache(t *testing.T) {
+	dir := writeVocabJSON(t, cacheTestVocab)
+
+	// First load compiles the cache
+	if err := NewQwen2VLTokenizer(dir, 0).Load(); err != nil {
+		t.Fatalf("first Load failed: %v", err)
+	}
+	// Break the JSON; a successful second load proves the cache path
+	// was taken
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "vocab.bin"), now, now); err != nil {
+		t.Fatal(err)
+	}
+	tok := NewQwen2VLTokenizer(dir, 0)
+	if err := tok.Load(); err != nil {
+		t.Fatalf("cached Load failed: %v", err)
+	}
+
+	for token, id := range cacheTestVocab {
+		got, err := tok.DecodeSingle(id)
+		if err != nil {
+			t.Fatalf("DecodeSingle(%d) failed: %v", id, err)
+		}
+		if got != token {
+			t.Errorf("DecodeSingle(%d) = %q, want %q", id, got, token)
+		}
+	}
+	// Byte-fallback maps must be derived on the cache path too
+	if tok.byteToID[0x41] != 2 {
+		t.Error("byte-fallback map missing after cached load")
+	}
+}
+
+func TestStaleCacheRecompiled(t *testing.T) {
+	dir := writeVocabJSON(t, cacheTestVocab)
+	if err := NewQwen2VLTokenizer(dir, 0).Load(); err != nil {
+		t.Fatalf("first Load failed: %v", err)
+	}
+
+	// Update the vocab and backdate the cache
+	updated := map[string]uint32{"fresh": 0}
+	data, _ := json.Marshal(updated)
+	if err := os.WriteFile(filepath.Join(dir, "vocab.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "vocab.bin"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	tok := NewQwen2VLTokenizer(dir, 0)
+	if err := tok.Load(); err != nil {
+		t.Fatalf("Load after update failed: %v", err)
+	}
+	if got, err := tok.DecodeSingle(0); err != nil || got != "fresh" {
+		t.Errorf("DecodeSingle(0) = (%q, %v), want the recompiled vocab", got, err)
+	}
+}
+
+func TestCorruptCacheFallsBack(t *testing.T) {
+	dir := writeVocabJSON(t, cacheTestVocab)
+	if err := os.WriteFile(filepath.Join(dir, "vocab.bin"), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The cache is newer than the JSON but unreadable; Load must fall
+	// back and rewrite it
+	tok := NewQwen2VLTokenizer(dir, 0)
+	if err := tok.Load(); err != nil {
+		t.Fatalf("Load with corrupt cache failed: %v", err)
+	}
+	if got, err := tok.DecodeSingle(0); err != nil || got != "hello" {
+		t.Errorf("DecodeSingle(0) = (%q, %v), want fallback to JSON", got, err)
+	}
+}
+
+func TestSharedTokenizerReused(t *testing.T) {
+	dir := writeVocabJSON(t, cacheTestVocab)
+
+	a := SharedQwen2VLTokenizer(dir, 0)
+	b := SharedQwen2VLTokenizer(dir, 0)
+	if a != b {
+		t.Error("same model path produced distinct tokenizer instances")
+	}
+
+	other := SharedQwen2VLTokenizer(t.TempDir(), 0)
+	if other == a {
+		t.Error("different model paths share one tokenizer")
+	}
+}
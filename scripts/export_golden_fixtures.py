@@ -0,0 +1,189 @@
+"""Export golden fixtures from the HuggingFace reference implementation.
+
+The Go parity tests compare their outputs against fixtures produced by the
+Python transformers stack:
+
+- pkg/tokenizer: tokenizer encodings and chat-template renderings
+- src/pkg/vision: smart-resize dimensions
+- internal/mlx: first-token logits (requires hardware to verify)
+
+Usage:
+    python scripts/export_golden_fixtures.py <model_path_or_id> [output_dir]
+
+Fixtures are written as JSON next to the tests that consume them; pass an
+output_dir to collect them in one place instead.
+"""
+
+import json
+import math
+import os
+import sys
+
+from transformers import AutoProcessor, AutoTokenizer
+
+SAMPLE_TEXTS = [
+    "Hello, World!",
+    "Click the Submit button in the lower right corner.",
+    "The quick brown fox jumps over the lazy dog.",
+    "Ouvrez les parametres systeme",
+    "1234567890 !@#$%^&*()",
+]
+
+SAMPLE_CONVERSATIONS = [
+    {
+        "name": "user_only",
+        "messages": [
+            {"role": "user", "content": "Say 'Hello, World!'"},
+        ],
+    },
+    {
+        "name": "system_and_user",
+        "messages": [
+            {"role": "system", "content": "You are a GUI agent."},
+            {"role": "user", "content": "Click the search icon."},
+        ],
+    },
+    {
+        "name": "multi_turn",
+        "messages": [
+            {"role": "user", "content": "Open settings"},
+            {"role": "assistant", "content": "Done."},
+            {"role": "user", "content": "Now enable dark mode"},
+        ],
+    },
+]
+
+# (width, height, min_pixels, max_pixels) matching the server profiles
+SMART_RESIZE_CASES = [
+    (224, 224, 3136, 5720064),
+    (112, 224, 3136, 5720064),
+    (56, 56, 3136, 5720064),
+    (28, 28, 3136, 5720064),
+    (1932, 1092, 3136, 5720064),
+    (2800, 2800, 3136, 1048576),
+    (3840, 2160, 3136, 5720064),
+]
+
+
+def export_tokenizer_encodings(tokenizer, path):
+    cases = []
+    for text in SAMPLE_TEXTS:
+        cases.append({
+            "text": text,
+            "token_ids": tokenizer.encode(text, add_special_tokens=False),
+        })
+    write_fixture(path, {"cases": cases})
+
+
+def export_chat_template(tokenizer, path):
+    cases = []
+    for conv in SAMPLE_CONVERSATIONS:
+        rendered = tokenizer.apply_chat_template(
+            conv["messages"], tokenize=False, add_generation_prompt=True
+        )
+        cases.append({
+            "name": conv["name"],
+            "messages": conv["messages"],
+            "rendered": rendered,
+        })
+    write_fixture(path, {"cases": cases})
+
+
+def export_smart_resize(processor, path):
+    from transformers.models.qwen2_vl.image_processing_qwen2_vl import smart_resize
+
+    cases = []
+    for w, h, min_pixels, max_pixels in SMART_RESIZE_CASES:
+        resized_h, resized_w = smart_resize(
+            h, w, factor=28, min_pixels=min_pixels, max_pixels=max_pixels
+        )
+        cases.append({
+            "name": f"{w}x{h}",
+            "width": w,
+            "height": h,
+            "min_pixels": min_pixels,
+            "max_pixels": max_pixels,
+            "expect_width": resized_w,
+            "expect_height": resized_h,
+            "grid_w": resized_w // 28,
+            "grid_h": resized_h // 28,
+        })
+    write_fixture(path, {"cases": cases})
+
+
+def export_first_token_logits(model_path, tokenizer, path, top_k=16):
+    """Runs the reference model on CPU and records top-k first-token logits.
+
+    This is the heavyweight fixture; it is only needed for the
+    hardware-gated parity test in internal/mlx.
+    """
+    import torch
+    from transformers import AutoModelForCausalLM
+
+    model = AutoModelForCausalLM.from_pretrained(
+        model_path, torch_dtype=torch.float32, device_map="cpu"
+    ).eval()
+
+    cases = []
+    for text in SAMPLE_TEXTS[:2]:
+        ids = tokenizer.encode(text, add_special_tokens=False)
+        with torch.no_grad():
+            logits = model(torch.tensor([ids])).logits[0, -1]
+        values, indices = torch.topk(logits, top_k)
+        cases.append({
+            "prompt_tokens": ids,
+            "top_token": int(indices[0]),
+            "top_logits": [
+                {"token": int(t), "value": float(v)}
+                for t, v in zip(indices, values)
+            ],
+        })
+    write_fixture(path, {"cases": cases})
+
+
+def write_fixture(path, data):
+    os.makedirs(os.path.dirname(path), exist_ok=True)
+    with open(path, "w") as f:
+        json.dump(data, f, indent=2)
+        f.write("\n")
+    print(f"Wrote {path}")
+
+
+def main():
+    if len(sys.argv) < 2:
+        print(__doc__)
+        sys.exit(1)
+
+    model_path = sys.argv[1]
+    output_dir = sys.argv[2] if len(sys.argv) > 2 else None
+
+    def dest(default):
+        if output_dir:
+            return os.path.join(output_dir, os.path.basename(default))
+        return default
+
+    tokenizer = AutoTokenizer.from_pretrained(model_path)
+    export_tokenizer_encodings(
+        tokenizer, dest("pkg/tokenizer/testdata/golden/tokenizer_encodings.json")
+    )
+    export_chat_template(
+        tokenizer, dest("pkg/tokenizer/testdata/golden/chat_template.json")
+    )
+
+    processor = AutoProcessor.from_pretrained(model_path)
+    export_smart_resize(
+        processor, dest("src/pkg/vision/testdata/golden/smart_resize.json")
+    )
+
+    try:
+        export_first_token_logits(
+            model_path,
+            tokenizer,
+            dest("internal/mlx/testdata/golden/first_token_logits.json"),
+        )
+    except Exception as e:
+        print(f"Skipping first-token logits fixture: {e}")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,14 @@
+// Command mlxvllm is the unified CLI: serve runs the inference server,
+// and the remaining subcommands cover benchmarking, replay, model cache
+// management, tokenization, and interactive chat.
+package main
+
+import (
+	"os"
+
+	"github.com/agenthands/mlxvllm/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Execute())
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prompt logit inspection. POST /v1/analyze runs prefill only: the
+// prompt is rendered position by position and each position reports
+// the entropy of the next-token distribution plus its top-k
+// candidates, with no generation. Researchers use this to compare how
+// sharply the model predicts over screenshot patches versus
+// instruction text. Like debug traces, the output exposes token-level
+// distributions, so when admin keys are configured only they may call
+// it.
+
+const (
+	// DefaultAnalyzeTopK is how many candidates each position reports
+	// when the request does not say
+	DefaultAnalyzeTopK = 5
+
+	// maxAnalyzeTopK bounds per-position candidate lists so a single
+	// long-prompt request cannot produce an unbounded response
+	maxAnalyzeTopK = 20
+
+	// analyzeMockVocab is the vocabulary width of the fabricated
+	// distributions; entropies top out at ln(analyzeMockVocab)
+	analyzeMockVocab = 64
+)
+
+// AnalyzeRequest asks for prefill-only inspection of a prompt
+type AnalyzeRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	TopK     int       `json:"top_k,omitempty"`
+}
+
+// TokenProb is one candidate token and its probability mass
+type TokenProb struct {
+	TokenID int     `json:"token_id"`
+	Prob    float64 `json:"prob"`
+}
+
+// AnalyzePosition is the next-token distribution at one prompt
+// position. Entropy is in nats; image patches appear as
+// <|image_pad|> tokens inside a vision marker pair.
+type AnalyzePosition struct {
+	Index   int         `json:"index"`
+	Token   string      `json:"token"`
+	Entropy float64     `json:"entropy"`
+	TopK    []TokenProb `json:"top_k"`
+}
+
+// AnalyzeResponse answers an analyze request: one entry per prompt
+// position, in order
+type AnalyzeResponse struct {
+	Object       string            `json:"object"`
+	Created      int64             `json:"created"`
+	Model        string            `json:"model"`
+	PromptTokens int               `json:"prompt_tokens"`
+	Positions    []AnalyzePosition `json:"positions"`
+}
+
+// Analyze handles prefill-only prompt inspection requests
+func (h *Handler) Analyze(w http.ResponseWriter, r *http.Request) {
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	resolved, ok := h.resolveModel(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+	req.Model = resolved
+
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required for prompt analysis")
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages are required")
+		return
+	}
+	topK := req.TopK
+	switch {
+	case topK < 0:
+		writeError(w, http.StatusBadRequest, "top_k must be non-negative")
+		return
+	case topK == 0:
+		topK = DefaultAnalyzeTopK
+	case topK > maxAnalyzeTopK:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("top_k must be at most %d", maxAnalyzeTopK))
+		return
+	}
+
+	tokens, err := analyzeTokens(req.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := &AnalyzeResponse{
+		Object:       "chat.analysis",
+		Created:      time.Now().Unix(),
+		Model:        req.Model,
+		PromptTokens: len(tokens),
+		Positions:    analyzePositions(tokens, topK),
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// analyzeTokens renders the prompt as one token string per position.
+// Text splits on whitespace and each attached screenshot expands into
+// its resize-plan patch grid wrapped in vision markers, so positions
+// line up with what the dry-run accounting counts per image. ChatML
+// framing is omitted.
+// TODO: render through the real tokenizer once it is wired in
+func analyzeTokens(messages []Message) ([]string, error) {
+	var tokens []string
+	images := 0
+	for _, msg := range messages {
+		switch content := msg.Content.(type) {
+		case string:
+			tokens = append(tokens, strings.Fields(content)...)
+		case []interface{}:
+			for _, part := range content {
+				m, ok := part.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if text, ok := m["text"].(string); ok && text != "" {
+					tokens = append(tokens, strings.Fields(text)...)
+				}
+				imageURL, ok := m["image_url"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				url, ok := imageURL["url"].(string)
+				if !ok {
+					continue
+				}
+				preview, err := planImage(url, images, defaultMinPixels, defaultMaxPixels)
+				if err != nil {
+					return nil, err
+				}
+				images++
+				tokens = append(tokens, "<|vision_start|>")
+				for i := 0; i < preview.Tokens; i++ {
+					tokens = append(tokens, "<|image_pad|>")
+				}
+				tokens = append(tokens, "<|vision_end|>")
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// analyzePositions computes the per-position report. The mock engine
+// fabricates a stable distribution from each token text and its
+// position, so identical prompts analyze identically across runs.
+// TODO: read real prefill logits once the engine exposes them
+func analyzePositions(tokens []string, topK int) []AnalyzePosition {
+	positions := make([]AnalyzePosition, 0, len(tokens))
+	for i, token := range tokens {
+		probs := mockDistribution(token, i)
+
+		entropy := 0.0
+		for _, p := range probs {
+			if p > 0 {
+				entropy -= p * math.Log(p)
+			}
+		}
+
+		candidates := make([]TokenProb, len(probs))
+		for id, p := range probs {
+			candidates[id] = TokenProb{TokenID: id, Prob: p}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].Prob != candidates[b].Prob {
+				return candidates[a].Prob > candidates[b].Prob
+			}
+			return candidates[a].TokenID < candidates[b].TokenID
+		})
+		if len(candidates) > topK {
+			candidates = candidates[:topK]
+		}
+
+		positions = append(positions, AnalyzePosition{
+			Index:   i,
+			Token:   token,
+			Entropy: entropy,
+			TopK:    candidates,
+		})
+	}
+	return positions
+}
+
+// mockDistribution fabricates a softmax distribution over the mock
+// vocabulary, seeded from the token text and position via xorshift so
+// the same prompt always yields the same logits
+func mockDistribution(token string, index int) []float64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(token))
+	state := hash.Sum64() + uint64(index)*0x9e3779b97f4a7c15
+	if state == 0 {
+		state = 1
+	}
+
+	logits := make([]float64, analyzeMockVocab)
+	maxLogit := math.Inf(-1)
+	for i := range logits {
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		logits[i] = float64(state%1000) / 200
+		if logits[i] > maxLogit {
+			maxLogit = logits[i]
+		}
+	}
+
+	sum := 0.0
+	for i, l := range logits {
+		logits[i] = math.Exp(l - maxLogit)
+		sum += logits[i]
+	}
+	for i := range logits {
+		logits[i] /= sum
+	}
+	return logits
+}
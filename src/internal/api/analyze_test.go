@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"testing"
+)
+
+func postAnalyze(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/analyze", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.Analyze(rec, req)
+	return rec
+}
+
+func TestAnalyzeReportsPerPositionEntropy(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postAnalyze(t, h, `{"messages":[{"role":"user","content":"click the save button"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp AnalyzeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Object != "chat.analysis" {
+		t.Errorf("object = %q, want chat.analysis", resp.Object)
+	}
+	if resp.PromptTokens != 4 || len(resp.Positions) != 4 {
+		t.Fatalf("got %d prompt tokens and %d positions, want 4 of each", resp.PromptTokens, len(resp.Positions))
+	}
+
+	maxEntropy := math.Log(analyzeMockVocab)
+	for _, pos := range resp.Positions {
+		if pos.Entropy <= 0 || pos.Entropy > maxEntropy {
+			t.Errorf("position %d entropy = %v, want in (0, %v]", pos.Index, pos.Entropy, maxEntropy)
+		}
+		if len(pos.TopK) != DefaultAnalyzeTopK {
+			t.Fatalf("position %d has %d candidates, want %d", pos.Index, len(pos.TopK), DefaultAnalyzeTopK)
+		}
+		for i, cand := range pos.TopK {
+			if cand.Prob <= 0 || cand.Prob > 1 {
+				t.Errorf("position %d candidate %d prob = %v, want in (0, 1]", pos.Index, i, cand.Prob)
+			}
+			if i > 0 && cand.Prob > pos.TopK[i-1].Prob {
+				t.Errorf("position %d candidates not sorted by probability", pos.Index)
+			}
+		}
+	}
+	// No generation happened, so no completion fields leak through
+	if bytes.Contains(rec.Body.Bytes(), []byte("choices")) {
+		t.Error("analysis response carries choices")
+	}
+}
+
+func TestAnalyzeExpandsScreenshotPatches(t *testing.T) {
+	h := NewHandler(nil)
+
+	// 100x100 resizes to 84x84, a 3x3 patch grid, so the prompt is
+	// 2 words + vision markers + 9 patch positions
+	body := fmt.Sprintf(`{"messages":[{"role":"user","content":[
+		{"type":"text","text":"click save"},
+		{"type":"image_url","image_url":{"url":%q}}
+	]}]}`, pngDataURI(t, 100, 100))
+
+	rec := postAnalyze(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp AnalyzeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.PromptTokens != 13 {
+		t.Fatalf("prompt_tokens = %d, want 13", resp.PromptTokens)
+	}
+	pads := 0
+	for _, pos := range resp.Positions {
+		if pos.Token == "<|image_pad|>" {
+			pads++
+		}
+	}
+	if pads != 9 {
+		t.Errorf("got %d patch positions, want 9", pads)
+	}
+	if resp.Positions[2].Token != "<|vision_start|>" || resp.Positions[12].Token != "<|vision_end|>" {
+		t.Errorf("vision markers misplaced: %q ... %q", resp.Positions[2].Token, resp.Positions[12].Token)
+	}
+}
+
+func TestAnalyzeDeterministic(t *testing.T) {
+	h := NewHandler(nil)
+	body := `{"messages":[{"role":"user","content":"click the save button"}]}`
+
+	first := postAnalyze(t, h, body)
+	second := postAnalyze(t, h, body)
+
+	var a, b AnalyzeResponse
+	if err := json.Unmarshal(first.Body.Bytes(), &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(second.Body.Bytes(), &b); err != nil {
+		t.Fatal(err)
+	}
+	for i := range a.Positions {
+		if a.Positions[i].Entropy != b.Positions[i].Entropy {
+			t.Errorf("position %d entropy differs across runs: %v vs %v",
+				i, a.Positions[i].Entropy, b.Positions[i].Entropy)
+		}
+	}
+}
+
+func TestAnalyzeValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{`},
+		{"no messages", `{"messages":[]}`},
+		{"negative top_k", `{"top_k":-1,"messages":[{"role":"user","content":"click"}]}`},
+		{"top_k over cap", `{"top_k":100,"messages":[{"role":"user","content":"click"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(nil)
+			rec := postAnalyze(t, h, tt.body)
+			if rec.Code != 400 {
+				t.Errorf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAnalyzeRequiresAdminKey(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"admin-key"}})
+	body := `{"messages":[{"role":"user","content":"click"}]}`
+
+	rec := postAnalyze(t, h, body)
+	if rec.Code != 403 {
+		t.Fatalf("status without key = %d, want 403: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/v1/analyze", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer admin-key")
+	w := httptest.NewRecorder()
+	h.Analyze(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status with admin key = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/agenthands/mlxvllm/internal/audit"
+)
+
+// DefaultAuditLimit is the query window when none is given
+const DefaultAuditLimit = 100
+
+// SetAuditLog enables audit logging of admin operations
+func (h *Handler) SetAuditLog(l *audit.Log) {
+	h.audit = l
+}
+
+// auditLog records an admin operation with the caller as actor
+func (h *Handler) auditLog(r *http.Request, action, target, detail string) {
+	if h.audit == nil {
+		return
+	}
+
+	entry := audit.Entry{
+		Actor:  apiKeyFrom(r),
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}
+	if err := h.audit.Append(entry); err != nil {
+		log.Printf("audit logging failed: %v", err)
+	}
+}
+
+// Audit handles GET /v1/audit: recent admin operations, oldest first.
+// When admin keys are configured, only they may query the log.
+func (h *Handler) Audit(w http.ResponseWriter, r *http.Request) {
+	if h.audit == nil {
+		writeError(w, http.StatusServiceUnavailable, "audit logging not enabled")
+		return
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to query the audit log")
+		return
+	}
+
+	limit := DefaultAuditLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := h.audit.Tail(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AuditResponse{
+		Object:  "list",
+		Entries: entries,
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/audit"
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+func newAuditHandler(t *testing.T) *Handler {
+	t.Helper()
+	l, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	h := NewHandler(nil)
+	h.SetAuditLog(l)
+	return h
+}
+
+func auditEntries(t *testing.T, h *Handler, key string) []audit.Entry {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/v1/audit", nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	h.Audit(w, req)
+	if w.Code != 200 {
+		t.Fatalf("Audit returned status %d: %s", w.Code, w.Body.String())
+	}
+	var resp AuditResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode audit response: %v", err)
+	}
+	return resp.Entries
+}
+
+func TestAuditDisabled(t *testing.T) {
+	h := NewHandler(nil)
+	w := httptest.NewRecorder()
+	h.Audit(w, httptest.NewRequest("GET", "/v1/audit", nil))
+	if w.Code != 503 {
+		t.Errorf("Audit status = %d, want 503", w.Code)
+	}
+}
+
+func TestAuditAdminGating(t *testing.T) {
+	h := newAuditHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"sk-admin"}})
+
+	req := httptest.NewRequest("GET", "/v1/audit", nil)
+	req.Header.Set("X-API-Key", "sk-lab-1")
+	w := httptest.NewRecorder()
+	h.Audit(w, req)
+	if w.Code != 403 {
+		t.Errorf("Audit status for non-admin = %d, want 403", w.Code)
+	}
+
+	if got := auditEntries(t, h, "sk-admin"); len(got) != 0 {
+		t.Errorf("Audit returned %d entries, want 0", len(got))
+	}
+}
+
+func TestAuditBadLimit(t *testing.T) {
+	h := newAuditHandler(t)
+
+	tests := []struct {
+		name  string
+		limit string
+	}{
+		{"non-numeric", "abc"},
+		{"zero", "0"},
+		{"negative", "-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Audit(w, httptest.NewRequest("GET", "/v1/audit?limit="+tt.limit, nil))
+			if w.Code != 400 {
+				t.Errorf("Audit status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
+func TestAuditRecordsQuotaSet(t *testing.T) {
+	h := newAuditHandler(t)
+	store, err := usage.Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("Failed to open usage store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	h.SetUsageStore(store)
+
+	body, _ := json.Marshal(QuotaRequest{APIKey: "sk-lab-1", DailyTokens: 100})
+	req := httptest.NewRequest("PUT", "/v1/usage/quota", bytes.NewReader(body))
+	req.Header.Set("X-API-Key", "sk-admin")
+	w := httptest.NewRecorder()
+	h.SetQuota(w, req)
+	if w.Code != 200 {
+		t.Fatalf("SetQuota returned status %d: %s", w.Code, w.Body.String())
+	}
+
+	entries := auditEntries(t, h, "sk-admin")
+	if len(entries) != 1 {
+		t.Fatalf("Audit returned %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Actor != "sk-admin" || e.Action != "quota.set" || e.Target != "sk-lab-1" {
+		t.Errorf("audit entry = %+v, want actor sk-admin action quota.set target sk-lab-1", e)
+	}
+	if e.Detail != "daily=100 monthly=0" {
+		t.Errorf("audit detail = %q, want %q", e.Detail, "daily=100 monthly=0")
+	}
+}
+
+func TestAuditLogNilIsNoOp(t *testing.T) {
+	h := NewHandler(nil)
+	// Must not panic when audit logging is disabled
+	h.auditLog(httptest.NewRequest("POST", "/v1/models/x/load", nil), "model.load", "x", "")
+}
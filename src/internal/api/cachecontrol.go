@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/agenthands/mlxvllm/internal/prefixcache"
+)
+
+// SetPrefixPins enables cache_control handling for completion requests
+func (h *Handler) SetPrefixPins(t *prefixcache.Tracker) {
+	h.pins = t
+}
+
+// applyCacheControl registers the prefixes that messages marked for
+// pinning; the return value reports whether the response was already
+// written. Token counts are estimated until the tokenizer is wired in.
+func (h *Handler) applyCacheControl(w http.ResponseWriter, req *ChatCompletionRequest) bool {
+	var prefix strings.Builder
+	for i := range req.Messages {
+		msg := &req.Messages[i]
+		if text, ok := msg.Content.(string); ok {
+			prefix.WriteString(text)
+		}
+		cc := msg.CacheControl
+		if cc == nil {
+			continue
+		}
+
+		var kind prefixcache.Kind
+		switch cc.Type {
+		case "ephemeral":
+			kind = prefixcache.Ephemeral
+		case "persistent":
+			kind = prefixcache.Persistent
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported cache_control type %q", cc.Type))
+			return true
+		}
+
+		if h.pins == nil {
+			continue
+		}
+		hash := prefixcache.HashPrefix(prefix.String())
+		err := h.pins.Pin(hash, estimateTokens(prefix.String()), kind)
+		if err == prefixcache.ErrBudgetExhausted {
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return true
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return true
+		}
+	}
+	return false
+}
+
+// estimateTokens approximates the token count of a prefix at four
+// characters per token.
+// TODO: count exactly once the tokenizer is wired in
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/prefixcache"
+)
+
+func TestCacheControlPinsPrefix(t *testing.T) {
+	h := NewHandler(nil)
+	tr := prefixcache.NewTracker(10000)
+	h.SetPrefixPins(tr)
+
+	body := `{"messages":[
+		{"role":"system","content":"You are a GUI agent.","cache_control":{"type":"persistent"}},
+		{"role":"user","content":"click save"}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := tr.PersistentTokens(); got == 0 {
+		t.Error("persistent marker did not consume budget")
+	}
+	if !tr.Pinned(prefixcache.HashPrefix("You are a GUI agent.")) {
+		t.Error("prefix up to the marked message not pinned")
+	}
+}
+
+func TestCacheControlBudgetExhausted(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetPrefixPins(prefixcache.NewTracker(1))
+
+	long := strings.Repeat("a long system prompt ", 20)
+	body := `{"messages":[{"role":"system","content":"` + long + `","cache_control":{"type":"persistent"}}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 429 {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+}
+
+func TestCacheControlUnknownType(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := `{"messages":[{"role":"system","content":"hi","cache_control":{"type":"forever"}}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 400 {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestCacheControlEphemeralWithoutTracker(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := `{"messages":[{"role":"system","content":"hi","cache_control":{"type":"ephemeral"}},{"role":"user","content":"click save"}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
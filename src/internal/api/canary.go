@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+// Canary rollout splits one client-facing model name between a stable
+// and a canary version by percentage, so a new fine-tune can take a
+// growing slice of real traffic before replacing the stable version.
+// Routes are adjusted at runtime through the admin API; per-version
+// request counters make the split (and its effect) observable.
+
+// canaryRoute is one traffic split, keyed by the client-facing name
+type canaryRoute struct {
+	stable  string
+	canary  string
+	percent float64 // 0..100 share of traffic sent to the canary
+
+	stableHits int64
+	canaryHits int64
+}
+
+// SetCanary handles PUT /v1/admin/canary/{model}: install or replace a
+// traffic split. When admin keys are configured, only they may call
+// this. Counters reset on update so each rollout step is measured
+// from zero.
+func (h *Handler) SetCanary(w http.ResponseWriter, r *http.Request) {
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to manage canary routes")
+		return
+	}
+
+	var req CanaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Stable == "" || req.Canary == "" {
+		writeError(w, http.StatusBadRequest, "stable and canary are required")
+		return
+	}
+	if req.Percent < 0 || req.Percent > 100 {
+		writeError(w, http.StatusBadRequest, "percent must be between 0 and 100")
+		return
+	}
+	if h.registry != nil {
+		for _, version := range []string{req.Stable, req.Canary} {
+			if !h.registry.HasModel(version) {
+				writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", version))
+				return
+			}
+		}
+	}
+
+	name := mux.Vars(r)["model"]
+	h.canaryMu.Lock()
+	if h.canaries == nil {
+		h.canaries = make(map[string]*canaryRoute)
+	}
+	h.canaries[name] = &canaryRoute{stable: req.Stable, canary: req.Canary, percent: req.Percent}
+	h.canaryMu.Unlock()
+
+	h.auditLog(r, "canary.set", name,
+		fmt.Sprintf("stable=%s canary=%s percent=%g", req.Stable, req.Canary, req.Percent))
+
+	writeJSON(w, http.StatusOK, CanaryStatus{
+		Model:   name,
+		Stable:  req.Stable,
+		Canary:  req.Canary,
+		Percent: req.Percent,
+	})
+}
+
+// ListCanaries handles GET /v1/admin/canary: every active split with
+// its per-version request counters
+func (h *Handler) ListCanaries(w http.ResponseWriter, r *http.Request) {
+	h.canaryMu.RLock()
+	resp := CanaryListResponse{Object: "list", Data: make([]CanaryStatus, 0, len(h.canaries))}
+	for name, route := range h.canaries {
+		resp.Data = append(resp.Data, CanaryStatus{
+			Model:          name,
+			Stable:         route.stable,
+			Canary:         route.canary,
+			Percent:        route.percent,
+			StableRequests: atomic.LoadInt64(&route.stableHits),
+			CanaryRequests: atomic.LoadInt64(&route.canaryHits),
+		})
+	}
+	h.canaryMu.RUnlock()
+
+	sort.Slice(resp.Data, func(i, j int) bool { return resp.Data[i].Model < resp.Data[j].Model })
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteCanary handles DELETE /v1/admin/canary/{model}: remove a split
+// so the name routes normally again
+func (h *Handler) DeleteCanary(w http.ResponseWriter, r *http.Request) {
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to manage canary routes")
+		return
+	}
+
+	name := mux.Vars(r)["model"]
+	h.canaryMu.Lock()
+	_, found := h.canaries[name]
+	delete(h.canaries, name)
+	h.canaryMu.Unlock()
+	if !found {
+		writeError(w, http.StatusNotFound, "no canary route for model")
+		return
+	}
+
+	h.auditLog(r, "canary.delete", name, "")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// pickCanary rolls the traffic split for a resolved model name, if one
+// is installed, and counts which version won
+func (h *Handler) pickCanary(name string) string {
+	h.canaryMu.RLock()
+	route := h.canaries[name]
+	h.canaryMu.RUnlock()
+	if route == nil {
+		return name
+	}
+
+	if rand.Float64()*100 < route.percent {
+		atomic.AddInt64(&route.canaryHits, 1)
+		return route.canary
+	}
+	atomic.AddInt64(&route.stableHits, 1)
+	return route.stable
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func putCanary(t *testing.T, h *Handler, model, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("PUT", "/v1/admin/canary/"+model, bytes.NewReader([]byte(body)))
+	req = mux.SetURLVars(req, map[string]string{"model": model})
+	w := httptest.NewRecorder()
+	h.SetCanary(w, req)
+	return w
+}
+
+func TestSetCanaryValidation(t *testing.T) {
+	h := NewHandler(nil)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"valid", `{"stable":"gui-actor-2b","canary":"gui-actor-2b-v2","percent":10}`, 200},
+		{"full cutover", `{"stable":"gui-actor-2b","canary":"gui-actor-2b-v2","percent":100}`, 200},
+		{"missing stable", `{"canary":"gui-actor-2b-v2","percent":10}`, 400},
+		{"missing canary", `{"stable":"gui-actor-2b","percent":10}`, 400},
+		{"negative percent", `{"stable":"a","canary":"b","percent":-1}`, 400},
+		{"over 100 percent", `{"stable":"a","canary":"b","percent":101}`, 400},
+		{"invalid json", `{`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := putCanary(t, h, "gui-actor", tt.body); w.Code != tt.code {
+				t.Errorf("SetCanary status = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCanarySplitRoutesTraffic(t *testing.T) {
+	h := NewHandler(nil)
+	putCanary(t, h, "gui-actor", `{"stable":"gui-actor-2b","canary":"gui-actor-2b-v2","percent":50}`)
+
+	seen := map[string]int{}
+	for i := 0; i < 500; i++ {
+		name, ok := h.resolveModel("gui-actor")
+		if !ok {
+			t.Fatal("resolveModel rejected a canary-routed name")
+		}
+		seen[name]++
+	}
+	// At 50% both versions should serve a healthy share; bounds are
+	// loose enough to never flake
+	if seen["gui-actor-2b"] < 100 || seen["gui-actor-2b-v2"] < 100 {
+		t.Errorf("traffic split = %v, want both versions well represented", seen)
+	}
+}
+
+func TestCanaryPercentEdges(t *testing.T) {
+	h := NewHandler(nil)
+
+	putCanary(t, h, "gui-actor", `{"stable":"stable-v","canary":"canary-v","percent":0}`)
+	for i := 0; i < 50; i++ {
+		if name, _ := h.resolveModel("gui-actor"); name != "stable-v" {
+			t.Fatalf("at 0%% resolved %q, want stable", name)
+		}
+	}
+
+	putCanary(t, h, "gui-actor", `{"stable":"stable-v","canary":"canary-v","percent":100}`)
+	for i := 0; i < 50; i++ {
+		if name, _ := h.resolveModel("gui-actor"); name != "canary-v" {
+			t.Fatalf("at 100%% resolved %q, want canary", name)
+		}
+	}
+}
+
+func TestListCanariesCountsPerVersion(t *testing.T) {
+	h := NewHandler(nil)
+	putCanary(t, h, "gui-actor", `{"stable":"stable-v","canary":"canary-v","percent":0}`)
+	for i := 0; i < 7; i++ {
+		h.resolveModel("gui-actor")
+	}
+
+	w := httptest.NewRecorder()
+	h.ListCanaries(w, httptest.NewRequest("GET", "/v1/admin/canary", nil))
+	var resp CanaryListResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("got %d routes, want 1", len(resp.Data))
+	}
+	got := resp.Data[0]
+	if got.Model != "gui-actor" || got.StableRequests != 7 || got.CanaryRequests != 0 {
+		t.Errorf("route = %+v, want 7 stable requests on gui-actor", got)
+	}
+}
+
+func TestDeleteCanaryRestoresRouting(t *testing.T) {
+	h := NewHandler(nil)
+	putCanary(t, h, "gui-actor", `{"stable":"stable-v","canary":"canary-v","percent":100}`)
+
+	req := httptest.NewRequest("DELETE", "/v1/admin/canary/gui-actor", nil)
+	req = mux.SetURLVars(req, map[string]string{"model": "gui-actor"})
+	w := httptest.NewRecorder()
+	h.DeleteCanary(w, req)
+	if w.Code != 200 {
+		t.Fatalf("DeleteCanary status = %d", w.Code)
+	}
+
+	if name, _ := h.resolveModel("gui-actor"); name != "gui-actor" {
+		t.Errorf("after delete resolved %q, want the name untouched", name)
+	}
+
+	w = httptest.NewRecorder()
+	h.DeleteCanary(w, mux.SetURLVars(httptest.NewRequest("DELETE", "/v1/admin/canary/gui-actor", nil),
+		map[string]string{"model": "gui-actor"}))
+	if w.Code != 404 {
+		t.Errorf("deleting a missing route status = %d, want 404", w.Code)
+	}
+}
+
+func TestCanaryRequiresAdminKey(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"admin-secret"}})
+
+	w := putCanary(t, h, "gui-actor", `{"stable":"a","canary":"b","percent":10}`)
+	if w.Code != 403 {
+		t.Errorf("SetCanary without admin key status = %d, want 403", w.Code)
+	}
+
+	req := httptest.NewRequest("PUT", "/v1/admin/canary/gui-actor",
+		bytes.NewReader([]byte(`{"stable":"a","canary":"b","percent":10}`)))
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	req = mux.SetURLVars(req, map[string]string{"model": "gui-actor"})
+	w = httptest.NewRecorder()
+	h.SetCanary(w, req)
+	if w.Code != 200 {
+		t.Errorf("SetCanary with admin key status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+// OpenAI-compat strictness. GUI-Actor extends the chat completion
+// schema with grounding fields; by default they appear both at their
+// historical top-level spots on Choice and under the versioned
+// x_gui_actor extension object, so existing clients keep working while
+// new ones feature-detect on x_gui_actor.schema_version. Strict mode
+// drops the top-level duplicates and the custom grounding SSE event,
+// leaving a response an unmodified OpenAI SDK validates cleanly.
+
+// SetCompatStrictness toggles strict OpenAI compatibility. When strict,
+// GUI-Actor fields appear only under the x_gui_actor extension.
+func (h *Handler) SetCompatStrictness(strict bool) {
+	h.compatStrict = strict
+}
+
+// newExtension builds the namespaced extension for a grounded result
+func newExtension(point, raw *Point) *GUIActorExtension {
+	if point == nil && raw == nil {
+		return nil
+	}
+	return &GUIActorExtension{
+		SchemaVersion:  ExtensionSchemaVersion,
+		Coordinates:    point,
+		RawCoordinates: raw,
+	}
+}
+
+// applyCompat strips the legacy top-level duplicates in strict mode;
+// the extension object keeps the grounded coordinates
+func (h *Handler) applyCompat(resp *ChatCompletionResponse) {
+	if !h.compatStrict {
+		return
+	}
+	for i := range resp.Choices {
+		resp.Choices[i].Coordinates = nil
+		resp.Choices[i].RawCoordinates = nil
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtensionNamespacePresentByDefault(t *testing.T) {
+	h := NewHandler(nil)
+	resp := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if resp.Code != 200 {
+		t.Fatalf("status = %d", resp.Code)
+	}
+
+	var parsed ChatCompletionResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &parsed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	choice := parsed.Choices[0]
+	if choice.Coordinates == nil {
+		t.Error("legacy coordinates missing in default mode")
+	}
+	ext := choice.XGUIActor
+	if ext == nil {
+		t.Fatal("x_gui_actor extension missing")
+	}
+	if ext.SchemaVersion != ExtensionSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", ext.SchemaVersion, ExtensionSchemaVersion)
+	}
+	if ext.Coordinates == nil || *ext.Coordinates != *choice.Coordinates {
+		t.Errorf("extension coordinates = %v, want %v", ext.Coordinates, choice.Coordinates)
+	}
+}
+
+func TestStrictCompatDropsLegacyFields(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetCompatStrictness(true)
+	resp := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if resp.Code != 200 {
+		t.Fatalf("status = %d", resp.Code)
+	}
+
+	var top struct {
+		Choices []map[string]json.RawMessage `json:"choices"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &top); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	choice := top.Choices[0]
+	for _, legacy := range []string{"coordinates", "raw_coordinates"} {
+		if _, ok := choice[legacy]; ok {
+			t.Errorf("strict mode leaked top-level %q", legacy)
+		}
+	}
+	var ext GUIActorExtension
+	if err := json.Unmarshal(choice["x_gui_actor"], &ext); err != nil {
+		t.Fatalf("x_gui_actor: %v", err)
+	}
+	if ext.SchemaVersion != ExtensionSchemaVersion || ext.Coordinates == nil {
+		t.Errorf("extension = %+v, want version %d with coordinates", ext, ExtensionSchemaVersion)
+	}
+}
+
+func TestStrictCompatSuppressesGroundingEvent(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetCompatStrictness(true)
+	body := `{"stream":true,"messages":[{"role":"user","content":"click save"}]}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "chat.grounding") {
+		t.Error("strict mode still streamed the grounding event")
+	}
+	if !strings.Contains(w.Body.String(), "chat.completion.chunk") {
+		t.Error("stream lost its completion chunks")
+	}
+}
+
+func TestGroundingEventCarriesExtension(t *testing.T) {
+	h := NewHandler(nil)
+	body := `{"stream":true,"messages":[{"role":"user","content":"click save"}]}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		payload, ok := strings.CutPrefix(line, "data: ")
+		if !ok || !strings.Contains(payload, "chat.grounding") {
+			continue
+		}
+		var event GroundingEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			t.Fatalf("grounding event: %v", err)
+		}
+		if event.XGUIActor == nil || event.XGUIActor.SchemaVersion != ExtensionSchemaVersion {
+			t.Errorf("grounding extension = %+v", event.XGUIActor)
+		}
+		return
+	}
+	t.Fatal("no grounding event in stream")
+}
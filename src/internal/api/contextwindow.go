@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"strings"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// DefaultContextLength is assumed when neither the request nor the
+// model's config bounds the context window
+const DefaultContextLength = 8192
+
+// SetContextLengths configures per-model context windows, used to
+// report context_remaining in usage blocks
+func (h *Handler) SetContextLengths(lengths map[string]int) {
+	h.contextLengths = lengths
+}
+
+// contextLength resolves the effective window for a request: the
+// request's own max_context first, then the model's configured length,
+// then the default
+func (h *Handler) contextLength(req *ChatCompletionRequest) int {
+	if req.MaxContext != nil && *req.MaxContext > 0 {
+		return *req.MaxContext
+	}
+	if n, ok := h.contextLengths[req.Model]; ok && n > 0 {
+		return n
+	}
+	return DefaultContextLength
+}
+
+// completionUsage builds the usage block for a finished turn: prompt
+// tokens split by modality, plus how much of the context window is
+// left for the next turn
+func (h *Handler) completionUsage(req *ChatCompletionRequest, assistant *Message) Usage {
+	text := estimateMessageTokens(req.Messages)
+	images := estimateImageTokens(req.Messages, req.MinPixels, req.MaxPixels)
+	completion := estimateMessageTokens([]Message{*assistant})
+
+	u := Usage{
+		PromptTokens:     text + images,
+		CompletionTokens: completion,
+		TotalTokens:      text + images + completion,
+		PromptTokensDetails: &PromptTokensDetails{
+			TextTokens:  text,
+			ImageTokens: images,
+		},
+	}
+	if remaining := h.contextLength(req) - u.TotalTokens; remaining > 0 {
+		u.ContextRemaining = remaining
+	}
+	return u
+}
+
+// estimateImageTokens counts the patch-grid tokens every inline image
+// will occupy, from its header dimensions and the resize plan alone.
+// Images that cannot be decoded contribute nothing here; the real
+// pipeline rejects them with a proper error.
+func estimateImageTokens(messages []Message, minPixels, maxPixels *int) int {
+	minP, maxP := defaultMinPixels, defaultMaxPixels
+	if minPixels != nil {
+		minP = *minPixels
+	}
+	if maxPixels != nil {
+		maxP = *maxPixels
+	}
+
+	tokens := 0
+	for _, msg := range messages {
+		parts, ok := msg.Content.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, part := range parts {
+			m, ok := part.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			imageURL, ok := m["image_url"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			url, ok := imageURL["url"].(string)
+			if !ok {
+				continue
+			}
+			idx := strings.Index(url, "base64,")
+			if idx < 0 {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(url[idx+len("base64,"):])
+			if err != nil {
+				continue
+			}
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				continue
+			}
+			w, h := vision.PlanResize(cfg.Width, cfg.Height, minP, maxP)
+			gridW, gridH := vision.CalculateGrid(w, h)
+			tokens += gridW*gridH + visionMarkerTokens
+		}
+	}
+	return tokens
+}
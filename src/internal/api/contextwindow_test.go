@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestUsageSplitsPromptByModality(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := fmt.Sprintf(`{"messages":[{"role":"user","content":[
+		{"type":"text","text":"click the save button"},
+		{"type":"image_url","image_url":{"url":%q}}
+	]}]}`, pngDataURI(t, 100, 100))
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	details := resp.Usage.PromptTokensDetails
+	if details == nil {
+		t.Fatal("usage has no prompt_tokens_details")
+	}
+	if details.TextTokens == 0 {
+		t.Error("text tokens not counted")
+	}
+	// 100x100 grid-aligns to 84x84: a 3x3 grid plus the vision markers
+	if details.ImageTokens != 9+visionMarkerTokens {
+		t.Errorf("image tokens = %d, want %d", details.ImageTokens, 9+visionMarkerTokens)
+	}
+	if resp.Usage.PromptTokens != details.TextTokens+details.ImageTokens {
+		t.Errorf("prompt_tokens = %d, want text+image = %d",
+			resp.Usage.PromptTokens, details.TextTokens+details.ImageTokens)
+	}
+}
+
+func TestContextRemainingUsesDefaultWindow(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	want := DefaultContextLength - resp.Usage.TotalTokens
+	if resp.Usage.ContextRemaining != want {
+		t.Errorf("context_remaining = %d, want %d", resp.Usage.ContextRemaining, want)
+	}
+}
+
+func TestContextRemainingHonorsRequestLimit(t *testing.T) {
+	h := NewHandler(nil)
+
+	// A window smaller than the prompt leaves nothing; the field is
+	// clamped rather than going negative
+	rec := postCompletion(t, h, `{"max_context":1,"messages":[{"role":"user","content":"click the save button now"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Usage.ContextRemaining != 0 {
+		t.Errorf("context_remaining = %d, want 0", resp.Usage.ContextRemaining)
+	}
+}
+
+func TestContextRemainingUsesModelWindow(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetContextLengths(map[string]int{"gui-actor-2b": 100})
+
+	rec := postCompletion(t, h, `{"model":"gui-actor-2b","messages":[{"role":"user","content":"click save"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	want := 100 - resp.Usage.TotalTokens
+	if resp.Usage.ContextRemaining != want {
+		t.Errorf("context_remaining = %d, want %d", resp.Usage.ContextRemaining, want)
+	}
+}
+
+func TestEstimateImageTokensSkipsUndecodable(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: []interface{}{
+			map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]interface{}{"url": "data:image/png;base64,bm90IGFuIGltYWdl"},
+			},
+		}},
+	}
+	if got := estimateImageTokens(messages, nil, nil); got != 0 {
+		t.Errorf("estimateImageTokens = %d, want 0 for undecodable image", got)
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/audit"
+	"github.com/agenthands/mlxvllm/internal/model"
+)
+
+// SetMemoryLimit tells the dashboard the configured memory budget so
+// usage renders as a fraction of it
+func (h *Handler) SetMemoryLimit(gb float64) {
+	h.memLimitGB = gb
+}
+
+// dashboardData is everything the dashboard template renders
+type dashboardData struct {
+	Uptime        string
+	MemoryUsedGB  float64
+	MemoryLimitGB float64
+	MemoryPct     int
+	Models        []model.ModelStatus
+	ActiveStreams int
+	SchedulerOn   bool
+	Slots         int
+	InFlight      int
+	Queued        int
+	Usage         []dashboardUsage
+	AuditEntries  []audit.Entry
+}
+
+// dashboardUsage is one API key's rollup over the last seven days
+type dashboardUsage struct {
+	Key      string
+	Requests int64
+	Tokens   int64
+}
+
+// dashboardTemplate is the embedded single-page dashboard; it reuses
+// the same sources as the JSON endpoints so the numbers always agree
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>mlxvllm dashboard</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; } h2 { font-size: 1.1em; margin-top: 1.5em; }
+table { border-collapse: collapse; min-width: 30em; }
+th, td { text-align: left; padding: 0.3em 1em 0.3em 0; border-bottom: 1px solid #ddd; }
+.bar { background: #eee; width: 20em; height: 1em; display: inline-block; }
+.bar span { background: #4a90d9; height: 100%; display: block; }
+.muted { color: #888; }
+</style>
+</head>
+<body>
+<h1>mlxvllm</h1>
+<p>Uptime {{.Uptime}} &middot; {{.ActiveStreams}} active stream(s)</p>
+
+<h2>Memory</h2>
+{{if gt .MemoryLimitGB 0.0}}
+<p>{{printf "%.1f" .MemoryUsedGB}} / {{printf "%.1f" .MemoryLimitGB}} GB
+<span class="bar"><span style="width: {{.MemoryPct}}%"></span></span></p>
+{{else}}
+<p>{{printf "%.1f" .MemoryUsedGB}} GB in use <span class="muted">(no limit configured)</span></p>
+{{end}}
+
+<h2>Models</h2>
+{{if .Models}}
+<table>
+<tr><th>Model</th><th>Loaded</th><th>Memory</th></tr>
+{{range .Models}}
+<tr><td>{{.Name}}</td><td>{{if .Loaded}}yes{{else}}no{{end}}</td><td>{{printf "%.1f" .MemoryGB}} GB</td></tr>
+{{end}}
+</table>
+{{else}}<p class="muted">No models registered.</p>{{end}}
+
+{{if .SchedulerOn}}
+<h2>Scheduler</h2>
+<p>{{.InFlight}} / {{.Slots}} slots in flight, {{.Queued}} queued</p>
+{{end}}
+
+<h2>Usage (last 7 days)</h2>
+{{if .Usage}}
+<table>
+<tr><th>API key</th><th>Requests</th><th>Tokens</th></tr>
+{{range .Usage}}
+<tr><td>{{.Key}}</td><td>{{.Requests}}</td><td>{{.Tokens}}</td></tr>
+{{end}}
+</table>
+{{else}}<p class="muted">Usage metering disabled or no traffic yet.</p>{{end}}
+
+<h2>Recent admin operations</h2>
+{{if .AuditEntries}}
+<table>
+<tr><th>Actor</th><th>Action</th><th>Target</th><th>Detail</th></tr>
+{{range .AuditEntries}}
+<tr><td>{{.Actor}}</td><td>{{.Action}}</td><td>{{.Target}}</td><td>{{.Detail}}</td></tr>
+{{end}}
+</table>
+{{else}}<p class="muted">Audit logging disabled or empty.</p>{{end}}
+</body>
+</html>
+`))
+
+// Dashboard renders the admin status page at /dashboard
+func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to view the dashboard")
+		return
+	}
+
+	data := dashboardData{
+		Uptime:        time.Since(h.startTime).Round(time.Second).String(),
+		MemoryLimitGB: h.memLimitGB,
+		ActiveStreams: h.streams.Active(),
+	}
+	if h.registry != nil {
+		data.MemoryUsedGB = h.registry.UsedGB()
+		data.Models = h.registry.Status()
+	}
+	if data.MemoryLimitGB > 0 {
+		data.MemoryPct = int(data.MemoryUsedGB / data.MemoryLimitGB * 100)
+		if data.MemoryPct > 100 {
+			data.MemoryPct = 100
+		}
+	}
+	if h.sched != nil {
+		data.SchedulerOn = true
+		data.Slots = h.sched.Slots()
+		data.InFlight = h.sched.InFlight()
+		data.Queued = h.sched.Queued()
+	}
+	if h.usage != nil {
+		data.Usage = h.recentUsage()
+	}
+	if h.audit != nil {
+		if entries, err := h.audit.Tail(10); err == nil {
+			data.AuditEntries = entries
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// recentUsage aggregates the last seven days of metering per API key
+func (h *Handler) recentUsage() []dashboardUsage {
+	keys, err := h.usage.Keys()
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -7)
+	var rows []dashboardUsage
+	for _, key := range keys {
+		report, err := h.usage.Query(key, from, now)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, dashboardUsage{
+			Key:      key,
+			Requests: report.Totals.Requests,
+			Tokens:   report.Totals.PromptTokens + report.Totals.CompletionTokens,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/model"
+)
+
+func newDashboardHandler(t *testing.T) *Handler {
+	t.Helper()
+	cfg := &config.Config{
+		Models: map[string]config.ModelConfig{
+			"gui-actor-2b": {Path: "./models/gui-actor-2b", Enabled: true},
+		},
+	}
+	return NewHandler(model.NewRegistry(cfg))
+}
+
+func TestDashboardRenders(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetMemoryLimit(32)
+
+	w := httptest.NewRecorder()
+	h.Dashboard(w, httptest.NewRequest("GET", "/dashboard", nil))
+
+	if w.Code != 200 {
+		t.Fatalf("Dashboard status = %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"gui-actor-2b", "32.0 GB", "Uptime"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Dashboard body missing %q", want)
+		}
+	}
+}
+
+func TestDashboardWithoutRegistry(t *testing.T) {
+	h := NewHandler(nil)
+
+	w := httptest.NewRecorder()
+	h.Dashboard(w, httptest.NewRequest("GET", "/dashboard", nil))
+
+	if w.Code != 200 {
+		t.Fatalf("Dashboard status = %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "No models registered") {
+		t.Error("Dashboard missing empty-registry placeholder")
+	}
+}
+
+func TestDashboardAdminGating(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"sk-admin"}})
+
+	// Without an admin key the page is forbidden
+	req := httptest.NewRequest("GET", "/dashboard", nil)
+	req.Header.Set("X-API-Key", "sk-lab-1")
+	w := httptest.NewRecorder()
+	h.Dashboard(w, req)
+	if w.Code != 403 {
+		t.Errorf("Dashboard status = %d for non-admin, want 403", w.Code)
+	}
+
+	// Admins get through
+	req = httptest.NewRequest("GET", "/dashboard", nil)
+	req.Header.Set("X-API-Key", "sk-admin")
+	w = httptest.NewRecorder()
+	h.Dashboard(w, req)
+	if w.Code != 200 {
+		t.Errorf("Dashboard status = %d for admin, want 200", w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Token-level debug tracing. A request carrying `debug: true` gets a
+// per-step trace back alongside the normal response so bad grounding
+// outputs can be diagnosed without attaching a profiler. Traces expose
+// token IDs and logprobs, so when admin keys are configured only they
+// may request one.
+
+// DebugTopLogprobs is how many candidate tokens each step records
+const DebugTopLogprobs = 5
+
+// DebugStep records one decode step of a traced request
+type DebugStep struct {
+	Step       int   `json:"step"`
+	TokenID    int   `json:"token_id"`
+	DurationUS int64 `json:"duration_us"`
+	// TopLogprobs are the highest-probability candidates at this step,
+	// sampled token first
+	TopLogprobs []TokenLogprob `json:"top_logprobs"`
+	// Batched reports whether the step ran in a batch with other
+	// requests rather than alone
+	Batched bool `json:"batched"`
+}
+
+// TokenLogprob is one candidate token and its log probability
+type TokenLogprob struct {
+	TokenID int     `json:"token_id"`
+	Logprob float64 `json:"logprob"`
+}
+
+// DebugTrace is the full trace attached to a debug-mode response
+type DebugTrace struct {
+	// CacheHandle identifies the prefix-cache entry the request ran
+	// against, if any
+	CacheHandle string      `json:"cache_handle"`
+	Steps       []DebugStep `json:"steps"`
+}
+
+// gateDebug rejects debug-mode requests from non-admin keys; the
+// return value reports whether the response was already written
+func (h *Handler) gateDebug(w http.ResponseWriter, r *http.Request, req *ChatCompletionRequest) bool {
+	if !req.Debug {
+		return false
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required for debug tracing")
+		return true
+	}
+	return false
+}
+
+// debugTrace builds the trace for a traced request. The mock engine
+// fabricates one step per generated byte with flat logprobs.
+// TODO: populate from real engine step callbacks
+func debugTrace(content string, start time.Time) *DebugTrace {
+	elapsed := time.Since(start).Microseconds()
+	steps := make([]DebugStep, 0, len(content))
+	for i, b := range []byte(content) {
+		step := DebugStep{
+			Step:       i,
+			TokenID:    int(b),
+			DurationUS: elapsed / int64(len(content)),
+		}
+		for j := 0; j < DebugTopLogprobs; j++ {
+			step.TopLogprobs = append(step.TopLogprobs, TokenLogprob{
+				TokenID: int(b) + j,
+				Logprob: -float64(j),
+			})
+		}
+		steps = append(steps, step)
+	}
+	return &DebugTrace{
+		CacheHandle: fmt.Sprintf("cache-%d", start.Unix()),
+		Steps:       steps,
+	}
+}
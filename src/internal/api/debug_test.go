@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postDebugCompletion(t *testing.T, h *Handler, apiKey string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	rec := httptest.NewRecorder()
+	h.ChatCompletion(rec, req)
+	return rec
+}
+
+func TestDebugTraceAttached(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postDebugCompletion(t, h, "", `{"debug":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Debug == nil {
+		t.Fatal("debug trace missing from response")
+	}
+	if len(resp.Debug.Steps) == 0 {
+		t.Fatal("debug trace has no steps")
+	}
+	if resp.Debug.CacheHandle == "" {
+		t.Error("debug trace missing cache handle")
+	}
+	for _, step := range resp.Debug.Steps {
+		if len(step.TopLogprobs) != DebugTopLogprobs {
+			t.Fatalf("step %d has %d logprobs, want %d", step.Step, len(step.TopLogprobs), DebugTopLogprobs)
+		}
+	}
+}
+
+func TestDebugOmittedByDefault(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postDebugCompletion(t, h, "", `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), `"debug"`) {
+		t.Error("response carries a debug trace without the flag")
+	}
+}
+
+func TestDebugRequiresAdminKey(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"sk-admin"}})
+
+	rec := postDebugCompletion(t, h, "sk-user", `{"debug":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 403 {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+
+	rec = postDebugCompletion(t, h, "sk-admin", `{"debug":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("admin status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"steps"`) {
+		t.Error("admin debug response missing trace")
+	}
+}
+
+func TestDebugStreamEvent(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postDebugCompletion(t, h, "", `{"debug":true,"stream":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: debug") {
+		t.Error("stream missing debug event")
+	}
+	if !strings.Contains(body, `"top_logprobs"`) {
+		t.Error("debug event missing logprobs")
+	}
+}
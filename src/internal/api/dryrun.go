@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// Pixel bounds used when neither the request nor its profile narrows
+// them; these mirror the 2B defaults in config.yaml
+const (
+	defaultMinPixels = 3136    // 56x56
+	defaultMaxPixels = 5720064 // ~3192x1792
+)
+
+// messageOverheadTokens approximates the ChatML framing around each
+// message (<|im_start|>role\n ... <|im_end|>\n)
+const messageOverheadTokens = 4
+
+// visionMarkerTokens is the <|vision_start|>/<|vision_end|> pair that
+// wraps each image's pad tokens in the rendered prompt
+const visionMarkerTokens = 2
+
+// TokenPreviewResponse answers a dry_run request: the prompt accounting
+// the model would see, with no generation performed. Image tokens are
+// exact (the resize plan is deterministic); text tokens are estimated
+// until the tokenizer is wired in.
+type TokenPreviewResponse struct {
+	Object       string         `json:"object"`
+	Created      int64          `json:"created"`
+	Model        string         `json:"model"`
+	PromptTokens int            `json:"prompt_tokens"`
+	TextTokens   int            `json:"text_tokens"`
+	ImageTokens  int            `json:"image_tokens"`
+	Images       []ImagePreview `json:"images,omitempty"`
+}
+
+// ImagePreview is the resize plan for one attached image, in message
+// order
+type ImagePreview struct {
+	Index         int `json:"index"`
+	Width         int `json:"width"`
+	Height        int `json:"height"`
+	ResizedWidth  int `json:"resized_width"`
+	ResizedHeight int `json:"resized_height"`
+	GridW         int `json:"grid_w"`
+	GridH         int `json:"grid_h"`
+	Tokens        int `json:"tokens"`
+	// Tiles is set when the image exceeds the tiling threshold and the
+	// serving path splits it instead of downscaling; ResizedWidth,
+	// ResizedHeight and the grid then describe one tile, Tokens the sum
+	// over all tiles
+	Tiles int `json:"tiles,omitempty"`
+}
+
+// dryRunPreview answers dry_run requests with the token accounting of
+// the rendered prompt; the return value reports whether the response
+// was already written. Runs after presets so injected system prompts
+// are counted, and before anything stateful (cache pins, sessions,
+// recording) is touched.
+func (h *Handler) dryRunPreview(w http.ResponseWriter, req *ChatCompletionRequest) bool {
+	if !req.DryRun {
+		return false
+	}
+
+	minPixels, maxPixels := defaultMinPixels, defaultMaxPixels
+	if req.MinPixels != nil {
+		minPixels = *req.MinPixels
+	}
+	if req.MaxPixels != nil {
+		maxPixels = *req.MaxPixels
+	}
+
+	resp := &TokenPreviewResponse{
+		Object:  "chat.completion.preview",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+	}
+
+	for _, msg := range req.Messages {
+		resp.TextTokens += messageOverheadTokens
+		switch content := msg.Content.(type) {
+		case string:
+			resp.TextTokens += estimateTokens(content)
+		case []interface{}:
+			for _, part := range content {
+				m, ok := part.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if text, ok := m["text"].(string); ok && text != "" {
+					resp.TextTokens += estimateTokens(text)
+				}
+				imageURL, ok := m["image_url"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				url, ok := imageURL["url"].(string)
+				if !ok {
+					continue
+				}
+				preview, err := planImage(url, len(resp.Images), minPixels, maxPixels)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return true
+				}
+				resp.Images = append(resp.Images, preview)
+				resp.ImageTokens += preview.Tokens + visionMarkerTokens
+			}
+		}
+	}
+
+	resp.PromptTokens = resp.TextTokens + resp.ImageTokens
+	writeJSON(w, http.StatusOK, resp)
+	return true
+}
+
+// planImage decodes one inline image and runs the same resize or
+// tiling plan the vision tower would apply, reporting the resulting
+// patch grid without normalizing any pixels
+func planImage(url string, index, minPixels, maxPixels int) (ImagePreview, error) {
+	idx := strings.Index(url, "base64,")
+	if idx < 0 {
+		return ImagePreview{}, fmt.Errorf("image %d: only data URIs can be token-counted", index)
+	}
+	data, err := base64.StdEncoding.DecodeString(url[idx+len("base64,"):])
+	if err != nil {
+		return ImagePreview{}, fmt.Errorf("image %d: invalid base64 payload: %v", index, err)
+	}
+
+	img, err := vision.Decode(data)
+	if err != nil {
+		return ImagePreview{}, fmt.Errorf("image %d: %v", index, err)
+	}
+	bounds := img.Bounds()
+
+	// Oversized images take the tiled path at serving time, so their
+	// token count is the sum over the tile grids, not one resize plan
+	if w, h := bounds.Dx(), bounds.Dy(); vision.ShouldTile(w, h, maxPixels) {
+		rects := vision.TileRects(w, h, maxPixels)
+		tokens := 0
+		var tileW, tileH int
+		for _, rect := range rects {
+			tileW, tileH = vision.PlanResize(rect.Dx(), rect.Dy(), minPixels, maxPixels)
+			gw, gh := vision.CalculateGrid(tileW, tileH)
+			tokens += gw * gh
+		}
+		gridW, gridH := vision.CalculateGrid(tileW, tileH)
+		return ImagePreview{
+			Index:         index,
+			Width:         w,
+			Height:        h,
+			ResizedWidth:  tileW,
+			ResizedHeight: tileH,
+			GridW:         gridW,
+			GridH:         gridH,
+			Tokens:        tokens,
+			Tiles:         len(rects),
+		}, nil
+	}
+
+	resized, err := vision.SmartResize(img, minPixels, maxPixels)
+	if err != nil {
+		return ImagePreview{}, fmt.Errorf("image %d: %v", index, err)
+	}
+	rb := resized.Bounds()
+	gridW, gridH := vision.CalculateGrid(rb.Dx(), rb.Dy())
+
+	return ImagePreview{
+		Index:         index,
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+		ResizedWidth:  rb.Dx(),
+		ResizedHeight: rb.Dy(),
+		GridW:         gridW,
+		GridH:         gridH,
+		Tokens:        gridW * gridH,
+	}, nil
+}
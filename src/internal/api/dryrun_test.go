@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// pngDataURI builds a solid-color PNG data URI of the given size
+func pngDataURI(t *testing.T, w, h int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{128, 128, 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDryRunReturnsPreview(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"dry_run":true,"messages":[{"role":"user","content":"click the save button"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TokenPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Object != "chat.completion.preview" {
+		t.Errorf("object = %q, want chat.completion.preview", resp.Object)
+	}
+	if resp.TextTokens == 0 || resp.PromptTokens != resp.TextTokens {
+		t.Errorf("tokens = %+v, want text-only prompt accounting", resp)
+	}
+	if resp.ImageTokens != 0 || len(resp.Images) != 0 {
+		t.Errorf("image tokens reported for a text-only request: %+v", resp)
+	}
+	// No generation happened, so no completion fields leak through
+	if bytes.Contains(rec.Body.Bytes(), []byte("choices")) {
+		t.Error("preview response carries choices")
+	}
+}
+
+func TestDryRunImageBreakdown(t *testing.T) {
+	h := NewHandler(nil)
+
+	// 100x100 is within the default pixel bounds, so the plan is plain
+	// grid alignment: 84x84, a 3x3 patch grid
+	body := fmt.Sprintf(`{"dry_run":true,"messages":[{"role":"user","content":[
+		{"type":"text","text":"click save"},
+		{"type":"image_url","image_url":{"url":%q}}
+	]}]}`, pngDataURI(t, 100, 100))
+
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TokenPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(resp.Images) != 1 {
+		t.Fatalf("got %d image previews, want 1", len(resp.Images))
+	}
+	img := resp.Images[0]
+	if img.Width != 100 || img.Height != 100 {
+		t.Errorf("original size = %dx%d, want 100x100", img.Width, img.Height)
+	}
+	if img.ResizedWidth != 84 || img.ResizedHeight != 84 {
+		t.Errorf("resized = %dx%d, want 84x84", img.ResizedWidth, img.ResizedHeight)
+	}
+	if img.GridW != 3 || img.GridH != 3 || img.Tokens != 9 {
+		t.Errorf("grid = %dx%d (%d tokens), want 3x3 (9)", img.GridW, img.GridH, img.Tokens)
+	}
+	if resp.ImageTokens != img.Tokens+visionMarkerTokens {
+		t.Errorf("image_tokens = %d, want %d", resp.ImageTokens, img.Tokens+visionMarkerTokens)
+	}
+	if resp.PromptTokens != resp.TextTokens+resp.ImageTokens {
+		t.Errorf("prompt_tokens = %d, want text+image = %d", resp.PromptTokens, resp.TextTokens+resp.ImageTokens)
+	}
+}
+
+func TestDryRunHonorsPixelBounds(t *testing.T) {
+	h := NewHandler(nil)
+
+	// max_pixels 3136 forces the 100x100 screenshot down to 56x56
+	body := fmt.Sprintf(`{"dry_run":true,"max_pixels":3136,"messages":[{"role":"user","content":[
+		{"type":"image_url","image_url":{"url":%q}}
+	]}]}`, pngDataURI(t, 100, 100))
+
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TokenPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(resp.Images) != 1 || resp.Images[0].Tokens != 4 {
+		t.Errorf("previews = %+v, want one 2x2 grid (4 tokens)", resp.Images)
+	}
+}
+
+func TestDryRunCountsTiledImage(t *testing.T) {
+	h := NewHandler(nil)
+
+	// 400x400 into max_pixels 10000 crosses the tiling threshold: the
+	// plan is a 5x5 layout of 84x84 tiles, 3x3 patches each
+	body := fmt.Sprintf(`{"dry_run":true,"max_pixels":10000,"messages":[{"role":"user","content":[
+		{"type":"image_url","image_url":{"url":%q}}
+	]}]}`, pngDataURI(t, 400, 400))
+
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TokenPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(resp.Images) != 1 {
+		t.Fatalf("got %d image previews, want 1", len(resp.Images))
+	}
+	img := resp.Images[0]
+	if img.Tiles != 25 {
+		t.Errorf("tiles = %d, want 25", img.Tiles)
+	}
+	if img.ResizedWidth != 84 || img.ResizedHeight != 84 || img.GridW != 3 || img.GridH != 3 {
+		t.Errorf("tile plan = %dx%d grid %dx%d, want 84x84 grid 3x3", img.ResizedWidth, img.ResizedHeight, img.GridW, img.GridH)
+	}
+	if img.Tokens != 25*9 {
+		t.Errorf("tokens = %d, want 225 across tiles", img.Tokens)
+	}
+}
+
+func TestDryRunRejectsUndecodableImage(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := `{"dry_run":true,"messages":[{"role":"user","content":[
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,bm90IGFuIGltYWdl"}}
+	]}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 400 {
+		t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDryRunCountsPresetPrompt(t *testing.T) {
+	h := NewHandler(nil)
+
+	plain := postCompletion(t, h, `{"dry_run":true,"messages":[{"role":"user","content":"click save"}]}`)
+	preset := postCompletion(t, h, `{"dry_run":true,"preset":"osworld","messages":[{"role":"user","content":"click save"}]}`)
+
+	var a, b TokenPreviewResponse
+	if err := json.Unmarshal(plain.Body.Bytes(), &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(preset.Body.Bytes(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.PromptTokens <= a.PromptTokens {
+		t.Errorf("preset prompt counted %d tokens, want more than the plain %d", b.PromptTokens, a.PromptTokens)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/agenthands/mlxvllm/internal/model"
+)
+
+// ModelEvents streams load progress for one model as SSE so UIs can
+// show a load bar while large models come up. When no load is in
+// flight a single snapshot event is emitted and the stream ends.
+func (h *Handler) ModelEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	modelID := vars["id"]
+
+	if h.registry == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not available")
+		return
+	}
+	if !h.registry.HasModel(modelID) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", modelID))
+		return
+	}
+
+	events, loading := h.registry.SubscribeLoad(modelID)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	conn := newStreamConn(w, r, DefaultStreamBuffer, DefaultWriteDeadline)
+
+	if !loading {
+		conn.SendEvent("load", snapshotEvent(h.registry, modelID))
+	} else {
+		for ev := range events {
+			conn.SendEvent("load", ev)
+		}
+	}
+
+	conn.SendDone()
+	conn.Close()
+}
+
+// snapshotEvent describes a model with no load in flight
+func snapshotEvent(registry *model.Registry, modelID string) model.LoadEvent {
+	ev := model.LoadEvent{Model: modelID, Done: true}
+	for _, status := range registry.Status() {
+		if status.Name == modelID && status.Loaded {
+			ev.Progress = 1
+		}
+	}
+	return ev
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func getModelEvents(h *Handler, modelID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/v1/models/"+modelID+"/events", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": modelID})
+	w := httptest.NewRecorder()
+	h.ModelEvents(w, req)
+	return w
+}
+
+func TestModelEventsStreamsProgress(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.registry.StartLoad("gui-actor-2b")
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- getModelEvents(h, "gui-actor-2b") }()
+	time.Sleep(20 * time.Millisecond)
+
+	h.registry.SetLoadPhase("gui-actor-2b", "weights_read", 0.5)
+	h.registry.FinishLoad("gui-actor-2b", nil)
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ModelEvents never returned after the load finished")
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"event: load", "weights_read", `"done":true`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream missing %q:\n%s", want, body)
+		}
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Error("stream must terminate with [DONE]")
+	}
+}
+
+func TestModelEventsIdleSnapshot(t *testing.T) {
+	h := newDashboardHandler(t)
+
+	w := getModelEvents(h, "gui-actor-2b")
+	if w.Code != 200 {
+		t.Fatalf("ModelEvents status = %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"done":true`) {
+		t.Errorf("idle stream missing snapshot event:\n%s", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Error("stream must terminate with [DONE]")
+	}
+}
+
+func TestModelEventsUnknownModel(t *testing.T) {
+	h := newDashboardHandler(t)
+	if w := getModelEvents(h, "no-such-model"); w.Code != 404 {
+		t.Errorf("ModelEvents status = %d, want 404", w.Code)
+	}
+}
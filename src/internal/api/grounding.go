@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// MaxBatchInstructions bounds one grounding batch; evaluation harnesses
+// chunk larger sets themselves
+const MaxBatchInstructions = 64
+
+// BatchGroundingRequest grounds several instructions against one
+// screenshot. The image is encoded once and its vision embeddings and
+// prefix cache entry are shared across all generations.
+type BatchGroundingRequest struct {
+	Model        string   `json:"model,omitempty"`
+	Image        string   `json:"image"` // data URI
+	Instructions []string `json:"instructions"`
+	MaxPixels    *int     `json:"max_pixels,omitempty"`
+	MinPixels    *int     `json:"min_pixels,omitempty"`
+}
+
+// BatchGroundingResponse carries one result per instruction, in order
+type BatchGroundingResponse struct {
+	Object  string            `json:"object"`
+	Created int64             `json:"created"`
+	Model   string            `json:"model"`
+	Results []GroundingResult `json:"results"`
+}
+
+// GroundingResult is the predicted point for one instruction
+type GroundingResult struct {
+	Index       int    `json:"index"`
+	Instruction string `json:"instruction"`
+	Coordinates *Point `json:"coordinates"`
+}
+
+// GroundingBatch handles POST /v1/grounding/batch: one screenshot, N
+// instructions, N predicted points
+func (h *Handler) GroundingBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchGroundingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	resolved, ok := h.resolveModel(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+	req.Model = resolved
+
+	if len(req.Instructions) == 0 {
+		writeError(w, http.StatusBadRequest, "instructions must not be empty")
+		return
+	}
+	if len(req.Instructions) > MaxBatchInstructions {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("at most %d instructions per batch", MaxBatchInstructions))
+		return
+	}
+	if h.rejectRevoked(w, r) {
+		return
+	}
+	if h.enforceQuota(w, r) {
+		return
+	}
+
+	// Decode and preprocess the screenshot exactly once; every
+	// instruction reuses the same tensor (and, once inference lands,
+	// the same vision embeddings and image-prefix KV entry)
+	idx := strings.Index(req.Image, "base64,")
+	if idx < 0 {
+		writeError(w, http.StatusBadRequest, "image must be a data URI")
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(req.Image[idx+len("base64,"):])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid base64 image payload")
+		return
+	}
+	minPixels, maxPixels := defaultMinPixels, defaultMaxPixels
+	if req.MinPixels != nil {
+		minPixels = *req.MinPixels
+	}
+	if req.MaxPixels != nil {
+		maxPixels = *req.MaxPixels
+	}
+	img, err := vision.Decode(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bounds := img.Bounds()
+
+	// Oversized captures (5K, multi-monitor) are tiled instead of
+	// downscaled, so small UI elements keep patch resolution; per-tile
+	// predictions map back through Tile.MapToGlobal
+	var tiles []vision.Tile
+	if vision.ShouldTile(bounds.Dx(), bounds.Dy(), maxPixels) {
+		tiles, err = vision.PreprocessTiled(img, minPixels, maxPixels)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		resized, err := vision.SmartResize(img, minPixels, maxPixels)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		_ = vision.Normalize(resized)
+	}
+
+	// Batches queue as one admission unit so a 64-instruction eval run
+	// cannot starve interactive requests slot by slot
+	if h.sched != nil {
+		if err := h.sched.Acquire(r.Context(), apiKeyFrom(r)); err != nil {
+			writeError(w, http.StatusServiceUnavailable, "request cancelled while queued")
+			return
+		}
+		defer h.sched.Release()
+	}
+
+	resp := BatchGroundingResponse{
+		Object:  "grounding.batch",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Results: make([]GroundingResult, len(req.Instructions)),
+	}
+	for i, instruction := range req.Instructions {
+		// TODO: run the pointer head per instruction over the shared
+		// vision embeddings; on the tiled path, over every tile, keeping
+		// the highest-confidence point
+		x, y := 0.5, 0.5
+		if len(tiles) > 0 {
+			x, y = tiles[0].MapToGlobal(x, y)
+		}
+		resp.Results[i] = GroundingResult{
+			Index:       i,
+			Instruction: instruction,
+			Coordinates: &Point{X: x, Y: y},
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postGroundingBatch(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/grounding/batch", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.GroundingBatch(rec, req)
+	return rec
+}
+
+func TestGroundingBatch(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := fmt.Sprintf(`{"image":%q,"instructions":["click save","click cancel","open menu"]}`,
+		pngDataURI(t, 100, 100))
+	rec := postGroundingBatch(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp BatchGroundingResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Object != "grounding.batch" {
+		t.Errorf("object = %q, want grounding.batch", resp.Object)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(resp.Results))
+	}
+	for i, res := range resp.Results {
+		if res.Index != i {
+			t.Errorf("result %d has index %d", i, res.Index)
+		}
+		if res.Coordinates == nil {
+			t.Errorf("result %d has no coordinates", i)
+		}
+	}
+	if resp.Results[1].Instruction != "click cancel" {
+		t.Errorf("instructions out of order: %+v", resp.Results)
+	}
+}
+
+func TestGroundingBatchTilesOversized(t *testing.T) {
+	h := NewHandler(nil)
+
+	// 400x400 into max_pixels 10000 would downscale past the tiling
+	// threshold, so the batch takes the tiled path and the stub point
+	// comes back in global coordinates of the first 84x84 tile
+	body := fmt.Sprintf(`{"image":%q,"instructions":["click save"],"max_pixels":10000}`,
+		pngDataURI(t, 400, 400))
+	rec := postGroundingBatch(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp BatchGroundingResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Coordinates == nil {
+		t.Fatalf("results = %+v, want one with coordinates", resp.Results)
+	}
+	want := 0.5 * 84 / 400
+	got := resp.Results[0].Coordinates
+	if got.X != want || got.Y != want {
+		t.Errorf("coordinates = (%v, %v), want tile-mapped (%v, %v)", got.X, got.Y, want, want)
+	}
+}
+
+func TestGroundingBatchValidation(t *testing.T) {
+	h := NewHandler(nil)
+	image := pngDataURI(t, 56, 56)
+
+	many := make([]string, MaxBatchInstructions+1)
+	for i := range many {
+		many[i] = fmt.Sprintf(`"step %d"`, i)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"no instructions", fmt.Sprintf(`{"image":%q,"instructions":[]}`, image), 400},
+		{"too many instructions", fmt.Sprintf(`{"image":%q,"instructions":[%s]}`, image, strings.Join(many, ",")), 400},
+		{"not a data URI", `{"image":"https://example.com/a.png","instructions":["x"]}`, 400},
+		{"undecodable image", `{"image":"data:image/png;base64,bm90IGFuIGltYWdl","instructions":["x"]}`, 400},
+		{"invalid body", `{`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := postGroundingBatch(t, h, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGroundingBatchResolvesAlias(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetModelRouting(map[string]string{"gpt-4o": "gui-actor-7b"}, "", false)
+
+	body := fmt.Sprintf(`{"model":"gpt-4o","image":%q,"instructions":["x"]}`, pngDataURI(t, 56, 56))
+	rec := postGroundingBatch(t, h, body)
+
+	var resp BatchGroundingResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Model != "gui-actor-7b" {
+		t.Errorf("model = %q, want the alias target", resp.Model)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/guardrail"
+)
+
+func postGuardedCompletion(t *testing.T, h *Handler, apiKey string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "click the button"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+	return w
+}
+
+func TestGuardrailBlocksRegion(t *testing.T) {
+	h := newDashboardHandler(t)
+	rails := guardrail.NewRegistry()
+	// The mock response clicks (0.5, 0.5); confine clicks to the top-left
+	// quadrant so it is rejected
+	rails.Register(guardrail.NewAllowedRegion("region", 0, 0, 0.25, 0.25))
+	h.SetGuardrails(rails)
+
+	w := postGuardedCompletion(t, h, "")
+	if w.Code != 403 {
+		t.Fatalf("ChatCompletion status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGuardrailBlocksPattern(t *testing.T) {
+	h := newDashboardHandler(t)
+	rails := guardrail.NewRegistry()
+	hook, err := guardrail.NewBlockedPatterns("danger", []string{`pyautogui\.click`})
+	if err != nil {
+		t.Fatalf("NewBlockedPatterns: %v", err)
+	}
+	rails.Register(hook)
+	h.SetGuardrails(rails)
+
+	w := postGuardedCompletion(t, h, "")
+	if w.Code != 403 {
+		t.Fatalf("ChatCompletion status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGuardrailPermissivePasses(t *testing.T) {
+	h := newDashboardHandler(t)
+	rails := guardrail.NewRegistry()
+	rails.Register(guardrail.NewAllowedRegion("region", 0, 0, 1, 1))
+	h.SetGuardrails(rails)
+
+	w := postGuardedCompletion(t, h, "")
+	if w.Code != 200 {
+		t.Fatalf("ChatCompletion status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGuardrailPerKeyExemption(t *testing.T) {
+	h := newDashboardHandler(t)
+	rails := guardrail.NewRegistry()
+	rails.Register(guardrail.NewAllowedRegion("region", 0, 0, 0.25, 0.25))
+	rails.SetKeyHooks("sk-trusted", []string{})
+	h.SetGuardrails(rails)
+
+	if w := postGuardedCompletion(t, h, "sk-lab-1"); w.Code != 403 {
+		t.Errorf("ChatCompletion status = %d for guarded key, want 403", w.Code)
+	}
+	if w := postGuardedCompletion(t, h, "sk-trusted"); w.Code != 200 {
+		t.Errorf("ChatCompletion status = %d for exempted key, want 200", w.Code)
+	}
+}
@@ -1,22 +1,110 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/agenthands/mlxvllm/internal/audit"
+	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/guardrail"
 	"github.com/agenthands/mlxvllm/internal/model"
+	"github.com/agenthands/mlxvllm/internal/notify"
+	"github.com/agenthands/mlxvllm/internal/ocr"
+	"github.com/agenthands/mlxvllm/internal/plugin"
+	"github.com/agenthands/mlxvllm/internal/prefixcache"
+	"github.com/agenthands/mlxvllm/internal/recording"
+	"github.com/agenthands/mlxvllm/internal/scheduler"
+	"github.com/agenthands/mlxvllm/internal/session"
+	"github.com/agenthands/mlxvllm/internal/shadow"
+	"github.com/agenthands/mlxvllm/internal/slo"
+	"github.com/agenthands/mlxvllm/internal/trace"
+	"github.com/agenthands/mlxvllm/internal/usage"
+	"github.com/agenthands/mlxvllm/pkg/vision"
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
 	registry  *model.Registry
+	sessions  *session.Manager
+	presetMu  sync.RWMutex
+	presets   map[string]config.PresetConfig
+	recorder  *recording.Recorder
+	streams   *StreamLimiter
+	usage     *usage.Store
+	audit     *audit.Log
+	sched     *scheduler.Scheduler
 	startTime time.Time
+
+	quotaDefault usage.Quota
+	adminKeys    map[string]struct{}
+	memLimitGB   float64
+
+	aliases        map[string]string
+	defaultModel   string
+	strictModels   bool
+	compatStrict   bool
+	contextLengths map[string]int
+	canaryMu       sync.RWMutex
+	canaries       map[string]*canaryRoute
+
+	guardrails    *guardrail.Registry
+	redactRegions []vision.Region
+	loadWaiters   chan struct{}
+
+	connMu      sync.Mutex
+	activeConns map[*streamConn]struct{}
+
+	engineSup engineSupervisor
+	slo       *slo.Monitor
+	notifier  *notify.Notifier
+	plugins   *plugin.Registry
+
+	reqTimeout   time.Duration
+	keepAlive    time.Duration
+	timeoutsHit  int64
+	maxActions   int
+	pins         *prefixcache.Tracker
+	ocrProviders map[string]ocr.Provider
+	snapRadius   float64
+	shadowModel  string
+	shadowLog    *shadow.Log
+}
+
+// SetRecorder enables request/response recording for dataset building
+func (h *Handler) SetRecorder(r *recording.Recorder) {
+	h.recorder = r
+}
+
+// SetSessionStore persists sessions in the server state file and
+// restores any saved before the last shutdown, returning how many
+func (h *Handler) SetSessionStore(st session.Store) (int, error) {
+	h.sessions.SetStore(st)
+	return h.sessions.Restore()
+}
+
+// SetGuardrails enables output filtering of generated actions
+func (h *Handler) SetGuardrails(g *guardrail.Registry) {
+	h.guardrails = g
+}
+
+// SetScheduler enables weighted fair admission of completion requests
+func (h *Handler) SetScheduler(s *scheduler.Scheduler) {
+	h.sched = s
 }
 
 func NewHandler(registry *model.Registry) *Handler {
 	return &Handler{
 		registry:  registry,
+		sessions:  session.NewManager(0),
+		presets:   builtinPresets(),
+		streams:   NewStreamLimiter(0),
 		startTime: time.Now(),
 	}
 }
@@ -33,22 +121,16 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// ListModels returns available models
+// ListModels returns available models with their load state
 func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
 	resp := ModelsResponse{
 		Object: "list",
-		Data: []ModelInfo{
-			{
-				ID:     "gui-actor-2b",
-				Object: "model",
-				Loaded: false, // TODO: check registry
-			},
-			{
-				ID:     "gui-actor-7b",
-				Object: "model",
-				Loaded: false,
-			},
-		},
+		Data:   []ModelInfo{},
+	}
+	if h.registry != nil {
+		for _, status := range h.registry.Status() {
+			resp.Data = append(resp.Data, modelInfo(status))
+		}
 	}
 
 	writeJSON(w, http.StatusOK, resp)
@@ -59,13 +141,32 @@ func (h *Handler) GetModel(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	modelID := vars["id"]
 
-	resp := ModelInfo{
-		ID:     modelID,
-		Object: "model",
-		Loaded: false, // TODO: check registry
+	if h.registry != nil {
+		for _, status := range h.registry.Status() {
+			if status.Name == modelID {
+				writeJSON(w, http.StatusOK, modelInfo(status))
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", modelID))
+		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeJSON(w, http.StatusOK, ModelInfo{ID: modelID, Object: "model"})
+}
+
+// modelInfo converts a registry snapshot into the API representation
+func modelInfo(status model.ModelStatus) ModelInfo {
+	return ModelInfo{
+		ID:           status.Name,
+		Object:       "model",
+		Loaded:       status.Loaded,
+		MemoryGB:     status.MemoryGB,
+		Loading:      status.Loading,
+		LoadPhase:    status.LoadPhase,
+		LoadProgress: status.LoadProgress,
+		Offload:      status.Residency,
+	}
 }
 
 // LoadModel loads a model into memory
@@ -78,11 +179,23 @@ func (h *Handler) LoadModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.registry.LoadModel(modelID); err != nil {
+	events, err := h.registry.LoadModel(modelID)
+	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	// Wait out the load so the endpoint stays synchronous for clients;
+	// progress watchers use /models/{id}/events instead
+	for ev := range events {
+		if ev.Error != "" {
+			writeError(w, http.StatusInternalServerError, ev.Error)
+			return
+		}
+	}
+
+	h.auditLog(r, "model.load", modelID, "")
+	h.emit("model.loaded", map[string]interface{}{"model": modelID})
 	writeJSON(w, http.StatusOK, map[string]string{"status": "loaded"})
 }
 
@@ -101,28 +214,494 @@ func (h *Handler) UnloadModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditLog(r, "model.unload", modelID, "")
+	h.emit("model.unloaded", map[string]interface{}{"model": modelID})
 	writeJSON(w, http.StatusOK, map[string]string{"status": "unloaded"})
 }
 
 // ChatCompletion handles inference requests
 func (h *Handler) ChatCompletion(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
 	var req ChatCompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
+	// Map aliases and fall back to the default model (or 404 in
+	// strict mode) before anything downstream reads req.Model
+	resolved, ok := h.resolveModel(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+	req.Model = resolved
+
+	// Hold or reject requests whose model is still loading
+	if h.gateLoading(w, r, req.Model) {
+		return
+	}
+
+	if h.rejectRevoked(w, r) {
+		return
+	}
+	if h.gateDebug(w, r, &req) {
+		return
+	}
+
+	// Give lifecycle plugins a veto before any work is queued
+	preq := pluginRequest(r, &req, trace.RequestID(r.Context()))
+	if h.firePluginRequest(w, preq) {
+		return
+	}
+	if h.enforceQuota(w, r) {
+		return
+	}
+
+	// Non-streaming requests run under a deadline covering queueing and
+	// generation; streams stay alive via keep-alive comments instead
+	if h.reqTimeout > 0 && !req.Stream {
+		ctx, cancel := context.WithTimeout(r.Context(), h.reqTimeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	// Queue behind other tenants' work in weighted fair order; under
+	// sustained queue buildup, shed before queueing so admitted
+	// requests keep their TTFT budget
+	if h.sched != nil {
+		if h.sched.ShouldShed(apiKeyFrom(r)) {
+			writeOverloadedError(w)
+			return
+		}
+		if err := h.sched.Acquire(r.Context(), apiKeyFrom(r)); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				h.writeTimeoutError(w, h.reqTimeout)
+				return
+			}
+			writeError(w, http.StatusServiceUnavailable, "request cancelled while queued")
+			return
+		}
+		defer h.sched.Release()
+	}
+
+	if err := h.applyPreset(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate sampler extensions up front so bad parameters fail the
+	// request instead of the decode loop
+	if _, err := samplerFor(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := processorsFor(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateResponseFormat(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// A dry run stops here with the prompt accounting; nothing below
+	// (cache pins, sessions, recording, generation) runs
+	if h.dryRunPreview(w, &req) {
+		return
+	}
+
+	if h.applyCacheControl(w, &req) {
+		return
+	}
+
+	// Mask sensitive screen regions before the pixels reach recording
+	// or the vision encoder
+	if err := h.redactScreenshots(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// OCR runs on the redacted pixels so masked regions contribute no
+	// text hints; the boxes double as snap targets below
+	ocrBoxes := h.applyOCRHints(&req)
+
+	// Pop a trailing partial assistant message before it can enter
+	// session history; generation continues from it
+	prefill := extractPrefill(&req)
+
+	// Resolve server-side history when the request references a session
+	if req.SessionID != "" {
+		history, err := h.appendToSession(req.SessionID, req.Messages)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		req.Messages = history
+	}
+
+	// Buffer the request for prefill replay should the engine worker
+	// crash while serving it
+	inflightID := h.trackInflight(&req)
+	if inflightID != "" {
+		defer h.engineSup.Deregister(inflightID)
+	}
+
+	if req.Stream {
+		h.markRestarted(w, inflightID)
+		h.streamChatCompletion(w, r, &req, prefill, ocrBoxes)
+		return
+	}
+
 	// TODO: implement actual inference
+	generated := continueFrom(prefill, "pyautogui.click(0.5, 0.5)")
+	assistant := Message{Role: "assistant", Content: generated}
+	if err := h.firePluginGeneration(preq, generated); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	finishReason := "stop"
+	if req.ParallelToolCalls {
+		assistant = Message{Role: "assistant", ToolCalls: h.capToolCalls(mockToolCalls(generateID()))}
+		finishReason = "tool_calls"
+	}
+	point := &Point{X: 0.5, Y: 0.5}
+	raw := h.applySnap(&req, ocrBoxes, point)
 	resp := NewChatCompletionResponse(req.Model, []Choice{
 		{
-			Index:        0,
-			Message:      Message{Role: "assistant", Content: "pyautogui.click(0.5, 0.5)"},
-			FinishReason: "stop",
-			Coordinates:  &Point{X: 0.5, Y: 0.5},
+			Index:          0,
+			Message:        assistant,
+			FinishReason:   finishReason,
+			Coordinates:    point,
+			RawCoordinates: raw,
+			XGUIActor:      newExtension(point, raw),
 		},
 	})
+	if req.Debug {
+		if content, ok := assistant.Content.(string); ok {
+			resp.Debug = debugTrace(content, start)
+		}
+	}
 
+	if h.applyGuardrails(w, r, &req, resp) {
+		return
+	}
+	h.applyCompat(resp)
+	assistant = resp.Choices[0].Message
+	resp.Usage = h.completionUsage(&req, &assistant)
+
+	if req.SessionID != "" {
+		// Record the reply so the next turn sees it; the session was
+		// just validated above, so an error here is unexpected. History
+		// gets the full message, prefill included.
+		full := assistant
+		if text, ok := assistant.Content.(string); ok && prefill != "" {
+			full.Content = prefill + text
+		}
+		if _, err := h.appendToSession(req.SessionID, []Message{full}); err != nil {
+			h.recordSLO(start, true)
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	// The deadline may have elapsed during generation; a partial result
+	// is useless to a non-streaming client, so answer with the timeout
+	if r.Context().Err() != nil && h.reqTimeout > 0 {
+		h.writeTimeoutError(w, h.reqTimeout)
+		return
+	}
+
+	h.record(&req, &assistant, point, start)
+	h.meter(r, &req, &assistant, start)
+	if content, ok := assistant.Content.(string); ok {
+		h.firePluginComplete(preq, content, resp.Choices[0].FinishReason, start)
+	}
+	h.recordSLO(start, false)
+	h.markRestarted(w, inflightID)
 	writeJSON(w, http.StatusOK, resp)
+
+	if content, ok := assistant.Content.(string); ok {
+		go h.runShadow(&req, content, point)
+	}
+}
+
+// applyGuardrails runs the configured output hooks over the generated
+// choice, writing back any rewrites. A block is audited and answered
+// with 403; the return value reports whether the response was already
+// written.
+func (h *Handler) applyGuardrails(w http.ResponseWriter, r *http.Request, req *ChatCompletionRequest, resp *ChatCompletionResponse) bool {
+	if h.guardrails == nil || len(resp.Choices) == 0 {
+		return false
+	}
+
+	choice := &resp.Choices[0]
+	action := guardrail.Action{Model: req.Model}
+	if text, ok := choice.Message.Content.(string); ok {
+		action.Content = text
+	}
+	if choice.Coordinates != nil {
+		action.X, action.Y = choice.Coordinates.X, choice.Coordinates.Y
+		action.HasPoint = true
+	}
+
+	if err := h.guardrails.Apply(apiKeyFrom(r), &action); err != nil {
+		h.auditLog(r, "guardrail.block", req.Model, err.Error())
+		writeError(w, http.StatusForbidden, err.Error())
+		return true
+	}
+
+	choice.Message.Content = action.Content
+	if choice.Coordinates != nil && action.HasPoint {
+		choice.Coordinates.X, choice.Coordinates.Y = action.X, action.Y
+	}
+	return false
+}
+
+// record persists a sanitized trace of a completed request when
+// recording is enabled
+func (h *Handler) record(req *ChatCompletionRequest, assistant *Message, coords *Point, start time.Time) {
+	if h.recorder == nil {
+		return
+	}
+
+	rec := recording.Record{
+		Timestamp: start.Unix(),
+		Model:     req.Model,
+		Prompt:    lastUserText(req.Messages),
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if text, ok := assistant.Content.(string); ok {
+		rec.Response = text
+	}
+	if coords != nil {
+		rec.Coordinates = &recording.Point{X: coords.X, Y: coords.Y}
+	}
+	if hash := screenshotHash(req.Messages); hash != "" {
+		rec.ScreenshotHash = hash
+	}
+
+	if err := h.recorder.Record(rec); err != nil {
+		log.Printf("recording failed: %v", err)
+	}
+}
+
+// lastUserText extracts the text of the most recent user message
+func lastUserText(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		switch content := messages[i].Content.(type) {
+		case string:
+			return content
+		case []interface{}:
+			for _, part := range content {
+				m, ok := part.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if text, ok := m["text"].(string); ok && text != "" {
+					return text
+				}
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// screenshotHash hashes the first image found in the messages so the
+// raw screenshot never lands in the recording
+func screenshotHash(messages []Message) string {
+	for _, msg := range messages {
+		parts, ok := msg.Content.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, part := range parts {
+			m, ok := part.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			imageURL, ok := m["image_url"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			url, ok := imageURL["url"].(string)
+			if !ok || url == "" {
+				continue
+			}
+			// Data URIs are hashed over the base64 payload; remote URLs
+			// over the URL string itself
+			if idx := strings.Index(url, "base64,"); idx >= 0 {
+				url = url[idx+len("base64,"):]
+			}
+			return recording.HashScreenshot([]byte(url))
+		}
+	}
+	return ""
+}
+
+// appendToSession adds API messages to a session and returns the full
+// history converted back to API message types
+func (h *Handler) appendToSession(id string, messages []Message) ([]Message, error) {
+	converted := make([]session.Message, len(messages))
+	for i, m := range messages {
+		converted[i] = session.Message{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+	}
+
+	history, err := h.sessions.Append(id, converted...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Message, len(history))
+	for i, m := range history {
+		out[i] = Message{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+	}
+	return out, nil
+}
+
+// CreateSession opens a server-side conversation session
+func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	var req SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s := h.sessions.Create(req.Model)
+	writeJSON(w, http.StatusOK, SessionResponse{
+		ID:      s.ID,
+		Object:  "session",
+		Model:   s.Model,
+		Created: s.CreatedAt.Unix(),
+	})
+}
+
+// GetSession returns session metadata
+func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	s, err := h.sessions.Get(vars["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SessionResponse{
+		ID:       s.ID,
+		Object:   "session",
+		Model:    s.Model,
+		Created:  s.CreatedAt.Unix(),
+		Messages: len(s.History),
+	})
+}
+
+// DeleteSession removes a session and its history
+func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.sessions.Delete(vars["id"]); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// streamChatCompletion emits the response as SSE. The first event is a
+// custom "grounding" event carrying the predicted coordinates as soon
+// as the pointer head finishes, so agent UIs can dispatch the click
+// before the text rationale streams in. Frames go through a streamConn
+// so a stalled consumer aborts instead of pinning a generation slot.
+func (h *Handler) streamChatCompletion(w http.ResponseWriter, r *http.Request, req *ChatCompletionRequest, prefill string, ocrBoxes []ocr.Box) {
+	start := time.Now()
+
+	if err := h.streams.Acquire(); err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	defer h.streams.Release()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	conn := newStreamConn(w, r, DefaultStreamBuffer, DefaultWriteDeadline)
+	h.trackStream(conn)
+	defer h.untrackStream(conn)
+
+	// The keep-alive pinger must be stopped and joined before the conn
+	// closes, or it could send on a dead stream
+	stopKeepAlive := func() {}
+	if h.keepAlive > 0 {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			keepAliveLoop(conn, h.keepAlive, stop)
+		}()
+		stopKeepAlive = func() {
+			close(stop)
+			<-done
+		}
+	}
+
+	// TODO: implement actual inference
+	point := &Point{X: 0.5, Y: 0.5}
+	content := continueFrom(prefill, "pyautogui.click(0.5, 0.5)")
+
+	preq := pluginRequest(r, req, trace.RequestID(r.Context()))
+	if err := h.firePluginGeneration(preq, content); err != nil {
+		// Headers are already out; all we can do is cut the stream
+		log.Printf("stream aborted by plugin (request %s): %v", trace.RequestID(r.Context()), err)
+		stopKeepAlive()
+		conn.Close()
+		return
+	}
+
+	raw := h.applySnap(req, ocrBoxes, point)
+	if !h.compatStrict {
+		// The grounding event is not part of the OpenAI wire format, so
+		// strict compat mode keeps it off the stream entirely
+		grounding := NewGroundingEvent(req.Model, point)
+		grounding.RawCoordinates = raw
+		grounding.XGUIActor = newExtension(point, raw)
+		conn.SendEvent("grounding", grounding)
+	}
+
+	id := generateID()
+	conn.SendEvent("", streamChunk(id, req.Model, &Message{Role: "assistant", Content: content}, ""))
+	final := streamChunk(id, req.Model, nil, "stop")
+	final.Usage = h.completionUsage(req, &Message{Role: "assistant", Content: content})
+	conn.SendEvent("", final)
+	if req.Debug {
+		conn.SendEvent("debug", debugTrace(content, start))
+	}
+	conn.SendDone()
+
+	stopKeepAlive()
+	if err := conn.Close(); err != nil {
+		log.Printf("stream aborted (request %s): %v", trace.RequestID(r.Context()), err)
+	}
+
+	h.meter(r, req, &Message{Role: "assistant", Content: content}, start)
+	h.firePluginComplete(preq, content, "stop", start)
+	h.recordSLO(start, false)
+}
+
+// streamChunk builds one chat.completion.chunk payload
+func streamChunk(id, model string, delta *Message, finishReason string) *ChatCompletionResponse {
+	return &ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []Choice{
+			{Index: 0, Delta: delta, FinishReason: finishReason},
+		},
+	}
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -131,6 +710,18 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	json.NewEncoder(w).Encode(v)
 }
 
+// writeOverloadedError answers a shed request; the dedicated type lets
+// clients back off with jitter instead of treating it as a hard failure
+func writeOverloadedError(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+		Error: ErrorDetail{
+			Message: "server overloaded, please retry",
+			Type:    "server_overloaded",
+		},
+	})
+}
+
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, ErrorResponse{
 		Error: ErrorDetail{
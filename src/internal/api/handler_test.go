@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
+
+	"github.com/agenthands/mlxvllm/internal/model"
 )
 
 func TestHealthHandler(t *testing.T) {
@@ -331,3 +334,154 @@ func TestNewChatCompletionResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestChatCompletionStreaming(t *testing.T) {
+	h := NewHandler(nil)
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:  "gui-actor-2b",
+		Stream: true,
+		Messages: []Message{
+			{Role: "user", Content: "click the button"},
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.ChatCompletion(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", ct)
+	}
+
+	out := w.Body.String()
+
+	// The grounding event must arrive before any completion chunk
+	groundingIdx := strings.Index(out, "event: grounding")
+	chunkIdx := strings.Index(out, "chat.completion.chunk")
+	if groundingIdx < 0 {
+		t.Fatal("Missing grounding event in stream")
+	}
+	if chunkIdx < 0 {
+		t.Fatal("Missing completion chunks in stream")
+	}
+	if groundingIdx > chunkIdx {
+		t.Error("Grounding event must precede completion chunks")
+	}
+	if !strings.HasSuffix(out, "data: [DONE]\n\n") {
+		t.Error("Stream must terminate with [DONE]")
+	}
+
+	// The grounding payload carries coordinates
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.Contains(line, "[DONE]") {
+			continue
+		}
+		var ev GroundingEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+			continue
+		}
+		if ev.Object != "chat.grounding" {
+			continue
+		}
+		if ev.Coordinates == nil {
+			t.Error("Grounding event has no coordinates")
+		}
+		return
+	}
+	t.Error("No parseable grounding event found")
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	h := NewHandler(nil)
+
+	// Create
+	body, _ := json.Marshal(SessionRequest{Model: "gui-actor-2b"})
+	req := httptest.NewRequest("POST", "/v1/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.CreateSession(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("CreateSession status = %d", w.Code)
+	}
+	var created SessionResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode session: %v", err)
+	}
+	if created.ID == "" || created.Object != "session" {
+		t.Fatalf("Unexpected session response: %+v", created)
+	}
+
+	// Chat referencing the session stores history server-side
+	chatBody, _ := json.Marshal(ChatCompletionRequest{
+		Model:     "gui-actor-2b",
+		SessionID: created.ID,
+		Messages:  []Message{{Role: "user", Content: "click start"}},
+	})
+	req = httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(chatBody))
+	w = httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ChatCompletion status = %d: %s", w.Code, w.Body.String())
+	}
+
+	// Get shows accumulated messages (user + assistant)
+	req = httptest.NewRequest("GET", "/v1/sessions/"+created.ID, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+	w = httptest.NewRecorder()
+	h.GetSession(w, req)
+	var got SessionResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode session: %v", err)
+	}
+	if got.Messages != 2 {
+		t.Errorf("Messages = %d, want 2", got.Messages)
+	}
+
+	// Delete
+	req = httptest.NewRequest("DELETE", "/v1/sessions/"+created.ID, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+	w = httptest.NewRecorder()
+	h.DeleteSession(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("DeleteSession status = %d", w.Code)
+	}
+
+	// Unknown session in chat request returns 404
+	req = httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(chatBody))
+	w = httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for deleted session, got %d", w.Code)
+	}
+}
+
+func TestModelInfoReportsLayerResidency(t *testing.T) {
+	status := model.ModelStatus{
+		Name:   "gui-actor-7b",
+		Loaded: true,
+		Residency: &model.Residency{
+			TotalLayers:     28,
+			ResidentLayers:  8,
+			OffloadedLayers: 20,
+			KeepFirst:       4,
+			KeepLast:        4,
+			Target:          model.OffloadTargetMmap,
+		},
+	}
+
+	info := modelInfo(status)
+	if info.Offload == nil {
+		t.Fatal("modelInfo dropped the residency plan")
+	}
+	if info.Offload.OffloadedLayers != 20 || info.Offload.Target != model.OffloadTargetMmap {
+		t.Errorf("Offload = %+v", info.Offload)
+	}
+
+	if plain := modelInfo(model.ModelStatus{Name: "gui-actor-2b"}); plain.Offload != nil {
+		t.Errorf("model without offload reports %+v", plain.Offload)
+	}
+}
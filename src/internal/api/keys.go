@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateKey handles POST /v1/admin/keys: mint a new managed API key.
+// When admin keys are configured, only they may manage keys.
+func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to manage api keys")
+		return
+	}
+
+	var req KeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	key, err := h.usage.CreateKey(req.Name, req.Admin)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.auditLog(r, "key.create", key.Key, "name="+req.Name)
+
+	writeJSON(w, http.StatusCreated, KeyInfo{
+		Key:     key.Key,
+		Name:    key.Name,
+		Admin:   key.Admin,
+		Created: key.Created,
+	})
+}
+
+// ListKeys handles GET /v1/admin/keys: every managed key with its
+// lifetime usage totals
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to manage api keys")
+		return
+	}
+
+	keys, err := h.usage.ListAPIKeys()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := KeysResponse{Object: "list", Data: make([]KeyInfo, 0, len(keys))}
+	now := time.Now().UTC()
+	for _, key := range keys {
+		info := KeyInfo{
+			Key:       key.Key,
+			Name:      key.Name,
+			Admin:     key.Admin,
+			Created:   key.Created,
+			Revoked:   key.Revoked,
+			RevokedAt: key.RevokedAt,
+		}
+		if report, err := h.usage.Query(key.Key, time.Unix(key.Created, 0), now); err == nil {
+			info.Requests = report.Totals.Requests
+			info.TotalTokens = report.Totals.PromptTokens + report.Totals.CompletionTokens
+		}
+		resp.Data = append(resp.Data, info)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RevokeKey handles DELETE /v1/admin/keys/{key}: reject the key from
+// now on while keeping its usage history
+func (h *Handler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to manage api keys")
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	if _, found, err := h.usage.GetKey(key); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !found {
+		writeError(w, http.StatusNotFound, "unknown api key")
+		return
+	}
+
+	if err := h.usage.RevokeKey(key); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.auditLog(r, "key.revoke", key, "")
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
+
+// rejectRevoked rejects requests authenticated with a revoked managed
+// key. Returns true if the request was rejected.
+func (h *Handler) rejectRevoked(w http.ResponseWriter, r *http.Request) bool {
+	if h.usage == nil {
+		return false
+	}
+	key := apiKeyFrom(r)
+	if key == anonymousKey {
+		return false
+	}
+	if record, found, err := h.usage.GetKey(key); err == nil && found && record.Revoked {
+		writeError(w, http.StatusUnauthorized, "api key has been revoked")
+		return true
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+func createKey(t *testing.T, h *Handler, name string, admin bool) KeyInfo {
+	t.Helper()
+	body, _ := json.Marshal(KeyRequest{Name: name, Admin: admin})
+	w := httptest.NewRecorder()
+	h.CreateKey(w, httptest.NewRequest("POST", "/v1/admin/keys", bytes.NewReader(body)))
+	if w.Code != 201 {
+		t.Fatalf("CreateKey returned status %d: %s", w.Code, w.Body.String())
+	}
+	var info KeyInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode key response: %v", err)
+	}
+	return info
+}
+
+func TestCreateKeyEndpoint(t *testing.T) {
+	h := newUsageHandler(t)
+
+	info := createKey(t, h, "alice", false)
+	if info.Key == "" || info.Name != "alice" || info.Admin {
+		t.Errorf("CreateKey = %+v, want named non-admin key", info)
+	}
+}
+
+func TestCreateKeyValidation(t *testing.T) {
+	h := newUsageHandler(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"missing name", `{}`, 400},
+		{"invalid json", `{`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.CreateKey(w, httptest.NewRequest("POST", "/v1/admin/keys", bytes.NewReader([]byte(tt.body))))
+			if w.Code != tt.code {
+				t.Errorf("CreateKey status = %d, want %d", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestKeyEndpointsDisabled(t *testing.T) {
+	h := NewHandler(nil)
+
+	w := httptest.NewRecorder()
+	h.ListKeys(w, httptest.NewRequest("GET", "/v1/admin/keys", nil))
+	if w.Code != 503 {
+		t.Errorf("ListKeys status = %d, want 503", w.Code)
+	}
+}
+
+func TestKeyEndpointsAdminGated(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"sk-admin"}})
+
+	req := httptest.NewRequest("GET", "/v1/admin/keys", nil)
+	req.Header.Set("X-API-Key", "sk-lab-1")
+	w := httptest.NewRecorder()
+	h.ListKeys(w, req)
+	if w.Code != 403 {
+		t.Errorf("ListKeys status for non-admin = %d, want 403", w.Code)
+	}
+}
+
+func TestListKeysWithUsage(t *testing.T) {
+	h := newUsageHandler(t)
+
+	info := createKey(t, h, "alice", false)
+	sample := usage.Day{Requests: 2, PromptTokens: 30, CompletionTokens: 10}
+	if err := h.usage.Record(info.Key, time.Now(), sample); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ListKeys(w, httptest.NewRequest("GET", "/v1/admin/keys", nil))
+	if w.Code != 200 {
+		t.Fatalf("ListKeys returned status %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp KeysResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode keys response: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("ListKeys returned %d keys, want 1", len(resp.Data))
+	}
+	got := resp.Data[0]
+	if got.Requests != 2 || got.TotalTokens != 40 {
+		t.Errorf("key usage = (requests=%d, tokens=%d), want (2, 40)", got.Requests, got.TotalTokens)
+	}
+}
+
+func TestRevokeKeyEndpoint(t *testing.T) {
+	h := newUsageHandler(t)
+	info := createKey(t, h, "alice", false)
+
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/v1/admin/keys/"+info.Key, nil),
+		map[string]string{"key": info.Key})
+	w := httptest.NewRecorder()
+	h.RevokeKey(w, req)
+	if w.Code != 200 {
+		t.Fatalf("RevokeKey returned status %d: %s", w.Code, w.Body.String())
+	}
+
+	// Completions authenticated with the revoked key are rejected
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "Click the button"}},
+	})
+	creq := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	creq.Header.Set("X-API-Key", info.Key)
+	cw := httptest.NewRecorder()
+	h.ChatCompletion(cw, creq)
+	if cw.Code != 401 {
+		t.Errorf("ChatCompletion with revoked key status = %d, want 401", cw.Code)
+	}
+}
+
+func TestRevokeUnknownKeyEndpoint(t *testing.T) {
+	h := newUsageHandler(t)
+
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/v1/admin/keys/sk-nope", nil),
+		map[string]string{"key": "sk-nope"})
+	w := httptest.NewRecorder()
+	h.RevokeKey(w, req)
+	if w.Code != 404 {
+		t.Errorf("RevokeKey status = %d, want 404", w.Code)
+	}
+}
+
+func TestManagedAdminKey(t *testing.T) {
+	h := newUsageHandler(t)
+	info := createKey(t, h, "ops", true)
+
+	if !h.isAdmin(info.Key) {
+		t.Error("managed admin key not recognized as admin")
+	}
+
+	if err := h.usage.RevokeKey(info.Key); err != nil {
+		t.Fatalf("RevokeKey failed: %v", err)
+	}
+	if h.isAdmin(info.Key) {
+		t.Error("revoked key still recognized as admin")
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetLoadGate bounds how many requests may queue behind an in-flight
+// model load; requests beyond the limit get 503 model_loading. Zero
+// queues nothing and rejects immediately.
+func (h *Handler) SetLoadGate(maxWaiters int) {
+	if maxWaiters <= 0 {
+		h.loadWaiters = nil
+		return
+	}
+	h.loadWaiters = make(chan struct{}, maxWaiters)
+}
+
+// gateLoading holds the request while its model is still loading. When
+// the wait queue is full it answers 503 with a model_loading code and a
+// Retry-After estimate instead of hitting a half-initialized engine.
+// The return value reports whether the response was already written.
+func (h *Handler) gateLoading(w http.ResponseWriter, r *http.Request, model string) bool {
+	if h.registry == nil {
+		return false
+	}
+	loading, _, eta := h.registry.LoadState(model)
+	if !loading {
+		return false
+	}
+
+	if h.loadWaiters != nil {
+		select {
+		case h.loadWaiters <- struct{}{}:
+			defer func() { <-h.loadWaiters }()
+			if err := h.registry.AwaitLoad(r.Context(), model); err != nil {
+				writeLoadingError(w, model, eta)
+				return true
+			}
+			return false
+		default:
+			// Queue full; fall through to rejection
+		}
+	}
+
+	writeLoadingError(w, model, eta)
+	return true
+}
+
+func writeLoadingError(w http.ResponseWriter, model string, eta time.Duration) {
+	retryAfter := int(math.Ceil(eta.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+		Error: ErrorDetail{
+			Message: fmt.Sprintf("model %q is still loading, retry in ~%ds", model, retryAfter),
+			Type:    "server_error",
+			Code:    "model_loading",
+		},
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func postCompletionFor(t *testing.T, h *Handler, model string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    model,
+		Messages: []Message{{Role: "user", Content: "click the button"}},
+	})
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+	return w
+}
+
+func TestLoadingModelRejectedWithoutQueue(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.registry.StartLoad("gui-actor-2b")
+
+	w := postCompletionFor(t, h, "gui-actor-2b")
+	if w.Code != 503 {
+		t.Fatalf("ChatCompletion status = %d, want 503: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("503 response is missing Retry-After")
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid error body: %v", err)
+	}
+	if resp.Error.Code != "model_loading" {
+		t.Errorf("error code = %q, want model_loading", resp.Error.Code)
+	}
+}
+
+func TestLoadingModelQueuesUntilLoaded(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetLoadGate(4)
+	h.registry.StartLoad("gui-actor-2b")
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- postCompletionFor(t, h, "gui-actor-2b") }()
+
+	select {
+	case w := <-done:
+		t.Fatalf("request completed with %d while the model was loading", w.Code)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	h.registry.FinishLoad("gui-actor-2b", nil)
+	select {
+	case w := <-done:
+		if w.Code != 200 {
+			t.Errorf("queued request status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued request never completed")
+	}
+}
+
+func TestLoadingModelQueueOverflow(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetLoadGate(1)
+	h.registry.StartLoad("gui-actor-2b")
+	defer h.registry.FinishLoad("gui-actor-2b", nil)
+
+	// Fill the single queue slot
+	go postCompletionFor(t, h, "gui-actor-2b")
+	time.Sleep(20 * time.Millisecond)
+
+	// The next request overflows the queue and is rejected
+	if w := postCompletionFor(t, h, "gui-actor-2b"); w.Code != 503 {
+		t.Errorf("overflow request status = %d, want 503", w.Code)
+	}
+}
+
+func TestListModelsReportsLoadProgress(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.registry.StartLoad("gui-actor-2b")
+	h.registry.SetLoadProgress("gui-actor-2b", 0.25)
+
+	w := httptest.NewRecorder()
+	h.ListModels(w, httptest.NewRequest("GET", "/v1/models", nil))
+
+	var resp ModelsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("got %d models, want 1", len(resp.Data))
+	}
+	if !resp.Data[0].Loading || resp.Data[0].LoadProgress != 0.25 {
+		t.Errorf("model info = %+v, want loading at 0.25", resp.Data[0])
+	}
+}
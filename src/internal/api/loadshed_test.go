@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/scheduler"
+)
+
+// overloadedScheduler builds a scheduler whose queue-wait estimate is
+// already past the shed threshold, by staging one real queued admission
+func overloadedScheduler(t *testing.T) *scheduler.Scheduler {
+	t.Helper()
+	sched := scheduler.New(scheduler.Config{Slots: 1, ShedThreshold: time.Nanosecond})
+	if err := sched.Acquire(context.Background(), "holder"); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	admitted := make(chan struct{})
+	go func() {
+		defer close(admitted)
+		if err := sched.Acquire(context.Background(), "queued"); err != nil {
+			t.Errorf("queued Acquire: %v", err)
+			return
+		}
+		sched.Release()
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for sched.Queued() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("request never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond) // accumulate measurable queue wait
+	sched.Release()
+	<-admitted
+	return sched
+}
+
+func TestShedRequestsAnswered503(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetScheduler(overloadedScheduler(t))
+
+	resp := postCompletion(t, h, `{"messages":[{"role":"user","content":"hi"}]}`)
+	if resp.Code != 503 {
+		t.Fatalf("status = %d, want 503", resp.Code)
+	}
+	if ra := resp.Header().Get("Retry-After"); ra != "1" {
+		t.Errorf("Retry-After = %q, want 1", ra)
+	}
+	var er ErrorResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &er); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if er.Error.Type != "server_overloaded" {
+		t.Errorf("error type = %q, want server_overloaded", er.Error.Type)
+	}
+}
+
+func TestShedCountExposedInMetrics(t *testing.T) {
+	h := NewHandler(nil)
+	sched := overloadedScheduler(t)
+	h.SetScheduler(sched)
+
+	if resp := postCompletion(t, h, `{"messages":[{"role":"user","content":"hi"}]}`); resp.Code != 503 {
+		t.Fatalf("status = %d, want 503", resp.Code)
+	}
+	if sched.Shed() < 1 {
+		t.Errorf("Shed = %d, want >= 1", sched.Shed())
+	}
+}
+
+func TestNoSheddingWithoutThreshold(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetScheduler(scheduler.New(scheduler.Config{Slots: 1}))
+
+	resp := postCompletion(t, h, `{"messages":[{"role":"user","content":"hi"}]}`)
+	if resp.Code != 200 {
+		t.Fatalf("status = %d, want 200", resp.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/slo"
+)
+
+// SetSLOMonitor enables SLO evaluation of completion requests
+func (h *Handler) SetSLOMonitor(m *slo.Monitor) {
+	h.slo = m
+}
+
+// recordSLO feeds one finished request into the SLO window
+func (h *Handler) recordSLO(start time.Time, failed bool) {
+	if h.slo == nil {
+		return
+	}
+	h.slo.Record(slo.Sample{TTFT: time.Since(start), Err: failed})
+}
+
+// Metrics serves operational counters for scraping; breach counters
+// make SLO degradation visible without tailing logs
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	resp := MetricsResponse{
+		UptimeSeconds:   int64(time.Since(h.startTime).Seconds()),
+		ActiveStreams:   h.streams.Active(),
+		RequestTimeouts: h.Timeouts(),
+	}
+	if h.registry != nil {
+		for _, status := range h.registry.Status() {
+			if status.Loaded {
+				resp.LoadedModels++
+			}
+		}
+	}
+	if h.sched != nil {
+		resp.QueuedRequests = h.sched.Queued()
+		resp.InFlightRequests = h.sched.InFlight()
+		resp.ShedRequests = h.sched.Shed()
+	}
+	if h.slo != nil {
+		status := h.slo.Status()
+		resp.SLO = &status
+	}
+	if h.shadowLog != nil {
+		stats := h.shadowLog.Stats()
+		resp.Shadow = &stats
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
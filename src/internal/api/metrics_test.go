@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/slo"
+)
+
+func getMetrics(t *testing.T, h *Handler) MetricsResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Metrics(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp MetricsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode metrics: %v", err)
+	}
+	return resp
+}
+
+func TestMetricsWithoutSLO(t *testing.T) {
+	h := NewHandler(nil)
+	resp := getMetrics(t, h)
+	if resp.SLO != nil {
+		t.Error("SLO section present without a monitor")
+	}
+}
+
+func TestMetricsReportsSLOSamples(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSLOMonitor(slo.NewMonitor(slo.Config{TTFT: time.Second}))
+
+	// Each completion feeds the SLO window
+	for i := 0; i < 3; i++ {
+		body := bytes.NewBufferString(`{"messages":[{"role":"user","content":"click"}]}`)
+		rec := httptest.NewRecorder()
+		h.ChatCompletion(rec, httptest.NewRequest("POST", "/v1/chat/completions", body))
+		if rec.Code != 200 {
+			t.Fatalf("completion status = %d", rec.Code)
+		}
+	}
+
+	resp := getMetrics(t, h)
+	if resp.SLO == nil {
+		t.Fatal("SLO section missing")
+	}
+	if resp.SLO.Samples != 3 {
+		t.Errorf("SLO samples = %d, want 3", resp.SLO.Samples)
+	}
+	if resp.SLO.TTFTBreaches != 0 {
+		t.Errorf("TTFTBreaches = %d, want 0", resp.SLO.TTFTBreaches)
+	}
+}
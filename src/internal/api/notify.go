@@ -0,0 +1,17 @@
+package api
+
+import "github.com/agenthands/mlxvllm/internal/notify"
+
+// SetNotifier enables webhook delivery of server events
+func (h *Handler) SetNotifier(n *notify.Notifier) {
+	h.notifier = n
+}
+
+// emit queues an event for webhook delivery; a no-op when
+// notifications are not configured
+func (h *Handler) emit(eventType string, detail map[string]interface{}) {
+	if h.notifier == nil {
+		return
+	}
+	h.notifier.Emit(eventType, detail)
+}
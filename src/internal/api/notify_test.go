@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/model"
+	"github.com/agenthands/mlxvllm/internal/notify"
+)
+
+func TestModelLifecycleEmitsEvents(t *testing.T) {
+	events := make(chan notify.Event, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev notify.Event
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Errorf("bad event payload: %v", err)
+			return
+		}
+		events <- ev
+	}))
+	defer srv.Close()
+
+	registry := model.NewRegistry(&config.Config{Models: map[string]config.ModelConfig{
+		"gui-actor-2b": {Path: "./models/gui-actor-2b", Enabled: true},
+	}})
+	h := NewHandler(registry)
+	notifier := notify.New(notify.Config{URLs: []string{srv.URL}})
+	h.SetNotifier(notifier)
+
+	req := httptest.NewRequest("POST", "/v1/models/gui-actor-2b/load", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "gui-actor-2b"})
+	rec := httptest.NewRecorder()
+	h.LoadModel(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("load status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("DELETE", "/v1/models/gui-actor-2b", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "gui-actor-2b"})
+	rec = httptest.NewRecorder()
+	h.UnloadModel(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unload status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	notifier.Close()
+	close(events)
+
+	var types []string
+	for ev := range events {
+		types = append(types, ev.Type)
+		if ev.Detail["model"] != "gui-actor-2b" {
+			t.Errorf("event %s detail = %v", ev.Type, ev.Detail)
+		}
+	}
+	if len(types) != 2 || types[0] != "model.loaded" || types[1] != "model.unloaded" {
+		t.Errorf("event types = %v, want [model.loaded model.unloaded]", types)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/base64"
+	"log"
+	"strings"
+
+	"github.com/agenthands/mlxvllm/internal/ocr"
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// SetOCR enables OCR-assisted grounding for the given models; models
+// without an entry skip OCR entirely
+func (h *Handler) SetOCR(providers map[string]ocr.Provider) {
+	h.ocrProviders = providers
+}
+
+// applyOCRHints runs the model's OCR provider over every inline
+// screenshot and appends the detected text boxes to the same message
+// as a structured text part. Hints are assistive, so recognition
+// failures are logged and the request proceeds without them. The
+// detected boxes are returned for coordinate snapping.
+func (h *Handler) applyOCRHints(req *ChatCompletionRequest) []ocr.Box {
+	provider, ok := h.ocrProviders[req.Model]
+	if !ok {
+		return nil
+	}
+	var allBoxes []ocr.Box
+
+	for mi := range req.Messages {
+		parts, ok := req.Messages[mi].Content.([]interface{})
+		if !ok {
+			continue
+		}
+		var hints []string
+		for _, part := range parts {
+			m, ok := part.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			imageURL, ok := m["image_url"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			url, ok := imageURL["url"].(string)
+			if !ok {
+				continue
+			}
+			idx := strings.Index(url, "base64,")
+			if idx < 0 {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(url[idx+len("base64,"):])
+			if err != nil {
+				continue
+			}
+			img, err := vision.Decode(data)
+			if err != nil {
+				continue
+			}
+			boxes, err := provider.Recognize(img)
+			if err != nil {
+				log.Printf("ocr provider %s failed: %v", provider.Name(), err)
+				continue
+			}
+			if hint := ocr.FormatHints(boxes); hint != "" {
+				hints = append(hints, hint)
+			}
+			allBoxes = append(allBoxes, boxes...)
+		}
+		for _, hint := range hints {
+			parts = append(parts, map[string]interface{}{"type": "text", "text": hint})
+		}
+		if len(hints) > 0 {
+			req.Messages[mi].Content = parts
+		}
+	}
+	return allBoxes
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/ocr"
+)
+
+type stubOCR struct {
+	boxes []ocr.Box
+	err   error
+	calls int
+}
+
+func (s *stubOCR) Name() string { return "stub" }
+func (s *stubOCR) Recognize(image.Image) ([]ocr.Box, error) {
+	s.calls++
+	return s.boxes, s.err
+}
+
+func ocrImageRequest(t *testing.T, model string) *ChatCompletionRequest {
+	t.Helper()
+	return &ChatCompletionRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: "user", Content: []interface{}{
+				map[string]interface{}{"type": "text", "text": "click save"},
+				map[string]interface{}{
+					"type":      "image_url",
+					"image_url": map[string]interface{}{"url": pngDataURI(t, 56, 56)},
+				},
+			}},
+		},
+	}
+}
+
+func TestOCRHintsAppended(t *testing.T) {
+	h := NewHandler(nil)
+	stub := &stubOCR{boxes: []ocr.Box{{Text: "Save", X0: 0.4, Y0: 0.8, X1: 0.5, Y1: 0.85}}}
+	h.SetOCR(map[string]ocr.Provider{"gui-actor-2b": stub})
+
+	req := ocrImageRequest(t, "gui-actor-2b")
+	h.applyOCRHints(req)
+
+	parts, ok := req.Messages[0].Content.([]interface{})
+	if !ok || len(parts) != 3 {
+		t.Fatalf("got %d content parts, want 3 (text, image, hint)", len(parts))
+	}
+	hint, ok := parts[2].(map[string]interface{})
+	if !ok || hint["type"] != "text" {
+		t.Fatalf("appended part is not a text part: %v", parts[2])
+	}
+	if text, _ := hint["text"].(string); !strings.Contains(text, `"Save"`) {
+		t.Errorf("hint %q does not carry the detected text", text)
+	}
+	if stub.calls != 1 {
+		t.Errorf("Recognize called %d times, want 1", stub.calls)
+	}
+}
+
+func TestOCRSkipsUnconfiguredModel(t *testing.T) {
+	h := NewHandler(nil)
+	stub := &stubOCR{boxes: []ocr.Box{{Text: "Save"}}}
+	h.SetOCR(map[string]ocr.Provider{"gui-actor-7b": stub})
+
+	req := ocrImageRequest(t, "gui-actor-2b")
+	h.applyOCRHints(req)
+
+	if parts := req.Messages[0].Content.([]interface{}); len(parts) != 2 {
+		t.Errorf("got %d content parts, want the original 2", len(parts))
+	}
+	if stub.calls != 0 {
+		t.Errorf("Recognize called %d times for an unconfigured model", stub.calls)
+	}
+}
+
+func TestOCRFailureLeavesRequestIntact(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetOCR(map[string]ocr.Provider{"gui-actor-2b": &stubOCR{err: errors.New("framework unavailable")}})
+
+	req := ocrImageRequest(t, "gui-actor-2b")
+	h.applyOCRHints(req)
+
+	if parts := req.Messages[0].Content.([]interface{}); len(parts) != 2 {
+		t.Errorf("got %d content parts, want the original 2 after an OCR failure", len(parts))
+	}
+}
+
+func TestOCRNoBoxesNoHint(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetOCR(map[string]ocr.Provider{"gui-actor-2b": &stubOCR{}})
+
+	req := ocrImageRequest(t, "gui-actor-2b")
+	h.applyOCRHints(req)
+
+	if parts := req.Messages[0].Content.([]interface{}); len(parts) != 2 {
+		t.Errorf("got %d content parts, want 2 when nothing was recognized", len(parts))
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/plugin"
+)
+
+// SetPlugins enables generation lifecycle hooks for completion requests
+func (h *Handler) SetPlugins(p *plugin.Registry) {
+	h.plugins = p
+}
+
+// pluginRequest builds the lifecycle descriptor hooks observe
+func pluginRequest(r *http.Request, req *ChatCompletionRequest, id string) *plugin.Request {
+	return &plugin.Request{
+		ID:     id,
+		Model:  req.Model,
+		APIKey: apiKeyFrom(r),
+		Prompt: lastUserText(req.Messages),
+		Stream: req.Stream,
+	}
+}
+
+// firePluginRequest runs admission hooks; the return value reports
+// whether the response was already written
+func (h *Handler) firePluginRequest(w http.ResponseWriter, preq *plugin.Request) bool {
+	if h.plugins == nil {
+		return false
+	}
+	if err := h.plugins.FireRequest(preq); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return true
+	}
+	return false
+}
+
+// firePluginGeneration drives the prefill and token events around the
+// generated content and reports whether generation may proceed; a
+// non-nil error means a hook aborted the request.
+// TODO: fire OnToken per decoded step once real inference lands
+func (h *Handler) firePluginGeneration(preq *plugin.Request, content string) error {
+	if h.plugins == nil {
+		return nil
+	}
+	h.plugins.FirePrefillDone(preq)
+	if err := h.plugins.FireToken(preq, plugin.Token{Index: 0, Text: content}); err != nil {
+		if err == plugin.ErrStopGeneration {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// firePluginComplete reports the finished generation to hooks
+func (h *Handler) firePluginComplete(preq *plugin.Request, content, finishReason string, start time.Time) {
+	if h.plugins == nil {
+		return
+	}
+	h.plugins.FireComplete(preq, plugin.Result{
+		Content:      content,
+		FinishReason: finishReason,
+		Tokens:       1,
+		DurationMS:   time.Since(start).Milliseconds(),
+	})
+}
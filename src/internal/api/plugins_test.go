@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/plugin"
+)
+
+// vetoHook rejects requests for a specific model
+type vetoHook struct {
+	plugin.Base
+	blockModel string
+	completed  []plugin.Result
+}
+
+func (h *vetoHook) Name() string { return "veto" }
+
+func (h *vetoHook) OnRequest(req *plugin.Request) error {
+	if h.blockModel != "" && req.Model == h.blockModel {
+		return errors.New("model disabled by policy")
+	}
+	return nil
+}
+
+func (h *vetoHook) OnComplete(req *plugin.Request, res plugin.Result) {
+	h.completed = append(h.completed, res)
+}
+
+func TestPluginVetoRejectsRequest(t *testing.T) {
+	h := NewHandler(nil)
+	reg := plugin.NewRegistry()
+	reg.Register(&vetoHook{blockModel: "gui-actor-2b"})
+	h.SetPlugins(reg)
+
+	rec := postCompletion(t, h, `{"model":"gui-actor-2b","messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 403 {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "veto") {
+		t.Error("rejection does not name the plugin")
+	}
+}
+
+func TestPluginObservesCompletion(t *testing.T) {
+	h := NewHandler(nil)
+	reg := plugin.NewRegistry()
+	hook := &vetoHook{}
+	reg.Register(hook)
+	h.SetPlugins(reg)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if len(hook.completed) != 1 {
+		t.Fatalf("OnComplete ran %d times, want 1", len(hook.completed))
+	}
+	res := hook.completed[0]
+	if res.Content == "" || res.FinishReason != "stop" {
+		t.Errorf("result = %+v, want content and finish reason", res)
+	}
+}
+
+func TestPluginObservesStream(t *testing.T) {
+	h := NewHandler(nil)
+	reg := plugin.NewRegistry()
+	hook := &vetoHook{}
+	reg.Register(hook)
+	h.SetPlugins(reg)
+
+	rec := postCompletion(t, h, `{"stream":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if len(hook.completed) != 1 {
+		t.Errorf("OnComplete ran %d times, want 1", len(hook.completed))
+	}
+}
+
+func TestNoPluginsConfigured(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import "time"
+
+// Sleep/wake handling. The power monitor calls Suspend before the
+// machine sleeps and Wake after it returns, so queued requests survive
+// a lid close instead of timing out against a frozen scheduler.
+
+// trackStream registers an open SSE connection for checkpointing
+func (h *Handler) trackStream(conn *streamConn) {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	if h.activeConns == nil {
+		h.activeConns = make(map[*streamConn]struct{})
+	}
+	h.activeConns[conn] = struct{}{}
+}
+
+func (h *Handler) untrackStream(conn *streamConn) {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	delete(h.activeConns, conn)
+}
+
+// Suspend pauses admission of new requests and checkpoints in-flight
+// streams with a keep-alive event, so clients hold their connections
+// through the sleep rather than treating the silence as a dead server
+func (h *Handler) Suspend() {
+	if h.sched != nil {
+		h.sched.Pause()
+	}
+
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	for conn := range h.activeConns {
+		conn.SendEvent("keep-alive", map[string]interface{}{
+			"object":  "system.suspend",
+			"created": time.Now().Unix(),
+		})
+	}
+}
+
+// Wake reopens admission after the system returns from sleep
+func (h *Handler) Wake() {
+	if h.sched != nil {
+		h.sched.Resume()
+	}
+}
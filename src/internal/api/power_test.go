@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/scheduler"
+)
+
+func TestSuspendPausesSchedulerAndCheckpointsStreams(t *testing.T) {
+	h := NewHandler(nil)
+	sched := scheduler.New(scheduler.Config{Slots: 1})
+	h.SetScheduler(sched)
+
+	rec := httptest.NewRecorder()
+	conn := newStreamConn(rec, httptest.NewRequest("POST", "/", nil), 4, 0)
+	h.trackStream(conn)
+
+	h.Suspend()
+
+	if !sched.Paused() {
+		t.Error("scheduler not paused after Suspend")
+	}
+
+	h.untrackStream(conn)
+	conn.Close()
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: keep-alive") {
+		t.Errorf("stream body %q missing keep-alive event", body)
+	}
+	if !strings.Contains(body, "system.suspend") {
+		t.Errorf("stream body %q missing suspend payload", body)
+	}
+
+	h.Wake()
+	if sched.Paused() {
+		t.Error("scheduler still paused after Wake")
+	}
+}
+
+func TestSuspendSkipsUntrackedStreams(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := httptest.NewRecorder()
+	conn := newStreamConn(rec, httptest.NewRequest("POST", "/", nil), 4, 0)
+	h.trackStream(conn)
+	h.untrackStream(conn)
+
+	h.Suspend()
+	conn.Close()
+
+	if body := rec.Body.String(); body != "" {
+		t.Errorf("untracked stream received %q", body)
+	}
+}
+
+func TestSuspendWakeWithoutScheduler(t *testing.T) {
+	h := NewHandler(nil)
+	// No scheduler and no streams configured: both are no-ops
+	h.Suspend()
+	h.Wake()
+}
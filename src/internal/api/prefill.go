@@ -0,0 +1,39 @@
+package api
+
+import "strings"
+
+// Assistant prefill: a trailing assistant message with partial content
+// seeds the response, so clients can force beginnings like
+// "pyautogui." and let generation continue from there. The partial
+// message is popped from the prompt history and the response carries
+// only the continuation.
+
+// extractPrefill pops a trailing partial assistant message and returns
+// its text; requests without one are left untouched
+func extractPrefill(req *ChatCompletionRequest) string {
+	n := len(req.Messages)
+	if n == 0 {
+		return ""
+	}
+	last := req.Messages[n-1]
+	if last.Role != "assistant" {
+		return ""
+	}
+	text, ok := last.Content.(string)
+	if !ok || text == "" {
+		return ""
+	}
+	req.Messages = req.Messages[:n-1]
+	return text
+}
+
+// continueFrom strips the prefill from the generated text so the
+// response carries only the continuation.
+// TODO: feed the prefill tokens through the tokenizer after the
+// assistant prefix instead of string-matching the mock output
+func continueFrom(prefill, generated string) string {
+	if prefill != "" && strings.HasPrefix(generated, prefill) {
+		return strings.TrimPrefix(generated, prefill)
+	}
+	return generated
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPrefillContinuation(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := `{"messages":[{"role":"user","content":"click save"},{"role":"assistant","content":"pyautogui."}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	content, _ := resp.Choices[0].Message.Content.(string)
+	if strings.HasPrefix(content, "pyautogui.") {
+		t.Errorf("content = %q, want the prefill stripped from the continuation", content)
+	}
+	if content == "" {
+		t.Error("continuation is empty")
+	}
+}
+
+func TestPrefillStreamContinuation(t *testing.T) {
+	h := NewHandler(nil)
+
+	body := `{"stream":true,"messages":[{"role":"user","content":"click save"},{"role":"assistant","content":"pyautogui."}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), `"content":"pyautogui.`) {
+		t.Error("stream repeats the prefill")
+	}
+}
+
+func TestNoPrefillWithoutTrailingAssistant(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	content, _ := resp.Choices[0].Message.Content.(string)
+	if !strings.HasPrefix(content, "pyautogui.") {
+		t.Errorf("content = %q, want the full action without a prefill", content)
+	}
+}
+
+func TestExtractPrefill(t *testing.T) {
+	req := &ChatCompletionRequest{Messages: []Message{
+		{Role: "user", Content: "click save"},
+		{Role: "assistant", Content: "pyautogui."},
+	}}
+	if got := extractPrefill(req); got != "pyautogui." {
+		t.Errorf("extractPrefill = %q, want the partial content", got)
+	}
+	if len(req.Messages) != 1 {
+		t.Errorf("messages = %d, want the partial message popped", len(req.Messages))
+	}
+
+	// Non-string assistant content is not a prefill
+	req = &ChatCompletionRequest{Messages: []Message{
+		{Role: "assistant", Content: []interface{}{}},
+	}}
+	if got := extractPrefill(req); got != "" {
+		t.Errorf("extractPrefill = %q, want empty for non-string content", got)
+	}
+	if len(req.Messages) != 1 {
+		t.Error("non-prefill message was popped")
+	}
+}
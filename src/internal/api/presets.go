@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+// builtinPresets are prompt templates for common agent frameworks.
+// Config entries with the same name override these.
+func builtinPresets() map[string]config.PresetConfig {
+	return map[string]config.PresetConfig{
+		"osworld": {
+			SystemPrompt: "You are a GUI agent. You are given a task and a screenshot of the screen. " +
+				"You need to perform a series of pyautogui actions to complete the task.",
+		},
+		"screenspot": {
+			SystemPrompt: "You are a GUI grounding model. Given a screenshot and an instruction, " +
+				"point to the UI element that should be interacted with.",
+			InstructionTemplate: "Where should you click to: {instruction}",
+		},
+		"agentnet": {
+			SystemPrompt: "You are an autonomous computer-use agent. Observe the screenshot, " +
+				"reason briefly about the next step, then output exactly one action.",
+		},
+	}
+}
+
+// SetPresets merges config-defined presets over the built-in ones
+func (h *Handler) SetPresets(presets map[string]config.PresetConfig) {
+	h.presetMu.Lock()
+	defer h.presetMu.Unlock()
+	for name, p := range presets {
+		h.presets[name] = p
+	}
+}
+
+// ReplacePresets resets to the built-in presets and merges the given
+// ones over them, so a preset whose template file was removed
+// disappears on reload instead of lingering
+func (h *Handler) ReplacePresets(presets map[string]config.PresetConfig) {
+	h.presetMu.Lock()
+	defer h.presetMu.Unlock()
+	h.presets = builtinPresets()
+	for name, p := range presets {
+		h.presets[name] = p
+	}
+}
+
+// preset looks up a preset under the read lock; hot reload swaps the
+// map concurrently with requests
+func (h *Handler) preset(name string) (config.PresetConfig, bool) {
+	h.presetMu.RLock()
+	defer h.presetMu.RUnlock()
+	p, ok := h.presets[name]
+	return p, ok
+}
+
+// applyPreset rewrites the request messages according to the named
+// preset before tokenization: prepends the preset system prompt when
+// the request has none, and wraps the last user instruction with the
+// preset's instruction template.
+func (h *Handler) applyPreset(req *ChatCompletionRequest) error {
+	if req.Preset == "" {
+		return nil
+	}
+
+	preset, ok := h.preset(req.Preset)
+	if !ok {
+		return fmt.Errorf("unknown preset %q", req.Preset)
+	}
+
+	if preset.SystemPrompt != "" && !hasSystemMessage(req.Messages) {
+		req.Messages = append([]Message{{Role: "system", Content: preset.SystemPrompt}}, req.Messages...)
+	}
+
+	if preset.InstructionTemplate != "" {
+		for i := len(req.Messages) - 1; i >= 0; i-- {
+			if req.Messages[i].Role != "user" {
+				continue
+			}
+			if text, ok := req.Messages[i].Content.(string); ok {
+				req.Messages[i].Content = strings.ReplaceAll(preset.InstructionTemplate, "{instruction}", text)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// ValidateTemplate handles POST /v1/templates/validate: render a
+// sample instruction through a preset and return the messages the
+// model would see, so template edits can be checked before a real
+// request depends on them
+func (h *Handler) ValidateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplateValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Preset == "" {
+		writeError(w, http.StatusBadRequest, "preset is required")
+		return
+	}
+	instruction := req.Instruction
+	if instruction == "" {
+		instruction = "click the Save button"
+	}
+
+	sample := ChatCompletionRequest{
+		Preset:   req.Preset,
+		Messages: []Message{{Role: "user", Content: instruction}},
+	}
+	if err := h.applyPreset(&sample); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TemplateValidateResponse{
+		Preset:   req.Preset,
+		Messages: sample.Messages,
+	})
+}
+
+func hasSystemMessage(messages []Message) bool {
+	for _, m := range messages {
+		if m.Role == "system" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func TestApplyPresetSystemPrompt(t *testing.T) {
+	h := NewHandler(nil)
+	req := &ChatCompletionRequest{
+		Preset:   "osworld",
+		Messages: []Message{{Role: "user", Content: "open terminal"}},
+	}
+
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	if len(req.Messages) != 2 {
+		t.Fatalf("Messages = %d, want 2", len(req.Messages))
+	}
+	if req.Messages[0].Role != "system" {
+		t.Error("Preset did not prepend system message")
+	}
+}
+
+func TestApplyPresetKeepsExistingSystemPrompt(t *testing.T) {
+	h := NewHandler(nil)
+	req := &ChatCompletionRequest{
+		Preset: "osworld",
+		Messages: []Message{
+			{Role: "system", Content: "custom"},
+			{Role: "user", Content: "open terminal"},
+		},
+	}
+
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	if len(req.Messages) != 2 {
+		t.Fatalf("Messages = %d, want 2", len(req.Messages))
+	}
+	if req.Messages[0].Content != "custom" {
+		t.Error("Preset replaced the request's own system message")
+	}
+}
+
+func TestApplyPresetInstructionTemplate(t *testing.T) {
+	h := NewHandler(nil)
+	req := &ChatCompletionRequest{
+		Preset:   "screenspot",
+		Messages: []Message{{Role: "user", Content: "close the dialog"}},
+	}
+
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	got := req.Messages[len(req.Messages)-1].Content.(string)
+	want := "Where should you click to: close the dialog"
+	if got != want {
+		t.Errorf("Instruction = %q, want %q", got, want)
+	}
+}
+
+func TestApplyPresetUnknown(t *testing.T) {
+	h := NewHandler(nil)
+	req := &ChatCompletionRequest{Preset: "nonexistent"}
+	if err := h.applyPreset(req); err == nil {
+		t.Error("Expected error for unknown preset")
+	}
+}
+
+func TestApplyPresetEmptyIsNoop(t *testing.T) {
+	h := NewHandler(nil)
+	req := &ChatCompletionRequest{
+		Messages: []Message{{Role: "user", Content: "hello"}},
+	}
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	if len(req.Messages) != 1 {
+		t.Error("Empty preset must not modify messages")
+	}
+}
+
+func TestSetPresetsOverridesBuiltin(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetPresets(map[string]config.PresetConfig{
+		"osworld": {SystemPrompt: "overridden"},
+		"custom":  {SystemPrompt: "from config"},
+	})
+
+	req := &ChatCompletionRequest{
+		Preset:   "osworld",
+		Messages: []Message{{Role: "user", Content: "x"}},
+	}
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	if req.Messages[0].Content != "overridden" {
+		t.Error("Config preset did not override builtin")
+	}
+
+	req = &ChatCompletionRequest{
+		Preset:   "custom",
+		Messages: []Message{{Role: "user", Content: "x"}},
+	}
+	if err := h.applyPreset(req); err != nil {
+		t.Fatalf("applyPreset failed: %v", err)
+	}
+	if req.Messages[0].Content != "from config" {
+		t.Error("Config-only preset not applied")
+	}
+}
+
+func TestReplacePresetsDropsRemoved(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetPresets(map[string]config.PresetConfig{
+		"custom": {SystemPrompt: "from a file"},
+	})
+	h.ReplacePresets(map[string]config.PresetConfig{})
+
+	if _, ok := h.preset("custom"); ok {
+		t.Error("removed preset survived ReplacePresets")
+	}
+	if _, ok := h.preset("osworld"); !ok {
+		t.Error("built-in preset lost by ReplacePresets")
+	}
+}
+
+func TestValidateTemplateRendersSample(t *testing.T) {
+	h := NewHandler(nil)
+	rec := httptest.NewRecorder()
+	h.ValidateTemplate(rec, httptest.NewRequest("POST", "/v1/templates/validate",
+		strings.NewReader(`{"preset":"screenspot","instruction":"open settings"}`)))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TemplateValidateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Messages) != 2 || resp.Messages[0].Role != "system" {
+		t.Fatalf("Messages = %+v, want system + user", resp.Messages)
+	}
+	if resp.Messages[1].Content != "Where should you click to: open settings" {
+		t.Errorf("instruction rendered as %v", resp.Messages[1].Content)
+	}
+}
+
+func TestValidateTemplateErrors(t *testing.T) {
+	h := NewHandler(nil)
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"unknown preset", `{"preset":"nope"}`, 404},
+		{"missing preset", `{}`, 400},
+		{"invalid json", `{`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ValidateTemplate(rec, httptest.NewRequest("POST", "/v1/templates/validate",
+				strings.NewReader(tt.body)))
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+// QuotaPolicy is the server-wide quota configuration: default limits
+// applied to every key without an override, and keys exempt from
+// enforcement (also the only keys allowed to adjust quotas at runtime)
+type QuotaPolicy struct {
+	Default   usage.Quota
+	AdminKeys []string
+}
+
+// SetQuotaPolicy enables quota enforcement (requires a usage store)
+func (h *Handler) SetQuotaPolicy(p QuotaPolicy) {
+	h.quotaDefault = p.Default
+	h.adminKeys = make(map[string]struct{}, len(p.AdminKeys))
+	for _, key := range p.AdminKeys {
+		h.adminKeys[key] = struct{}{}
+	}
+}
+
+// isAdmin reports whether the key is exempt from quotas; both config
+// admin keys and managed keys minted with admin rights qualify
+func (h *Handler) isAdmin(apiKey string) bool {
+	if _, ok := h.adminKeys[apiKey]; ok {
+		return true
+	}
+	if h.usage != nil {
+		if record, found, err := h.usage.GetKey(apiKey); err == nil && found {
+			return record.Admin && !record.Revoked
+		}
+	}
+	return false
+}
+
+// effectiveQuota resolves the key's override or the default
+func (h *Handler) effectiveQuota(apiKey string) (usage.Quota, string) {
+	if h.usage != nil {
+		if q, found, err := h.usage.GetQuota(apiKey); err == nil && found {
+			return q, "override"
+		} else if err != nil {
+			log.Printf("quota lookup failed: %v", err)
+		}
+	}
+	return h.quotaDefault, "default"
+}
+
+// enforceQuota rejects the request with 429 and quota headers when the
+// caller is over its daily or monthly token limit. Returns true if the
+// request was rejected.
+func (h *Handler) enforceQuota(w http.ResponseWriter, r *http.Request) bool {
+	if h.usage == nil {
+		return false
+	}
+
+	key := apiKeyFrom(r)
+	if h.isAdmin(key) {
+		return false
+	}
+	quota, _ := h.effectiveQuota(key)
+	if quota.DailyTokens == 0 && quota.MonthlyTokens == 0 {
+		return false
+	}
+
+	now := time.Now().UTC()
+	checks := []struct {
+		scope string
+		limit int64
+		from  time.Time
+	}{
+		{"daily", quota.DailyTokens, now.Truncate(24 * time.Hour)},
+		{"monthly", quota.MonthlyTokens, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, check := range checks {
+		if check.limit <= 0 {
+			continue
+		}
+		used, err := h.usage.TokensUsed(key, check.from, now)
+		if err != nil {
+			log.Printf("quota check failed: %v", err)
+			continue
+		}
+		if used >= check.limit {
+			w.Header().Set("X-Quota-Scope", check.scope)
+			w.Header().Set("X-Quota-Limit", strconv.FormatInt(check.limit, 10))
+			w.Header().Set("X-Quota-Used", strconv.FormatInt(used, 10))
+			h.emit("quota.exceeded", map[string]interface{}{
+				"api_key": key,
+				"scope":   check.scope,
+				"used":    used,
+				"limit":   check.limit,
+			})
+			writeError(w, http.StatusTooManyRequests,
+				fmt.Sprintf("%s token quota exceeded (%d/%d)", check.scope, used, check.limit))
+			return true
+		}
+	}
+	return false
+}
+
+// GetQuota handles GET /v1/usage/quota: the effective quota for a key
+func (h *Handler) GetQuota(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+
+	key := r.URL.Query().Get("api_key")
+	if key == "" {
+		key = apiKeyFrom(r)
+	}
+
+	quota, source := h.effectiveQuota(key)
+	writeJSON(w, http.StatusOK, QuotaResponse{
+		APIKey:        key,
+		DailyTokens:   quota.DailyTokens,
+		MonthlyTokens: quota.MonthlyTokens,
+		Source:        source,
+		Admin:         h.isAdmin(key),
+	})
+}
+
+// SetQuota handles PUT /v1/usage/quota: adjust a key's quota at
+// runtime. When admin keys are configured, only they may call this.
+func (h *Handler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+	if len(h.adminKeys) > 0 && !h.isAdmin(apiKeyFrom(r)) {
+		writeError(w, http.StatusForbidden, "admin key required to adjust quotas")
+		return
+	}
+
+	var req QuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.APIKey == "" {
+		writeError(w, http.StatusBadRequest, "api_key is required")
+		return
+	}
+	if req.DailyTokens < 0 || req.MonthlyTokens < 0 {
+		writeError(w, http.StatusBadRequest, "quota limits must be non-negative")
+		return
+	}
+
+	quota := usage.Quota{DailyTokens: req.DailyTokens, MonthlyTokens: req.MonthlyTokens}
+	if err := h.usage.SetQuota(req.APIKey, quota); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.auditLog(r, "quota.set", req.APIKey,
+		fmt.Sprintf("daily=%d monthly=%d", quota.DailyTokens, quota.MonthlyTokens))
+
+	writeJSON(w, http.StatusOK, QuotaResponse{
+		APIKey:        req.APIKey,
+		DailyTokens:   quota.DailyTokens,
+		MonthlyTokens: quota.MonthlyTokens,
+		Source:        "override",
+		Admin:         h.isAdmin(req.APIKey),
+	})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+func TestQuotaEnforcement(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{Default: usage.Quota{DailyTokens: 10}})
+
+	// Pre-consume past the limit
+	if err := h.usage.Record("sk-greedy", time.Now(), usage.Day{PromptTokens: 8, CompletionTokens: 4}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "Click something"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-greedy")
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+
+	if w.Code != 429 {
+		t.Fatalf("Status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("X-Quota-Scope") != "daily" {
+		t.Errorf("X-Quota-Scope = %q, want daily", w.Header().Get("X-Quota-Scope"))
+	}
+	if w.Header().Get("X-Quota-Limit") != "10" || w.Header().Get("X-Quota-Used") != "12" {
+		t.Errorf("Quota headers = limit %s used %s, want 10/12",
+			w.Header().Get("X-Quota-Limit"), w.Header().Get("X-Quota-Used"))
+	}
+	if !strings.Contains(w.Body.String(), "quota exceeded") {
+		t.Errorf("Body = %s, want quota exceeded error", w.Body.String())
+	}
+}
+
+func TestQuotaUnderLimitAllows(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{Default: usage.Quota{DailyTokens: 1000}})
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "Click something"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Status = %d, want 200 under quota", w.Code)
+	}
+}
+
+func TestQuotaAdminExempt(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{
+		Default:   usage.Quota{DailyTokens: 1},
+		AdminKeys: []string{"sk-admin"},
+	})
+
+	if err := h.usage.Record("sk-admin", time.Now(), usage.Day{PromptTokens: 100}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "Admin request"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-admin")
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Status = %d, want 200 for admin key", w.Code)
+	}
+}
+
+func TestQuotaMonthlyScope(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{Default: usage.Quota{MonthlyTokens: 50}})
+
+	// Spread usage earlier in the month (if we're on day one it still
+	// lands in the window)
+	now := time.Now().UTC()
+	earlier := now
+	if now.Day() > 1 {
+		earlier = now.AddDate(0, 0, -1)
+	}
+	h.usage.Record(anonymousKey, earlier, usage.Day{PromptTokens: 30})
+	h.usage.Record(anonymousKey, now, usage.Day{CompletionTokens: 25})
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "One more"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+
+	if w.Code != 429 {
+		t.Fatalf("Status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("X-Quota-Scope") != "monthly" {
+		t.Errorf("X-Quota-Scope = %q, want monthly", w.Header().Get("X-Quota-Scope"))
+	}
+}
+
+func TestSetQuotaEndpoint(t *testing.T) {
+	h := newUsageHandler(t)
+	h.SetQuotaPolicy(QuotaPolicy{AdminKeys: []string{"sk-admin"}})
+
+	// Non-admin is rejected
+	body, _ := json.Marshal(QuotaRequest{APIKey: "sk-user", DailyTokens: 100})
+	req := httptest.NewRequest("PUT", "/v1/usage/quota", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.SetQuota(w, req)
+	if w.Code != 403 {
+		t.Errorf("Non-admin status = %d, want 403", w.Code)
+	}
+
+	// Admin can adjust
+	req = httptest.NewRequest("PUT", "/v1/usage/quota", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-admin")
+	w = httptest.NewRecorder()
+	h.SetQuota(w, req)
+	if w.Code != 200 {
+		t.Fatalf("Admin status = %d: %s", w.Code, w.Body.String())
+	}
+
+	// The override is now effective
+	getReq := httptest.NewRequest("GET", "/v1/usage/quota?api_key=sk-user", nil)
+	getW := httptest.NewRecorder()
+	h.GetQuota(getW, getReq)
+
+	var resp QuotaResponse
+	if err := json.Unmarshal(getW.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.DailyTokens != 100 || resp.Source != "override" {
+		t.Errorf("Quota = %+v, want daily 100 from override", resp)
+	}
+}
+
+func TestSetQuotaValidation(t *testing.T) {
+	h := newUsageHandler(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"missing key", `{"daily_tokens": 10}`, 400},
+		{"negative limit", `{"api_key": "x", "daily_tokens": -5}`, 400},
+		{"bad json", `{`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/v1/usage/quota", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.SetQuota(w, req)
+			if w.Code != tt.code {
+				t.Errorf("Status = %d, want %d", w.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"strings"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// SetRedaction configures screen regions that are masked out of every
+// attached screenshot, e.g. a password manager window rectangle.
+// Requests can add further regions via redact_regions.
+func (h *Handler) SetRedaction(regions []vision.Region) {
+	h.redactRegions = regions
+}
+
+// redactScreenshots masks the configured and requested regions in all
+// inline screenshots before anything downstream — recording, hashing,
+// the vision encoder — sees the pixels
+func (h *Handler) redactScreenshots(req *ChatCompletionRequest) error {
+	regions := make([]vision.Region, 0, len(h.redactRegions)+len(req.RedactRegions))
+	regions = append(regions, h.redactRegions...)
+	for _, r := range req.RedactRegions {
+		regions = append(regions, vision.Region{X0: r.X0, Y0: r.Y0, X1: r.X1, Y1: r.Y1, Blur: r.Blur})
+	}
+	if len(regions) == 0 {
+		return nil
+	}
+
+	for mi := range req.Messages {
+		parts, ok := req.Messages[mi].Content.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, part := range parts {
+			m, ok := part.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			imageURL, ok := m["image_url"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			url, ok := imageURL["url"].(string)
+			if !ok {
+				continue
+			}
+			redacted, err := redactDataURI(url, regions)
+			if err != nil {
+				return err
+			}
+			if redacted != "" {
+				imageURL["url"] = redacted
+			}
+		}
+	}
+	return nil
+}
+
+// redactDataURI masks the regions in a base64 data URI image and
+// re-encodes it as PNG. Remote URLs are left alone (empty return).
+func redactDataURI(url string, regions []vision.Region) (string, error) {
+	idx := strings.Index(url, "base64,")
+	if idx < 0 {
+		return "", nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(url[idx+len("base64,"):])
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 image: %w", err)
+	}
+	img, err := vision.Decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, vision.Redact(img, regions)); err != nil {
+		return "", fmt.Errorf("failed to encode redacted image: %w", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// whiteScreenshotURI encodes a solid white PNG as an OpenAI-style data URI
+func whiteScreenshotURI(t *testing.T) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// imageRequest builds a chat request with one screenshot, decoded the
+// way the HTTP layer would decode it (content parts as interface maps)
+func imageRequest(t *testing.T, uri string) ChatCompletionRequest {
+	t.Helper()
+	raw, _ := json.Marshal(ChatCompletionRequest{
+		Model: "gui-actor-2b",
+		Messages: []Message{{
+			Role: "user",
+			Content: []ContentPart{
+				{Type: "text", Text: "click the button"},
+				{Type: "image_url", ImageURL: &ImageURL{URL: uri}},
+			},
+		}},
+	})
+	var req ChatCompletionRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		t.Fatalf("Failed to round-trip request: %v", err)
+	}
+	return req
+}
+
+// extractImageURI pulls the screenshot data URI back out of the request
+func extractImageURI(t *testing.T, req *ChatCompletionRequest) string {
+	t.Helper()
+	parts := req.Messages[0].Content.([]interface{})
+	for _, part := range parts {
+		m := part.(map[string]interface{})
+		if imageURL, ok := m["image_url"].(map[string]interface{}); ok {
+			return imageURL["url"].(string)
+		}
+	}
+	t.Fatal("request has no image part")
+	return ""
+}
+
+func TestRedactScreenshotsMasksConfiguredRegion(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetRedaction([]vision.Region{{X0: 0, Y0: 0, X1: 0.5, Y1: 0.5}})
+
+	req := imageRequest(t, whiteScreenshotURI(t))
+	if err := h.redactScreenshots(&req); err != nil {
+		t.Fatalf("redactScreenshots: %v", err)
+	}
+
+	uri := extractImageURI(t, &req)
+	raw, err := base64.StdEncoding.DecodeString(uri[strings.Index(uri, "base64,")+len("base64,"):])
+	if err != nil {
+		t.Fatalf("redacted URI is not valid base64: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("redacted URI is not a PNG: %v", err)
+	}
+
+	if r, _, _, _ := img.At(10, 10).RGBA(); r != 0 {
+		t.Error("pixel inside redacted region is not black")
+	}
+	if r, _, _, _ := img.At(50, 50).RGBA(); r>>8 != 255 {
+		t.Error("pixel outside redacted region was modified")
+	}
+}
+
+func TestRedactScreenshotsRequestRegions(t *testing.T) {
+	h := newDashboardHandler(t)
+
+	req := imageRequest(t, whiteScreenshotURI(t))
+	req.RedactRegions = []RedactRegion{{X0: 0, Y0: 0, X1: 1, Y1: 1}}
+	if err := h.redactScreenshots(&req); err != nil {
+		t.Fatalf("redactScreenshots: %v", err)
+	}
+
+	uri := extractImageURI(t, &req)
+	raw, _ := base64.StdEncoding.DecodeString(uri[strings.Index(uri, "base64,")+len("base64,"):])
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("redacted URI is not a PNG: %v", err)
+	}
+	if r, _, _, _ := img.At(32, 32).RGBA(); r != 0 {
+		t.Error("requested region was not masked")
+	}
+}
+
+func TestRedactScreenshotsNoRegionsIsNoop(t *testing.T) {
+	h := newDashboardHandler(t)
+
+	uri := whiteScreenshotURI(t)
+	req := imageRequest(t, uri)
+	if err := h.redactScreenshots(&req); err != nil {
+		t.Fatalf("redactScreenshots: %v", err)
+	}
+	if got := extractImageURI(t, &req); got != uri {
+		t.Error("screenshot was re-encoded without any regions configured")
+	}
+}
+
+func TestChatCompletionRejectsBadImageWhenRedacting(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetRedaction([]vision.Region{{X0: 0, Y0: 0, X1: 1, Y1: 1}})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"model": "gui-actor-2b",
+		"messages": []map[string]interface{}{{
+			"role": "user",
+			"content": []map[string]interface{}{{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": "data:image/png;base64,not-valid"},
+			}},
+		}},
+	})
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+
+	if w.Code != 400 {
+		t.Fatalf("ChatCompletion status = %d, want 400", w.Code)
+	}
+}
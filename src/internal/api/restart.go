@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/agenthands/mlxvllm/internal/distributed"
+)
+
+// engineSupervisor is the slice of distributed.Supervisor the handler
+// needs to track requests across engine crashes
+type engineSupervisor interface {
+	Register(distributed.InflightRequest)
+	Deregister(id string)
+	Restarted(id string) bool
+}
+
+// SetEngineSupervisor enables crash-resilient request tracking: the
+// supervisor replays registered prompts after an engine restart, and
+// affected responses carry an x-restarted marker
+func (h *Handler) SetEngineSupervisor(s engineSupervisor) {
+	h.engineSup = s
+}
+
+// trackInflight registers the request for replay and returns its
+// tracking id; empty when no supervisor is configured
+func (h *Handler) trackInflight(req *ChatCompletionRequest) string {
+	if h.engineSup == nil {
+		return ""
+	}
+	id := generateID()
+	h.engineSup.Register(distributed.InflightRequest{ID: id})
+	return id
+}
+
+// markRestarted stamps x-restarted on responses whose engine crashed
+// and was replayed mid-request. Must run before the response body is
+// written.
+func (h *Handler) markRestarted(w http.ResponseWriter, inflightID string) {
+	if inflightID == "" || !h.engineSup.Restarted(inflightID) {
+		return
+	}
+	w.Header().Set("x-restarted", "true")
+}
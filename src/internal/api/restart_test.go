@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/distributed"
+)
+
+// stubSupervisor drives the restart marker without a real engine
+type stubSupervisor struct {
+	restarted    bool
+	registered   []string
+	deregistered []string
+}
+
+func (s *stubSupervisor) Register(req distributed.InflightRequest) {
+	s.registered = append(s.registered, req.ID)
+}
+
+func (s *stubSupervisor) Deregister(id string) {
+	s.deregistered = append(s.deregistered, id)
+}
+
+func (s *stubSupervisor) Restarted(id string) bool {
+	return s.restarted
+}
+
+func postTrackedCompletion(t *testing.T, h *Handler, stream bool) *httptest.ResponseRecorder {
+	t.Helper()
+	body := `{"messages":[{"role":"user","content":"click save"}]}`
+	if stream {
+		body = `{"stream":true,"messages":[{"role":"user","content":"click save"}]}`
+	}
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.ChatCompletion(rec, req)
+	return rec
+}
+
+func TestRestartMarkerOnAffectedResponse(t *testing.T) {
+	h := NewHandler(nil)
+	sup := &stubSupervisor{restarted: true}
+	h.SetEngineSupervisor(sup)
+
+	rec := postTrackedCompletion(t, h, false)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("x-restarted"); got != "true" {
+		t.Errorf("x-restarted = %q, want \"true\"", got)
+	}
+	if len(sup.registered) != 1 || len(sup.deregistered) != 1 {
+		t.Errorf("registered %d, deregistered %d requests, want 1 each", len(sup.registered), len(sup.deregistered))
+	}
+	if sup.registered[0] != sup.deregistered[0] {
+		t.Error("deregistered a different id than was registered")
+	}
+}
+
+func TestRestartMarkerAbsentWithoutRestart(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetEngineSupervisor(&stubSupervisor{})
+
+	rec := postTrackedCompletion(t, h, false)
+	if got := rec.Header().Get("x-restarted"); got != "" {
+		t.Errorf("x-restarted = %q, want unset", got)
+	}
+}
+
+func TestRestartMarkerOnStream(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetEngineSupervisor(&stubSupervisor{restarted: true})
+
+	rec := postTrackedCompletion(t, h, true)
+	if got := rec.Header().Get("x-restarted"); got != "true" {
+		t.Errorf("x-restarted = %q, want \"true\"", got)
+	}
+}
+
+func TestNoSupervisorNoTracking(t *testing.T) {
+	h := NewHandler(nil)
+	rec := postTrackedCompletion(t, h, false)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("x-restarted"); got != "" {
+		t.Errorf("x-restarted = %q, want unset", got)
+	}
+}
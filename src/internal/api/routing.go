@@ -0,0 +1,38 @@
+package api
+
+// Model routing maps client-facing model names onto local models:
+// aliases let cloud API names (e.g. "gpt-4o") resolve to a local
+// model, and server.default_model backs requests that omit or misname
+// one, easing drop-in replacement of hosted APIs.
+
+// SetModelRouting configures alias resolution and the default-model
+// fallback. With strict set, unknown model names are rejected instead
+// of falling back.
+func (h *Handler) SetModelRouting(aliases map[string]string, defaultModel string, strict bool) {
+	h.aliases = aliases
+	h.defaultModel = defaultModel
+	h.strictModels = strict
+}
+
+// resolveModel maps a requested model name to a served one. The
+// returned bool is false only in strict mode when the name (after
+// alias expansion) is not a registered model.
+func (h *Handler) resolveModel(requested string) (string, bool) {
+	name := requested
+	if alias, ok := h.aliases[name]; ok {
+		name = alias
+	}
+
+	if name == "" {
+		return h.defaultModel, true
+	}
+	// An installed canary split rolls the dice between two versions
+	name = h.pickCanary(name)
+	if h.registry == nil || h.registry.HasModel(name) {
+		return name, true
+	}
+	if h.strictModels {
+		return "", false
+	}
+	return h.defaultModel, true
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		strict    bool
+		want      string
+		wantOK    bool
+	}{
+		{"registered name passes through", "gui-actor-2b", false, "gui-actor-2b", true},
+		{"alias maps to local model", "gpt-4o", false, "gui-actor-2b", true},
+		{"default alias", "default", false, "gui-actor-2b", true},
+		{"empty model uses default", "", false, "gui-actor-2b", true},
+		{"unknown falls back to default", "gpt-3.5-turbo", false, "gui-actor-2b", true},
+		{"unknown rejected in strict mode", "gpt-3.5-turbo", true, "", false},
+		{"alias still resolves in strict mode", "gpt-4o", true, "gui-actor-2b", true},
+		{"empty model still allowed in strict mode", "", true, "gui-actor-2b", true},
+	}
+
+	aliases := map[string]string{
+		"default": "gui-actor-2b",
+		"gpt-4o":  "gui-actor-2b",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newDashboardHandler(t)
+			h.SetModelRouting(aliases, "gui-actor-2b", tt.strict)
+
+			got, ok := h.resolveModel(tt.requested)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveModel(%q) = (%q, %v), want (%q, %v)",
+					tt.requested, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolveModelWithoutRegistry(t *testing.T) {
+	// Without a registry every name is assumed servable, so strict mode
+	// cannot reject anything
+	h := NewHandler(nil)
+	h.SetModelRouting(nil, "gui-actor-2b", true)
+
+	if got, ok := h.resolveModel("anything"); got != "anything" || !ok {
+		t.Errorf("resolveModel(anything) = (%q, %v), want (anything, true)", got, ok)
+	}
+}
+
+func TestChatCompletionResolvesAlias(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetModelRouting(map[string]string{"gpt-4o": "gui-actor-2b"}, "gui-actor-2b", false)
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: "user", Content: "click the button"}},
+	})
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+
+	if w.Code != 200 {
+		t.Fatalf("ChatCompletion status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Model != "gui-actor-2b" {
+		t.Errorf("response model = %q, want resolved name gui-actor-2b", resp.Model)
+	}
+}
+
+func TestChatCompletionStrictModelNotFound(t *testing.T) {
+	h := newDashboardHandler(t)
+	h.SetModelRouting(nil, "gui-actor-2b", true)
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "gpt-3.5-turbo",
+		Messages: []Message{{Role: "user", Content: "click the button"}},
+	})
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+
+	if w.Code != 404 {
+		t.Fatalf("ChatCompletion status = %d, want 404", w.Code)
+	}
+}
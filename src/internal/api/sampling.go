@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/agenthands/mlxvllm/internal/sampling"
+)
+
+// samplerFor builds the per-request sampler from the request
+// extensions; a nil sampler means the default pipeline.
+// TODO: thread the sampler into the engine decode loop
+func samplerFor(req *ChatCompletionRequest) (sampling.Sampler, error) {
+	if req.Mirostat == 0 {
+		return nil, nil
+	}
+	return sampling.NewMirostat(sampling.MirostatConfig{
+		Version: req.Mirostat,
+		Tau:     req.MirostatTau,
+		Eta:     req.MirostatEta,
+	})
+}
+
+// validateResponseFormat rejects unknown constrained-decoder types.
+// The "gui_action" DFA itself is built at decode time, where the
+// tokenizer vocabulary is available.
+func validateResponseFormat(req *ChatCompletionRequest) error {
+	if req.ResponseFormat == nil {
+		return nil
+	}
+	switch req.ResponseFormat.Type {
+	case "text", "gui_action":
+		return nil
+	}
+	return fmt.Errorf("unsupported response_format type %q", req.ResponseFormat.Type)
+}
+
+// processorsFor builds the logit processors the request asked for, in
+// the order they run each decode step
+func processorsFor(req *ChatCompletionRequest) ([]sampling.Processor, error) {
+	var procs []sampling.Processor
+	if req.NoRepeatNgramSize > 0 {
+		p, err := sampling.NewNoRepeatNGram(req.NoRepeatNgramSize)
+		if err != nil {
+			return nil, err
+		}
+		procs = append(procs, p)
+	}
+	if req.DRY {
+		p, err := sampling.NewDRY(sampling.DRYConfig{
+			Multiplier:    req.DRYMultiplier,
+			Base:          req.DRYBase,
+			AllowedLength: req.DRYAllowedLength,
+		})
+		if err != nil {
+			return nil, err
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
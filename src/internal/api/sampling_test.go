@@ -0,0 +1,39 @@
+package api
+
+import "testing"
+
+func TestInvalidMirostatRejected(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"mirostat":3,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 400 {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestMirostatAccepted(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"mirostat":2,"mirostat_tau":3,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestUnknownResponseFormatRejected(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"response_format":{"type":"json_schema"},"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 400 {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGUIActionResponseFormat(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"response_format":{"type":"gui_action"},"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+
+	"github.com/agenthands/mlxvllm/internal/trace"
 )
 
 type Server struct {
@@ -16,6 +18,7 @@ type Server struct {
 
 func NewServer(addr string, handler *Handler) *Server {
 	r := mux.NewRouter()
+	r.Use(trace.Middleware)
 	api := r.PathPrefix("/v1").Subrouter()
 
 	// Register routes
@@ -23,14 +26,38 @@ func NewServer(addr string, handler *Handler) *Server {
 	api.HandleFunc("/models", handler.ListModels).Methods("GET")
 	api.HandleFunc("/models/{id}", handler.GetModel).Methods("GET")
 	api.HandleFunc("/models/{id}/load", handler.LoadModel).Methods("POST")
+	api.HandleFunc("/models/{id}/events", handler.ModelEvents).Methods("GET")
 	api.HandleFunc("/models/{id}", handler.UnloadModel).Methods("DELETE")
 	api.HandleFunc("/chat/completions", handler.ChatCompletion).Methods("POST")
+	api.HandleFunc("/analyze", handler.Analyze).Methods("POST")
+	api.HandleFunc("/grounding/batch", handler.GroundingBatch).Methods("POST")
+	api.HandleFunc("/templates/validate", handler.ValidateTemplate).Methods("POST")
+	api.HandleFunc("/usage", handler.Usage).Methods("GET")
+	api.HandleFunc("/usage/quota", handler.GetQuota).Methods("GET")
+	api.HandleFunc("/usage/quota", handler.SetQuota).Methods("PUT")
+	api.HandleFunc("/audit", handler.Audit).Methods("GET")
+	api.HandleFunc("/admin/keys", handler.CreateKey).Methods("POST")
+	api.HandleFunc("/admin/keys", handler.ListKeys).Methods("GET")
+	api.HandleFunc("/admin/keys/{key}", handler.RevokeKey).Methods("DELETE")
+	api.HandleFunc("/admin/canary", handler.ListCanaries).Methods("GET")
+	api.HandleFunc("/admin/canary/{model}", handler.SetCanary).Methods("PUT")
+	api.HandleFunc("/admin/canary/{model}", handler.DeleteCanary).Methods("DELETE")
+	api.HandleFunc("/sessions", handler.CreateSession).Methods("POST")
+	api.HandleFunc("/sessions/{id}", handler.GetSession).Methods("GET")
+	api.HandleFunc("/sessions/{id}", handler.DeleteSession).Methods("DELETE")
+
+	// Admin status page and metrics, outside the /v1 API prefix
+	r.HandleFunc("/dashboard", handler.Dashboard).Methods("GET")
+	r.HandleFunc("/metrics", handler.Metrics).Methods("GET")
 
 	httpSrv := &http.Server{
-		Addr:         addr,
-		Handler:      r,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 60 * time.Second,
+		Addr:        addr,
+		Handler:     r,
+		ReadTimeout: 30 * time.Second,
+		// No blanket WriteTimeout: it would sever healthy long streams.
+		// Non-streaming requests get per-request deadlines from the
+		// handler and stream frames carry their own write deadlines.
+		WriteTimeout: 0,
 		IdleTimeout:  120 * time.Second,
 	}
 
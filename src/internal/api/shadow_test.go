@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/shadow"
+)
+
+// waitForComparisons polls the shadow log until n comparisons landed,
+// since shadow runs happen off the request goroutine
+func waitForComparisons(t *testing.T, l *shadow.Log, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if l.Stats().Comparisons >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("shadow log has %d comparisons, want %d", l.Stats().Comparisons, n)
+}
+
+func TestShadowComparisonRecorded(t *testing.T) {
+	h := NewHandler(nil)
+	l := shadow.NewLog(0)
+	h.SetShadow("gui-actor-2b-q4", l)
+
+	rec := postCompletion(t, h, `{"model":"gui-actor-2b","messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	waitForComparisons(t, l, 1)
+
+	cmp := l.Recent()[0]
+	if cmp.PrimaryModel != "gui-actor-2b" || cmp.ShadowModel != "gui-actor-2b-q4" {
+		t.Errorf("comparison models = %q vs %q", cmp.PrimaryModel, cmp.ShadowModel)
+	}
+	if cmp.PrimaryContent == "" || cmp.ShadowContent == "" {
+		t.Error("comparison is missing an output")
+	}
+	// Both sides run the same mock generation today, so divergence is 0
+	if cmp.CoordDistance != 0 || cmp.EditDistance != 0 {
+		t.Errorf("divergence = (%f, %d), want zero for identical mocks", cmp.CoordDistance, cmp.EditDistance)
+	}
+}
+
+func TestShadowSkipsSameModel(t *testing.T) {
+	h := NewHandler(nil)
+	l := shadow.NewLog(0)
+	h.SetShadow("gui-actor-2b", l)
+
+	postCompletion(t, h, `{"model":"gui-actor-2b","messages":[{"role":"user","content":"click save"}]}`)
+
+	// Give a stray goroutine a moment to (incorrectly) record
+	time.Sleep(10 * time.Millisecond)
+	if got := l.Stats().Comparisons; got != 0 {
+		t.Errorf("comparisons = %d, want 0 when shadow equals primary", got)
+	}
+}
+
+func TestShadowDisabledByDefault(t *testing.T) {
+	h := NewHandler(nil)
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d with no shadow configured", rec.Code)
+	}
+}
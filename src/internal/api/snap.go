@@ -0,0 +1,54 @@
+package api
+
+import (
+	"math"
+
+	"github.com/agenthands/mlxvllm/internal/ocr"
+)
+
+// SetSnapRadius enables coordinate snapping: predicted points within
+// radius (normalized distance) of an interactive element's center are
+// moved onto it. Zero disables snapping.
+func (h *Handler) SetSnapRadius(radius float64) {
+	h.snapRadius = radius
+}
+
+// snapCandidates collects the element centers a prediction may snap
+// to: client-supplied a11y elements first, then OCR text boxes
+func snapCandidates(req *ChatCompletionRequest, boxes []ocr.Box) []Point {
+	pts := make([]Point, 0, len(req.Elements)+len(boxes))
+	for _, e := range req.Elements {
+		pts = append(pts, Point{X: (e.X0 + e.X1) / 2, Y: (e.Y0 + e.Y1) / 2})
+	}
+	for _, b := range boxes {
+		pts = append(pts, Point{X: (b.X0 + b.X1) / 2, Y: (b.Y0 + b.Y1) / 2})
+	}
+	return pts
+}
+
+// applySnap moves p onto the nearest candidate center within the snap
+// radius and returns the original point, or nil if nothing was close
+// enough (or snapping is disabled). A prediction just off a small
+// button lands on it; one in empty space stays put.
+func (h *Handler) applySnap(req *ChatCompletionRequest, boxes []ocr.Box, p *Point) *Point {
+	if h.snapRadius <= 0 || p == nil {
+		return nil
+	}
+
+	bestDist := h.snapRadius
+	var best *Point
+	for _, c := range snapCandidates(req, boxes) {
+		c := c
+		if dist := math.Hypot(c.X-p.X, c.Y-p.Y); dist <= bestDist {
+			bestDist = dist
+			best = &c
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	raw := *p
+	p.X, p.Y = best.X, best.Y
+	return &raw
+}
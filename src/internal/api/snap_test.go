@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/ocr"
+)
+
+func TestApplySnapToNearestElement(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSnapRadius(0.1)
+
+	req := &ChatCompletionRequest{Elements: []Element{
+		{Name: "Save", X0: 0.50, Y0: 0.50, X1: 0.58, Y1: 0.56},
+		{Name: "Cancel", X0: 0.80, Y0: 0.50, X1: 0.88, Y1: 0.56},
+	}}
+
+	p := &Point{X: 0.5, Y: 0.5}
+	raw := h.applySnap(req, nil, p)
+	if raw == nil {
+		t.Fatal("prediction near an element was not snapped")
+	}
+	if raw.X != 0.5 || raw.Y != 0.5 {
+		t.Errorf("raw point = %+v, want the original prediction", raw)
+	}
+	if math.Abs(p.X-0.54) > 1e-9 || math.Abs(p.Y-0.53) > 1e-9 {
+		t.Errorf("snapped to (%f, %f), want the Save center (0.54, 0.53)", p.X, p.Y)
+	}
+}
+
+func TestApplySnapOutsideRadius(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSnapRadius(0.05)
+
+	req := &ChatCompletionRequest{Elements: []Element{
+		{X0: 0.8, Y0: 0.8, X1: 0.9, Y1: 0.9},
+	}}
+
+	p := &Point{X: 0.1, Y: 0.1}
+	if raw := h.applySnap(req, nil, p); raw != nil {
+		t.Errorf("snapped across the screen to %+v", p)
+	}
+	if p.X != 0.1 || p.Y != 0.1 {
+		t.Errorf("point moved to (%f, %f) without snapping", p.X, p.Y)
+	}
+}
+
+func TestApplySnapDisabled(t *testing.T) {
+	h := NewHandler(nil)
+
+	req := &ChatCompletionRequest{Elements: []Element{
+		{X0: 0.49, Y0: 0.49, X1: 0.51, Y1: 0.51},
+	}}
+	p := &Point{X: 0.5, Y: 0.5}
+	if raw := h.applySnap(req, nil, p); raw != nil {
+		t.Error("snapping ran with a zero radius")
+	}
+}
+
+func TestApplySnapUsesOCRBoxes(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSnapRadius(0.1)
+
+	boxes := []ocr.Box{{Text: "OK", X0: 0.46, Y0: 0.46, X1: 0.50, Y1: 0.50}}
+	p := &Point{X: 0.5, Y: 0.5}
+	raw := h.applySnap(&ChatCompletionRequest{}, boxes, p)
+	if raw == nil {
+		t.Fatal("OCR box center was not used as a snap target")
+	}
+	if math.Abs(p.X-0.48) > 1e-9 || math.Abs(p.Y-0.48) > 1e-9 {
+		t.Errorf("snapped to (%f, %f), want (0.48, 0.48)", p.X, p.Y)
+	}
+}
+
+func TestSnappedResponseCarriesBothPoints(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSnapRadius(0.1)
+
+	body := `{"elements":[{"name":"Save","x0":0.50,"y0":0.50,"x1":0.58,"y1":0.56}],
+		"messages":[{"role":"user","content":"click save"}]}`
+	rec := postCompletion(t, h, body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	choice := resp.Choices[0]
+	if choice.RawCoordinates == nil {
+		t.Fatal("response has no raw_coordinates")
+	}
+	if choice.RawCoordinates.X != 0.5 || choice.RawCoordinates.Y != 0.5 {
+		t.Errorf("raw_coordinates = %+v, want the mock prediction (0.5, 0.5)", choice.RawCoordinates)
+	}
+	if math.Abs(choice.Coordinates.X-0.54) > 1e-9 {
+		t.Errorf("coordinates = %+v, want the element center", choice.Coordinates)
+	}
+}
+
+func TestUnsnappedResponseOmitsRawPoint(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetSnapRadius(0.01)
+
+	body := `{"elements":[{"x0":0.9,"y0":0.9,"x1":0.95,"y1":0.95}],
+		"messages":[{"role":"user","content":"click save"}]}`
+	rec := postCompletion(t, h, body)
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if resp.Choices[0].RawCoordinates != nil {
+		t.Errorf("raw_coordinates = %+v, want omitted when nothing snapped", resp.Choices[0].RawCoordinates)
+	}
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMaxStreams caps concurrent SSE connections
+const DefaultMaxStreams = 32
+
+// DefaultStreamBuffer is the per-connection send buffer in events
+const DefaultStreamBuffer = 64
+
+// DefaultWriteDeadline bounds how long a single SSE frame may take to
+// reach the client
+const DefaultWriteDeadline = 10 * time.Second
+
+// ErrSlowClient is returned when a consumer falls behind the send
+// buffer and the stream is aborted
+var ErrSlowClient = errors.New("client too slow, stream aborted")
+
+// ErrTooManyStreams is returned when the global stream cap is reached
+var ErrTooManyStreams = errors.New("too many concurrent streams")
+
+// ErrStreamClosed is returned by Send once the stream has been closed
+var ErrStreamClosed = errors.New("stream closed")
+
+// StreamLimiter enforces a global cap on concurrent streaming
+// connections
+type StreamLimiter struct {
+	sem chan struct{}
+}
+
+// NewStreamLimiter creates a limiter for max concurrent streams
+func NewStreamLimiter(max int) *StreamLimiter {
+	if max <= 0 {
+		max = DefaultMaxStreams
+	}
+	return &StreamLimiter{sem: make(chan struct{}, max)}
+}
+
+// Acquire reserves a stream slot without blocking
+func (l *StreamLimiter) Acquire() error {
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	default:
+		return ErrTooManyStreams
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *StreamLimiter) Release() {
+	<-l.sem
+}
+
+// Active returns the number of streams currently held
+func (l *StreamLimiter) Active() int {
+	return len(l.sem)
+}
+
+// streamConn decouples generation from a possibly slow SSE consumer: a
+// pump goroutine drains a bounded buffer with a per-frame write
+// deadline, and producers that would overflow the buffer abort the
+// stream instead of blocking a generation slot.
+type streamConn struct {
+	frames    chan []byte
+	closing   chan struct{}
+	done      chan struct{}
+	aborted   chan struct{}
+	once      sync.Once
+	closeOnce sync.Once
+	errMu     sync.Mutex
+	writeErr  error
+}
+
+// newStreamConn starts the pump goroutine. deadline bounds each frame
+// write; a zero deadline disables it. The frames channel is never
+// closed — Close signals the pump through closing instead — so
+// concurrent producers such as the keep-alive pinger can race Close
+// without a send-on-closed-channel panic.
+func newStreamConn(w http.ResponseWriter, r *http.Request, buffer int, deadline time.Duration) *streamConn {
+	if buffer <= 0 {
+		buffer = DefaultStreamBuffer
+	}
+	c := &streamConn{
+		frames:  make(chan []byte, buffer),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		aborted: make(chan struct{}),
+	}
+
+	rc := http.NewResponseController(w)
+	flusher, _ := w.(http.Flusher)
+	go func() {
+		defer close(c.done)
+		write := func(frame []byte) {
+			if deadline > 0 {
+				rc.SetWriteDeadline(time.Now().Add(deadline))
+			}
+			if _, err := w.Write(frame); err != nil {
+				c.fail(err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		for {
+			select {
+			case frame := <-c.frames:
+				write(frame)
+			case <-c.closing:
+				// Flush whatever producers queued before Close won the race
+				for {
+					select {
+					case frame := <-c.frames:
+						write(frame)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// fail records the first write error and marks the stream aborted
+func (c *streamConn) fail(err error) {
+	c.once.Do(func() {
+		c.errMu.Lock()
+		c.writeErr = err
+		c.errMu.Unlock()
+		close(c.aborted)
+	})
+}
+
+// err returns the recorded write error, if any
+func (c *streamConn) err() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	return c.writeErr
+}
+
+// Send queues one frame. It returns ErrSlowClient when the buffer is
+// full, ErrStreamClosed after Close, and the recorded error once a
+// write has failed.
+func (c *streamConn) Send(frame []byte) error {
+	select {
+	case <-c.aborted:
+		return c.err()
+	case <-c.closing:
+		return ErrStreamClosed
+	default:
+	}
+
+	select {
+	case c.frames <- frame:
+		return nil
+	default:
+		c.fail(ErrSlowClient)
+		return ErrSlowClient
+	}
+}
+
+// SendEvent marshals and queues one SSE frame; event may be empty for
+// plain data frames
+func (c *streamConn) SendEvent(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if event != "" {
+		return c.Send([]byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data)))
+	}
+	return c.Send([]byte(fmt.Sprintf("data: %s\n\n", data)))
+}
+
+// SendDone queues the terminating [DONE] marker
+func (c *streamConn) SendDone() error {
+	return c.Send([]byte("data: [DONE]\n\n"))
+}
+
+// Close drains the buffer and waits for the pump to finish; closing
+// more than once is a no-op
+func (c *streamConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closing) })
+	<-c.done
+	return c.err()
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamLimiter(t *testing.T) {
+	l := NewStreamLimiter(2)
+
+	if err := l.Acquire(); err != nil {
+		t.Fatalf("First Acquire failed: %v", err)
+	}
+	if err := l.Acquire(); err != nil {
+		t.Fatalf("Second Acquire failed: %v", err)
+	}
+	if l.Active() != 2 {
+		t.Errorf("Active = %d, want 2", l.Active())
+	}
+
+	if err := l.Acquire(); !errors.Is(err, ErrTooManyStreams) {
+		t.Errorf("Third Acquire = %v, want ErrTooManyStreams", err)
+	}
+
+	l.Release()
+	if err := l.Acquire(); err != nil {
+		t.Errorf("Acquire after Release failed: %v", err)
+	}
+}
+
+func TestStreamLimiterDefault(t *testing.T) {
+	l := NewStreamLimiter(0)
+	for i := 0; i < DefaultMaxStreams; i++ {
+		if err := l.Acquire(); err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+	}
+	if err := l.Acquire(); err == nil {
+		t.Error("Expected cap at DefaultMaxStreams")
+	}
+}
+
+func TestStreamConnDeliversFrames(t *testing.T) {
+	rec := httptest.NewRecorder()
+	conn := newStreamConn(rec, httptest.NewRequest("POST", "/", nil), 4, 0)
+
+	if err := conn.SendEvent("grounding", map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+	if err := conn.SendDone(); err != nil {
+		t.Fatalf("SendDone failed: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: grounding\n") {
+		t.Errorf("Missing event frame: %q", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("Missing [DONE]: %q", body)
+	}
+}
+
+func TestStreamConnSendAfterClose(t *testing.T) {
+	rec := httptest.NewRecorder()
+	conn := newStreamConn(rec, httptest.NewRequest("POST", "/", nil), 4, 0)
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := conn.Send([]byte("data: x\n\n")); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Send after Close = %v, want ErrStreamClosed", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Errorf("Second Close returned error: %v", err)
+	}
+}
+
+func TestStreamConnConcurrentSendAndClose(t *testing.T) {
+	rec := httptest.NewRecorder()
+	conn := newStreamConn(rec, httptest.NewRequest("POST", "/", nil), 4, 0)
+
+	// A producer hammering Send while Close runs must get an error back,
+	// never a panic; run under -race this also covers the frame channel
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if err := conn.Send([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Millisecond)
+	conn.Close()
+	<-done
+}
+
+// stallWriter blocks writes until released, simulating a stalled client
+type stallWriter struct {
+	header  http.Header
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func newStallWriter() *stallWriter {
+	return &stallWriter{header: http.Header{}, release: make(chan struct{})}
+}
+
+func (s *stallWriter) Header() http.Header { return s.header }
+func (s *stallWriter) WriteHeader(int)     {}
+func (s *stallWriter) Write(p []byte) (int, error) {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func TestStreamConnAbortsSlowClient(t *testing.T) {
+	w := newStallWriter()
+	defer close(w.release)
+
+	conn := newStreamConn(w, httptest.NewRequest("POST", "/", nil), 2, 0)
+
+	// The pump takes one frame and blocks in Write; two more fill the
+	// buffer, so subsequent sends must fail fast rather than block
+	deadline := time.After(5 * time.Second)
+	var err error
+	for i := 0; i < 10; i++ {
+		select {
+		case <-deadline:
+			t.Fatal("Send blocked on a slow client")
+		default:
+		}
+		if err = conn.Send([]byte("data: x\n\n")); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrSlowClient) {
+		t.Fatalf("Expected ErrSlowClient, got: %v", err)
+	}
+
+	// Later sends keep failing
+	if err := conn.Send([]byte("data: y\n\n")); !errors.Is(err, ErrSlowClient) {
+		t.Errorf("Expected sticky ErrSlowClient, got: %v", err)
+	}
+}
+
+func TestStreamConcurrencyCap(t *testing.T) {
+	h := NewHandler(nil)
+	h.streams = NewStreamLimiter(1)
+
+	// Hold the only slot
+	if err := h.streams.Acquire(); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer h.streams.Release()
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model:    "m",
+		Stream:   true,
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ChatCompletion(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Status = %d, want 429", rec.Code)
+	}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("Error body not JSON: %v", err)
+	}
+	if !strings.Contains(errResp.Error.Message, "too many concurrent streams") {
+		t.Errorf("Unexpected error message: %q", errResp.Error.Message)
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/agenthands/mlxvllm/internal/session"
+)
+
+// SetSessionSummarizer enables history summarization for server-managed
+// sessions: once a session's estimated size passes thresholdTokens,
+// older turns collapse into a generated summary so the prompt stays
+// inside the context window. Zero disables it.
+func (h *Handler) SetSessionSummarizer(thresholdTokens int) {
+	h.sessions.SetSummarizer(summarizeTurns, thresholdTokens)
+}
+
+// summarizeTurns condenses older session turns into a short text.
+// TODO: run the session's own model over the turns once inference
+// lands; until then the summary is a structural digest
+func summarizeTurns(model string, turns []session.Message) (string, error) {
+	var firstTask string
+	actions := 0
+	for _, t := range turns {
+		text, ok := t.Content.(string)
+		if !ok {
+			continue
+		}
+		switch t.Role {
+		case "user":
+			if firstTask == "" {
+				firstTask = text
+			}
+		case "assistant":
+			actions++
+		}
+	}
+
+	if firstTask == "" {
+		return fmt.Sprintf("%d earlier messages were exchanged.", len(turns)), nil
+	}
+	return fmt.Sprintf("The user asked: %q. The assistant performed %d actions toward it.", firstTask, actions), nil
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/session"
+)
+
+func TestSummarizeTurnsDigest(t *testing.T) {
+	turns := []session.Message{
+		{Role: "user", Content: "open the settings dialog"},
+		{Role: "assistant", Content: "pyautogui.click(0.9, 0.1)"},
+		{Role: "assistant", Content: "pyautogui.click(0.5, 0.5)"},
+	}
+	got, err := summarizeTurns("gui-actor-2b", turns)
+	if err != nil {
+		t.Fatalf("summarizeTurns failed: %v", err)
+	}
+	if !strings.Contains(got, "open the settings dialog") {
+		t.Errorf("summary %q lost the original task", got)
+	}
+	if !strings.Contains(got, "2 actions") {
+		t.Errorf("summary %q does not count the actions", got)
+	}
+}
+
+func TestSummarizeTurnsWithoutUserTask(t *testing.T) {
+	turns := []session.Message{
+		{Role: "assistant", Content: "pyautogui.click(0.5, 0.5)"},
+	}
+	got, err := summarizeTurns("gui-actor-2b", turns)
+	if err != nil || got == "" {
+		t.Errorf("summarizeTurns = (%q, %v), want a non-empty digest", got, err)
+	}
+}
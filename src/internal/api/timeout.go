@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Generation-aware timeouts. The blunt http.Server WriteTimeout cannot
+// distinguish a stuck generation from a long healthy stream, so
+// non-streaming requests get a per-request deadline covering queueing
+// and generation, while streams stay alive via periodic SSE comments.
+
+// DefaultKeepAliveInterval spaces keep-alive comments on streams
+const DefaultKeepAliveInterval = 15 * time.Second
+
+// SetRequestTimeout bounds non-streaming completions; zero disables.
+// keepAlive sets the SSE comment interval for streams (zero applies
+// the default).
+func (h *Handler) SetRequestTimeout(request, keepAlive time.Duration) {
+	h.reqTimeout = request
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAliveInterval
+	}
+	h.keepAlive = keepAlive
+}
+
+// Timeouts returns how many requests have hit the deadline, for
+// /metrics
+func (h *Handler) Timeouts() int64 {
+	return atomic.LoadInt64(&h.timeoutsHit)
+}
+
+// writeTimeoutError answers a request whose deadline elapsed with a
+// structured error clients can distinguish from validation failures
+func (h *Handler) writeTimeoutError(w http.ResponseWriter, timeout time.Duration) {
+	atomic.AddInt64(&h.timeoutsHit, 1)
+	w.Header().Set("X-Timeout-Seconds", strconv.Itoa(int(timeout.Seconds())))
+	writeJSON(w, http.StatusGatewayTimeout, ErrorResponse{
+		Error: ErrorDetail{
+			Message: "request exceeded the generation deadline",
+			Type:    "timeout_error",
+			Code:    "request_timeout",
+		},
+	})
+}
+
+// keepAliveLoop emits SSE comment frames until stopped, so proxies and
+// clients do not sever an idle-looking stream mid-generation
+func keepAliveLoop(conn *streamConn, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.Send([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/scheduler"
+)
+
+func postCompletion(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.ChatCompletion(rec, req)
+	return rec
+}
+
+func TestRequestTimeoutWhileQueued(t *testing.T) {
+	h := NewHandler(nil)
+	sched := scheduler.New(scheduler.Config{Slots: 1})
+	h.SetScheduler(sched)
+	h.SetRequestTimeout(10*time.Millisecond, 0)
+
+	// Occupy the only slot so the request stays queued past its deadline
+	if err := sched.Acquire(context.Background(), "occupant"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer sched.Release()
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 504 {
+		t.Fatalf("status = %d, want 504", rec.Code)
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid error body: %v", err)
+	}
+	if resp.Error.Type != "timeout_error" || resp.Error.Code != "request_timeout" {
+		t.Errorf("error = %+v, want timeout_error/request_timeout", resp.Error)
+	}
+	if got := rec.Header().Get("X-Timeout-Seconds"); got != "0" {
+		t.Errorf("X-Timeout-Seconds = %q, want \"0\"", got)
+	}
+	if got := h.Timeouts(); got != 1 {
+		t.Errorf("Timeouts = %d, want 1", got)
+	}
+}
+
+func TestNoTimeoutConfigured(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := h.Timeouts(); got != 0 {
+		t.Errorf("Timeouts = %d, want 0", got)
+	}
+}
+
+func TestStreamingNotSubjectToDeadline(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetRequestTimeout(time.Nanosecond, time.Hour)
+
+	rec := postCompletion(t, h, `{"stream":true,"messages":[{"role":"user","content":"click save"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "data: [DONE]") {
+		t.Error("stream did not complete")
+	}
+}
+
+func TestKeepAliveLoop(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/chat/completions", nil)
+	conn := newStreamConn(rec, req, DefaultStreamBuffer, DefaultWriteDeadline)
+
+	stop := make(chan struct{})
+	go keepAliveLoop(conn, 5*time.Millisecond, stop)
+	time.Sleep(25 * time.Millisecond)
+	close(stop)
+	conn.Close()
+
+	if got := strings.Count(rec.Body.String(), ": keep-alive"); got < 2 {
+		t.Errorf("saw %d keep-alive comments, want >= 2", got)
+	}
+}
+
+func TestSetRequestTimeoutDefaultsKeepAlive(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetRequestTimeout(time.Minute, 0)
+	if h.keepAlive != DefaultKeepAliveInterval {
+		t.Errorf("keepAlive = %v, want %v", h.keepAlive, DefaultKeepAliveInterval)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+// Multi-action responses. When a request opts into parallel tool
+// calls, one turn may carry several ordered actions instead of a
+// single pyautogui line, each with its own grounded coordinates.
+
+// SetActionCap bounds how many actions one response may carry; zero
+// leaves the count uncapped
+func (h *Handler) SetActionCap(n int) {
+	h.maxActions = n
+}
+
+// mockToolCalls fabricates a two-action step (type, then press enter)
+// until real inference emits action sequences.
+// TODO: build from the decoded action sequence and pointer head output
+func mockToolCalls(id string) []ToolCall {
+	return []ToolCall{
+		{
+			Index:       0,
+			ID:          id + "-0",
+			Type:        "function",
+			Function:    FunctionCall{Name: "type", Arguments: `{"text":"hello"}`},
+			Coordinates: &Point{X: 0.5, Y: 0.5},
+		},
+		{
+			Index:    1,
+			ID:       id + "-1",
+			Type:     "function",
+			Function: FunctionCall{Name: "press", Arguments: `{"key":"enter"}`},
+		},
+	}
+}
+
+// capToolCalls truncates an action sequence to the configured cap,
+// keeping execution order
+func (h *Handler) capToolCalls(calls []ToolCall) []ToolCall {
+	if h.maxActions > 0 && len(calls) > h.maxActions {
+		return calls[:h.maxActions]
+	}
+	return calls
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParallelToolCalls(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"parallel_tool_calls":true,"messages":[{"role":"user","content":"type hello and press enter"}]}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("finish_reason = %q, want \"tool_calls\"", choice.FinishReason)
+	}
+	calls := choice.Message.ToolCalls
+	if len(calls) < 2 {
+		t.Fatalf("got %d tool calls, want at least 2", len(calls))
+	}
+	for i, call := range calls {
+		if call.Index != i {
+			t.Errorf("call %d has index %d, want ordered indices", i, call.Index)
+		}
+		if call.Type != "function" || call.Function.Name == "" {
+			t.Errorf("call %d = %+v, want a named function call", i, call)
+		}
+	}
+	if calls[0].Coordinates == nil {
+		t.Error("grounded action missing coordinates")
+	}
+}
+
+func TestActionCapTruncates(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetActionCap(1)
+
+	rec := postCompletion(t, h, `{"parallel_tool_calls":true,"messages":[{"role":"user","content":"type hello and press enter"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if got := len(resp.Choices[0].Message.ToolCalls); got != 1 {
+		t.Errorf("got %d tool calls, want cap of 1", got)
+	}
+}
+
+func TestSingleActionByDefault(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[{"role":"user","content":"click save"}]}`)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(resp.Choices[0].Message.ToolCalls) != 0 {
+		t.Error("tool calls present without parallel_tool_calls")
+	}
+}
+
+func TestToolResultMessageAccepted(t *testing.T) {
+	h := NewHandler(nil)
+
+	rec := postCompletion(t, h, `{"messages":[
+		{"role":"user","content":"click save"},
+		{"role":"assistant","content":"pyautogui.click(0.5, 0.5)"},
+		{"role":"tool","tool_call_id":"call_1","content":[
+			{"type":"text","text":"action executed"},
+			{"type":"image_url","image_url":{"url":"data:image/png;base64,aGk="}}
+		]},
+		{"role":"user","content":"now click cancel"}
+	]}`)
+	if rec.Code != 200 {
+		t.Fatalf("tool-result request status = %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestToolCallIDRoundTripsThroughSession(t *testing.T) {
+	h := NewHandler(nil)
+	s := h.sessions.Create("gui-actor-2b")
+
+	history, err := h.appendToSession(s.ID, []Message{
+		{Role: "assistant", Content: "pyautogui.click(0.5, 0.5)"},
+		{Role: "tool", ToolCallID: "call_1", Content: "action executed"},
+	})
+	if err != nil {
+		t.Fatalf("appendToSession: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history length = %d, want 2", len(history))
+	}
+	if history[1].Role != "tool" || history[1].ToolCallID != "call_1" {
+		t.Errorf("tool message = %+v, lost role or tool_call_id", history[1])
+	}
+}
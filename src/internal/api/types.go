@@ -1,6 +1,13 @@
 package api
 
-import "time"
+import (
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/audit"
+	"github.com/agenthands/mlxvllm/internal/model"
+	"github.com/agenthands/mlxvllm/internal/shadow"
+	"github.com/agenthands/mlxvllm/internal/slo"
+)
 
 // ChatCompletionRequest represents an OpenAI-compatible request
 type ChatCompletionRequest struct {
@@ -14,12 +21,106 @@ type ChatCompletionRequest struct {
 	Temperature *float64  `json:"temperature,omitempty"`
 	TopP        *float64  `json:"top_p,omitempty"`
 	MaxTokens   *int      `json:"max_tokens,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Preset      string    `json:"preset,omitempty"`
+	// RedactRegions are masked out of attached screenshots before they
+	// reach the vision encoder, in addition to any statically configured
+	// regions
+	RedactRegions []RedactRegion `json:"redact_regions,omitempty"`
+	// Debug requests a per-step token trace in the response; restricted
+	// to admin keys when any are configured
+	Debug bool `json:"debug,omitempty"`
+	// Mirostat selects adaptive sampling: 0 disables (the default),
+	// 1 and 2 pick the algorithm version; tau and eta tune the target
+	// surprise and learning rate, zero meaning the built-in defaults
+	Mirostat    int     `json:"mirostat,omitempty"`
+	MirostatTau float64 `json:"mirostat_tau,omitempty"`
+	MirostatEta float64 `json:"mirostat_eta,omitempty"`
+	// NoRepeatNgramSize bans n-grams from repeating verbatim; DRY
+	// enables the soft repetition suppressor, its knobs defaulting when
+	// zero
+	NoRepeatNgramSize int     `json:"no_repeat_ngram_size,omitempty"`
+	DRY               bool    `json:"dry,omitempty"`
+	DRYMultiplier     float64 `json:"dry_multiplier,omitempty"`
+	DRYBase           float64 `json:"dry_base,omitempty"`
+	DRYAllowedLength  int     `json:"dry_allowed_length,omitempty"`
+	// ResponseFormat constrains decoding; "gui_action" forces output
+	// matching the pyautogui action grammar
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	// ParallelToolCalls lets one response carry several ordered actions
+	// (e.g. type text, then press enter) as tool_calls
+	ParallelToolCalls bool `json:"parallel_tool_calls,omitempty"`
+	// DryRun returns the prompt token accounting (template overhead,
+	// text, image resize plan) without running the model
+	DryRun bool `json:"dry_run,omitempty"`
+	// Elements are interactive UI elements (typically from the platform
+	// a11y tree) that predicted coordinates may snap to
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Element is one interactive UI element rect in normalized [0,1]
+// screen coordinates
+type Element struct {
+	Role string  `json:"role,omitempty"`
+	Name string  `json:"name,omitempty"`
+	X0   float64 `json:"x0"`
+	Y0   float64 `json:"y0"`
+	X1   float64 `json:"x1"`
+	Y1   float64 `json:"y1"`
+}
+
+// ResponseFormat selects a constrained decoder; "text" (or omitting
+// the field) leaves decoding unconstrained
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// RedactRegion is a normalized screen rectangle to mask in attached
+// screenshots; blur pixelates instead of blacking out
+type RedactRegion struct {
+	X0   float64 `json:"x0"`
+	Y0   float64 `json:"y0"`
+	X1   float64 `json:"x1"`
+	Y1   float64 `json:"y1"`
+	Blur bool    `json:"blur,omitempty"`
 }
 
 // Message represents a chat message
 type Message struct {
 	Role    string      `json:"role"`
 	Content interface{} `json:"content"` // string or []ContentPart
+	// ToolCalls carries the ordered actions of a multi-action step;
+	// indices give the execution order
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID links a role:"tool" result message (often carrying a
+	// post-action screenshot) back to the call it answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// CacheControl pins the prompt prefix up to and including this
+	// message beyond normal LRU lifetime
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// CacheControl marks a message as a caching boundary; "ephemeral" pins
+// survive bursts, "persistent" pins are exempt from eviction and count
+// against the server budget
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// ToolCall is one action in a multi-action response. Coordinates are
+// set for actions grounded to a screen position.
+type ToolCall struct {
+	Index       int          `json:"index"`
+	ID          string       `json:"id"`
+	Type        string       `json:"type"` // always "function"
+	Function    FunctionCall `json:"function"`
+	Coordinates *Point       `json:"coordinates,omitempty"`
+}
+
+// FunctionCall names the action and its JSON-encoded arguments
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // ContentPart represents a multipart content (text + image)
@@ -42,6 +143,8 @@ type ChatCompletionResponse struct {
 	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage,omitempty"`
+	// Debug carries the token-level trace when the request asked for one
+	Debug *DebugTrace `json:"debug,omitempty"`
 }
 
 // Choice represents a completion choice
@@ -51,6 +154,26 @@ type Choice struct {
 	FinishReason string   `json:"finish_reason"`
 	Delta        *Message `json:"delta,omitempty"`       // For streaming
 	Coordinates  *Point   `json:"coordinates,omitempty"` // GUI-Actor specific
+	// RawCoordinates preserves the model's unsnapped prediction when
+	// Coordinates was snapped onto a nearby element
+	RawCoordinates *Point `json:"raw_coordinates,omitempty"`
+	// XGUIActor namespaces the fields above under an extension object;
+	// in strict compat mode it is the only place they appear
+	XGUIActor *GUIActorExtension `json:"x_gui_actor,omitempty"`
+}
+
+// ExtensionSchemaVersion is the current x_gui_actor schema version.
+// Bump it when the extension fields change shape so clients can
+// feature-detect against one number instead of sniffing keys.
+const ExtensionSchemaVersion = 1
+
+// GUIActorExtension gathers the GUI-Actor-specific response fields
+// under one namespaced object. The top-level duplicates on Choice stay
+// for existing clients unless strict compat mode drops them.
+type GUIActorExtension struct {
+	SchemaVersion  int    `json:"schema_version"`
+	Coordinates    *Point `json:"coordinates,omitempty"`
+	RawCoordinates *Point `json:"raw_coordinates,omitempty"`
 }
 
 // Point represents normalized coordinates [0, 1]
@@ -64,6 +187,19 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	// PromptTokensDetails splits the prompt between text and image
+	// tokens so controllers know which side to shrink first
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+	// ContextRemaining is the model context left after this turn, in
+	// tokens; controllers summarize history or downscale screenshots
+	// when it runs low
+	ContextRemaining int `json:"context_remaining,omitempty"`
+}
+
+// PromptTokensDetails breaks the prompt token count down by modality
+type PromptTokensDetails struct {
+	TextTokens  int `json:"text_tokens"`
+	ImageTokens int `json:"image_tokens"`
 }
 
 // ModelInfo represents model status
@@ -72,6 +208,14 @@ type ModelInfo struct {
 	Object   string  `json:"object"`
 	Loaded   bool    `json:"loaded"`
 	MemoryGB float64 `json:"memory_gb,omitempty"`
+	// Loading/LoadPhase/LoadProgress surface an in-flight load
+	// (progress in [0,1])
+	Loading      bool    `json:"loading,omitempty"`
+	LoadPhase    string  `json:"load_phase,omitempty"`
+	LoadProgress float64 `json:"load_progress,omitempty"`
+	// Offload reports per-layer weight residency for models running
+	// with layer offload configured
+	Offload *model.Residency `json:"offload,omitempty"`
 }
 
 // ModelsResponse lists available models
@@ -88,6 +232,142 @@ type HealthResponse struct {
 	Models   int     `json:"loaded_models"`
 }
 
+// MetricsResponse is the /metrics payload for operator scraping
+type MetricsResponse struct {
+	UptimeSeconds    int64 `json:"uptime_seconds"`
+	ActiveStreams    int   `json:"active_streams"`
+	LoadedModels     int   `json:"loaded_models"`
+	QueuedRequests   int   `json:"queued_requests"`
+	InFlightRequests int   `json:"in_flight_requests"`
+	RequestTimeouts  int64 `json:"request_timeouts"`
+	// ShedRequests counts arrivals rejected by load shedding
+	ShedRequests int64       `json:"shed_requests"`
+	SLO          *slo.Status `json:"slo,omitempty"`
+	// Shadow carries A/B divergence aggregates when shadow traffic is on
+	Shadow *shadow.Stats `json:"shadow,omitempty"`
+}
+
+// SessionRequest creates a server-side conversation session
+type SessionRequest struct {
+	Model string `json:"model,omitempty"`
+}
+
+// SessionResponse describes a server-side session
+type SessionResponse struct {
+	ID       string `json:"id"`
+	Object   string `json:"object"`
+	Model    string `json:"model,omitempty"`
+	Created  int64  `json:"created"`
+	Messages int    `json:"messages,omitempty"`
+}
+
+// GroundingEvent is streamed as a custom "grounding" SSE event before
+// the text rationale, carrying the pointer head's prediction
+type GroundingEvent struct {
+	Object      string `json:"object"`
+	Created     int64  `json:"created"`
+	Model       string `json:"model"`
+	Coordinates *Point `json:"coordinates"`
+	// RawCoordinates is set when Coordinates was snapped onto an element
+	RawCoordinates *Point `json:"raw_coordinates,omitempty"`
+	// XGUIActor mirrors the coordinates under the versioned extension
+	// namespace so streaming clients feature-detect the same way
+	XGUIActor *GUIActorExtension `json:"x_gui_actor,omitempty"`
+}
+
+// NewGroundingEvent creates a grounding event for streaming
+func NewGroundingEvent(model string, point *Point) *GroundingEvent {
+	return &GroundingEvent{
+		Object:      "chat.grounding",
+		Created:     time.Now().Unix(),
+		Model:       model,
+		Coordinates: point,
+	}
+}
+
+// QuotaRequest adjusts a key's token quota (zero means unlimited)
+type QuotaRequest struct {
+	APIKey        string `json:"api_key"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+}
+
+// QuotaResponse describes the effective quota for a key
+type QuotaResponse struct {
+	APIKey        string `json:"api_key"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+	Source        string `json:"source"` // "default" or "override"
+	Admin         bool   `json:"admin"`
+}
+
+// TemplateValidateRequest renders a sample instruction through a
+// preset; an empty instruction uses a stand-in
+type TemplateValidateRequest struct {
+	Preset      string `json:"preset"`
+	Instruction string `json:"instruction,omitempty"`
+}
+
+// TemplateValidateResponse is the messages the preset would produce
+type TemplateValidateResponse struct {
+	Preset   string    `json:"preset"`
+	Messages []Message `json:"messages"`
+}
+
+// CanaryRequest installs a traffic split for a model name
+type CanaryRequest struct {
+	Stable  string  `json:"stable"`
+	Canary  string  `json:"canary"`
+	Percent float64 `json:"percent"` // 0..100 share sent to the canary
+}
+
+// CanaryStatus describes an active traffic split and how many
+// requests each version has served since it was installed
+type CanaryStatus struct {
+	Model          string  `json:"model"`
+	Stable         string  `json:"stable"`
+	Canary         string  `json:"canary"`
+	Percent        float64 `json:"percent"`
+	StableRequests int64   `json:"stable_requests"`
+	CanaryRequests int64   `json:"canary_requests"`
+}
+
+// CanaryListResponse lists active canary routes
+type CanaryListResponse struct {
+	Object string         `json:"object"`
+	Data   []CanaryStatus `json:"data"`
+}
+
+// KeyRequest creates a managed API key
+type KeyRequest struct {
+	Name  string `json:"name"`
+	Admin bool   `json:"admin,omitempty"`
+}
+
+// KeyInfo describes a managed API key and its lifetime usage
+type KeyInfo struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Admin       bool   `json:"admin"`
+	Created     int64  `json:"created"`
+	Revoked     bool   `json:"revoked,omitempty"`
+	RevokedAt   int64  `json:"revoked_at,omitempty"`
+	Requests    int64  `json:"requests"`
+	TotalTokens int64  `json:"total_tokens"`
+}
+
+// KeysResponse lists managed API keys
+type KeysResponse struct {
+	Object string    `json:"object"`
+	Data   []KeyInfo `json:"data"`
+}
+
+// AuditResponse lists recent audited admin operations
+type AuditResponse struct {
+	Object  string        `json:"object"`
+	Entries []audit.Entry `json:"entries"`
+}
+
 // ErrorResponse represents an error
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+// DefaultUsageDays is the query window when none is given
+const DefaultUsageDays = 7
+
+// anonymousKey buckets unauthenticated requests; shared lab servers
+// often run without auth, so metering still needs somewhere to land
+const anonymousKey = "anonymous"
+
+// SetUsageStore enables per-API-key usage metering
+func (h *Handler) SetUsageStore(s *usage.Store) {
+	h.usage = s
+}
+
+// apiKeyFrom extracts the caller's API key from the Authorization
+// bearer token or X-API-Key header
+func apiKeyFrom(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok && token != "" {
+			return token
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return anonymousKey
+}
+
+// meter records one completed request against the caller's key
+func (h *Handler) meter(r *http.Request, req *ChatCompletionRequest, assistant *Message, start time.Time) {
+	if h.usage == nil {
+		return
+	}
+
+	sample := usage.Day{
+		Requests:         1,
+		PromptTokens:     int64(estimateMessageTokens(req.Messages)),
+		CompletionTokens: int64(estimateMessageTokens([]Message{*assistant})),
+		GPUSeconds:       time.Since(start).Seconds(),
+	}
+	if err := h.usage.Record(apiKeyFrom(r), start, sample); err != nil {
+		log.Printf("usage metering failed: %v", err)
+	}
+}
+
+// estimateMessageTokens approximates token counts from text length
+// (~4 chars/token); real counts need the model tokenizer, which the
+// inference pipeline does not expose yet
+func estimateMessageTokens(messages []Message) int {
+	chars := 0
+	for _, msg := range messages {
+		switch content := msg.Content.(type) {
+		case string:
+			chars += len(content)
+		case []interface{}:
+			for _, part := range content {
+				m, ok := part.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if text, ok := m["text"].(string); ok {
+					chars += len(text)
+				}
+			}
+		}
+	}
+	return (chars + 3) / 4
+}
+
+// Usage handles GET /v1/usage: daily rollups for an API key. Without an
+// api_key parameter it reports on the caller's own key.
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage metering not enabled")
+		return
+	}
+
+	key := r.URL.Query().Get("api_key")
+	if key == "" {
+		key = apiKeyFrom(r)
+	}
+
+	days := DefaultUsageDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	report, err := h.usage.Query(key, now.AddDate(0, 0, -(days-1)), now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/usage"
+)
+
+func newUsageHandler(t *testing.T) *Handler {
+	t.Helper()
+	store, err := usage.Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("Failed to open usage store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	h := NewHandler(nil)
+	h.SetUsageStore(store)
+	return h
+}
+
+func TestAPIKeyFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		expect string
+	}{
+		{"bearer token", "Authorization", "Bearer sk-lab-1", "sk-lab-1"},
+		{"api key header", "X-API-Key", "sk-lab-2", "sk-lab-2"},
+		{"empty bearer", "Authorization", "Bearer ", anonymousKey},
+		{"no auth", "", "", anonymousKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			if got := apiKeyFrom(req); got != tt.expect {
+				t.Errorf("apiKeyFrom = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestEstimateMessageTokens(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "12345678"}, // 8 chars -> 2 tokens
+		{Role: "user", Content: []interface{}{
+			map[string]interface{}{"type": "text", "text": "abcd"}, // 4 chars -> 1 token
+		}},
+	}
+	if got := estimateMessageTokens(messages); got != 3 {
+		t.Errorf("estimateMessageTokens = %d, want 3", got)
+	}
+}
+
+func TestUsageMeteredOnCompletion(t *testing.T) {
+	h := newUsageHandler(t)
+
+	body, _ := json.Marshal(ChatCompletionRequest{
+		Model: "gui-actor-2b",
+		Messages: []Message{
+			{Role: "user", Content: "Click the button"},
+		},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-alice")
+	w := httptest.NewRecorder()
+	h.ChatCompletion(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("ChatCompletion status = %d", w.Code)
+	}
+
+	// Query the rollup back through the endpoint
+	usageReq := httptest.NewRequest("GET", "/v1/usage", nil)
+	usageReq.Header.Set("Authorization", "Bearer sk-alice")
+	usageW := httptest.NewRecorder()
+	h.Usage(usageW, usageReq)
+
+	if usageW.Code != 200 {
+		t.Fatalf("Usage status = %d: %s", usageW.Code, usageW.Body.String())
+	}
+
+	var report usage.Report
+	if err := json.Unmarshal(usageW.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if report.APIKey != "sk-alice" {
+		t.Errorf("APIKey = %q, want sk-alice", report.APIKey)
+	}
+	if report.Totals.Requests != 1 {
+		t.Errorf("Requests = %d, want 1", report.Totals.Requests)
+	}
+	if report.Totals.PromptTokens == 0 || report.Totals.CompletionTokens == 0 {
+		t.Errorf("Token totals = %+v, want non-zero", report.Totals)
+	}
+}
+
+func TestUsageQueryOtherKey(t *testing.T) {
+	h := newUsageHandler(t)
+
+	req := httptest.NewRequest("GET", "/v1/usage?api_key=sk-bob&days=30", nil)
+	w := httptest.NewRecorder()
+	h.Usage(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("Usage status = %d", w.Code)
+	}
+	var report usage.Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if report.APIKey != "sk-bob" {
+		t.Errorf("APIKey = %q, want sk-bob", report.APIKey)
+	}
+}
+
+func TestUsageBadDays(t *testing.T) {
+	h := newUsageHandler(t)
+
+	for _, days := range []string{"0", "-1", "abc"} {
+		req := httptest.NewRequest("GET", "/v1/usage?days="+days, nil)
+		w := httptest.NewRecorder()
+		h.Usage(w, req)
+		if w.Code != 400 {
+			t.Errorf("days=%s status = %d, want 400", days, w.Code)
+		}
+	}
+}
+
+func TestUsageDisabled(t *testing.T) {
+	h := NewHandler(nil)
+
+	req := httptest.NewRequest("GET", "/v1/usage", nil)
+	w := httptest.NewRecorder()
+	h.Usage(w, req)
+
+	if w.Code != 503 {
+		t.Errorf("Usage status = %d, want 503 when metering disabled", w.Code)
+	}
+}
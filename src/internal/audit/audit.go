@@ -0,0 +1,104 @@
+// Package audit appends admin operations to an append-only JSONL file
+// with actor identity and timestamps, so changes to a shared server
+// (model loads, quota edits) can be traced back to who made them.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is one audited admin operation
+type Entry struct {
+	Timestamp int64  `json:"timestamp"`
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	Target    string `json:"target,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Log writes entries to an append-only file; safe for concurrent use
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+}
+
+// Open opens (or creates) the audit log at path in append-only mode
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Log{file: f, path: path}, nil
+}
+
+// Close releases the underlying file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Append writes one entry, filling in the timestamp when unset
+func (l *Log) Append(e Entry) error {
+	if e.Timestamp == 0 {
+		e.Timestamp = time.Now().Unix()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Tail returns the most recent n entries, oldest first
+func (l *Log) Tail(n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			// Skip torn or corrupt lines rather than failing the query
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
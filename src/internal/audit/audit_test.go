@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestLog(t *testing.T) *Log {
+	t.Helper()
+	l, err := Open(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestAppendAndTail(t *testing.T) {
+	l := newTestLog(t)
+
+	entries := []Entry{
+		{Actor: "sk-admin", Action: "model.load", Target: "gui-actor-2b"},
+		{Actor: "sk-admin", Action: "quota.set", Target: "sk-lab-1", Detail: "daily=100 monthly=0"},
+		{Actor: "sk-admin", Action: "model.unload", Target: "gui-actor-2b"},
+	}
+	for _, e := range entries {
+		if err := l.Append(e); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	got, err := l.Tail(10)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(got) != len(entries) {
+		t.Fatalf("Tail returned %d entries, want %d", len(got), len(entries))
+	}
+	for i, e := range got {
+		if e.Actor != entries[i].Actor || e.Action != entries[i].Action ||
+			e.Target != entries[i].Target || e.Detail != entries[i].Detail {
+			t.Errorf("entry %d = %+v, want %+v", i, e, entries[i])
+		}
+		if e.Timestamp == 0 {
+			t.Errorf("entry %d has no timestamp", i)
+		}
+	}
+}
+
+func TestTailLimit(t *testing.T) {
+	l := newTestLog(t)
+
+	for i := 0; i < 5; i++ {
+		actor := string(rune('a' + i))
+		if err := l.Append(Entry{Actor: actor, Action: "model.load"}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	got, err := l.Tail(2)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Tail returned %d entries, want 2", len(got))
+	}
+	// Most recent two, oldest first
+	if got[0].Actor != "d" || got[1].Actor != "e" {
+		t.Errorf("Tail = [%s, %s], want [d, e]", got[0].Actor, got[1].Actor)
+	}
+}
+
+func TestTailZero(t *testing.T) {
+	l := newTestLog(t)
+	if err := l.Append(Entry{Actor: "a", Action: "model.load"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	got, err := l.Tail(0)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Tail(0) = %v, want nil", got)
+	}
+}
+
+func TestTailSkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Append(Entry{Actor: "a", Action: "model.load"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	// Simulate a torn write from a crash
+	if _, err := l.file.Write([]byte("{\"actor\":\"b\",\"acti\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := l.Append(Entry{Actor: "c", Action: "model.unload"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	got, err := l.Tail(10)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Tail returned %d entries, want 2", len(got))
+	}
+	if got[0].Actor != "a" || got[1].Actor != "c" {
+		t.Errorf("Tail = [%s, %s], want [a, c]", got[0].Actor, got[1].Actor)
+	}
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	l := newTestLog(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := l.Append(Entry{Actor: "sk-lab", Action: "model.load"}); err != nil {
+					t.Errorf("Append failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := l.Tail(100)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(got) != 80 {
+		t.Errorf("Tail returned %d entries, want 80", len(got))
+	}
+}
+
+func TestOpenBadPath(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "audit.log")); err == nil {
+		t.Error("Open succeeded on a missing directory, want error")
+	}
+}
+
+func TestAppendPersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	if err := l.Append(Entry{Actor: "a", Action: "model.load"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	l.Close()
+
+	l2, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	defer l2.Close()
+	if err := l2.Append(Entry{Actor: "b", Action: "model.unload"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	got, err := l2.Tail(10)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Tail returned %d entries after reopen, want 2", len(got))
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("audit log file missing: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package bench generates synthetic chat+screenshot workloads against a
+// running server and reports throughput and latency distributions, so
+// model or cache changes can be load-tested without capturing real
+// agent traffic first.
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+)
+
+// Options configures a benchmark run
+type Options struct {
+	// ServerURL is the base URL, e.g. http://localhost:8080
+	ServerURL string
+	// Model to request
+	Model string
+	// Requests is the total number of requests to send
+	Requests int
+	// Concurrency is the number of parallel workers (default 1)
+	Concurrency int
+	// PromptTokens is the approximate synthetic prompt length in words
+	PromptTokens int
+	// CacheHitRatio in [0,1] is the fraction of requests that reuse a
+	// previously sent prompt, exercising the prefix cache
+	CacheHitRatio float64
+	// ImageBase64 is an optional screenshot payload; when empty a small
+	// synthetic PNG is generated
+	ImageBase64 string
+	// Seed makes the workload reproducible (0 uses a time-based seed)
+	Seed int64
+	// Client overrides the HTTP client (tests)
+	Client *http.Client
+}
+
+// Result aggregates a benchmark run
+type Result struct {
+	Requests         int
+	Errors           int
+	CacheHits        int
+	CompletionTokens int
+	Duration         time.Duration
+	TTFTs            []time.Duration
+	Latencies        []time.Duration
+}
+
+// TokensPerSec returns the aggregate completion token throughput
+func (r *Result) TokensPerSec() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.CompletionTokens) / r.Duration.Seconds()
+}
+
+// CacheHitRate returns the fraction of requests that reused a prompt
+func (r *Result) CacheHitRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.CacheHits) / float64(r.Requests)
+}
+
+// Percentile returns the p-th percentile of the given samples (p in [0,100])
+func Percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p / 100 * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// workload pre-generates the request sequence so that workers can pull
+// jobs without coordinating on the RNG
+type workload struct {
+	prompts   []string
+	cacheHits []bool
+}
+
+// generateWorkload builds the prompt sequence honouring the hit ratio
+func generateWorkload(opts Options) workload {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	w := workload{
+		prompts:   make([]string, opts.Requests),
+		cacheHits: make([]bool, opts.Requests),
+	}
+	var sent []string
+	for i := 0; i < opts.Requests; i++ {
+		if len(sent) > 0 && rng.Float64() < opts.CacheHitRatio {
+			w.prompts[i] = sent[rng.Intn(len(sent))]
+			w.cacheHits[i] = true
+		} else {
+			w.prompts[i] = makePrompt(rng, opts.PromptTokens)
+			sent = append(sent, w.prompts[i])
+		}
+	}
+	return w
+}
+
+// makePrompt builds a synthetic instruction of roughly n words
+func makePrompt(rng *rand.Rand, n int) string {
+	if n <= 0 {
+		n = 16
+	}
+	words := make([]string, n)
+	words[0] = "click"
+	for i := 1; i < n; i++ {
+		words[i] = fmt.Sprintf("elem%d", rng.Intn(100000))
+	}
+	return strings.Join(words, " ")
+}
+
+// syntheticScreenshot returns a base64-encoded 56x56 PNG
+func syntheticScreenshot() string {
+	img := image.NewRGBA(image.Rect(0, 0, 56, 56))
+	for y := 0; y < 56; y++ {
+		for x := 0; x < 56; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 4), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// Run executes the benchmark at the configured concurrency
+func Run(opts Options) (*Result, error) {
+	if opts.ServerURL == "" {
+		return nil, fmt.Errorf("server URL required")
+	}
+	if opts.Requests <= 0 {
+		return nil, fmt.Errorf("request count must be positive")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.ImageBase64 == "" {
+		opts.ImageBase64 = syntheticScreenshot()
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 120 * time.Second}
+	}
+
+	w := generateWorkload(opts)
+	result := &Result{Requests: opts.Requests}
+	var mu sync.Mutex
+
+	start := time.Now()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				ttft, latency, tokens, err := sendOne(client, opts, w.prompts[j])
+
+				mu.Lock()
+				if err != nil {
+					result.Errors++
+				} else {
+					result.TTFTs = append(result.TTFTs, ttft)
+					result.Latencies = append(result.Latencies, latency)
+					result.CompletionTokens += tokens
+					if w.cacheHits[j] {
+						result.CacheHits++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for j := 0; j < opts.Requests; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// sendOne issues a streaming request and measures time to first chunk
+func sendOne(client *http.Client, opts Options, prompt string) (ttft, latency time.Duration, tokens int, err error) {
+	reqBody, err := json.Marshal(api.ChatCompletionRequest{
+		Model:  opts.Model,
+		Stream: true,
+		Messages: []api.Message{
+			{Role: "user", Content: []api.ContentPart{
+				{Type: "image_url", ImageURL: &api.ImageURL{URL: "data:image/png;base64," + opts.ImageBase64}},
+				{Type: "text", Text: prompt},
+			}},
+		},
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	start := time.Now()
+	resp, err := client.Post(opts.ServerURL+"/v1/chat/completions", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, 0, fmt.Errorf("server returned %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		if data == "[DONE]" {
+			break
+		}
+		if ttft == 0 {
+			ttft = time.Since(start)
+		}
+
+		var chunk api.ChatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta != nil {
+				if _, ok := c.Delta.Content.(string); ok {
+					tokens++
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ttft, time.Since(start), tokens, err
+	}
+	return ttft, time.Since(start), tokens, nil
+}
@@ -0,0 +1,153 @@
+package bench
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeStreamServer emits n SSE content chunks followed by [DONE]
+func fakeStreamServer(t *testing.T, chunks int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < chunks; i++ {
+			fmt.Fprintf(w, "data: {\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"tok\"}}]}\n\n")
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestRun(t *testing.T) {
+	srv := fakeStreamServer(t, 5)
+	defer srv.Close()
+
+	result, err := Run(Options{
+		ServerURL:   srv.URL,
+		Model:       "gui-actor-2b",
+		Requests:    8,
+		Concurrency: 2,
+		Seed:        1,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Requests != 8 || result.Errors != 0 {
+		t.Errorf("Requests=%d Errors=%d, want 8/0", result.Requests, result.Errors)
+	}
+	if result.CompletionTokens != 40 {
+		t.Errorf("CompletionTokens = %d, want 40", result.CompletionTokens)
+	}
+	if len(result.TTFTs) != 8 || len(result.Latencies) != 8 {
+		t.Errorf("Got %d TTFTs / %d latencies, want 8/8", len(result.TTFTs), len(result.Latencies))
+	}
+	if result.TokensPerSec() <= 0 {
+		t.Errorf("TokensPerSec = %v, want > 0", result.TokensPerSec())
+	}
+}
+
+func TestRunCountsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	result, err := Run(Options{ServerURL: srv.URL, Requests: 3, Seed: 1})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Errors != 3 {
+		t.Errorf("Errors = %d, want 3", result.Errors)
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	if _, err := Run(Options{Requests: 1}); err == nil {
+		t.Error("Expected error without server URL")
+	}
+	if _, err := Run(Options{ServerURL: "http://x", Requests: 0}); err == nil {
+		t.Error("Expected error with zero requests")
+	}
+}
+
+func TestGenerateWorkloadHitRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratio    float64
+		requests int
+	}{
+		{"no reuse", 0.0, 50},
+		{"half reuse", 0.5, 200},
+		{"always reuse", 1.0, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := generateWorkload(Options{Requests: tt.requests, CacheHitRatio: tt.ratio, Seed: 7})
+
+			hits := 0
+			for _, h := range w.cacheHits {
+				if h {
+					hits++
+				}
+			}
+			rate := float64(hits) / float64(tt.requests)
+			// The first request can never be a hit, so allow some slack
+			if rate < tt.ratio-0.15 || rate > tt.ratio+0.1 {
+				t.Errorf("Hit rate = %v, want ~%v", rate, tt.ratio)
+			}
+			for i, h := range w.cacheHits {
+				if h {
+					continue
+				}
+				if w.prompts[i] == "" {
+					t.Errorf("Empty prompt at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWorkloadReproducible(t *testing.T) {
+	opts := Options{Requests: 20, CacheHitRatio: 0.3, PromptTokens: 8, Seed: 42}
+	a := generateWorkload(opts)
+	b := generateWorkload(opts)
+	for i := range a.prompts {
+		if a.prompts[i] != b.prompts[i] || a.cacheHits[i] != b.cacheHits[i] {
+			t.Fatalf("Workloads diverge at %d", i)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{
+		30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond,
+	}
+	if got := Percentile(samples, 0); got != 10*time.Millisecond {
+		t.Errorf("p0 = %v, want 10ms", got)
+	}
+	if got := Percentile(samples, 100); got != 30*time.Millisecond {
+		t.Errorf("p100 = %v, want 30ms", got)
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Empty percentile = %v, want 0", got)
+	}
+}
+
+func TestCacheHitRate(t *testing.T) {
+	r := &Result{Requests: 10, CacheHits: 4}
+	if got := r.CacheHitRate(); got != 0.4 {
+		t.Errorf("CacheHitRate = %v, want 0.4", got)
+	}
+	empty := &Result{}
+	if got := empty.CacheHitRate(); got != 0 {
+		t.Errorf("Empty CacheHitRate = %v, want 0", got)
+	}
+}
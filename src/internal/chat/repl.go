@@ -0,0 +1,177 @@
+// Package chat implements an interactive terminal session against a
+// running server, replacing ad-hoc curl testing. Input and output are
+// injected so the loop can be driven from tests.
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agenthands/mlxvllm/pkg/client"
+)
+
+// REPL is an interactive chat loop
+type REPL struct {
+	client  *client.Client
+	model   string
+	in      io.Reader
+	out     io.Writer
+	history []client.Message
+	// pendingImage is a base64 screenshot attached to the next message
+	pendingImage string
+}
+
+// New creates a REPL talking to the given client
+func New(c *client.Client, model string, in io.Reader, out io.Writer) *REPL {
+	return &REPL{client: c, model: model, in: in, out: out}
+}
+
+// AttachImage queues an image file to be sent with the next message
+func (r *REPL) AttachImage(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	r.pendingImage = base64.StdEncoding.EncodeToString(data)
+	return nil
+}
+
+// Run reads lines until EOF or /quit
+func (r *REPL) Run(ctx context.Context) error {
+	fmt.Fprintf(r.out, "Model: %s. Type /help for commands.\n", r.model)
+	scanner := bufio.NewScanner(r.in)
+	for {
+		fmt.Fprint(r.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			if quit := r.handleCommand(line); quit {
+				return nil
+			}
+			continue
+		}
+		if err := r.sendMessage(ctx, line); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		}
+	}
+}
+
+// handleCommand processes a /command line and reports whether to quit
+func (r *REPL) handleCommand(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/quit", "/exit":
+		return true
+	case "/help":
+		fmt.Fprintln(r.out, "Commands:")
+		fmt.Fprintln(r.out, "  /image <path>   attach a screenshot to the next message")
+		fmt.Fprintln(r.out, "  /model <name>   switch model")
+		fmt.Fprintln(r.out, "  /clear          reset conversation history")
+		fmt.Fprintln(r.out, "  /quit           exit")
+	case "/image":
+		if len(fields) < 2 {
+			fmt.Fprintln(r.out, "usage: /image <path>")
+			break
+		}
+		if err := r.AttachImage(fields[1]); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		} else {
+			fmt.Fprintf(r.out, "attached %s\n", fields[1])
+		}
+	case "/model":
+		if len(fields) < 2 {
+			fmt.Fprintln(r.out, "usage: /model <name>")
+			break
+		}
+		r.model = fields[1]
+		fmt.Fprintf(r.out, "model: %s\n", r.model)
+	case "/clear":
+		r.history = nil
+		fmt.Fprintln(r.out, "history cleared")
+	default:
+		fmt.Fprintf(r.out, "unknown command: %s\n", fields[0])
+	}
+	return false
+}
+
+// sendMessage streams one user turn and prints the response
+func (r *REPL) sendMessage(ctx context.Context, text string) error {
+	r.history = append(r.history, r.userMessage(text))
+
+	stream, err := r.client.Stream(ctx, &client.ChatRequest{
+		Model:    r.model,
+		Messages: r.history,
+	})
+	if err != nil {
+		// Drop the failed turn so the history stays consistent
+		r.history = r.history[:len(r.history)-1]
+		return err
+	}
+	defer stream.Close()
+
+	var reply strings.Builder
+	var coords *client.Point
+	tokens := 0
+	start := time.Now()
+	for {
+		ev, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case ev.Grounding != nil:
+			coords = ev.Grounding.Coordinates
+		case ev.Chunk != nil:
+			for _, c := range ev.Chunk.Choices {
+				if c.Delta == nil {
+					continue
+				}
+				if text, ok := c.Delta.Content.(string); ok {
+					fmt.Fprint(r.out, text)
+					reply.WriteString(text)
+					tokens++
+				}
+			}
+		}
+	}
+	fmt.Fprintln(r.out)
+
+	elapsed := time.Since(start).Seconds()
+	if elapsed > 0 && tokens > 0 {
+		fmt.Fprintf(r.out, "[%d tokens, %.1f tok/s]\n", tokens, float64(tokens)/elapsed)
+	}
+	if coords != nil {
+		fmt.Fprintf(r.out, "→ click (%.4f, %.4f)\n", coords.X, coords.Y)
+	}
+
+	r.history = append(r.history, client.Message{Role: "assistant", Content: reply.String()})
+	return nil
+}
+
+// userMessage builds the user turn, attaching any pending image
+func (r *REPL) userMessage(text string) client.Message {
+	if r.pendingImage == "" {
+		return client.Message{Role: "user", Content: text}
+	}
+	image := r.pendingImage
+	r.pendingImage = ""
+	return client.Message{Role: "user", Content: []client.ContentPart{
+		{Type: "image_url", ImageURL: &client.ImageURL{URL: "data:image/png;base64," + image}},
+		{Type: "text", Text: text},
+	}}
+}
@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/pkg/client"
+)
+
+// fakeServer streams a grounding event and one content chunk, and
+// records the received requests
+func fakeServer(t *testing.T, requests *[]client.ChatRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req client.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		*requests = append(*requests, req)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: grounding\n")
+		fmt.Fprint(w, `data: {"object":"chat.grounding","coordinates":{"x":0.25,"y":0.75}}`+"\n\n")
+		fmt.Fprint(w, `data: {"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"done"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestRunSendsMessageAndPrintsCoordinates(t *testing.T) {
+	var requests []client.ChatRequest
+	srv := fakeServer(t, &requests)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	repl := New(client.New(srv.URL), "gui-actor-2b", strings.NewReader("click save\n/quit\n"), &out)
+	if err := repl.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("Server got %d requests, want 1", len(requests))
+	}
+	if requests[0].Model != "gui-actor-2b" || len(requests[0].Messages) != 1 {
+		t.Errorf("Unexpected request: %+v", requests[0])
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "done") {
+		t.Errorf("Output missing streamed text: %q", output)
+	}
+	if !strings.Contains(output, "click (0.2500, 0.7500)") {
+		t.Errorf("Output missing coordinates: %q", output)
+	}
+	if !strings.Contains(output, "tok/s") {
+		t.Errorf("Output missing throughput: %q", output)
+	}
+}
+
+func TestHistoryAccumulates(t *testing.T) {
+	var requests []client.ChatRequest
+	srv := fakeServer(t, &requests)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	repl := New(client.New(srv.URL), "m", strings.NewReader("first\nsecond\n"), &out)
+	if err := repl.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Server got %d requests, want 2", len(requests))
+	}
+	// Second request carries user + assistant + user
+	if len(requests[1].Messages) != 3 {
+		t.Errorf("Second request has %d messages, want 3", len(requests[1].Messages))
+	}
+	if requests[1].Messages[1].Role != "assistant" || requests[1].Messages[1].Content != "done" {
+		t.Errorf("Assistant turn missing from history: %+v", requests[1].Messages[1])
+	}
+}
+
+func TestImageCommandAttachesToNextMessage(t *testing.T) {
+	var requests []client.ChatRequest
+	srv := fakeServer(t, &requests)
+	defer srv.Close()
+
+	imgPath := filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(imgPath, []byte("pngdata"), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	input := fmt.Sprintf("/image %s\nclick it\nno image now\n", imgPath)
+	var out bytes.Buffer
+	repl := New(client.New(srv.URL), "m", strings.NewReader(input), &out)
+	if err := repl.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Server got %d requests, want 2", len(requests))
+	}
+	// First message should be multipart (image + text)
+	parts, ok := requests[0].Messages[0].Content.([]interface{})
+	if !ok || len(parts) != 2 {
+		t.Fatalf("First message not multipart: %+v", requests[0].Messages[0].Content)
+	}
+	// Second request's final turn should be plain text again
+	last := requests[1].Messages[len(requests[1].Messages)-1]
+	if _, ok := last.Content.(string); !ok {
+		t.Errorf("Image leaked into later message: %+v", last.Content)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	var out bytes.Buffer
+	repl := New(client.New("http://unused"), "m", strings.NewReader("/help\n/model other\n/clear\n/bogus\n/exit\n"), &out)
+	if err := repl.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "Commands:") {
+		t.Errorf("Missing help output: %q", output)
+	}
+	if !strings.Contains(output, "model: other") {
+		t.Errorf("Missing model switch: %q", output)
+	}
+	if repl.model != "other" {
+		t.Errorf("Model = %q, want other", repl.model)
+	}
+	if !strings.Contains(output, "history cleared") {
+		t.Errorf("Missing clear output: %q", output)
+	}
+	if !strings.Contains(output, "unknown command: /bogus") {
+		t.Errorf("Missing unknown command output: %q", output)
+	}
+}
+
+func TestFailedTurnDroppedFromHistory(t *testing.T) {
+	var out bytes.Buffer
+	repl := New(client.New("http://127.0.0.1:1"), "m", strings.NewReader("hello\n"), &out)
+	if err := repl.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(repl.history) != 0 {
+		t.Errorf("History has %d entries after failure, want 0", len(repl.history))
+	}
+	if !strings.Contains(out.String(), "error:") {
+		t.Errorf("Missing error output: %q", out.String())
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/bench"
+)
+
+// newBenchCmd load-tests a running server with synthetic workloads
+func newBenchCmd() *cobra.Command {
+	var (
+		model         string
+		requests      int
+		concurrency   int
+		promptTokens  int
+		cacheHitRatio float64
+		imagePath     string
+		seed          int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Load-test the server with synthetic chat+screenshot traffic",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := bench.Options{
+				ServerURL:     serverURL,
+				Model:         model,
+				Requests:      requests,
+				Concurrency:   concurrency,
+				PromptTokens:  promptTokens,
+				CacheHitRatio: cacheHitRatio,
+				Seed:          seed,
+			}
+			if imagePath != "" {
+				data, err := os.ReadFile(imagePath)
+				if err != nil {
+					return fmt.Errorf("failed to read image: %w", err)
+				}
+				opts.ImageBase64 = base64.StdEncoding.EncodeToString(data)
+			}
+
+			result, err := bench.Run(opts)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Requests:   %d (%d errors)\n", result.Requests, result.Errors)
+			fmt.Fprintf(out, "Duration:   %v\n", result.Duration)
+			fmt.Fprintf(out, "Throughput: %.1f tokens/sec\n", result.TokensPerSec())
+			fmt.Fprintf(out, "Cache hits: %.1f%%\n", result.CacheHitRate()*100)
+			fmt.Fprintf(out, "TTFT:       p50=%v p95=%v p99=%v\n",
+				bench.Percentile(result.TTFTs, 50), bench.Percentile(result.TTFTs, 95), bench.Percentile(result.TTFTs, 99))
+			fmt.Fprintf(out, "Latency:    p50=%v p95=%v p99=%v\n",
+				bench.Percentile(result.Latencies, 50), bench.Percentile(result.Latencies, 95), bench.Percentile(result.Latencies, 99))
+
+			if result.Errors > 0 {
+				return fmt.Errorf("%d requests failed", result.Errors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", envOr("MLXVLLM_MODEL", "gui-actor-2b"), "Model to benchmark")
+	cmd.Flags().IntVar(&requests, "requests", 100, "Total number of requests")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of parallel workers")
+	cmd.Flags().IntVar(&promptTokens, "prompt-tokens", 32, "Approximate synthetic prompt length in words")
+	cmd.Flags().Float64Var(&cacheHitRatio, "cache-hit-ratio", 0.0, "Fraction of requests reusing a previous prompt")
+	cmd.Flags().StringVar(&imagePath, "image", "", "Screenshot file to attach (default: synthetic PNG)")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Workload RNG seed (0 = time-based)")
+	return cmd
+}
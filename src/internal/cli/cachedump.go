@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/modelcache"
+)
+
+// newCachedumpCmd dumps the local model cache file-by-file
+func newCachedumpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cachedump",
+		Short: "Dump the local model cache contents file-by-file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Cache: %s\n", store.Dir())
+			for _, e := range entries {
+				fmt.Fprintf(out, "\n%s (%s)\n", e.Name, modelcache.FormatSize(e.SizeBytes))
+				err := filepath.Walk(e.Path, func(path string, info os.FileInfo, err error) error {
+					if err != nil || info.IsDir() {
+						return err
+					}
+					rel, _ := filepath.Rel(e.Path, path)
+					fmt.Fprintf(out, "  %-40s %s\n", rel, modelcache.FormatSize(info.Size()))
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
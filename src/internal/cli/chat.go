@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/chat"
+	"github.com/agenthands/mlxvllm/pkg/client"
+)
+
+// newChatCmd opens the interactive REPL
+func newChatCmd() *cobra.Command {
+	var (
+		model     string
+		imagePath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Open an interactive chat session against the server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repl := chat.New(client.New(serverURL), model, cmd.InOrStdin(), cmd.OutOrStdout())
+			if imagePath != "" {
+				if err := repl.AttachImage(imagePath); err != nil {
+					return err
+				}
+			}
+			return repl.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", envOr("MLXVLLM_MODEL", "gui-actor-2b"), "Model to chat with")
+	cmd.Flags().StringVar(&imagePath, "image", "", "Screenshot to attach to the first message")
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/convert"
+)
+
+// newConvertCmd converts a HuggingFace checkpoint into the bin_weights
+// layout the MLX engine loads, without requiring Python tooling
+func newConvertCmd() *cobra.Command {
+	var quantize string
+	var quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "convert <checkpoint-dir> <output-dir>",
+		Short: "Convert HF safetensors weights to the MLX bin_weights layout",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := convert.Options{Quantize: quantize}
+			if !quiet {
+				opts.Progress = func(name string, index, total int) {
+					fmt.Fprintf(cmd.OutOrStdout(), "[%d/%d] %s\n", index, total, name)
+				}
+			}
+
+			n, err := convert.Run(args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Converted %d tensors to %s\n",
+				n, filepath.Join(args[1], convert.WeightsDir))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&quantize, "quantize", convert.QuantNone,
+		"Output precision (none, f16, int8)")
+	cmd.Flags().BoolVar(&quiet, "quiet", false, "Suppress per-tensor progress output")
+	return cmd
+}
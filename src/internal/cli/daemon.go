@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/daemon"
+)
+
+// newStopCmd terminates a daemonized server
+func newStopCmd() *cobra.Command {
+	var pidFile string
+
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a daemonized server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := daemon.Stop(pidFile, 10*time.Second); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Stopped")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pidFile, "pidfile", daemon.DefaultPIDFile(), "Pidfile location")
+	return cmd
+}
+
+// newStatusCmd reports whether a daemonized server is running
+func newStatusCmd() *cobra.Command {
+	var pidFile string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show daemon status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, running, err := daemon.Status(pidFile)
+			if err != nil {
+				return err
+			}
+			if running {
+				fmt.Fprintf(cmd.OutOrStdout(), "Running (pid %d)\n", pid)
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), "Not running")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pidFile, "pidfile", daemon.DefaultPIDFile(), "Pidfile location")
+	return cmd
+}
+
+// newInstallServiceCmd writes a launchd plist for persistent serving
+func newInstallServiceCmd() *cobra.Command {
+	var (
+		configPath string
+		logFile    string
+		plistPath  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install-service",
+		Short: "Install a launchd plist so the server runs persistently (macOS)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if logFile == "" {
+				logFile = daemon.DefaultLogFile()
+			}
+			path, err := daemon.InstallService(
+				[]string{"serve", "--config", configPath},
+				logFile, plistPath)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Installed %s\n", path)
+			fmt.Fprintf(out, "Activate with: launchctl load %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config",
+		envOr("MLXVLLM_CONFIG", "./models/config.yaml"), "Path to configuration file")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "Log file for the service")
+	cmd.Flags().StringVar(&plistPath, "plist", "", "Plist destination (default: ~/Library/LaunchAgents)")
+	return cmd
+}
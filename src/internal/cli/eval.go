@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/eval"
+)
+
+// newEvalCmd runs a ScreenSpot-style grounding benchmark against a
+// running server
+func newEvalCmd() *cobra.Command {
+	var (
+		model       string
+		manifest    string
+		concurrency int
+		reportPath  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate grounding accuracy on a benchmark manifest",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cases, err := eval.LoadManifest(manifest)
+			if err != nil {
+				return err
+			}
+
+			report, err := eval.Run(eval.Options{
+				ServerURL:   serverURL,
+				Model:       model,
+				Concurrency: concurrency,
+			}, cases)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Cases:    %d (%d errors)\n", report.Cases, report.Errors)
+			fmt.Fprintf(out, "Accuracy: %.1f%% (%d/%d point-in-box)\n",
+				report.Accuracy*100, report.Correct, report.Cases-report.Errors)
+			fmt.Fprintf(out, "Latency:  p50=%dms p95=%dms p99=%dms\n",
+				report.LatencyP50MS, report.LatencyP95MS, report.LatencyP99MS)
+
+			if reportPath != "" {
+				if err := eval.WriteReport(reportPath, report); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+				fmt.Fprintf(out, "Report:   %s\n", reportPath)
+			}
+
+			if report.Errors > 0 {
+				return fmt.Errorf("%d cases failed", report.Errors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", envOr("MLXVLLM_MODEL", "gui-actor-2b"), "Model to evaluate")
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Benchmark manifest JSON (images, instructions, gold boxes)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of parallel workers")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write the full JSON report to this path")
+	cmd.MarkFlagRequired("manifest")
+	return cmd
+}
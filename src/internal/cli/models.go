@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/modelcache"
+	"github.com/agenthands/mlxvllm/internal/persist"
+)
+
+// openStore opens the local model cache at the default location
+func openStore() (*modelcache.Store, error) {
+	return modelcache.NewStore(modelcache.DefaultDir())
+}
+
+// recordDownload notes a completed pull in the state file; best-effort,
+// the download itself already succeeded
+func recordDownload(repo, path string) {
+	state, err := persist.Open(defaultStateFile())
+	if err != nil {
+		return
+	}
+	defer state.Close()
+	state.PutDownload(persist.Download{Repo: repo, Path: path, Completed: time.Now().Unix()})
+}
+
+// newPullCmd downloads a model repo into the cache
+func newPullCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull <repo>",
+		Short: "Download a model from the Hugging Face hub",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			var lastFile string
+			path, err := store.Pull(cmd.Context(), args[0], func(file string, done, total int64) {
+				if file != lastFile {
+					if lastFile != "" {
+						fmt.Fprintln(out)
+					}
+					lastFile = file
+				}
+				fmt.Fprintf(out, "\r%s: %s / %s", file, modelcache.FormatSize(done), modelcache.FormatSize(total))
+			})
+			if lastFile != "" {
+				fmt.Fprintln(out)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Pulled %s to %s\n", args[0], path)
+			recordDownload(args[0], path)
+			return nil
+		},
+	}
+}
+
+// newListCmd shows cached models
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show cached models",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "No models cached in %s\n", store.Dir())
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSIZE\tQUANT\tMODIFIED")
+			for _, e := range entries {
+				quant := e.Quantization
+				if quant == "" {
+					quant = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, modelcache.FormatSize(e.SizeBytes), quant, e.ModifiedAt.Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newRemoveCmd deletes a cached model
+func newRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <model>",
+		Short: "Remove a cached model",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Remove(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", args[0])
+			return nil
+		},
+	}
+}
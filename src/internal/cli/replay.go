@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/replay"
+)
+
+// newReplayCmd re-sends a recorded trace against the server
+func newReplayCmd() *cobra.Command {
+	var (
+		concurrency int
+		showDiffs   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <trace.jsonl>",
+		Short: "Replay a recorded JSONL trace and diff the responses",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := replay.LoadTrace(args[0])
+			if err != nil {
+				return err
+			}
+			log.Printf("Replaying %d records against %s (concurrency %d)", len(records), serverURL, concurrency)
+
+			result, err := replay.Run(records, replay.Options{
+				ServerURL:   serverURL,
+				Concurrency: concurrency,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Total:   %d\n", result.Total)
+			fmt.Fprintf(out, "Errors:  %d\n", result.Errors)
+			fmt.Fprintf(out, "Diffs:   %d\n", len(result.Diffs))
+			fmt.Fprintf(out, "Latency: p50=%v p95=%v p99=%v\n",
+				result.Percentile(50), result.Percentile(95), result.Percentile(99))
+
+			for i, d := range result.Diffs {
+				if i >= showDiffs {
+					fmt.Fprintf(out, "... %d more diffs\n", len(result.Diffs)-i)
+					break
+				}
+				fmt.Fprintf(out, "--- diff #%d (prompt: %q)\n  recorded: %q\n  replayed: %q\n", d.Index, d.Prompt, d.Recorded, d.Replayed)
+			}
+
+			if len(result.Diffs) > 0 || result.Errors > 0 {
+				return fmt.Errorf("replay found %d diffs, %d errors", len(result.Diffs), result.Errors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of parallel workers")
+	cmd.Flags().IntVar(&showDiffs, "show-diffs", 10, "Maximum diffs to print")
+	return cmd
+}
@@ -0,0 +1,65 @@
+// Package cli wires the mlxvllm subcommands (serve, router, bench,
+// replay, pull, list, rm, tokenize, cachedump, chat) into a single
+// cobra-based entrypoint with consistent flags and environment
+// integration.
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// serverURL is the shared --server flag, defaulting from MLXVLLM_SERVER
+var serverURL string
+
+// newRootCmd builds the command tree
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "mlxvllm",
+		Short:         "OpenAI-compatible GUI-Actor inference server and tools",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&serverURL, "server",
+		envOr("MLXVLLM_SERVER", "http://localhost:8080"), "Server base URL")
+
+	root.AddCommand(
+		newServeCmd(),
+		newRouterCmd(),
+		newStopCmd(),
+		newStatusCmd(),
+		newInstallServiceCmd(),
+		newBenchCmd(),
+		newEvalCmd(),
+		newReplayCmd(),
+		newPullCmd(),
+		newListCmd(),
+		newRemoveCmd(),
+		newTokenizeCmd(),
+		newConvertCmd(),
+		newCachedumpCmd(),
+		newChatCmd(),
+		newWorkerCmd(),
+		newEngineWorkerCmd(),
+	)
+	return root
+}
+
+// Execute runs the CLI and returns the process exit code
+func Execute() int {
+	root := newRootCmd()
+	if err := root.Execute(); err != nil {
+		root.PrintErrln("Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// envOr returns the environment value or a default
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
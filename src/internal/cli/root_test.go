@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// execute runs the CLI with args and returns combined output
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	root := newRootCmd()
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs(args)
+	err := root.Execute()
+	return buf.String(), err
+}
+
+func TestRootHasSubcommands(t *testing.T) {
+	root := newRootCmd()
+	for _, name := range []string{"serve", "stop", "status", "install-service", "bench", "replay", "pull", "list", "rm", "tokenize", "cachedump", "chat"} {
+		cmd, _, err := root.Find([]string{name})
+		if err != nil || cmd.Name() != name {
+			t.Errorf("Subcommand %q not registered: %v", name, err)
+		}
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	if _, err := execute(t, "bogus"); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+}
+
+func TestListEmptyCache(t *testing.T) {
+	t.Setenv("MLXVLLM_MODELS", t.TempDir())
+
+	out, err := execute(t, "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "No models cached") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestListAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MLXVLLM_MODELS", dir)
+	modelDir := filepath.Join(dir, "gui-actor-2b-4bit")
+	os.MkdirAll(modelDir, 0755)
+	os.WriteFile(filepath.Join(modelDir, "config.json"), []byte("{}"), 0644)
+
+	out, err := execute(t, "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "gui-actor-2b-4bit") || !strings.Contains(out, "4bit") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+
+	out, err = execute(t, "cachedump")
+	if err != nil {
+		t.Fatalf("cachedump failed: %v", err)
+	}
+	if !strings.Contains(out, "config.json") {
+		t.Errorf("Unexpected cachedump output: %q", out)
+	}
+
+	if _, err := execute(t, "rm", "gui-actor-2b-4bit"); err != nil {
+		t.Fatalf("rm failed: %v", err)
+	}
+	if _, err := os.Stat(modelDir); !os.IsNotExist(err) {
+		t.Error("Model dir still exists after rm")
+	}
+}
+
+func TestTokenizeCommand(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/tokenize" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"tokens": []int{5, 6}, "count": 2})
+	}))
+	defer srv.Close()
+
+	out, err := execute(t, "--server", srv.URL, "tokenize", "hi there")
+	if err != nil {
+		t.Fatalf("tokenize failed: %v", err)
+	}
+	if !strings.Contains(out, "Tokens: 2") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestStatusNotRunning(t *testing.T) {
+	out, err := execute(t, "status", "--pidfile", filepath.Join(t.TempDir(), "none.pid"))
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if !strings.Contains(out, "Not running") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestInstallServiceCommand(t *testing.T) {
+	plistPath := filepath.Join(t.TempDir(), "test.plist")
+	out, err := execute(t, "install-service", "--plist", plistPath, "--config", "/tmp/c.yaml")
+	if err != nil {
+		t.Fatalf("install-service failed: %v", err)
+	}
+	if !strings.Contains(out, "launchctl load") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if _, err := os.Stat(plistPath); err != nil {
+		t.Errorf("Plist not written: %v", err)
+	}
+}
+
+func TestServerFlagFromEnv(t *testing.T) {
+	t.Setenv("MLXVLLM_SERVER", "http://example.test:9999")
+	root := newRootCmd()
+	if got, _ := root.PersistentFlags().GetString("server"); got != "http://example.test:9999" {
+		t.Errorf("server flag = %q, want env value", got)
+	}
+}
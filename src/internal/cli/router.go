@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/router"
+)
+
+// routerOptions collects the router flags
+type routerOptions struct {
+	listen         string
+	backends       []string
+	healthInterval time.Duration
+}
+
+// newRouterCmd load-balances requests across multiple backends
+func newRouterCmd() *cobra.Command {
+	var opts routerOptions
+
+	cmd := &cobra.Command{
+		Use:   "router",
+		Short: "Route OpenAI requests across multiple mlxvllm backends",
+		Long: `Run a horizontal proxy in front of several mlxvllm servers.
+
+Routing is model-aware and health-checked; requests with the same
+session or prompt prefix stick to the same backend to maximize prefix
+cache hits. GET /v1/models aggregates the model lists of all healthy
+backends.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRouter(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.listen, "listen", ":8081", "Router listen address")
+	cmd.Flags().StringSliceVar(&opts.backends, "backend", nil,
+		"Backend base URL (repeatable, e.g. http://mac-1:8080)")
+	cmd.Flags().DurationVar(&opts.healthInterval, "health-interval",
+		router.DefaultHealthInterval, "Backend health probe interval")
+	return cmd
+}
+
+// runRouter starts the proxy and serves until interrupted
+func runRouter(opts routerOptions) error {
+	rt, err := router.New(opts.backends)
+	if err != nil {
+		return err
+	}
+
+	rt.Start(opts.healthInterval)
+	defer rt.Stop()
+
+	server := &http.Server{
+		Addr:        opts.listen,
+		Handler:     rt,
+		ReadTimeout: 30 * time.Second,
+		IdleTimeout: 120 * time.Second,
+	}
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+
+		log.Println("Shutting down router...")
+		server.Shutdown(context.Background())
+	}()
+
+	log.Printf("Router listening on %s with %d backends", opts.listen, len(rt.Backends()))
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("router error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+	"github.com/agenthands/mlxvllm/internal/audit"
+	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/daemon"
+	"github.com/agenthands/mlxvllm/internal/distributed"
+	"github.com/agenthands/mlxvllm/internal/guardrail"
+	"github.com/agenthands/mlxvllm/internal/mlx"
+	"github.com/agenthands/mlxvllm/internal/model"
+	"github.com/agenthands/mlxvllm/internal/notify"
+	"github.com/agenthands/mlxvllm/internal/ocr"
+	"github.com/agenthands/mlxvllm/internal/persist"
+	"github.com/agenthands/mlxvllm/internal/plugin"
+	"github.com/agenthands/mlxvllm/internal/power"
+	"github.com/agenthands/mlxvllm/internal/prefixcache"
+	"github.com/agenthands/mlxvllm/internal/recording"
+	"github.com/agenthands/mlxvllm/internal/scheduler"
+	"github.com/agenthands/mlxvllm/internal/shadow"
+	"github.com/agenthands/mlxvllm/internal/slo"
+	"github.com/agenthands/mlxvllm/internal/templates"
+	"github.com/agenthands/mlxvllm/internal/usage"
+	"github.com/agenthands/mlxvllm/pkg/vision"
+)
+
+// serveOptions collects the serve flags
+type serveOptions struct {
+	configPath string
+	daemonize  bool
+	pidFile    string
+	logFile    string
+}
+
+// newServeCmd runs the HTTP server
+func newServeCmd() *cobra.Command {
+	var opts serveOptions
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the OpenAI-compatible inference server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.daemonize {
+				return spawnDaemon(cmd.OutOrStdout(), opts)
+			}
+			return runServe(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.configPath, "config",
+		envOr("MLXVLLM_CONFIG", "./models/config.yaml"), "Path to configuration file")
+	cmd.Flags().BoolVar(&opts.daemonize, "daemon", false, "Run in the background, detached from the terminal")
+	cmd.Flags().StringVar(&opts.pidFile, "pidfile", daemon.DefaultPIDFile(), "Pidfile location")
+	cmd.Flags().StringVar(&opts.logFile, "log-file", "", "Log to a rotating file instead of stderr")
+	return cmd
+}
+
+// spawnDaemon re-launches serve detached and reports the child pid
+func spawnDaemon(out interface{ Write([]byte) (int, error) }, opts serveOptions) error {
+	if pid, running, _ := daemon.Status(opts.pidFile); running {
+		return fmt.Errorf("already running (pid %d)", pid)
+	}
+
+	logFile := opts.logFile
+	if logFile == "" {
+		logFile = daemon.DefaultLogFile()
+	}
+	args := []string{
+		"serve",
+		"--config", opts.configPath,
+		"--pidfile", opts.pidFile,
+		"--log-file", logFile,
+	}
+	pid, err := daemon.Spawn(args, logFile)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Started daemon (pid %d), logging to %s\n", pid, logFile)
+	return nil
+}
+
+// runServe loads config, preloads models, and serves until interrupted
+func runServe(opts serveOptions) error {
+	if opts.logFile != "" {
+		w, err := daemon.NewRotatingWriter(opts.logFile, daemon.DefaultMaxLogBytes, daemon.DefaultLogBackups)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		log.SetOutput(w)
+	}
+	if opts.pidFile != "" {
+		if err := daemon.WritePID(opts.pidFile, os.Getpid()); err != nil {
+			return err
+		}
+		defer daemon.RemovePID(opts.pidFile)
+	}
+
+	cfg, err := config.LoadConfig(opts.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	registry := model.NewRegistry(cfg)
+	// Preload in the background so the server accepts connections
+	// immediately; the load gate holds or rejects requests for models
+	// that are still coming up
+	for name, mcfg := range cfg.Models {
+		if mcfg.Preload {
+			log.Printf("Preloading model: %s", name)
+			go func(name string) {
+				events, err := registry.LoadModel(name)
+				if err != nil {
+					log.Printf("Warning: failed to preload %s: %v", name, err)
+					return
+				}
+				for ev := range events {
+					if ev.Error != "" {
+						log.Printf("Warning: failed to preload %s: %s", name, ev.Error)
+					}
+				}
+			}(name)
+		}
+	}
+
+	handler := api.NewHandler(registry)
+	handler.SetPresets(cfg.Presets)
+	if cfg.Templates.Dir != "" {
+		filePresets, err := templates.Load(cfg.Templates.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to load templates: %w", err)
+		}
+		handler.SetPresets(filePresets)
+		stopWatch := templates.Watch(cfg.Templates.Dir,
+			time.Duration(cfg.Templates.ReloadSeconds)*time.Second,
+			func(filePresets map[string]config.PresetConfig) {
+				merged := make(map[string]config.PresetConfig, len(cfg.Presets)+len(filePresets))
+				for name, p := range cfg.Presets {
+					merged[name] = p
+				}
+				for name, p := range filePresets {
+					merged[name] = p
+				}
+				handler.ReplacePresets(merged)
+				log.Printf("Templates reloaded: %d presets from %s", len(filePresets), cfg.Templates.Dir)
+			})
+		defer stopWatch()
+		log.Printf("Templates enabled: %d presets from %s", len(filePresets), cfg.Templates.Dir)
+	}
+	handler.SetMemoryLimit(parseGB(cfg.Memory.MaxTotalGB))
+	handler.SetLoadGate(cfg.Server.MaxLoadWaiters)
+	handler.SetModelRouting(cfg.Aliases, cfg.Server.DefaultModel, cfg.Server.StrictModels)
+	contextLengths := make(map[string]int, len(cfg.Models))
+	for name, mcfg := range cfg.Models {
+		contextLengths[name] = mcfg.MaxContextLength
+	}
+	handler.SetContextLengths(contextLengths)
+	handler.SetRequestTimeout(
+		time.Duration(cfg.Server.RequestTimeoutSeconds)*time.Second,
+		time.Duration(cfg.Server.KeepAliveSeconds)*time.Second,
+	)
+	handler.SetActionCap(cfg.Server.MaxActionsPerTurn)
+	handler.SetPrefixPins(prefixcache.NewTracker(cfg.Server.MaxPersistentPinTokens))
+	handler.SetSessionSummarizer(cfg.Server.SummarizeAfterTokens)
+	handler.SetSnapRadius(cfg.Server.SnapRadius)
+	handler.SetCompatStrictness(cfg.Server.OpenAICompatStrict)
+	if cfg.Server.ShadowModel != "" {
+		handler.SetShadow(cfg.Server.ShadowModel, shadow.NewLog(0))
+	}
+	// Linked-in plugins register into the default registry from init()
+	handler.SetPlugins(plugin.Default)
+
+	// Resolve OCR providers at startup so a typo fails the config, not
+	// the first request
+	ocrProviders := make(map[string]ocr.Provider)
+	for name, mcfg := range cfg.Models {
+		if mcfg.OCRProvider == "" {
+			continue
+		}
+		p, err := ocr.Default.Get(mcfg.OCRProvider)
+		if err != nil {
+			return fmt.Errorf("model %s: %w", name, err)
+		}
+		ocrProviders[name] = p
+	}
+	handler.SetOCR(ocrProviders)
+
+	if cfg.Guardrail.Enabled {
+		rails := guardrail.NewRegistry()
+		if len(cfg.Guardrail.BlockedPatterns) > 0 {
+			hook, err := guardrail.NewBlockedPatterns("blocked-patterns", cfg.Guardrail.BlockedPatterns)
+			if err != nil {
+				return fmt.Errorf("invalid guardrail config: %w", err)
+			}
+			rails.Register(hook)
+		}
+		if region := cfg.Guardrail.AllowedRegion; region != nil {
+			rails.Register(guardrail.NewAllowedRegion("allowed-region",
+				region.MinX, region.MinY, region.MaxX, region.MaxY))
+		}
+		for key, names := range cfg.Guardrail.Keys {
+			rails.SetKeyHooks(key, names)
+		}
+		handler.SetGuardrails(rails)
+		log.Printf("Guardrails enabled: %d blocked patterns", len(cfg.Guardrail.BlockedPatterns))
+	}
+
+	if cfg.Redaction.Enabled && len(cfg.Redaction.Regions) > 0 {
+		regions := make([]vision.Region, len(cfg.Redaction.Regions))
+		for i, r := range cfg.Redaction.Regions {
+			regions[i] = vision.Region{X0: r.MinX, Y0: r.MinY, X1: r.MaxX, Y1: r.MaxY, Blur: r.Blur}
+		}
+		handler.SetRedaction(regions)
+		log.Printf("Screenshot redaction enabled: %d regions", len(regions))
+	}
+
+	if cfg.Recording.Enabled {
+		recorder, err := recording.NewRecorder(cfg.Recording.Dir, int64(cfg.Recording.MaxFileSizeMB)<<20)
+		if err != nil {
+			return fmt.Errorf("failed to initialize recording: %w", err)
+		}
+		defer recorder.Close()
+		handler.SetRecorder(recorder)
+		log.Printf("Recording enabled: %s", cfg.Recording.Dir)
+	}
+
+	var state *persist.Store
+	if cfg.Persistence.Enabled {
+		path := cfg.Persistence.Path
+		if path == "" {
+			path = defaultStateFile()
+		}
+		state, err = persist.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open state file: %w", err)
+		}
+		defer state.Close()
+		restored, err := handler.SetSessionStore(state)
+		if err != nil {
+			return fmt.Errorf("failed to restore sessions: %w", err)
+		}
+		log.Printf("Persistence enabled: %s (%d sessions restored)", path, restored)
+	}
+
+	if cfg.Usage.Enabled {
+		var store *usage.Store
+		if state != nil && cfg.Usage.Path == "" {
+			// API keys and usage rollups share the state file
+			store = usage.OpenDB(state.DB())
+		} else {
+			path := cfg.Usage.Path
+			if path == "" {
+				path = filepath.Join(filepath.Dir(daemon.DefaultPIDFile()), "usage.db")
+			}
+			store, err = usage.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open usage store: %w", err)
+			}
+			log.Printf("Usage metering enabled: %s", path)
+		}
+		defer store.Close()
+		handler.SetUsageStore(store)
+		handler.SetQuotaPolicy(api.QuotaPolicy{
+			Default: usage.Quota{
+				DailyTokens:   cfg.Usage.DailyTokenQuota,
+				MonthlyTokens: cfg.Usage.MonthlyTokenQuota,
+			},
+			AdminKeys: cfg.Usage.AdminKeys,
+		})
+	}
+
+	if cfg.Audit.Enabled {
+		path := cfg.Audit.Path
+		if path == "" {
+			path = filepath.Join(filepath.Dir(daemon.DefaultPIDFile()), "audit.log")
+		}
+		auditLog, err := audit.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		defer auditLog.Close()
+		handler.SetAuditLog(auditLog)
+		log.Printf("Audit logging enabled: %s", path)
+	}
+
+	if cfg.Scheduler.Enabled {
+		sched := scheduler.New(scheduler.Config{
+			Slots:          cfg.Scheduler.Slots,
+			MinSlots:       cfg.Scheduler.MinSlots,
+			MaxSlots:       cfg.Scheduler.MaxSlots,
+			GrowHeadroom:   cfg.Scheduler.GrowHeadroom,
+			ShrinkHeadroom: cfg.Scheduler.ShrinkHeadroom,
+			DefaultWeight:  cfg.Scheduler.DefaultWeight,
+			Weights:        cfg.Scheduler.Weights,
+			ShedThreshold:  time.Duration(cfg.Scheduler.ShedThresholdMS) * time.Millisecond,
+		})
+		handler.SetScheduler(sched)
+
+		// Size the decode batch from memory headroom when a limit is
+		// configured
+		if limitGB := parseGB(cfg.Memory.MaxTotalGB); limitGB > 0 {
+			stop := sched.AutoTune(func() (float64, float64) {
+				return registry.UsedGB(), limitGB
+			}, 0)
+			defer stop()
+		}
+		log.Printf("Fair scheduling enabled: %d slots", sched.Slots())
+	}
+
+	if cfg.Notify.Enabled && len(cfg.Notify.URLs) > 0 {
+		notifier := notify.New(notify.Config{
+			URLs:       cfg.Notify.URLs,
+			Secret:     cfg.Notify.Secret,
+			MaxRetries: cfg.Notify.MaxRetries,
+		})
+		defer notifier.Close()
+		handler.SetNotifier(notifier)
+		log.Printf("Event notifications enabled: %d webhooks", len(cfg.Notify.URLs))
+	}
+
+	if cfg.SLO.Enabled {
+		monitor := slo.NewMonitor(slo.Config{
+			Window:       time.Duration(cfg.SLO.WindowSeconds) * time.Second,
+			TTFT:         time.Duration(cfg.SLO.TTFTMillis) * time.Millisecond,
+			InterToken:   time.Duration(cfg.SLO.InterTokenMillis) * time.Millisecond,
+			MaxErrorRate: cfg.SLO.MaxErrorRate,
+		})
+		if cfg.SLO.WebhookURL != "" {
+			monitor.SetNotifier(slo.NewWebhookNotifier(cfg.SLO.WebhookURL))
+		}
+		handler.SetSLOMonitor(monitor)
+		log.Println("SLO monitoring enabled")
+	}
+
+	if cfg.Distributed.Enabled {
+		coord, err := distributed.NewCoordinator(distributed.Config{
+			Workers:     cfg.Distributed.Workers,
+			TotalLayers: cfg.Distributed.TotalLayers,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid distributed config: %w", err)
+		}
+		if err := coord.Start(); err != nil {
+			return fmt.Errorf("failed to start tensor-parallel workers: %w", err)
+		}
+		defer coord.Stop()
+		log.Printf("Tensor parallelism enabled: %d workers, plan %v", coord.Workers(), coord.Plan())
+	}
+
+	if cfg.Distributed.EngineIsolation {
+		supervisor := distributed.NewSupervisor(distributed.SupervisorConfig{
+			MaxRestarts: cfg.Distributed.MaxRestarts,
+		})
+		if err := supervisor.Start(); err != nil {
+			return fmt.Errorf("failed to start isolated MLX engine: %w", err)
+		}
+		defer supervisor.Stop()
+		handler.SetEngineSupervisor(supervisor)
+		log.Println("MLX engine isolation enabled")
+	}
+
+	monitor := power.NewMonitor(power.Hooks{
+		OnSleep: func() {
+			log.Println("System sleeping: pausing scheduler")
+			handler.Suspend()
+		},
+		OnWake: func() {
+			if err := mlx.Revalidate(); err != nil {
+				log.Printf("Warning: MLX revalidation after wake: %v", err)
+			}
+			log.Println("System awake: resuming scheduler")
+			handler.Wake()
+		},
+	})
+	if err := monitor.Start(); err != nil {
+		log.Printf("Warning: sleep/wake monitoring unavailable: %v", err)
+	} else {
+		defer monitor.Stop()
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	server := api.NewServer(addr, handler)
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+
+		log.Println("Shutting down...")
+		server.Shutdown(context.Background())
+	}()
+
+	if err := server.Start(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return nil
+}
+
+// defaultStateFile is where the persistence layer keeps server state
+// when no explicit path is configured
+func defaultStateFile() string {
+	return filepath.Join(filepath.Dir(daemon.DefaultPIDFile()), "state.db")
+}
+
+// parseGB reads a size like "32" or "32GB"; 0 when unparseable
+func parseGB(s string) float64 {
+	s = strings.TrimSuffix(strings.TrimSpace(strings.ToUpper(s)), "GB")
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
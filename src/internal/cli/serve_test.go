@@ -1,4 +1,4 @@
-package main
+package cli
 
 import (
 	"bytes"
@@ -85,3 +85,9 @@ func TestFullIntegration(t *testing.T) {
 	// Shutdown
 	server.Shutdown(context.Background())
 }
+
+func TestRunServeInvalidConfig(t *testing.T) {
+	if err := runServe(serveOptions{configPath: "/nonexistent/config.yaml"}); err == nil {
+		t.Error("Expected error for missing config")
+	}
+}
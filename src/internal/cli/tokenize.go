@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/pkg/client"
+)
+
+// newTokenizeCmd tokenizes text via the server
+func newTokenizeCmd() *cobra.Command {
+	var model string
+
+	cmd := &cobra.Command{
+		Use:   "tokenize [text]",
+		Short: "Tokenize text via the server (reads stdin if no argument)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var text string
+			if len(args) == 1 {
+				text = args[0]
+			} else {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				text = strings.TrimRight(string(data), "\n")
+			}
+
+			c := client.New(serverURL)
+			resp, err := c.Tokenize(cmd.Context(), &client.TokenizeRequest{Model: model, Text: text})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Tokens: %d\n", resp.Count)
+			fmt.Fprintln(out, resp.Tokens)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", envOr("MLXVLLM_MODEL", ""), "Model whose tokenizer to use")
+	return cmd
+}
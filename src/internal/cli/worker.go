@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agenthands/mlxvllm/internal/distributed"
+)
+
+// newWorkerCmd runs one tensor-parallel shard; spawned by the serve
+// coordinator, not meant for interactive use
+func newWorkerCmd() *cobra.Command {
+	var shmPath, shardSpec string
+
+	cmd := &cobra.Command{
+		Use:    "worker",
+		Short:  "Run a tensor-parallel worker process (internal)",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if shmPath == "" {
+				return fmt.Errorf("--shm is required")
+			}
+			shard, err := distributed.ParseLayerRange(shardSpec)
+			if err != nil {
+				return err
+			}
+			return distributed.RunWorker(cmd.Context(), shmPath, shard)
+		},
+	}
+
+	cmd.Flags().StringVar(&shmPath, "shm", "", "Shared memory segment path")
+	cmd.Flags().StringVar(&shardSpec, "shard", "", "Layer range to serve (start:end)")
+	return cmd
+}
+
+// newEngineWorkerCmd runs the isolated MLX engine; spawned by the
+// serve supervisor so a Metal crash cannot take down the HTTP server
+func newEngineWorkerCmd() *cobra.Command {
+	var shmPath string
+
+	cmd := &cobra.Command{
+		Use:    "engine-worker",
+		Short:  "Run the isolated MLX engine process (internal)",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if shmPath == "" {
+				return fmt.Errorf("--shm is required")
+			}
+			return distributed.RunEngine(cmd.Context(), shmPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&shmPath, "shm", "", "Shared memory segment path")
+	return cmd
+}
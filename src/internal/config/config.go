@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +13,38 @@ type ServerConfig struct {
 	Host         string `yaml:"host"`
 	Port         int    `yaml:"port"`
 	DefaultModel string `yaml:"default_model"`
+	// StrictModels disables default-model fallback: requests naming an
+	// unknown model get a 404 instead of the default
+	StrictModels bool `yaml:"strict_models"`
+	// MaxLoadWaiters bounds how many requests may queue behind an
+	// in-flight model load; beyond it (or at zero) requests get a 503
+	// with a model_loading code
+	MaxLoadWaiters int `yaml:"max_load_waiters"`
+	// RequestTimeoutSeconds bounds non-streaming completions, covering
+	// queueing and generation; zero disables the deadline
+	RequestTimeoutSeconds int `yaml:"request_timeout_seconds"`
+	// KeepAliveSeconds spaces SSE keep-alive comments on streams; zero
+	// uses the built-in default
+	KeepAliveSeconds int `yaml:"keep_alive_seconds"`
+	// MaxActionsPerTurn caps how many tool calls one response may
+	// carry; zero leaves the count uncapped
+	MaxActionsPerTurn int `yaml:"max_actions_per_turn"`
+	// MaxPersistentPinTokens bounds prompt tokens pinned with
+	// cache_control "persistent"; zero disables persistent pinning
+	MaxPersistentPinTokens int `yaml:"max_persistent_pin_tokens"`
+	// SummarizeAfterTokens turns on session history summarization once
+	// a session's estimated size passes this many tokens; zero keeps
+	// plain trimming
+	SummarizeAfterTokens int `yaml:"summarize_after_tokens"`
+	// SnapRadius snaps predicted coordinates to the nearest interactive
+	// element center within this normalized distance; zero disables it
+	SnapRadius float64 `yaml:"snap_radius"`
+	// ShadowModel duplicates finished requests to this model and logs
+	// divergence metrics; empty disables shadow traffic
+	ShadowModel string `yaml:"shadow_model"`
+	// OpenAICompatStrict confines GUI-Actor fields to the x_gui_actor
+	// extension object so responses validate against unmodified SDKs
+	OpenAICompatStrict bool `yaml:"openai_compat_strict"`
 }
 
 type ModelConfig struct {
@@ -21,11 +55,34 @@ type ModelConfig struct {
 	MaxPixels        int    `yaml:"max_pixels"`
 	MaxContextLength int    `yaml:"max_context_length"`
 	MemoryLimitGB    int    `yaml:"memory_limit_gb"`
+	// OCRProvider names a compiled-in OCR provider whose text boxes are
+	// appended to prompts as grounding hints; empty disables OCR
+	OCRProvider string `yaml:"ocr_provider"`
+	// Offload keeps only the first/last transformer layers GPU-resident
+	// and streams the rest, trading decode speed for fitting larger
+	// models in less unified memory
+	Offload *OffloadConfig `yaml:"offload"`
+}
+
+// OffloadConfig controls per-layer weight placement for
+// memory-constrained machines
+type OffloadConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TotalLayers is the transformer depth of the checkpoint
+	TotalLayers int `yaml:"total_layers"`
+	// KeepFirstLayers and KeepLastLayers stay GPU-resident; the layers
+	// between them are streamed in per forward pass
+	KeepFirstLayers int `yaml:"keep_first_layers"`
+	KeepLastLayers  int `yaml:"keep_last_layers"`
+	// Target is where streamed layers live: "mmap" (the default, a
+	// memory-mapped weight file) or "cpu"
+	Target string `yaml:"target"`
 }
 
 type ProfileConfig struct {
-	MaxPixels        int `yaml:"max_pixels"`
-	MaxContextLength int `yaml:"max_context_length"`
+	MaxPixels        int    `yaml:"max_pixels"`
+	MaxContextLength int    `yaml:"max_context_length"`
+	OCRProvider      string `yaml:"ocr_provider"`
 }
 
 type MemoryConfig struct {
@@ -34,6 +91,165 @@ type MemoryConfig struct {
 	KeepModels     []string `yaml:"keep_models"`
 }
 
+// LimitGB parses MaxTotalGB ("32" or "32GB"); 0 means no limit or an
+// unparseable value
+func (m MemoryConfig) LimitGB() float64 {
+	s := strings.TrimSuffix(strings.TrimSpace(strings.ToUpper(m.MaxTotalGB)), "GB")
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+type TemplatesConfig struct {
+	// Dir holds one preset per YAML file (<name>.yaml); file presets
+	// override config and built-in presets of the same name
+	Dir string `yaml:"dir"`
+	// ReloadSeconds is how often the directory is polled for edits;
+	// zero uses the built-in default
+	ReloadSeconds int `yaml:"reload_seconds"`
+}
+
+type PresetConfig struct {
+	// SystemPrompt is prepended when the request has no system message
+	SystemPrompt string `yaml:"system_prompt"`
+	// InstructionTemplate wraps the final user instruction; the literal
+	// {instruction} is replaced with the original text
+	InstructionTemplate string `yaml:"instruction_template"`
+}
+
+type CoreMLConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	ComputeUnits      string `yaml:"compute_units"` // ALL, CPU_AND_NE, CPU_ONLY
+	ModelCacheDir     string `yaml:"model_cache_dir"`
+	AllowLowPrecision bool   `yaml:"allow_low_precision"`
+}
+
+type OnnxConfig struct {
+	CoreML CoreMLConfig `yaml:"coreml"`
+}
+
+type RecordingConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Dir           string `yaml:"dir"`
+	MaxFileSizeMB int    `yaml:"max_file_size_mb"`
+}
+
+type UsageConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+	// Default per-key token quotas (0 = unlimited); individual keys can
+	// be overridden at runtime via PUT /v1/usage/quota
+	DailyTokenQuota   int64 `yaml:"daily_token_quota"`
+	MonthlyTokenQuota int64 `yaml:"monthly_token_quota"`
+	// AdminKeys are exempt from quotas and may adjust them
+	AdminKeys []string `yaml:"admin_keys"`
+}
+
+type SchedulerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Slots is the initial number of requests served concurrently
+	Slots int `yaml:"slots"`
+	// MinSlots/MaxSlots bound dynamic batch sizing from memory
+	// headroom; leaving them zero pins the batch at Slots
+	MinSlots int `yaml:"min_slots"`
+	MaxSlots int `yaml:"max_slots"`
+	// GrowHeadroom/ShrinkHeadroom are free-memory fractions steering
+	// growth and shrinkage
+	GrowHeadroom   float64 `yaml:"grow_headroom"`
+	ShrinkHeadroom float64 `yaml:"shrink_headroom"`
+	// DefaultWeight applies to API keys without an explicit weight
+	DefaultWeight float64 `yaml:"default_weight"`
+	// Weights maps API keys to their relative share under contention
+	Weights map[string]float64 `yaml:"weights"`
+	// ShedThresholdMS starts load shedding once smoothed queue wait
+	// exceeds this many milliseconds; zero disables shedding
+	ShedThresholdMS int `yaml:"shed_threshold_ms"`
+}
+
+type PersistenceConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path is the single state file holding API keys, usage rollups,
+	// sessions, and download metadata; empty uses the state directory
+	Path string `yaml:"path"`
+}
+
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+type GuardrailConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BlockedPatterns are regular expressions matched against generated
+	// content; a match blocks the response
+	BlockedPatterns []string `yaml:"blocked_patterns"`
+	// AllowedRegion restricts click coordinates to a normalized screen
+	// rectangle when set
+	AllowedRegion *RegionConfig `yaml:"allowed_region"`
+	// Keys scopes which hooks apply per API key; unlisted keys get every
+	// hook, an empty list disables them for that key
+	Keys map[string][]string `yaml:"keys"`
+}
+
+type RegionConfig struct {
+	MinX float64 `yaml:"min_x"`
+	MinY float64 `yaml:"min_y"`
+	MaxX float64 `yaml:"max_x"`
+	MaxY float64 `yaml:"max_y"`
+}
+
+type RedactionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Regions are masked out of every incoming screenshot before it
+	// reaches the vision encoder
+	Regions []RedactRegionConfig `yaml:"regions"`
+}
+
+type RedactRegionConfig struct {
+	RegionConfig `yaml:",inline"`
+	// Blur pixelates the region instead of blacking it out
+	Blur bool `yaml:"blur"`
+}
+
+type NotifyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URLs receive every server event as a signed JSON POST
+	URLs []string `yaml:"urls"`
+	// Secret signs request bodies (X-MLXVLLM-Signature) when set
+	Secret string `yaml:"secret"`
+	// MaxRetries bounds delivery attempts per URL per event
+	MaxRetries int `yaml:"max_retries"`
+}
+
+type SLOConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// WindowSeconds is the sliding evaluation window
+	WindowSeconds int `yaml:"window_seconds"`
+	// TTFTMillis is the p95 time-to-first-token objective
+	TTFTMillis int `yaml:"ttft_ms"`
+	// InterTokenMillis is the p95 inter-token latency objective
+	InterTokenMillis int `yaml:"inter_token_ms"`
+	// MaxErrorRate is the tolerated fraction of failed requests (0..1)
+	MaxErrorRate float64 `yaml:"max_error_rate"`
+	// WebhookURL receives breach notifications when set
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+type DistributedConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Workers is the number of MLX worker processes sharing the model
+	Workers int `yaml:"workers"`
+	// TotalLayers is the transformer depth sharded across the workers
+	TotalLayers int `yaml:"total_layers"`
+	// EngineIsolation runs the MLX engine in a supervised subprocess so
+	// a Metal crash is auto-restarted instead of killing the server
+	EngineIsolation bool `yaml:"engine_isolation"`
+	// MaxRestarts caps engine respawns before the supervisor gives up
+	MaxRestarts int `yaml:"max_restarts"`
+}
+
 type LoggingConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
@@ -42,9 +258,34 @@ type LoggingConfig struct {
 type Config struct {
 	Server   ServerConfig             `yaml:"server"`
 	Models   map[string]ModelConfig   `yaml:"models"`
+	Aliases  map[string]string        `yaml:"aliases"`
 	Profiles map[string]ProfileConfig `yaml:"profiles"`
-	Memory   MemoryConfig             `yaml:"memory"`
-	Logging  LoggingConfig            `yaml:"logging"`
+	Presets  map[string]PresetConfig  `yaml:"presets"`
+	// Templates loads presets from files and hot-reloads them on change
+	Templates TemplatesConfig `yaml:"templates"`
+	Memory    MemoryConfig    `yaml:"memory"`
+	Onnx      OnnxConfig      `yaml:"onnx"`
+	Recording RecordingConfig `yaml:"recording"`
+	Usage     UsageConfig     `yaml:"usage"`
+	Audit     AuditConfig     `yaml:"audit"`
+	// Persistence keeps sessions, usage, and download metadata in one
+	// state file that survives restarts
+	Persistence PersistenceConfig `yaml:"persistence"`
+	Scheduler   SchedulerConfig   `yaml:"scheduler"`
+	Guardrail   GuardrailConfig   `yaml:"guardrail"`
+	Redaction   RedactionConfig   `yaml:"redaction"`
+	// Distributed shards layers across worker processes for models too
+	// large for a single MLX context
+	Distributed DistributedConfig `yaml:"distributed"`
+	// SLO alerts when latency or error-rate objectives are breached
+	SLO SLOConfig `yaml:"slo"`
+	// Notify posts server events to webhook URLs
+	Notify  NotifyConfig  `yaml:"notify"`
+	Logging LoggingConfig `yaml:"logging"`
+	// ChecksumPolicy controls weight integrity verification at load:
+	// "strict" fails the load on a mismatch, "warn" (the default) logs
+	// and continues, "off" skips the check
+	ChecksumPolicy string `yaml:"checksum_policy"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -162,8 +162,8 @@ server:
 			validate:    nil,
 		},
 		{
-			name: "empty file",
-			content: ``,
+			name:        "empty file",
+			content:     ``,
 			expectError: false,
 			validate: func(t *testing.T, cfg *Config) {
 				// Empty config should load with zero values
@@ -251,3 +251,109 @@ models:
 		t.Error("Expected model with dash in name to be loaded")
 	}
 }
+
+func TestLoadConfigOnnxSection(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+server:
+  host: "127.0.0.1"
+  port: 8080
+onnx:
+  coreml:
+    enabled: true
+    compute_units: "CPU_AND_NE"
+    model_cache_dir: "/tmp/coreml-cache"
+    allow_low_precision: true
+`
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if !cfg.Onnx.CoreML.Enabled {
+		t.Error("Expected coreml.enabled to be true")
+	}
+	if cfg.Onnx.CoreML.ComputeUnits != "CPU_AND_NE" {
+		t.Errorf("Expected compute_units CPU_AND_NE, got %s", cfg.Onnx.CoreML.ComputeUnits)
+	}
+	if cfg.Onnx.CoreML.ModelCacheDir != "/tmp/coreml-cache" {
+		t.Errorf("Unexpected model_cache_dir %s", cfg.Onnx.CoreML.ModelCacheDir)
+	}
+	if !cfg.Onnx.CoreML.AllowLowPrecision {
+		t.Error("Expected allow_low_precision to be true")
+	}
+}
+
+func TestMemoryLimitGB(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want float64
+	}{
+		{"plain number", "32", 32},
+		{"with suffix", "32GB", 32},
+		{"lowercase with spaces", " 24 gb ", 24},
+		{"unset", "", 0},
+		{"garbage", "lots", 0},
+		{"negative", "-5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := MemoryConfig{MaxTotalGB: tt.in}
+			if got := m.LimitGB(); got != tt.want {
+				t.Errorf("LimitGB(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigOffloadSection(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+models:
+  gui-actor-7b:
+    path: "./models/gui-actor-7b"
+    enabled: true
+    offload:
+      enabled: true
+      total_layers: 28
+      keep_first_layers: 4
+      keep_last_layers: 4
+      target: "cpu"
+  gui-actor-2b:
+    path: "./models/gui-actor-2b"
+    enabled: true
+`
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	offload := cfg.Models["gui-actor-7b"].Offload
+	if offload == nil {
+		t.Fatal("Expected offload config for gui-actor-7b")
+	}
+	if !offload.Enabled || offload.TotalLayers != 28 {
+		t.Errorf("Unexpected offload %+v", offload)
+	}
+	if offload.KeepFirstLayers != 4 || offload.KeepLastLayers != 4 {
+		t.Errorf("Expected keep 4/4, got %d/%d", offload.KeepFirstLayers, offload.KeepLastLayers)
+	}
+	if offload.Target != "cpu" {
+		t.Errorf("Expected target cpu, got %s", offload.Target)
+	}
+	if cfg.Models["gui-actor-2b"].Offload != nil {
+		t.Error("Expected no offload config for gui-actor-2b")
+	}
+}
@@ -0,0 +1,179 @@
+// Package convert turns HuggingFace Qwen2-VL safetensors checkpoints
+// into the bin_weights layout the MLX engine loads: one raw tensor file
+// per weight plus a JSON manifest, with optional f16 or int8
+// quantization. It is pure Go so users don't need a Python environment
+// to prepare a model.
+package convert
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output layout names
+const (
+	WeightsDir   = "bin_weights"
+	ManifestFile = "manifest.json"
+)
+
+// Quantization modes for converted weights
+const (
+	QuantNone = "none" // float32, bit-exact with the decoded source
+	QuantF16  = "f16"  // half precision, half the size
+	QuantInt8 = "int8" // symmetric per-tensor int8 with a float scale
+)
+
+// Options configures a conversion run
+type Options struct {
+	// Quantize selects the output precision (default QuantNone)
+	Quantize string
+
+	// Progress, when non-nil, is called once per converted tensor
+	Progress func(name string, index, total int)
+}
+
+// Validate rejects unknown quantization modes
+func (o *Options) Validate() error {
+	switch o.Quantize {
+	case "", QuantNone, QuantF16, QuantInt8:
+		return nil
+	default:
+		return fmt.Errorf("unknown quantization %q (use %s, %s, or %s)",
+			o.Quantize, QuantNone, QuantF16, QuantInt8)
+	}
+}
+
+// ManifestEntry describes one converted tensor in the manifest
+type ManifestEntry struct {
+	Name  string  `json:"name"`
+	Shape []int64 `json:"shape"`
+	Dtype string  `json:"dtype"`
+	File  string  `json:"file"`
+
+	// Scale dequantizes int8 data (value = int8 * scale); zero for
+	// unquantized tensors
+	Scale float32 `json:"scale,omitempty"`
+}
+
+// Manifest indexes the converted weights for the engine loader
+type Manifest struct {
+	Quantization string          `json:"quantization"`
+	Tensors      []ManifestEntry `json:"tensors"`
+}
+
+// Run converts every safetensors shard under inputDir into
+// outputDir/bin_weights and writes the manifest. It returns the number
+// of tensors converted.
+func Run(inputDir, outputDir string, opts Options) (int, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	quant := opts.Quantize
+	if quant == "" {
+		quant = QuantNone
+	}
+
+	tensors, err := ReadCheckpoint(inputDir)
+	if err != nil {
+		return 0, err
+	}
+
+	weightsDir := filepath.Join(outputDir, WeightsDir)
+	if err := os.MkdirAll(weightsDir, 0o755); err != nil {
+		return 0, err
+	}
+
+	manifest := Manifest{Quantization: quant}
+	for i, tensor := range tensors {
+		if opts.Progress != nil {
+			opts.Progress(tensor.Name, i+1, len(tensors))
+		}
+
+		entry, data := encodeTensor(tensor, quant)
+		if err := os.WriteFile(filepath.Join(weightsDir, entry.File), data, 0o644); err != nil {
+			return 0, err
+		}
+		manifest.Tensors = append(manifest.Tensors, entry)
+	}
+
+	encoded, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepath.Join(weightsDir, ManifestFile), encoded, 0o644); err != nil {
+		return 0, err
+	}
+	return len(manifest.Tensors), nil
+}
+
+// encodeTensor serializes one tensor at the requested precision
+func encodeTensor(tensor *Tensor, quant string) (ManifestEntry, []byte) {
+	entry := ManifestEntry{
+		Name:  tensor.Name,
+		Shape: tensor.Shape,
+		File:  tensorFileName(tensor.Name),
+	}
+
+	switch quant {
+	case QuantF16:
+		entry.Dtype = "f16"
+		data := make([]byte, len(tensor.Data)*2)
+		for i, v := range tensor.Data {
+			binary.LittleEndian.PutUint16(data[i*2:], float32ToFloat16(v))
+		}
+		return entry, data
+	case QuantInt8:
+		entry.Dtype = "int8"
+		entry.Scale = int8Scale(tensor.Data)
+		data := make([]byte, len(tensor.Data))
+		for i, v := range tensor.Data {
+			data[i] = byte(int8(quantizeInt8(v, entry.Scale)))
+		}
+		return entry, data
+	default:
+		entry.Dtype = "f32"
+		data := make([]byte, len(tensor.Data)*4)
+		for i, v := range tensor.Data {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+		}
+		return entry, data
+	}
+}
+
+// int8Scale picks the symmetric per-tensor scale mapping the largest
+// magnitude to 127
+func int8Scale(values []float32) float32 {
+	var maxAbs float32
+	for _, v := range values {
+		if a := float32(math.Abs(float64(v))); a > maxAbs {
+			maxAbs = a
+		}
+	}
+	if maxAbs == 0 {
+		return 1
+	}
+	return maxAbs / 127
+}
+
+// quantizeInt8 rounds a value to its int8 representation, clamped to
+// the representable range
+func quantizeInt8(v, scale float32) int32 {
+	q := int32(math.RoundToEven(float64(v / scale)))
+	if q > 127 {
+		q = 127
+	}
+	if q < -127 {
+		q = -127
+	}
+	return q
+}
+
+// tensorFileName flattens a tensor name into a single weight file name
+func tensorFileName(name string) string {
+	return strings.ReplaceAll(name, "/", "_") + ".bin"
+}
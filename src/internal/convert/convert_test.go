@@ -0,0 +1,228 @@
+package convert
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSafetensors serializes float32 tensors into a minimal
+// .safetensors file at path
+func writeSafetensors(t *testing.T, path string, tensors []*Tensor) {
+	t.Helper()
+
+	header := make(map[string]stEntry, len(tensors))
+	var payload []byte
+	for _, tensor := range tensors {
+		start := int64(len(payload))
+		for _, v := range tensor.Data {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(v))
+			payload = append(payload, buf[:]...)
+		}
+		header[tensor.Name] = stEntry{
+			Dtype:       "F32",
+			Shape:       tensor.Shape,
+			DataOffsets: [2]int64{start, int64(len(payload))},
+		}
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("marshal header: %v", err)
+	}
+	data := make([]byte, 8, 8+len(headerJSON)+len(payload))
+	binary.LittleEndian.PutUint64(data, uint64(len(headerJSON)))
+	data = append(data, headerJSON...)
+	data = append(data, payload...)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write safetensors: %v", err)
+	}
+}
+
+func TestReadSafetensorsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.safetensors")
+	writeSafetensors(t, path, []*Tensor{
+		{Name: "b.weight", Shape: []int64{2}, Data: []float32{-1.5, 3}},
+		{Name: "a.weight", Shape: []int64{2, 2}, Data: []float32{1, 2, 3, 4}},
+	})
+
+	tensors, err := ReadSafetensors(path)
+	if err != nil {
+		t.Fatalf("ReadSafetensors failed: %v", err)
+	}
+	if len(tensors) != 2 {
+		t.Fatalf("loaded %d tensors, want 2", len(tensors))
+	}
+	// Sorted by name for determinism
+	if tensors[0].Name != "a.weight" || tensors[1].Name != "b.weight" {
+		t.Errorf("order = %s, %s; want a.weight, b.weight", tensors[0].Name, tensors[1].Name)
+	}
+	if got := tensors[0].Data; got[0] != 1 || got[3] != 4 {
+		t.Errorf("a.weight data = %v", got)
+	}
+	if got := tensors[1].Data; got[0] != -1.5 || got[1] != 3 {
+		t.Errorf("b.weight data = %v", got)
+	}
+}
+
+func TestDecodeHalfPrecisionDtypes(t *testing.T) {
+	// 1.0 in F16 is 0x3c00; in BF16 it is 0x3f80
+	f16, err := decodeValues("F16", []byte{0x00, 0x3c})
+	if err != nil || len(f16) != 1 || f16[0] != 1 {
+		t.Errorf("F16 decode = (%v, %v), want ([1], nil)", f16, err)
+	}
+	bf16, err := decodeValues("BF16", []byte{0x80, 0x3f})
+	if err != nil || len(bf16) != 1 || bf16[0] != 1 {
+		t.Errorf("BF16 decode = (%v, %v), want ([1], nil)", bf16, err)
+	}
+	if _, err := decodeValues("I64", make([]byte, 8)); err == nil {
+		t.Error("expected error for unsupported dtype")
+	}
+}
+
+func TestFloat16RoundTrip(t *testing.T) {
+	for _, v := range []float32{0, 1, -1, 0.5, 65504, -2.25, 1.0 / 1024} {
+		got := float16ToFloat32(float32ToFloat16(v))
+		if got != v {
+			t.Errorf("f16 round trip of %v = %v", v, got)
+		}
+	}
+	// Values past the f16 range saturate to Inf
+	if got := float16ToFloat32(float32ToFloat16(1e6)); !math.IsInf(float64(got), 1) {
+		t.Errorf("f16(1e6) = %v, want +Inf", got)
+	}
+}
+
+func TestRunWritesManifestAndWeights(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSafetensors(t, filepath.Join(input, "model.safetensors"), []*Tensor{
+		{Name: "visual.patch_embed.weight", Shape: []int64{2, 2}, Data: []float32{1, -2, 3, -4}},
+	})
+
+	var progressed int
+	n, err := Run(input, output, Options{
+		Progress: func(name string, index, total int) { progressed++ },
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if n != 1 || progressed != 1 {
+		t.Errorf("converted %d tensors with %d progress calls, want 1 and 1", n, progressed)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(output, WeightsDir, ManifestFile))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	if manifest.Quantization != QuantNone || len(manifest.Tensors) != 1 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	entry := manifest.Tensors[0]
+	if entry.Dtype != "f32" || entry.Name != "visual.patch_embed.weight" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	weights, err := os.ReadFile(filepath.Join(output, WeightsDir, entry.File))
+	if err != nil {
+		t.Fatalf("read weights: %v", err)
+	}
+	if len(weights) != 16 {
+		t.Fatalf("weight file is %d bytes, want 16", len(weights))
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(weights[4:])); got != -2 {
+		t.Errorf("second element = %v, want -2", got)
+	}
+}
+
+func TestRunQuantizations(t *testing.T) {
+	tests := []struct {
+		quant     string
+		dtype     string
+		wantBytes int
+	}{
+		{QuantF16, "f16", 8},
+		{QuantInt8, "int8", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quant, func(t *testing.T) {
+			input := t.TempDir()
+			output := t.TempDir()
+			writeSafetensors(t, filepath.Join(input, "model.safetensors"), []*Tensor{
+				{Name: "w", Shape: []int64{4}, Data: []float32{1, -2, 3, -4}},
+			})
+
+			if _, err := Run(input, output, Options{Quantize: tt.quant}); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			raw, err := os.ReadFile(filepath.Join(output, WeightsDir, ManifestFile))
+			if err != nil {
+				t.Fatalf("read manifest: %v", err)
+			}
+			var manifest Manifest
+			if err := json.Unmarshal(raw, &manifest); err != nil {
+				t.Fatalf("parse manifest: %v", err)
+			}
+			entry := manifest.Tensors[0]
+			if entry.Dtype != tt.dtype {
+				t.Errorf("dtype = %s, want %s", entry.Dtype, tt.dtype)
+			}
+
+			weights, err := os.ReadFile(filepath.Join(output, WeightsDir, entry.File))
+			if err != nil {
+				t.Fatalf("read weights: %v", err)
+			}
+			if len(weights) != tt.wantBytes {
+				t.Errorf("weight file is %d bytes, want %d", len(weights), tt.wantBytes)
+			}
+
+			if tt.quant == QuantInt8 {
+				// maxAbs 4 maps to -127; dequantized values stay close
+				if got := int8(weights[3]); got != -127 {
+					t.Errorf("quantized min = %d, want -127", got)
+				}
+				recovered := float32(int8(weights[1])) * entry.Scale
+				if math.Abs(float64(recovered+2)) > 0.05 {
+					t.Errorf("dequantized second element = %v, want ~-2", recovered)
+				}
+			}
+		})
+	}
+}
+
+func TestRunRejectsUnknownQuantization(t *testing.T) {
+	if _, err := Run(t.TempDir(), t.TempDir(), Options{Quantize: "q4"}); err == nil {
+		t.Error("expected error for unknown quantization")
+	}
+}
+
+func TestReadCheckpointMergesShards(t *testing.T) {
+	dir := t.TempDir()
+	writeSafetensors(t, filepath.Join(dir, "model-00001-of-00002.safetensors"),
+		[]*Tensor{{Name: "a", Shape: []int64{1}, Data: []float32{1}}})
+	writeSafetensors(t, filepath.Join(dir, "model-00002-of-00002.safetensors"),
+		[]*Tensor{{Name: "b", Shape: []int64{1}, Data: []float32{2}}})
+
+	tensors, err := ReadCheckpoint(dir)
+	if err != nil {
+		t.Fatalf("ReadCheckpoint failed: %v", err)
+	}
+	if len(tensors) != 2 {
+		t.Fatalf("loaded %d tensors, want 2", len(tensors))
+	}
+
+	if _, err := ReadCheckpoint(t.TempDir()); err == nil {
+		t.Error("expected error for empty checkpoint directory")
+	}
+}
@@ -0,0 +1,205 @@
+package convert
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// safetensors layout: an 8-byte little-endian header length, a JSON
+// header mapping tensor names to {dtype, shape, data_offsets}, then the
+// raw tensor data. Offsets are relative to the end of the header.
+
+// stEntry is one tensor's header record
+type stEntry struct {
+	Dtype       string   `json:"dtype"`
+	Shape       []int64  `json:"shape"`
+	DataOffsets [2]int64 `json:"data_offsets"`
+}
+
+// Tensor is one weight loaded from a checkpoint, decoded to float32
+type Tensor struct {
+	Name  string
+	Shape []int64
+	Data  []float32
+}
+
+// NumElements returns the product of the tensor dimensions
+func (t *Tensor) NumElements() int64 {
+	n := int64(1)
+	for _, d := range t.Shape {
+		n *= d
+	}
+	return n
+}
+
+// ReadSafetensors loads every tensor from one .safetensors file,
+// decoding F32, F16, and BF16 payloads to float32
+func ReadSafetensors(path string) ([]*Tensor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 {
+		return nil, fmt.Errorf("%s: too short for a safetensors header", path)
+	}
+
+	headerLen := binary.LittleEndian.Uint64(data[:8])
+	if headerLen > uint64(len(data)-8) {
+		return nil, fmt.Errorf("%s: header length %d exceeds file size", path, headerLen)
+	}
+
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data[8:8+headerLen], &header); err != nil {
+		return nil, fmt.Errorf("%s: invalid header: %w", path, err)
+	}
+	payload := data[8+headerLen:]
+
+	var tensors []*Tensor
+	for name, raw := range header {
+		if name == "__metadata__" {
+			continue
+		}
+		var entry stEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("%s: invalid entry for %s: %w", path, name, err)
+		}
+		start, end := entry.DataOffsets[0], entry.DataOffsets[1]
+		if start < 0 || end < start || end > int64(len(payload)) {
+			return nil, fmt.Errorf("%s: offsets %v for %s out of range", path, entry.DataOffsets, name)
+		}
+
+		values, err := decodeValues(entry.Dtype, payload[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("%s: tensor %s: %w", path, name, err)
+		}
+		tensors = append(tensors, &Tensor{Name: name, Shape: entry.Shape, Data: values})
+	}
+
+	// The header is a map; give callers a deterministic order
+	sort.Slice(tensors, func(i, j int) bool { return tensors[i].Name < tensors[j].Name })
+	return tensors, nil
+}
+
+// ReadCheckpoint loads all tensors from every .safetensors shard in a
+// HuggingFace checkpoint directory
+func ReadCheckpoint(dir string) ([]*Tensor, error) {
+	shards, err := filepath.Glob(filepath.Join(dir, "*.safetensors"))
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("no .safetensors files in %s", dir)
+	}
+	sort.Strings(shards)
+
+	var tensors []*Tensor
+	for _, shard := range shards {
+		loaded, err := ReadSafetensors(shard)
+		if err != nil {
+			return nil, err
+		}
+		tensors = append(tensors, loaded...)
+	}
+	return tensors, nil
+}
+
+// decodeValues converts a raw tensor payload to float32
+func decodeValues(dtype string, raw []byte) ([]float32, error) {
+	switch dtype {
+	case "F32":
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("F32 payload size %d not a multiple of 4", len(raw))
+		}
+		values := make([]float32, len(raw)/4)
+		for i := range values {
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+		}
+		return values, nil
+	case "F16":
+		if len(raw)%2 != 0 {
+			return nil, fmt.Errorf("F16 payload size %d not a multiple of 2", len(raw))
+		}
+		values := make([]float32, len(raw)/2)
+		for i := range values {
+			values[i] = float16ToFloat32(binary.LittleEndian.Uint16(raw[i*2:]))
+		}
+		return values, nil
+	case "BF16":
+		if len(raw)%2 != 0 {
+			return nil, fmt.Errorf("BF16 payload size %d not a multiple of 2", len(raw))
+		}
+		values := make([]float32, len(raw)/2)
+		for i := range values {
+			values[i] = math.Float32frombits(uint32(binary.LittleEndian.Uint16(raw[i*2:])) << 16)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported dtype %q", dtype)
+	}
+}
+
+// float16ToFloat32 expands an IEEE 754 half-precision value
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) & 1
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	var bits uint32
+	switch {
+	case exp == 0 && frac == 0:
+		bits = sign << 31
+	case exp == 0:
+		// Subnormal: normalize into float32 range
+		e := uint32(127 - 15 + 1)
+		for frac&0x400 == 0 {
+			frac <<= 1
+			e--
+		}
+		bits = sign<<31 | e<<23 | (frac&0x3ff)<<13
+	case exp == 0x1f:
+		bits = sign<<31 | 0xff<<23 | frac<<13 // Inf / NaN
+	default:
+		bits = sign<<31 | (exp-15+127)<<23 | frac<<13
+	}
+	return math.Float32frombits(bits)
+}
+
+// float32ToFloat16 rounds a float32 to IEEE 754 half precision
+// (round-to-nearest-even), saturating to Inf on overflow
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23&0xff) - 127 + 15
+	frac := bits & 0x7fffff
+
+	switch {
+	case exp >= 0x1f:
+		if int32(bits>>23&0xff) == 0xff && frac != 0 {
+			return sign | 0x7e00 // NaN
+		}
+		return sign | 0x7c00 // Inf / overflow
+	case exp <= 0:
+		if exp < -10 {
+			return sign // Underflows to zero
+		}
+		// Subnormal half
+		frac |= 0x800000
+		shift := uint32(14 - exp)
+		half := uint16(frac >> shift)
+		if frac>>(shift-1)&1 != 0 {
+			half++ // Round
+		}
+		return sign | half
+	default:
+		half := sign | uint16(exp)<<10 | uint16(frac>>13)
+		if frac&0x1000 != 0 {
+			half++ // Round to nearest (carries into the exponent safely)
+		}
+		return half
+	}
+}
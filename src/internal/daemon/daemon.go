@@ -0,0 +1,146 @@
+// Package daemon runs the server as a background process: pidfile
+// management, detached spawning, rotating log files, and launchd
+// integration so the server survives logout on a Mac mini.
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// stateDir returns the per-user state directory (~/.mlxvllm)
+func stateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".mlxvllm")
+}
+
+// DefaultPIDFile is where serve records its pid
+func DefaultPIDFile() string {
+	return filepath.Join(stateDir(), "mlxvllm.pid")
+}
+
+// DefaultLogFile is where a daemonized server writes its log
+func DefaultLogFile() string {
+	return filepath.Join(stateDir(), "logs", "mlxvllm.log")
+}
+
+// WritePID records a pid, creating parent directories as needed
+func WritePID(path string, pid int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create pidfile dir: %w", err)
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0644)
+}
+
+// ReadPID returns the pid recorded in the pidfile
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pidfile %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// RemovePID deletes the pidfile, ignoring a missing file
+func RemovePID(path string) error {
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Running reports whether a process with the pid exists
+func Running(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	return syscall.Kill(pid, 0) == nil
+}
+
+// Status reads the pidfile and checks the process. A missing pidfile
+// is reported as not running without error.
+func Status(pidPath string) (pid int, running bool, err error) {
+	pid, err = ReadPID(pidPath)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return pid, Running(pid), nil
+}
+
+// Stop signals the recorded process with SIGTERM and waits for it to
+// exit, cleaning up the pidfile
+func Stop(pidPath string, timeout time.Duration) error {
+	pid, err := ReadPID(pidPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("not running (no pidfile at %s)", pidPath)
+	}
+	if err != nil {
+		return err
+	}
+	if !Running(pid) {
+		RemovePID(pidPath)
+		return fmt.Errorf("not running (stale pidfile for pid %d)", pid)
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to signal pid %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !Running(pid) {
+			RemovePID(pidPath)
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("pid %d did not exit within %v", pid, timeout)
+}
+
+// Spawn starts the current executable detached with the given
+// arguments, redirecting output to the log file, and returns the child
+// pid. The child is expected to write its own pidfile.
+func Spawn(args []string, logPath string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create log dir: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
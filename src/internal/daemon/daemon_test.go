@@ -0,0 +1,207 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPIDFileLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "test.pid")
+
+	pid := os.Getpid()
+	if err := WritePID(path, pid); err != nil {
+		t.Fatalf("WritePID failed: %v", err)
+	}
+
+	got, err := ReadPID(path)
+	if err != nil {
+		t.Fatalf("ReadPID failed: %v", err)
+	}
+	if got != pid {
+		t.Errorf("ReadPID = %d, want %d", got, pid)
+	}
+
+	gotPid, running, err := Status(path)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if gotPid != pid || !running {
+		t.Errorf("Status = (%d, %v), want (%d, true)", gotPid, running, pid)
+	}
+
+	if err := RemovePID(path); err != nil {
+		t.Fatalf("RemovePID failed: %v", err)
+	}
+	// Removing again is not an error
+	if err := RemovePID(path); err != nil {
+		t.Errorf("Second RemovePID failed: %v", err)
+	}
+}
+
+func TestStatusMissingPIDFile(t *testing.T) {
+	pid, running, err := Status(filepath.Join(t.TempDir(), "missing.pid"))
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if pid != 0 || running {
+		t.Errorf("Status = (%d, %v), want (0, false)", pid, running)
+	}
+}
+
+func TestReadPIDInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.pid")
+	os.WriteFile(path, []byte("not a pid\n"), 0644)
+	if _, err := ReadPID(path); err == nil {
+		t.Error("Expected error for invalid pidfile")
+	}
+}
+
+func TestRunning(t *testing.T) {
+	if !Running(os.Getpid()) {
+		t.Error("Own process reported not running")
+	}
+	if Running(0) || Running(-1) {
+		t.Error("Invalid pid reported running")
+	}
+}
+
+func TestStopStalePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.pid")
+	// Max pid on Linux is bounded well below this
+	WritePID(path, 1<<22+12345)
+
+	if err := Stop(path, time.Second); err == nil {
+		t.Error("Expected error for stale pidfile")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Stale pidfile not cleaned up")
+	}
+}
+
+func TestStopNoPIDFile(t *testing.T) {
+	if err := Stop(filepath.Join(t.TempDir(), "none.pid"), time.Second); err == nil {
+		t.Error("Expected error when not running")
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "test.log")
+	w, err := NewRotatingWriter(path, 100, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	line := strings.Repeat("x", 40) + "\n"
+	for i := 0; i < 8; i++ {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+
+	// Current file plus two backups should exist, no third backup
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Errorf("Missing %s: %v", p, err)
+			continue
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s is %d bytes, exceeds limit", p, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Backup beyond limit exists")
+	}
+}
+
+func TestRotatingWriterResumesSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	os.WriteFile(path, []byte(strings.Repeat("y", 90)), 0644)
+
+	w, err := NewRotatingWriter(path, 100, 1)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	// 90 existing + 20 new exceeds 100, so this must rotate
+	if _, err := w.Write([]byte(strings.Repeat("z", 20))); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("Expected rotation of pre-existing content: %v", err)
+	}
+}
+
+func TestGeneratePlist(t *testing.T) {
+	plist := GeneratePlist("/usr/local/bin/mlxvllm", []string{"serve", "--config", "/etc/a&b.yaml"}, "/tmp/out.log")
+
+	for _, want := range []string{
+		PlistLabel,
+		"<string>/usr/local/bin/mlxvllm</string>",
+		"<string>serve</string>",
+		"<string>/etc/a&amp;b.yaml</string>",
+		"<string>/tmp/out.log</string>",
+		"<key>KeepAlive</key>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("Plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestInstallService(t *testing.T) {
+	plistPath := filepath.Join(t.TempDir(), "agents", PlistLabel+".plist")
+	got, err := InstallService([]string{"serve"}, "/tmp/log", plistPath)
+	if err != nil {
+		t.Fatalf("InstallService failed: %v", err)
+	}
+	if got != plistPath {
+		t.Errorf("Path = %q, want %q", got, plistPath)
+	}
+	data, err := os.ReadFile(plistPath)
+	if err != nil {
+		t.Fatalf("Plist not written: %v", err)
+	}
+	if !strings.Contains(string(data), "<string>serve</string>") {
+		t.Errorf("Plist content wrong:\n%s", data)
+	}
+}
+
+func TestSpawn(t *testing.T) {
+	if os.Getenv("DAEMON_SPAWN_CHILD") == "1" {
+		// We are the spawned child: exit immediately
+		fmt.Println("child ran")
+		os.Exit(0)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "logs", "spawn.log")
+	t.Setenv("DAEMON_SPAWN_CHILD", "1")
+
+	pid, err := Spawn([]string{"-test.run", "TestSpawn"}, logPath)
+	if err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	if pid <= 0 {
+		t.Fatalf("Spawn returned pid %d", pid)
+	}
+
+	// Wait for the child to exit and flush its output
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && Running(pid) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Log file not written: %v", err)
+	}
+	if !strings.Contains(string(data), "child ran") {
+		t.Errorf("Log missing child output: %q", data)
+	}
+}
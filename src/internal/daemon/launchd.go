@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PlistLabel identifies the launchd job
+const PlistLabel = "com.agenthands.mlxvllm"
+
+// plistTemplate is filled by GeneratePlist
+const plistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`
+
+// DefaultPlistPath is the per-user LaunchAgents location
+func DefaultPlistPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return PlistLabel + ".plist"
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", PlistLabel+".plist")
+}
+
+// GeneratePlist renders a launchd plist running exe with args
+func GeneratePlist(exe string, args []string, logPath string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\t\t<string>%s</string>\n", xmlEscape(exe))
+	for _, a := range args {
+		fmt.Fprintf(&sb, "\t\t<string>%s</string>\n", xmlEscape(a))
+	}
+	return fmt.Sprintf(plistTemplate, PlistLabel, sb.String(), xmlEscape(logPath), xmlEscape(logPath))
+}
+
+// InstallService writes the plist and returns its path. The caller
+// activates it with `launchctl load <path>`.
+func InstallService(args []string, logPath, plistPath string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if plistPath == "" {
+		plistPath = DefaultPlistPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create plist dir: %w", err)
+	}
+	if err := os.WriteFile(plistPath, []byte(GeneratePlist(exe, args, logPath)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write plist: %w", err)
+	}
+	return plistPath, nil
+}
+
+// xmlEscape escapes the characters that matter inside plist strings
+func xmlEscape(s string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return r.Replace(s)
+}
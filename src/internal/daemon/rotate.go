@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultMaxLogBytes rotates the log after 10 MiB
+const DefaultMaxLogBytes = 10 << 20
+
+// DefaultLogBackups is how many rotated files are kept
+const DefaultLogBackups = 3
+
+// RotatingWriter is an io.Writer that rotates the underlying file when
+// it exceeds a size limit, keeping a fixed number of numbered backups
+// (log, log.1, log.2, ...)
+type RotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	backups  int
+	file     *os.File
+	written  int64
+}
+
+// NewRotatingWriter opens (or creates) the log file for appending
+func NewRotatingWriter(path string, maxBytes int64, backups int) (*RotatingWriter, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxLogBytes
+	}
+	if backups <= 0 {
+		backups = DefaultLogBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	}
+
+	w := &RotatingWriter{path: path, maxBytes: maxBytes, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends, rotating first if the entry would exceed the limit
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.written+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the log file and records its current size
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.written = info.Size()
+	return nil
+}
+
+// rotate shifts the numbered backups and reopens a fresh file
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", w.path, w.backups))
+	for i := w.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return w.open()
+}
@@ -0,0 +1,169 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// DefaultReadyTimeout bounds how long a spawned worker may take to
+// attach and announce readiness
+const DefaultReadyTimeout = 10 * time.Second
+
+// Config sets the tensor-parallel topology
+type Config struct {
+	// Workers is the number of MLX worker processes sharing the model
+	Workers int
+	// TotalLayers is the transformer depth being sharded
+	TotalLayers int
+}
+
+// launchFunc starts one worker against a segment and returns a stop
+// function; replaced in tests to run workers in-process
+type launchFunc func(shmPath string, shard LayerRange) (stop func(), err error)
+
+// workerProc is one running shard
+type workerProc struct {
+	shard    LayerRange
+	seg      *Segment
+	requests *Mailbox
+	replies  *Mailbox
+	stop     func()
+}
+
+// Coordinator owns the worker processes and their shared-memory
+// channels
+type Coordinator struct {
+	cfg          Config
+	plan         []LayerRange
+	workers      []*workerProc
+	launch       launchFunc
+	readyTimeout time.Duration
+}
+
+// NewCoordinator validates the topology and computes the shard plan
+func NewCoordinator(cfg Config) (*Coordinator, error) {
+	plan, err := ShardLayers(cfg.TotalLayers, cfg.Workers)
+	if err != nil {
+		return nil, err
+	}
+	return &Coordinator{
+		cfg:          cfg,
+		plan:         plan,
+		launch:       spawnWorker,
+		readyTimeout: DefaultReadyTimeout,
+	}, nil
+}
+
+// Plan returns the per-worker layer ranges
+func (c *Coordinator) Plan() []LayerRange {
+	return c.plan
+}
+
+// Start creates a segment per shard, launches the workers, and waits
+// for each to report ready. A partial start is torn down.
+func (c *Coordinator) Start() error {
+	for _, shard := range c.plan {
+		seg, err := CreateSegment("", DefaultSegmentSize)
+		if err != nil {
+			c.Stop()
+			return err
+		}
+		w := &workerProc{
+			shard:    shard,
+			seg:      seg,
+			requests: seg.Mailbox(0),
+			replies:  seg.Mailbox(1),
+		}
+		c.workers = append(c.workers, w)
+
+		w.stop, err = c.launch(seg.Path(), shard)
+		if err != nil {
+			c.Stop()
+			return fmt.Errorf("failed to launch worker for layers %s: %w", shard, err)
+		}
+
+		if err := c.awaitReady(w); err != nil {
+			c.Stop()
+			return err
+		}
+	}
+	return nil
+}
+
+// awaitReady blocks until the worker posts its ready frame
+func (c *Coordinator) awaitReady(w *workerProc) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.readyTimeout)
+	defer cancel()
+
+	payload, err := w.replies.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("worker for layers %s did not become ready: %w", w.shard, err)
+	}
+	var msg message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Op != "ready" {
+		return fmt.Errorf("worker for layers %s sent %q instead of ready", w.shard, payload)
+	}
+	if msg.Layers != w.shard.String() {
+		return fmt.Errorf("worker reported shard %s, want %s", msg.Layers, w.shard)
+	}
+	return nil
+}
+
+// Ping round-trips a control frame through every worker
+func (c *Coordinator) Ping() error {
+	for _, w := range c.workers {
+		if err := w.requests.Post(encode(message{Op: "ping"})); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.readyTimeout)
+		payload, err := w.replies.Wait(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("worker for layers %s unresponsive: %w", w.shard, err)
+		}
+		var msg message
+		if err := json.Unmarshal(payload, &msg); err != nil || msg.Op != "pong" {
+			return fmt.Errorf("worker for layers %s replied %q to ping", w.shard, payload)
+		}
+	}
+	return nil
+}
+
+// Workers returns the number of running workers
+func (c *Coordinator) Workers() int {
+	return len(c.workers)
+}
+
+// Stop shuts down every worker and releases the segments
+func (c *Coordinator) Stop() {
+	for _, w := range c.workers {
+		w.requests.Post(encode(message{Op: "shutdown"}))
+		if w.stop != nil {
+			w.stop()
+		}
+		w.seg.Close()
+	}
+	c.workers = nil
+}
+
+// spawnWorker re-executes the server binary as a worker process
+func spawnWorker(shmPath string, shard LayerRange) (func(), error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, "worker", "--shm", shmPath, "--shard", shard.String())
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}, nil
+}
@@ -0,0 +1,117 @@
+package distributed
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// inProcessCoordinator runs workers as goroutines instead of spawning
+// processes, keeping the shared-memory protocol under test
+func inProcessCoordinator(t *testing.T, cfg Config) *Coordinator {
+	t.Helper()
+	c, err := NewCoordinator(cfg)
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	c.launch = func(shmPath string, shard LayerRange) (func(), error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := RunWorker(ctx, shmPath, shard); err != nil {
+				t.Errorf("worker for %s: %v", shard, err)
+			}
+		}()
+		return func() {
+			cancel()
+			wg.Wait()
+		}, nil
+	}
+	return c
+}
+
+func TestCoordinatorStartPingStop(t *testing.T) {
+	c := inProcessCoordinator(t, Config{Workers: 2, TotalLayers: 28})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if got := c.Workers(); got != 2 {
+		t.Errorf("Workers = %d, want 2", got)
+	}
+	if err := c.Ping(); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	c.Stop()
+	if got := c.Workers(); got != 0 {
+		t.Errorf("Workers after Stop = %d, want 0", got)
+	}
+}
+
+func TestCoordinatorPlan(t *testing.T) {
+	c, err := NewCoordinator(Config{Workers: 2, TotalLayers: 28})
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+	plan := c.Plan()
+	if len(plan) != 2 || plan[0] != (LayerRange{0, 14}) || plan[1] != (LayerRange{14, 28}) {
+		t.Errorf("Plan = %v, want [0:14 14:28]", plan)
+	}
+}
+
+func TestNewCoordinatorInvalidConfig(t *testing.T) {
+	if _, err := NewCoordinator(Config{Workers: 0, TotalLayers: 28}); err == nil {
+		t.Error("NewCoordinator accepted zero workers")
+	}
+}
+
+func TestCoordinatorReadyTimeout(t *testing.T) {
+	c, err := NewCoordinator(Config{Workers: 1, TotalLayers: 28})
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+	c.readyTimeout = 10 * time.Millisecond
+	// A worker that never attaches
+	c.launch = func(string, LayerRange) (func(), error) {
+		return func() {}, nil
+	}
+
+	err = c.Start()
+	if err == nil {
+		t.Fatal("Start succeeded without a ready worker")
+	}
+	if !strings.Contains(err.Error(), "did not become ready") {
+		t.Errorf("error = %v, want a ready-timeout message", err)
+	}
+	if got := c.Workers(); got != 0 {
+		t.Errorf("Workers after failed Start = %d, want 0", got)
+	}
+}
+
+func TestWorkerRejectsUnknownOp(t *testing.T) {
+	c := inProcessCoordinator(t, Config{Workers: 1, TotalLayers: 28})
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer c.Stop()
+
+	w := c.workers[0]
+	if err := w.requests.Post(encode(message{Op: "teleport"})); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	payload, err := w.replies.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if !strings.Contains(string(payload), "unknown op") {
+		t.Errorf("reply = %s, want an unknown-op error", payload)
+	}
+}
@@ -0,0 +1,57 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// RunEngine serves the full MLX engine from an isolated worker
+// process: attach to the supervisor's segment, announce readiness, and
+// answer control frames until shutdown. A Metal crash or C++ abort
+// kills only this process; the supervisor respawns it.
+func RunEngine(ctx context.Context, shmPath string) error {
+	seg, err := OpenSegment(shmPath, DefaultSegmentSize)
+	if err != nil {
+		return err
+	}
+	defer seg.Close()
+
+	requests := seg.Mailbox(0)
+	replies := seg.Mailbox(1)
+
+	// TODO: initialize the MLX context and load weights here
+	if err := replies.Post(encode(message{Op: "ready"})); err != nil {
+		return err
+	}
+
+	for {
+		payload, err := requests.Wait(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			replies.Post(encode(message{Op: "error", Error: fmt.Sprintf("bad frame: %v", err)}))
+			continue
+		}
+
+		switch msg.Op {
+		case "ping":
+			replies.Post(encode(message{Op: "pong"}))
+		case "prefill":
+			// TODO: run the real prefill over msg.Tokens once the MLX
+			// generation loop lands
+			replies.Post(encode(message{Op: "prefilled", ID: msg.ID}))
+		case "shutdown":
+			replies.Post(encode(message{Op: "bye"}))
+			return nil
+		default:
+			replies.Post(encode(message{Op: "error", Error: fmt.Sprintf("unknown op %q", msg.Op)}))
+		}
+	}
+}
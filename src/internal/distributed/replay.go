@@ -0,0 +1,91 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// InflightRequest buffers what the supervisor needs to resume a
+// request after an engine crash: the prompt tokens are replayed as a
+// fresh prefill on the restarted worker
+type InflightRequest struct {
+	ID           string
+	PromptTokens []int
+}
+
+// Register tracks a request for replay across engine restarts. Pair
+// with Deregister when the response is written.
+func (s *Supervisor) Register(req InflightRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight == nil {
+		s.inflight = make(map[string]*inflightEntry)
+	}
+	s.inflight[req.ID] = &inflightEntry{req: req}
+}
+
+// Deregister drops a completed request from the replay table
+func (s *Supervisor) Deregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inflight, id)
+}
+
+// Restarted reports whether the request survived an engine restart,
+// which callers surface to clients as an x-restarted marker
+func (s *Supervisor) Restarted(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.inflight[id]
+	return ok && entry.restarted
+}
+
+// Inflight returns the number of tracked requests
+func (s *Supervisor) Inflight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inflight)
+}
+
+// replayInflight re-runs the prefill for every tracked request on the
+// freshly restarted worker. Called from the watch loop after a
+// successful respawn.
+func (s *Supervisor) replayInflight() {
+	s.mu.Lock()
+	requests, replies := s.requests, s.replies
+	entries := make([]*inflightEntry, 0, len(s.inflight))
+	for _, entry := range s.inflight {
+		entries = append(entries, entry)
+	}
+	s.mu.Unlock()
+
+	for _, entry := range entries {
+		if err := s.replayOne(requests, replies, entry.req); err != nil {
+			log.Printf("Failed to replay request %s after restart: %v", entry.req.ID, err)
+			continue
+		}
+		s.mu.Lock()
+		entry.restarted = true
+		s.mu.Unlock()
+	}
+}
+
+// replayOne posts one prefill frame and waits for its acknowledgement
+func (s *Supervisor) replayOne(requests, replies *Mailbox, req InflightRequest) error {
+	if err := requests.Post(encode(message{Op: "prefill", ID: req.ID, Tokens: req.PromptTokens})); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.readyTimeout)
+	defer cancel()
+	payload, err := replies.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	var msg message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Op != "prefilled" || msg.ID != req.ID {
+		return fmt.Errorf("unexpected prefill reply %q", payload)
+	}
+	return nil
+}
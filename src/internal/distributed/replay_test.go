@@ -0,0 +1,72 @@
+package distributed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterDeregister(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{})
+
+	s.Register(InflightRequest{ID: "req-1", PromptTokens: []int{1, 2, 3}})
+	s.Register(InflightRequest{ID: "req-2"})
+	if got := s.Inflight(); got != 2 {
+		t.Errorf("Inflight = %d, want 2", got)
+	}
+	if s.Restarted("req-1") {
+		t.Error("Restarted = true before any restart")
+	}
+
+	s.Deregister("req-1")
+	if got := s.Inflight(); got != 1 {
+		t.Errorf("Inflight after Deregister = %d, want 1", got)
+	}
+	if s.Restarted("req-1") {
+		t.Error("Restarted = true for a deregistered request")
+	}
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	s := inProcessSupervisor(SupervisorConfig{RestartBackoff: time.Millisecond})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Stop()
+
+	s.Register(InflightRequest{ID: "req-1", PromptTokens: []int{5, 7, 11}})
+	s.Register(InflightRequest{ID: "req-2", PromptTokens: []int{13}})
+
+	// Kill the engine out from under the in-flight requests
+	s.mu.Lock()
+	requests := s.requests
+	s.mu.Unlock()
+	requests.Post(encode(message{Op: "shutdown"}))
+
+	waitFor(t, "replay", func() bool {
+		return s.Restarted("req-1") && s.Restarted("req-2")
+	})
+	if got := s.Restarts(); got != 1 {
+		t.Errorf("Restarts = %d, want 1", got)
+	}
+	// The restarted worker still answers control traffic after the
+	// replayed prefills
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping after replay failed: %v", err)
+	}
+}
+
+func TestEnginePrefillAck(t *testing.T) {
+	s := inProcessSupervisor(SupervisorConfig{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Stop()
+
+	s.mu.Lock()
+	requests, replies := s.requests, s.replies
+	s.mu.Unlock()
+
+	if err := s.replayOne(requests, replies, InflightRequest{ID: "req-9", PromptTokens: []int{1, 2}}); err != nil {
+		t.Errorf("replayOne failed: %v", err)
+	}
+}
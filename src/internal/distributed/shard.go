@@ -0,0 +1,73 @@
+// Package distributed shards transformer layers across MLX worker
+// processes communicating over shared memory, so Mac Studio Ultra
+// configurations can serve models larger than one process can hold.
+package distributed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LayerRange is one worker's slice of the transformer stack,
+// half-open: [Start, End)
+type LayerRange struct {
+	Start int
+	End   int
+}
+
+// Count returns the number of layers in the range
+func (r LayerRange) Count() int {
+	return r.End - r.Start
+}
+
+// String renders the range as "start:end" for worker command lines
+func (r LayerRange) String() string {
+	return fmt.Sprintf("%d:%d", r.Start, r.End)
+}
+
+// ParseLayerRange reads a "start:end" range
+func ParseLayerRange(s string) (LayerRange, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return LayerRange{}, fmt.Errorf("invalid layer range %q, want start:end", s)
+	}
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return LayerRange{}, fmt.Errorf("invalid layer range %q: %w", s, err)
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return LayerRange{}, fmt.Errorf("invalid layer range %q: %w", s, err)
+	}
+	if start < 0 || end <= start {
+		return LayerRange{}, fmt.Errorf("invalid layer range %q: empty or negative", s)
+	}
+	return LayerRange{Start: start, End: end}, nil
+}
+
+// ShardLayers splits totalLayers evenly across workers; earlier
+// workers absorb the remainder so no shard differs by more than one
+// layer
+func ShardLayers(totalLayers, workers int) ([]LayerRange, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("workers must be positive, got %d", workers)
+	}
+	if totalLayers < workers {
+		return nil, fmt.Errorf("cannot shard %d layers across %d workers", totalLayers, workers)
+	}
+
+	base := totalLayers / workers
+	rem := totalLayers % workers
+	plan := make([]LayerRange, workers)
+	start := 0
+	for i := range plan {
+		count := base
+		if i < rem {
+			count++
+		}
+		plan[i] = LayerRange{Start: start, End: start + count}
+		start += count
+	}
+	return plan, nil
+}
@@ -0,0 +1,89 @@
+package distributed
+
+import "testing"
+
+func TestShardLayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		workers int
+		want    []LayerRange
+	}{
+		{"28 layers 2 workers", 28, 2, []LayerRange{{0, 14}, {14, 28}}},
+		{"28 layers 4 workers", 28, 4, []LayerRange{{0, 7}, {7, 14}, {14, 21}, {21, 28}}},
+		{"29 layers 2 workers", 29, 2, []LayerRange{{0, 15}, {15, 29}}},
+		{"single worker", 32, 1, []LayerRange{{0, 32}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := ShardLayers(tt.total, tt.workers)
+			if err != nil {
+				t.Fatalf("ShardLayers failed: %v", err)
+			}
+			if len(plan) != len(tt.want) {
+				t.Fatalf("plan = %v, want %v", plan, tt.want)
+			}
+			for i := range plan {
+				if plan[i] != tt.want[i] {
+					t.Errorf("shard %d = %v, want %v", i, plan[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestShardLayersInvalid(t *testing.T) {
+	if _, err := ShardLayers(28, 0); err == nil {
+		t.Error("ShardLayers accepted zero workers")
+	}
+	if _, err := ShardLayers(1, 2); err == nil {
+		t.Error("ShardLayers accepted more workers than layers")
+	}
+}
+
+func TestParseLayerRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LayerRange
+		wantErr bool
+	}{
+		{"0:14", LayerRange{0, 14}, false},
+		{"14:28", LayerRange{14, 28}, false},
+		{"14", LayerRange{}, true},
+		{"a:b", LayerRange{}, true},
+		{"5:5", LayerRange{}, true},
+		{"-1:4", LayerRange{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLayerRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLayerRange(%q) accepted invalid input", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLayerRange(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLayerRange(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLayerRangeStringRoundTrip(t *testing.T) {
+	r := LayerRange{Start: 7, End: 21}
+	if r.Count() != 14 {
+		t.Errorf("Count = %d, want 14", r.Count())
+	}
+	parsed, err := ParseLayerRange(r.String())
+	if err != nil {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if parsed != r {
+		t.Errorf("round trip = %v, want %v", parsed, r)
+	}
+}
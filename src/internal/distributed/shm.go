@@ -0,0 +1,156 @@
+package distributed
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// DefaultSegmentSize is the shared-memory segment per worker; big
+// enough for activation frames at the shard boundary
+const DefaultSegmentSize = 1 << 20
+
+// pollInterval paces mailbox polling; shared memory has no wakeup
+// primitive, so receivers spin gently
+const pollInterval = 200 * time.Microsecond
+
+// Segment is a file-backed shared-memory region mapped into both the
+// coordinator and one worker process
+type Segment struct {
+	f       *os.File
+	data    []byte
+	creator bool
+}
+
+// CreateSegment makes a new segment backed by a temp file in dir
+// (os.TempDir when empty). The creator removes the file on Close.
+func CreateSegment(dir string, size int) (*Segment, error) {
+	f, err := os.CreateTemp(dir, "mlxvllm-shm-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shared memory file: %w", err)
+	}
+	if err := f.Truncate(int64(size)); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to size shared memory file: %w", err)
+	}
+	seg, err := mapSegment(f, size)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	seg.creator = true
+	return seg, nil
+}
+
+// OpenSegment attaches to a segment created by the coordinator
+func OpenSegment(path string, size int) (*Segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shared memory file: %w", err)
+	}
+	seg, err := mapSegment(f, size)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return seg, nil
+}
+
+func mapSegment(f *os.File, size int) (*Segment, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map shared memory: %w", err)
+	}
+	return &Segment{f: f, data: data}, nil
+}
+
+// Path returns the backing file, passed to the worker on its command
+// line
+func (s *Segment) Path() string {
+	return s.f.Name()
+}
+
+// Close unmaps the segment; the creating side also removes the
+// backing file. Closing twice is a no-op, so teardown paths need not
+// coordinate over who unmaps.
+func (s *Segment) Close() error {
+	if s.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(s.data)
+	s.data = nil
+	s.f.Close()
+	if s.creator {
+		os.Remove(s.f.Name())
+	}
+	return err
+}
+
+// Mailbox is a single-writer message slot inside a segment. Index 0
+// carries coordinator-to-worker requests, index 1 the replies.
+type Mailbox struct {
+	buf  []byte
+	seen uint64
+}
+
+// mailboxHeader is the sequence counter plus the payload length
+const mailboxHeader = 16
+
+// Mailbox returns a view of one half of the segment
+func (s *Segment) Mailbox(index int) *Mailbox {
+	half := len(s.data) / 2
+	return &Mailbox{buf: s.data[index*half : (index+1)*half]}
+}
+
+// seqPtr aliases the sequence counter for atomic access; the mapping
+// is page-aligned and each half starts on an 8-byte boundary
+func (m *Mailbox) seqPtr() *uint64 {
+	return (*uint64)(unsafe.Pointer(&m.buf[0]))
+}
+
+// Post publishes a message, overwriting any unconsumed predecessor.
+// The payload is written before the sequence bump so a receiver never
+// observes a torn frame.
+func (m *Mailbox) Post(payload []byte) error {
+	if len(payload) > len(m.buf)-mailboxHeader {
+		return fmt.Errorf("message of %d bytes exceeds mailbox capacity %d", len(payload), len(m.buf)-mailboxHeader)
+	}
+	binary.LittleEndian.PutUint64(m.buf[8:mailboxHeader], uint64(len(payload)))
+	copy(m.buf[mailboxHeader:], payload)
+	atomic.AddUint64(m.seqPtr(), 1)
+	return nil
+}
+
+// Poll returns the latest unseen message, if any
+func (m *Mailbox) Poll() ([]byte, bool) {
+	seq := atomic.LoadUint64(m.seqPtr())
+	if seq == m.seen {
+		return nil, false
+	}
+	m.seen = seq
+	n := binary.LittleEndian.Uint64(m.buf[8:mailboxHeader])
+	payload := make([]byte, n)
+	copy(payload, m.buf[mailboxHeader:mailboxHeader+n])
+	return payload, true
+}
+
+// Wait polls until a message arrives or the context ends
+func (m *Mailbox) Wait(ctx context.Context) ([]byte, error) {
+	for {
+		if payload, ok := m.Poll(); ok {
+			return payload, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
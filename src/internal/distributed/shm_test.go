@@ -0,0 +1,106 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSegmentRoundTrip(t *testing.T) {
+	seg, err := CreateSegment(t.TempDir(), DefaultSegmentSize)
+	if err != nil {
+		t.Fatalf("CreateSegment failed: %v", err)
+	}
+	defer seg.Close()
+
+	peer, err := OpenSegment(seg.Path(), DefaultSegmentSize)
+	if err != nil {
+		t.Fatalf("OpenSegment failed: %v", err)
+	}
+	defer peer.Close()
+
+	// Coordinator-to-worker direction
+	if err := seg.Mailbox(0).Post([]byte("request")); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	got, ok := peer.Mailbox(0).Poll()
+	if !ok || !bytes.Equal(got, []byte("request")) {
+		t.Errorf("Poll = %q, %v, want \"request\"", got, ok)
+	}
+
+	// Worker-to-coordinator direction is independent
+	if err := peer.Mailbox(1).Post([]byte("reply")); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	got, ok = seg.Mailbox(1).Poll()
+	if !ok || !bytes.Equal(got, []byte("reply")) {
+		t.Errorf("Poll = %q, %v, want \"reply\"", got, ok)
+	}
+}
+
+func TestMailboxPollOncePerMessage(t *testing.T) {
+	seg, err := CreateSegment(t.TempDir(), DefaultSegmentSize)
+	if err != nil {
+		t.Fatalf("CreateSegment failed: %v", err)
+	}
+	defer seg.Close()
+
+	box := seg.Mailbox(0)
+	reader := seg.Mailbox(0)
+
+	if _, ok := reader.Poll(); ok {
+		t.Error("Poll returned a message from an empty mailbox")
+	}
+	if err := box.Post([]byte("one")); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if _, ok := reader.Poll(); !ok {
+		t.Fatal("Poll missed a posted message")
+	}
+	if _, ok := reader.Poll(); ok {
+		t.Error("Poll returned the same message twice")
+	}
+}
+
+func TestMailboxPostTooLarge(t *testing.T) {
+	seg, err := CreateSegment(t.TempDir(), 4096)
+	if err != nil {
+		t.Fatalf("CreateSegment failed: %v", err)
+	}
+	defer seg.Close()
+
+	if err := seg.Mailbox(0).Post(make([]byte, 4096)); err == nil {
+		t.Error("Post accepted a payload larger than the mailbox")
+	}
+}
+
+func TestMailboxWait(t *testing.T) {
+	seg, err := CreateSegment(t.TempDir(), DefaultSegmentSize)
+	if err != nil {
+		t.Fatalf("CreateSegment failed: %v", err)
+	}
+	defer seg.Close()
+
+	writer := seg.Mailbox(0)
+	reader := seg.Mailbox(0)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		writer.Post([]byte("late"))
+	}()
+
+	got, err := reader.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if !bytes.Equal(got, []byte("late")) {
+		t.Errorf("Wait = %q, want \"late\"", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := reader.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+}
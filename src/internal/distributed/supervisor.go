@@ -0,0 +1,274 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRestarts caps engine respawns before the supervisor
+	// gives up and leaves the server degraded
+	DefaultMaxRestarts = 5
+	// DefaultRestartBackoff spaces respawns so a crash loop cannot
+	// spin the CPU
+	DefaultRestartBackoff = time.Second
+)
+
+// SupervisorConfig tunes the engine restart policy
+type SupervisorConfig struct {
+	MaxRestarts    int
+	RestartBackoff time.Duration
+}
+
+// engineLaunch starts the engine worker and reports its exit; replaced
+// in tests to run the engine in-process
+type engineLaunch func(shmPath string) (stop func(), exited <-chan error, err error)
+
+// Supervisor keeps one isolated MLX engine process alive. A crash
+// kills only the worker; the supervisor respawns it while the HTTP
+// server and its streams keep running.
+type Supervisor struct {
+	cfg          SupervisorConfig
+	launch       engineLaunch
+	readyTimeout time.Duration
+
+	mu         sync.Mutex
+	seg        *Segment
+	requests   *Mailbox
+	replies    *Mailbox
+	stopWorker func()
+	restarts   int
+	healthy    bool
+	stopped    bool
+	done       chan struct{}
+	watchDone  chan struct{}
+	inflight   map[string]*inflightEntry
+}
+
+// inflightEntry is one tracked request and whether it has been
+// replayed on a restarted worker
+type inflightEntry struct {
+	req       InflightRequest
+	restarted bool
+}
+
+// NewSupervisor applies restart-policy defaults
+func NewSupervisor(cfg SupervisorConfig) *Supervisor {
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = DefaultMaxRestarts
+	}
+	if cfg.RestartBackoff <= 0 {
+		cfg.RestartBackoff = DefaultRestartBackoff
+	}
+	return &Supervisor{
+		cfg:          cfg,
+		launch:       spawnEngine,
+		readyTimeout: DefaultReadyTimeout,
+	}
+}
+
+// Start launches the engine worker and begins supervising it
+func (s *Supervisor) Start() error {
+	s.done = make(chan struct{})
+	s.watchDone = make(chan struct{})
+	exited, err := s.startWorker()
+	if err != nil {
+		return err
+	}
+	go s.watch(exited)
+	return nil
+}
+
+// startWorker spawns one engine process and waits for its ready frame
+func (s *Supervisor) startWorker() (<-chan error, error) {
+	seg, err := CreateSegment("", DefaultSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stop, exited, err := s.launch(seg.Path())
+	if err != nil {
+		seg.Close()
+		return nil, fmt.Errorf("failed to launch MLX engine worker: %w", err)
+	}
+
+	replies := seg.Mailbox(1)
+	ctx, cancel := context.WithTimeout(context.Background(), s.readyTimeout)
+	defer cancel()
+	payload, err := replies.Wait(ctx)
+	if err != nil {
+		stop()
+		seg.Close()
+		return nil, fmt.Errorf("MLX engine worker did not become ready: %w", err)
+	}
+	var msg message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Op != "ready" {
+		stop()
+		seg.Close()
+		return nil, fmt.Errorf("MLX engine worker sent %q instead of ready", payload)
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		// Stop ran while the worker was coming up; tear it back down
+		// rather than hand the supervisor a worker nobody will stop
+		s.mu.Unlock()
+		stop()
+		seg.Close()
+		return nil, fmt.Errorf("supervisor stopped")
+	}
+	s.seg = seg
+	s.requests = seg.Mailbox(0)
+	s.replies = replies
+	s.stopWorker = stop
+	s.healthy = true
+	s.mu.Unlock()
+	return exited, nil
+}
+
+// watch respawns the worker whenever it exits unexpectedly
+func (s *Supervisor) watch(exited <-chan error) {
+	defer close(s.watchDone)
+	for {
+		select {
+		case <-s.done:
+			return
+		case err := <-exited:
+			s.mu.Lock()
+			if s.stopped {
+				s.mu.Unlock()
+				return
+			}
+			s.healthy = false
+			// Drop every reference into the dead worker's mapping before
+			// unmapping it; the next startWorker may reuse the address
+			s.seg.Close()
+			s.seg = nil
+			s.requests, s.replies = nil, nil
+			s.stopWorker = nil
+			if s.restarts >= s.cfg.MaxRestarts {
+				s.mu.Unlock()
+				log.Printf("MLX engine worker exited (%v); restart limit of %d reached, giving up", err, s.cfg.MaxRestarts)
+				return
+			}
+			s.restarts++
+			attempt := s.restarts
+			s.mu.Unlock()
+
+			log.Printf("MLX engine worker exited (%v); restarting (%d/%d)", err, attempt, s.cfg.MaxRestarts)
+			time.Sleep(s.cfg.RestartBackoff)
+
+			next, startErr := s.startWorker()
+			if startErr != nil {
+				log.Printf("Failed to restart MLX engine worker: %v", startErr)
+				return
+			}
+			s.replayInflight()
+			exited = next
+		}
+	}
+}
+
+// Ping round-trips a control frame through the engine worker
+func (s *Supervisor) Ping() error {
+	s.mu.Lock()
+	requests, replies, healthy := s.requests, s.replies, s.healthy
+	s.mu.Unlock()
+	if !healthy {
+		return fmt.Errorf("MLX engine worker is down")
+	}
+
+	if err := requests.Post(encode(message{Op: "ping"})); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.readyTimeout)
+	defer cancel()
+	payload, err := replies.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("MLX engine worker unresponsive: %w", err)
+	}
+	var msg message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Op != "pong" {
+		return fmt.Errorf("MLX engine worker replied %q to ping", payload)
+	}
+	return nil
+}
+
+// Healthy reports whether the engine worker is up
+func (s *Supervisor) Healthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.healthy
+}
+
+// Restarts returns how many times the worker has been respawned
+func (s *Supervisor) Restarts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restarts
+}
+
+// Stop shuts the worker down without triggering a restart
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	s.healthy = false
+	s.mu.Unlock()
+
+	// Join the restart loop before touching the segment so a respawn
+	// cannot race the unmap below
+	if s.done != nil {
+		close(s.done)
+	}
+	if s.watchDone != nil {
+		<-s.watchDone
+	}
+
+	s.mu.Lock()
+	requests, stop, seg := s.requests, s.stopWorker, s.seg
+	s.requests, s.replies = nil, nil
+	s.stopWorker = nil
+	s.seg = nil
+	s.mu.Unlock()
+
+	if requests != nil {
+		requests.Post(encode(message{Op: "shutdown"}))
+	}
+	if stop != nil {
+		stop()
+	}
+	if seg != nil {
+		seg.Close()
+	}
+}
+
+// spawnEngine re-executes the server binary as the engine worker
+func spawnEngine(shmPath string) (func(), <-chan error, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.Command(exe, "engine-worker", "--shm", shmPath)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	stop := func() {
+		cmd.Process.Kill()
+		<-exited
+	}
+	return stop, exited, nil
+}
@@ -0,0 +1,122 @@
+package distributed
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// inProcessSupervisor runs the engine as a goroutine per launch so the
+// restart logic can be exercised without spawning processes
+func inProcessSupervisor(cfg SupervisorConfig) *Supervisor {
+	s := NewSupervisor(cfg)
+	s.readyTimeout = time.Second
+	s.launch = func(shmPath string) (func(), <-chan error, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		exited := make(chan error, 1)
+		go func() { exited <- RunEngine(ctx, shmPath) }()
+		stop := func() {
+			cancel()
+		}
+		return stop, exited, nil
+	}
+	return s
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSupervisorStartPingStop(t *testing.T) {
+	s := inProcessSupervisor(SupervisorConfig{RestartBackoff: time.Millisecond})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !s.Healthy() {
+		t.Error("Healthy = false after Start")
+	}
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	s.Stop()
+	if s.Healthy() {
+		t.Error("Healthy = true after Stop")
+	}
+	if err := s.Ping(); err == nil {
+		t.Error("Ping succeeded after Stop")
+	}
+	if got := s.Restarts(); got != 0 {
+		t.Errorf("Restarts = %d, want 0 for a clean shutdown", got)
+	}
+}
+
+func TestSupervisorRestartsCrashedWorker(t *testing.T) {
+	s := inProcessSupervisor(SupervisorConfig{RestartBackoff: time.Millisecond})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Stop()
+
+	// A shutdown frame makes the engine exit without the supervisor
+	// stopping, which is indistinguishable from a crash
+	s.mu.Lock()
+	requests := s.requests
+	s.mu.Unlock()
+	requests.Post(encode(message{Op: "shutdown"}))
+
+	waitFor(t, "restart", func() bool { return s.Restarts() == 1 && s.Healthy() })
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping after restart failed: %v", err)
+	}
+}
+
+func TestSupervisorGivesUpAfterMaxRestarts(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{MaxRestarts: 2, RestartBackoff: time.Millisecond})
+	s.readyTimeout = time.Second
+	// A worker that reports ready, then dies immediately
+	s.launch = func(shmPath string) (func(), <-chan error, error) {
+		exited := make(chan error, 1)
+		go func() {
+			seg, err := OpenSegment(shmPath, DefaultSegmentSize)
+			if err != nil {
+				exited <- err
+				return
+			}
+			seg.Mailbox(1).Post(encode(message{Op: "ready"}))
+			seg.Close()
+			exited <- nil
+		}()
+		return func() {}, exited, nil
+	}
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Stop()
+
+	waitFor(t, "restart limit", func() bool { return s.Restarts() == 2 })
+	waitFor(t, "unhealthy", func() bool { return !s.Healthy() })
+}
+
+func TestSupervisorStartFailsWithoutReady(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{})
+	s.readyTimeout = 10 * time.Millisecond
+	s.launch = func(string) (func(), <-chan error, error) {
+		return func() {}, make(chan error, 1), nil
+	}
+
+	if err := s.Start(); err == nil {
+		t.Fatal("Start succeeded without a ready worker")
+	}
+	if s.Healthy() {
+		t.Error("Healthy = true after a failed Start")
+	}
+}
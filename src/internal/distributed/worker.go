@@ -0,0 +1,71 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// message is the control frame exchanged over the mailboxes. Tensor
+// payloads will travel in dedicated regions once the MLX shard kernels
+// land; control stays JSON for debuggability.
+type message struct {
+	Op     string `json:"op"`
+	Layers string `json:"layers,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// ID and Tokens carry prefill replays after an engine restart
+	ID     string `json:"id,omitempty"`
+	Tokens []int  `json:"tokens,omitempty"`
+}
+
+func encode(msg message) []byte {
+	b, _ := json.Marshal(msg)
+	return b
+}
+
+// RunWorker serves one layer shard: attach to the coordinator's
+// segment, announce readiness, then answer control frames until a
+// shutdown message or context cancellation.
+func RunWorker(ctx context.Context, shmPath string, shard LayerRange) error {
+	seg, err := OpenSegment(shmPath, DefaultSegmentSize)
+	if err != nil {
+		return err
+	}
+	defer seg.Close()
+
+	requests := seg.Mailbox(0)
+	replies := seg.Mailbox(1)
+
+	// TODO: load the shard's weights into this process's MLX context
+	if err := replies.Post(encode(message{Op: "ready", Layers: shard.String()})); err != nil {
+		return err
+	}
+
+	for {
+		payload, err := requests.Wait(ctx)
+		if err != nil {
+			// Cancellation is the normal stop path when the coordinator
+			// dies without a shutdown frame
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			replies.Post(encode(message{Op: "error", Error: fmt.Sprintf("bad frame: %v", err)}))
+			continue
+		}
+
+		switch msg.Op {
+		case "ping":
+			replies.Post(encode(message{Op: "pong", Layers: shard.String()}))
+		case "shutdown":
+			replies.Post(encode(message{Op: "bye"}))
+			return nil
+		default:
+			replies.Post(encode(message{Op: "error", Error: fmt.Sprintf("unknown op %q", msg.Op)}))
+		}
+	}
+}
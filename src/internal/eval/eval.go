@@ -0,0 +1,226 @@
+// Package eval runs ScreenSpot-style grounding benchmarks against a
+// running server: each case pairs a screenshot and an instruction with
+// a gold bounding box, and a prediction counts as correct when the
+// predicted point falls inside the box.
+package eval
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+	"github.com/agenthands/mlxvllm/internal/bench"
+)
+
+// Box is a gold bounding box in normalized [0,1] coordinates
+type Box struct {
+	X0 float64 `json:"x0"`
+	Y0 float64 `json:"y0"`
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+}
+
+// Contains reports whether the point lies inside the box (inclusive)
+func (b Box) Contains(x, y float64) bool {
+	return x >= b.X0 && x <= b.X1 && y >= b.Y0 && y <= b.Y1
+}
+
+// Case is one benchmark sample from the manifest
+type Case struct {
+	// Image is the screenshot path, relative to the manifest file
+	Image       string `json:"image"`
+	Instruction string `json:"instruction"`
+	Box         Box    `json:"box"`
+}
+
+// Options configures an evaluation run
+type Options struct {
+	// ServerURL is the base URL, e.g. http://localhost:8080
+	ServerURL string
+	// Model to evaluate
+	Model string
+	// Concurrency is the number of parallel workers (default 1)
+	Concurrency int
+	// Client overrides the HTTP client (tests)
+	Client *http.Client
+}
+
+// CaseResult records the outcome of one case
+type CaseResult struct {
+	Index       int     `json:"index"`
+	Instruction string  `json:"instruction"`
+	Correct     bool    `json:"correct"`
+	PredictedX  float64 `json:"predicted_x"`
+	PredictedY  float64 `json:"predicted_y"`
+	LatencyMS   int64   `json:"latency_ms"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Report aggregates an evaluation run; Accuracy is over the cases that
+// completed without a request error
+type Report struct {
+	Model        string       `json:"model"`
+	Cases        int          `json:"cases"`
+	Errors       int          `json:"errors"`
+	Correct      int          `json:"correct"`
+	Accuracy     float64      `json:"accuracy"`
+	LatencyP50MS int64        `json:"latency_p50_ms"`
+	LatencyP95MS int64        `json:"latency_p95_ms"`
+	LatencyP99MS int64        `json:"latency_p99_ms"`
+	Results      []CaseResult `json:"results"`
+}
+
+// LoadManifest reads a benchmark manifest (a JSON array of cases) and
+// resolves image paths relative to the manifest location
+func LoadManifest(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var cases []Case
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("manifest has no cases")
+	}
+
+	dir := filepath.Dir(path)
+	for i := range cases {
+		if cases[i].Image == "" {
+			return nil, fmt.Errorf("case %d has no image", i)
+		}
+		if !filepath.IsAbs(cases[i].Image) {
+			cases[i].Image = filepath.Join(dir, cases[i].Image)
+		}
+	}
+	return cases, nil
+}
+
+// Run evaluates all cases at the configured concurrency
+func Run(opts Options, cases []Case) (*Report, error) {
+	if opts.ServerURL == "" {
+		return nil, fmt.Errorf("server URL required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 120 * time.Second}
+	}
+
+	report := &Report{
+		Model:   opts.Model,
+		Cases:   len(cases),
+		Results: make([]CaseResult, len(cases)),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				report.Results[j] = runCase(client, opts, j, cases[j])
+			}
+		}()
+	}
+	for j := range cases {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	var latencies []time.Duration
+	for _, res := range report.Results {
+		if res.Error != "" {
+			report.Errors++
+			continue
+		}
+		if res.Correct {
+			report.Correct++
+		}
+		latencies = append(latencies, time.Duration(res.LatencyMS)*time.Millisecond)
+	}
+	if scored := report.Cases - report.Errors; scored > 0 {
+		report.Accuracy = float64(report.Correct) / float64(scored)
+	}
+	report.LatencyP50MS = bench.Percentile(latencies, 50).Milliseconds()
+	report.LatencyP95MS = bench.Percentile(latencies, 95).Milliseconds()
+	report.LatencyP99MS = bench.Percentile(latencies, 99).Milliseconds()
+
+	return report, nil
+}
+
+// runCase sends one grounding request and scores it against the box
+func runCase(client *http.Client, opts Options, index int, c Case) CaseResult {
+	result := CaseResult{Index: index, Instruction: c.Instruction}
+
+	img, err := os.ReadFile(c.Image)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	reqBody, err := json.Marshal(api.ChatCompletionRequest{
+		Model: opts.Model,
+		Messages: []api.Message{
+			{Role: "user", Content: []api.ContentPart{
+				{Type: "image_url", ImageURL: &api.ImageURL{URL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(img)}},
+				{Type: "text", Text: c.Instruction},
+			}},
+		},
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	resp, err := client.Post(opts.ServerURL+"/v1/chat/completions", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	result.LatencyMS = time.Since(start).Milliseconds()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Sprintf("server returned %d", resp.StatusCode)
+		return result
+	}
+
+	var completion api.ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if len(completion.Choices) == 0 || completion.Choices[0].Coordinates == nil {
+		result.Error = "response has no coordinates"
+		return result
+	}
+
+	point := completion.Choices[0].Coordinates
+	result.PredictedX, result.PredictedY = point.X, point.Y
+	result.Correct = c.Box.Contains(point.X, point.Y)
+	return result
+}
+
+// WriteReport writes the JSON report to path
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
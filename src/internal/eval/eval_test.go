@@ -0,0 +1,196 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeGroundingServer always predicts the given point
+func fakeGroundingServer(t *testing.T, x, y float64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop","coordinates":{"x":%f,"y":%f}}]}`, x, y)
+	}))
+}
+
+// writeManifest creates a manifest with n cases sharing one synthetic
+// screenshot; boxes[i] is the gold box of case i
+func writeManifest(t *testing.T, boxes []Box) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	img := image.NewRGBA(image.Rect(0, 0, 56, 56))
+	for y := 0; y < 56; y++ {
+		for x := 0; x < 56; x++ {
+			img.Set(x, y, color.RGBA{128, 128, 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "shot.png"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := make([]Case, len(boxes))
+	for i, box := range boxes {
+		cases[i] = Case{
+			Image:       "shot.png",
+			Instruction: fmt.Sprintf("click element %d", i),
+			Box:         box,
+		}
+	}
+	data, err := json.Marshal(cases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBoxContains(t *testing.T) {
+	box := Box{X0: 0.4, Y0: 0.4, X1: 0.6, Y1: 0.6}
+	tests := []struct {
+		name string
+		x, y float64
+		want bool
+	}{
+		{"center", 0.5, 0.5, true},
+		{"edge inclusive", 0.4, 0.6, true},
+		{"outside x", 0.7, 0.5, false},
+		{"outside y", 0.5, 0.3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := box.Contains(tt.x, tt.y); got != tt.want {
+				t.Errorf("Contains(%f, %f) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadManifestResolvesPaths(t *testing.T) {
+	path := writeManifest(t, []Box{{X0: 0, Y0: 0, X1: 1, Y1: 1}})
+
+	cases, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("got %d cases, want 1", len(cases))
+	}
+	if !filepath.IsAbs(cases[0].Image) {
+		t.Errorf("image path %q not resolved against the manifest dir", cases[0].Image)
+	}
+	if _, err := os.Stat(cases[0].Image); err != nil {
+		t.Errorf("resolved image path does not exist: %v", err)
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	if _, err := LoadManifest(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for a missing manifest")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(empty, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifest(empty); err == nil {
+		t.Error("expected error for a manifest without cases")
+	}
+}
+
+func TestRunScoresPointInBox(t *testing.T) {
+	srv := fakeGroundingServer(t, 0.5, 0.5)
+	defer srv.Close()
+
+	// Two boxes contain the fixed (0.5, 0.5) prediction, one does not
+	manifest := writeManifest(t, []Box{
+		{X0: 0.4, Y0: 0.4, X1: 0.6, Y1: 0.6},
+		{X0: 0.0, Y0: 0.0, X1: 1.0, Y1: 1.0},
+		{X0: 0.8, Y0: 0.8, X1: 0.9, Y1: 0.9},
+	})
+	cases, err := LoadManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{ServerURL: srv.URL, Model: "gui-actor-2b", Concurrency: 2}, cases)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Errors != 0 {
+		t.Fatalf("Errors = %d: %+v", report.Errors, report.Results)
+	}
+	if report.Correct != 2 {
+		t.Errorf("Correct = %d, want 2", report.Correct)
+	}
+	if want := 2.0 / 3.0; report.Accuracy != want {
+		t.Errorf("Accuracy = %f, want %f", report.Accuracy, want)
+	}
+	for i, res := range report.Results {
+		if res.Index != i {
+			t.Errorf("result %d has index %d", i, res.Index)
+		}
+		if res.PredictedX != 0.5 || res.PredictedY != 0.5 {
+			t.Errorf("result %d predicted (%f, %f), want (0.5, 0.5)", i, res.PredictedX, res.PredictedY)
+		}
+	}
+}
+
+func TestRunCountsServerErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	manifest := writeManifest(t, []Box{{X0: 0, Y0: 0, X1: 1, Y1: 1}})
+	cases, _ := LoadManifest(manifest)
+
+	report, err := Run(Options{ServerURL: srv.URL}, cases)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Errors != 1 || report.Accuracy != 0 {
+		t.Errorf("Errors=%d Accuracy=%f, want 1/0", report.Errors, report.Accuracy)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	report := &Report{Model: "gui-actor-2b", Cases: 1, Correct: 1, Accuracy: 1}
+
+	if err := WriteReport(path, report); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if got.Accuracy != 1 || got.Model != "gui-actor-2b" {
+		t.Errorf("round-tripped report = %+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package guardrail post-processes generated actions before they are
+// returned to clients. Deployments register hooks that inspect, rewrite
+// or block output — e.g. refusing destructive keystroke sequences or
+// clicks outside an allowed screen region — and can scope which hooks
+// apply to each API key.
+package guardrail
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Action is the generated output under review. Hooks may modify it in
+// place; coordinates are normalized to [0,1].
+type Action struct {
+	Model    string
+	Content  string
+	X, Y     float64
+	HasPoint bool
+}
+
+// Hook inspects one generated action. Returning an error blocks the
+// response; mutating the action rewrites it.
+type Hook interface {
+	Name() string
+	Inspect(a *Action) error
+}
+
+// Registry holds named hooks and decides which apply to each API key.
+type Registry struct {
+	mu     sync.RWMutex
+	hooks  map[string]Hook
+	order  []string
+	perKey map[string][]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		hooks:  make(map[string]Hook),
+		perKey: make(map[string][]string),
+	}
+}
+
+// Register adds a hook. Hooks run in registration order; registering a
+// name twice replaces the earlier hook in place.
+func (r *Registry) Register(h Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.hooks[h.Name()]; !exists {
+		r.order = append(r.order, h.Name())
+	}
+	r.hooks[h.Name()] = h
+}
+
+// SetKeyHooks restricts an API key to the named hooks. Keys without an
+// entry get every registered hook; an empty list disables all hooks for
+// that key.
+func (r *Registry) SetKeyHooks(apiKey string, names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.perKey[apiKey] = names
+}
+
+// Apply runs the hooks configured for apiKey against the action. The
+// first hook to object wins, and its name is included in the returned
+// error.
+func (r *Registry) Apply(apiKey string, a *Action) error {
+	r.mu.RLock()
+	names := r.order
+	if scoped, ok := r.perKey[apiKey]; ok {
+		names = scoped
+	}
+	hooks := make([]Hook, 0, len(names))
+	for _, name := range names {
+		if h, ok := r.hooks[name]; ok {
+			hooks = append(hooks, h)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		if err := h.Inspect(a); err != nil {
+			return fmt.Errorf("blocked by guardrail %q: %w", h.Name(), err)
+		}
+	}
+	return nil
+}
+
+// BlockedPatterns rejects actions whose content matches any of a set of
+// regular expressions, e.g. `rm -rf` keystroke sequences.
+type BlockedPatterns struct {
+	name     string
+	patterns []*regexp.Regexp
+}
+
+// NewBlockedPatterns compiles the patterns; an invalid expression fails
+// the whole hook so misconfigurations surface at startup.
+func NewBlockedPatterns(name string, patterns []string) (*BlockedPatterns, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", p, err)
+		}
+		compiled[i] = re
+	}
+	return &BlockedPatterns{name: name, patterns: compiled}, nil
+}
+
+func (b *BlockedPatterns) Name() string { return b.name }
+
+func (b *BlockedPatterns) Inspect(a *Action) error {
+	for _, re := range b.patterns {
+		if re.MatchString(a.Content) {
+			return fmt.Errorf("content matches blocked pattern %q", re.String())
+		}
+	}
+	return nil
+}
+
+// AllowedRegion rejects click actions outside a normalized screen
+// rectangle. Actions without coordinates pass.
+type AllowedRegion struct {
+	name                   string
+	minX, minY, maxX, maxY float64
+}
+
+func NewAllowedRegion(name string, minX, minY, maxX, maxY float64) *AllowedRegion {
+	return &AllowedRegion{name: name, minX: minX, minY: minY, maxX: maxX, maxY: maxY}
+}
+
+func (g *AllowedRegion) Name() string { return g.name }
+
+func (g *AllowedRegion) Inspect(a *Action) error {
+	if !a.HasPoint {
+		return nil
+	}
+	if a.X < g.minX || a.X > g.maxX || a.Y < g.minY || a.Y > g.maxY {
+		return fmt.Errorf("click (%.3f, %.3f) outside allowed region [%.3f,%.3f]x[%.3f,%.3f]",
+			a.X, a.Y, g.minX, g.maxX, g.minY, g.maxY)
+	}
+	return nil
+}
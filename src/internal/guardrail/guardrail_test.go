@@ -0,0 +1,136 @@
+package guardrail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// rewriteHook uppercases content; used to verify modification flows
+type rewriteHook struct{}
+
+func (rewriteHook) Name() string { return "rewrite" }
+func (rewriteHook) Inspect(a *Action) error {
+	a.Content = strings.ToUpper(a.Content)
+	return nil
+}
+
+// denyHook blocks everything
+type denyHook struct{}
+
+func (denyHook) Name() string          { return "deny" }
+func (denyHook) Inspect(*Action) error { return errors.New("nope") }
+
+func TestBlockedPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		blocked bool
+	}{
+		{"destructive keystrokes", `pyautogui.typewrite("rm -rf /")`, true},
+		{"harmless click", "pyautogui.click(0.5, 0.5)", false},
+		{"partial match", "echo rm -rf is dangerous", true},
+	}
+
+	hook, err := NewBlockedPatterns("danger", []string{`rm\s+-rf`})
+	if err != nil {
+		t.Fatalf("NewBlockedPatterns: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := hook.Inspect(&Action{Content: tt.content})
+			if (err != nil) != tt.blocked {
+				t.Errorf("Inspect(%q) err = %v, want blocked = %v", tt.content, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestNewBlockedPatternsInvalidRegex(t *testing.T) {
+	if _, err := NewBlockedPatterns("bad", []string{"["}); err == nil {
+		t.Error("NewBlockedPatterns accepted an invalid expression")
+	}
+}
+
+func TestAllowedRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		x, y     float64
+		hasPoint bool
+		blocked  bool
+	}{
+		{"inside", 0.5, 0.5, true, false},
+		{"left of region", 0.05, 0.5, true, true},
+		{"below region", 0.5, 0.95, true, true},
+		{"on boundary", 0.1, 0.1, true, false},
+		{"no coordinates", 0, 0, false, false},
+	}
+
+	hook := NewAllowedRegion("region", 0.1, 0.1, 0.9, 0.9)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := hook.Inspect(&Action{X: tt.x, Y: tt.y, HasPoint: tt.hasPoint})
+			if (err != nil) != tt.blocked {
+				t.Errorf("Inspect(%.2f, %.2f) err = %v, want blocked = %v", tt.x, tt.y, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestRegistryApplyNamesBlockingHook(t *testing.T) {
+	r := NewRegistry()
+	r.Register(denyHook{})
+
+	err := r.Apply("sk-lab-1", &Action{Content: "anything"})
+	if err == nil {
+		t.Fatal("Apply did not block")
+	}
+	if !strings.Contains(err.Error(), `guardrail "deny"`) {
+		t.Errorf("error %q does not name the blocking hook", err)
+	}
+}
+
+func TestRegistryApplyAllowsModification(t *testing.T) {
+	r := NewRegistry()
+	r.Register(rewriteHook{})
+
+	a := &Action{Content: "click"}
+	if err := r.Apply("sk-lab-1", a); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if a.Content != "CLICK" {
+		t.Errorf("Content = %q, want rewrite applied", a.Content)
+	}
+}
+
+func TestRegistryPerKeyScoping(t *testing.T) {
+	r := NewRegistry()
+	r.Register(denyHook{})
+	r.SetKeyHooks("sk-trusted", []string{})
+
+	// Unlisted keys get every hook
+	if err := r.Apply("sk-lab-1", &Action{}); err == nil {
+		t.Error("Apply did not block for an unscoped key")
+	}
+	// The trusted key opted out of all hooks
+	if err := r.Apply("sk-trusted", &Action{}); err != nil {
+		t.Errorf("Apply blocked the exempted key: %v", err)
+	}
+}
+
+func TestRegistryReRegisterReplaces(t *testing.T) {
+	r := NewRegistry()
+	hook, _ := NewBlockedPatterns("danger", []string{"foo"})
+	r.Register(hook)
+	hook, _ = NewBlockedPatterns("danger", []string{"bar"})
+	r.Register(hook)
+
+	if err := r.Apply("k", &Action{Content: "foo"}); err != nil {
+		t.Errorf("Apply still uses the replaced hook: %v", err)
+	}
+	if err := r.Apply("k", &Action{Content: "bar"}); err == nil {
+		t.Error("Apply missed the replacement hook")
+	}
+}
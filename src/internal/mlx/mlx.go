@@ -39,3 +39,14 @@ func GetDefaultDevice() string {
 	C.mlx_get_default_device((*C.char)(unsafe.Pointer(&buf[0])), 32)
 	return string(buf)
 }
+
+// Revalidate checks the runtime after a system wake. Metal command
+// queues and buffers can be invalidated while the machine sleeps, so a
+// torn-down context is reinitialized rather than left to fail the next
+// inference.
+func Revalidate() error {
+	if IsInitialized() {
+		return nil
+	}
+	return Init()
+}
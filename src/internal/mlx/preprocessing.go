@@ -2,87 +2,36 @@ package mlx
 
 import (
 	"image"
-	"math"
+
+	"github.com/agenthands/mlxvllm/pkg/vision"
 )
 
 const (
-	MergePatchSize = 28
+	MergePatchSize = vision.MergePatchSize
 )
 
 // SmartResize resizes image to fit within [minPixels, maxPixels]
-// while maintaining aspect ratio and aligning to MergePatchSize
+// while maintaining aspect ratio and aligning to MergePatchSize.
+// The implementation lives in pkg/vision, which is shared with the
+// ONNX backend so both produce identical model inputs.
 func SmartResize(img image.Image, minPixels, maxPixels int) (image.Image, error) {
-	bounds := img.Bounds()
-	w, h := bounds.Dx(), bounds.Dy()
-	currentPixels := w * h
-
-	// If already in range, ensure grid alignment
-	if currentPixels >= minPixels && currentPixels <= maxPixels {
-		return alignToGrid(img)
-	}
-
-	// Calculate scale factor
-	scale := 1.0
-	if currentPixels < minPixels {
-		scale = math.Sqrt(float64(minPixels) / float64(currentPixels))
-	} else if currentPixels > maxPixels {
-		scale = math.Sqrt(float64(maxPixels) / float64(currentPixels))
-	}
-
-	newW := int(math.Round(float64(w) * scale))
-	newH := int(math.Round(float64(h) * scale))
-
-	// Align to grid size
-	newW = (newW / MergePatchSize) * MergePatchSize
-	newH = (newH / MergePatchSize) * MergePatchSize
-
-	// Ensure minimum size
-	if newW < MergePatchSize {
-		newW = MergePatchSize
-	}
-	if newH < MergePatchSize {
-		newH = MergePatchSize
-	}
-
-	return resizeImage(img, newW, newH)
+	return vision.SmartResize(img, minPixels, maxPixels)
 }
 
 // CalculateGrid returns the grid dimensions for patch processing
 func CalculateGrid(w, h int) (int, int) {
-	return w / MergePatchSize, h / MergePatchSize
+	return vision.CalculateGrid(w, h)
 }
 
-func alignToGrid(img image.Image) (image.Image, error) {
-	bounds := img.Bounds()
-	w, h := bounds.Dx(), bounds.Dy()
-	gridW, gridH := CalculateGrid(w, h)
-	newW := gridW * MergePatchSize
-	newH := gridH * MergePatchSize
-
-	// Ensure minimum size after grid alignment
-	if newW < MergePatchSize {
-		newW = MergePatchSize
-	}
-	if newH < MergePatchSize {
-		newH = MergePatchSize
-	}
-
-	return resizeImage(img, newW, newH)
+// ShouldTile reports whether fitting a w x h image into maxPixels
+// would lose enough detail that the tiled path should run instead
+func ShouldTile(w, h, maxPixels int) bool {
+	return vision.ShouldTile(w, h, maxPixels)
 }
 
-func resizeImage(img image.Image, w, h int) (image.Image, error) {
-	// Simple bilinear resize implementation
-	// In production, use a dedicated imaging library
-	dst := image.NewRGBA(image.Rect(0, 0, w, h))
-	srcBounds := img.Bounds()
-
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			srcX := x * srcBounds.Dx() / w
-			srcY := y * srcBounds.Dy() / h
-			dst.Set(x, y, img.At(srcBounds.Min.X+srcX, srcBounds.Min.Y+srcY))
-		}
-	}
-
-	return dst, nil
+// PreprocessTiled splits an oversized image into overlapping tiles,
+// each run through the shared pipeline at native resolution; per-tile
+// predictions map back to the screenshot via Tile.MapToGlobal
+func PreprocessTiled(img image.Image, minPixels, maxPixels int) ([]vision.Tile, error) {
+	return vision.PreprocessTiled(img, minPixels, maxPixels)
 }
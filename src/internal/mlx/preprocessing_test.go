@@ -177,11 +177,11 @@ func TestCalculateGrid(t *testing.T) {
 		expectGW int
 		expectGH int
 	}{
-		{"112x224", 112, 224, 4, 8},   // 112/28=4, 224/28=8
-		{"224x224", 224, 224, 8, 8},   // 224/28=8
-		{"56x56", 56, 56, 2, 2},       // 56/28=2
-		{"28x28", 28, 28, 1, 1},       // Minimum
-		{"1000x500", 1000, 500, 35, 17}, // 1000/28=35, 500/28=17
+		{"112x224", 112, 224, 4, 8},      // 112/28=4, 224/28=8
+		{"224x224", 224, 224, 8, 8},      // 224/28=8
+		{"56x56", 56, 56, 2, 2},          // 56/28=2
+		{"28x28", 28, 28, 1, 1},          // Minimum
+		{"1000x500", 1000, 500, 35, 17},  // 1000/28=35, 500/28=17
 		{"odd dimensions", 99, 57, 3, 2}, // Integer division
 	}
 
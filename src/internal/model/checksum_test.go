@@ -0,0 +1,73 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/modelcache"
+)
+
+// tamperedModelDir creates a model dir whose recorded checksum no
+// longer matches the shard on disk
+func tamperedModelDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.safetensors")
+	if err := os.WriteFile(path, []byte("original weights"), 0644); err != nil {
+		t.Fatalf("Failed to write shard: %v", err)
+	}
+	if err := modelcache.RecordChecksums(dir); err != nil {
+		t.Fatalf("RecordChecksums: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("truncated"), 0644); err != nil {
+		t.Fatalf("Failed to tamper shard: %v", err)
+	}
+	return dir
+}
+
+func checksumConfig(path, policy string) *config.Config {
+	return &config.Config{
+		ChecksumPolicy: policy,
+		Models: map[string]config.ModelConfig{
+			"gui-actor-2b": {Path: path, Enabled: true},
+		},
+	}
+}
+
+func TestLoadModelChecksumStrictFailsOnMismatch(t *testing.T) {
+	reg := NewRegistry(checksumConfig(tamperedModelDir(t), "strict"))
+
+	err := loadAndWait(reg, "gui-actor-2b")
+	if err == nil {
+		t.Fatal("strict load succeeded despite a checksum mismatch")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("error = %v, want a checksum mismatch", err)
+	}
+	if _, err := reg.GetModel("gui-actor-2b"); err == nil {
+		t.Error("model is loaded despite the failed integrity check")
+	}
+}
+
+func TestLoadModelChecksumWarnContinues(t *testing.T) {
+	// The default (warn) policy logs the mismatch but still loads
+	reg := NewRegistry(checksumConfig(tamperedModelDir(t), ""))
+
+	if err := loadAndWait(reg, "gui-actor-2b"); err != nil {
+		t.Fatalf("warn-policy load failed: %v", err)
+	}
+	if _, err := reg.GetModel("gui-actor-2b"); err != nil {
+		t.Errorf("GetModel after warn-policy load: %v", err)
+	}
+}
+
+func TestLoadModelChecksumOff(t *testing.T) {
+	reg := NewRegistry(checksumConfig(tamperedModelDir(t), "off"))
+
+	if err := loadAndWait(reg, "gui-actor-2b"); err != nil {
+		t.Fatalf("load with verification off failed: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+// writeMinimalGGUF writes a GGUF header with no metadata
+func writeMinimalGGUF(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(0x46554747)) // "GGUF"
+	binary.Write(&buf, binary.LittleEndian, uint32(3))          // version
+	binary.Write(&buf, binary.LittleEndian, uint64(12))         // tensor count
+	binary.Write(&buf, binary.LittleEndian, uint64(0))          // kv count
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write gguf: %v", err)
+	}
+}
+
+func TestLoadRejectsGGUFWithConvertHint(t *testing.T) {
+	dir := t.TempDir()
+	writeMinimalGGUF(t, filepath.Join(dir, "model-q4.gguf"))
+
+	reg := NewRegistry(&config.Config{
+		Models: map[string]config.ModelConfig{
+			"dropped-in": {Path: dir, Enabled: true},
+		},
+	})
+
+	ch, err := reg.LoadModel("dropped-in")
+	if err != nil {
+		t.Fatalf("LoadModel failed: %v", err)
+	}
+	var loadErr string
+	for event := range ch {
+		if event.Error != "" {
+			loadErr = event.Error
+		}
+	}
+
+	if loadErr == "" {
+		t.Fatal("loading a GGUF checkpoint did not fail")
+	}
+	if !strings.Contains(loadErr, "GGUF") || !strings.Contains(loadErr, "mlxvllm convert") {
+		t.Errorf("error %q does not identify GGUF or point at the converter", loadErr)
+	}
+	if !strings.Contains(loadErr, "12 tensors") {
+		t.Errorf("error %q does not report header metadata", loadErr)
+	}
+}
+
+func TestGGUFCheckpointDetection(t *testing.T) {
+	dir := t.TempDir()
+	gguf := filepath.Join(dir, "model.gguf")
+	writeMinimalGGUF(t, gguf)
+
+	// Direct file path
+	if got, ok := ggufCheckpoint(gguf); !ok || got != gguf {
+		t.Errorf("ggufCheckpoint(file) = (%q, %v), want the file", got, ok)
+	}
+	// Directory containing only GGUF weights
+	if got, ok := ggufCheckpoint(dir); !ok || got != gguf {
+		t.Errorf("ggufCheckpoint(dir) = (%q, %v), want the contained file", got, ok)
+	}
+
+	// Converted weights alongside the GGUF take precedence
+	if err := os.MkdirAll(filepath.Join(dir, "bin_weights"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ggufCheckpoint(dir); ok {
+		t.Error("ggufCheckpoint flagged a directory that has bin_weights")
+	}
+
+	if _, ok := ggufCheckpoint(filepath.Join(dir, "missing")); ok {
+		t.Error("ggufCheckpoint flagged a missing path")
+	}
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func newLoadingRegistry() *Registry {
+	return NewRegistry(&config.Config{
+		Models: map[string]config.ModelConfig{
+			"gui-actor-2b": {Path: "/tmp/models/2b", Enabled: true},
+		},
+	})
+}
+
+func TestStartLoad(t *testing.T) {
+	reg := newLoadingRegistry()
+
+	started, err := reg.StartLoad("gui-actor-2b")
+	if err != nil || !started {
+		t.Fatalf("StartLoad() = (%v, %v), want (true, nil)", started, err)
+	}
+
+	// A second start joins the in-flight load instead of racing it
+	started, err = reg.StartLoad("gui-actor-2b")
+	if err != nil || started {
+		t.Errorf("second StartLoad() = (%v, %v), want (false, nil)", started, err)
+	}
+
+	if _, err := reg.StartLoad("unknown"); err == nil {
+		t.Error("StartLoad(unknown) did not fail")
+	}
+}
+
+func TestLoadStateProgressAndETA(t *testing.T) {
+	reg := newLoadingRegistry()
+
+	if loading, _, _ := reg.LoadState("gui-actor-2b"); loading {
+		t.Fatal("LoadState reports loading before StartLoad")
+	}
+
+	reg.StartLoad("gui-actor-2b")
+	loading, progress, eta := reg.LoadState("gui-actor-2b")
+	if !loading || progress != 0 {
+		t.Errorf("LoadState() = (%v, %v), want loading with zero progress", loading, progress)
+	}
+	if eta < time.Second {
+		t.Errorf("ETA = %v, want at least one second", eta)
+	}
+
+	reg.SetLoadProgress("gui-actor-2b", 0.5)
+	if _, progress, _ = reg.LoadState("gui-actor-2b"); progress != 0.5 {
+		t.Errorf("progress = %v after SetLoadProgress, want 0.5", progress)
+	}
+
+	// Status snapshots carry the load state too
+	status := reg.Status()[0]
+	if !status.Loading || status.LoadProgress != 0.5 {
+		t.Errorf("Status() = %+v, want loading at 0.5", status)
+	}
+}
+
+func TestFinishLoadSuccess(t *testing.T) {
+	reg := newLoadingRegistry()
+	reg.StartLoad("gui-actor-2b")
+	reg.FinishLoad("gui-actor-2b", nil)
+
+	if loading, _, _ := reg.LoadState("gui-actor-2b"); loading {
+		t.Error("LoadState still reports loading after FinishLoad")
+	}
+	if _, err := reg.GetModel("gui-actor-2b"); err != nil {
+		t.Errorf("GetModel after FinishLoad: %v", err)
+	}
+	if reg.UsedGB() != 4.0 {
+		t.Errorf("UsedGB() = %v, want 4.0", reg.UsedGB())
+	}
+}
+
+func TestFinishLoadFailure(t *testing.T) {
+	reg := newLoadingRegistry()
+	reg.StartLoad("gui-actor-2b")
+	reg.FinishLoad("gui-actor-2b", errors.New("weights corrupt"))
+
+	if _, err := reg.GetModel("gui-actor-2b"); err == nil {
+		t.Error("GetModel succeeded after a failed load")
+	}
+	if reg.UsedGB() != 0 {
+		t.Errorf("UsedGB() = %v after failed load, want 0", reg.UsedGB())
+	}
+}
+
+func TestAwaitLoad(t *testing.T) {
+	reg := newLoadingRegistry()
+
+	// No load in flight: returns immediately
+	if err := reg.AwaitLoad(context.Background(), "gui-actor-2b"); err != nil {
+		t.Fatalf("AwaitLoad with no load: %v", err)
+	}
+
+	reg.StartLoad("gui-actor-2b")
+
+	// Context cancellation unblocks waiters
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := reg.AwaitLoad(ctx, "gui-actor-2b"); err != context.DeadlineExceeded {
+		t.Errorf("AwaitLoad on cancelled context = %v, want deadline exceeded", err)
+	}
+
+	// Completion unblocks waiters with the load's outcome
+	loadErr := errors.New("weights corrupt")
+	done := make(chan error, 1)
+	go func() { done <- reg.AwaitLoad(context.Background(), "gui-actor-2b") }()
+	time.Sleep(10 * time.Millisecond)
+	reg.FinishLoad("gui-actor-2b", loadErr)
+
+	select {
+	case err := <-done:
+		if err != loadErr {
+			t.Errorf("AwaitLoad = %v, want the load error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("AwaitLoad never unblocked")
+	}
+}
+
+func TestLoadModelEmitsPhases(t *testing.T) {
+	reg := newLoadingRegistry()
+
+	events, err := reg.LoadModel("gui-actor-2b")
+	if err != nil {
+		t.Fatalf("LoadModel: %v", err)
+	}
+
+	var phases []string
+	var final LoadEvent
+	last := -1.0
+	for ev := range events {
+		if ev.Done {
+			final = ev
+			continue
+		}
+		phases = append(phases, ev.Phase)
+		if ev.Progress <= last {
+			t.Errorf("progress went backwards: %v after %v", ev.Progress, last)
+		}
+		last = ev.Progress
+	}
+
+	want := []string{"weights_read", "quantization", "metal_upload", "warmup"}
+	if len(phases) != len(want) {
+		t.Fatalf("phases = %v, want %v", phases, want)
+	}
+	for i, phase := range want {
+		if phases[i] != phase {
+			t.Errorf("phase[%d] = %q, want %q", i, phases[i], phase)
+		}
+	}
+	if !final.Done || final.Progress != 1 || final.Error != "" {
+		t.Errorf("final event = %+v, want done at full progress", final)
+	}
+	if _, err := reg.GetModel("gui-actor-2b"); err != nil {
+		t.Errorf("GetModel after load: %v", err)
+	}
+}
+
+func TestLoadModelAlreadyLoaded(t *testing.T) {
+	reg := newLoadingRegistry()
+	if err := loadAndWait(reg, "gui-actor-2b"); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+
+	events, err := reg.LoadModel("gui-actor-2b")
+	if err != nil {
+		t.Fatalf("LoadModel on loaded model: %v", err)
+	}
+
+	var got []LoadEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 1 || !got[0].Done || got[0].Progress != 1 {
+		t.Errorf("events = %+v, want a single done event", got)
+	}
+}
+
+func TestSubscribeLoadWithoutLoad(t *testing.T) {
+	reg := newLoadingRegistry()
+	if _, loading := reg.SubscribeLoad("gui-actor-2b"); loading {
+		t.Error("SubscribeLoad reports a load in flight on an idle model")
+	}
+}
+
+func TestLoadModelJoinsInFlightLoad(t *testing.T) {
+	reg := newLoadingRegistry()
+	reg.StartLoad("gui-actor-2b")
+
+	// A second LoadModel observes the in-flight load rather than racing
+	// a new one; its channel stays open until that load finishes
+	events, err := reg.LoadModel("gui-actor-2b")
+	if err != nil {
+		t.Fatalf("LoadModel while loading: %v", err)
+	}
+	select {
+	case ev, ok := <-events:
+		if ok {
+			t.Fatalf("got event %+v before the load finished", ev)
+		}
+		t.Fatal("event channel closed before the load finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	reg.FinishLoad("gui-actor-2b", nil)
+
+	var final LoadEvent
+	for ev := range events {
+		final = ev
+	}
+	if !final.Done || final.Error != "" {
+		t.Errorf("final event = %+v, want clean completion", final)
+	}
+}
@@ -0,0 +1,68 @@
+package model
+
+import "github.com/agenthands/mlxvllm/internal/config"
+
+// Offload targets for streamed layers
+const (
+	OffloadTargetMmap = "mmap"
+	OffloadTargetCPU  = "cpu"
+)
+
+// Residency summarizes per-layer weight placement for one model: how
+// many transformer layers are GPU-resident and where the rest are
+// streamed from
+type Residency struct {
+	TotalLayers     int    `json:"total_layers"`
+	ResidentLayers  int    `json:"resident_layers"`
+	OffloadedLayers int    `json:"offloaded_layers"`
+	KeepFirst       int    `json:"keep_first"`
+	KeepLast        int    `json:"keep_last"`
+	Target          string `json:"target"`
+}
+
+// ResidentFraction is the share of layers kept on GPU
+func (r *Residency) ResidentFraction() float64 {
+	if r.TotalLayers == 0 {
+		return 1
+	}
+	return float64(r.ResidentLayers) / float64(r.TotalLayers)
+}
+
+// PlanResidency turns an offload config into a concrete layer plan,
+// clamping out-of-range keep counts. It returns nil when offload is
+// disabled or not configured for the model.
+func PlanResidency(cfg *config.OffloadConfig) *Residency {
+	if cfg == nil || !cfg.Enabled || cfg.TotalLayers <= 0 {
+		return nil
+	}
+
+	first, last := cfg.KeepFirstLayers, cfg.KeepLastLayers
+	if first < 0 {
+		first = 0
+	}
+	if last < 0 {
+		last = 0
+	}
+	if first+last > cfg.TotalLayers {
+		// Everything fits; keep the whole stack resident, front-first
+		if first > cfg.TotalLayers {
+			first = cfg.TotalLayers
+		}
+		last = cfg.TotalLayers - first
+	}
+
+	target := cfg.Target
+	if target != OffloadTargetCPU {
+		target = OffloadTargetMmap
+	}
+
+	resident := first + last
+	return &Residency{
+		TotalLayers:     cfg.TotalLayers,
+		ResidentLayers:  resident,
+		OffloadedLayers: cfg.TotalLayers - resident,
+		KeepFirst:       first,
+		KeepLast:        last,
+		Target:          target,
+	}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func TestPlanResidency(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.OffloadConfig
+		want *Residency
+	}{
+		{"nil config", nil, nil},
+		{"disabled", &config.OffloadConfig{TotalLayers: 28, KeepFirstLayers: 4}, nil},
+		{"no layer count", &config.OffloadConfig{Enabled: true, KeepFirstLayers: 4}, nil},
+		{
+			"basic split",
+			&config.OffloadConfig{Enabled: true, TotalLayers: 28, KeepFirstLayers: 4, KeepLastLayers: 4},
+			&Residency{TotalLayers: 28, ResidentLayers: 8, OffloadedLayers: 20, KeepFirst: 4, KeepLast: 4, Target: OffloadTargetMmap},
+		},
+		{
+			"cpu target",
+			&config.OffloadConfig{Enabled: true, TotalLayers: 28, KeepFirstLayers: 2, KeepLastLayers: 2, Target: "cpu"},
+			&Residency{TotalLayers: 28, ResidentLayers: 4, OffloadedLayers: 24, KeepFirst: 2, KeepLast: 2, Target: OffloadTargetCPU},
+		},
+		{
+			"keeps exceed depth",
+			&config.OffloadConfig{Enabled: true, TotalLayers: 8, KeepFirstLayers: 6, KeepLastLayers: 6},
+			&Residency{TotalLayers: 8, ResidentLayers: 8, OffloadedLayers: 0, KeepFirst: 6, KeepLast: 2, Target: OffloadTargetMmap},
+		},
+		{
+			"negative keeps clamped",
+			&config.OffloadConfig{Enabled: true, TotalLayers: 10, KeepFirstLayers: -1, KeepLastLayers: 3},
+			&Residency{TotalLayers: 10, ResidentLayers: 3, OffloadedLayers: 7, KeepFirst: 0, KeepLast: 3, Target: OffloadTargetMmap},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlanResidency(tt.cfg)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("PlanResidency = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("PlanResidency = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryScalesMemoryForOffload(t *testing.T) {
+	reg := NewRegistry(&config.Config{
+		Models: map[string]config.ModelConfig{
+			"gui-actor-7b": {
+				Path:    "/tmp/models/7b",
+				Enabled: true,
+				Offload: &config.OffloadConfig{
+					Enabled:         true,
+					TotalLayers:     28,
+					KeepFirstLayers: 7,
+					KeepLastLayers:  7,
+				},
+			},
+			"gui-actor-2b": {Path: "/tmp/models/2b", Enabled: true},
+		},
+	})
+
+	var offloaded, full *ModelStatus
+	for _, status := range reg.Status() {
+		s := status
+		switch s.Name {
+		case "gui-actor-7b":
+			offloaded = &s
+		case "gui-actor-2b":
+			full = &s
+		}
+	}
+	if offloaded == nil || full == nil {
+		t.Fatal("registry did not report both models")
+	}
+
+	if offloaded.Residency == nil {
+		t.Fatal("offloaded model has no residency plan")
+	}
+	if offloaded.Residency.OffloadedLayers != 14 {
+		t.Errorf("OffloadedLayers = %d, want 14", offloaded.Residency.OffloadedLayers)
+	}
+	// Half the layers resident means roughly half the memory estimate
+	if want := estimateMemoryGB("gui-actor-7b") / 2; offloaded.MemoryGB != want {
+		t.Errorf("MemoryGB = %v, want %v", offloaded.MemoryGB, want)
+	}
+
+	if full.Residency != nil {
+		t.Errorf("model without offload has residency %+v", full.Residency)
+	}
+}
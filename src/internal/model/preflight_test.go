@@ -0,0 +1,88 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func preflightConfig(limit string) *config.Config {
+	return &config.Config{
+		Memory: config.MemoryConfig{MaxTotalGB: limit},
+		Models: map[string]config.ModelConfig{
+			"gui-actor-2b": {Path: "/tmp/models/2b", Enabled: true},
+			"gui-actor-7b": {Path: "/tmp/models/7b", Enabled: true},
+		},
+	}
+}
+
+func TestPreflightLoadWithinLimit(t *testing.T) {
+	reg := NewRegistry(preflightConfig("16"))
+	if err := reg.PreflightLoad("gui-actor-7b"); err != nil {
+		t.Errorf("PreflightLoad within limit: %v", err)
+	}
+}
+
+func TestPreflightLoadExceedsLimit(t *testing.T) {
+	reg := NewRegistry(preflightConfig("16"))
+	if err := loadAndWait(reg, "gui-actor-2b"); err != nil {
+		t.Fatalf("load 2b: %v", err)
+	}
+
+	// 4 GB loaded + 14 GB needed > 16 GB limit
+	err := reg.PreflightLoad("gui-actor-7b")
+	if err == nil {
+		t.Fatal("PreflightLoad accepted a load past the memory limit")
+	}
+	for _, want := range []string{"insufficient memory", "14.0 GB", "16.0 GB limit"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+
+	// LoadModel fails the same way before any load starts
+	if _, err := reg.LoadModel("gui-actor-7b"); err == nil {
+		t.Error("LoadModel started despite a failed preflight")
+	}
+	if loading, _, _ := reg.LoadState("gui-actor-7b"); loading {
+		t.Error("a load is in flight after a failed preflight")
+	}
+}
+
+func TestPreflightLoadCountsKVCache(t *testing.T) {
+	reg := NewRegistry(preflightConfig("16"))
+	reg.SetKVUsageFunc(func() float64 { return 3.0 })
+
+	// 3 GB KV cache + 14 GB needed > 16 GB limit
+	err := reg.PreflightLoad("gui-actor-7b")
+	if err == nil {
+		t.Fatal("PreflightLoad ignored KV cache usage")
+	}
+	if !strings.Contains(err.Error(), "KV cache 3.0 GB") {
+		t.Errorf("error %q does not report KV cache usage", err)
+	}
+
+	// The 2B model still fits
+	if err := reg.PreflightLoad("gui-actor-2b"); err != nil {
+		t.Errorf("PreflightLoad for the small model: %v", err)
+	}
+}
+
+func TestPreflightLoadNoLimit(t *testing.T) {
+	reg := NewRegistry(preflightConfig(""))
+	if err := reg.PreflightLoad("gui-actor-7b"); err != nil {
+		t.Errorf("PreflightLoad without a limit: %v", err)
+	}
+}
+
+func TestPreflightLoadAlreadyLoaded(t *testing.T) {
+	reg := NewRegistry(preflightConfig("4"))
+	if err := loadAndWait(reg, "gui-actor-2b"); err != nil {
+		t.Fatalf("load 2b: %v", err)
+	}
+	// Loaded models pass preflight regardless of headroom
+	if err := reg.PreflightLoad("gui-actor-2b"); err != nil {
+		t.Errorf("PreflightLoad on a loaded model: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+// LoadEvent reports progress of an asynchronous model load. The final
+// event has Done set, with Error carrying the failure if any.
+type LoadEvent struct {
+	Model    string  `json:"model"`
+	Phase    string  `json:"phase,omitempty"`
+	Progress float64 `json:"progress"`
+	Done     bool    `json:"done,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// loadPhases are the stages of bringing a model up, with the cumulative
+// progress reached as each completes
+var loadPhases = []struct {
+	name     string
+	progress float64
+}{
+	{"weights_read", 0.5},
+	{"quantization", 0.7},
+	{"metal_upload", 0.9},
+	{"warmup", 1.0},
+}
+
+// SetLoadPhase reports the current stage and cumulative progress of an
+// in-flight load, notifying subscribers
+func (r *Registry) SetLoadPhase(name, phase string, progress float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.loads[name]
+	if !ok {
+		return
+	}
+	state.phase = phase
+	state.progress = progress
+	status := r.models[name]
+	status.LoadPhase = phase
+	status.LoadProgress = progress
+
+	state.broadcast(LoadEvent{Model: name, Phase: phase, Progress: progress})
+}
+
+// SubscribeLoad returns a channel of progress events for an in-flight
+// load of the model; it is closed when the load completes. The bool is
+// false when no load is underway.
+func (r *Registry) SubscribeLoad(name string) (<-chan LoadEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.loads[name]
+	if !ok {
+		return nil, false
+	}
+
+	ch := make(chan LoadEvent, 16)
+	state.subs = append(state.subs, ch)
+	return ch, true
+}
+
+// broadcast delivers an event to all subscribers without blocking; the
+// caller must hold the registry lock
+func (s *loadState) broadcast(ev LoadEvent) {
+	for _, sub := range s.subs {
+		select {
+		case sub <- ev:
+		default:
+			// Slow subscriber; drop rather than stall the load
+		}
+	}
+}
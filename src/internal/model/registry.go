@@ -1,10 +1,17 @@
 package model
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/agenthands/mlxvllm/internal/config"
+	"github.com/agenthands/mlxvllm/internal/modelcache"
 )
 
 type ModelStatus struct {
@@ -13,6 +20,13 @@ type ModelStatus struct {
 	Path     string
 	MemoryGB float64
 	LastUsed int64 // Unix timestamp
+	// Loading/LoadPhase/LoadProgress describe an in-flight load
+	// (progress in [0,1])
+	Loading      bool
+	LoadPhase    string
+	LoadProgress float64
+	// Residency is non-nil when per-layer offload is configured
+	Residency *Residency
 }
 
 type Model interface {
@@ -21,12 +35,26 @@ type Model interface {
 	Unload() error
 }
 
+// loadState tracks one in-flight model load
+type loadState struct {
+	phase    string
+	progress float64
+	started  time.Time
+	done     chan struct{}
+	err      error
+	subs     []chan LoadEvent
+}
+
 type Registry struct {
 	mu      sync.RWMutex
 	cfg     *config.Config
 	models  map[string]*ModelStatus
 	loaded  map[string]Model
+	loads   map[string]*loadState
 	totalGB float64
+	// kvUsage reports the engine's current KV cache footprint in GB;
+	// it is counted against the memory limit during load preflight
+	kvUsage func() float64
 }
 
 func NewRegistry(cfg *config.Config) *Registry {
@@ -34,16 +62,25 @@ func NewRegistry(cfg *config.Config) *Registry {
 		cfg:    cfg,
 		models: make(map[string]*ModelStatus),
 		loaded: make(map[string]Model),
+		loads:  make(map[string]*loadState),
 	}
 
 	// Register all enabled models
 	for name, mcfg := range cfg.Models {
 		if mcfg.Enabled {
+			memoryGB := estimateMemoryGB(name)
+			residency := PlanResidency(mcfg.Offload)
+			if residency != nil {
+				// Streamed layers don't count against unified memory;
+				// scale the estimate by the resident fraction
+				memoryGB *= residency.ResidentFraction()
+			}
 			reg.models[name] = &ModelStatus{
-				Name:     name,
-				Loaded:   false,
-				Path:     mcfg.Path,
-				MemoryGB: estimateMemoryGB(name),
+				Name:      name,
+				Loaded:    false,
+				Path:      mcfg.Path,
+				MemoryGB:  memoryGB,
+				Residency: residency,
 			}
 		}
 	}
@@ -58,36 +95,272 @@ func (r *Registry) HasModel(name string) bool {
 	return ok
 }
 
-func (r *Registry) LoadModel(name string) error {
+// SetKVUsageFunc wires in the engine's KV cache accounting so load
+// preflight can count it against the memory limit
+func (r *Registry) SetKVUsageFunc(fn func() float64) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.kvUsage = fn
+}
+
+// PreflightLoad checks unified-memory headroom for the model against
+// the configured limit, counting loaded models and the KV cache, and
+// fails early with the numbers involved instead of OOM-killing mid-load
+func (r *Registry) PreflightLoad(name string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	status, ok := r.models[name]
 	if !ok {
 		return fmt.Errorf("model %s not found", name)
 	}
-
 	if status.Loaded {
-		return nil // Already loaded
+		return nil
+	}
+
+	var limit float64
+	if r.cfg != nil {
+		limit = r.cfg.Memory.LimitGB()
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	var kvGB float64
+	if r.kvUsage != nil {
+		kvGB = r.kvUsage()
+	}
+	if r.totalGB+kvGB+status.MemoryGB > limit {
+		return fmt.Errorf(
+			"insufficient memory to load %s: needs %.1f GB but %.1f GB of the %.1f GB limit is in use (models %.1f GB, KV cache %.1f GB) — unload a model or raise memory.max_total_gb",
+			name, status.MemoryGB, r.totalGB+kvGB, limit, r.totalGB, kvGB)
 	}
+	return nil
+}
+
+// LoadModel starts loading the model in the background, returning a
+// channel of progress events that closes when the load completes. A
+// call while a load is in flight observes that load; an already loaded
+// model yields a single done event.
+func (r *Registry) LoadModel(name string) (<-chan LoadEvent, error) {
+	if err := r.PreflightLoad(name); err != nil {
+		return nil, err
+	}
+
+	started, err := r.StartLoad(name)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		if ch, loading := r.SubscribeLoad(name); loading {
+			return ch, nil
+		}
+		// Already loaded
+		ch := make(chan LoadEvent, 1)
+		ch <- LoadEvent{Model: name, Progress: 1, Done: true}
+		close(ch)
+		return ch, nil
+	}
+
+	ch, _ := r.SubscribeLoad(name)
+	go r.runLoad(name)
+	return ch, nil
+}
+
+// runLoad executes the load pipeline, reporting each phase
+func (r *Registry) runLoad(name string) {
+	r.mu.Lock()
+	status := r.models[name]
+	path := status.Path
 
 	// Check memory constraints
-	if r.totalGB+status.MemoryGB > 32 { // TODO: parse cfg.Memory.MaxTotalGB
+	if limit := r.cfg.Memory.LimitGB(); limit > 0 && r.totalGB+status.MemoryGB > limit {
 		r.makeRoom(status.MemoryGB)
 	}
+	r.mu.Unlock()
 
-	// Load model (placeholder)
-	model := &GUIActorModel{
-		name:   name,
-		path:   status.Path,
-		loaded: true,
+	// GGUF checkpoints use a different weight layout; identify them and
+	// point at the converter instead of failing opaquely further down
+	if ggufPath, ok := ggufCheckpoint(path); ok {
+		desc := fmt.Sprintf("model %s is a GGUF checkpoint (%s)", name, filepath.Base(ggufPath))
+		if info, err := modelcache.ReadGGUFInfo(ggufPath); err == nil {
+			desc = fmt.Sprintf("model %s is a GGUF checkpoint (%s: arch %q, %d tensors, GGUF v%d)",
+				name, filepath.Base(ggufPath), info.Architecture(), info.TensorCount, info.Version)
+		}
+		r.FinishLoad(name, fmt.Errorf(
+			"%s — the MLX engine loads bin_weights; convert the original HF checkpoint with `mlxvllm convert`", desc))
+		return
 	}
 
-	r.loaded[name] = model
-	status.Loaded = true
-	r.totalGB += status.MemoryGB
+	// Verify weight integrity before anything reads the shards
+	if policy := r.checksumPolicy(); policy != "off" {
+		if err := modelcache.VerifyChecksums(path); err != nil {
+			if policy == "strict" {
+				r.FinishLoad(name, err)
+				return
+			}
+			log.Printf("Warning: integrity check for %s: %v", name, err)
+		}
+	}
 
-	return nil
+	// Load model (placeholder); a real engine reads weights, quantizes,
+	// uploads to Metal and warms up in these phases
+	for _, phase := range loadPhases {
+		r.SetLoadPhase(name, phase.name, phase.progress)
+	}
+
+	r.FinishLoad(name, nil)
+}
+
+// ggufCheckpoint returns the GGUF file a model path points at: either
+// the path itself, or the first .gguf in a directory that has no
+// converted bin_weights
+func ggufCheckpoint(path string) (string, bool) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if !fi.IsDir() {
+		return path, modelcache.IsGGUF(path)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "bin_weights")); err == nil {
+		return "", false
+	}
+	matches, _ := filepath.Glob(filepath.Join(path, "*.gguf"))
+	sort.Strings(matches)
+	for _, match := range matches {
+		if modelcache.IsGGUF(match) {
+			return match, true
+		}
+	}
+	return "", false
+}
+
+// StartLoad marks a load as in flight. It returns false when the model
+// is already loaded or another load is underway; callers that started
+// the load must pair it with FinishLoad.
+func (r *Registry) StartLoad(name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status, ok := r.models[name]
+	if !ok {
+		return false, fmt.Errorf("model %s not found", name)
+	}
+	if status.Loaded {
+		return false, nil
+	}
+	if _, loading := r.loads[name]; loading {
+		return false, nil
+	}
+
+	r.loads[name] = &loadState{
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+	status.Loading = true
+	status.LoadProgress = 0
+	return true, nil
+}
+
+// SetLoadProgress reports progress of an in-flight load, in [0, 1]
+func (r *Registry) SetLoadProgress(name string, progress float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.loads[name]
+	if !ok {
+		return
+	}
+	state.progress = progress
+	r.models[name].LoadProgress = progress
+	state.broadcast(LoadEvent{Model: name, Phase: state.phase, Progress: progress})
+}
+
+// FinishLoad completes an in-flight load, registering the model on
+// success and releasing any waiters either way
+func (r *Registry) FinishLoad(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.loads[name]
+	if !ok {
+		return
+	}
+	delete(r.loads, name)
+
+	status := r.models[name]
+	status.Loading = false
+	status.LoadPhase = ""
+	status.LoadProgress = 0
+
+	if err == nil {
+		r.loaded[name] = &GUIActorModel{
+			name:   name,
+			path:   status.Path,
+			loaded: true,
+		}
+		status.Loaded = true
+		status.LoadProgress = 1
+		r.totalGB += status.MemoryGB
+	}
+
+	final := LoadEvent{Model: name, Progress: status.LoadProgress, Done: true}
+	if err != nil {
+		final.Error = err.Error()
+	}
+	state.broadcast(final)
+	for _, sub := range state.subs {
+		close(sub)
+	}
+
+	state.err = err
+	close(state.done)
+}
+
+// LoadState reports whether a load is in flight for the model, how far
+// along it is, and a retry-after estimate. The estimate extrapolates
+// from reported progress when there is any, otherwise from the model's
+// memory footprint.
+func (r *Registry) LoadState(name string) (loading bool, progress float64, eta time.Duration) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.loads[name]
+	if !ok {
+		return false, 0, 0
+	}
+
+	elapsed := time.Since(state.started)
+	if state.progress > 0 {
+		eta = time.Duration(float64(elapsed) * (1 - state.progress) / state.progress)
+	} else {
+		eta = time.Duration(r.models[name].MemoryGB*2)*time.Second - elapsed
+	}
+	if eta < time.Second {
+		eta = time.Second
+	}
+	return true, state.progress, eta
+}
+
+// AwaitLoad blocks until an in-flight load of the model completes,
+// returning its outcome. It returns immediately when no load is
+// underway.
+func (r *Registry) AwaitLoad(ctx context.Context, name string) error {
+	r.mu.RLock()
+	state, loading := r.loads[name]
+	r.mu.RUnlock()
+	if !loading {
+		return nil
+	}
+
+	select {
+	case <-state.done:
+		return state.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (r *Registry) UnloadModel(name string) error {
@@ -123,11 +396,40 @@ func (r *Registry) GetModel(name string) (Model, error) {
 	return model, nil
 }
 
+// Status returns a snapshot of every registered model, sorted by name
+func (r *Registry) Status() []ModelStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]ModelStatus, 0, len(r.models))
+	for _, status := range r.models {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// UsedGB returns the memory accounted to currently loaded models
+func (r *Registry) UsedGB() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.totalGB
+}
+
 func (r *Registry) makeRoom(requiredGB float64) {
 	// LRU eviction logic
 	// TODO: implement actual LRU
 }
 
+// checksumPolicy resolves the configured integrity policy, defaulting
+// to warn
+func (r *Registry) checksumPolicy() string {
+	if r.cfg != nil && r.cfg.ChecksumPolicy != "" {
+		return r.cfg.ChecksumPolicy
+	}
+	return "warn"
+}
+
 func estimateMemoryGB(name string) float64 {
 	switch name {
 	case "gui-actor-2b":
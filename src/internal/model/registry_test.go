@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/agenthands/mlxvllm/internal/config"
@@ -8,10 +9,10 @@ import (
 
 func TestNewRegistry(t *testing.T) {
 	tests := []struct {
-		name          string
-		cfg           *config.Config
-		expectModels  []string
-		expectCount   int
+		name         string
+		cfg          *config.Config
+		expectModels []string
+		expectCount  int
 	}{
 		{
 			name: "single model",
@@ -123,6 +124,21 @@ func TestHasModel(t *testing.T) {
 	}
 }
 
+// loadAndWait drives an asynchronous load to completion
+func loadAndWait(reg *Registry, name string) error {
+	events, err := reg.LoadModel(name)
+	if err != nil {
+		return err
+	}
+	var loadErr error
+	for ev := range events {
+		if ev.Error != "" {
+			loadErr = errors.New(ev.Error)
+		}
+	}
+	return loadErr
+}
+
 func TestLoadModel(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -151,7 +167,7 @@ func TestLoadModel(t *testing.T) {
 			}
 
 			reg := NewRegistry(cfg)
-			err := reg.LoadModel(tt.modelID)
+			err := loadAndWait(reg, tt.modelID)
 
 			if tt.expectError && err == nil {
 				t.Errorf("Expected error for %s, got nil", tt.modelID)
@@ -176,13 +192,13 @@ func TestLoadModelTwice(t *testing.T) {
 	reg := NewRegistry(cfg)
 
 	// Load once
-	err := reg.LoadModel("gui-actor-2b")
+	err := loadAndWait(reg, "gui-actor-2b")
 	if err != nil {
 		t.Fatalf("First load failed: %v", err)
 	}
 
 	// Load again - should be idempotent
-	err = reg.LoadModel("gui-actor-2b")
+	err = loadAndWait(reg, "gui-actor-2b")
 	if err != nil {
 		t.Errorf("Second load should be idempotent, got error: %v", err)
 	}
@@ -207,7 +223,7 @@ func TestUnloadModel(t *testing.T) {
 	}
 
 	// Load then unload
-	reg.LoadModel("gui-actor-2b")
+	loadAndWait(reg, "gui-actor-2b")
 	err = reg.UnloadModel("gui-actor-2b")
 	if err != nil {
 		t.Errorf("Failed to unload loaded model: %v", err)
@@ -245,7 +261,7 @@ func TestGetModel(t *testing.T) {
 	}
 
 	// Load then get
-	reg.LoadModel("gui-actor-2b")
+	loadAndWait(reg, "gui-actor-2b")
 	model, err = reg.GetModel("gui-actor-2b")
 	if err != nil {
 		t.Errorf("Failed to get loaded model: %v", err)
@@ -275,9 +291,9 @@ func TestGUIActorModel(t *testing.T) {
 	}
 
 	tests := []struct {
-		name       string
-		method     string
-		expectVal  interface{}
+		name      string
+		method    string
+		expectVal interface{}
 	}{
 		{"ID method", "ID", "test-model"},
 		{"IsLoaded true", "IsLoaded", true},
@@ -0,0 +1,155 @@
+package modelcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumFile is the per-model manifest of recorded weight hashes
+const ChecksumFile = "checksums.json"
+
+// weightExtensions are the file types covered by integrity checks
+var weightExtensions = []string{".safetensors", ".bin", ".gguf", ".npz"}
+
+// ChecksumError reports a weight shard whose contents differ from the
+// recorded hash — typically a truncated or corrupted download that
+// would otherwise silently produce garbage logits
+type ChecksumError struct {
+	File string
+	Want string
+	Got  string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: recorded %s, computed %s", e.File, e.Want, e.Got)
+}
+
+// isWeightFile reports whether integrity checks cover the file
+func isWeightFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, want := range weightExtensions {
+		if ext == want {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile computes the SHA256 of a file as lowercase hex
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// weightFiles lists the weight shards under dir, as slash-separated
+// paths relative to it
+func weightFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isWeightFile(info.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// RecordChecksums hashes every weight shard under dir and writes the
+// manifest, overwriting any previous one
+func RecordChecksums(dir string) error {
+	files, err := weightFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	sums := make(map[string]string, len(files))
+	for _, rel := range files {
+		sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		sums[rel] = sum
+	}
+	return WriteChecksums(dir, sums)
+}
+
+// WriteChecksums stores a manifest of relative path -> SHA256
+func WriteChecksums(dir string, sums map[string]string) error {
+	data, err := json.MarshalIndent(sums, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ChecksumFile), data, 0644)
+}
+
+// readChecksums loads the manifest; a missing file yields (nil, nil)
+func readChecksums(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ChecksumFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sums map[string]string
+	if err := json.Unmarshal(data, &sums); err != nil {
+		return nil, fmt.Errorf("corrupt checksum manifest: %w", err)
+	}
+	return sums, nil
+}
+
+// VerifyChecksums re-hashes the recorded weight shards under dir and
+// returns a *ChecksumError on the first mismatch or missing shard. On
+// first load — no manifest yet — it records one instead. Directories
+// that do not exist are skipped so unpulled model paths stay loadable.
+func VerifyChecksums(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+
+	sums, err := readChecksums(dir)
+	if err != nil {
+		return err
+	}
+	if sums == nil {
+		return RecordChecksums(dir)
+	}
+
+	for rel, want := range sums {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		got, err := hashFile(path)
+		if os.IsNotExist(err) {
+			return &ChecksumError{File: rel, Want: want, Got: "missing"}
+		}
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return &ChecksumError{File: rel, Want: want, Got: got}
+		}
+	}
+	return nil
+}
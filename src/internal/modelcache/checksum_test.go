@@ -0,0 +1,123 @@
+package modelcache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeWeight(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestIsWeightFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"model.safetensors", true},
+		{"pytorch_model.bin", true},
+		{"model-q4.gguf", true},
+		{"weights.npz", true},
+		{"config.json", false},
+		{"tokenizer.model", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWeightFile(tt.name); got != tt.want {
+				t.Errorf("isWeightFile(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordAndVerifyChecksums(t *testing.T) {
+	dir := t.TempDir()
+	writeWeight(t, dir, "model-00001.safetensors", []byte("shard one"))
+	writeWeight(t, dir, "bin_weights/layer0.bin", []byte("shard two"))
+	writeWeight(t, dir, "config.json", []byte("{}")) // not covered
+
+	if err := RecordChecksums(dir); err != nil {
+		t.Fatalf("RecordChecksums: %v", err)
+	}
+	sums, err := readChecksums(dir)
+	if err != nil {
+		t.Fatalf("readChecksums: %v", err)
+	}
+	if len(sums) != 2 {
+		t.Errorf("manifest has %d entries, want 2: %v", len(sums), sums)
+	}
+
+	if err := VerifyChecksums(dir); err != nil {
+		t.Errorf("VerifyChecksums on intact shards: %v", err)
+	}
+}
+
+func TestVerifyChecksumsDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	path := writeWeight(t, dir, "model.safetensors", []byte("original weights"))
+	if err := RecordChecksums(dir); err != nil {
+		t.Fatalf("RecordChecksums: %v", err)
+	}
+
+	// Simulate a truncated download
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to truncate shard: %v", err)
+	}
+
+	err := VerifyChecksums(dir)
+	var mismatch *ChecksumError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("VerifyChecksums = %v, want *ChecksumError", err)
+	}
+	if mismatch.File != "model.safetensors" {
+		t.Errorf("mismatch file = %q, want model.safetensors", mismatch.File)
+	}
+}
+
+func TestVerifyChecksumsDetectsMissingShard(t *testing.T) {
+	dir := t.TempDir()
+	path := writeWeight(t, dir, "model.safetensors", []byte("weights"))
+	if err := RecordChecksums(dir); err != nil {
+		t.Fatalf("RecordChecksums: %v", err)
+	}
+	os.Remove(path)
+
+	err := VerifyChecksums(dir)
+	var mismatch *ChecksumError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("VerifyChecksums = %v, want *ChecksumError", err)
+	}
+	if mismatch.Got != "missing" {
+		t.Errorf("mismatch got = %q, want missing", mismatch.Got)
+	}
+}
+
+func TestVerifyChecksumsFirstLoadRecords(t *testing.T) {
+	dir := t.TempDir()
+	writeWeight(t, dir, "model.safetensors", []byte("weights"))
+
+	// No manifest yet: the first verification writes one
+	if err := VerifyChecksums(dir); err != nil {
+		t.Fatalf("VerifyChecksums on first load: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ChecksumFile)); err != nil {
+		t.Errorf("manifest was not recorded on first load: %v", err)
+	}
+}
+
+func TestVerifyChecksumsMissingDir(t *testing.T) {
+	if err := VerifyChecksums(filepath.Join(t.TempDir(), "never-pulled")); err != nil {
+		t.Errorf("VerifyChecksums on a missing dir: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package modelcache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// GGUF header layout: the "GGUF" magic, a u32 version, u64 tensor and
+// metadata counts, then length-prefixed metadata key/value pairs. The
+// engine cannot load GGUF weights, but reading the header lets the
+// registry say what a dropped-in file actually is instead of failing
+// with an opaque load error.
+
+// ggufMagic is "GGUF" read as little-endian u32
+const ggufMagic = 0x46554747
+
+// Sanity bounds so a corrupt header cannot trigger huge allocations
+const (
+	maxGGUFStringLen = 1 << 20
+	maxGGUFKVCount   = 1 << 16
+	maxGGUFArrayLen  = 1 << 20
+)
+
+// GGUF metadata value types
+const (
+	ggufTypeUint8 = iota
+	ggufTypeInt8
+	ggufTypeUint16
+	ggufTypeInt16
+	ggufTypeUint32
+	ggufTypeInt32
+	ggufTypeFloat32
+	ggufTypeBool
+	ggufTypeString
+	ggufTypeArray
+	ggufTypeUint64
+	ggufTypeInt64
+	ggufTypeFloat64
+)
+
+// GGUFInfo is the parsed header of a GGUF model file
+type GGUFInfo struct {
+	Version     uint32
+	TensorCount uint64
+	Metadata    map[string]any
+}
+
+// Architecture returns the general.architecture key ("" when absent)
+func (i *GGUFInfo) Architecture() string {
+	s, _ := i.Metadata["general.architecture"].(string)
+	return s
+}
+
+// ModelName returns the general.name key ("" when absent)
+func (i *GGUFInfo) ModelName() string {
+	s, _ := i.Metadata["general.name"].(string)
+	return s
+}
+
+// ReadGGUFInfo parses the header and metadata of a GGUF file without
+// reading its tensor data
+func ReadGGUFInfo(path string) (*GGUFInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseGGUF(f)
+}
+
+// IsGGUF reports whether the file starts with the GGUF magic
+func IsGGUF(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var magic uint32
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil {
+		return false
+	}
+	return magic == ggufMagic
+}
+
+// parseGGUF reads the header and metadata section from r
+func parseGGUF(r io.Reader) (*GGUFInfo, error) {
+	var header struct {
+		Magic       uint32
+		Version     uint32
+		TensorCount uint64
+		KVCount     uint64
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("not a GGUF file: %w", err)
+	}
+	if header.Magic != ggufMagic {
+		return nil, fmt.Errorf("not a GGUF file: bad magic 0x%08x", header.Magic)
+	}
+	if header.KVCount > maxGGUFKVCount {
+		return nil, fmt.Errorf("implausible metadata count %d", header.KVCount)
+	}
+
+	info := &GGUFInfo{
+		Version:     header.Version,
+		TensorCount: header.TensorCount,
+		Metadata:    make(map[string]any, header.KVCount),
+	}
+	for i := uint64(0); i < header.KVCount; i++ {
+		key, err := readGGUFString(r)
+		if err != nil {
+			return nil, fmt.Errorf("metadata key %d: %w", i, err)
+		}
+		var valueType uint32
+		if err := binary.Read(r, binary.LittleEndian, &valueType); err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		value, err := readGGUFValue(r, valueType)
+		if err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		info.Metadata[key] = value
+	}
+	return info, nil
+}
+
+// readGGUFValue reads one typed metadata value
+func readGGUFValue(r io.Reader, valueType uint32) (any, error) {
+	switch valueType {
+	case ggufTypeUint8:
+		var v uint8
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeInt8:
+		var v int8
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeUint16:
+		var v uint16
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeInt16:
+		var v int16
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeUint32:
+		var v uint32
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeInt32:
+		var v int32
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeFloat32:
+		var bits uint32
+		if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(bits), nil
+	case ggufTypeBool:
+		var v uint8
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return nil, err
+		}
+		return v != 0, nil
+	case ggufTypeString:
+		return readGGUFString(r)
+	case ggufTypeArray:
+		var elemType uint32
+		var count uint64
+		if err := binary.Read(r, binary.LittleEndian, &elemType); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return nil, err
+		}
+		if count > maxGGUFArrayLen {
+			return nil, fmt.Errorf("implausible array length %d", count)
+		}
+		values := make([]any, count)
+		for i := range values {
+			v, err := readGGUFValue(r, elemType)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case ggufTypeUint64:
+		var v uint64
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeInt64:
+		var v int64
+		return v, binary.Read(r, binary.LittleEndian, &v)
+	case ggufTypeFloat64:
+		var bits uint64
+		if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	default:
+		return nil, fmt.Errorf("unknown value type %d", valueType)
+	}
+}
+
+// readGGUFString reads a u64-length-prefixed string
+func readGGUFString(r io.Reader) (string, error) {
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", err
+	}
+	if length > maxGGUFStringLen {
+		return "", fmt.Errorf("implausible string length %d", length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,124 @@
+package modelcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ggufString appends a u64-length-prefixed string
+func ggufString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// writeGGUF builds a minimal GGUF file with string, uint32, bool, and
+// array metadata
+func writeGGUF(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufMagic))
+	binary.Write(&buf, binary.LittleEndian, uint32(3))   // version
+	binary.Write(&buf, binary.LittleEndian, uint64(291)) // tensor count
+	binary.Write(&buf, binary.LittleEndian, uint64(4))   // kv count
+
+	ggufString(&buf, "general.architecture")
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufTypeString))
+	ggufString(&buf, "qwen2vl")
+
+	ggufString(&buf, "general.name")
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufTypeString))
+	ggufString(&buf, "GUI-Actor-2B")
+
+	ggufString(&buf, "general.file_type")
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufTypeUint32))
+	binary.Write(&buf, binary.LittleEndian, uint32(7))
+
+	ggufString(&buf, "tokenizer.ggml.eos")
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufTypeArray))
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufTypeInt32))
+	binary.Write(&buf, binary.LittleEndian, uint64(2))
+	binary.Write(&buf, binary.LittleEndian, int32(1))
+	binary.Write(&buf, binary.LittleEndian, int32(2))
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write gguf: %v", err)
+	}
+}
+
+func TestReadGGUFInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	writeGGUF(t, path)
+
+	info, err := ReadGGUFInfo(path)
+	if err != nil {
+		t.Fatalf("ReadGGUFInfo failed: %v", err)
+	}
+	if info.Version != 3 || info.TensorCount != 291 {
+		t.Errorf("header = v%d with %d tensors, want v3 with 291", info.Version, info.TensorCount)
+	}
+	if got := info.Architecture(); got != "qwen2vl" {
+		t.Errorf("Architecture = %q, want qwen2vl", got)
+	}
+	if got := info.ModelName(); got != "GUI-Actor-2B" {
+		t.Errorf("ModelName = %q, want GUI-Actor-2B", got)
+	}
+	if got, ok := info.Metadata["general.file_type"].(uint32); !ok || got != 7 {
+		t.Errorf("file_type = %v, want uint32 7", info.Metadata["general.file_type"])
+	}
+	eos, ok := info.Metadata["tokenizer.ggml.eos"].([]any)
+	if !ok || len(eos) != 2 || eos[1] != int32(2) {
+		t.Errorf("eos array = %v, want [1 2]", info.Metadata["tokenizer.ggml.eos"])
+	}
+}
+
+func TestReadGGUFInfoRejectsBadMagic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.bin")
+	if err := os.WriteFile(path, make([]byte, 32), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadGGUFInfo(path); err == nil || !strings.Contains(err.Error(), "not a GGUF file") {
+		t.Errorf("err = %v, want bad-magic error", err)
+	}
+}
+
+func TestReadGGUFInfoRejectsImplausibleString(t *testing.T) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufMagic))
+	binary.Write(&buf, binary.LittleEndian, uint32(3))
+	binary.Write(&buf, binary.LittleEndian, uint64(0))
+	binary.Write(&buf, binary.LittleEndian, uint64(1))
+	binary.Write(&buf, binary.LittleEndian, uint64(maxGGUFStringLen+1)) // key length
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadGGUFInfo(path); err == nil {
+		t.Error("expected error for implausible string length")
+	}
+}
+
+func TestIsGGUF(t *testing.T) {
+	dir := t.TempDir()
+	gguf := filepath.Join(dir, "model.gguf")
+	writeGGUF(t, gguf)
+	other := filepath.Join(dir, "model.safetensors")
+	if err := os.WriteFile(other, []byte("not gguf"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsGGUF(gguf) {
+		t.Error("IsGGUF = false for a GGUF file")
+	}
+	if IsGGUF(other) {
+		t.Error("IsGGUF = true for a non-GGUF file")
+	}
+	if IsGGUF(filepath.Join(dir, "missing.gguf")) {
+		t.Error("IsGGUF = true for a missing file")
+	}
+}
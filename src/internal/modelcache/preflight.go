@@ -0,0 +1,39 @@
+package modelcache
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// diskHeadroomBytes is the free space kept in reserve so a pull never
+// fills the volume completely
+const diskHeadroomBytes = int64(1) << 30
+
+// diskFree returns the bytes available to this process on the
+// filesystem containing path
+func diskFree(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// checkDiskSpace fails early, with the numbers involved, when the cache
+// volume cannot hold needed more bytes plus headroom
+func checkDiskSpace(dir string, needed int64) error {
+	if needed <= 0 {
+		return nil
+	}
+	free, err := diskFree(dir)
+	if err != nil {
+		// Can't measure (exotic filesystem); don't block the pull
+		return nil
+	}
+	if needed+diskHeadroomBytes > free {
+		return fmt.Errorf(
+			"insufficient disk space in %s: download needs %s plus %s headroom but only %s is free — remove cached models or point MLXVLLM_MODELS at a larger volume",
+			dir, FormatSize(needed), FormatSize(diskHeadroomBytes), FormatSize(free))
+	}
+	return nil
+}
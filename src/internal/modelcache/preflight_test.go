@@ -0,0 +1,62 @@
+package modelcache
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiskFree(t *testing.T) {
+	free, err := diskFree(t.TempDir())
+	if err != nil {
+		t.Fatalf("diskFree: %v", err)
+	}
+	if free <= 0 {
+		t.Errorf("diskFree = %d, want positive", free)
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := checkDiskSpace(dir, 0); err != nil {
+		t.Errorf("checkDiskSpace(0): %v", err)
+	}
+	if err := checkDiskSpace(dir, 1); err != nil {
+		t.Errorf("checkDiskSpace(1 byte): %v", err)
+	}
+
+	err := checkDiskSpace(dir, math.MaxInt64/2)
+	if err == nil {
+		t.Fatal("checkDiskSpace accepted an impossible download")
+	}
+	if !strings.Contains(err.Error(), "insufficient disk space") {
+		t.Errorf("error = %v, want an insufficient-disk-space message", err)
+	}
+}
+
+func TestPullFailsPreflightOnHugeRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/models/org/huge-model" {
+			json.NewEncoder(w).Encode(repoInfo{Siblings: []repoFile{
+				{Rfilename: "model.safetensors", Size: math.MaxInt64 / 2},
+			}})
+			return
+		}
+		t.Errorf("unexpected download request %s after failed preflight", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := newTestStore(t)
+	s.Endpoint = srv.URL
+
+	_, err := s.Pull(context.Background(), "org/huge-model", nil)
+	if err == nil || !strings.Contains(err.Error(), "insufficient disk space") {
+		t.Errorf("Pull = %v, want a disk-space preflight failure", err)
+	}
+}
@@ -0,0 +1,182 @@
+package modelcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/trace"
+)
+
+// ProgressFunc is called as files download. done and total are bytes
+// for the current file.
+type ProgressFunc func(file string, done, total int64)
+
+// repoInfo is the subset of the hub's model API response we need
+type repoInfo struct {
+	Siblings []repoFile `json:"siblings"`
+}
+
+// repoFile is one file inside a hub repo
+type repoFile struct {
+	Rfilename string `json:"rfilename"`
+	Size      int64  `json:"size"`
+}
+
+// Pull downloads a hub repo (e.g. "microsoft/GUI-Actor-2B-Qwen2-VL")
+// into the cache under its base name and returns the local path.
+// Already-complete files are skipped, so an interrupted pull resumes.
+func (s *Store) Pull(ctx context.Context, repo string, progress ProgressFunc) (string, error) {
+	if repo == "" {
+		return "", fmt.Errorf("repo required")
+	}
+	if progress == nil {
+		progress = func(string, int64, int64) {}
+	}
+	client := &http.Client{Timeout: 30 * time.Minute}
+
+	files, err := s.fetchFileList(ctx, client, repo)
+	if err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(s.dir, filepath.Base(repo))
+
+	// Preflight: fail before writing anything if the volume can't hold
+	// the files still missing
+	var needed int64
+	for _, f := range files {
+		target := filepath.Join(dest, filepath.FromSlash(f.Rfilename))
+		if info, err := os.Stat(target); err == nil && f.Size > 0 && info.Size() == f.Size {
+			continue // already complete; a resumed pull skips it
+		}
+		needed += f.Size
+	}
+	if err := checkDiskSpace(s.dir, needed); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", fmt.Errorf("failed to create model dir: %w", err)
+	}
+
+	for _, f := range files {
+		if err := s.downloadFile(ctx, client, repo, dest, f, progress); err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", f.Rfilename, err)
+		}
+	}
+
+	// Record weight hashes so later loads can detect truncated or
+	// corrupted shards
+	if err := RecordChecksums(dest); err != nil {
+		return "", fmt.Errorf("failed to record checksums: %w", err)
+	}
+	return dest, nil
+}
+
+// fetchFileList queries the hub's model API for the repo contents
+func (s *Store) fetchFileList(ctx context.Context, client *http.Client, repo string) ([]repoFile, error) {
+	url := fmt.Sprintf("%s/api/models/%s", s.Endpoint, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	trace.Inject(ctx, req.Header)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("repo not found: %s", repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hub returned %d for %s", resp.StatusCode, repo)
+	}
+
+	var info repoInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse hub response: %w", err)
+	}
+	if len(info.Siblings) == 0 {
+		return nil, fmt.Errorf("repo has no files: %s", repo)
+	}
+	return info.Siblings, nil
+}
+
+// downloadFile fetches one repo file unless it is already complete
+func (s *Store) downloadFile(ctx context.Context, client *http.Client, repo, dest string, f repoFile, progress ProgressFunc) error {
+	// Reject path traversal from hostile file listings
+	if strings.Contains(f.Rfilename, "..") || filepath.IsAbs(f.Rfilename) {
+		return fmt.Errorf("unsafe filename: %q", f.Rfilename)
+	}
+	target := filepath.Join(dest, filepath.FromSlash(f.Rfilename))
+
+	if info, err := os.Stat(target); err == nil && f.Size > 0 && info.Size() == f.Size {
+		progress(f.Rfilename, f.Size, f.Size)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s/resolve/main/%s", s.Endpoint, repo, f.Rfilename)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	trace.Inject(ctx, req.Header)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hub returned %d", resp.StatusCode)
+	}
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = f.Size
+	}
+
+	// Write to a temp file and rename so partial downloads never look
+	// complete
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var done int64
+	buf := make([]byte, 1<<20)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := tmp.Write(buf[:n]); werr != nil {
+				tmp.Close()
+				return werr
+			}
+			done += int64(n)
+			progress(f.Rfilename, done, total)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
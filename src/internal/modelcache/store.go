@@ -0,0 +1,143 @@
+// Package modelcache manages the local model directory used by the
+// registry: downloading repos from the Hugging Face hub, listing cached
+// models with sizes and quantization variants, and removing them.
+package modelcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the Hugging Face hub used by Pull
+const DefaultEndpoint = "https://huggingface.co"
+
+// quantVariants are recognized quantization suffixes in model names
+var quantVariants = []string{"4bit", "8bit", "bf16", "fp16", "fp32"}
+
+// Entry describes one cached model
+type Entry struct {
+	Name         string
+	Path         string
+	SizeBytes    int64
+	Quantization string
+	ModifiedAt   time.Time
+}
+
+// Store is a local model cache rooted at a directory
+type Store struct {
+	dir string
+	// Endpoint overrides the hub URL (tests)
+	Endpoint string
+}
+
+// DefaultDir returns the cache location, honouring MLXVLLM_MODELS
+func DefaultDir() string {
+	if dir := os.Getenv("MLXVLLM_MODELS"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "./models"
+	}
+	return filepath.Join(home, ".mlxvllm", "models")
+}
+
+// NewStore creates a store rooted at dir, creating it if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &Store{dir: dir, Endpoint: DefaultEndpoint}, nil
+}
+
+// Dir returns the cache root
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List returns the cached models sorted by name
+func (s *Store) List() ([]Entry, error) {
+	dirents, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache dir: %w", err)
+	}
+
+	var entries []Entry
+	for _, d := range dirents {
+		if !d.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.dir, d.Name())
+		size, modified, err := dirStats(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{
+			Name:         d.Name(),
+			Path:         path,
+			SizeBytes:    size,
+			Quantization: detectQuantization(d.Name()),
+			ModifiedAt:   modified,
+		})
+	}
+	return entries, nil
+}
+
+// Remove deletes a cached model by name
+func (s *Store) Remove(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid model name: %q", name)
+	}
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("model not cached: %s", name)
+	}
+	return os.RemoveAll(path)
+}
+
+// dirStats walks a model directory accumulating size and newest mtime
+func dirStats(root string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.Walk(root, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	return size, modified, err
+}
+
+// detectQuantization extracts a known variant suffix from the name
+func detectQuantization(name string) string {
+	lower := strings.ToLower(name)
+	for _, v := range quantVariants {
+		if strings.HasSuffix(lower, "-"+v) || strings.HasSuffix(lower, "_"+v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// FormatSize renders a byte count as a human-readable string
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
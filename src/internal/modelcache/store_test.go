@@ -0,0 +1,218 @@
+package modelcache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return s
+}
+
+func writeModel(t *testing.T, s *Store, name string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(s.Dir(), name)
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+}
+
+func TestListAndRemove(t *testing.T) {
+	s := newTestStore(t)
+	writeModel(t, s, "gui-actor-2b-4bit", map[string]string{"config.json": "{}", "weights.bin": "0123456789"})
+	writeModel(t, s, "gui-actor-7b", map[string]string{"config.json": "{}"})
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(entries))
+	}
+	if entries[0].Name != "gui-actor-2b-4bit" || entries[0].SizeBytes != 12 {
+		t.Errorf("Unexpected entry: %+v", entries[0])
+	}
+	if entries[0].Quantization != "4bit" {
+		t.Errorf("Quantization = %q, want 4bit", entries[0].Quantization)
+	}
+	if entries[1].Quantization != "" {
+		t.Errorf("Quantization = %q, want empty", entries[1].Quantization)
+	}
+
+	if err := s.Remove("gui-actor-7b"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	entries, _ = s.List()
+	if len(entries) != 1 {
+		t.Errorf("Got %d entries after remove, want 1", len(entries))
+	}
+}
+
+func TestRemoveValidation(t *testing.T) {
+	s := newTestStore(t)
+
+	tests := []struct {
+		name  string
+		model string
+	}{
+		{"empty", ""},
+		{"traversal", "../outside"},
+		{"hidden", ".git"},
+		{"not cached", "nonexistent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Remove(tt.model); err == nil {
+				t.Errorf("Remove(%q) succeeded, want error", tt.model)
+			}
+		})
+	}
+}
+
+func TestDetectQuantization(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect string
+	}{
+		{"model-4bit", "4bit"},
+		{"model-8bit", "8bit"},
+		{"model_fp16", "fp16"},
+		{"Model-BF16", "bf16"},
+		{"model", ""},
+		{"4bit-model", ""},
+	}
+	for _, tt := range tests {
+		if got := detectQuantization(tt.name); got != tt.expect {
+			t.Errorf("detectQuantization(%q) = %q, want %q", tt.name, got, tt.expect)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes  int64
+		expect string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KB"},
+		{5 << 20, "5.0 MB"},
+		{3 << 30, "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := FormatSize(tt.bytes); got != tt.expect {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.expect)
+		}
+	}
+}
+
+func TestPull(t *testing.T) {
+	content := map[string]string{
+		"config.json":        `{"model_type":"qwen2_vl"}`,
+		"weights/model.bin":  "0123456789abcdef",
+		"tokenizer/vocab.js": "{}",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/models/org/test-model" {
+			var files []repoFile
+			for name, c := range content {
+				files = append(files, repoFile{Rfilename: name, Size: int64(len(c))})
+			}
+			json.NewEncoder(w).Encode(repoInfo{Siblings: files})
+			return
+		}
+		for name, c := range content {
+			if r.URL.Path == "/org/test-model/resolve/main/"+name {
+				w.Write([]byte(c))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := newTestStore(t)
+	s.Endpoint = srv.URL
+
+	var progressed int
+	path, err := s.Pull(context.Background(), "org/test-model", func(file string, done, total int64) {
+		progressed++
+	})
+	if err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if filepath.Base(path) != "test-model" {
+		t.Errorf("Pull path = %q, want base test-model", path)
+	}
+	for name, c := range content {
+		data, err := os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Missing file %s: %v", name, err)
+			continue
+		}
+		if string(data) != c {
+			t.Errorf("File %s = %q, want %q", name, data, c)
+		}
+	}
+	if progressed == 0 {
+		t.Error("Progress callback never called")
+	}
+
+	// A second pull should skip complete files without error
+	if _, err := s.Pull(context.Background(), "org/test-model", nil); err != nil {
+		t.Fatalf("Resumed pull failed: %v", err)
+	}
+}
+
+func TestPullErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := newTestStore(t)
+	s.Endpoint = srv.URL
+
+	if _, err := s.Pull(context.Background(), "", nil); err == nil {
+		t.Error("Expected error for empty repo")
+	}
+	if _, err := s.Pull(context.Background(), "org/missing", nil); err == nil {
+		t.Error("Expected error for missing repo")
+	}
+}
+
+func TestPullRejectsUnsafeFilenames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(repoInfo{Siblings: []repoFile{{Rfilename: "../../etc/evil", Size: 4}}})
+	}))
+	defer srv.Close()
+
+	s := newTestStore(t)
+	s.Endpoint = srv.URL
+
+	if _, err := s.Pull(context.Background(), "org/evil", nil); err == nil {
+		t.Error("Expected error for path traversal filename")
+	}
+}
+
+func TestDefaultDirEnvOverride(t *testing.T) {
+	t.Setenv("MLXVLLM_MODELS", "/tmp/custom-models")
+	if got := DefaultDir(); got != "/tmp/custom-models" {
+		t.Errorf("DefaultDir = %q, want /tmp/custom-models", got)
+	}
+}
@@ -0,0 +1,166 @@
+// Package notify delivers server events (model loads, quota
+// rejections, restarts) to configured webhook URLs with HMAC signing
+// and bounded retries, for integration with ops tooling.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries bounds delivery attempts per URL
+	DefaultMaxRetries = 3
+	// DefaultBackoff spaces retry attempts
+	DefaultBackoff = 500 * time.Millisecond
+	// DefaultQueueSize buffers events between the request path and the
+	// delivery worker; overflow drops rather than blocks
+	DefaultQueueSize = 128
+	// SignatureHeader carries the HMAC-SHA256 of the request body
+	SignatureHeader = "X-MLXVLLM-Signature"
+
+	requestTimeout = 5 * time.Second
+)
+
+// Event is one delivered notification
+type Event struct {
+	Type   string                 `json:"type"`
+	At     time.Time              `json:"at"`
+	Detail map[string]interface{} `json:"detail,omitempty"`
+}
+
+// Config sets the delivery targets and retry policy
+type Config struct {
+	// URLs receive every event
+	URLs []string
+	// Secret signs request bodies when set; receivers verify the
+	// X-MLXVLLM-Signature header
+	Secret string
+	// MaxRetries bounds attempts per URL per event
+	MaxRetries int
+	// Backoff spaces retries
+	Backoff time.Duration
+}
+
+// Notifier queues events and delivers them from a single worker, so
+// the request path never waits on a webhook
+type Notifier struct {
+	cfg    Config
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+}
+
+// New applies defaults and starts the delivery worker
+func New(cfg Config) *Notifier {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	n := &Notifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: requestTimeout},
+		queue:  make(chan Event, DefaultQueueSize),
+		done:   make(chan struct{}),
+	}
+	go n.worker()
+	return n
+}
+
+// Emit queues an event without blocking; a full queue drops the event
+// with a log line rather than stalling a request
+func (n *Notifier) Emit(eventType string, detail map[string]interface{}) {
+	ev := Event{Type: eventType, At: time.Now(), Detail: detail}
+	select {
+	case n.queue <- ev:
+	default:
+		log.Printf("Warning: notification queue full, dropping %s event", eventType)
+	}
+}
+
+// Close stops the worker after draining queued events
+func (n *Notifier) Close() {
+	close(n.queue)
+	<-n.done
+}
+
+func (n *Notifier) worker() {
+	defer close(n.done)
+	for ev := range n.queue {
+		n.deliver(ev)
+	}
+}
+
+// deliver posts one event to every URL, retrying each independently
+func (n *Notifier) deliver(ev Event) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	for _, url := range n.cfg.URLs {
+		if err := n.post(url, body); err != nil {
+			log.Printf("Warning: failed to deliver %s event to %s: %v", ev.Type, url, err)
+		}
+	}
+}
+
+// post attempts delivery with backoff between retries
+func (n *Notifier) post(url string, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt < n.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(n.cfg.Backoff)
+		}
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if n.cfg.Secret != "" {
+			req.Header.Set(SignatureHeader, Sign(n.cfg.Secret, body))
+		}
+
+		resp, err := n.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = &statusError{code: resp.StatusCode}
+	}
+	return lastErr
+}
+
+// Sign computes the signature receivers verify: sha256= followed by
+// the hex HMAC-SHA256 of the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received signature against the body; for use by Go
+// webhook receivers
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook returned %d", e.code)
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmitDeliversSignedEvent(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer srv.Close()
+
+	n := New(Config{URLs: []string{srv.URL}, Secret: "s3cret"})
+	n.Emit("model.loaded", map[string]interface{}{"model": "gui-actor-2b"})
+	n.Close()
+
+	select {
+	case r := <-received:
+		sig := r.Header.Get(SignatureHeader)
+		if !Verify("s3cret", body, sig) {
+			t.Errorf("signature %q does not verify", sig)
+		}
+		var ev Event
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Fatalf("bad event payload: %v", err)
+		}
+		if ev.Type != "model.loaded" || ev.Detail["model"] != "gui-actor-2b" {
+			t.Errorf("event = %+v", ev)
+		}
+		if ev.At.IsZero() {
+			t.Error("event timestamp not set")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	n := New(Config{URLs: []string{srv.URL}, MaxRetries: 3, Backoff: time.Millisecond})
+	n.Emit("job.finished", nil)
+	n.Close()
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n := New(Config{URLs: []string{srv.URL}, MaxRetries: 2, Backoff: time.Millisecond})
+	n.Emit("oom", nil)
+	n.Close()
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestMultipleURLs(t *testing.T) {
+	var first, second int32
+	srvA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&first, 1)
+	}))
+	defer srvA.Close()
+	srvB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&second, 1)
+	}))
+	defer srvB.Close()
+
+	n := New(Config{URLs: []string{srvA.URL, srvB.URL}})
+	n.Emit("breaker.tripped", nil)
+	n.Close()
+
+	if first != 1 || second != 1 {
+		t.Errorf("deliveries = (%d, %d), want (1, 1)", first, second)
+	}
+}
+
+func TestUnsignedWithoutSecret(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(SignatureHeader)
+	}))
+	defer srv.Close()
+
+	n := New(Config{URLs: []string{srv.URL}})
+	n.Emit("model.unloaded", nil)
+	n.Close()
+
+	if sig := <-received; sig != "" {
+		t.Errorf("signature = %q, want empty without a secret", sig)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	body := []byte(`{"type":"quota.exceeded"}`)
+	sig := Sign("secret", body)
+	if !Verify("secret", body, sig) {
+		t.Error("valid signature rejected")
+	}
+	if Verify("secret", []byte(`{"type":"forged"}`), sig) {
+		t.Error("tampered body accepted")
+	}
+	if Verify("wrong", body, sig) {
+		t.Error("wrong secret accepted")
+	}
+}
@@ -0,0 +1,74 @@
+package ocr
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+type fakeProvider struct {
+	name  string
+	boxes []Box
+}
+
+func (f *fakeProvider) Name() string                         { return f.name }
+func (f *fakeProvider) Recognize(image.Image) ([]Box, error) { return f.boxes, nil }
+
+func TestRegistryGetUnknown(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Get("applevision"); err == nil {
+		t.Error("expected error for empty registry")
+	}
+
+	r.Register(&fakeProvider{name: "tesseract"})
+	_, err := r.Get("applevision")
+	if err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+	if !strings.Contains(err.Error(), "tesseract") {
+		t.Errorf("error %q does not list available providers", err)
+	}
+}
+
+func TestRegistryRegisterAndGet(t *testing.T) {
+	r := NewRegistry()
+	p := &fakeProvider{name: "tesseract"}
+	r.Register(p)
+
+	got, err := r.Get("tesseract")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got != Provider(p) {
+		t.Error("Get returned a different provider")
+	}
+}
+
+func TestFormatHintsOrderAndCenters(t *testing.T) {
+	boxes := []Box{
+		{Text: "Cancel", X0: 0.6, Y0: 0.8, X1: 0.7, Y1: 0.85},
+		{Text: "Save", X0: 0.4, Y0: 0.8, X1: 0.5, Y1: 0.85},
+		{Text: "Settings", X0: 0.1, Y0: 0.1, X1: 0.3, Y1: 0.15},
+	}
+
+	hints := FormatHints(boxes)
+	if !strings.HasPrefix(hints, "Detected on-screen text") {
+		t.Errorf("hints missing header: %q", hints)
+	}
+	// Top-to-bottom, left-to-right
+	si := strings.Index(hints, "Settings")
+	sv := strings.Index(hints, "Save")
+	ca := strings.Index(hints, "Cancel")
+	if !(si < sv && sv < ca) {
+		t.Errorf("hints out of reading order: %q", hints)
+	}
+	if !strings.Contains(hints, `"Save" at (0.4500, 0.8250)`) {
+		t.Errorf("hints missing center coordinates: %q", hints)
+	}
+}
+
+func TestFormatHintsEmpty(t *testing.T) {
+	if got := FormatHints(nil); got != "" {
+		t.Errorf("FormatHints(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,282 @@
+package onnx
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// Model file names inside the exported model directory
+const (
+	VisionTowerFile = "vision_tower.onnx"
+	PointerHeadFile = "pointer_head.onnx"
+	LLMFile         = "llm.onnx"
+)
+
+// KV cache tensor naming follows the HuggingFace ONNX export convention:
+// inputs "past_key_values.<layer>.key" / ".value" and
+// outputs "present.<layer>.key" / ".value".
+const (
+	pastKeyFmt      = "past_key_values.%d.key"
+	pastValueFmt    = "past_key_values.%d.value"
+	presentKeyFmt   = "present.%d.key"
+	presentValueFmt = "present.%d.value"
+)
+
+// EngineConfig holds the static model parameters the decode loop needs
+type EngineConfig struct {
+	NumLayers  int   // Transformer layer count (KV cache pairs)
+	NumKVHeads int   // Key/value head count per layer
+	HeadDim    int   // Per-head dimension
+	EOSToken   int64 // Generation stops on this token
+	MaxTokens  int   // Default generation budget when request has none
+
+	// Options selects the execution provider for all sessions
+	Options SessionOptions
+
+	// PoolSize is the number of vision and pointer sessions loaded per
+	// model so independent inferences can run concurrently (default 1).
+	// The LLM session is not pooled: its KV cache state is per-request.
+	PoolSize int
+}
+
+// Engine runs the full ONNX inference pipeline:
+// vision tower -> LLM backbone (iterative decode) -> pointer head.
+// It is the CPU/CoreML fallback for machines without MLX.
+type Engine struct {
+	cfg     EngineConfig
+	vision  *SessionPool
+	pointer *SessionPool
+	llm     Session
+}
+
+// NewEngine loads the three sessions from modelDir using the given loader.
+// Pass nil loader to use DefaultLoader.
+func NewEngine(modelDir string, cfg EngineConfig, loader SessionLoader) (*Engine, error) {
+	if loader == nil {
+		loader = DefaultLoader
+	}
+	if err := cfg.Options.Validate(); err != nil {
+		return nil, err
+	}
+
+	vision, err := NewSessionPool(filepath.Join(modelDir, VisionTowerFile), cfg.PoolSize, cfg.Options, loader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vision tower: %w", err)
+	}
+
+	pointer, err := NewSessionPool(filepath.Join(modelDir, PointerHeadFile), cfg.PoolSize, cfg.Options, loader)
+	if err != nil {
+		vision.Close()
+		return nil, fmt.Errorf("failed to load pointer head: %w", err)
+	}
+
+	llm, err := loadSession(loader, filepath.Join(modelDir, LLMFile), cfg.Options)
+	if err != nil {
+		vision.Close()
+		pointer.Close()
+		return nil, fmt.Errorf("failed to load LLM backbone: %w", err)
+	}
+
+	return &Engine{cfg: cfg, vision: vision, pointer: pointer, llm: llm}, nil
+}
+
+// loadSession opens one session and logs which provider it actually
+// bound to, so CPU fallback from CoreML is visible instead of silent
+func loadSession(loader SessionLoader, modelPath string, opts SessionOptions) (Session, error) {
+	s, err := loader(modelPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := ProviderCPU
+	if opts.UseCoreML {
+		requested = ProviderCoreML
+	}
+	bound := s.Provider()
+	if bound == requested {
+		log.Printf("onnx: %s bound to %s", filepath.Base(modelPath), bound)
+	} else {
+		log.Printf("onnx: %s requested %s but bound to %s", filepath.Base(modelPath), requested, bound)
+	}
+	return s, nil
+}
+
+// Close releases all sessions
+func (e *Engine) Close() error {
+	var firstErr error
+	for _, p := range []*SessionPool{e.vision, e.pointer} {
+		if p == nil {
+			continue
+		}
+		if err := p.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if e.llm != nil {
+		if err := e.llm.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// EncodeImage runs the vision tower over preprocessed pixel values
+// and returns the visual patch embeddings
+func (e *Engine) EncodeImage(pixelValues *Tensor) (*Tensor, error) {
+	outputs, err := e.vision.Run(map[string]*Tensor{"pixel_values": pixelValues})
+	if err != nil {
+		return nil, fmt.Errorf("vision tower failed: %w", err)
+	}
+	embeds, ok := outputs["image_embeds"]
+	if !ok {
+		return nil, fmt.Errorf("vision tower returned no image_embeds output")
+	}
+	return embeds, nil
+}
+
+// GenerateResult holds the output of a full decode run
+type GenerateResult struct {
+	Tokens       []int64 // Generated token IDs (EOS excluded)
+	HiddenStates *Tensor // Last-layer hidden states from the final step, for the pointer head
+	PromptTokens int
+}
+
+// Generate runs the LLM backbone autoregressively.
+// The first step prefills the whole prompt with empty KV caches; each
+// subsequent step feeds one token plus the present.* tensors from the
+// previous step as past_key_values.* inputs.
+func (e *Engine) Generate(promptTokens []int64, maxTokens int) (*GenerateResult, error) {
+	if len(promptTokens) == 0 {
+		return nil, fmt.Errorf("empty prompt")
+	}
+	if maxTokens <= 0 {
+		maxTokens = e.cfg.MaxTokens
+	}
+
+	result := &GenerateResult{PromptTokens: len(promptTokens)}
+
+	// Prefill: full prompt, zero-length KV caches
+	inputTokens := promptTokens
+	past := e.emptyCache()
+	seqLen := 0
+
+	for step := 0; step <= maxTokens; step++ {
+		inputs := e.decodeInputs(inputTokens, seqLen, past)
+
+		outputs, err := e.llm.Run(inputs)
+		if err != nil {
+			return nil, fmt.Errorf("LLM step %d failed: %w", step, err)
+		}
+
+		logits, ok := outputs["logits"]
+		if !ok {
+			return nil, fmt.Errorf("LLM returned no logits output")
+		}
+		if hidden, ok := outputs["hidden_states"]; ok {
+			result.HiddenStates = hidden
+		}
+
+		past, err = e.collectPresent(outputs)
+		if err != nil {
+			return nil, err
+		}
+		seqLen += len(inputTokens)
+
+		next, err := argmaxLastPosition(logits)
+		if err != nil {
+			return nil, err
+		}
+		if next == e.cfg.EOSToken || step == maxTokens {
+			break
+		}
+
+		result.Tokens = append(result.Tokens, next)
+		inputTokens = []int64{next}
+	}
+
+	return result, nil
+}
+
+// decodeInputs assembles the input map for one LLM step
+func (e *Engine) decodeInputs(tokens []int64, pastLen int, past map[string]*Tensor) map[string]*Tensor {
+	n := int64(len(tokens))
+	total := int64(pastLen) + n
+
+	inputs := map[string]*Tensor{
+		"input_ids": NewIntTensor([]int64{1, n}, tokens),
+	}
+
+	// Attention mask covers cached positions plus the new tokens
+	mask := make([]int64, total)
+	for i := range mask {
+		mask[i] = 1
+	}
+	inputs["attention_mask"] = NewIntTensor([]int64{1, total}, mask)
+
+	// Position IDs continue from the cached length
+	positions := make([]int64, n)
+	for i := range positions {
+		positions[i] = int64(pastLen) + int64(i)
+	}
+	inputs["position_ids"] = NewIntTensor([]int64{1, n}, positions)
+
+	for name, t := range past {
+		inputs[name] = t
+	}
+	return inputs
+}
+
+// emptyCache builds zero-length past_key_values tensors for the prefill step
+func (e *Engine) emptyCache() map[string]*Tensor {
+	past := make(map[string]*Tensor, e.cfg.NumLayers*2)
+	shape := []int64{1, int64(e.cfg.NumKVHeads), 0, int64(e.cfg.HeadDim)}
+	for i := 0; i < e.cfg.NumLayers; i++ {
+		past[fmt.Sprintf(pastKeyFmt, i)] = NewFloatTensor(shape, nil)
+		past[fmt.Sprintf(pastValueFmt, i)] = NewFloatTensor(shape, nil)
+	}
+	return past
+}
+
+// collectPresent maps present.* outputs to past_key_values.* inputs for the next step
+func (e *Engine) collectPresent(outputs map[string]*Tensor) (map[string]*Tensor, error) {
+	past := make(map[string]*Tensor, e.cfg.NumLayers*2)
+	for i := 0; i < e.cfg.NumLayers; i++ {
+		key, ok := outputs[fmt.Sprintf(presentKeyFmt, i)]
+		if !ok {
+			return nil, fmt.Errorf("LLM returned no present.%d.key output", i)
+		}
+		value, ok := outputs[fmt.Sprintf(presentValueFmt, i)]
+		if !ok {
+			return nil, fmt.Errorf("LLM returned no present.%d.value output", i)
+		}
+		past[fmt.Sprintf(pastKeyFmt, i)] = key
+		past[fmt.Sprintf(pastValueFmt, i)] = value
+	}
+	return past, nil
+}
+
+// argmaxLastPosition picks the highest-scoring token from the last
+// sequence position of a [batch, seq, vocab] logits tensor
+func argmaxLastPosition(logits *Tensor) (int64, error) {
+	if len(logits.Shape) != 3 {
+		return 0, fmt.Errorf("expected [batch, seq, vocab] logits, got shape %v", logits.Shape)
+	}
+	seq, vocab := logits.Shape[1], logits.Shape[2]
+	if seq == 0 || vocab == 0 || int64(len(logits.FloatData)) < seq*vocab {
+		return 0, fmt.Errorf("logits tensor too small for shape %v", logits.Shape)
+	}
+
+	offset := (seq - 1) * vocab
+	last := logits.FloatData[offset : offset+vocab]
+
+	best := int64(0)
+	bestScore := last[0]
+	for i, score := range last {
+		if score > bestScore {
+			best = int64(i)
+			bestScore = score
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,238 @@
+package onnx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mockSession is a scriptable Session for tests
+type mockSession struct {
+	runFunc func(inputs map[string]*Tensor) (map[string]*Tensor, error)
+	closed  bool
+}
+
+func (m *mockSession) Run(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+	return m.runFunc(inputs)
+}
+
+func (m *mockSession) InputNames() []string  { return nil }
+func (m *mockSession) OutputNames() []string { return nil }
+func (m *mockSession) Provider() string      { return ProviderCPU }
+func (m *mockSession) Close() error {
+	m.closed = true
+	return nil
+}
+
+// mockLLMLoader returns a loader whose LLM session emits the given
+// token sequence one step at a time, echoing valid KV cache tensors
+func mockLLMLoader(cfg EngineConfig, script []int64) SessionLoader {
+	step := 0
+	llm := &mockSession{
+		runFunc: func(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+			ids, ok := inputs["input_ids"]
+			if !ok {
+				return nil, fmt.Errorf("missing input_ids")
+			}
+			past, ok := inputs["past_key_values.0.key"]
+			if !ok {
+				return nil, fmt.Errorf("missing KV cache inputs")
+			}
+
+			seq := int64(len(ids.IntData))
+			total := past.Shape[2] + seq
+			vocab := int64(16)
+			logits := make([]float32, seq*vocab)
+			next := cfg.EOSToken
+			if step < len(script) {
+				next = script[step]
+			}
+			step++
+			logits[(seq-1)*vocab+next] = 1.0
+
+			outputs := map[string]*Tensor{
+				"logits": NewFloatTensor([]int64{1, seq, vocab}, logits),
+			}
+			for i := 0; i < cfg.NumLayers; i++ {
+				shape := []int64{1, int64(cfg.NumKVHeads), total, int64(cfg.HeadDim)}
+				outputs[fmt.Sprintf("present.%d.key", i)] = NewFloatTensor(shape, nil)
+				outputs[fmt.Sprintf("present.%d.value", i)] = NewFloatTensor(shape, nil)
+			}
+			return outputs, nil
+		},
+	}
+
+	return func(modelPath string, opts SessionOptions) (Session, error) {
+		if strings.HasSuffix(modelPath, LLMFile) {
+			return llm, nil
+		}
+		return &mockSession{
+			runFunc: func(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+				return map[string]*Tensor{
+					"image_embeds": NewFloatTensor([]int64{4, 8}, make([]float32, 32)),
+				}, nil
+			},
+		}, nil
+	}
+}
+
+func testConfig() EngineConfig {
+	return EngineConfig{
+		NumLayers:  2,
+		NumKVHeads: 2,
+		HeadDim:    4,
+		EOSToken:   15,
+		MaxTokens:  32,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    []int64
+		maxTokens int
+		expect    []int64
+	}{
+		{"stops on EOS", []int64{3, 7, 15}, 10, []int64{3, 7}},
+		{"stops at max tokens", []int64{1, 2, 3, 4, 5}, 3, []int64{1, 2, 3}},
+		{"immediate EOS", []int64{15}, 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			engine, err := NewEngine("/tmp/model", cfg, mockLLMLoader(cfg, tt.script))
+			if err != nil {
+				t.Fatalf("NewEngine failed: %v", err)
+			}
+			defer engine.Close()
+
+			result, err := engine.Generate([]int64{10, 11, 12}, tt.maxTokens)
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			if len(result.Tokens) != len(tt.expect) {
+				t.Fatalf("got %v tokens, want %v", result.Tokens, tt.expect)
+			}
+			for i, tok := range result.Tokens {
+				if tok != tt.expect[i] {
+					t.Errorf("token %d = %d, want %d", i, tok, tt.expect[i])
+				}
+			}
+			if result.PromptTokens != 3 {
+				t.Errorf("PromptTokens = %d, want 3", result.PromptTokens)
+			}
+		})
+	}
+}
+
+func TestGenerateEmptyPrompt(t *testing.T) {
+	cfg := testConfig()
+	engine, err := NewEngine("/tmp/model", cfg, mockLLMLoader(cfg, nil))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	if _, err := engine.Generate(nil, 10); err == nil {
+		t.Error("Expected error for empty prompt")
+	}
+}
+
+func TestGenerateKVCacheThreading(t *testing.T) {
+	cfg := testConfig()
+	var pastLens []int64
+
+	loader := mockLLMLoader(cfg, []int64{1, 2, 15})
+	base := loader
+	// Wrap the LLM session to record cached sequence lengths per step
+	wrapped := func(modelPath string, opts SessionOptions) (Session, error) {
+		s, err := base(modelPath, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(modelPath, LLMFile) {
+			return s, nil
+		}
+		inner := s
+		return &mockSession{
+			runFunc: func(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+				past := inputs["past_key_values.0.key"]
+				pastLens = append(pastLens, past.Shape[2])
+				return inner.Run(inputs)
+			},
+		}, nil
+	}
+
+	engine, err := NewEngine("/tmp/model", cfg, wrapped)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	if _, err := engine.Generate([]int64{10, 11, 12}, 10); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// Prefill sees an empty cache, then the cache grows by one token per step
+	want := []int64{0, 3, 4}
+	if len(pastLens) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(pastLens), len(want))
+	}
+	for i, l := range pastLens {
+		if l != want[i] {
+			t.Errorf("step %d past length = %d, want %d", i, l, want[i])
+		}
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	cfg := testConfig()
+	engine, err := NewEngine("/tmp/model", cfg, mockLLMLoader(cfg, nil))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	embeds, err := engine.EncodeImage(NewFloatTensor([]int64{1, 3, 56, 56}, make([]float32, 3*56*56)))
+	if err != nil {
+		t.Fatalf("EncodeImage failed: %v", err)
+	}
+	if embeds.NumElements() != 32 {
+		t.Errorf("embeds size = %d, want 32", embeds.NumElements())
+	}
+}
+
+func TestNewEngineLoaderError(t *testing.T) {
+	failing := func(modelPath string, opts SessionOptions) (Session, error) {
+		return nil, fmt.Errorf("no such file")
+	}
+	if _, err := NewEngine("/tmp/model", testConfig(), failing); err == nil {
+		t.Error("Expected error from failing loader")
+	}
+}
+
+func TestDefaultLoaderFails(t *testing.T) {
+	if _, err := DefaultLoader("model.onnx", SessionOptions{}); err == nil {
+		t.Error("Expected DefaultLoader to fail without onnxruntime")
+	}
+}
+
+func TestArgmaxLastPosition(t *testing.T) {
+	logits := NewFloatTensor([]int64{1, 2, 4}, []float32{
+		9, 0, 0, 0, // earlier position must be ignored
+		0, 1, 5, 2,
+	})
+	tok, err := argmaxLastPosition(logits)
+	if err != nil {
+		t.Fatalf("argmaxLastPosition failed: %v", err)
+	}
+	if tok != 2 {
+		t.Errorf("argmax = %d, want 2", tok)
+	}
+
+	if _, err := argmaxLastPosition(NewFloatTensor([]int64{1, 4}, nil)); err == nil {
+		t.Error("Expected error for 2D logits")
+	}
+}
@@ -0,0 +1,70 @@
+package onnx
+
+import (
+	"fmt"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+// Execution providers a session may bind to
+const (
+	ProviderCPU    = "CPUExecutionProvider"
+	ProviderCoreML = "CoreMLExecutionProvider"
+)
+
+// CoreML compute unit selections (MLComputeUnits)
+const (
+	ComputeUnitsAll      = "ALL"
+	ComputeUnitsCPUAndNE = "CPU_AND_NE"
+	ComputeUnitsCPUOnly  = "CPU_ONLY"
+)
+
+// CoreMLOptions configures the CoreML execution provider
+type CoreMLOptions struct {
+	// ComputeUnits selects which hardware CoreML may dispatch to
+	ComputeUnits string
+	// ModelCacheDir persists compiled CoreML models between runs
+	ModelCacheDir string
+	// AllowLowPrecision permits fp16 accumulation on the ANE
+	AllowLowPrecision bool
+}
+
+// SessionOptions configures how sessions bind to an execution provider
+type SessionOptions struct {
+	// UseCoreML requests the CoreML EP with CPU fallback
+	UseCoreML bool
+	CoreML    CoreMLOptions
+}
+
+// Validate checks that the options are internally consistent
+func (o SessionOptions) Validate() error {
+	if !o.UseCoreML {
+		return nil
+	}
+	switch o.CoreML.ComputeUnits {
+	case "", ComputeUnitsAll, ComputeUnitsCPUAndNE, ComputeUnitsCPUOnly:
+		return nil
+	default:
+		return fmt.Errorf("invalid coreml compute_units %q (want %s, %s, or %s)",
+			o.CoreML.ComputeUnits, ComputeUnitsAll, ComputeUnitsCPUAndNE, ComputeUnitsCPUOnly)
+	}
+}
+
+// OptionsFromConfig builds session options from the server config
+func OptionsFromConfig(cfg config.OnnxConfig) (SessionOptions, error) {
+	opts := SessionOptions{
+		UseCoreML: cfg.CoreML.Enabled,
+		CoreML: CoreMLOptions{
+			ComputeUnits:      cfg.CoreML.ComputeUnits,
+			ModelCacheDir:     cfg.CoreML.ModelCacheDir,
+			AllowLowPrecision: cfg.CoreML.AllowLowPrecision,
+		},
+	}
+	if opts.CoreML.ComputeUnits == "" {
+		opts.CoreML.ComputeUnits = ComputeUnitsAll
+	}
+	if err := opts.Validate(); err != nil {
+		return SessionOptions{}, err
+	}
+	return opts, nil
+}
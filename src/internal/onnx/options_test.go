@@ -0,0 +1,78 @@
+package onnx
+
+import (
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func TestSessionOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SessionOptions
+		expectError bool
+	}{
+		{"cpu only ignores compute units", SessionOptions{UseCoreML: false, CoreML: CoreMLOptions{ComputeUnits: "bogus"}}, false},
+		{"coreml all", SessionOptions{UseCoreML: true, CoreML: CoreMLOptions{ComputeUnits: ComputeUnitsAll}}, false},
+		{"coreml cpu and ne", SessionOptions{UseCoreML: true, CoreML: CoreMLOptions{ComputeUnits: ComputeUnitsCPUAndNE}}, false},
+		{"coreml cpu only", SessionOptions{UseCoreML: true, CoreML: CoreMLOptions{ComputeUnits: ComputeUnitsCPUOnly}}, false},
+		{"coreml empty defaults ok", SessionOptions{UseCoreML: true}, false},
+		{"coreml invalid units", SessionOptions{UseCoreML: true, CoreML: CoreMLOptions{ComputeUnits: "GPU_ONLY"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected validation error")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	cfg := config.OnnxConfig{
+		CoreML: config.CoreMLConfig{
+			Enabled:           true,
+			ModelCacheDir:     "/tmp/coreml-cache",
+			AllowLowPrecision: true,
+		},
+	}
+
+	opts, err := OptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("OptionsFromConfig failed: %v", err)
+	}
+	if !opts.UseCoreML {
+		t.Error("Expected UseCoreML to be true")
+	}
+	if opts.CoreML.ComputeUnits != ComputeUnitsAll {
+		t.Errorf("Expected default compute units %s, got %s", ComputeUnitsAll, opts.CoreML.ComputeUnits)
+	}
+	if opts.CoreML.ModelCacheDir != "/tmp/coreml-cache" {
+		t.Errorf("Unexpected cache dir %s", opts.CoreML.ModelCacheDir)
+	}
+	if !opts.CoreML.AllowLowPrecision {
+		t.Error("Expected AllowLowPrecision to be true")
+	}
+}
+
+func TestOptionsFromConfigInvalid(t *testing.T) {
+	cfg := config.OnnxConfig{
+		CoreML: config.CoreMLConfig{Enabled: true, ComputeUnits: "NE_ONLY"},
+	}
+	if _, err := OptionsFromConfig(cfg); err == nil {
+		t.Error("Expected error for invalid compute units")
+	}
+}
+
+func TestNewEngineInvalidOptions(t *testing.T) {
+	cfg := testConfig()
+	cfg.Options = SessionOptions{UseCoreML: true, CoreML: CoreMLOptions{ComputeUnits: "bogus"}}
+	if _, err := NewEngine("/tmp/model", cfg, mockLLMLoader(cfg, nil)); err == nil {
+		t.Error("Expected error for invalid session options")
+	}
+}
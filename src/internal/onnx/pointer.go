@@ -0,0 +1,156 @@
+package onnx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ClickPoint is a candidate interaction point in normalized [0,1] coordinates
+type ClickPoint struct {
+	X     float64
+	Y     float64
+	Score float32
+}
+
+// DefaultCentroidWindow is the neighborhood size (in patches) used for
+// sub-patch refinement when the caller does not configure one
+const DefaultCentroidWindow = 3
+
+// DefaultPeakThreshold keeps secondary peaks scoring at least this
+// fraction of the best peak
+const DefaultPeakThreshold = 0.5
+
+// GetClickPoint converts pointer-head attention scores over the patch
+// grid into a click point. Instead of snapping to the argmax patch
+// center, it refines the position with a weighted centroid over a
+// window x window neighborhood, which recovers sub-patch precision on
+// targets that straddle patch boundaries.
+func GetClickPoint(scores []float32, gridW, gridH, window int) (ClickPoint, error) {
+	if gridW <= 0 || gridH <= 0 || len(scores) < gridW*gridH {
+		return ClickPoint{}, fmt.Errorf("scores length %d too small for %dx%d grid", len(scores), gridW, gridH)
+	}
+	if window <= 0 {
+		window = DefaultCentroidWindow
+	}
+
+	best := argmax2D(scores, gridW, gridH)
+	return refineCentroid(scores, gridW, gridH, best, window), nil
+}
+
+// FindPeaks returns local attention maxima scoring at least
+// threshold * max, sorted by descending score. More than one peak
+// signals an ambiguous target (e.g. duplicate buttons on a dense UI);
+// callers can surface the alternatives or route them to the verifier.
+func FindPeaks(scores []float32, gridW, gridH int, threshold float32, window int) ([]ClickPoint, error) {
+	if gridW <= 0 || gridH <= 0 || len(scores) < gridW*gridH {
+		return nil, fmt.Errorf("scores length %d too small for %dx%d grid", len(scores), gridW, gridH)
+	}
+	if threshold <= 0 {
+		threshold = DefaultPeakThreshold
+	}
+	if window <= 0 {
+		window = DefaultCentroidWindow
+	}
+
+	best := argmax2D(scores, gridW, gridH)
+	maxScore := scores[best.y*gridW+best.x]
+	cutoff := maxScore * threshold
+
+	var peaks []ClickPoint
+	for y := 0; y < gridH; y++ {
+		for x := 0; x < gridW; x++ {
+			score := scores[y*gridW+x]
+			if score < cutoff || score <= 0 {
+				continue
+			}
+			if !isLocalMax(scores, gridW, gridH, x, y) {
+				continue
+			}
+			peaks = append(peaks, refineCentroid(scores, gridW, gridH, cell{x, y}, window))
+		}
+	}
+
+	sort.Slice(peaks, func(i, j int) bool { return peaks[i].Score > peaks[j].Score })
+	return peaks, nil
+}
+
+type cell struct{ x, y int }
+
+// argmax2D returns the highest-scoring grid cell
+func argmax2D(scores []float32, gridW, gridH int) cell {
+	best := cell{}
+	bestScore := scores[0]
+	for y := 0; y < gridH; y++ {
+		for x := 0; x < gridW; x++ {
+			if s := scores[y*gridW+x]; s > bestScore {
+				best = cell{x, y}
+				bestScore = s
+			}
+		}
+	}
+	return best
+}
+
+// isLocalMax reports whether (x,y) dominates its 8-neighborhood.
+// Ties are broken by position so plateaus yield a single peak.
+func isLocalMax(scores []float32, gridW, gridH, x, y int) bool {
+	score := scores[y*gridW+x]
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			nx, ny := x+dx, y+dy
+			if nx < 0 || nx >= gridW || ny < 0 || ny >= gridH {
+				continue
+			}
+			neighbor := scores[ny*gridW+nx]
+			if neighbor > score {
+				return false
+			}
+			if neighbor == score && (ny < y || (ny == y && nx < x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// refineCentroid computes the attention-weighted centroid over a
+// window x window neighborhood around the given cell
+func refineCentroid(scores []float32, gridW, gridH int, center cell, window int) ClickPoint {
+	half := window / 2
+
+	var sumW, sumX, sumY float64
+	for dy := -half; dy <= half; dy++ {
+		for dx := -half; dx <= half; dx++ {
+			x, y := center.x+dx, center.y+dy
+			if x < 0 || x >= gridW || y < 0 || y >= gridH {
+				continue
+			}
+			w := float64(scores[y*gridW+x])
+			if w <= 0 {
+				continue
+			}
+			sumW += w
+			sumX += w * (float64(x) + 0.5)
+			sumY += w * (float64(y) + 0.5)
+		}
+	}
+
+	peakScore := scores[center.y*gridW+center.x]
+	if sumW == 0 {
+		// Degenerate scores: fall back to the patch center
+		return ClickPoint{
+			X:     (float64(center.x) + 0.5) / float64(gridW),
+			Y:     (float64(center.y) + 0.5) / float64(gridH),
+			Score: peakScore,
+		}
+	}
+
+	return ClickPoint{
+		X:     sumX / sumW / float64(gridW),
+		Y:     sumY / sumW / float64(gridH),
+		Score: peakScore,
+	}
+}
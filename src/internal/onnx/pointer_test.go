@@ -0,0 +1,133 @@
+package onnx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetClickPointArgmaxCenter(t *testing.T) {
+	// Single hot patch in a 4x4 grid: centroid equals the patch center
+	scores := make([]float32, 16)
+	scores[1*4+2] = 1.0
+
+	pt, err := GetClickPoint(scores, 4, 4, 3)
+	if err != nil {
+		t.Fatalf("GetClickPoint failed: %v", err)
+	}
+	if math.Abs(pt.X-0.625) > 1e-9 || math.Abs(pt.Y-0.375) > 1e-9 {
+		t.Errorf("point = (%v, %v), want (0.625, 0.375)", pt.X, pt.Y)
+	}
+	if pt.Score != 1.0 {
+		t.Errorf("score = %v, want 1.0", pt.Score)
+	}
+}
+
+func TestGetClickPointCentroidRefinement(t *testing.T) {
+	// Mass split between two horizontal neighbors pulls the centroid
+	// toward the boundary between them
+	scores := make([]float32, 16)
+	scores[1*4+1] = 1.0
+	scores[1*4+2] = 1.0
+
+	pt, err := GetClickPoint(scores, 4, 4, 3)
+	if err != nil {
+		t.Fatalf("GetClickPoint failed: %v", err)
+	}
+	// Centroid of patches 1 and 2 in a 4-wide grid: (1.5+0.5)/2 / 4 = 0.5
+	if math.Abs(pt.X-0.5) > 1e-9 {
+		t.Errorf("X = %v, want 0.5", pt.X)
+	}
+	if math.Abs(pt.Y-0.375) > 1e-9 {
+		t.Errorf("Y = %v, want 0.375", pt.Y)
+	}
+}
+
+func TestGetClickPointWindowLimitsNeighborhood(t *testing.T) {
+	// A distant patch must not influence a window-1 centroid
+	scores := make([]float32, 64)
+	scores[3*8+3] = 1.0
+	scores[3*8+6] = 0.9
+
+	pt, err := GetClickPoint(scores, 8, 8, 1)
+	if err != nil {
+		t.Fatalf("GetClickPoint failed: %v", err)
+	}
+	want := (3.0 + 0.5) / 8.0
+	if math.Abs(pt.X-want) > 1e-9 {
+		t.Errorf("X = %v, want %v", pt.X, want)
+	}
+}
+
+func TestGetClickPointInvalidGrid(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float32
+		gw, gh int
+	}{
+		{"empty scores", nil, 4, 4},
+		{"zero grid", []float32{1}, 0, 0},
+		{"short scores", make([]float32, 3), 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetClickPoint(tt.scores, tt.gw, tt.gh, 3); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestGetClickPointDegenerateScores(t *testing.T) {
+	// All-zero attention still yields a valid in-range point
+	pt, err := GetClickPoint(make([]float32, 16), 4, 4, 3)
+	if err != nil {
+		t.Fatalf("GetClickPoint failed: %v", err)
+	}
+	if pt.X < 0 || pt.X > 1 || pt.Y < 0 || pt.Y > 1 {
+		t.Errorf("point (%v, %v) out of range", pt.X, pt.Y)
+	}
+}
+
+func TestFindPeaksMultiPeak(t *testing.T) {
+	// Two separated peaks: a strong one and one above the 0.5 cutoff
+	scores := make([]float32, 64)
+	scores[1*8+1] = 1.0
+	scores[6*8+6] = 0.7
+	scores[4*8+4] = 0.1 // Below cutoff, ignored
+
+	peaks, err := FindPeaks(scores, 8, 8, 0.5, 1)
+	if err != nil {
+		t.Fatalf("FindPeaks failed: %v", err)
+	}
+	if len(peaks) != 2 {
+		t.Fatalf("got %d peaks, want 2", len(peaks))
+	}
+	if peaks[0].Score < peaks[1].Score {
+		t.Error("peaks not sorted by descending score")
+	}
+	if peaks[0].Score != 1.0 || peaks[1].Score != 0.7 {
+		t.Errorf("peak scores = %v, %v, want 1.0, 0.7", peaks[0].Score, peaks[1].Score)
+	}
+}
+
+func TestFindPeaksSinglePeakOnPlateau(t *testing.T) {
+	// A flat 2x2 plateau must produce exactly one peak, not four
+	scores := make([]float32, 64)
+	for _, idx := range []int{2*8 + 2, 2*8 + 3, 3*8 + 2, 3*8 + 3} {
+		scores[idx] = 1.0
+	}
+
+	peaks, err := FindPeaks(scores, 8, 8, 0.5, 1)
+	if err != nil {
+		t.Fatalf("FindPeaks failed: %v", err)
+	}
+	if len(peaks) != 1 {
+		t.Errorf("got %d peaks, want 1", len(peaks))
+	}
+}
+
+func TestFindPeaksInvalidGrid(t *testing.T) {
+	if _, err := FindPeaks(nil, 4, 4, 0.5, 3); err == nil {
+		t.Error("Expected error for empty scores")
+	}
+}
@@ -0,0 +1,59 @@
+package onnx
+
+// SessionPool holds N sessions for one model so independent inferences
+// can run in parallel instead of serializing behind a single session.
+// Acquisition is a buffered channel: Run blocks until a session is free,
+// which doubles as the scheduler (FIFO over waiting goroutines).
+type SessionPool struct {
+	free chan Session
+	all  []Session
+}
+
+// NewSessionPool loads size sessions of the same model.
+// Size 0 or below defaults to 1.
+func NewSessionPool(modelPath string, size int, opts SessionOptions, loader SessionLoader) (*SessionPool, error) {
+	if size <= 0 {
+		size = 1
+	}
+
+	pool := &SessionPool{
+		free: make(chan Session, size),
+		all:  make([]Session, 0, size),
+	}
+
+	for i := 0; i < size; i++ {
+		s, err := loadSession(loader, modelPath, opts)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.all = append(pool.all, s)
+		pool.free <- s
+	}
+
+	return pool, nil
+}
+
+// Run acquires a free session, executes it, and returns it to the pool
+func (p *SessionPool) Run(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+	s := <-p.free
+	defer func() { p.free <- s }()
+	return s.Run(inputs)
+}
+
+// Size returns the number of sessions in the pool
+func (p *SessionPool) Size() int {
+	return len(p.all)
+}
+
+// Close releases all sessions. The pool must be idle.
+func (p *SessionPool) Close() error {
+	var firstErr error
+	for _, s := range p.all {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	p.all = nil
+	return firstErr
+}
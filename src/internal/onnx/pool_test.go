@@ -0,0 +1,128 @@
+package onnx
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingLoader returns sessions that park in Run until released,
+// and counts how many are executing at once
+func blockingLoader(inFlight *atomic.Int32, maxSeen *atomic.Int32, release chan struct{}) SessionLoader {
+	return func(modelPath string, opts SessionOptions) (Session, error) {
+		return &mockSession{
+			runFunc: func(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+				n := inFlight.Add(1)
+				for {
+					seen := maxSeen.Load()
+					if n <= seen || maxSeen.CompareAndSwap(seen, n) {
+						break
+					}
+				}
+				<-release
+				inFlight.Add(-1)
+				return map[string]*Tensor{}, nil
+			},
+		}, nil
+	}
+}
+
+func TestSessionPoolConcurrency(t *testing.T) {
+	var inFlight, maxSeen atomic.Int32
+	release := make(chan struct{})
+
+	pool, err := NewSessionPool("model.onnx", 3, SessionOptions{}, blockingLoader(&inFlight, &maxSeen, release))
+	if err != nil {
+		t.Fatalf("NewSessionPool failed: %v", err)
+	}
+	defer pool.Close()
+
+	if pool.Size() != 3 {
+		t.Fatalf("pool size = %d, want 3", pool.Size())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.Run(nil); err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		}()
+	}
+
+	// Wait until the pool is saturated, then release everyone
+	deadline := time.After(2 * time.Second)
+	for inFlight.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("pool never saturated: in flight = %d", inFlight.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if maxSeen.Load() != 3 {
+		t.Errorf("max concurrent runs = %d, want 3 (pool size)", maxSeen.Load())
+	}
+}
+
+func TestSessionPoolDefaultSize(t *testing.T) {
+	loader := func(modelPath string, opts SessionOptions) (Session, error) {
+		return &mockSession{runFunc: func(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+			return map[string]*Tensor{}, nil
+		}}, nil
+	}
+
+	pool, err := NewSessionPool("model.onnx", 0, SessionOptions{}, loader)
+	if err != nil {
+		t.Fatalf("NewSessionPool failed: %v", err)
+	}
+	defer pool.Close()
+
+	if pool.Size() != 1 {
+		t.Errorf("pool size = %d, want 1", pool.Size())
+	}
+}
+
+func TestSessionPoolLoaderError(t *testing.T) {
+	calls := 0
+	loader := func(modelPath string, opts SessionOptions) (Session, error) {
+		calls++
+		if calls > 1 {
+			return nil, fmt.Errorf("load failed")
+		}
+		return &mockSession{}, nil
+	}
+
+	if _, err := NewSessionPool("model.onnx", 2, SessionOptions{}, loader); err == nil {
+		t.Error("Expected error when a pool session fails to load")
+	}
+}
+
+func TestSessionPoolClose(t *testing.T) {
+	var sessions []*mockSession
+	loader := func(modelPath string, opts SessionOptions) (Session, error) {
+		s := &mockSession{}
+		sessions = append(sessions, s)
+		return s, nil
+	}
+
+	pool, err := NewSessionPool("model.onnx", 2, SessionOptions{}, loader)
+	if err != nil {
+		t.Fatalf("NewSessionPool failed: %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for i, s := range sessions {
+		if !s.closed {
+			t.Errorf("session %d not closed", i)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package onnx
+
+import "fmt"
+
+// Tensor is a dense tensor exchanged with an ONNX session.
+// Exactly one of FloatData or IntData is populated depending on the
+// element type the session expects (float32 for embeddings/logits,
+// int64 for token IDs and attention masks).
+type Tensor struct {
+	Shape     []int64
+	FloatData []float32
+	IntData   []int64
+}
+
+// NumElements returns the product of the tensor dimensions
+func (t *Tensor) NumElements() int64 {
+	n := int64(1)
+	for _, d := range t.Shape {
+		n *= d
+	}
+	return n
+}
+
+// NewFloatTensor creates a float32 tensor with the given shape
+func NewFloatTensor(shape []int64, data []float32) *Tensor {
+	return &Tensor{Shape: shape, FloatData: data}
+}
+
+// NewIntTensor creates an int64 tensor with the given shape
+func NewIntTensor(shape []int64, data []int64) *Tensor {
+	return &Tensor{Shape: shape, IntData: data}
+}
+
+// Session is a single loaded ONNX inference session.
+// Implementations wrap onnxruntime; tests use in-memory mocks.
+type Session interface {
+	// Run executes the session with named inputs and returns named outputs
+	Run(inputs map[string]*Tensor) (map[string]*Tensor, error)
+
+	// InputNames returns the model's input names in declaration order
+	InputNames() []string
+
+	// OutputNames returns the model's output names in declaration order
+	OutputNames() []string
+
+	// Provider reports the execution provider the session actually bound
+	// to (e.g. CoreMLExecutionProvider), which may differ from the one
+	// requested when onnxruntime falls back to CPU
+	Provider() string
+
+	// Close releases session resources
+	Close() error
+}
+
+// SessionLoader opens an ONNX model file and returns a ready session.
+// The production loader binds onnxruntime via cgo; tests inject mocks.
+type SessionLoader func(modelPath string, opts SessionOptions) (Session, error)
+
+// DefaultLoader is used when no loader is injected.
+// It fails until the onnxruntime bridge is linked in, mirroring the
+// placeholder MLX bridge in internal/mlx.
+func DefaultLoader(modelPath string, opts SessionOptions) (Session, error) {
+	return nil, fmt.Errorf("onnxruntime not linked: cannot load %s", modelPath)
+}
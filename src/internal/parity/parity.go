@@ -0,0 +1,152 @@
+// Package parity cross-checks the ONNX and MLX vision paths by running
+// the same preprocessed image through both and scoring how closely
+// their patch embeddings agree. A conversion or preprocessing bug
+// (wrong normalization, transposed weights, mismatched patch order)
+// shows up as low cosine similarity long before it degrades grounding
+// accuracy in end-to-end evals.
+package parity
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/agenthands/mlxvllm/internal/onnx"
+)
+
+// DefaultCosineThreshold is the per-patch similarity below which two
+// vision paths are considered divergent. Quantization and accumulation
+// order account for tiny differences; anything below this indicates a
+// real conversion bug.
+const DefaultCosineThreshold = 0.999
+
+// Encoder produces patch embeddings from preprocessed pixel values.
+// *onnx.Engine satisfies it directly; the MLX path is adapted through
+// EncoderFunc.
+type Encoder interface {
+	EncodeImage(pixelValues *onnx.Tensor) (*onnx.Tensor, error)
+}
+
+// EncoderFunc adapts a plain function to Encoder
+type EncoderFunc func(pixelValues *onnx.Tensor) (*onnx.Tensor, error)
+
+// EncodeImage implements Encoder
+func (f EncoderFunc) EncodeImage(pixelValues *onnx.Tensor) (*onnx.Tensor, error) {
+	return f(pixelValues)
+}
+
+// Report scores how closely two embedding tensors agree
+type Report struct {
+	// Patches and Dim describe the compared embedding shape
+	Patches int `json:"patches"`
+	Dim     int `json:"dim"`
+
+	// MeanCosine and MinCosine summarize per-patch cosine similarity;
+	// WorstPatch is the patch index MinCosine came from
+	MeanCosine float64 `json:"mean_cosine"`
+	MinCosine  float64 `json:"min_cosine"`
+	WorstPatch int     `json:"worst_patch"`
+
+	// MaxAbsDiff is the largest element-wise difference, which catches
+	// scale errors that cosine similarity is blind to
+	MaxAbsDiff float64 `json:"max_abs_diff"`
+}
+
+// Pass reports whether every patch met the similarity threshold
+// (non-positive thresholds use the default)
+func (r *Report) Pass(threshold float64) bool {
+	if threshold <= 0 {
+		threshold = DefaultCosineThreshold
+	}
+	return r.MinCosine >= threshold
+}
+
+// CompareVision runs the same pixel values through both encoders and
+// scores the agreement of their embeddings. The reference is typically
+// the ONNX session and the candidate the MLX path, but the comparison
+// is symmetric.
+func CompareVision(reference, candidate Encoder, pixelValues *onnx.Tensor) (*Report, error) {
+	ref, err := reference.EncodeImage(pixelValues)
+	if err != nil {
+		return nil, fmt.Errorf("reference encoder failed: %w", err)
+	}
+	cand, err := candidate.EncodeImage(pixelValues)
+	if err != nil {
+		return nil, fmt.Errorf("candidate encoder failed: %w", err)
+	}
+	return CompareEmbeddings(ref, cand)
+}
+
+// CompareEmbeddings scores two already-computed embedding tensors.
+// Shapes must match exactly; the last dimension is the embedding dim
+// and everything before it is flattened into patches.
+func CompareEmbeddings(ref, cand *onnx.Tensor) (*Report, error) {
+	if len(ref.Shape) == 0 {
+		return nil, fmt.Errorf("reference embedding has no shape")
+	}
+	if !shapesEqual(ref.Shape, cand.Shape) {
+		return nil, fmt.Errorf("embedding shape mismatch: %v vs %v", ref.Shape, cand.Shape)
+	}
+
+	dim := int(ref.Shape[len(ref.Shape)-1])
+	if dim == 0 {
+		return nil, fmt.Errorf("embedding dim is zero (shape %v)", ref.Shape)
+	}
+	total := int(ref.NumElements())
+	if len(ref.FloatData) < total || len(cand.FloatData) < total {
+		return nil, fmt.Errorf("embedding data shorter than shape %v", ref.Shape)
+	}
+	patches := total / dim
+
+	report := &Report{Patches: patches, Dim: dim, MinCosine: 1}
+	for p := 0; p < patches; p++ {
+		a := ref.FloatData[p*dim : (p+1)*dim]
+		b := cand.FloatData[p*dim : (p+1)*dim]
+
+		cos := cosine(a, b)
+		report.MeanCosine += cos
+		if cos < report.MinCosine {
+			report.MinCosine = cos
+			report.WorstPatch = p
+		}
+		for i := range a {
+			if diff := math.Abs(float64(a[i]) - float64(b[i])); diff > report.MaxAbsDiff {
+				report.MaxAbsDiff = diff
+			}
+		}
+	}
+	if patches > 0 {
+		report.MeanCosine /= float64(patches)
+	}
+	return report, nil
+}
+
+// cosine is the cosine similarity of two equal-length vectors. Two
+// zero vectors agree perfectly; a zero vector against a nonzero one
+// does not agree at all.
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 && normB == 0 {
+		return 1
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func shapesEqual(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
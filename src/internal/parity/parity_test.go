@@ -0,0 +1,112 @@
+package parity
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/onnx"
+)
+
+// embeds builds a [patches, dim] embedding tensor
+func embeds(patches, dim int, data []float32) *onnx.Tensor {
+	return onnx.NewFloatTensor([]int64{int64(patches), int64(dim)}, data)
+}
+
+func TestCompareIdenticalEmbeddings(t *testing.T) {
+	data := []float32{1, 2, 3, 4, 5, 6}
+	report, err := CompareEmbeddings(embeds(2, 3, data), embeds(2, 3, data))
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	if report.Patches != 2 || report.Dim != 3 {
+		t.Errorf("shape = %dx%d, want 2x3", report.Patches, report.Dim)
+	}
+	if report.MeanCosine < 0.9999 || report.MinCosine < 0.9999 {
+		t.Errorf("cosine = (mean %f, min %f), want 1", report.MeanCosine, report.MinCosine)
+	}
+	if report.MaxAbsDiff != 0 {
+		t.Errorf("MaxAbsDiff = %f, want 0", report.MaxAbsDiff)
+	}
+	if !report.Pass(0) {
+		t.Error("identical embeddings failed the default threshold")
+	}
+}
+
+func TestCompareScaledEmbeddingsCatchesScaleError(t *testing.T) {
+	ref := []float32{1, 2, 3}
+	cand := []float32{2, 4, 6}
+	report, err := CompareEmbeddings(embeds(1, 3, ref), embeds(1, 3, cand))
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	// Direction agrees perfectly, but MaxAbsDiff exposes the 2x scale
+	if report.MinCosine < 0.9999 {
+		t.Errorf("MinCosine = %f, want 1 for scaled copy", report.MinCosine)
+	}
+	if math.Abs(report.MaxAbsDiff-3) > 1e-9 {
+		t.Errorf("MaxAbsDiff = %f, want 3", report.MaxAbsDiff)
+	}
+}
+
+func TestComparePerturbedPatchFlagged(t *testing.T) {
+	ref := []float32{1, 0, 0, 1, 0, 1}
+	cand := []float32{1, 0, 0, 1, 1, 0} // third patch points elsewhere
+	report, err := CompareEmbeddings(embeds(3, 2, ref), embeds(3, 2, cand))
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	if report.Pass(DefaultCosineThreshold) {
+		t.Error("divergent patch passed the threshold")
+	}
+	if report.WorstPatch != 2 {
+		t.Errorf("WorstPatch = %d, want 2", report.WorstPatch)
+	}
+}
+
+func TestCompareShapeMismatch(t *testing.T) {
+	_, err := CompareEmbeddings(embeds(2, 3, make([]float32, 6)), embeds(3, 2, make([]float32, 6)))
+	if err == nil {
+		t.Fatal("expected error on shape mismatch")
+	}
+}
+
+func TestCompareZeroVectors(t *testing.T) {
+	report, err := CompareEmbeddings(embeds(1, 2, []float32{0, 0}), embeds(1, 2, []float32{0, 0}))
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	if report.MinCosine != 1 {
+		t.Errorf("MinCosine = %f for two zero vectors, want 1", report.MinCosine)
+	}
+
+	report, err = CompareEmbeddings(embeds(1, 2, []float32{0, 0}), embeds(1, 2, []float32{1, 0}))
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	if report.MinCosine != 0 {
+		t.Errorf("MinCosine = %f for zero vs nonzero, want 0", report.MinCosine)
+	}
+}
+
+func TestCompareVisionRunsBothEncoders(t *testing.T) {
+	pixels := onnx.NewFloatTensor([]int64{1, 4}, []float32{1, 2, 3, 4})
+	echo := EncoderFunc(func(pv *onnx.Tensor) (*onnx.Tensor, error) {
+		return embeds(1, 4, pv.FloatData), nil
+	})
+
+	report, err := CompareVision(echo, echo, pixels)
+	if err != nil {
+		t.Fatalf("CompareVision failed: %v", err)
+	}
+	if report.MinCosine < 0.9999 {
+		t.Errorf("MinCosine = %f, want 1", report.MinCosine)
+	}
+
+	fail := EncoderFunc(func(*onnx.Tensor) (*onnx.Tensor, error) {
+		return nil, errors.New("mlx bridge not linked")
+	})
+	if _, err := CompareVision(echo, fail, pixels); err == nil {
+		t.Error("expected candidate encoder error to propagate")
+	}
+}
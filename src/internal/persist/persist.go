@@ -0,0 +1,213 @@
+// Package persist keeps server state that should survive a restart —
+// sessions, batch job results, model download metadata, plus the API
+// keys and usage rollups metered by the usage package — in one
+// embedded database file. The file is bbolt rather than SQLite: it is
+// pure Go, already a dependency, and needs no CGO, while keeping the
+// single-file, versioned-migrations design.
+package persist
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket      = []byte("meta")
+	sessionsBucket  = []byte("sessions")
+	jobsBucket      = []byte("jobs")
+	downloadsBucket = []byte("downloads")
+
+	schemaKey = []byte("schema_version")
+)
+
+// migrations run in order inside one transaction each; the schema
+// version records how many have been applied, so opening an older
+// file applies only the missing ones
+var migrations = []func(tx *bolt.Tx) error{
+	// v1: the initial buckets
+	func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, jobsBucket, downloadsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// Store is the single state file; safe for concurrent use
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the state file at path and applies any
+// pending schema migrations
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// DB exposes the underlying database so other subsystems (the usage
+// store) can share the state file
+func (s *Store) DB() *bolt.DB {
+	return s.db
+}
+
+// SchemaVersion reports how many migrations have been applied
+func (s *Store) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	})
+	return version, err
+}
+
+// migrate applies pending migrations, bumping the version after each
+// so a failure partway resumes cleanly on the next open
+func (s *Store) migrate() error {
+	for {
+		done := true
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			version := schemaVersion(tx)
+			if version >= len(migrations) {
+				return nil
+			}
+			if err := migrations[version](tx); err != nil {
+				return fmt.Errorf("migration %d failed: %w", version+1, err)
+			}
+			done = version+1 >= len(migrations)
+			return setSchemaVersion(tx, version+1)
+		})
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+func schemaVersion(tx *bolt.Tx) int {
+	meta := tx.Bucket(metaBucket)
+	if meta == nil {
+		return 0
+	}
+	raw := meta.Get(schemaKey)
+	if len(raw) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(raw))
+}
+
+func setSchemaVersion(tx *bolt.Tx, version int) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	raw := make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, uint64(version))
+	return meta.Put(schemaKey, raw)
+}
+
+// PutSession stores a session snapshot (opaque to this package)
+func (s *Store) PutSession(id string, data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(id), data)
+	})
+}
+
+// DeleteSession removes a session snapshot; unknown ids are a no-op
+func (s *Store) DeleteSession(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(id))
+	})
+}
+
+// EachSession calls fn for every stored session snapshot
+func (s *Store) EachSession(fn func(id string, data []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// PutJob stores the result of a batch job (an eval or bench run) under
+// its id, replacing any earlier result
+func (s *Store) PutJob(id string, result []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(id), result)
+	})
+}
+
+// GetJob returns a stored job result, if one exists
+func (s *Store) GetJob(id string) ([]byte, bool, error) {
+	var result []byte
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(jobsBucket).Get([]byte(id)); v != nil {
+			result = append([]byte(nil), v...)
+			found = true
+		}
+		return nil
+	})
+	return result, found, err
+}
+
+// Download is the metadata recorded after a model pull completes
+type Download struct {
+	Repo      string `json:"repo"`
+	Path      string `json:"path"`
+	Completed int64  `json:"completed"` // unix seconds
+}
+
+// PutDownload records (or updates) a completed model download
+func (s *Store) PutDownload(d Download) error {
+	if d.Repo == "" {
+		return fmt.Errorf("empty repo")
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(downloadsBucket).Put([]byte(d.Repo), data)
+	})
+}
+
+// Downloads lists recorded model downloads in key order
+func (s *Store) Downloads() ([]Download, error) {
+	var downloads []Download
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(downloadsBucket).ForEach(func(k, v []byte) error {
+			var d Download
+			if err := json.Unmarshal(v, &d); err != nil {
+				return fmt.Errorf("corrupt download record for %s: %w", k, err)
+			}
+			downloads = append(downloads, d)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return downloads, nil
+}
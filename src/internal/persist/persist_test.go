@@ -0,0 +1,114 @@
+package persist
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestOpenAppliesMigrations(t *testing.T) {
+	s := openTestStore(t)
+	version, err := s.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version = %d, want %d", version, len(migrations))
+	}
+}
+
+func TestReopenIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.PutSession("sess-1", []byte("snapshot")); err != nil {
+		t.Fatalf("PutSession: %v", err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	found := false
+	err = s.EachSession(func(id string, data []byte) error {
+		if id == "sess-1" && string(data) == "snapshot" {
+			found = true
+		}
+		return nil
+	})
+	if err != nil || !found {
+		t.Errorf("session did not survive reopen (err=%v, found=%v)", err, found)
+	}
+}
+
+func TestSessionDelete(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.PutSession("sess-1", []byte("a")); err != nil {
+		t.Fatalf("PutSession: %v", err)
+	}
+	if err := s.DeleteSession("sess-1"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if err := s.DeleteSession("sess-missing"); err != nil {
+		t.Errorf("deleting a missing session errored: %v", err)
+	}
+
+	count := 0
+	s.EachSession(func(string, []byte) error { count++; return nil })
+	if count != 0 {
+		t.Errorf("%d sessions remain, want 0", count)
+	}
+}
+
+func TestJobRoundTrip(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.PutJob("eval-123", []byte(`{"accuracy":0.9}`)); err != nil {
+		t.Fatalf("PutJob: %v", err)
+	}
+
+	result, found, err := s.GetJob("eval-123")
+	if err != nil || !found {
+		t.Fatalf("GetJob: found=%v err=%v", found, err)
+	}
+	if string(result) != `{"accuracy":0.9}` {
+		t.Errorf("GetJob = %s", result)
+	}
+
+	if _, found, _ := s.GetJob("eval-missing"); found {
+		t.Error("GetJob found a job that was never stored")
+	}
+}
+
+func TestDownloadRoundTrip(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.PutDownload(Download{}); err == nil {
+		t.Error("PutDownload accepted an empty repo")
+	}
+
+	want := Download{Repo: "Qwen/Qwen2-VL-2B", Path: "/models/qwen2-vl-2b", Completed: 1700000000}
+	if err := s.PutDownload(want); err != nil {
+		t.Fatalf("PutDownload: %v", err)
+	}
+
+	downloads, err := s.Downloads()
+	if err != nil {
+		t.Fatalf("Downloads: %v", err)
+	}
+	if len(downloads) != 1 || downloads[0] != want {
+		t.Errorf("Downloads = %+v, want [%+v]", downloads, want)
+	}
+}
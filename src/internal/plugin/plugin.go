@@ -0,0 +1,139 @@
+// Package plugin exposes generation lifecycle hooks to external Go
+// packages. Deployments link custom telemetry, watermarking or
+// early-exit policies by importing their package for side effects and
+// registering hooks in init(), without forking the server. Unlike
+// guardrails, which inspect finished actions, plugin hooks see the
+// whole lifecycle of a request: admission, prefill, each decoded token
+// and completion.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStopGeneration is returned from OnToken to end generation early
+// without failing the request; the tokens decoded so far are kept.
+var ErrStopGeneration = errors.New("generation stopped by plugin")
+
+// Request describes the generation a hook is observing. Fields are
+// fixed at admission; hooks must not retain the pointer past OnComplete.
+type Request struct {
+	ID     string
+	Model  string
+	APIKey string
+	Prompt string
+	Stream bool
+}
+
+// Token is one decoded step
+type Token struct {
+	Index int
+	ID    int
+	Text  string
+}
+
+// Result summarizes a finished generation
+type Result struct {
+	Content      string
+	FinishReason string
+	Tokens       int
+	DurationMS   int64
+}
+
+// Hook observes the generation lifecycle. OnRequest may return an error
+// to reject the request before any work is done; OnToken may return
+// ErrStopGeneration to end decoding early, or any other error to abort.
+// Embed Base to implement only the events of interest.
+type Hook interface {
+	Name() string
+	OnRequest(req *Request) error
+	OnPrefillDone(req *Request)
+	OnToken(req *Request, tok Token) error
+	OnComplete(req *Request, res Result)
+}
+
+// Base is a no-op Hook implementation (minus Name) for embedding
+type Base struct{}
+
+func (Base) OnRequest(*Request) error      { return nil }
+func (Base) OnPrefillDone(*Request)        {}
+func (Base) OnToken(*Request, Token) error { return nil }
+func (Base) OnComplete(*Request, Result)   {}
+
+// Registry holds the registered hooks and fans lifecycle events out to
+// them in registration order.
+type Registry struct {
+	mu    sync.RWMutex
+	hooks map[string]Hook
+	order []string
+}
+
+// Default is the registry external packages register into from init()
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{hooks: make(map[string]Hook)}
+}
+
+// Register adds a hook. Hooks run in registration order; registering a
+// name twice replaces the earlier hook in place.
+func (r *Registry) Register(h Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.hooks[h.Name()]; !exists {
+		r.order = append(r.order, h.Name())
+	}
+	r.hooks[h.Name()] = h
+}
+
+// snapshot copies the hook list so events run without holding the lock
+func (r *Registry) snapshot() []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hooks := make([]Hook, 0, len(r.order))
+	for _, name := range r.order {
+		hooks = append(hooks, r.hooks[name])
+	}
+	return hooks
+}
+
+// FireRequest runs OnRequest on every hook; the first rejection wins
+// and its hook name is included in the returned error.
+func (r *Registry) FireRequest(req *Request) error {
+	for _, h := range r.snapshot() {
+		if err := h.OnRequest(req); err != nil {
+			return fmt.Errorf("rejected by plugin %q: %w", h.Name(), err)
+		}
+	}
+	return nil
+}
+
+// FirePrefillDone runs OnPrefillDone on every hook
+func (r *Registry) FirePrefillDone(req *Request) {
+	for _, h := range r.snapshot() {
+		h.OnPrefillDone(req)
+	}
+}
+
+// FireToken runs OnToken on every hook. ErrStopGeneration propagates
+// unwrapped so callers can distinguish early exit from failure.
+func (r *Registry) FireToken(req *Request, tok Token) error {
+	for _, h := range r.snapshot() {
+		if err := h.OnToken(req, tok); err != nil {
+			if errors.Is(err, ErrStopGeneration) {
+				return ErrStopGeneration
+			}
+			return fmt.Errorf("aborted by plugin %q: %w", h.Name(), err)
+		}
+	}
+	return nil
+}
+
+// FireComplete runs OnComplete on every hook
+func (r *Registry) FireComplete(req *Request, res Result) {
+	for _, h := range r.snapshot() {
+		h.OnComplete(req, res)
+	}
+}
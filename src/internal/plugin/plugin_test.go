@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingHook captures every event it sees
+type recordingHook struct {
+	Base
+	name       string
+	events     []string
+	requestErr error
+	tokenErr   error
+}
+
+func (h *recordingHook) Name() string { return h.name }
+
+func (h *recordingHook) OnRequest(req *Request) error {
+	h.events = append(h.events, "request:"+req.Model)
+	return h.requestErr
+}
+
+func (h *recordingHook) OnPrefillDone(req *Request) {
+	h.events = append(h.events, "prefill")
+}
+
+func (h *recordingHook) OnToken(req *Request, tok Token) error {
+	h.events = append(h.events, "token:"+tok.Text)
+	return h.tokenErr
+}
+
+func (h *recordingHook) OnComplete(req *Request, res Result) {
+	h.events = append(h.events, "complete:"+res.FinishReason)
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	r := NewRegistry()
+	hook := &recordingHook{name: "recorder"}
+	r.Register(hook)
+
+	req := &Request{Model: "gui-actor-2b"}
+	if err := r.FireRequest(req); err != nil {
+		t.Fatalf("FireRequest failed: %v", err)
+	}
+	r.FirePrefillDone(req)
+	if err := r.FireToken(req, Token{Text: "click"}); err != nil {
+		t.Fatalf("FireToken failed: %v", err)
+	}
+	r.FireComplete(req, Result{FinishReason: "stop"})
+
+	want := []string{"request:gui-actor-2b", "prefill", "token:click", "complete:stop"}
+	if len(hook.events) != len(want) {
+		t.Fatalf("events = %v, want %v", hook.events, want)
+	}
+	for i := range want {
+		if hook.events[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, hook.events[i], want[i])
+		}
+	}
+}
+
+func TestRequestRejectionNamesHook(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&recordingHook{name: "gatekeeper", requestErr: errors.New("key not allowed")})
+
+	err := r.FireRequest(&Request{})
+	if err == nil {
+		t.Fatal("expected rejection")
+	}
+	if !strings.Contains(err.Error(), "gatekeeper") {
+		t.Errorf("error = %v, want hook name included", err)
+	}
+}
+
+func TestFirstRejectionWins(t *testing.T) {
+	r := NewRegistry()
+	first := &recordingHook{name: "first", requestErr: errors.New("no")}
+	second := &recordingHook{name: "second"}
+	r.Register(first)
+	r.Register(second)
+
+	if err := r.FireRequest(&Request{}); err == nil {
+		t.Fatal("expected rejection")
+	}
+	if len(second.events) != 0 {
+		t.Error("later hook ran after an earlier rejection")
+	}
+}
+
+func TestTokenEarlyExit(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&recordingHook{name: "limiter", tokenErr: ErrStopGeneration})
+
+	err := r.FireToken(&Request{}, Token{})
+	if err != ErrStopGeneration {
+		t.Errorf("FireToken = %v, want ErrStopGeneration unwrapped", err)
+	}
+}
+
+func TestTokenAbortNamesHook(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&recordingHook{name: "auditor", tokenErr: errors.New("bad token")})
+
+	err := r.FireToken(&Request{}, Token{})
+	if err == nil || !strings.Contains(err.Error(), "auditor") {
+		t.Errorf("error = %v, want hook name included", err)
+	}
+}
+
+func TestRegisterReplacesInPlace(t *testing.T) {
+	r := NewRegistry()
+	old := &recordingHook{name: "dup"}
+	replacement := &recordingHook{name: "dup"}
+	r.Register(old)
+	r.Register(replacement)
+
+	r.FirePrefillDone(&Request{})
+	if len(old.events) != 0 {
+		t.Error("replaced hook still receives events")
+	}
+	if len(replacement.events) != 1 {
+		t.Error("replacement hook did not receive the event")
+	}
+}
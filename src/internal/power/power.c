@@ -0,0 +1,21 @@
+#include "power.h"
+
+// Placeholder implementation - the darwin build registers with IOKit
+// (IORegisterForSystemPower on a dedicated run loop) and invokes the
+// Go callbacks on power transitions. Other platforms register
+// successfully but never deliver events.
+
+static int registered = 0;
+
+int power_register_notifications(void) {
+    if (registered) return 1;
+    // TODO: IORegisterForSystemPower + CFRunLoop on darwin
+    registered = 1;
+    return 1;
+}
+
+void power_unregister_notifications(void) {
+    if (!registered) return;
+    // TODO: IODeregisterForSystemPower on darwin
+    registered = 0;
+}
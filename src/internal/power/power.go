@@ -0,0 +1,98 @@
+// Package power delivers macOS sleep/wake notifications so the server
+// can quiesce scheduling before the machine suspends and revalidate
+// GPU state on wake, letting overnight agent sessions survive lid
+// closes.
+package power
+
+/*
+#cgo CFLAGS: -I${SRCDIR}
+#include "power.h"
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+)
+
+// Hooks are invoked on power transitions, from the notification
+// goroutine
+type Hooks struct {
+	OnSleep func()
+	OnWake  func()
+}
+
+// Monitor owns the registration with the OS notification source
+type Monitor struct {
+	hooks Hooks
+}
+
+// The C layer delivers events process-wide, so at most one monitor may
+// be registered at a time
+var (
+	activeMu sync.Mutex
+	active   *Monitor
+)
+
+func NewMonitor(hooks Hooks) *Monitor {
+	return &Monitor{hooks: hooks}
+}
+
+// Start registers for sleep/wake notifications
+func (m *Monitor) Start() error {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if active != nil {
+		return fmt.Errorf("power monitor already active")
+	}
+	if C.power_register_notifications() == 0 {
+		return fmt.Errorf("failed to register for power notifications")
+	}
+	active = m
+	return nil
+}
+
+// Stop deregisters the monitor; a no-op unless it is the active one
+func (m *Monitor) Stop() {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if active != m {
+		return
+	}
+	C.power_unregister_notifications()
+	active = nil
+}
+
+func (m *Monitor) handleSleep() {
+	if m.hooks.OnSleep != nil {
+		m.hooks.OnSleep()
+	}
+}
+
+func (m *Monitor) handleWake() {
+	if m.hooks.OnWake != nil {
+		m.hooks.OnWake()
+	}
+}
+
+// activeMonitor snapshots the registered monitor for the C callbacks
+func activeMonitor() *Monitor {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	return active
+}
+
+//export goPowerSleep
+func goPowerSleep() {
+	if m := activeMonitor(); m != nil {
+		m.handleSleep()
+	}
+}
+
+//export goPowerWake
+func goPowerWake() {
+	if m := activeMonitor(); m != nil {
+		m.handleWake()
+	}
+}
@@ -0,0 +1,11 @@
+#ifndef POWER_H
+#define POWER_H
+
+// Registers for system sleep/wake notifications. Returns 1 on success.
+// The darwin implementation uses IOKit's IORegisterForSystemPower and
+// forwards kIOMessageSystemWillSleep / kIOMessageSystemHasPoweredOn to
+// the goPowerSleep / goPowerWake callbacks exported from Go.
+int power_register_notifications(void);
+void power_unregister_notifications(void);
+
+#endif // POWER_H
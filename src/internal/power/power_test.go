@@ -0,0 +1,76 @@
+package power
+
+import "testing"
+
+func TestMonitorDispatchesEvents(t *testing.T) {
+	var sleeps, wakes int
+	m := NewMonitor(Hooks{
+		OnSleep: func() { sleeps++ },
+		OnWake:  func() { wakes++ },
+	})
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer m.Stop()
+
+	goPowerSleep()
+	goPowerWake()
+	goPowerWake()
+
+	if sleeps != 1 {
+		t.Errorf("sleeps = %d, want 1", sleeps)
+	}
+	if wakes != 2 {
+		t.Errorf("wakes = %d, want 2", wakes)
+	}
+}
+
+func TestMonitorSingleActive(t *testing.T) {
+	first := NewMonitor(Hooks{})
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer first.Stop()
+
+	second := NewMonitor(Hooks{})
+	if err := second.Start(); err == nil {
+		second.Stop()
+		t.Fatal("second Start succeeded while a monitor was active")
+	}
+
+	// Stopping the inactive monitor must not deregister the active one
+	second.Stop()
+	if activeMonitor() != first {
+		t.Error("Stop on an inactive monitor cleared the registration")
+	}
+}
+
+func TestMonitorStopAllowsRestart(t *testing.T) {
+	m := NewMonitor(Hooks{})
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	m.Stop()
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("restart failed: %v", err)
+	}
+	m.Stop()
+}
+
+func TestCallbacksWithoutMonitor(t *testing.T) {
+	// Events arriving before registration (or after Stop) are dropped
+	goPowerSleep()
+	goPowerWake()
+}
+
+func TestNilHooks(t *testing.T) {
+	m := NewMonitor(Hooks{})
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer m.Stop()
+
+	goPowerSleep()
+	goPowerWake()
+}
@@ -0,0 +1,117 @@
+// Package prefixcache tracks prompt prefixes that requests asked to
+// keep cached beyond the normal LRU lifetime. Clients mark messages
+// with cache_control; the tracker enforces the server-wide budget on
+// persistently pinned tokens so a few tenants cannot pin the whole KV
+// cache. The engine consults the tracker when deciding what to evict.
+package prefixcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Kind distinguishes the two cache_control lifetimes
+type Kind int
+
+const (
+	// Ephemeral pins survive the current burst of requests but remain
+	// subject to eviction under memory pressure
+	Ephemeral Kind = iota
+	// Persistent pins are exempt from LRU eviction until released and
+	// count against the server budget
+	Persistent
+)
+
+// ErrBudgetExhausted rejects persistent pins past the admin limit
+var ErrBudgetExhausted = errors.New("persistent pinned token budget exhausted")
+
+// Pin is one tracked prefix
+type Pin struct {
+	Hash    string
+	Tokens  int
+	Kind    Kind
+	LastUse time.Time
+}
+
+// Tracker holds the pinned prefixes and the persistent-token budget.
+// Safe for concurrent use.
+type Tracker struct {
+	mu             sync.Mutex
+	pins           map[string]*Pin
+	budget         int
+	persistentUsed int
+}
+
+// NewTracker bounds persistent pins to budget tokens; zero disables
+// persistent pinning entirely
+func NewTracker(budget int) *Tracker {
+	return &Tracker{pins: make(map[string]*Pin), budget: budget}
+}
+
+// HashPrefix derives the pin key from the prefix content
+func HashPrefix(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Pin registers a prefix. Re-pinning the same hash refreshes its last
+// use; upgrading an ephemeral pin to persistent re-checks the budget.
+func (t *Tracker) Pin(hash string, tokens int, kind Kind) error {
+	if tokens <= 0 {
+		return fmt.Errorf("pin needs a positive token count, got %d", tokens)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing := t.pins[hash]
+	if existing != nil && existing.Kind == kind {
+		existing.LastUse = time.Now()
+		return nil
+	}
+
+	if kind == Persistent {
+		if t.persistentUsed+tokens > t.budget {
+			return ErrBudgetExhausted
+		}
+		t.persistentUsed += tokens
+	}
+	if existing != nil && existing.Kind == Persistent {
+		t.persistentUsed -= existing.Tokens
+	}
+
+	t.pins[hash] = &Pin{Hash: hash, Tokens: tokens, Kind: kind, LastUse: time.Now()}
+	return nil
+}
+
+// Release drops a pin, returning its tokens to the budget when it was
+// persistent
+func (t *Tracker) Release(hash string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pin := t.pins[hash]
+	if pin == nil {
+		return
+	}
+	if pin.Kind == Persistent {
+		t.persistentUsed -= pin.Tokens
+	}
+	delete(t.pins, hash)
+}
+
+// Pinned reports whether the prefix is tracked
+func (t *Tracker) Pinned(hash string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pins[hash] != nil
+}
+
+// PersistentTokens reports the budget currently consumed
+func (t *Tracker) PersistentTokens() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.persistentUsed
+}
@@ -0,0 +1,76 @@
+package prefixcache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPersistentBudgetEnforced(t *testing.T) {
+	tr := NewTracker(100)
+
+	if err := tr.Pin("a", 60, Persistent); err != nil {
+		t.Fatalf("Pin within budget failed: %v", err)
+	}
+	if err := tr.Pin("b", 60, Persistent); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("Pin over budget = %v, want ErrBudgetExhausted", err)
+	}
+	if got := tr.PersistentTokens(); got != 60 {
+		t.Errorf("PersistentTokens = %d, want 60", got)
+	}
+}
+
+func TestEphemeralUnbudgeted(t *testing.T) {
+	tr := NewTracker(0)
+
+	if err := tr.Pin("a", 10000, Ephemeral); err != nil {
+		t.Fatalf("ephemeral pin failed: %v", err)
+	}
+	if got := tr.PersistentTokens(); got != 0 {
+		t.Errorf("PersistentTokens = %d, want 0", got)
+	}
+	if !tr.Pinned("a") {
+		t.Error("ephemeral pin not tracked")
+	}
+}
+
+func TestZeroBudgetDisablesPersistent(t *testing.T) {
+	tr := NewTracker(0)
+	if err := tr.Pin("a", 1, Persistent); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("Pin with zero budget = %v, want ErrBudgetExhausted", err)
+	}
+}
+
+func TestRepinRefreshes(t *testing.T) {
+	tr := NewTracker(100)
+	if err := tr.Pin("a", 80, Persistent); err != nil {
+		t.Fatal(err)
+	}
+	// Same hash and kind must not double-count against the budget
+	if err := tr.Pin("a", 80, Persistent); err != nil {
+		t.Errorf("re-pin failed: %v", err)
+	}
+	if got := tr.PersistentTokens(); got != 80 {
+		t.Errorf("PersistentTokens = %d, want 80", got)
+	}
+}
+
+func TestReleaseReturnsBudget(t *testing.T) {
+	tr := NewTracker(100)
+	if err := tr.Pin("a", 100, Persistent); err != nil {
+		t.Fatal(err)
+	}
+	tr.Release("a")
+	if tr.Pinned("a") {
+		t.Error("released pin still tracked")
+	}
+	if err := tr.Pin("b", 100, Persistent); err != nil {
+		t.Errorf("Pin after release failed: %v", err)
+	}
+}
+
+func TestInvalidTokenCount(t *testing.T) {
+	tr := NewTracker(100)
+	if err := tr.Pin("a", 0, Ephemeral); err == nil {
+		t.Error("accepted a zero-token pin")
+	}
+}
@@ -0,0 +1,123 @@
+// Package recording persists sanitized request/response pairs from
+// live traffic as JSONL, so agent sessions can be turned into
+// fine-tuning datasets. Screenshots are never stored raw: only their
+// SHA-256 hash is written, which is enough to join against an external
+// screenshot store.
+package recording
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFileBytes rotates record files at 64 MiB
+const DefaultMaxFileBytes = 64 << 20
+
+// Point mirrors the API coordinate shape
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Record is one sanitized request/response pair
+type Record struct {
+	Timestamp      int64  `json:"timestamp"`
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	ScreenshotHash string `json:"screenshot_hash,omitempty"`
+	Response       string `json:"response"`
+	Coordinates    *Point `json:"coordinates,omitempty"`
+	LatencyMS      int64  `json:"latency_ms"`
+}
+
+// Recorder appends records to rotating JSONL files in a directory
+type Recorder struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	file     *os.File
+	written  int64
+}
+
+// NewRecorder creates the directory if needed and opens the first file.
+// maxBytes <= 0 uses DefaultMaxFileBytes.
+func NewRecorder(dir string, maxBytes int64) (*Recorder, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxFileBytes
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording dir: %w", err)
+	}
+
+	r := &Recorder{dir: dir, maxBytes: maxBytes}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record appends one record, rotating the file when it exceeds the limit
+func (r *Recorder) Record(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+	data = append(data, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.written+int64(len(data)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.file.Write(data)
+	r.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the current file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// rotate closes the current file and opens a fresh timestamped one.
+// Caller must hold the lock (or be the constructor).
+func (r *Recorder) rotate() error {
+	if r.file != nil {
+		r.file.Close()
+	}
+
+	name := fmt.Sprintf("records-%d.jsonl", time.Now().UnixNano())
+	f, err := os.OpenFile(filepath.Join(r.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open record file: %w", err)
+	}
+	r.file = f
+	r.written = 0
+	return nil
+}
+
+// HashScreenshot returns the hex SHA-256 of raw screenshot bytes
+func HashScreenshot(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
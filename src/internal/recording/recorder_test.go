@@ -0,0 +1,117 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordWritesJSONL(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	defer r.Close()
+
+	recs := []Record{
+		{Timestamp: 1, Model: "gui-actor-2b", Prompt: "click start", Response: "pyautogui.click(0.5, 0.5)", Coordinates: &Point{X: 0.5, Y: 0.5}, LatencyMS: 120},
+		{Timestamp: 2, Model: "gui-actor-2b", Prompt: "close window", Response: "pyautogui.click(0.9, 0.1)", LatencyMS: 95},
+	}
+	for _, rec := range recs {
+		if err := r.Record(rec); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "records-*.jsonl"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 record file, got %d", len(files))
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("Failed to open record file: %v", err)
+	}
+	defer f.Close()
+
+	var got []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid JSONL line: %v", err)
+		}
+		got = append(got, rec)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Got %d records, want 2", len(got))
+	}
+	if got[0].Prompt != "click start" || got[0].Coordinates == nil || got[0].Coordinates.X != 0.5 {
+		t.Errorf("First record mismatch: %+v", got[0])
+	}
+}
+
+func TestRecordRotation(t *testing.T) {
+	dir := t.TempDir()
+	// Tiny limit forces a rotation on the second record
+	r, err := NewRecorder(dir, 100)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := r.Record(Record{Timestamp: int64(i), Model: "m", Prompt: "some prompt text", Response: "some response text"}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "records-*.jsonl"))
+	if len(files) < 2 {
+		t.Errorf("Expected rotation to create multiple files, got %d", len(files))
+	}
+}
+
+func TestNewRecorderCreatesDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "recordings")
+	r, err := NewRecorder(dir, 0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Recording dir not created: %v", err)
+	}
+}
+
+func TestCloseIdempotent(t *testing.T) {
+	r, err := NewRecorder(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
+
+func TestHashScreenshot(t *testing.T) {
+	h1 := HashScreenshot([]byte("screenshot-a"))
+	h2 := HashScreenshot([]byte("screenshot-b"))
+	if len(h1) != 64 {
+		t.Errorf("Hash length = %d, want 64", len(h1))
+	}
+	if h1 == h2 {
+		t.Error("Different inputs produced the same hash")
+	}
+	if h1 != HashScreenshot([]byte("screenshot-a")) {
+		t.Error("Hash is not deterministic")
+	}
+}
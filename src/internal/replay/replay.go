@@ -0,0 +1,176 @@
+// Package replay re-sends a recorded JSONL trace against a running
+// server and compares the answers with the recording, turning captured
+// agent traffic into a regression test for model or cache changes.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+	"github.com/agenthands/mlxvllm/internal/recording"
+)
+
+// Options configures a replay run
+type Options struct {
+	// ServerURL is the base URL, e.g. http://localhost:8080
+	ServerURL string
+	// Concurrency is the number of parallel workers (default 1)
+	Concurrency int
+	// Client overrides the HTTP client (tests)
+	Client *http.Client
+}
+
+// Diff is one response that changed versus the recording
+type Diff struct {
+	Index    int
+	Prompt   string
+	Recorded string
+	Replayed string
+}
+
+// Result aggregates a replay run
+type Result struct {
+	Total     int
+	Errors    int
+	Diffs     []Diff
+	Latencies []time.Duration
+}
+
+// Percentile returns the p-th latency percentile (p in [0,100])
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(r.Latencies))
+	copy(sorted, r.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p / 100 * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// LoadTrace reads a recording JSONL file
+func LoadTrace(path string) ([]recording.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+
+	var records []recording.Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec recording.Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("invalid trace line %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return records, nil
+}
+
+// Run replays the records at the configured concurrency
+func Run(records []recording.Record, opts Options) (*Result, error) {
+	if opts.ServerURL == "" {
+		return nil, fmt.Errorf("server URL required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 120 * time.Second}
+	}
+
+	result := &Result{Total: len(records)}
+	var mu sync.Mutex
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				replayed, latency, err := replayOne(client, opts.ServerURL, records[i])
+
+				mu.Lock()
+				if err != nil {
+					result.Errors++
+				} else {
+					result.Latencies = append(result.Latencies, latency)
+					if replayed != records[i].Response {
+						result.Diffs = append(result.Diffs, Diff{
+							Index:    i,
+							Prompt:   records[i].Prompt,
+							Recorded: records[i].Response,
+							Replayed: replayed,
+						})
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range records {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(result.Diffs, func(i, j int) bool { return result.Diffs[i].Index < result.Diffs[j].Index })
+	return result, nil
+}
+
+// replayOne sends a single recorded prompt and returns the response text
+func replayOne(client *http.Client, serverURL string, rec recording.Record) (string, time.Duration, error) {
+	reqBody, err := json.Marshal(api.ChatCompletionRequest{
+		Model: rec.Model,
+		Messages: []api.Message{
+			{Role: "user", Content: rec.Prompt},
+		},
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	start := time.Now()
+	resp, err := client.Post(serverURL+"/v1/chat/completions", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	latency := time.Since(start)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", latency, fmt.Errorf("server returned %d", resp.StatusCode)
+	}
+
+	var completion api.ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", latency, err
+	}
+	if len(completion.Choices) == 0 {
+		return "", latency, fmt.Errorf("no choices in response")
+	}
+
+	text, _ := completion.Choices[0].Message.Content.(string)
+	return text, latency, nil
+}
@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+	"github.com/agenthands/mlxvllm/internal/recording"
+)
+
+// fakeServer answers every chat completion with the given content
+func fakeServer(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		resp := api.NewChatCompletionResponse("test", []api.Choice{
+			{Index: 0, Message: api.Message{Role: "assistant", Content: content}, FinishReason: "stop"},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func sampleRecords(response string, n int) []recording.Record {
+	records := make([]recording.Record, n)
+	for i := range records {
+		records[i] = recording.Record{
+			Model:    "gui-actor-2b",
+			Prompt:   "click the button",
+			Response: response,
+		}
+	}
+	return records
+}
+
+func TestRunMatchingResponses(t *testing.T) {
+	srv := fakeServer(t, "pyautogui.click(0.5, 0.5)")
+	defer srv.Close()
+
+	result, err := Run(sampleRecords("pyautogui.click(0.5, 0.5)", 4), Options{
+		ServerURL:   srv.URL,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Total != 4 || result.Errors != 0 {
+		t.Errorf("Total=%d Errors=%d, want 4/0", result.Total, result.Errors)
+	}
+	if len(result.Diffs) != 0 {
+		t.Errorf("Got %d diffs, want 0", len(result.Diffs))
+	}
+	if len(result.Latencies) != 4 {
+		t.Errorf("Got %d latencies, want 4", len(result.Latencies))
+	}
+}
+
+func TestRunDetectsDiffs(t *testing.T) {
+	srv := fakeServer(t, "pyautogui.click(0.9, 0.9)")
+	defer srv.Close()
+
+	result, err := Run(sampleRecords("pyautogui.click(0.5, 0.5)", 3), Options{ServerURL: srv.URL})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(result.Diffs) != 3 {
+		t.Fatalf("Got %d diffs, want 3", len(result.Diffs))
+	}
+	d := result.Diffs[0]
+	if d.Recorded != "pyautogui.click(0.5, 0.5)" || d.Replayed != "pyautogui.click(0.9, 0.9)" {
+		t.Errorf("Diff content wrong: %+v", d)
+	}
+}
+
+func TestRunCountsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	result, err := Run(sampleRecords("x", 2), Options{ServerURL: srv.URL})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Errors != 2 {
+		t.Errorf("Errors = %d, want 2", result.Errors)
+	}
+}
+
+func TestRunRequiresServerURL(t *testing.T) {
+	if _, err := Run(nil, Options{}); err == nil {
+		t.Error("Expected error without server URL")
+	}
+}
+
+func TestLoadTrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	content := `{"timestamp":1,"model":"m","prompt":"a","response":"ra","latency_ms":10}
+{"timestamp":2,"model":"m","prompt":"b","response":"rb","latency_ms":20}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write trace: %v", err)
+	}
+
+	records, err := LoadTrace(path)
+	if err != nil {
+		t.Fatalf("LoadTrace failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Got %d records, want 2", len(records))
+	}
+	if records[1].Prompt != "b" {
+		t.Errorf("Record order wrong: %+v", records[1])
+	}
+}
+
+func TestLoadTraceErrors(t *testing.T) {
+	if _, err := LoadTrace("/nonexistent/trace.jsonl"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.jsonl")
+	os.WriteFile(path, []byte("{not json\n"), 0644)
+	if _, err := LoadTrace(path); err == nil {
+		t.Error("Expected error for malformed line")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	r := &Result{Latencies: []time.Duration{
+		40 * time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond,
+	}}
+
+	if got := r.Percentile(0); got != 10*time.Millisecond {
+		t.Errorf("p0 = %v, want 10ms", got)
+	}
+	if got := r.Percentile(100); got != 40*time.Millisecond {
+		t.Errorf("p100 = %v, want 40ms", got)
+	}
+	if got := r.Percentile(50); got != 20*time.Millisecond {
+		t.Errorf("p50 = %v, want 20ms", got)
+	}
+
+	empty := &Result{}
+	if got := empty.Percentile(50); got != 0 {
+		t.Errorf("Empty percentile = %v, want 0", got)
+	}
+}
@@ -0,0 +1,301 @@
+// Package router load-balances OpenAI-compatible requests across
+// multiple mlxvllm backends, so several Apple Silicon boxes appear as
+// one endpoint. Routing is model-aware (only backends serving the
+// requested model are considered), health-checked, and sticky by
+// session or prompt prefix to maximize each backend's cache hit rate.
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/trace"
+)
+
+// DefaultHealthInterval is how often backends are probed
+const DefaultHealthInterval = 5 * time.Second
+
+// Backend is one mlxvllm server behind the router
+type Backend struct {
+	URL   *url.URL
+	proxy *httputil.ReverseProxy
+
+	mu      sync.RWMutex
+	healthy bool
+	models  map[string]struct{}
+}
+
+// Healthy reports the result of the last health probe
+func (b *Backend) Healthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+// serves reports whether the backend advertises the model; an empty
+// model matches any backend
+func (b *Backend) serves(model string) bool {
+	if model == "" {
+		return true
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.models[model]
+	return ok
+}
+
+// Router fans requests out to backends
+type Router struct {
+	backends []*Backend
+	client   *http.Client
+
+	mu       sync.Mutex
+	affinity map[string]*Backend
+	next     int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a router over the backend base URLs
+func New(backendURLs []string) (*Router, error) {
+	if len(backendURLs) == 0 {
+		return nil, fmt.Errorf("at least one backend is required")
+	}
+
+	r := &Router{
+		client:   &http.Client{Timeout: 5 * time.Second},
+		affinity: make(map[string]*Backend),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, raw := range backendURLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backend URL %q: %w", raw, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid backend URL %q: missing scheme or host", raw)
+		}
+		r.backends = append(r.backends, &Backend{
+			URL:   u,
+			proxy: httputil.NewSingleHostReverseProxy(u),
+		})
+	}
+	return r, nil
+}
+
+// Backends returns the configured backends
+func (r *Router) Backends() []*Backend {
+	return r.backends
+}
+
+// Start probes all backends once, then keeps probing in the
+// background until Stop is called
+func (r *Router) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+	r.CheckHealth(context.Background())
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.CheckHealth(context.Background())
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends background health checking
+func (r *Router) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// CheckHealth probes every backend's health and model list
+func (r *Router) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, b := range r.backends {
+		wg.Add(1)
+		go func(b *Backend) {
+			defer wg.Done()
+			r.probe(ctx, b)
+		}(b)
+	}
+	wg.Wait()
+}
+
+// probe marks the backend healthy and refreshes its model list
+func (r *Router) probe(ctx context.Context, b *Backend) {
+	healthy := false
+	models := make(map[string]struct{})
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.URL.String()+"/v1/health", nil)
+	if err == nil {
+		if resp, err := r.client.Do(req); err == nil {
+			resp.Body.Close()
+			healthy = resp.StatusCode == http.StatusOK
+		}
+	}
+
+	if healthy {
+		req, err := http.NewRequestWithContext(ctx, "GET", b.URL.String()+"/v1/models", nil)
+		if err == nil {
+			if resp, err := r.client.Do(req); err == nil {
+				var list struct {
+					Data []struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				}
+				if json.NewDecoder(resp.Body).Decode(&list) == nil {
+					for _, m := range list.Data {
+						models[m.ID] = struct{}{}
+					}
+				}
+				resp.Body.Close()
+			}
+		}
+	}
+
+	b.mu.Lock()
+	b.healthy = healthy
+	b.models = models
+	b.mu.Unlock()
+}
+
+// routedRequest is the subset of the completion body the router needs
+type routedRequest struct {
+	Model     string `json:"model"`
+	SessionID string `json:"session_id"`
+	Messages  []struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+}
+
+// affinityKey derives the sticky-routing key: the session when one is
+// set, otherwise a digest of the first message (typically the long
+// system prompt shared across an agent's requests)
+func (req *routedRequest) affinityKey() string {
+	if req.SessionID != "" {
+		return "session:" + req.SessionID
+	}
+	if len(req.Messages) > 0 {
+		h := fnv.New64a()
+		h.Write(req.Messages[0].Content)
+		return fmt.Sprintf("prefix:%x", h.Sum64())
+	}
+	return ""
+}
+
+// pickBackend chooses a healthy backend for the model, honoring
+// affinity when the pinned backend is still eligible
+func (r *Router) pickBackend(model, key string) *Backend {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key != "" {
+		if b, ok := r.affinity[key]; ok && b.Healthy() && b.serves(model) {
+			return b
+		}
+	}
+
+	// Round-robin over eligible backends
+	for i := 0; i < len(r.backends); i++ {
+		b := r.backends[r.next%len(r.backends)]
+		r.next++
+		if b.Healthy() && b.serves(model) {
+			if key != "" {
+				r.affinity[key] = b
+			}
+			return b
+		}
+	}
+	return nil
+}
+
+// ServeHTTP routes OpenAI requests to backends
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// Attach a correlation ID before proxying so backend logs line up
+	// with the controller's trace
+	req = trace.Ensure(req)
+	w.Header().Set(trace.RequestIDHeader, trace.RequestID(req.Context()))
+
+	if req.URL.Path == "/v1/models" && req.Method == http.MethodGet {
+		r.aggregateModels(w, req)
+		return
+	}
+
+	var routed routedRequest
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		req.Body.Close()
+		// Best effort: non-JSON bodies just get no affinity
+		_ = json.Unmarshal(body, &routed)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	backend := r.pickBackend(routed.Model, routed.affinityKey())
+	if backend == nil {
+		http.Error(w, "no healthy backend for model "+routed.Model, http.StatusBadGateway)
+		return
+	}
+	backend.proxy.ServeHTTP(w, req)
+}
+
+// aggregateModels merges /v1/models from every healthy backend
+func (r *Router) aggregateModels(w http.ResponseWriter, req *http.Request) {
+	ids := make(map[string]struct{})
+	for _, b := range r.backends {
+		if !b.Healthy() {
+			continue
+		}
+		b.mu.RLock()
+		for id := range b.models {
+			ids[id] = struct{}{}
+		}
+		b.mu.RUnlock()
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	type modelInfo struct {
+		ID     string `json:"id"`
+		Object string `json:"object"`
+	}
+	resp := struct {
+		Object string      `json:"object"`
+		Data   []modelInfo `json:"data"`
+	}{Object: "list", Data: make([]modelInfo, 0, len(sorted))}
+	for _, id := range sorted {
+		resp.Data = append(resp.Data, modelInfo{ID: id, Object: "model"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBackend serves health, models, and an echo completion endpoint
+// that reports which backend answered
+func fakeBackend(t *testing.T, name string, models []string, healthy bool) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		type info struct {
+			ID string `json:"id"`
+		}
+		resp := struct {
+			Object string `json:"object"`
+			Data   []info `json:"data"`
+		}{Object: "list"}
+		for _, m := range models {
+			resp.Data = append(resp.Data, info{ID: m})
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"backend": name})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestRouter(t *testing.T, urls ...string) *Router {
+	t.Helper()
+	r, err := New(urls)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	r.CheckHealth(context.Background())
+	return r
+}
+
+// routeCompletion sends a completion through the router and returns
+// the answering backend's name
+func routeCompletion(t *testing.T, r *Router, body string) (string, int) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return "", w.Code
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode routed response: %v", err)
+	}
+	return resp["backend"], w.Code
+}
+
+func TestNewValidation(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) succeeded, want error")
+	}
+	if _, err := New([]string{"not a url"}); err == nil {
+		t.Error("New with invalid URL succeeded, want error")
+	}
+}
+
+func TestModelAwareRouting(t *testing.T) {
+	b2 := fakeBackend(t, "mac-2b", []string{"gui-actor-2b"}, true)
+	b7 := fakeBackend(t, "mac-7b", []string{"gui-actor-7b"}, true)
+	r := newTestRouter(t, b2.URL, b7.URL)
+
+	for i := 0; i < 3; i++ {
+		got, code := routeCompletion(t, r, `{"model":"gui-actor-7b"}`)
+		if code != http.StatusOK || got != "mac-7b" {
+			t.Fatalf("request for 7b routed to %q (status %d), want mac-7b", got, code)
+		}
+	}
+}
+
+func TestUnhealthyBackendSkipped(t *testing.T) {
+	down := fakeBackend(t, "down", []string{"gui-actor-2b"}, false)
+	up := fakeBackend(t, "up", []string{"gui-actor-2b"}, true)
+	r := newTestRouter(t, down.URL, up.URL)
+
+	for i := 0; i < 4; i++ {
+		got, code := routeCompletion(t, r, `{"model":"gui-actor-2b"}`)
+		if code != http.StatusOK || got != "up" {
+			t.Fatalf("request routed to %q (status %d), want up", got, code)
+		}
+	}
+}
+
+func TestNoEligibleBackend(t *testing.T) {
+	b := fakeBackend(t, "mac-1", []string{"gui-actor-2b"}, true)
+	r := newTestRouter(t, b.URL)
+
+	_, code := routeCompletion(t, r, `{"model":"unknown-model"}`)
+	if code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", code)
+	}
+}
+
+func TestSessionAffinity(t *testing.T) {
+	a := fakeBackend(t, "mac-a", []string{"gui-actor-2b"}, true)
+	b := fakeBackend(t, "mac-b", []string{"gui-actor-2b"}, true)
+	r := newTestRouter(t, a.URL, b.URL)
+
+	body := `{"model":"gui-actor-2b","session_id":"sess-1"}`
+	first, _ := routeCompletion(t, r, body)
+	for i := 0; i < 5; i++ {
+		got, _ := routeCompletion(t, r, body)
+		if got != first {
+			t.Fatalf("session request moved from %q to %q", first, got)
+		}
+	}
+}
+
+func TestPrefixAffinity(t *testing.T) {
+	a := fakeBackend(t, "mac-a", []string{"gui-actor-2b"}, true)
+	b := fakeBackend(t, "mac-b", []string{"gui-actor-2b"}, true)
+	r := newTestRouter(t, a.URL, b.URL)
+
+	// Same leading system prompt -> same backend
+	body := `{"model":"gui-actor-2b","messages":[{"role":"system","content":"You are a GUI agent."}]}`
+	first, _ := routeCompletion(t, r, body)
+	for i := 0; i < 5; i++ {
+		got, _ := routeCompletion(t, r, body)
+		if got != first {
+			t.Fatalf("prefix request moved from %q to %q", first, got)
+		}
+	}
+}
+
+func TestRoundRobinWithoutAffinity(t *testing.T) {
+	a := fakeBackend(t, "mac-a", []string{"gui-actor-2b"}, true)
+	b := fakeBackend(t, "mac-b", []string{"gui-actor-2b"}, true)
+	r := newTestRouter(t, a.URL, b.URL)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		got, _ := routeCompletion(t, r, `{"model":"gui-actor-2b"}`)
+		seen[got] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("round-robin hit %d backends, want 2", len(seen))
+	}
+}
+
+func TestAggregatedModels(t *testing.T) {
+	b2 := fakeBackend(t, "mac-2b", []string{"gui-actor-2b"}, true)
+	b7 := fakeBackend(t, "mac-7b", []string{"gui-actor-7b", "gui-actor-2b"}, true)
+	down := fakeBackend(t, "down", []string{"gui-actor-13b"}, false)
+	r := newTestRouter(t, b2.URL, b7.URL, down.URL)
+
+	req := httptest.NewRequest("GET", "/v1/models", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode models response: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("aggregated %d models, want 2 (deduped, unhealthy excluded)", len(resp.Data))
+	}
+	if resp.Data[0].ID != "gui-actor-2b" || resp.Data[1].ID != "gui-actor-7b" {
+		t.Errorf("models = %v, want sorted [gui-actor-2b gui-actor-7b]", resp.Data)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	b := fakeBackend(t, "mac-1", []string{"gui-actor-2b"}, true)
+	r, err := New([]string{b.URL})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	r.Start(time.Millisecond)
+	defer r.Stop()
+
+	if !r.Backends()[0].Healthy() {
+		t.Error("backend not marked healthy after Start")
+	}
+}
@@ -0,0 +1,192 @@
+package sampling
+
+import (
+	"fmt"
+	"math"
+)
+
+// Constrained decoding for the GUI-Actor action grammar
+//
+//	pyautogui.<fn>(<float>, <float>)
+//
+// implemented as a byte DFA. Each decode step the constraint masks
+// every token whose text cannot extend a valid prefix, so the model is
+// forced to emit a well-formed action regardless of sampling settings.
+
+// DFA states; the literal prefix "pyautogui." occupies states 0..9,
+// one per matched byte
+const (
+	gaPrefixLen  = 10 // len("pyautogui.")
+	gaFnFirst    = 10 // expecting the first function-name letter
+	gaFn         = 11 // inside the function name
+	gaNum1Start  = 12 // expecting the first digit of the x coordinate
+	gaNum1Int    = 13 // integer digits of x
+	gaNum1Dot    = 14 // just consumed the decimal point of x
+	gaNum1Frac   = 15 // fraction digits of x
+	gaComma      = 16 // just consumed the separating comma
+	gaNum2Start  = 17 // expecting the first digit of the y coordinate
+	gaNum2Int    = 18 // integer digits of y
+	gaNum2Dot    = 19 // just consumed the decimal point of y
+	gaNum2Frac   = 20 // fraction digits of y
+	gaDone       = 21 // closing parenthesis consumed
+	gaActionSpec = "pyautogui."
+)
+
+// stepGUIAction advances the DFA by one byte; ok is false when the
+// byte is not valid in the current state
+func stepGUIAction(state int, b byte) (int, bool) {
+	isLetter := (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+	isDigit := b >= '0' && b <= '9'
+
+	switch {
+	case state < gaPrefixLen:
+		if b != gaActionSpec[state] {
+			return 0, false
+		}
+		return state + 1, true
+	case state == gaFnFirst:
+		if !isLetter {
+			return 0, false
+		}
+		return gaFn, true
+	case state == gaFn:
+		if isLetter {
+			return gaFn, true
+		}
+		if b == '(' {
+			return gaNum1Start, true
+		}
+		return 0, false
+	case state == gaNum1Start:
+		if !isDigit {
+			return 0, false
+		}
+		return gaNum1Int, true
+	case state == gaNum1Int:
+		if isDigit {
+			return gaNum1Int, true
+		}
+		if b == '.' {
+			return gaNum1Dot, true
+		}
+		if b == ',' {
+			return gaComma, true
+		}
+		return 0, false
+	case state == gaNum1Dot:
+		if !isDigit {
+			return 0, false
+		}
+		return gaNum1Frac, true
+	case state == gaNum1Frac:
+		if isDigit {
+			return gaNum1Frac, true
+		}
+		if b == ',' {
+			return gaComma, true
+		}
+		return 0, false
+	case state == gaComma:
+		if b == ' ' {
+			return gaNum2Start, true
+		}
+		if isDigit {
+			return gaNum2Int, true
+		}
+		return 0, false
+	case state == gaNum2Start:
+		if !isDigit {
+			return 0, false
+		}
+		return gaNum2Int, true
+	case state == gaNum2Int:
+		if isDigit {
+			return gaNum2Int, true
+		}
+		if b == '.' {
+			return gaNum2Dot, true
+		}
+		if b == ')' {
+			return gaDone, true
+		}
+		return 0, false
+	case state == gaNum2Dot:
+		if !isDigit {
+			return 0, false
+		}
+		return gaNum2Frac, true
+	case state == gaNum2Frac:
+		if isDigit {
+			return gaNum2Frac, true
+		}
+		if b == ')' {
+			return gaDone, true
+		}
+		return 0, false
+	}
+	// gaDone accepts nothing further
+	return 0, false
+}
+
+// advanceGUIAction runs the DFA over a byte string from state
+func advanceGUIAction(state int, text string) (int, bool) {
+	ok := true
+	for i := 0; i < len(text); i++ {
+		state, ok = stepGUIAction(state, text[i])
+		if !ok {
+			return 0, false
+		}
+	}
+	return state, true
+}
+
+// MatchGUIAction reports whether the text is a complete action
+func MatchGUIAction(text string) bool {
+	state, ok := advanceGUIAction(0, text)
+	return ok && state == gaDone
+}
+
+// GUIActionConstraint masks tokens that would leave the action grammar.
+// It carries the DFA state across decode steps of one request; not safe
+// for concurrent use.
+type GUIActionConstraint struct {
+	tokenText func(id int) string
+	state     int
+}
+
+// NewGUIActionConstraint builds the constraint over a vocabulary,
+// described by a token-id-to-text lookup
+func NewGUIActionConstraint(tokenText func(id int) string) *GUIActionConstraint {
+	return &GUIActionConstraint{tokenText: tokenText}
+}
+
+// Process bans every token whose text cannot extend the current valid
+// prefix
+func (c *GUIActionConstraint) Process(history []int, logits []float32) {
+	for id := range logits {
+		text := c.tokenText(id)
+		if text == "" {
+			logits[id] = float32(math.Inf(-1))
+			continue
+		}
+		if _, ok := advanceGUIAction(c.state, text); !ok {
+			logits[id] = float32(math.Inf(-1))
+		}
+	}
+}
+
+// Accept advances the DFA past a sampled token; an error means the
+// token was not legal in the current state
+func (c *GUIActionConstraint) Accept(id int) error {
+	next, ok := advanceGUIAction(c.state, c.tokenText(id))
+	if !ok {
+		return fmt.Errorf("token %d leaves the gui_action grammar", id)
+	}
+	c.state = next
+	return nil
+}
+
+// Done reports whether a complete action has been accepted
+func (c *GUIActionConstraint) Done() bool {
+	return c.state == gaDone
+}
@@ -0,0 +1,94 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMatchGUIAction(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"pyautogui.click(0.5, 0.5)", true},
+		{"pyautogui.click(0.5,0.5)", true},
+		{"pyautogui.doubleClick(1, 0)", true},
+		{"pyautogui.moveTo(0.875, 0.125)", true},
+		{"pyautogui.click(120, 480)", true},
+		{"pyautogui.click(0.5, 0.5", false},
+		{"pyautogui.(0.5, 0.5)", false},
+		{"pyautogui.click(, 0.5)", false},
+		{"pyautogui.click(0.5)", false},
+		{"pyautogui.click(0., 0.5)", false},
+		{"click(0.5, 0.5)", false},
+		{"pyautogui.click(0.5, 0.5) ", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGUIAction(tt.text); got != tt.want {
+			t.Errorf("MatchGUIAction(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// tinyVocab maps token IDs to text fragments for constraint tests
+var tinyVocab = []string{"pyautogui.", "click", "(", "0.5", ", ", ")", "hello", "click(0"}
+
+func tinyTokenText(id int) string {
+	if id < 0 || id >= len(tinyVocab) {
+		return ""
+	}
+	return tinyVocab[id]
+}
+
+func TestConstraintMasksInvalidTokens(t *testing.T) {
+	c := NewGUIActionConstraint(tinyTokenText)
+	logits := make([]float32, len(tinyVocab))
+	c.Process(nil, logits)
+
+	// At the start only the prefix token (and fragments extending it)
+	// survive
+	if math.IsInf(float64(logits[0]), -1) {
+		t.Error(`"pyautogui." banned at start`)
+	}
+	for _, id := range []int{1, 2, 3, 4, 5, 6, 7} {
+		if !math.IsInf(float64(logits[id]), -1) {
+			t.Errorf("token %q allowed at start", tinyVocab[id])
+		}
+	}
+}
+
+func TestConstraintAdvancesAcrossSteps(t *testing.T) {
+	c := NewGUIActionConstraint(tinyTokenText)
+	for _, id := range []int{0, 1, 2, 3, 4, 3, 5} {
+		if err := c.Accept(id); err != nil {
+			t.Fatalf("Accept(%q) failed: %v", tinyVocab[id], err)
+		}
+	}
+	if !c.Done() {
+		t.Error("complete action not recognized as done")
+	}
+}
+
+func TestConstraintMultiBytTokens(t *testing.T) {
+	c := NewGUIActionConstraint(tinyTokenText)
+	if err := c.Accept(0); err != nil {
+		t.Fatal(err)
+	}
+	// "click(0" spans the name, parenthesis and first digit in one token
+	logits := make([]float32, len(tinyVocab))
+	c.Process(nil, logits)
+	if math.IsInf(float64(logits[7]), -1) {
+		t.Error("multi-part token banned although it extends the prefix")
+	}
+}
+
+func TestConstraintRejectsIllegalAccept(t *testing.T) {
+	c := NewGUIActionConstraint(tinyTokenText)
+	if err := c.Accept(6); err == nil {
+		t.Error(`Accept("hello") succeeded at grammar start`)
+	}
+	if c.Done() {
+		t.Error("Done after a rejected token")
+	}
+}
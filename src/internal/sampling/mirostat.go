@@ -0,0 +1,195 @@
+// Package sampling implements token samplers over model logits.
+// Mirostat adaptively tunes the truncation threshold each step to hold
+// the surprise (negative log2 probability) of sampled tokens near a
+// target, which keeps long action rationales evenly "surprising"
+// instead of drifting between bland and incoherent as plain top-p can.
+package sampling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Sampler picks the next token from a logit vector, carrying whatever
+// state it needs across decode steps of one request.
+type Sampler interface {
+	Sample(logits []float32) int
+}
+
+// Mirostat defaults from the paper (Basu et al., 2021)
+const (
+	DefaultMirostatTau = 5.0
+	DefaultMirostatEta = 0.1
+	// mirostatM is how many top tokens v1 uses to estimate the Zipf
+	// exponent of the distribution
+	mirostatM = 100
+)
+
+// MirostatConfig selects and tunes a Mirostat sampler
+type MirostatConfig struct {
+	// Version is 1 (top-k via Zipf estimate) or 2 (direct surprise
+	// truncation); v2 is simpler and usually preferred
+	Version int
+	// Tau is the target surprise in bits; zero applies the default
+	Tau float64
+	// Eta is the learning rate for the threshold update; zero applies
+	// the default
+	Eta float64
+	// Seed fixes the RNG for reproducible sampling; zero seeds from the
+	// global source
+	Seed int64
+}
+
+// Mirostat holds the per-request adaptive state. Not safe for
+// concurrent use; each request gets its own instance.
+type Mirostat struct {
+	version int
+	tau     float64
+	eta     float64
+	mu      float64
+	rng     *rand.Rand
+}
+
+// NewMirostat validates the config and builds a sampler with the
+// threshold initialized to twice the target surprise, per the paper.
+func NewMirostat(cfg MirostatConfig) (*Mirostat, error) {
+	if cfg.Version != 1 && cfg.Version != 2 {
+		return nil, fmt.Errorf("mirostat version must be 1 or 2, got %d", cfg.Version)
+	}
+	if cfg.Tau < 0 {
+		return nil, fmt.Errorf("mirostat tau must be non-negative, got %g", cfg.Tau)
+	}
+	if cfg.Eta < 0 {
+		return nil, fmt.Errorf("mirostat eta must be non-negative, got %g", cfg.Eta)
+	}
+	if cfg.Tau == 0 {
+		cfg.Tau = DefaultMirostatTau
+	}
+	if cfg.Eta == 0 {
+		cfg.Eta = DefaultMirostatEta
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	return &Mirostat{
+		version: cfg.Version,
+		tau:     cfg.Tau,
+		eta:     cfg.Eta,
+		mu:      2 * cfg.Tau,
+		rng:     rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Mu exposes the current truncation threshold, mostly for tests and
+// debug traces
+func (m *Mirostat) Mu() float64 { return m.mu }
+
+// Sample picks the next token and updates the adaptive threshold from
+// the observed surprise
+func (m *Mirostat) Sample(logits []float32) int {
+	probs, index := softmaxSorted(logits)
+
+	var cut int
+	if m.version == 1 {
+		cut = m.topKFromZipf(probs)
+	} else {
+		cut = m.truncateBySurprise(probs)
+	}
+
+	chosen := m.sampleFrom(probs[:cut])
+	surprise := -math.Log2(probs[chosen])
+	m.mu -= m.eta * (surprise - m.tau)
+	return index[chosen]
+}
+
+// softmaxSorted returns the probabilities in descending order along
+// with the original token index of each entry
+func softmaxSorted(logits []float32) ([]float64, []int) {
+	index := make([]int, len(logits))
+	for i := range index {
+		index[i] = i
+	}
+	sort.Slice(index, func(a, b int) bool { return logits[index[a]] > logits[index[b]] })
+
+	max := float64(logits[index[0]])
+	probs := make([]float64, len(logits))
+	var sum float64
+	for i, tok := range index {
+		probs[i] = math.Exp(float64(logits[tok]) - max)
+		sum += probs[i]
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	return probs, index
+}
+
+// truncateBySurprise keeps the tokens whose surprise is below the
+// current threshold (Mirostat v2), always at least the top one
+func (m *Mirostat) truncateBySurprise(probs []float64) int {
+	cut := 0
+	for _, p := range probs {
+		if -math.Log2(p) > m.mu {
+			break
+		}
+		cut++
+	}
+	if cut == 0 {
+		cut = 1
+	}
+	return cut
+}
+
+// topKFromZipf estimates the Zipf exponent of the distribution from
+// the top tokens and derives the k that meets the surprise target
+// (Mirostat v1)
+func (m *Mirostat) topKFromZipf(probs []float64) int {
+	n := len(probs)
+	pairs := mirostatM - 1
+	if pairs > n-1 {
+		pairs = n - 1
+	}
+	var num, den float64
+	for i := 0; i < pairs; i++ {
+		t := math.Log(float64(i+2) / float64(i+1))
+		b := math.Log(probs[i] / probs[i+1])
+		num += t * b
+		den += t * t
+	}
+	if den == 0 {
+		return 1
+	}
+	s := num / den
+	eps := s - 1
+	if eps <= 0 {
+		return n
+	}
+	k := math.Pow(eps*math.Exp2(m.mu)/(1-math.Pow(float64(n), -eps)), 1/s)
+	cut := int(math.Round(k))
+	if cut < 1 {
+		cut = 1
+	}
+	if cut > n {
+		cut = n
+	}
+	return cut
+}
+
+// sampleFrom draws an index from the (unnormalized) candidate prefix
+func (m *Mirostat) sampleFrom(probs []float64) int {
+	var sum float64
+	for _, p := range probs {
+		sum += p
+	}
+	r := m.rng.Float64() * sum
+	for i, p := range probs {
+		r -= p
+		if r <= 0 {
+			return i
+		}
+	}
+	return len(probs) - 1
+}
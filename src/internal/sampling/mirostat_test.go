@@ -0,0 +1,134 @@
+package sampling
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// zipfLogits builds a heavy-tailed logit vector resembling a language
+// model head
+func zipfLogits(n int, s float64) []float32 {
+	logits := make([]float32, n)
+	for i := range logits {
+		logits[i] = float32(-s * math.Log(float64(i+1)))
+	}
+	// Shuffle deterministically so sorted order differs from token order
+	rng := rand.New(rand.NewSource(7))
+	rng.Shuffle(n, func(a, b int) { logits[a], logits[b] = logits[b], logits[a] })
+	return logits
+}
+
+func TestNewMirostatValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     MirostatConfig
+		wantErr bool
+	}{
+		{"v1", MirostatConfig{Version: 1}, false},
+		{"v2", MirostatConfig{Version: 2}, false},
+		{"v0", MirostatConfig{Version: 0}, true},
+		{"v3", MirostatConfig{Version: 3}, true},
+		{"negative tau", MirostatConfig{Version: 2, Tau: -1}, true},
+		{"negative eta", MirostatConfig{Version: 2, Eta: -0.5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMirostat(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMirostat(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultsApplied(t *testing.T) {
+	m, err := NewMirostat(MirostatConfig{Version: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.tau != DefaultMirostatTau || m.eta != DefaultMirostatEta {
+		t.Errorf("tau, eta = %g, %g, want defaults %g, %g", m.tau, m.eta, DefaultMirostatTau, DefaultMirostatEta)
+	}
+	if m.Mu() != 2*DefaultMirostatTau {
+		t.Errorf("initial mu = %g, want %g", m.Mu(), 2*DefaultMirostatTau)
+	}
+}
+
+func TestV2SurpriseConverges(t *testing.T) {
+	const tau = 3.0
+	m, err := NewMirostat(MirostatConfig{Version: 2, Tau: tau, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logits := zipfLogits(1000, 1.1)
+	var total float64
+	const steps = 500
+	for i := 0; i < steps; i++ {
+		tok := m.Sample(logits)
+		if tok < 0 || tok >= len(logits) {
+			t.Fatalf("step %d sampled out-of-range token %d", i, tok)
+		}
+		probs, index := softmaxSorted(logits)
+		for j, orig := range index {
+			if orig == tok {
+				total += -math.Log2(probs[j])
+				break
+			}
+		}
+	}
+
+	mean := total / steps
+	if math.Abs(mean-tau) > 1.0 {
+		t.Errorf("mean surprise = %.2f, want within 1 bit of tau %.1f", mean, tau)
+	}
+}
+
+func TestV2GreedyAtZeroTau(t *testing.T) {
+	// A tiny tau drives mu toward zero, leaving only the top token
+	m, err := NewMirostat(MirostatConfig{Version: 2, Tau: 0.01, Eta: 1, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logits := zipfLogits(100, 2.0)
+	_, index := softmaxSorted(logits)
+	for i := 0; i < 20; i++ {
+		m.Sample(logits)
+	}
+	if tok := m.Sample(logits); tok != index[0] {
+		t.Errorf("converged sample = %d, want top token %d", tok, index[0])
+	}
+}
+
+func TestV1SamplesValidTokens(t *testing.T) {
+	m, err := NewMirostat(MirostatConfig{Version: 1, Tau: 4, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logits := zipfLogits(500, 1.2)
+	seen := make(map[int]struct{})
+	for i := 0; i < 200; i++ {
+		tok := m.Sample(logits)
+		if tok < 0 || tok >= len(logits) {
+			t.Fatalf("step %d sampled out-of-range token %d", i, tok)
+		}
+		seen[tok] = struct{}{}
+	}
+	if len(seen) < 2 {
+		t.Error("v1 degenerated to a single token on a heavy-tailed distribution")
+	}
+}
+
+func TestSeedReproducible(t *testing.T) {
+	logits := zipfLogits(200, 1.1)
+	a, _ := NewMirostat(MirostatConfig{Version: 2, Seed: 99})
+	b, _ := NewMirostat(MirostatConfig{Version: 2, Seed: 99})
+	for i := 0; i < 50; i++ {
+		if ta, tb := a.Sample(logits), b.Sample(logits); ta != tb {
+			t.Fatalf("step %d diverged: %d vs %d", i, ta, tb)
+		}
+	}
+}
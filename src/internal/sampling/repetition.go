@@ -0,0 +1,150 @@
+package sampling
+
+import (
+	"fmt"
+	"math"
+)
+
+// Processor adjusts logits in place before sampling, seeing the tokens
+// generated so far. Processors run each decode step, ahead of whatever
+// sampler picks the token.
+type Processor interface {
+	Process(history []int, logits []float32)
+}
+
+// DRY defaults follow the reference implementation
+const (
+	DefaultDRYMultiplier    = 0.8
+	DefaultDRYBase          = 1.75
+	DefaultDRYAllowedLength = 2
+)
+
+// NoRepeatNGram bans every token that would complete an n-gram already
+// present in the generated history, the classic hard constraint against
+// verbatim loops.
+type NoRepeatNGram struct {
+	n int
+}
+
+func NewNoRepeatNGram(n int) (*NoRepeatNGram, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("no_repeat_ngram_size must be at least 1, got %d", n)
+	}
+	return &NoRepeatNGram{n: n}, nil
+}
+
+func (p *NoRepeatNGram) Process(history []int, logits []float32) {
+	if len(history) < p.n-1 {
+		return
+	}
+	suffix := history[len(history)-(p.n-1):]
+	for i := 0; i+p.n <= len(history); i++ {
+		if !equalTokens(history[i:i+p.n-1], suffix) {
+			continue
+		}
+		banned := history[i+p.n-1]
+		if banned >= 0 && banned < len(logits) {
+			logits[banned] = float32(math.Inf(-1))
+		}
+	}
+}
+
+func equalTokens(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DRY ("don't repeat yourself") softly penalizes tokens that would
+// extend a sequence already seen earlier in the history, with the
+// penalty growing exponentially in the length of the repeat. Unlike
+// NoRepeatNGram it leaves short, natural repetitions alone and only
+// bears down as a loop actually develops.
+type DRY struct {
+	multiplier float64
+	base       float64
+	allowedLen int
+}
+
+// DRYConfig tunes the suppressor; zero values apply the defaults
+type DRYConfig struct {
+	// Multiplier scales the penalty; negative is invalid
+	Multiplier float64
+	// Base is the exponential growth factor per repeated token beyond
+	// the allowed length; must be at least 1 when set
+	Base float64
+	// AllowedLength is the longest repeat that goes unpenalized
+	AllowedLength int
+}
+
+func NewDRY(cfg DRYConfig) (*DRY, error) {
+	if cfg.Multiplier < 0 {
+		return nil, fmt.Errorf("dry multiplier must be non-negative, got %g", cfg.Multiplier)
+	}
+	if cfg.Base != 0 && cfg.Base < 1 {
+		return nil, fmt.Errorf("dry base must be at least 1, got %g", cfg.Base)
+	}
+	if cfg.AllowedLength < 0 {
+		return nil, fmt.Errorf("dry allowed length must be non-negative, got %d", cfg.AllowedLength)
+	}
+	if cfg.Multiplier == 0 {
+		cfg.Multiplier = DefaultDRYMultiplier
+	}
+	if cfg.Base == 0 {
+		cfg.Base = DefaultDRYBase
+	}
+	if cfg.AllowedLength == 0 {
+		cfg.AllowedLength = DefaultDRYAllowedLength
+	}
+	return &DRY{
+		multiplier: cfg.Multiplier,
+		base:       cfg.Base,
+		allowedLen: cfg.AllowedLength,
+	}, nil
+}
+
+func (p *DRY) Process(history []int, logits []float32) {
+	n := len(history)
+	if n < p.allowedLen {
+		return
+	}
+
+	// For every earlier position whose preceding tokens match the tail
+	// of the history, the token that followed it would extend a repeat;
+	// penalize it by the longest such match.
+	best := make(map[int]int)
+	for i := 0; i < n-1; i++ {
+		match := suffixMatch(history, i)
+		if match < p.allowedLen {
+			continue
+		}
+		tok := history[i+1]
+		if tok < 0 || tok >= len(logits) {
+			continue
+		}
+		if match > best[tok] {
+			best[tok] = match
+		}
+	}
+	for tok, match := range best {
+		penalty := p.multiplier * math.Pow(p.base, float64(match-p.allowedLen))
+		logits[tok] -= float32(penalty)
+	}
+}
+
+// suffixMatch returns how many tokens of the history's tail match the
+// run ending at position i
+func suffixMatch(history []int, i int) int {
+	n := len(history)
+	match := 0
+	for j := 0; j <= i && history[i-j] == history[n-1-j]; j++ {
+		match++
+	}
+	return match
+}
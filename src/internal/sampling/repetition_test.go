@@ -0,0 +1,138 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+)
+
+func flatLogits(n int) []float32 {
+	return make([]float32, n)
+}
+
+func TestNoRepeatNGramBansCompletion(t *testing.T) {
+	p, err := NewNoRepeatNGram(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// History contains the trigram [1 2 3]; with the tail at [1 2] the
+	// token 3 would repeat it
+	history := []int{1, 2, 3, 4, 1, 2}
+	logits := flatLogits(10)
+	p.Process(history, logits)
+
+	if !math.IsInf(float64(logits[3]), -1) {
+		t.Error("token completing a seen trigram was not banned")
+	}
+	if math.IsInf(float64(logits[4]), -1) {
+		t.Error("unrelated token was banned")
+	}
+}
+
+func TestNoRepeatUnigramBansAllHistory(t *testing.T) {
+	p, err := NewNoRepeatNGram(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := []int{2, 5, 7}
+	logits := flatLogits(10)
+	p.Process(history, logits)
+
+	for _, tok := range history {
+		if !math.IsInf(float64(logits[tok]), -1) {
+			t.Errorf("token %d in history was not banned", tok)
+		}
+	}
+	if math.IsInf(float64(logits[1]), -1) {
+		t.Error("unseen token was banned")
+	}
+}
+
+func TestNoRepeatNGramShortHistory(t *testing.T) {
+	p, _ := NewNoRepeatNGram(4)
+	logits := flatLogits(10)
+	p.Process([]int{1, 2}, logits)
+	for i, l := range logits {
+		if l != 0 {
+			t.Fatalf("logit %d modified on short history", i)
+		}
+	}
+}
+
+func TestNoRepeatNGramValidation(t *testing.T) {
+	if _, err := NewNoRepeatNGram(0); err == nil {
+		t.Error("accepted n-gram size 0")
+	}
+}
+
+func TestDRYPenalizesLoopContinuation(t *testing.T) {
+	p, err := NewDRY(DRYConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tail [5 6 7] already occurred, so token 8 would extend the
+	// repeat to four tokens
+	history := []int{5, 6, 7, 8, 9, 5, 6, 7}
+	logits := flatLogits(16)
+	p.Process(history, logits)
+
+	if logits[8] >= 0 {
+		t.Error("loop continuation token was not penalized")
+	}
+	if logits[9] < logits[8] {
+		t.Error("unrelated token penalized harder than the loop continuation")
+	}
+}
+
+func TestDRYPenaltyGrowsWithMatchLength(t *testing.T) {
+	p, err := NewDRY(DRYConfig{AllowedLength: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short := flatLogits(16)
+	p.Process([]int{3, 4, 9, 3, 4}, short) // 2-token match penalizes 9
+
+	long := flatLogits(16)
+	p.Process([]int{3, 4, 5, 9, 3, 4, 5}, long) // 3-token match penalizes 9
+
+	if long[9] >= short[9] {
+		t.Errorf("penalty did not grow with match length: short %g, long %g", short[9], long[9])
+	}
+}
+
+func TestDRYAllowsShortRepeats(t *testing.T) {
+	p, err := NewDRY(DRYConfig{AllowedLength: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only a 2-token match: below the allowed length, so untouched
+	history := []int{3, 4, 9, 3, 4}
+	logits := flatLogits(16)
+	p.Process(history, logits)
+
+	if logits[9] != 0 {
+		t.Errorf("short repeat penalized: logit = %g", logits[9])
+	}
+}
+
+func TestDRYValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DRYConfig
+	}{
+		{"negative multiplier", DRYConfig{Multiplier: -1}},
+		{"base below one", DRYConfig{Base: 0.5}},
+		{"negative allowed length", DRYConfig{AllowedLength: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDRY(tt.cfg); err == nil {
+				t.Errorf("accepted config %+v", tt.cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// Tuning defaults
+const (
+	DefaultGrowHeadroom   = 0.30
+	DefaultShrinkHeadroom = 0.10
+	DefaultOOMBackoff     = 30 * time.Second
+	DefaultTuneInterval   = 5 * time.Second
+)
+
+// MemoryStats reports live accelerator memory in any consistent unit;
+// a non-positive limit skips that tuning sample
+type MemoryStats func() (used, limit float64)
+
+// Tune grows or shrinks the slot count from a memory sample: plenty of
+// headroom (and no recent OOM) admits more concurrent decodes, tight
+// headroom sheds them
+func (s *Scheduler) Tune(used, limit float64) {
+	if limit <= 0 {
+		return
+	}
+	free := 1.0 - used/limit
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case free < s.cfg.ShrinkHeadroom && s.slots > s.cfg.MinSlots:
+		s.slots--
+	case free > s.cfg.GrowHeadroom && s.slots < s.cfg.MaxSlots &&
+		time.Since(s.lastOOM) > s.cfg.OOMBackoff:
+		s.slots++
+		s.admitLocked()
+	}
+}
+
+// ReportOOM halves the slot count after an out-of-memory event and
+// holds off growth for the backoff window
+func (s *Scheduler) ReportOOM() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastOOM = time.Now()
+	s.slots /= 2
+	if s.slots < s.cfg.MinSlots {
+		s.slots = s.cfg.MinSlots
+	}
+}
+
+// Slots returns the current concurrency bound
+func (s *Scheduler) Slots() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slots
+}
+
+// AutoTune samples memory on an interval and tunes the slot count
+// until the returned stop function is called
+func (s *Scheduler) AutoTune(stats MemoryStats, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultTuneInterval
+	}
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Tune(stats())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTuneGrowsOnHeadroom(t *testing.T) {
+	s := New(Config{Slots: 2, MaxSlots: 4})
+
+	s.Tune(10, 100) // 90% free
+	if got := s.Slots(); got != 3 {
+		t.Errorf("Slots = %d, want 3", got)
+	}
+	s.Tune(10, 100)
+	s.Tune(10, 100)
+	if got := s.Slots(); got != 4 {
+		t.Errorf("Slots = %d, want 4 (capped at MaxSlots)", got)
+	}
+}
+
+func TestTuneShrinksOnPressure(t *testing.T) {
+	s := New(Config{Slots: 4, MinSlots: 2})
+
+	s.Tune(95, 100) // 5% free
+	if got := s.Slots(); got != 3 {
+		t.Errorf("Slots = %d, want 3", got)
+	}
+	s.Tune(95, 100)
+	s.Tune(95, 100)
+	if got := s.Slots(); got != 2 {
+		t.Errorf("Slots = %d, want 2 (floored at MinSlots)", got)
+	}
+}
+
+func TestTuneHoldsInBand(t *testing.T) {
+	s := New(Config{Slots: 4, MinSlots: 1, MaxSlots: 8})
+
+	s.Tune(80, 100) // 20% free: between shrink (10%) and grow (30%)
+	if got := s.Slots(); got != 4 {
+		t.Errorf("Slots = %d, want 4", got)
+	}
+	s.Tune(10, 0) // no limit: sample skipped
+	if got := s.Slots(); got != 4 {
+		t.Errorf("Slots after limitless sample = %d, want 4", got)
+	}
+}
+
+func TestReportOOMHalvesAndBacksOff(t *testing.T) {
+	s := New(Config{Slots: 8, MinSlots: 1, MaxSlots: 8})
+
+	s.ReportOOM()
+	if got := s.Slots(); got != 4 {
+		t.Errorf("Slots after OOM = %d, want 4", got)
+	}
+
+	// Growth is blocked during the backoff window despite headroom
+	s.Tune(10, 100)
+	if got := s.Slots(); got != 4 {
+		t.Errorf("Slots = %d, want 4 (growth blocked by OOM backoff)", got)
+	}
+
+	// After the window passes, growth resumes
+	s.mu.Lock()
+	s.lastOOM = time.Now().Add(-time.Minute)
+	s.mu.Unlock()
+	s.Tune(10, 100)
+	if got := s.Slots(); got != 5 {
+		t.Errorf("Slots = %d, want 5", got)
+	}
+}
+
+func TestReportOOMRespectsMinSlots(t *testing.T) {
+	s := New(Config{Slots: 2, MinSlots: 2, MaxSlots: 4})
+	s.ReportOOM()
+	if got := s.Slots(); got != 2 {
+		t.Errorf("Slots = %d, want 2", got)
+	}
+}
+
+func TestGrowthAdmitsWaiters(t *testing.T) {
+	s := New(Config{Slots: 1, MaxSlots: 2})
+	if err := s.Acquire(context.Background(), "holder"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	admitted := make(chan struct{})
+	go func() {
+		if err := s.Acquire(context.Background(), "a"); err != nil {
+			t.Errorf("Acquire failed: %v", err)
+		}
+		close(admitted)
+	}()
+	waitForQueued(t, s, 1)
+
+	// Growing the batch admits the waiter without a Release
+	s.Tune(10, 100)
+	select {
+	case <-admitted:
+	case <-time.After(time.Second):
+		t.Fatal("Waiter not admitted after batch grew")
+	}
+}
+
+func TestAutoTune(t *testing.T) {
+	s := New(Config{Slots: 1, MaxSlots: 4})
+
+	stop := s.AutoTune(func() (float64, float64) { return 10, 100 }, time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Slots() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Slots = %d, want 4 after autotune", s.Slots())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
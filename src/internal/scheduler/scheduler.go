@@ -0,0 +1,255 @@
+// Package scheduler admits requests through weighted fair queuing
+// keyed by API key, so one tenant's batch evaluation cannot starve
+// interactive users sharing the same server.
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultSlots is the number of concurrently admitted requests when
+// unconfigured
+const DefaultSlots = 4
+
+// Config sets the admission capacity and per-tenant weights
+type Config struct {
+	// Slots is the initial number of requests served concurrently
+	Slots int
+	// MinSlots and MaxSlots bound dynamic tuning; both default to
+	// Slots, which disables growth and shrinkage
+	MinSlots int
+	MaxSlots int
+	// GrowHeadroom and ShrinkHeadroom are free-memory fractions:
+	// above GrowHeadroom the batch grows, below ShrinkHeadroom it
+	// shrinks
+	GrowHeadroom   float64
+	ShrinkHeadroom float64
+	// OOMBackoff blocks growth for this long after an OOM event
+	OOMBackoff time.Duration
+	// DefaultWeight applies to tenants without an explicit weight
+	// (<= 0 means 1.0)
+	DefaultWeight float64
+	// Weights maps API keys to their share; a weight of 2 gets twice
+	// the throughput of a weight of 1 under contention
+	Weights map[string]float64
+	// ShedThreshold turns on load shedding: once smoothed queue wait
+	// exceeds it, arrivals are probabilistically rejected, low-weight
+	// tenants first (zero disables)
+	ShedThreshold time.Duration
+}
+
+// waiter is one queued request
+type waiter struct {
+	finish    float64
+	seq       uint64
+	enqueued  time.Time
+	admit     chan struct{}
+	cancelled bool
+	index     int
+}
+
+// waitQueue orders waiters by virtual finish time, arrival breaking ties
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].finish != q[j].finish {
+		return q[i].finish < q[j].finish
+	}
+	return q[i].seq < q[j].seq
+}
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+func (q *waitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return w
+}
+
+// Scheduler hands out admission slots in weighted fair order
+type Scheduler struct {
+	cfg Config
+
+	mu         sync.Mutex
+	slots      int
+	inFlight   int
+	queue      waitQueue
+	vtime      float64
+	lastFinish map[string]float64
+	seq        uint64
+	lastOOM    time.Time
+	paused     bool
+	waitEWMA   float64
+	shedCount  int64
+}
+
+// New creates a scheduler from the config
+func New(cfg Config) *Scheduler {
+	if cfg.Slots <= 0 {
+		cfg.Slots = DefaultSlots
+	}
+	if cfg.MinSlots <= 0 {
+		cfg.MinSlots = cfg.Slots
+	}
+	if cfg.MaxSlots <= 0 {
+		cfg.MaxSlots = cfg.Slots
+	}
+	if cfg.MinSlots > cfg.MaxSlots {
+		cfg.MinSlots = cfg.MaxSlots
+	}
+	if cfg.GrowHeadroom <= 0 {
+		cfg.GrowHeadroom = DefaultGrowHeadroom
+	}
+	if cfg.ShrinkHeadroom <= 0 {
+		cfg.ShrinkHeadroom = DefaultShrinkHeadroom
+	}
+	if cfg.OOMBackoff <= 0 {
+		cfg.OOMBackoff = DefaultOOMBackoff
+	}
+	if cfg.DefaultWeight <= 0 {
+		cfg.DefaultWeight = 1.0
+	}
+	return &Scheduler{
+		cfg:        cfg,
+		slots:      cfg.Slots,
+		lastFinish: make(map[string]float64),
+	}
+}
+
+// weight resolves the tenant's configured share
+func (s *Scheduler) weight(apiKey string) float64 {
+	if w, ok := s.cfg.Weights[apiKey]; ok && w > 0 {
+		return w
+	}
+	return s.cfg.DefaultWeight
+}
+
+// Acquire blocks until the request is admitted in fair order, or the
+// context is cancelled. Every successful Acquire must be paired with
+// Release.
+func (s *Scheduler) Acquire(ctx context.Context, apiKey string) error {
+	s.mu.Lock()
+
+	// Stamp the request with its virtual finish time: later requests
+	// from a flooding tenant accumulate ever-larger tags, while a
+	// quiet tenant's first request starts near the current clock
+	start := s.vtime
+	if last, ok := s.lastFinish[apiKey]; ok && last > start {
+		start = last
+	}
+	finish := start + 1.0/s.weight(apiKey)
+	s.lastFinish[apiKey] = finish
+
+	if !s.paused && s.inFlight < s.slots && s.queue.Len() == 0 {
+		s.inFlight++
+		s.vtime = start
+		s.observeWait(0)
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{finish: finish, seq: s.seq, enqueued: time.Now(), admit: make(chan struct{})}
+	s.seq++
+	heap.Push(&s.queue, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.admit:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if w.index >= 0 && !w.cancelled {
+			w.cancelled = true
+			heap.Remove(&s.queue, w.index)
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+		s.mu.Unlock()
+		// Already admitted while cancelling: give the slot back
+		select {
+		case <-w.admit:
+			s.Release()
+		default:
+		}
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot and admits the next waiter in fair order
+func (s *Scheduler) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	if s.inFlight < 0 {
+		s.inFlight = 0
+	}
+	s.admitLocked()
+}
+
+// admitLocked grants free slots to waiters in fair order
+// Caller holds the lock.
+func (s *Scheduler) admitLocked() {
+	for !s.paused && s.inFlight < s.slots && s.queue.Len() > 0 {
+		w := heap.Pop(&s.queue).(*waiter)
+		if w.cancelled {
+			continue
+		}
+		w.index = -1
+		s.inFlight++
+		s.vtime = w.finish
+		s.observeWait(time.Since(w.enqueued))
+		close(w.admit)
+	}
+}
+
+// Pause stops admitting new requests; callers queue until Resume.
+// In-flight requests are unaffected.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume reopens admission and drains queued waiters in fair order
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+	s.admitLocked()
+}
+
+// Paused reports whether admission is suspended
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Queued returns the number of waiting requests
+func (s *Scheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// InFlight returns the number of admitted requests
+func (s *Scheduler) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
@@ -0,0 +1,244 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// drainOrder occupies the single slot, queues the given (key, count)
+// workloads, then releases repeatedly and records admission order
+func drainOrder(t *testing.T, s *Scheduler, workloads [][2]interface{}) []string {
+	t.Helper()
+	if err := s.Acquire(context.Background(), "holder"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, wl := range workloads {
+		key := wl[0].(string)
+		count := wl[1].(int)
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				if err := s.Acquire(context.Background(), key); err != nil {
+					t.Errorf("Acquire failed: %v", err)
+					return
+				}
+				mu.Lock()
+				order = append(order, key)
+				mu.Unlock()
+			}(key)
+			// Serialize enqueue so virtual finish tags are deterministic
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+
+	total := 0
+	for _, wl := range workloads {
+		total += wl[1].(int)
+	}
+	waitForQueued(t, s, total)
+
+	for i := 0; i < total+1; i++ {
+		s.Release()
+		time.Sleep(2 * time.Millisecond)
+	}
+	wg.Wait()
+	return order
+}
+
+func waitForQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Queued() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queued = %d, want >= %d", s.Queued(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestImmediateAdmission(t *testing.T) {
+	s := New(Config{Slots: 2})
+
+	if err := s.Acquire(context.Background(), "a"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if err := s.Acquire(context.Background(), "b"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if got := s.InFlight(); got != 2 {
+		t.Errorf("InFlight = %d, want 2", got)
+	}
+	s.Release()
+	s.Release()
+	if got := s.InFlight(); got != 0 {
+		t.Errorf("InFlight after releases = %d, want 0", got)
+	}
+}
+
+func TestEqualWeightsInterleave(t *testing.T) {
+	s := New(Config{Slots: 1})
+
+	// Tenant a floods four requests before tenant b's two arrive;
+	// equal weights should still interleave them
+	order := drainOrder(t, s, [][2]interface{}{{"a", 4}, {"b", 2}})
+
+	if len(order) != 6 {
+		t.Fatalf("admitted %d requests, want 6", len(order))
+	}
+	// b's first two tags (1, 2) sort ahead of a's later tags (3, 4)
+	bDone := 0
+	for i, key := range order[:4] {
+		_ = i
+		if key == "b" {
+			bDone++
+		}
+	}
+	if bDone != 2 {
+		t.Errorf("order = %v: tenant b finished %d of 2 within first four slots", order, bDone)
+	}
+}
+
+func TestWeightedShare(t *testing.T) {
+	s := New(Config{Slots: 1, Weights: map[string]float64{"fast": 4}})
+
+	order := drainOrder(t, s, [][2]interface{}{{"slow", 4}, {"fast", 4}})
+
+	if len(order) != 8 {
+		t.Fatalf("admitted %d requests, want 8", len(order))
+	}
+	// With 4x weight, all of fast's requests (tags 0.25..1.0) beat
+	// slow's second request (tag 2.0)
+	fastDone := 0
+	for _, key := range order[:5] {
+		if key == "fast" {
+			fastDone++
+		}
+	}
+	if fastDone != 4 {
+		t.Errorf("order = %v: fast tenant finished %d of 4 within first five slots", order, fastDone)
+	}
+}
+
+func TestAcquireCancellation(t *testing.T) {
+	s := New(Config{Slots: 1})
+	if err := s.Acquire(context.Background(), "holder"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Acquire(ctx, "a") }()
+	waitForQueued(t, s, 1)
+
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Errorf("Acquire = %v, want context.Canceled", err)
+	}
+	if got := s.Queued(); got != 0 {
+		t.Errorf("Queued after cancel = %d, want 0", got)
+	}
+
+	// The held slot is unaffected
+	s.Release()
+	if got := s.InFlight(); got != 0 {
+		t.Errorf("InFlight = %d, want 0", got)
+	}
+}
+
+func TestDefaultWeightApplied(t *testing.T) {
+	s := New(Config{})
+	if s.weight("anyone") != 1.0 {
+		t.Errorf("weight = %f, want 1.0", s.weight("anyone"))
+	}
+
+	weighted := New(Config{DefaultWeight: 2, Weights: map[string]float64{"vip": 8, "bogus": -1}})
+	if weighted.weight("anyone") != 2 {
+		t.Errorf("default weight = %f, want 2", weighted.weight("anyone"))
+	}
+	if weighted.weight("vip") != 8 {
+		t.Errorf("vip weight = %f, want 8", weighted.weight("vip"))
+	}
+	// Non-positive configured weights fall back to the default
+	if weighted.weight("bogus") != 2 {
+		t.Errorf("bogus weight = %f, want 2", weighted.weight("bogus"))
+	}
+}
+
+func TestPauseBlocksAdmission(t *testing.T) {
+	s := New(Config{Slots: 2})
+	s.Pause()
+	if !s.Paused() {
+		t.Fatal("Paused = false after Pause")
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Acquire(context.Background(), "a") }()
+	waitForQueued(t, s, 1)
+
+	select {
+	case <-errCh:
+		t.Fatal("Acquire admitted while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Resume()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Acquire after Resume: %v", err)
+	}
+	if got := s.InFlight(); got != 1 {
+		t.Errorf("InFlight = %d, want 1", got)
+	}
+}
+
+func TestPauseKeepsInFlightWork(t *testing.T) {
+	s := New(Config{Slots: 1})
+	if err := s.Acquire(context.Background(), "holder"); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	s.Pause()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Acquire(context.Background(), "a") }()
+	waitForQueued(t, s, 1)
+
+	// Releasing the in-flight slot must not admit waiters mid-sleep
+	s.Release()
+	select {
+	case <-errCh:
+		t.Fatal("Release admitted a waiter while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Resume()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Acquire after Resume: %v", err)
+	}
+	s.Release()
+}
+
+func TestPausedAcquireHonorsCancellation(t *testing.T) {
+	s := New(Config{Slots: 1})
+	s.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Acquire(ctx, "a") }()
+	waitForQueued(t, s, 1)
+
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Errorf("Acquire = %v, want context.Canceled", err)
+	}
+	s.Resume()
+	if got := s.Queued(); got != 0 {
+		t.Errorf("Queued = %d, want 0", got)
+	}
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Load shedding. When the queue backs up far enough that admitted
+// requests would blow the TTFT budget anyway, it is kinder to fail a
+// fraction of arrivals immediately than to time every request out.
+// Shedding is probabilistic and weighted: low-weight tenants are
+// rejected first, and high-weight tenants only once the overload is
+// severe.
+
+// shedAlpha smooths the observed queue wait; roughly the last five
+// admissions dominate the estimate
+const shedAlpha = 0.2
+
+// QueueWait returns the smoothed queue wait recent admissions observed
+func (s *Scheduler) QueueWait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.waitEWMA * float64(time.Second))
+}
+
+// Shed reports how many requests load shedding has rejected
+func (s *Scheduler) Shed() int64 {
+	return atomic.LoadInt64(&s.shedCount)
+}
+
+// observeWait folds one admission's queue wait into the moving
+// average. Caller holds the lock. Immediate admissions report zero,
+// which is what decays the estimate once the burst passes.
+func (s *Scheduler) observeWait(wait time.Duration) {
+	s.waitEWMA += shedAlpha * (wait.Seconds() - s.waitEWMA)
+}
+
+// ShouldShed decides whether to reject the request before queueing.
+// Below the configured threshold nothing is shed; past it the shed
+// probability ramps linearly, reaching certainty at twice the
+// threshold. A tenant's weight exponentiates the probability, so at
+// p=0.5 a weight-2 tenant is shed a quarter of the time while a
+// weight-1 tenant is shed half the time.
+func (s *Scheduler) ShouldShed(apiKey string) bool {
+	if s.cfg.ShedThreshold <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	wait := s.waitEWMA
+	weight := s.weight(apiKey)
+	s.mu.Unlock()
+
+	threshold := s.cfg.ShedThreshold.Seconds()
+	if wait <= threshold {
+		return false
+	}
+	p := (wait - threshold) / threshold
+	if p < 1 {
+		p = math.Pow(p, weight)
+	}
+	if rand.Float64() >= p {
+		return false
+	}
+	atomic.AddInt64(&s.shedCount, 1)
+	return true
+}
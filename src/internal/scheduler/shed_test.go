@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// setWait pins the smoothed queue-wait estimate directly so shedding
+// decisions can be tested without staging real queue buildup
+func setWait(s *Scheduler, wait time.Duration) {
+	s.mu.Lock()
+	s.waitEWMA = wait.Seconds()
+	s.mu.Unlock()
+}
+
+func TestShedDisabledWithoutThreshold(t *testing.T) {
+	s := New(Config{Slots: 1})
+	setWait(s, time.Hour)
+	for i := 0; i < 100; i++ {
+		if s.ShouldShed("key") {
+			t.Fatal("shed without a configured threshold")
+		}
+	}
+}
+
+func TestShedBelowThresholdAdmitsEverything(t *testing.T) {
+	s := New(Config{Slots: 1, ShedThreshold: 100 * time.Millisecond})
+	setWait(s, 50*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		if s.ShouldShed("key") {
+			t.Fatal("shed below the wait threshold")
+		}
+	}
+	if s.Shed() != 0 {
+		t.Errorf("Shed = %d, want 0", s.Shed())
+	}
+}
+
+func TestShedCertainAtTwiceThreshold(t *testing.T) {
+	s := New(Config{Slots: 1, ShedThreshold: 100 * time.Millisecond})
+	setWait(s, 300*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		if !s.ShouldShed("key") {
+			t.Fatal("admitted at triple the wait threshold")
+		}
+	}
+	if s.Shed() != 100 {
+		t.Errorf("Shed = %d, want 100", s.Shed())
+	}
+}
+
+func TestShedFavorsHighWeightTenants(t *testing.T) {
+	s := New(Config{
+		Slots:         1,
+		ShedThreshold: 100 * time.Millisecond,
+		Weights:       map[string]float64{"gold": 3},
+	})
+	// Halfway up the ramp: p=0.5 for weight 1, p=0.125 for weight 3
+	setWait(s, 150*time.Millisecond)
+
+	const trials = 2000
+	var basic, gold int
+	for i := 0; i < trials; i++ {
+		if s.ShouldShed("basic") {
+			basic++
+		}
+		if s.ShouldShed("gold") {
+			gold++
+		}
+	}
+	// Generous bounds around the expected 1000 and 250
+	if basic < 800 || basic > 1200 {
+		t.Errorf("basic shed %d/%d, want ~1000", basic, trials)
+	}
+	if gold < 100 || gold > 450 {
+		t.Errorf("gold shed %d/%d, want ~250", gold, trials)
+	}
+	if gold >= basic {
+		t.Errorf("gold shed (%d) not below basic shed (%d)", gold, basic)
+	}
+}
+
+func TestQueueWaitDecaysOnImmediateAdmissions(t *testing.T) {
+	s := New(Config{Slots: 4, ShedThreshold: 10 * time.Millisecond})
+	setWait(s, time.Second)
+	before := s.QueueWait()
+
+	// Uncontended admissions observe zero wait and pull the average down
+	for i := 0; i < 50; i++ {
+		if err := s.Acquire(context.Background(), "key"); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		s.Release()
+	}
+	if after := s.QueueWait(); after >= before {
+		t.Errorf("QueueWait did not decay: %v -> %v", before, after)
+	}
+	if s.ShouldShed("key") {
+		t.Error("still shedding after the estimate decayed")
+	}
+}
@@ -0,0 +1,179 @@
+// Package session stores conversation history server-side so clients
+// can reference a session_id instead of resending the full transcript
+// (and its screenshots) on every request.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxHistory bounds the number of messages kept per session
+// before trimming; the leading system message is always preserved
+const DefaultMaxHistory = 64
+
+// Message mirrors the API chat message shape without importing the api
+// package (which depends on this one)
+type Message struct {
+	Role    string
+	Content interface{}
+	// ToolCallID ties a role:"tool" result back to its call so full
+	// agent loops replay intact from history
+	ToolCallID string
+}
+
+// Session is one server-side conversation
+type Session struct {
+	ID      string
+	Model   string
+	History []Message
+
+	// PinnedHandle is the KV cache handle for the session's prefix,
+	// kept pinned so follow-up turns reuse it (0 = none yet)
+	PinnedHandle uint64
+
+	CreatedAt time.Time
+	LastUsed  time.Time
+}
+
+// Manager owns all active sessions
+type Manager struct {
+	mu         sync.RWMutex
+	sessions   map[string]*Session
+	maxHistory int
+
+	// summarizer, when set, condenses older turns once a session's
+	// estimated token count passes summarizeAt
+	summarizer  Summarizer
+	summarizeAt int
+
+	// store, when set, persists sessions across restarts
+	store Store
+}
+
+// NewManager creates a session manager.
+// maxHistory <= 0 uses DefaultMaxHistory.
+func NewManager(maxHistory int) *Manager {
+	if maxHistory <= 0 {
+		maxHistory = DefaultMaxHistory
+	}
+	return &Manager{
+		sessions:   make(map[string]*Session),
+		maxHistory: maxHistory,
+	}
+}
+
+// Create opens a new session for the given model
+func (m *Manager) Create(model string) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	s := &Session{
+		ID:        newSessionID(),
+		Model:     model,
+		CreatedAt: now,
+		LastUsed:  now,
+	}
+	m.sessions[s.ID] = s
+	m.save(s)
+	return s
+}
+
+// Get returns the session or an error if it does not exist
+func (m *Manager) Get(id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return s, nil
+}
+
+// Delete removes a session
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[id]; !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+	delete(m.sessions, id)
+	m.drop(id)
+	return nil
+}
+
+// Count returns the number of active sessions
+func (m *Manager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sessions)
+}
+
+// Append adds messages to the session history and trims it to the
+// configured limit, preserving a leading system message when present.
+// Returns the full history to use as the prompt.
+func (m *Manager) Append(id string, messages ...Message) ([]Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+
+	s.History = append(s.History, messages...)
+	m.maybeSummarize(s)
+	s.History = trimHistory(s.History, m.maxHistory)
+	s.LastUsed = time.Now()
+	m.save(s)
+
+	// Return a copy so callers don't race with later appends
+	out := make([]Message, len(s.History))
+	copy(out, s.History)
+	return out, nil
+}
+
+// SetPinnedHandle records the KV cache handle covering the session prefix
+func (m *Manager) SetPinnedHandle(id string, handle uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+	s.PinnedHandle = handle
+	return nil
+}
+
+// trimHistory drops the oldest non-system messages beyond the limit
+func trimHistory(history []Message, limit int) []Message {
+	if len(history) <= limit {
+		return history
+	}
+
+	if len(history) > 0 && history[0].Role == "system" {
+		// Keep the system message plus the most recent limit-1 entries
+		keep := history[len(history)-(limit-1):]
+		out := make([]Message, 0, limit)
+		out = append(out, history[0])
+		return append(out, keep...)
+	}
+
+	return history[len(history)-limit:]
+}
+
+func newSessionID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		// Extremely unlikely; fall back to a time-based ID
+		return fmt.Sprintf("sess-%d", time.Now().UnixNano())
+	}
+	return "sess-" + hex.EncodeToString(buf)
+}
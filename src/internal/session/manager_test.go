@@ -0,0 +1,142 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateGetDelete(t *testing.T) {
+	m := NewManager(0)
+
+	s := m.Create("gui-actor-2b")
+	if !strings.HasPrefix(s.ID, "sess-") {
+		t.Errorf("Unexpected session ID format: %s", s.ID)
+	}
+	if s.Model != "gui-actor-2b" {
+		t.Errorf("Model = %s, want gui-actor-2b", s.Model)
+	}
+
+	got, err := m.Get(s.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.ID != s.ID {
+		t.Errorf("Got wrong session %s", got.ID)
+	}
+	if m.Count() != 1 {
+		t.Errorf("Count = %d, want 1", m.Count())
+	}
+
+	if err := m.Delete(s.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := m.Get(s.ID); err == nil {
+		t.Error("Expected error after delete")
+	}
+	if err := m.Delete(s.ID); err == nil {
+		t.Error("Expected error deleting twice")
+	}
+}
+
+func TestGetUnknownSession(t *testing.T) {
+	m := NewManager(0)
+	if _, err := m.Get("sess-missing"); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
+
+func TestAppendAccumulatesHistory(t *testing.T) {
+	m := NewManager(0)
+	s := m.Create("gui-actor-2b")
+
+	history, err := m.Append(s.ID, Message{Role: "user", Content: "hello"})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("History length = %d, want 1", len(history))
+	}
+
+	history, err = m.Append(s.ID, Message{Role: "assistant", Content: "hi"})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("History length = %d, want 2", len(history))
+	}
+	if history[0].Role != "user" || history[1].Role != "assistant" {
+		t.Error("History order wrong")
+	}
+}
+
+func TestAppendUnknownSession(t *testing.T) {
+	m := NewManager(0)
+	if _, err := m.Append("sess-missing", Message{Role: "user", Content: "x"}); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
+
+func TestTrimPreservesSystemMessage(t *testing.T) {
+	m := NewManager(4)
+	s := m.Create("gui-actor-2b")
+
+	if _, err := m.Append(s.ID, Message{Role: "system", Content: "be helpful"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := m.Append(s.ID, Message{Role: "user", Content: i}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	history, err := m.Append(s.ID, Message{Role: "user", Content: "latest"})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	if len(history) != 4 {
+		t.Fatalf("History length = %d, want 4", len(history))
+	}
+	if history[0].Role != "system" {
+		t.Error("System message not preserved after trim")
+	}
+	if history[len(history)-1].Content != "latest" {
+		t.Error("Most recent message not preserved")
+	}
+}
+
+func TestTrimWithoutSystemMessage(t *testing.T) {
+	m := NewManager(3)
+	s := m.Create("gui-actor-2b")
+
+	for i := 0; i < 10; i++ {
+		if _, err := m.Append(s.ID, Message{Role: "user", Content: i}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	history, _ := m.Get(s.ID)
+	if len(history.History) != 3 {
+		t.Errorf("History length = %d, want 3", len(history.History))
+	}
+	if history.History[2].Content != 9 {
+		t.Error("Trim did not keep the newest messages")
+	}
+}
+
+func TestSetPinnedHandle(t *testing.T) {
+	m := NewManager(0)
+	s := m.Create("gui-actor-2b")
+
+	if err := m.SetPinnedHandle(s.ID, 42); err != nil {
+		t.Fatalf("SetPinnedHandle failed: %v", err)
+	}
+	got, _ := m.Get(s.ID)
+	if got.PinnedHandle != 42 {
+		t.Errorf("PinnedHandle = %d, want 42", got.PinnedHandle)
+	}
+
+	if err := m.SetPinnedHandle("sess-missing", 1); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
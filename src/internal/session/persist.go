@@ -0,0 +1,72 @@
+package session
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// Store is the persistence hook for sessions; satisfied by
+// persist.Store without this package depending on it
+type Store interface {
+	PutSession(id string, data []byte) error
+	DeleteSession(id string) error
+	EachSession(fn func(id string, data []byte) error) error
+}
+
+// SetStore persists sessions as they change. Writes are best-effort:
+// a failed save costs durability for that turn, not the request.
+func (m *Manager) SetStore(st Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = st
+}
+
+// Restore loads persisted sessions, returning how many were restored.
+// Pinned KV cache handles do not survive a restart, so they come back
+// zeroed and the next turn re-pins.
+func (m *Manager) Restore() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.store == nil {
+		return 0, nil
+	}
+
+	restored := 0
+	err := m.store.EachSession(func(id string, data []byte) error {
+		var s Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			log.Printf("skipping corrupt session %s: %v", id, err)
+			return nil
+		}
+		s.PinnedHandle = 0
+		m.sessions[s.ID] = &s
+		restored++
+		return nil
+	})
+	return restored, err
+}
+
+// save writes the session snapshot; callers hold m.mu
+func (m *Manager) save(s *Session) {
+	if m.store == nil {
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		log.Printf("failed to encode session %s: %v", s.ID, err)
+		return
+	}
+	if err := m.store.PutSession(s.ID, data); err != nil {
+		log.Printf("failed to persist session %s: %v", s.ID, err)
+	}
+}
+
+// drop removes the persisted snapshot; callers hold m.mu
+func (m *Manager) drop(id string) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.DeleteSession(id); err != nil {
+		log.Printf("failed to delete persisted session %s: %v", id, err)
+	}
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+// memStore is an in-memory Store for tests
+type memStore struct {
+	snapshots map[string][]byte
+	failPuts  bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{snapshots: make(map[string][]byte)}
+}
+
+func (m *memStore) PutSession(id string, data []byte) error {
+	if m.failPuts {
+		return errors.New("disk full")
+	}
+	m.snapshots[id] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memStore) DeleteSession(id string) error {
+	delete(m.snapshots, id)
+	return nil
+}
+
+func (m *memStore) EachSession(fn func(id string, data []byte) error) error {
+	for id, data := range m.snapshots {
+		if err := fn(id, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSessionsPersistAcrossManagers(t *testing.T) {
+	st := newMemStore()
+
+	m := NewManager(0)
+	m.SetStore(st)
+	s := m.Create("gui-actor-2b")
+	if _, err := m.Append(s.ID, Message{Role: "user", Content: "click save"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	m.SetPinnedHandle(s.ID, 42)
+
+	// A fresh manager stands in for a restarted server
+	m2 := NewManager(0)
+	m2.SetStore(st)
+	restored, err := m2.Restore()
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored != 1 {
+		t.Fatalf("restored %d sessions, want 1", restored)
+	}
+
+	got, err := m2.Get(s.ID)
+	if err != nil {
+		t.Fatalf("Get after restore: %v", err)
+	}
+	if got.Model != "gui-actor-2b" || len(got.History) != 1 {
+		t.Errorf("restored session = %+v, want model and one message back", got)
+	}
+	if got.PinnedHandle != 0 {
+		t.Errorf("PinnedHandle = %d, want 0 (KV cache does not survive restarts)", got.PinnedHandle)
+	}
+}
+
+func TestDeleteRemovesSnapshot(t *testing.T) {
+	st := newMemStore()
+	m := NewManager(0)
+	m.SetStore(st)
+
+	s := m.Create("gui-actor-2b")
+	if err := m.Delete(s.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(st.snapshots) != 0 {
+		t.Errorf("%d snapshots remain after delete, want 0", len(st.snapshots))
+	}
+}
+
+func TestRestoreSkipsCorruptSnapshots(t *testing.T) {
+	st := newMemStore()
+	st.snapshots["sess-bad"] = []byte("not json")
+
+	m := NewManager(0)
+	m.SetStore(st)
+	restored, err := m.Restore()
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored != 0 || m.Count() != 0 {
+		t.Errorf("restored %d sessions from corrupt data, want 0", restored)
+	}
+}
+
+func TestPersistFailureDoesNotFailRequest(t *testing.T) {
+	st := newMemStore()
+	st.failPuts = true
+
+	m := NewManager(0)
+	m.SetStore(st)
+	s := m.Create("gui-actor-2b")
+	history, err := m.Append(s.ID, Message{Role: "user", Content: "click save"})
+	if err != nil {
+		t.Fatalf("Append with failing store errored: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("history length = %d, want 1", len(history))
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+// Summarizer condenses older conversation turns into a short text,
+// typically by running the session's own model over them
+type Summarizer func(model string, turns []Message) (string, error)
+
+// keepRecentMessages is how many trailing messages survive a
+// summarization pass verbatim; everything older collapses into the
+// summary
+const keepRecentMessages = 4
+
+// SetSummarizer enables history summarization: once a session's
+// estimated token count exceeds thresholdTokens, older turns are
+// replaced by a generated summary instead of being dropped by plain
+// trimming. A threshold of zero disables it.
+//
+// The summarizer runs while the session lock is held, so appends to
+// other sessions wait on it; callers should keep it fast.
+func (m *Manager) SetSummarizer(fn Summarizer, thresholdTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summarizer = fn
+	m.summarizeAt = thresholdTokens
+}
+
+// maybeSummarize collapses older turns when the session has outgrown
+// the threshold. Failures leave the history untouched: plain trimming
+// still bounds it, just without preserving the old context.
+func (m *Manager) maybeSummarize(s *Session) {
+	if m.summarizer == nil || m.summarizeAt <= 0 {
+		return
+	}
+	if estimateHistoryTokens(s.History) <= m.summarizeAt {
+		return
+	}
+
+	start := 0
+	if len(s.History) > 0 && s.History[0].Role == "system" {
+		start = 1
+	}
+	if len(s.History)-start <= keepRecentMessages {
+		return
+	}
+
+	older := s.History[start : len(s.History)-keepRecentMessages]
+	summary, err := m.summarizer(s.Model, older)
+	if err != nil {
+		return
+	}
+
+	out := make([]Message, 0, start+1+keepRecentMessages)
+	out = append(out, s.History[:start]...)
+	out = append(out, Message{Role: "system", Content: "Summary of earlier turns: " + summary})
+	out = append(out, s.History[len(s.History)-keepRecentMessages:]...)
+	s.History = out
+
+	// The prompt prefix just changed, so the pinned KV handle no longer
+	// matches; the next turn re-pins against the fresh prefix
+	s.PinnedHandle = 0
+}
+
+// estimateHistoryTokens approximates the history's token count from
+// its text content at ~4 chars/token; screenshots are referenced by
+// session, not resent, so text dominates here
+func estimateHistoryTokens(history []Message) int {
+	chars := 0
+	for _, msg := range history {
+		if text, ok := msg.Content.(string); ok {
+			chars += len(text)
+		}
+	}
+	return (chars + 3) / 4
+}
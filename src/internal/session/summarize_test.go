@@ -0,0 +1,105 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fill appends count user/assistant turn pairs of ~40 chars each
+func fill(t *testing.T, m *Manager, id string, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		_, err := m.Append(id,
+			Message{Role: "user", Content: fmt.Sprintf("click the button labelled step %d", i)},
+			Message{Role: "assistant", Content: fmt.Sprintf("pyautogui.click(0.%d, 0.5)", i%10)},
+		)
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+}
+
+func TestSummarizeCollapsesOldTurns(t *testing.T) {
+	m := NewManager(0)
+	m.SetSummarizer(func(model string, turns []Message) (string, error) {
+		return fmt.Sprintf("%d turns on %s", len(turns), model), nil
+	}, 50)
+
+	s := m.Create("gui-actor-2b")
+	if _, err := m.Append(s.ID, Message{Role: "system", Content: "be helpful"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetPinnedHandle(s.ID, 42); err != nil {
+		t.Fatal(err)
+	}
+	fill(t, m, s.ID, 10)
+
+	got, _ := m.Get(s.ID)
+	// system + summary + the last keepRecentMessages verbatim
+	if len(got.History) != 2+keepRecentMessages {
+		t.Fatalf("history length = %d, want %d", len(got.History), 2+keepRecentMessages)
+	}
+	if got.History[0].Role != "system" || got.History[0].Content != "be helpful" {
+		t.Error("leading system message not preserved")
+	}
+	summary, ok := got.History[1].Content.(string)
+	if !ok || !strings.Contains(summary, "on gui-actor-2b") {
+		t.Errorf("summary = %v, want the summarizer's output for the session model", got.History[1].Content)
+	}
+	if !strings.HasPrefix(summary, "Summary of earlier turns: ") {
+		t.Errorf("summary message not labelled: %q", summary)
+	}
+	// The prefix changed, so the pinned KV handle must be dropped
+	if got.PinnedHandle != 0 {
+		t.Errorf("PinnedHandle = %d, want 0 after summarization", got.PinnedHandle)
+	}
+}
+
+func TestSummarizeBelowThresholdUntouched(t *testing.T) {
+	m := NewManager(0)
+	m.SetSummarizer(func(string, []Message) (string, error) {
+		t.Error("summarizer ran below the threshold")
+		return "", nil
+	}, 1000000)
+
+	s := m.Create("gui-actor-2b")
+	fill(t, m, s.ID, 5)
+
+	got, _ := m.Get(s.ID)
+	if len(got.History) != 10 {
+		t.Errorf("history length = %d, want 10", len(got.History))
+	}
+}
+
+func TestSummarizerErrorFallsBackToTrimming(t *testing.T) {
+	m := NewManager(6)
+	m.SetSummarizer(func(string, []Message) (string, error) {
+		return "", errors.New("model busy")
+	}, 50)
+
+	s := m.Create("gui-actor-2b")
+	fill(t, m, s.ID, 10)
+
+	got, _ := m.Get(s.ID)
+	if len(got.History) != 6 {
+		t.Errorf("history length = %d, want the trim limit 6", len(got.History))
+	}
+	for _, msg := range got.History {
+		if text, ok := msg.Content.(string); ok && strings.HasPrefix(text, "Summary") {
+			t.Error("failed summarization still injected a summary")
+		}
+	}
+}
+
+func TestSummarizeDisabledByZeroThreshold(t *testing.T) {
+	m := NewManager(0)
+	m.SetSummarizer(func(string, []Message) (string, error) {
+		t.Error("summarizer ran while disabled")
+		return "", nil
+	}, 0)
+
+	s := m.Create("gui-actor-2b")
+	fill(t, m, s.ID, 10)
+}
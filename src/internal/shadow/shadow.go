@@ -0,0 +1,152 @@
+// Package shadow compares primary completions against a secondary
+// model fed the same requests. Divergence metrics make quantization or
+// checkpoint regressions visible on live traffic before a variant is
+// promoted, without the shadow output ever reaching clients.
+package shadow
+
+import (
+	"math"
+	"sync"
+)
+
+// DefaultCapacity bounds the retained comparison ring when the caller
+// does not choose one
+const DefaultCapacity = 128
+
+// Comparison is one primary-vs-shadow divergence sample
+type Comparison struct {
+	Timestamp      int64  `json:"timestamp"`
+	PrimaryModel   string `json:"primary_model"`
+	ShadowModel    string `json:"shadow_model"`
+	PrimaryContent string `json:"primary_content"`
+	ShadowContent  string `json:"shadow_content"`
+	// CoordDistance is the euclidean distance between the predicted
+	// points in normalized screen space; -1 when either side had none
+	CoordDistance float64 `json:"coord_distance"`
+	// EditDistance is the token-level Levenshtein distance between the
+	// generated texts
+	EditDistance int `json:"edit_distance"`
+}
+
+// Stats aggregates all comparisons seen so far
+type Stats struct {
+	Comparisons       int64   `json:"comparisons"`
+	MeanCoordDistance float64 `json:"mean_coord_distance"`
+	MeanEditDistance  float64 `json:"mean_edit_distance"`
+}
+
+// Log keeps the most recent comparisons and running divergence means
+type Log struct {
+	mu      sync.Mutex
+	entries []Comparison
+	next    int
+	full    bool
+
+	count     int64
+	coordSum  float64
+	coordSeen int64
+	editSum   int64
+}
+
+// NewLog creates a comparison log retaining the last capacity entries
+func NewLog(capacity int) *Log {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Log{entries: make([]Comparison, capacity)}
+}
+
+// Record adds one comparison
+func (l *Log) Record(c Comparison) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[l.next] = c
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+
+	l.count++
+	if c.CoordDistance >= 0 {
+		l.coordSum += c.CoordDistance
+		l.coordSeen++
+	}
+	l.editSum += int64(c.EditDistance)
+}
+
+// Recent returns the retained comparisons, oldest first
+func (l *Log) Recent() []Comparison {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.full {
+		out := make([]Comparison, l.next)
+		copy(out, l.entries[:l.next])
+		return out
+	}
+	out := make([]Comparison, 0, len(l.entries))
+	out = append(out, l.entries[l.next:]...)
+	return append(out, l.entries[:l.next]...)
+}
+
+// Stats returns the running divergence aggregates
+func (l *Log) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := Stats{Comparisons: l.count}
+	if l.coordSeen > 0 {
+		s.MeanCoordDistance = l.coordSum / float64(l.coordSeen)
+	}
+	if l.count > 0 {
+		s.MeanEditDistance = float64(l.editSum) / float64(l.count)
+	}
+	return s
+}
+
+// CoordDistance returns the euclidean distance between two normalized
+// points
+func CoordDistance(ax, ay, bx, by float64) float64 {
+	return math.Hypot(ax-bx, ay-by)
+}
+
+// EditDistance returns the Levenshtein distance between the rune
+// sequences of a and b
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,82 @@
+package shadow
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"identical", "pyautogui.click(0.5, 0.5)", "pyautogui.click(0.5, 0.5)", 0},
+		{"empty vs text", "", "abc", 3},
+		{"text vs empty", "abc", "", 3},
+		{"substitution", "click", "clack", 1},
+		{"insert and delete", "pyautogui.click(0.5, 0.5)", "pyautogui.click(0.52, 0.5)", 1},
+		{"unicode runes", "héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoordDistance(t *testing.T) {
+	if got := CoordDistance(0.5, 0.5, 0.5, 0.5); got != 0 {
+		t.Errorf("distance of identical points = %f", got)
+	}
+	if got := CoordDistance(0, 0, 0.3, 0.4); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("CoordDistance = %f, want 0.5", got)
+	}
+}
+
+func TestLogStats(t *testing.T) {
+	l := NewLog(8)
+	l.Record(Comparison{CoordDistance: 0.2, EditDistance: 4})
+	l.Record(Comparison{CoordDistance: 0.4, EditDistance: 2})
+	l.Record(Comparison{CoordDistance: -1, EditDistance: 0}) // no coords
+
+	s := l.Stats()
+	if s.Comparisons != 3 {
+		t.Errorf("Comparisons = %d, want 3", s.Comparisons)
+	}
+	if math.Abs(s.MeanCoordDistance-0.3) > 1e-9 {
+		t.Errorf("MeanCoordDistance = %f, want 0.3 (coordless samples excluded)", s.MeanCoordDistance)
+	}
+	if math.Abs(s.MeanEditDistance-2) > 1e-9 {
+		t.Errorf("MeanEditDistance = %f, want 2", s.MeanEditDistance)
+	}
+}
+
+func TestLogRecentRingOrder(t *testing.T) {
+	l := NewLog(3)
+	for i := 0; i < 5; i++ {
+		l.Record(Comparison{EditDistance: i})
+	}
+
+	recent := l.Recent()
+	if len(recent) != 3 {
+		t.Fatalf("got %d entries, want 3", len(recent))
+	}
+	for i, c := range recent {
+		if c.EditDistance != i+2 {
+			t.Errorf("entry %d has EditDistance %d, want %d (oldest first)", i, c.EditDistance, i+2)
+		}
+	}
+}
+
+func TestLogRecentPartiallyFilled(t *testing.T) {
+	l := NewLog(8)
+	l.Record(Comparison{EditDistance: 7})
+
+	recent := l.Recent()
+	if len(recent) != 1 || recent[0].EditDistance != 7 {
+		t.Errorf("Recent() = %+v, want the single recorded entry", recent)
+	}
+}
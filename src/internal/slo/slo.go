@@ -0,0 +1,214 @@
+// Package slo evaluates latency and error-rate service-level
+// objectives over sliding windows, counting breaches and notifying a
+// webhook so operators notice degradation before users do.
+package slo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the sliding evaluation window when unconfigured
+const DefaultWindow = 5 * time.Minute
+
+// minSamples gates evaluation so a single slow request at startup
+// cannot trip an alert
+const minSamples = 5
+
+// Config sets the thresholds; zero values disable the corresponding
+// objective
+type Config struct {
+	// Window is the sliding evaluation window
+	Window time.Duration
+	// TTFT is the p95 time-to-first-token objective
+	TTFT time.Duration
+	// InterToken is the p95 inter-token latency objective
+	InterToken time.Duration
+	// MaxErrorRate is the tolerated fraction of failed requests (0..1)
+	MaxErrorRate float64
+}
+
+// Sample is one completed (or failed) request
+type Sample struct {
+	TTFT       time.Duration
+	InterToken time.Duration
+	Err        bool
+	at         time.Time
+}
+
+// Breach describes one objective crossing its threshold
+type Breach struct {
+	Metric    string        `json:"metric"`
+	Value     float64       `json:"value"`
+	Threshold float64       `json:"threshold"`
+	At        time.Time     `json:"at"`
+	Window    time.Duration `json:"-"`
+}
+
+// Status is the snapshot served from /metrics
+type Status struct {
+	Samples            int     `json:"samples"`
+	WindowSeconds      int     `json:"window_seconds"`
+	TTFTP95Millis      float64 `json:"ttft_p95_ms"`
+	InterTokenP95      float64 `json:"inter_token_p95_ms"`
+	ErrorRate          float64 `json:"error_rate"`
+	TTFTBreaches       int     `json:"ttft_breaches"`
+	InterTokenBreaches int     `json:"inter_token_breaches"`
+	ErrorRateBreaches  int     `json:"error_rate_breaches"`
+}
+
+// Monitor keeps the sample window and breach counters
+type Monitor struct {
+	cfg    Config
+	notify func(Breach)
+
+	mu       sync.Mutex
+	samples  []Sample
+	breaches map[string]int
+	// breached tracks which objectives are currently violated, so the
+	// notifier fires on transitions instead of every sample
+	breached map[string]bool
+	now      func() time.Time
+}
+
+// NewMonitor creates a monitor with the window default applied
+func NewMonitor(cfg Config) *Monitor {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	return &Monitor{
+		cfg:      cfg,
+		breaches: make(map[string]int),
+		breached: make(map[string]bool),
+		now:      time.Now,
+	}
+}
+
+// SetNotifier registers a breach callback, invoked once per transition
+// into breach
+func (m *Monitor) SetNotifier(fn func(Breach)) {
+	m.notify = fn
+}
+
+// Record adds a sample and re-evaluates every objective
+func (m *Monitor) Record(s Sample) {
+	m.mu.Lock()
+	s.at = m.now()
+	m.samples = append(m.samples, s)
+	m.evict(s.at)
+	breaches := m.evaluate(s.at)
+	m.mu.Unlock()
+
+	for _, b := range breaches {
+		if m.notify != nil {
+			m.notify(b)
+		}
+	}
+}
+
+// evict drops samples older than the window. Caller holds the lock.
+func (m *Monitor) evict(now time.Time) {
+	cutoff := now.Add(-m.cfg.Window)
+	i := 0
+	for i < len(m.samples) && m.samples[i].at.Before(cutoff) {
+		i++
+	}
+	m.samples = m.samples[i:]
+}
+
+// evaluate checks each objective, counting and returning new breaches.
+// Caller holds the lock.
+func (m *Monitor) evaluate(now time.Time) []Breach {
+	if len(m.samples) < minSamples {
+		return nil
+	}
+
+	var breaches []Breach
+	check := func(metric string, value, threshold float64) {
+		if threshold <= 0 {
+			return
+		}
+		if value > threshold {
+			if !m.breached[metric] {
+				m.breached[metric] = true
+				m.breaches[metric]++
+				breaches = append(breaches, Breach{
+					Metric: metric, Value: value, Threshold: threshold,
+					At: now, Window: m.cfg.Window,
+				})
+			}
+		} else {
+			m.breached[metric] = false
+		}
+	}
+
+	check("ttft_p95_ms", m.p95TTFT(), float64(m.cfg.TTFT.Milliseconds()))
+	check("inter_token_p95_ms", m.p95InterToken(), float64(m.cfg.InterToken.Milliseconds()))
+	check("error_rate", m.errorRate(), m.cfg.MaxErrorRate)
+	return breaches
+}
+
+// p95 returns the 95th percentile of the values in milliseconds
+func p95(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	idx := (len(values)*95 + 99) / 100
+	if idx > 0 {
+		idx--
+	}
+	return values[idx]
+}
+
+func (m *Monitor) p95TTFT() float64 {
+	values := make([]float64, 0, len(m.samples))
+	for _, s := range m.samples {
+		if !s.Err {
+			values = append(values, float64(s.TTFT.Milliseconds()))
+		}
+	}
+	return p95(values)
+}
+
+func (m *Monitor) p95InterToken() float64 {
+	values := make([]float64, 0, len(m.samples))
+	for _, s := range m.samples {
+		if !s.Err {
+			values = append(values, float64(s.InterToken.Milliseconds()))
+		}
+	}
+	return p95(values)
+}
+
+func (m *Monitor) errorRate() float64 {
+	errs := 0
+	for _, s := range m.samples {
+		if s.Err {
+			errs++
+		}
+	}
+	return float64(errs) / float64(len(m.samples))
+}
+
+// Status snapshots the window for /metrics
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evict(m.now())
+
+	st := Status{
+		Samples:            len(m.samples),
+		WindowSeconds:      int(m.cfg.Window.Seconds()),
+		TTFTBreaches:       m.breaches["ttft_p95_ms"],
+		InterTokenBreaches: m.breaches["inter_token_p95_ms"],
+		ErrorRateBreaches:  m.breaches["error_rate"],
+	}
+	if len(m.samples) > 0 {
+		st.TTFTP95Millis = m.p95TTFT()
+		st.InterTokenP95 = m.p95InterToken()
+		st.ErrorRate = m.errorRate()
+	}
+	return st
+}
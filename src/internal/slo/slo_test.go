@@ -0,0 +1,125 @@
+package slo
+
+import (
+	"testing"
+	"time"
+)
+
+// fill records n healthy samples with the given TTFT
+func fill(m *Monitor, n int, ttft time.Duration) {
+	for i := 0; i < n; i++ {
+		m.Record(Sample{TTFT: ttft})
+	}
+}
+
+func TestNoBreachBelowThreshold(t *testing.T) {
+	m := NewMonitor(Config{TTFT: 500 * time.Millisecond})
+	fill(m, 10, 100*time.Millisecond)
+
+	st := m.Status()
+	if st.TTFTBreaches != 0 {
+		t.Errorf("TTFTBreaches = %d, want 0", st.TTFTBreaches)
+	}
+	if st.Samples != 10 {
+		t.Errorf("Samples = %d, want 10", st.Samples)
+	}
+	if st.TTFTP95Millis != 100 {
+		t.Errorf("TTFTP95Millis = %f, want 100", st.TTFTP95Millis)
+	}
+}
+
+func TestTTFTBreachCountedOncePerTransition(t *testing.T) {
+	m := NewMonitor(Config{TTFT: 200 * time.Millisecond})
+
+	fill(m, 10, time.Second)
+	if st := m.Status(); st.TTFTBreaches != 1 {
+		t.Errorf("TTFTBreaches = %d, want 1 after a sustained breach", st.TTFTBreaches)
+	}
+
+	// Recovery, then a second breach, counts again
+	fill(m, 200, 50*time.Millisecond)
+	fill(m, 200, time.Second)
+	if st := m.Status(); st.TTFTBreaches != 2 {
+		t.Errorf("TTFTBreaches = %d, want 2 after recovery and re-breach", st.TTFTBreaches)
+	}
+}
+
+func TestMinSamplesGate(t *testing.T) {
+	m := NewMonitor(Config{TTFT: 10 * time.Millisecond})
+	fill(m, minSamples-1, time.Second)
+	if st := m.Status(); st.TTFTBreaches != 0 {
+		t.Errorf("TTFTBreaches = %d, want 0 below the sample floor", st.TTFTBreaches)
+	}
+}
+
+func TestErrorRateBreach(t *testing.T) {
+	m := NewMonitor(Config{MaxErrorRate: 0.2})
+
+	for i := 0; i < 6; i++ {
+		m.Record(Sample{TTFT: time.Millisecond})
+	}
+	for i := 0; i < 4; i++ {
+		m.Record(Sample{Err: true})
+	}
+
+	st := m.Status()
+	if st.ErrorRate != 0.4 {
+		t.Errorf("ErrorRate = %f, want 0.4", st.ErrorRate)
+	}
+	if st.ErrorRateBreaches != 1 {
+		t.Errorf("ErrorRateBreaches = %d, want 1", st.ErrorRateBreaches)
+	}
+}
+
+func TestInterTokenBreach(t *testing.T) {
+	m := NewMonitor(Config{InterToken: 50 * time.Millisecond})
+	for i := 0; i < 10; i++ {
+		m.Record(Sample{InterToken: 200 * time.Millisecond})
+	}
+	if st := m.Status(); st.InterTokenBreaches != 1 {
+		t.Errorf("InterTokenBreaches = %d, want 1", st.InterTokenBreaches)
+	}
+}
+
+func TestWindowEviction(t *testing.T) {
+	m := NewMonitor(Config{Window: time.Minute, TTFT: 100 * time.Millisecond})
+	clock := time.Now()
+	m.now = func() time.Time { return clock }
+
+	fill(m, 10, time.Second)
+	if st := m.Status(); st.Samples != 10 {
+		t.Fatalf("Samples = %d, want 10", st.Samples)
+	}
+
+	// Everything ages out of the window
+	clock = clock.Add(2 * time.Minute)
+	if st := m.Status(); st.Samples != 0 {
+		t.Errorf("Samples after window = %d, want 0", st.Samples)
+	}
+}
+
+func TestNotifierFiresOnTransition(t *testing.T) {
+	m := NewMonitor(Config{TTFT: 100 * time.Millisecond})
+	var breaches []Breach
+	m.SetNotifier(func(b Breach) { breaches = append(breaches, b) })
+
+	fill(m, 10, time.Second)
+	if len(breaches) != 1 {
+		t.Fatalf("notified %d times, want 1", len(breaches))
+	}
+	b := breaches[0]
+	if b.Metric != "ttft_p95_ms" || b.Threshold != 100 || b.Value != 1000 {
+		t.Errorf("breach = %+v, want ttft_p95_ms 1000 > 100", b)
+	}
+}
+
+func TestDisabledObjectivesNeverBreach(t *testing.T) {
+	m := NewMonitor(Config{})
+	for i := 0; i < 10; i++ {
+		m.Record(Sample{TTFT: time.Hour, Err: true})
+	}
+	st := m.Status()
+	if st.TTFTBreaches+st.InterTokenBreaches+st.ErrorRateBreaches != 0 {
+		t.Errorf("breaches counted with no thresholds configured: %+v", st)
+	}
+}
@@ -0,0 +1,37 @@
+package slo
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds the notification POST so a dead receiver
+// cannot back up the request path
+const webhookTimeout = 5 * time.Second
+
+// NewWebhookNotifier returns a notifier that POSTs each breach as JSON
+// to url. Delivery is fire-and-forget: failures are logged, never
+// retried, and never block the caller.
+func NewWebhookNotifier(url string) func(Breach) {
+	client := &http.Client{Timeout: webhookTimeout}
+	return func(b Breach) {
+		go func() {
+			body, err := json.Marshal(b)
+			if err != nil {
+				return
+			}
+			resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				log.Printf("Warning: SLO webhook delivery failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				log.Printf("Warning: SLO webhook returned %d", resp.StatusCode)
+			}
+		}()
+	}
+}
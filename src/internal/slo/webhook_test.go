@@ -0,0 +1,40 @@
+package slo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierDelivers(t *testing.T) {
+	received := make(chan Breach, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b Breach
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			t.Errorf("bad webhook payload: %v", err)
+		}
+		received <- b
+	}))
+	defer srv.Close()
+
+	notify := NewWebhookNotifier(srv.URL)
+	notify(Breach{Metric: "ttft_p95_ms", Value: 900, Threshold: 500, At: time.Now()})
+
+	select {
+	case b := <-received:
+		if b.Metric != "ttft_p95_ms" || b.Value != 900 || b.Threshold != 500 {
+			t.Errorf("delivered breach = %+v", b)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestWebhookNotifierToleratesDeadReceiver(t *testing.T) {
+	notify := NewWebhookNotifier("http://127.0.0.1:1/unreachable")
+	// Must not block or panic
+	notify(Breach{Metric: "error_rate", Value: 1})
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,124 @@
+// Package templates loads prompt presets from a directory of YAML
+// files — one preset per file, named after it — and watches the
+// directory so edits apply without a server restart. Prompt tuning is
+// iterative; a redeploy per wording tweak makes it painful.
+package templates
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+// DefaultPollInterval is how often the directory is checked for edits
+const DefaultPollInterval = 2 * time.Second
+
+// Load reads every *.yaml / *.yml file in dir as one preset keyed by
+// the file name without extension
+func Load(dir string) (map[string]config.PresetConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates dir: %w", err)
+	}
+
+	presets := make(map[string]config.PresetConfig)
+	for _, entry := range entries {
+		name, ok := presetName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var preset config.PresetConfig
+		if err := yaml.Unmarshal(data, &preset); err != nil {
+			return nil, fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+		if preset.SystemPrompt == "" && preset.InstructionTemplate == "" {
+			return nil, fmt.Errorf("template %s: defines neither system_prompt nor instruction_template", entry.Name())
+		}
+		presets[name] = preset
+	}
+	return presets, nil
+}
+
+// presetName maps a template file name to its preset name
+func presetName(file string) (string, bool) {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if strings.HasSuffix(file, ext) {
+			return strings.TrimSuffix(file, ext), true
+		}
+	}
+	return "", false
+}
+
+// Watch polls dir and calls onChange with the freshly loaded presets
+// whenever a template file is added, edited, or removed. A load error
+// after a change is logged and the previous presets stay in effect.
+// The returned function stops the watcher.
+func Watch(dir string, interval time.Duration, onChange func(map[string]config.PresetConfig)) func() {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	stop := make(chan struct{})
+	// Snapshot the baseline before returning so edits made right after
+	// Watch are never mistaken for the starting state
+	last := fingerprint(dir)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := fingerprint(dir)
+				if current == last {
+					continue
+				}
+				last = current
+				presets, err := Load(dir)
+				if err != nil {
+					log.Printf("template reload failed, keeping previous presets: %v", err)
+					continue
+				}
+				onChange(presets)
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// fingerprint summarizes the template files' names, sizes, and mtimes;
+// any edit changes it
+func fingerprint(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "unreadable: " + err.Error()
+	}
+
+	var parts []string
+	for _, entry := range entries {
+		if _, ok := presetName(entry.Name()); !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
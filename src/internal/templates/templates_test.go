@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agenthands/mlxvllm/internal/config"
+)
+
+func writeTemplate(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+}
+
+func TestLoadReadsPresetsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "osworld.yaml", "system_prompt: You are a GUI agent.\n")
+	writeTemplate(t, dir, "grounding.yml",
+		"system_prompt: Point to the element.\ninstruction_template: \"Where is: {instruction}\"\n")
+	writeTemplate(t, dir, "README.md", "not a template")
+
+	presets, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(presets) != 2 {
+		t.Fatalf("loaded %d presets, want 2: %v", len(presets), presets)
+	}
+	if presets["osworld"].SystemPrompt != "You are a GUI agent." {
+		t.Errorf("osworld = %+v", presets["osworld"])
+	}
+	if presets["grounding"].InstructionTemplate != "Where is: {instruction}" {
+		t.Errorf("grounding = %+v", presets["grounding"])
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"invalid yaml", "bad.yaml", "system_prompt: [unclosed\n"},
+		{"empty preset", "empty.yaml", "# nothing here\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, tt.file, tt.content)
+			if _, err := Load(dir); err == nil {
+				t.Errorf("Load accepted %s", tt.file)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load accepted a missing directory")
+	}
+}
+
+func TestWatchReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "agent.yaml", "system_prompt: v1\n")
+
+	reloaded := make(chan map[string]config.PresetConfig, 4)
+	stop := Watch(dir, 5*time.Millisecond, func(presets map[string]config.PresetConfig) {
+		reloaded <- presets
+	})
+	defer stop()
+
+	writeTemplate(t, dir, "agent.yaml", "system_prompt: v2\n")
+
+	select {
+	case presets := <-reloaded:
+		if presets["agent"].SystemPrompt != "v2" {
+			t.Errorf("reloaded preset = %+v, want v2", presets["agent"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher never saw the edit")
+	}
+}
+
+func TestWatchKeepsOldPresetsOnBadEdit(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "agent.yaml", "system_prompt: v1\n")
+
+	reloaded := make(chan map[string]config.PresetConfig, 4)
+	stop := Watch(dir, 5*time.Millisecond, func(presets map[string]config.PresetConfig) {
+		reloaded <- presets
+	})
+	defer stop()
+
+	// A broken edit must not reach the callback; the fix after it must
+	writeTemplate(t, dir, "agent.yaml", "system_prompt: [unclosed\n")
+	time.Sleep(50 * time.Millisecond)
+	writeTemplate(t, dir, "agent.yaml", "system_prompt: fixed\n")
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case presets := <-reloaded:
+			if got := presets["agent"].SystemPrompt; got == "fixed" {
+				return
+			} else if got != "v1" {
+				t.Fatalf("callback saw unexpected preset %q", got)
+			}
+		case <-deadline:
+			t.Fatal("watcher never recovered from the bad edit")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package trace propagates request correlation identifiers. The agent
+// controller sends `traceparent` (W3C Trace Context) and/or
+// `X-Request-ID`; carrying them through logs and outbound calls lets a
+// single interaction be followed across the controller, the router,
+// and the inference server.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// TraceparentHeader is the W3C Trace Context header
+const TraceparentHeader = "traceparent"
+
+// RequestIDHeader carries the per-request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// ctxKey keeps trace values private to this package
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	traceparentKey
+)
+
+// NewRequestID generates a fresh 16-byte hex correlation ID
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "00000000000000000000000000000000"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// WithRequestID stores a request ID in the context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID from the context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTraceparent stores a traceparent in the context
+func WithTraceparent(ctx context.Context, tp string) context.Context {
+	return context.WithValue(ctx, traceparentKey, tp)
+}
+
+// Traceparent returns the traceparent from the context, or ""
+func Traceparent(ctx context.Context) string {
+	tp, _ := ctx.Value(traceparentKey).(string)
+	return tp
+}
+
+// Ensure returns the request with a correlation ID attached: the
+// incoming X-Request-ID when present, otherwise a fresh one. The ID
+// and any traceparent are stored in the context, and the header is set
+// so proxies forward it unchanged.
+func Ensure(r *http.Request) *http.Request {
+	id := r.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = NewRequestID()
+		r.Header.Set(RequestIDHeader, id)
+	}
+
+	ctx := WithRequestID(r.Context(), id)
+	if tp := r.Header.Get(TraceparentHeader); tp != "" {
+		ctx = WithTraceparent(ctx, tp)
+	}
+	return r.WithContext(ctx)
+}
+
+// Middleware attaches correlation IDs to every request and echoes the
+// request ID in the response so clients can quote it
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = Ensure(r)
+		w.Header().Set(RequestIDHeader, RequestID(r.Context()))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Inject copies the context's trace identifiers onto an outbound
+// request's headers (model downloads, image fetches, proxied calls)
+func Inject(ctx context.Context, h http.Header) {
+	if id := RequestID(ctx); id != "" {
+		h.Set(RequestIDHeader, id)
+	}
+	if tp := Traceparent(ctx); tp != "" {
+		h.Set(TraceparentHeader, tp)
+	}
+}
@@ -0,0 +1,83 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRequestIDUnique(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	if len(a) != 32 {
+		t.Errorf("Request ID length = %d, want 32", len(a))
+	}
+	if a == b {
+		t.Error("Consecutive request IDs collided")
+	}
+}
+
+func TestEnsurePreservesIncomingID(t *testing.T) {
+	r := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	r.Header.Set(RequestIDHeader, "controller-id-1")
+	r.Header.Set(TraceparentHeader, "00-abc-def-01")
+
+	r = Ensure(r)
+	if got := RequestID(r.Context()); got != "controller-id-1" {
+		t.Errorf("RequestID = %q, want controller-id-1", got)
+	}
+	if got := Traceparent(r.Context()); got != "00-abc-def-01" {
+		t.Errorf("Traceparent = %q, want 00-abc-def-01", got)
+	}
+}
+
+func TestEnsureGeneratesID(t *testing.T) {
+	r := httptest.NewRequest("GET", "/v1/models", nil)
+	r = Ensure(r)
+
+	id := RequestID(r.Context())
+	if id == "" {
+		t.Fatal("Ensure did not generate a request ID")
+	}
+	// The header is set too so proxies forward it
+	if got := r.Header.Get(RequestIDHeader); got != id {
+		t.Errorf("Header ID = %q, context ID = %q; want equal", got, id)
+	}
+}
+
+func TestMiddlewareEchoesID(t *testing.T) {
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	r := httptest.NewRequest("GET", "/v1/health", nil)
+	r.Header.Set(RequestIDHeader, "abc123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if seen != "abc123" {
+		t.Errorf("Handler saw request ID %q, want abc123", seen)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "abc123" {
+		t.Errorf("Response header = %q, want abc123", got)
+	}
+}
+
+func TestInject(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "rid-1")
+	ctx = WithTraceparent(ctx, "00-abc-def-01")
+
+	h := make(http.Header)
+	Inject(ctx, h)
+	if h.Get(RequestIDHeader) != "rid-1" || h.Get(TraceparentHeader) != "00-abc-def-01" {
+		t.Errorf("Injected headers = %v, want both trace headers", h)
+	}
+
+	// An empty context injects nothing
+	empty := make(http.Header)
+	Inject(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Errorf("Inject on empty context set %v", empty)
+	}
+}
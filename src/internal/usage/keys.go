@@ -0,0 +1,122 @@
+package usage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var keysBucket = []byte("keys")
+
+// APIKey is a server-managed credential; revoked keys are kept so their
+// usage history stays attributable
+type APIKey struct {
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	Admin     bool   `json:"admin"`
+	Created   int64  `json:"created"`
+	Revoked   bool   `json:"revoked"`
+	RevokedAt int64  `json:"revoked_at,omitempty"`
+}
+
+// CreateKey generates and persists a new API key
+func (s *Store) CreateKey(name string, admin bool) (APIKey, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return APIKey{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key := APIKey{
+		Key:     "sk-" + hex.EncodeToString(raw),
+		Name:    name,
+		Admin:   admin,
+		Created: time.Now().Unix(),
+	}
+	if err := s.putKey(key); err != nil {
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+// GetKey returns the stored record for a key, if one exists
+func (s *Store) GetKey(key string) (APIKey, bool, error) {
+	var record APIKey
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(keysBucket)
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("corrupt key record for %s: %w", key, err)
+		}
+		found = true
+		return nil
+	})
+	return record, found, err
+}
+
+// ListAPIKeys returns every managed key, including revoked ones
+func (s *Store) ListAPIKeys() ([]APIKey, error) {
+	var keys []APIKey
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(keysBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var record APIKey
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("corrupt key record for %s: %w", k, err)
+			}
+			keys = append(keys, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeKey marks a key revoked; requests authenticated with it are
+// rejected from then on
+func (s *Store) RevokeKey(key string) error {
+	record, found, err := s.GetKey(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("unknown api key")
+	}
+	if record.Revoked {
+		return nil
+	}
+
+	record.Revoked = true
+	record.RevokedAt = time.Now().Unix()
+	return s.putKey(record)
+}
+
+func (s *Store) putKey(record APIKey) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(keysBucket)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(record.Key), data)
+	})
+}
@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateAndGetKey(t *testing.T) {
+	s := openTestStore(t)
+
+	key, err := s.CreateKey("alice", false)
+	if err != nil {
+		t.Fatalf("CreateKey failed: %v", err)
+	}
+	if !strings.HasPrefix(key.Key, "sk-") || len(key.Key) != 35 {
+		t.Errorf("key = %q, want sk- prefix with 32 hex chars", key.Key)
+	}
+	if key.Created == 0 {
+		t.Error("key has no creation timestamp")
+	}
+
+	got, found, err := s.GetKey(key.Key)
+	if err != nil || !found {
+		t.Fatalf("GetKey = (found=%v, err=%v), want found", found, err)
+	}
+	if got.Name != "alice" || got.Admin || got.Revoked {
+		t.Errorf("GetKey = %+v, want name alice, non-admin, not revoked", got)
+	}
+}
+
+func TestCreateKeyUnique(t *testing.T) {
+	s := openTestStore(t)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		key, err := s.CreateKey("bob", false)
+		if err != nil {
+			t.Fatalf("CreateKey failed: %v", err)
+		}
+		if seen[key.Key] {
+			t.Fatalf("CreateKey returned duplicate key %q", key.Key)
+		}
+		seen[key.Key] = true
+	}
+}
+
+func TestGetKeyUnknown(t *testing.T) {
+	s := openTestStore(t)
+
+	_, found, err := s.GetKey("sk-nonexistent")
+	if err != nil {
+		t.Fatalf("GetKey failed: %v", err)
+	}
+	if found {
+		t.Error("GetKey found an unknown key")
+	}
+}
+
+func TestListAPIKeys(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.CreateKey("alice", true); err != nil {
+		t.Fatalf("CreateKey failed: %v", err)
+	}
+	if _, err := s.CreateKey("bob", false); err != nil {
+		t.Fatalf("CreateKey failed: %v", err)
+	}
+
+	keys, err := s.ListAPIKeys()
+	if err != nil {
+		t.Fatalf("ListAPIKeys failed: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("ListAPIKeys returned %d keys, want 2", len(keys))
+	}
+}
+
+func TestRevokeKey(t *testing.T) {
+	s := openTestStore(t)
+
+	key, err := s.CreateKey("alice", false)
+	if err != nil {
+		t.Fatalf("CreateKey failed: %v", err)
+	}
+	if err := s.RevokeKey(key.Key); err != nil {
+		t.Fatalf("RevokeKey failed: %v", err)
+	}
+
+	got, found, err := s.GetKey(key.Key)
+	if err != nil || !found {
+		t.Fatalf("GetKey = (found=%v, err=%v), want found", found, err)
+	}
+	if !got.Revoked || got.RevokedAt == 0 {
+		t.Errorf("key after revoke = %+v, want revoked with timestamp", got)
+	}
+
+	// Revoking twice is a no-op and keeps the original timestamp
+	if err := s.RevokeKey(key.Key); err != nil {
+		t.Fatalf("second RevokeKey failed: %v", err)
+	}
+	again, _, _ := s.GetKey(key.Key)
+	if again.RevokedAt != got.RevokedAt {
+		t.Errorf("RevokedAt changed on second revoke: %d -> %d", got.RevokedAt, again.RevokedAt)
+	}
+}
+
+func TestRevokeUnknownKey(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.RevokeKey("sk-nonexistent"); err == nil {
+		t.Error("RevokeKey succeeded on an unknown key, want error")
+	}
+}
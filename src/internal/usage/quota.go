@@ -0,0 +1,80 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var quotaBucket = []byte("quotas")
+
+// Quota bounds a key's token consumption; zero fields are unlimited
+type Quota struct {
+	DailyTokens   int64 `json:"daily_tokens"`
+	MonthlyTokens int64 `json:"monthly_tokens"`
+}
+
+// SetQuota stores a per-key quota override
+func (s *Store) SetQuota(apiKey string, q Quota) error {
+	if apiKey == "" {
+		return fmt.Errorf("empty api key")
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(quotaBucket)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(q)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(apiKey), data)
+	})
+}
+
+// GetQuota returns the key's quota override, if one is set
+func (s *Store) GetQuota(apiKey string) (Quota, bool, error) {
+	var q Quota
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quotaBucket)
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get([]byte(apiKey))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &q); err != nil {
+			return fmt.Errorf("corrupt quota for %s: %w", apiKey, err)
+		}
+		found = true
+		return nil
+	})
+	return q, found, err
+}
+
+// DeleteQuota removes a per-key override, restoring the default
+func (s *Store) DeleteQuota(apiKey string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quotaBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(apiKey))
+	})
+}
+
+// TokensUsed returns prompt+completion tokens the key consumed between
+// from and to (inclusive, by UTC day)
+func (s *Store) TokensUsed(apiKey string, from, to time.Time) (int64, error) {
+	report, err := s.Query(apiKey, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return report.Totals.PromptTokens + report.Totals.CompletionTokens, nil
+}
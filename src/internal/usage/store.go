@@ -0,0 +1,164 @@
+// Package usage meters per-API-key consumption in an embedded bbolt
+// store with daily rollups, so shared lab machines can see who is
+// burning tokens and GPU time on a common server.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DayFormat is the rollup bucket key layout
+const DayFormat = "2006-01-02"
+
+var rootBucket = []byte("usage")
+
+// Day is one day's accumulated usage for a single API key
+type Day struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	GPUSeconds       float64 `json:"gpu_seconds"`
+}
+
+// add accumulates another sample into the rollup
+func (d *Day) add(other Day) {
+	d.Requests += other.Requests
+	d.PromptTokens += other.PromptTokens
+	d.CompletionTokens += other.CompletionTokens
+	d.GPUSeconds += other.GPUSeconds
+}
+
+// DayReport is a rollup with its date attached
+type DayReport struct {
+	Date string `json:"date"`
+	Day
+}
+
+// Report is the result of a usage query
+type Report struct {
+	APIKey string      `json:"api_key"`
+	Days   []DayReport `json:"days"`
+	Totals Day         `json:"totals"`
+}
+
+// Store persists usage rollups; safe for concurrent use
+type Store struct {
+	db *bolt.DB
+	// shared marks a database owned by the caller (a persist.Store
+	// state file); Close is then a no-op
+	shared bool
+}
+
+// Open opens (or creates) the store at path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenDB wraps an already-open database so usage can share a single
+// state file with other subsystems; the caller keeps ownership and
+// closes it
+func OpenDB(db *bolt.DB) *Store {
+	return &Store{db: db, shared: true}
+}
+
+// Close releases the underlying database unless it is shared
+func (s *Store) Close() error {
+	if s.shared {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Record merges a usage sample into the rollup for the key's day
+func (s *Store) Record(apiKey string, at time.Time, sample Day) error {
+	if apiKey == "" {
+		return fmt.Errorf("empty api key")
+	}
+
+	day := at.UTC().Format(DayFormat)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(rootBucket)
+		if err != nil {
+			return err
+		}
+		keyBucket, err := root.CreateBucketIfNotExists([]byte(apiKey))
+		if err != nil {
+			return err
+		}
+
+		var rollup Day
+		if existing := keyBucket.Get([]byte(day)); existing != nil {
+			if err := json.Unmarshal(existing, &rollup); err != nil {
+				return fmt.Errorf("corrupt rollup for %s/%s: %w", apiKey, day, err)
+			}
+		}
+		rollup.add(sample)
+
+		data, err := json.Marshal(rollup)
+		if err != nil {
+			return err
+		}
+		return keyBucket.Put([]byte(day), data)
+	})
+}
+
+// Query returns daily rollups for the key between from and to
+// (inclusive, by UTC day) plus totals
+func (s *Store) Query(apiKey string, from, to time.Time) (*Report, error) {
+	report := &Report{APIKey: apiKey}
+	fromDay := from.UTC().Format(DayFormat)
+	toDay := to.UTC().Format(DayFormat)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(rootBucket)
+		if root == nil {
+			return nil
+		}
+		keyBucket := root.Bucket([]byte(apiKey))
+		if keyBucket == nil {
+			return nil
+		}
+
+		c := keyBucket.Cursor()
+		for k, v := c.Seek([]byte(fromDay)); k != nil && string(k) <= toDay; k, v = c.Next() {
+			var rollup Day
+			if err := json.Unmarshal(v, &rollup); err != nil {
+				return fmt.Errorf("corrupt rollup for %s/%s: %w", apiKey, k, err)
+			}
+			report.Days = append(report.Days, DayReport{Date: string(k), Day: rollup})
+			report.Totals.add(rollup)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Keys lists every API key the store has seen
+func (s *Store) Keys() ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(rootBucket)
+		if root == nil {
+			return nil
+		}
+		return root.ForEachBucket(func(k []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	samples := []Day{
+		{Requests: 1, PromptTokens: 100, CompletionTokens: 20, GPUSeconds: 1.5},
+		{Requests: 1, PromptTokens: 50, CompletionTokens: 10, GPUSeconds: 0.5},
+	}
+	for _, sample := range samples {
+		if err := s.Record("alice", now, sample); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	report, err := s.Query("alice", now, now)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(report.Days) != 1 {
+		t.Fatalf("Got %d days, want 1", len(report.Days))
+	}
+	day := report.Days[0]
+	if day.Date != "2026-03-10" {
+		t.Errorf("Date = %s, want 2026-03-10", day.Date)
+	}
+	if day.Requests != 2 || day.PromptTokens != 150 || day.CompletionTokens != 30 {
+		t.Errorf("Rollup = %+v, want merged samples", day.Day)
+	}
+	if day.GPUSeconds != 2.0 {
+		t.Errorf("GPUSeconds = %f, want 2.0", day.GPUSeconds)
+	}
+}
+
+func TestQueryDateRange(t *testing.T) {
+	s := openTestStore(t)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		day := base.AddDate(0, 0, i)
+		if err := s.Record("bob", day, Day{Requests: 1, PromptTokens: 10}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	report, err := s.Query("bob", base.AddDate(0, 0, 1), base.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(report.Days) != 3 {
+		t.Fatalf("Got %d days, want 3", len(report.Days))
+	}
+	if report.Totals.Requests != 3 || report.Totals.PromptTokens != 30 {
+		t.Errorf("Totals = %+v, want 3 requests / 30 prompt tokens", report.Totals)
+	}
+	if report.Days[0].Date != "2026-03-02" || report.Days[2].Date != "2026-03-04" {
+		t.Errorf("Range = %s..%s, want 2026-03-02..2026-03-04",
+			report.Days[0].Date, report.Days[2].Date)
+	}
+}
+
+func TestQueryUnknownKey(t *testing.T) {
+	s := openTestStore(t)
+
+	report, err := s.Query("nobody", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(report.Days) != 0 || report.Totals.Requests != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
+
+func TestRecordEmptyKey(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Record("", time.Now(), Day{Requests: 1}); err == nil {
+		t.Error("Expected error for empty key")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Now()
+
+	for _, key := range []string{"alice", "bob"} {
+		if err := s.Record(key, now, Day{Requests: 1}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("Got %d keys, want 2: %v", len(keys), keys)
+	}
+}
+
+func TestConcurrentRecord(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := s.Record("shared", now, Day{Requests: 1, PromptTokens: 5}); err != nil {
+					t.Errorf("Record failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	report, err := s.Query("shared", now, now)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if report.Totals.Requests != 80 || report.Totals.PromptTokens != 400 {
+		t.Errorf("Totals = %+v, want 80 requests / 400 prompt tokens", report.Totals)
+	}
+}
+
+func TestOpenDBSharesDatabase(t *testing.T) {
+	owner := openTestStore(t)
+	shared := OpenDB(owner.db)
+
+	now := time.Now()
+	if err := shared.Record("alice", now, Day{Requests: 1}); err != nil {
+		t.Fatalf("Record through shared store failed: %v", err)
+	}
+
+	// Closing the shared wrapper must not close the owner's database
+	if err := shared.Close(); err != nil {
+		t.Fatalf("Close on shared store: %v", err)
+	}
+	report, err := owner.Query("alice", now, now)
+	if err != nil {
+		t.Fatalf("Query after shared close failed: %v", err)
+	}
+	if report.Totals.Requests != 1 {
+		t.Errorf("Totals.Requests = %d, want 1", report.Totals.Requests)
+	}
+}
@@ -0,0 +1,173 @@
+// Package client is a typed Go SDK for the server's OpenAI-compatible
+// API, so agent controllers don't need to hand-roll HTTP. It supports
+// contexts, retries with exponential backoff, and streaming iterators.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRetries is the retry count when none is configured
+const DefaultMaxRetries = 2
+
+// DefaultRetryBackoff is the initial backoff, doubled per attempt
+const DefaultRetryBackoff = 200 * time.Millisecond
+
+// Client talks to a running server
+type Client struct {
+	baseURL    string
+	http       *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option customizes a Client
+type Option func(*Client)
+
+// WithHTTPClient overrides the underlying HTTP client
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
+}
+
+// WithRetries sets the retry count and initial backoff for transient
+// failures (connection errors and 5xx responses)
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the given base URL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{Timeout: 120 * time.Second},
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ChatCompletion sends a non-streaming chat request
+func (c *Client) ChatCompletion(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	req.Stream = false
+	var resp ChatResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/chat/completions", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Tokenize returns the token ids for a piece of text
+func (c *Client) Tokenize(ctx context.Context, req *TokenizeRequest) (*TokenizeResponse, error) {
+	var resp TokenizeResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/tokenize", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Models lists the models the server knows about
+func (c *Client) Models(ctx context.Context) (*ModelsResponse, error) {
+	var resp ModelsResponse
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Health returns the server's health status
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var resp HealthResponse
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/health", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// doJSON performs a request with retries, decoding the JSON response
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, body)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode >= 500 {
+			lastErr = decodeError(resp)
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			err := decodeError(resp)
+			resp.Body.Close()
+			return err
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(out)
+		resp.Body.Close()
+		return err
+	}
+	return fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+// send issues a single HTTP request
+func (c *Client) send(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+// decodeError converts a non-2xx response into an *APIError
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var envelope errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err == nil {
+		apiErr.Type = envelope.Error.Type
+		apiErr.Message = envelope.Error.Message
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChatCompletion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			t.Error("ChatCompletion should not set stream")
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: req.Model,
+			Choices: []Choice{
+				{Message: Message{Role: "assistant", Content: "ok"}, Coordinates: &Point{X: 0.5, Y: 0.25}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	resp, err := c.ChatCompletion(context.Background(), &ChatRequest{
+		Model:    "gui-actor-2b",
+		Messages: []Message{{Role: "user", Content: "click save"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if resp.Model != "gui-actor-2b" || len(resp.Choices) != 1 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if resp.Choices[0].Coordinates.X != 0.5 {
+		t.Errorf("Coordinates = %+v, want X=0.5", resp.Choices[0].Coordinates)
+	}
+}
+
+func TestRetriesOn5xx(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chatcmpl-1"})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+	resp, err := c.ChatCompletion(context.Background(), &ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("Expected retry success, got: %v", err)
+	}
+	if resp.ID != "chatcmpl-1" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Server called %d times, want 3", got)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"error":{"message":"down","type":"server_error"}}`, http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithRetries(2, time.Millisecond))
+	_, err := c.ChatCompletion(context.Background(), &ChatRequest{Model: "m"})
+	if err == nil {
+		t.Fatal("Expected error after exhausted retries")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Server called %d times, want 3", got)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected wrapped *APIError 500, got: %v", err)
+	}
+}
+
+func TestNoRetryOn4xx(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"error":{"message":"unknown model","type":"invalid_request_error"}}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+	_, err := c.ChatCompletion(context.Background(), &ChatRequest{Model: "nope"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "unknown model" {
+		t.Errorf("Unexpected error: %+v", apiErr)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Server called %d times, want 1 (no retry on 4xx)", got)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := New(srv.URL, WithRetries(5, time.Second))
+	_, err := c.ChatCompletion(ctx, &ChatRequest{Model: "m"})
+	if err == nil {
+		t.Fatal("Expected error from cancelled context")
+	}
+}
+
+func TestModelsAndHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			json.NewEncoder(w).Encode(ModelsResponse{
+				Object: "list",
+				Data:   []ModelInfo{{ID: "gui-actor-2b", Loaded: true}},
+			})
+		case "/v1/health":
+			json.NewEncoder(w).Encode(HealthResponse{Status: "ok", Models: 1})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	models, err := c.Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models failed: %v", err)
+	}
+	if len(models.Data) != 1 || models.Data[0].ID != "gui-actor-2b" {
+		t.Errorf("Unexpected models: %+v", models)
+	}
+
+	health, err := c.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if health.Status != "ok" || health.Models != 1 {
+		t.Errorf("Unexpected health: %+v", health)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/tokenize" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(TokenizeResponse{Tokens: []int{1, 2, 3}, Count: 3})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	resp, err := c.Tokenize(context.Background(), &TokenizeRequest{Text: "abc"})
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if resp.Count != 3 || len(resp.Tokens) != 3 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: grounding\n")
+		fmt.Fprint(w, `data: {"object":"chat.grounding","model":"m","coordinates":{"x":0.3,"y":0.7}}`+"\n\n")
+		fmt.Fprint(w, `data: {"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	stream, err := c.Stream(context.Background(), &ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer stream.Close()
+
+	ev, err := stream.Next()
+	if err != nil {
+		t.Fatalf("First Next failed: %v", err)
+	}
+	if ev.Grounding == nil || ev.Grounding.Coordinates.X != 0.3 {
+		t.Errorf("Expected grounding event first, got: %+v", ev)
+	}
+
+	ev, err = stream.Next()
+	if err != nil {
+		t.Fatalf("Second Next failed: %v", err)
+	}
+	if ev.Chunk == nil || ev.Chunk.Choices[0].Delta.Content != "hi" {
+		t.Errorf("Expected content chunk, got: %+v", ev)
+	}
+
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after [DONE], got: %v", err)
+	}
+}
+
+func TestStreamErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad","type":"invalid_request_error"}}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	_, err := c.Stream(context.Background(), &ChatRequest{Model: "m"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected *APIError 400, got: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamEvent is one event from a streaming completion. Exactly one of
+// Grounding or Chunk is set.
+type StreamEvent struct {
+	Grounding *GroundingEvent
+	Chunk     *ChatResponse
+}
+
+// Stream iterates over server-sent events from a streaming completion
+type Stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	event   string // pending event: name, if any
+}
+
+// Stream starts a streaming chat request. The caller must Close the
+// returned stream. Streaming requests are not retried.
+func (c *Client) Stream(ctx context.Context, req *ChatRequest) (*Stream, error) {
+	req.Stream = true
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, "/v1/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &Stream{body: resp.Body, scanner: scanner}, nil
+}
+
+// Next returns the next event, or io.EOF after the [DONE] marker
+func (s *Stream) Next() (*StreamEvent, error) {
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			s.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return nil, io.EOF
+			}
+			event := s.event
+			s.event = ""
+			if event == "grounding" {
+				var g GroundingEvent
+				if err := json.Unmarshal([]byte(data), &g); err != nil {
+					return nil, err
+				}
+				return &StreamEvent{Grounding: &g}, nil
+			}
+			var chunk ChatResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return nil, err
+			}
+			return &StreamEvent{Chunk: &chunk}, nil
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close releases the underlying connection
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
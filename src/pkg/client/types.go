@@ -0,0 +1,131 @@
+package client
+
+import "fmt"
+
+// Message is a chat message
+type Message struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // string or []ContentPart
+}
+
+// ContentPart is one element of multipart content (text + image)
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL carries the base64 image data
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// ChatRequest is an OpenAI-compatible chat completion request
+type ChatRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Stream      bool      `json:"stream,omitempty"`
+	MaxPixels   *int      `json:"max_pixels,omitempty"`
+	MinPixels   *int      `json:"min_pixels,omitempty"`
+	Profile     string    `json:"profile,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+	MaxTokens   *int      `json:"max_tokens,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Preset      string    `json:"preset,omitempty"`
+}
+
+// ChatResponse is an OpenAI-compatible chat completion response or
+// streaming chunk
+type ChatResponse struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage,omitempty"`
+}
+
+// Choice is one completion choice
+type Choice struct {
+	Index        int      `json:"index"`
+	Message      Message  `json:"message"`
+	FinishReason string   `json:"finish_reason"`
+	Delta        *Message `json:"delta,omitempty"`
+	Coordinates  *Point   `json:"coordinates,omitempty"`
+}
+
+// Point is a normalized coordinate pair in [0, 1]
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Usage reports token counts
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// GroundingEvent is the custom SSE event carrying pointer coordinates
+type GroundingEvent struct {
+	Object      string `json:"object"`
+	Created     int64  `json:"created"`
+	Model       string `json:"model"`
+	Coordinates *Point `json:"coordinates"`
+}
+
+// ModelInfo describes one model's status
+type ModelInfo struct {
+	ID       string  `json:"id"`
+	Object   string  `json:"object"`
+	Loaded   bool    `json:"loaded"`
+	MemoryGB float64 `json:"memory_gb,omitempty"`
+}
+
+// ModelsResponse lists available models
+type ModelsResponse struct {
+	Object string      `json:"object"`
+	Data   []ModelInfo `json:"data"`
+}
+
+// HealthResponse is the health check payload
+type HealthResponse struct {
+	Status   string  `json:"status"`
+	Uptime   int64   `json:"uptime_seconds"`
+	MemoryGB float64 `json:"memory_used_gb"`
+	Models   int     `json:"loaded_models"`
+}
+
+// TokenizeRequest asks the server to tokenize text without generating
+type TokenizeRequest struct {
+	Model string `json:"model,omitempty"`
+	Text  string `json:"text"`
+}
+
+// TokenizeResponse returns the token ids and count
+type TokenizeResponse struct {
+	Tokens []int `json:"tokens"`
+	Count  int   `json:"count"`
+}
+
+// APIError is a non-2xx response decoded from the server's error body
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d (%s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// errorResponse mirrors the server's error envelope
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code,omitempty"`
+	} `json:"error"`
+}
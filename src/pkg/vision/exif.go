@@ -0,0 +1,121 @@
+package vision
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation extracts the EXIF orientation tag (1-8) from JPEG
+// bytes, returning 0 when absent or unparseable. Screenshots from
+// mobile devices frequently carry non-default orientations that must
+// be applied before grounding, or predicted coordinates are rotated.
+func jpegOrientation(data []byte) int {
+	// Walk JPEG segments looking for APP1/Exif
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0
+		}
+		marker := data[pos+1]
+		// Start of scan - no EXIF past this point
+		if marker == 0xDA {
+			return 0
+		}
+		segLen := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if segLen < 2 || pos+2+segLen > len(data) {
+			return 0
+		}
+		if marker == 0xE1 {
+			return exifOrientation(data[pos+4 : pos+2+segLen])
+		}
+		pos += 2 + segLen
+	}
+	return 0
+}
+
+// exifOrientation parses an APP1 payload ("Exif\0\0" + TIFF) for tag 0x0112
+func exifOrientation(seg []byte) int {
+	if len(seg) < 14 || string(seg[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := seg[6:]
+
+	var order binary.ByteOrder
+	switch {
+	case tiff[0] == 'I' && tiff[1] == 'I':
+		order = binary.LittleEndian
+	case tiff[0] == 'M' && tiff[1] == 'M':
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifdOffset := order.Uint32(tiff[4:8])
+	if int(ifdOffset)+2 > len(tiff) {
+		return 0
+	}
+
+	numEntries := int(order.Uint16(tiff[ifdOffset : ifdOffset+2]))
+	entryBase := int(ifdOffset) + 2
+	for i := 0; i < numEntries; i++ {
+		entry := entryBase + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		tag := order.Uint16(tiff[entry : entry+2])
+		if tag == 0x0112 {
+			orient := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orient >= 1 && orient <= 8 {
+				return orient
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// applyOrientation transforms img according to the EXIF orientation value
+func applyOrientation(img image.Image, orient int) image.Image {
+	if orient <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	var dst *image.RGBA
+	if orient >= 5 {
+		// Orientations 5-8 swap width and height (90/270 degree rotations)
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			switch orient {
+			case 2: // Mirror horizontal
+				dst.Set(w-1-x, y, c)
+			case 3: // Rotate 180
+				dst.Set(w-1-x, h-1-y, c)
+			case 4: // Mirror vertical
+				dst.Set(x, h-1-y, c)
+			case 5: // Mirror horizontal + rotate 270 CW
+				dst.Set(y, x, c)
+			case 6: // Rotate 90 CW
+				dst.Set(h-1-y, x, c)
+			case 7: // Mirror horizontal + rotate 90 CW
+				dst.Set(h-1-y, w-1-x, c)
+			case 8: // Rotate 270 CW
+				dst.Set(y, w-1-x, c)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,62 @@
+package vision
+
+import (
+	"encoding/json"
+	"image"
+	"os"
+	"testing"
+)
+
+// TestSmartResizeGolden compares resize dimensions against reference
+// values from the HuggingFace Qwen2-VL image processor's smart_resize.
+// Regenerate the fixture with scripts/export_golden_fixtures.py.
+func TestSmartResizeGolden(t *testing.T) {
+	data, err := os.ReadFile("testdata/golden/smart_resize.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var fixture struct {
+		Cases []struct {
+			Name         string `json:"name"`
+			Width        int    `json:"width"`
+			Height       int    `json:"height"`
+			MinPixels    int    `json:"min_pixels"`
+			MaxPixels    int    `json:"max_pixels"`
+			ExpectWidth  int    `json:"expect_width"`
+			ExpectHeight int    `json:"expect_height"`
+			GridW        int    `json:"grid_w"`
+			GridH        int    `json:"grid_h"`
+		} `json:"cases"`
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	if len(fixture.Cases) == 0 {
+		t.Fatal("Fixture has no cases")
+	}
+
+	for _, tt := range fixture.Cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, tt.Width, tt.Height))
+
+			resized, err := SmartResize(img, tt.MinPixels, tt.MaxPixels)
+			if err != nil {
+				t.Fatalf("SmartResize failed: %v", err)
+			}
+
+			bounds := resized.Bounds()
+			if bounds.Dx() != tt.ExpectWidth || bounds.Dy() != tt.ExpectHeight {
+				t.Errorf("SmartResize(%dx%d) = %dx%d, reference %dx%d",
+					tt.Width, tt.Height, bounds.Dx(), bounds.Dy(),
+					tt.ExpectWidth, tt.ExpectHeight)
+			}
+
+			gw, gh := CalculateGrid(bounds.Dx(), bounds.Dy())
+			if gw != tt.GridW || gh != tt.GridH {
+				t.Errorf("CalculateGrid = (%d,%d), reference (%d,%d)",
+					gw, gh, tt.GridW, tt.GridH)
+			}
+		})
+	}
+}
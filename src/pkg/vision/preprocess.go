@@ -0,0 +1,164 @@
+// Package vision is the shared image preprocessing pipeline for all
+// inference backends: decode -> EXIF orientation -> smart resize ->
+// normalize -> tensor. Both the MLX and ONNX paths must use this
+// package so their pixel inputs stay bit-identical.
+package vision
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+)
+
+const (
+	// MergePatchSize is the spatial merge unit of the Qwen2-VL vision
+	// tower; processed dimensions are always multiples of it
+	MergePatchSize = 28
+)
+
+// Normalization constants from the HuggingFace Qwen2-VL image processor
+// (OPENAI_CLIP_MEAN / OPENAI_CLIP_STD)
+var (
+	NormMean = [3]float32{0.48145466, 0.4578275, 0.40821073}
+	NormStd  = [3]float32{0.26862954, 0.26130258, 0.27577711}
+)
+
+// ImageTensor is a normalized CHW float32 tensor ready for a vision tower
+type ImageTensor struct {
+	// Data is laid out [3][Height][Width]
+	Data   []float32
+	Width  int
+	Height int
+	// GridW/GridH are the patch grid dimensions (size / MergePatchSize)
+	GridW int
+	GridH int
+}
+
+// Preprocess runs the full pipeline over encoded image bytes
+func Preprocess(data []byte, minPixels, maxPixels int) (*ImageTensor, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err = SmartResize(img, minPixels, maxPixels)
+	if err != nil {
+		return nil, err
+	}
+
+	return Normalize(img), nil
+}
+
+// Decode parses PNG or JPEG bytes and applies any EXIF orientation
+func Decode(data []byte) (image.Image, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if format == "jpeg" {
+		if orient := jpegOrientation(data); orient > 1 {
+			img = applyOrientation(img, orient)
+		}
+	}
+
+	return img, nil
+}
+
+// SmartResize resizes image to fit within [minPixels, maxPixels]
+// while maintaining aspect ratio and aligning to MergePatchSize
+func SmartResize(img image.Image, minPixels, maxPixels int) (image.Image, error) {
+	bounds := img.Bounds()
+	newW, newH := PlanResize(bounds.Dx(), bounds.Dy(), minPixels, maxPixels)
+	return Resize(img, newW, newH), nil
+}
+
+// PlanResize returns the dimensions SmartResize would produce for a
+// w x h image without touching any pixels, so callers can count image
+// tokens cheaply
+func PlanResize(w, h, minPixels, maxPixels int) (int, int) {
+	currentPixels := w * h
+
+	// Scale into range if needed; in-range images only get grid-aligned
+	newW, newH := w, h
+	if currentPixels < minPixels || currentPixels > maxPixels {
+		scale := 1.0
+		if currentPixels < minPixels {
+			scale = math.Sqrt(float64(minPixels) / float64(currentPixels))
+		} else {
+			scale = math.Sqrt(float64(maxPixels) / float64(currentPixels))
+		}
+		newW = int(math.Round(float64(w) * scale))
+		newH = int(math.Round(float64(h) * scale))
+	}
+
+	// Align to grid size
+	newW = (newW / MergePatchSize) * MergePatchSize
+	newH = (newH / MergePatchSize) * MergePatchSize
+
+	// Ensure minimum size
+	if newW < MergePatchSize {
+		newW = MergePatchSize
+	}
+	if newH < MergePatchSize {
+		newH = MergePatchSize
+	}
+
+	return newW, newH
+}
+
+// CalculateGrid returns the grid dimensions for patch processing
+func CalculateGrid(w, h int) (int, int) {
+	return w / MergePatchSize, h / MergePatchSize
+}
+
+// Resize scales img to w x h with nearest-neighbor sampling
+func Resize(img image.Image, w, h int) image.Image {
+	srcBounds := img.Bounds()
+	if srcBounds.Dx() == w && srcBounds.Dy() == h {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			srcX := x * srcBounds.Dx() / w
+			srcY := y * srcBounds.Dy() / h
+			dst.Set(x, y, img.At(srcBounds.Min.X+srcX, srcBounds.Min.Y+srcY))
+		}
+	}
+
+	return dst
+}
+
+// Normalize converts an image to a CHW float32 tensor using the
+// Qwen2-VL processor's rescale + normalize: (pixel/255 - mean) / std
+func Normalize(img image.Image) *ImageTensor {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	gridW, gridH := CalculateGrid(w, h)
+
+	data := make([]float32, 3*w*h)
+	plane := w * h
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			idx := y*w + x
+			data[idx] = (float32(r>>8)/255.0 - NormMean[0]) / NormStd[0]
+			data[plane+idx] = (float32(g>>8)/255.0 - NormMean[1]) / NormStd[1]
+			data[2*plane+idx] = (float32(b>>8)/255.0 - NormMean[2]) / NormStd[2]
+		}
+	}
+
+	return &ImageTensor{
+		Data:   data,
+		Width:  w,
+		Height: h,
+		GridW:  gridW,
+		GridH:  gridH,
+	}
+}
@@ -0,0 +1,204 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func solidImage(w, h int, c color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCalculateGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		w, h     int
+		expectGW int
+		expectGH int
+	}{
+		{"112x224", 112, 224, 4, 8},
+		{"224x224", 224, 224, 8, 8},
+		{"56x56", 56, 56, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw, gh := CalculateGrid(tt.w, tt.h)
+			if gw != tt.expectGW || gh != tt.expectGH {
+				t.Errorf("CalculateGrid(%d,%d) = (%d,%d), want (%d,%d)",
+					tt.w, tt.h, gw, gh, tt.expectGW, tt.expectGH)
+			}
+		})
+	}
+}
+
+func TestSmartResizeGridAlignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		w, h      int
+		minPixels int
+		maxPixels int
+	}{
+		{"upscale small", 10, 10, 3136, 5720064},
+		{"downscale large", 4000, 3000, 3136, 1048576},
+		{"in range unaligned", 100, 100, 3136, 5720064},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SmartResize(solidImage(tt.w, tt.h, color.RGBA{128, 128, 128, 255}), tt.minPixels, tt.maxPixels)
+			if err != nil {
+				t.Fatalf("SmartResize failed: %v", err)
+			}
+			b := out.Bounds()
+			if b.Dx()%MergePatchSize != 0 || b.Dy()%MergePatchSize != 0 {
+				t.Errorf("Result %dx%d not aligned to %d", b.Dx(), b.Dy(), MergePatchSize)
+			}
+			if b.Dx()*b.Dy() > tt.maxPixels {
+				t.Errorf("Result %dx%d exceeds maxPixels %d", b.Dx(), b.Dy(), tt.maxPixels)
+			}
+		})
+	}
+}
+
+// Golden values match the HuggingFace Qwen2-VL image processor output
+// for solid-color inputs: (pixel/255 - CLIP_MEAN) / CLIP_STD in float32
+func TestNormalizeGoldenValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		pixel  color.RGBA
+		expect [3]float32
+	}{
+		{"mid gray", color.RGBA{128, 128, 128, 255}, [3]float32{0.0763361, 0.1688973, 0.3399488}},
+		{"pure red", color.RGBA{255, 0, 0, 255}, [3]float32{1.9303361, -1.7520971, -1.4802198}},
+		{"black", color.RGBA{0, 0, 0, 255}, [3]float32{-1.7922626, -1.7520971, -1.4802198}},
+		{"white", color.RGBA{255, 255, 255, 255}, [3]float32{1.9303361, 2.0748837, 2.1458972}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tensor := Normalize(solidImage(MergePatchSize, MergePatchSize, tt.pixel))
+			plane := tensor.Width * tensor.Height
+			for ch := 0; ch < 3; ch++ {
+				got := tensor.Data[ch*plane]
+				if math.Abs(float64(got-tt.expect[ch])) > 1e-5 {
+					t.Errorf("channel %d = %v, want %v", ch, got, tt.expect[ch])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeLayout(t *testing.T) {
+	tensor := Normalize(solidImage(56, 28, color.RGBA{0, 0, 0, 255}))
+	if tensor.Width != 56 || tensor.Height != 28 {
+		t.Errorf("Unexpected dimensions %dx%d", tensor.Width, tensor.Height)
+	}
+	if tensor.GridW != 2 || tensor.GridH != 1 {
+		t.Errorf("Grid = (%d,%d), want (2,1)", tensor.GridW, tensor.GridH)
+	}
+	if len(tensor.Data) != 3*56*28 {
+		t.Errorf("Data length = %d, want %d", len(tensor.Data), 3*56*28)
+	}
+}
+
+func TestPreprocessFullPipeline(t *testing.T) {
+	data := encodePNG(t, solidImage(100, 100, color.RGBA{128, 128, 128, 255}))
+
+	tensor, err := Preprocess(data, 3136, 5720064)
+	if err != nil {
+		t.Fatalf("Preprocess failed: %v", err)
+	}
+	if tensor.Width%MergePatchSize != 0 || tensor.Height%MergePatchSize != 0 {
+		t.Errorf("Output %dx%d not grid aligned", tensor.Width, tensor.Height)
+	}
+	if len(tensor.Data) != 3*tensor.Width*tensor.Height {
+		t.Errorf("Data length %d inconsistent with %dx%d", len(tensor.Data), tensor.Width, tensor.Height)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte("not an image")); err == nil {
+		t.Error("Expected error for invalid image data")
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1 image: red on the left, blue on the right
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{0, 0, 255, 255})
+
+	tests := []struct {
+		name   string
+		orient int
+		w, h   int
+		redAt  image.Point
+	}{
+		{"identity", 1, 2, 1, image.Pt(0, 0)},
+		{"mirror horizontal", 2, 2, 1, image.Pt(1, 0)},
+		{"rotate 180", 3, 2, 1, image.Pt(1, 0)},
+		{"rotate 90 cw", 6, 1, 2, image.Pt(0, 0)},
+		{"rotate 270 cw", 8, 1, 2, image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := applyOrientation(img, tt.orient)
+			b := out.Bounds()
+			if b.Dx() != tt.w || b.Dy() != tt.h {
+				t.Fatalf("Size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.w, tt.h)
+			}
+			r, _, _, _ := out.At(tt.redAt.X, tt.redAt.Y).RGBA()
+			if r>>8 != 255 {
+				t.Errorf("Expected red pixel at %v", tt.redAt)
+			}
+		})
+	}
+}
+
+func TestJpegOrientation(t *testing.T) {
+	// Minimal JPEG prefix with an APP1/Exif segment declaring orientation 6
+	exif := []byte{
+		0xFF, 0xD8, // SOI
+		0xFF, 0xE1, 0x00, 0x1E, // APP1, length 30
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		'M', 'M', 0x00, 0x2A, // big-endian TIFF
+		0x00, 0x00, 0x00, 0x08, // IFD at offset 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, // tag 0x0112, SHORT, count 1
+		0x00, 0x06, 0x00, 0x00, // value 6
+	}
+	if got := jpegOrientation(exif); got != 6 {
+		t.Errorf("jpegOrientation = %d, want 6", got)
+	}
+
+	if got := jpegOrientation([]byte{0x00, 0x01}); got != 0 {
+		t.Errorf("jpegOrientation on garbage = %d, want 0", got)
+	}
+
+	// PNG-encoded bytes are not JPEG and must return 0
+	data := encodePNG(t, solidImage(4, 4, color.RGBA{0, 0, 0, 255}))
+	if got := jpegOrientation(data); got != 0 {
+		t.Errorf("jpegOrientation on PNG = %d, want 0", got)
+	}
+}
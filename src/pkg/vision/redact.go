@@ -0,0 +1,80 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// pixelateBlock is the mosaic cell size used for blur redaction, in
+// pixels of the source image
+const pixelateBlock = 16
+
+// Region is a normalized rectangle (coordinates in [0, 1]) to mask
+// before an image reaches the vision encoder, e.g. a password manager
+// window. Blur pixelates the area instead of blacking it out.
+type Region struct {
+	X0, Y0 float64
+	X1, Y1 float64
+	Blur   bool
+}
+
+// Redact returns a copy of img with the regions masked. With no
+// regions the original image is returned unchanged.
+func Redact(img image.Image, regions []Region) image.Image {
+	if len(regions) == 0 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	for _, r := range regions {
+		rect := image.Rect(
+			int(math.Floor(r.X0*float64(w))),
+			int(math.Floor(r.Y0*float64(h))),
+			int(math.Ceil(r.X1*float64(w))),
+			int(math.Ceil(r.Y1*float64(h))),
+		).Intersect(dst.Bounds())
+		if rect.Empty() {
+			continue
+		}
+		if r.Blur {
+			pixelate(dst, rect)
+		} else {
+			draw.Draw(dst, rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+		}
+	}
+
+	return dst
+}
+
+// pixelate replaces rect with the per-block average color, destroying
+// text while keeping coarse layout visible
+func pixelate(dst *image.RGBA, rect image.Rectangle) {
+	for by := rect.Min.Y; by < rect.Max.Y; by += pixelateBlock {
+		for bx := rect.Min.X; bx < rect.Max.X; bx += pixelateBlock {
+			block := image.Rect(bx, by, bx+pixelateBlock, by+pixelateBlock).Intersect(rect)
+
+			var sumR, sumG, sumB, n uint64
+			for y := block.Min.Y; y < block.Max.Y; y++ {
+				for x := block.Min.X; x < block.Max.X; x++ {
+					r, g, b, _ := dst.At(x, y).RGBA()
+					sumR += uint64(r >> 8)
+					sumG += uint64(g >> 8)
+					sumB += uint64(b >> 8)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+
+			avg := color.RGBA{uint8(sumR / n), uint8(sumG / n), uint8(sumB / n), 255}
+			draw.Draw(dst, block, image.NewUniform(avg), image.Point{}, draw.Src)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRedactBlackout(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	img := solidImage(100, 100, white)
+
+	out := Redact(img, []Region{{X0: 0.25, Y0: 0.25, X1: 0.75, Y1: 0.75}})
+
+	tests := []struct {
+		name      string
+		x, y      int
+		wantBlack bool
+	}{
+		{"center of region", 50, 50, true},
+		{"region corner", 30, 30, true},
+		{"outside top-left", 10, 10, false},
+		{"outside bottom-right", 90, 90, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g, b, _ := out.At(tt.x, tt.y).RGBA()
+			isBlack := r == 0 && g == 0 && b == 0
+			if isBlack != tt.wantBlack {
+				t.Errorf("pixel (%d,%d) black = %v, want %v", tt.x, tt.y, isBlack, tt.wantBlack)
+			}
+		})
+	}
+}
+
+func TestRedactBlur(t *testing.T) {
+	// Alternate black and white columns; pixelation should average them
+	// to grey inside the region while the outside keeps pure colors
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.RGBA{0, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	out := Redact(img, []Region{{X0: 0, Y0: 0, X1: 0.5, Y1: 1, Blur: true}})
+
+	r, _, _, _ := out.At(4, 4).RGBA()
+	v := uint8(r >> 8)
+	if v < 64 || v > 192 {
+		t.Errorf("blurred pixel value = %d, want mid-grey average", v)
+	}
+	// Right half keeps the alternating pattern
+	r, _, _, _ = out.At(40, 4).RGBA()
+	if v := uint8(r >> 8); v != 0 {
+		t.Errorf("unredacted pixel value = %d, want untouched black column", v)
+	}
+}
+
+func TestRedactNoRegionsReturnsOriginal(t *testing.T) {
+	img := solidImage(32, 32, color.RGBA{10, 20, 30, 255})
+	if out := Redact(img, nil); out != img {
+		t.Error("Redact(nil regions) did not return the original image")
+	}
+}
+
+func TestRedactRegionOutsideBounds(t *testing.T) {
+	img := solidImage(32, 32, color.RGBA{255, 255, 255, 255})
+	out := Redact(img, []Region{{X0: 1.5, Y0: 1.5, X1: 2, Y1: 2}})
+
+	r, _, _, _ := out.At(16, 16).RGBA()
+	if v := uint8(r >> 8); v != 255 {
+		t.Errorf("pixel value = %d, want untouched white", v)
+	}
+}
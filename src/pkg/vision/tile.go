@@ -0,0 +1,143 @@
+package vision
+
+import (
+	"image"
+	"math"
+)
+
+// Tiling for screenshots far larger than the pixel budget. Downscaling
+// a 5K or multi-monitor capture to maxPixels shrinks small UI elements
+// (close buttons, checkboxes) below patch resolution; instead the
+// screenshot is split into overlapping tiles, each encoded at native
+// resolution, and per-tile predictions are mapped back to global
+// coordinates.
+
+const (
+	// MaxDownscale is the linear scale factor below which plain
+	// downscaling is considered lossy enough to tile instead
+	MaxDownscale = 0.5
+
+	// TileOverlapPx is how much neighboring tiles share, so an element
+	// cut by one seam appears whole in the adjacent tile; four merge
+	// patches on each side
+	TileOverlapPx = 4 * MergePatchSize
+)
+
+// Tile is one preprocessed region of an oversized screenshot
+type Tile struct {
+	Tensor *ImageTensor
+	// Rect locates the tile in the source image, in source pixels
+	Rect image.Rectangle
+	// SourceW/SourceH are the full source dimensions, kept so tile
+	// coordinates can be mapped back without extra bookkeeping
+	SourceW int
+	SourceH int
+}
+
+// ShouldTile reports whether fitting a w x h image into maxPixels would
+// shrink it past MaxDownscale, i.e. whether tiling preserves detail
+// that plain resizing would destroy
+func ShouldTile(w, h, maxPixels int) bool {
+	pixels := w * h
+	if pixels <= maxPixels {
+		return false
+	}
+	scale := math.Sqrt(float64(maxPixels) / float64(pixels))
+	return scale < MaxDownscale
+}
+
+// TileRects lays out overlapping tiles covering a w x h image, each at
+// most maxPixels. Tiles are square-ish, grid-aligned, and the last row
+// and column are shifted inward rather than clipped so every tile has
+// the full size.
+func TileRects(w, h, maxPixels int) []image.Rectangle {
+	side := int(math.Sqrt(float64(maxPixels)))
+	side = (side / MergePatchSize) * MergePatchSize
+	if side < MergePatchSize {
+		side = MergePatchSize
+	}
+
+	tileW, tileH := side, side
+	if tileW > w {
+		tileW = w
+	}
+	if tileH > h {
+		tileH = h
+	}
+
+	strideX := tileW - TileOverlapPx
+	if strideX <= 0 {
+		strideX = tileW
+	}
+	strideY := tileH - TileOverlapPx
+	if strideY <= 0 {
+		strideY = tileH
+	}
+
+	var rects []image.Rectangle
+	for y := 0; ; y += strideY {
+		y0 := y
+		if y0+tileH > h {
+			y0 = h - tileH
+		}
+		for x := 0; ; x += strideX {
+			x0 := x
+			if x0+tileW > w {
+				x0 = w - tileW
+			}
+			rects = append(rects, image.Rect(x0, y0, x0+tileW, y0+tileH))
+			if x0+tileW >= w {
+				break
+			}
+		}
+		if y0+tileH >= h {
+			break
+		}
+	}
+	return rects
+}
+
+// PreprocessTiled splits an oversized image into tiles and runs the
+// standard pipeline over each at native resolution. Callers decide via
+// ShouldTile whether to use this or the plain Preprocess path.
+func PreprocessTiled(img image.Image, minPixels, maxPixels int) ([]Tile, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	rects := TileRects(w, h, maxPixels)
+	tiles := make([]Tile, 0, len(rects))
+	for _, rect := range rects {
+		sub := cropImage(img, rect.Add(bounds.Min))
+		resized, err := SmartResize(sub, minPixels, maxPixels)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, Tile{
+			Tensor:  Normalize(resized),
+			Rect:    rect,
+			SourceW: w,
+			SourceH: h,
+		})
+	}
+	return tiles, nil
+}
+
+// MapToGlobal converts a normalized point predicted within the tile
+// back to normalized coordinates of the full screenshot
+func (t Tile) MapToGlobal(x, y float64) (float64, float64) {
+	px := float64(t.Rect.Min.X) + x*float64(t.Rect.Dx())
+	py := float64(t.Rect.Min.Y) + y*float64(t.Rect.Dy())
+	return px / float64(t.SourceW), py / float64(t.SourceH)
+}
+
+// cropImage copies a source rectangle into its own image so tile
+// tensors don't alias the full-resolution backing array
+func cropImage(img image.Image, rect image.Rectangle) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			dst.Set(x, y, img.At(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,114 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestShouldTile(t *testing.T) {
+	tests := []struct {
+		name      string
+		w, h      int
+		maxPixels int
+		want      bool
+	}{
+		{"fits outright", 1024, 768, 5720064, false},
+		{"mild downscale", 3000, 2000, 5720064, false},
+		{"5K ultra-wide", 5120, 2880, 1048576, true},
+		{"dual 4K", 7680, 2160, 1048576, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldTile(tt.w, tt.h, tt.maxPixels); got != tt.want {
+				t.Errorf("ShouldTile(%d, %d, %d) = %v, want %v", tt.w, tt.h, tt.maxPixels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTileRectsCoverAndOverlap(t *testing.T) {
+	w, h := 300, 150
+	maxPixels := 3136 // 56x56 tiles
+
+	rects := TileRects(w, h, maxPixels)
+	if len(rects) < 2 {
+		t.Fatalf("got %d tiles, want several for a %dx%d image", len(rects), w, h)
+	}
+
+	covered := image.NewGray(image.Rect(0, 0, w, h))
+	for _, r := range rects {
+		if r.Dx()*r.Dy() > maxPixels {
+			t.Errorf("tile %v exceeds the pixel budget", r)
+		}
+		if r.Min.X < 0 || r.Min.Y < 0 || r.Max.X > w || r.Max.Y > h {
+			t.Errorf("tile %v outside the image", r)
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				covered.SetGray(x, y, color.Gray{255})
+			}
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if covered.GrayAt(x, y).Y != 255 {
+				t.Fatalf("pixel (%d, %d) covered by no tile", x, y)
+			}
+		}
+	}
+}
+
+func TestTileRectsSingleTileForSmallImage(t *testing.T) {
+	rects := TileRects(40, 40, 3136)
+	if len(rects) != 1 {
+		t.Fatalf("got %d tiles, want 1", len(rects))
+	}
+	if rects[0] != image.Rect(0, 0, 40, 40) {
+		t.Errorf("tile = %v, want the whole image", rects[0])
+	}
+}
+
+func TestPreprocessTiled(t *testing.T) {
+	img := solidImage(300, 150, color.RGBA{128, 128, 128, 255})
+
+	tiles, err := PreprocessTiled(img, 0, 3136)
+	if err != nil {
+		t.Fatalf("PreprocessTiled failed: %v", err)
+	}
+	if len(tiles) < 2 {
+		t.Fatalf("got %d tiles, want several", len(tiles))
+	}
+	for i, tile := range tiles {
+		if tile.Tensor == nil {
+			t.Fatalf("tile %d has no tensor", i)
+		}
+		if tile.Tensor.GridW == 0 || tile.Tensor.GridH == 0 {
+			t.Errorf("tile %d has an empty patch grid", i)
+		}
+		if tile.SourceW != 300 || tile.SourceH != 150 {
+			t.Errorf("tile %d source = %dx%d, want 300x150", i, tile.SourceW, tile.SourceH)
+		}
+	}
+}
+
+func TestMapToGlobal(t *testing.T) {
+	tile := Tile{
+		Rect:    image.Rect(100, 50, 200, 150),
+		SourceW: 400,
+		SourceH: 200,
+	}
+
+	// The tile center is at source pixel (150, 100)
+	gx, gy := tile.MapToGlobal(0.5, 0.5)
+	if math.Abs(gx-150.0/400) > 1e-9 || math.Abs(gy-100.0/200) > 1e-9 {
+		t.Errorf("MapToGlobal(0.5, 0.5) = (%f, %f), want (%f, %f)", gx, gy, 150.0/400, 100.0/200)
+	}
+
+	// The tile origin maps to the tile's offset
+	gx, gy = tile.MapToGlobal(0, 0)
+	if math.Abs(gx-0.25) > 1e-9 || math.Abs(gy-0.25) > 1e-9 {
+		t.Errorf("MapToGlobal(0, 0) = (%f, %f), want (0.25, 0.25)", gx, gy)
+	}
+}
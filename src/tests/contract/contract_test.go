@@ -0,0 +1,259 @@
+// Package contract pins the OpenAI-compatible wire format. Fixtures
+// under testdata/ are raw request bodies; each test replays one
+// against a full server (all routes, mock engine) and checks the
+// response shape field by field, so accidental schema drift fails
+// here before it breaks SDK clients.
+package contract
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/agenthands/mlxvllm/internal/api"
+)
+
+// newContractServer serves the complete route table the way serve does
+func newContractServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(api.NewServer("", api.NewHandler(nil)).Handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// postFixture replays a testdata request body against the server
+func postFixture(t *testing.T, srv *httptest.Server, fixture string) *http.Response {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post %s: %v", fixture, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// checkKeys fails when obj has keys outside allowed or misses any of
+// required; unexpected keys are how schema drift usually shows up
+func checkKeys(t *testing.T, what string, obj map[string]json.RawMessage, required, optional []string) {
+	t.Helper()
+	allowed := make(map[string]bool, len(required)+len(optional))
+	for _, k := range required {
+		allowed[k] = true
+		if _, ok := obj[k]; !ok {
+			t.Errorf("%s: required field %q missing", what, k)
+		}
+	}
+	for _, k := range optional {
+		allowed[k] = true
+	}
+
+	var extra []string
+	for k := range obj {
+		if !allowed[k] {
+			extra = append(extra, k)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		t.Errorf("%s: unexpected fields %v — update the contract if this change is intentional", what, extra)
+	}
+}
+
+func TestCompletionResponseShape(t *testing.T) {
+	srv := newContractServer(t)
+	resp := postFixture(t, srv, "completion.json")
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&top); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	checkKeys(t, "response", top,
+		[]string{"id", "object", "created", "model", "choices"},
+		[]string{"usage", "debug"})
+
+	var object string
+	json.Unmarshal(top["object"], &object)
+	if object != "chat.completion" {
+		t.Errorf("object = %q, want chat.completion", object)
+	}
+
+	var choices []map[string]json.RawMessage
+	if err := json.Unmarshal(top["choices"], &choices); err != nil || len(choices) != 1 {
+		t.Fatalf("choices = %s (err=%v)", top["choices"], err)
+	}
+	checkKeys(t, "choice", choices[0],
+		[]string{"index", "message", "finish_reason"},
+		[]string{"delta", "coordinates", "raw_coordinates", "x_gui_actor"})
+
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(choices[0]["message"], &message); err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	checkKeys(t, "message", message,
+		[]string{"role", "content"},
+		[]string{"tool_calls", "tool_call_id", "cache_control"})
+	var role string
+	json.Unmarshal(message["role"], &role)
+	if role != "assistant" {
+		t.Errorf("message role = %q", role)
+	}
+
+	var usage map[string]json.RawMessage
+	if err := json.Unmarshal(top["usage"], &usage); err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	checkKeys(t, "usage", usage,
+		[]string{"prompt_tokens", "completion_tokens", "total_tokens"},
+		[]string{"prompt_tokens_details", "context_remaining"})
+}
+
+func TestStreamingContract(t *testing.T) {
+	srv := newContractServer(t)
+	resp := postFixture(t, srv, "completion_stream.json")
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	sawChunk, sawGrounding, sawDone := false, false, false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+		if payload == "[DONE]" {
+			sawDone = true
+			continue
+		}
+
+		var event map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			t.Fatalf("unparseable SSE payload %q: %v", payload, err)
+		}
+		var object string
+		json.Unmarshal(event["object"], &object)
+		switch object {
+		case "chat.completion.chunk":
+			sawChunk = true
+			checkKeys(t, "chunk", event,
+				[]string{"id", "object", "created", "model", "choices"},
+				[]string{"usage", "debug"})
+		case "chat.grounding":
+			sawGrounding = true
+			checkKeys(t, "grounding event", event,
+				[]string{"object", "created", "model", "coordinates"},
+				[]string{"raw_coordinates", "x_gui_actor"})
+		}
+	}
+	if !sawChunk || !sawGrounding || !sawDone {
+		t.Errorf("stream missing parts: chunk=%v grounding=%v done=%v", sawChunk, sawGrounding, sawDone)
+	}
+}
+
+func TestToolCallContract(t *testing.T) {
+	srv := newContractServer(t)
+	resp := postFixture(t, srv, "tool_calls.json")
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []map[string]json.RawMessage `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(parsed.Choices) != 1 || len(parsed.Choices[0].Message.ToolCalls) == 0 {
+		t.Fatal("no tool calls in response")
+	}
+	if parsed.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", parsed.Choices[0].FinishReason)
+	}
+
+	for i, call := range parsed.Choices[0].Message.ToolCalls {
+		checkKeys(t, "tool call", call,
+			[]string{"index", "id", "type", "function"},
+			[]string{"coordinates"})
+		var callType string
+		json.Unmarshal(call["type"], &callType)
+		if callType != "function" {
+			t.Errorf("tool call %d type = %q", i, callType)
+		}
+		var fn map[string]json.RawMessage
+		if err := json.Unmarshal(call["function"], &fn); err != nil {
+			t.Fatalf("tool call %d function: %v", i, err)
+		}
+		checkKeys(t, "function call", fn, []string{"name", "arguments"}, nil)
+		// Arguments must round-trip as a JSON-encoded string, the way
+		// SDKs expect
+		var args string
+		if err := json.Unmarshal(fn["arguments"], &args); err != nil {
+			t.Errorf("tool call %d arguments is not a JSON string: %s", i, fn["arguments"])
+		} else if !json.Valid([]byte(args)) {
+			t.Errorf("tool call %d arguments does not contain JSON: %q", i, args)
+		}
+	}
+}
+
+func TestErrorContract(t *testing.T) {
+	srv := newContractServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `{`, 400},
+		{"bad response_format", `{"response_format":{"type":"nope"},"messages":[{"role":"user","content":"hi"}]}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json",
+				strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.code {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.code)
+			}
+
+			var top map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&top); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			checkKeys(t, "error response", top, []string{"error"}, nil)
+			var detail map[string]json.RawMessage
+			if err := json.Unmarshal(top["error"], &detail); err != nil {
+				t.Fatalf("error detail: %v", err)
+			}
+			checkKeys(t, "error detail", detail, []string{"message", "type"}, []string{"code"})
+		})
+	}
+}
@@ -1,54 +1,96 @@
-//go:build integration
-// +build integration
-
 package integration
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/agenthands/GUI-Actor/internal/http"
+	httpserver "github.com/agenthands/GUI-Actor/internal/http"
 	"github.com/agenthands/GUI-Actor/internal/radix"
 	"github.com/agenthands/GUI-Actor/pkg/tokenizer"
 )
 
-// TestFullPipelineIntegration tests the complete request pipeline
-// Requires Apple Silicon hardware and MLX runtime
-func TestFullPipelineIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
-
-	// This test requires:
-	// 1. Apple Silicon M4 Pro (or similar)
-	// 2. MLX runtime library built and installed
-	// 3. Model weights available
-	// 4. Sufficient memory
+// These tests run the full HTTP + radix + tokenizer stack against the
+// simulated engine - the same assembly internal/server performs with
+// -backend=sim - so the complete pipeline is exercised in CI without
+// Apple Silicon hardware or the MLX runtime.
 
-	t.Skip("Integration tests require Apple Silicon hardware - set up environment first")
+// newSimStack wires a test server onto a SimEngine with the given config
+func newSimStack(t *testing.T, cfg radix.SimConfig) (*httptest.Server, *radix.Tree, *radix.SimEngine) {
+	t.Helper()
 
-	// Setup components
 	tree := radix.NewTree()
-	engine := setupMLXEngine(t)
+	engine := radix.NewSimEngine(cfg)
 	tok := tokenizer.NewTokenizer(32000)
-	model := loadModel(t)
+	model := map[string]any{"type": "sim", "vocab_size": 32000}
+
+	server := httpserver.NewServer(tree, engine, tok, model)
+	mux := nethttp.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, tree, engine
+}
+
+func sendChatCompletion(t *testing.T, ts *httptest.Server, req httpserver.ChatCompletionRequest) *httpserver.ChatCompletionResponse {
+	t.Helper()
+
+	resp, err := sendChatCompletionAsync(ts, req)
+	if err != nil {
+		t.Fatalf("Chat completion failed: %v", err)
+	}
+	return resp
+}
+
+func sendChatCompletionAsync(ts *httptest.Server, req httpserver.ChatCompletionRequest) (*httpserver.ChatCompletionResponse, error) {
+	body, _ := json.Marshal(req)
+	resp, err := nethttp.Post(ts.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != nethttp.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
 
-	server := http.NewServer(tree, engine, tok, model)
+	var response httpserver.ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
 
-	// Start HTTP server
-	addr := startTestServer(server)
-	defer stopTestServer(addr)
+// waitForForwards polls until the engine has run at least n forward
+// passes (they happen on background finalize goroutines)
+func waitForForwards(t *testing.T, engine *radix.SimEngine, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if engine.Forwards() >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Engine ran %d forwards, want >= %d", engine.Forwards(), n)
+}
+
+// TestFullPipelineSim covers the end-to-end request path on the sim backend
+func TestFullPipelineSim(t *testing.T) {
+	ts, _, engine := newSimStack(t, radix.SimConfig{VocabSize: 32000, Seed: 1})
 
-	// Test 1: Simple chat completion
 	t.Run("SimpleChatCompletion", func(t *testing.T) {
-		req := http.ChatCompletionRequest{
+		req := httpserver.ChatCompletionRequest{
 			Messages: []tokenizer.ChatMessage{
 				{Role: "system", Content: "You are a helpful assistant."},
 				{Role: "user", Content: "Say 'Hello, World!'"},
@@ -56,182 +98,232 @@ func TestFullPipelineIntegration(t *testing.T) {
 			MaxTokens: 10,
 		}
 
-		response := sendChatCompletion(t, addr, req)
+		response := sendChatCompletion(t, ts, req)
 
 		if len(response.Choices) == 0 {
 			t.Fatal("Expected at least one choice")
 		}
-
-		if response.Choices[0].Message.Content == "" {
-			t.Error("Expected non-empty content")
+		if response.Choices[0].FinishReason != "stop" {
+			t.Errorf("FinishReason = %q, want stop", response.Choices[0].FinishReason)
+		}
+		if response.Usage.PromptTokens == 0 {
+			t.Error("Expected non-zero prompt tokens")
 		}
-
-		t.Logf("Response: %s", response.Choices[0].Message.Content)
 	})
 
-	// Test 2: Prefix caching - second request should hit cache
 	t.Run("PrefixCaching", func(t *testing.T) {
-		// First request
-		req1 := http.ChatCompletionRequest{
+		req := httpserver.ChatCompletionRequest{
 			Messages: []tokenizer.ChatMessage{
 				{Role: "user", Content: "The capital of France is"},
 			},
 			MaxTokens: 5,
 		}
 
-		start1 := time.Now()
-		resp1 := sendChatCompletion(t, addr, req1)
-		duration1 := time.Since(start1)
-
-		// Second request with same prefix
-		req2 := http.ChatCompletionRequest{
-			Messages: []tokenizer.ChatMessage{
-				{Role: "user", Content: "The capital of France is Paris"},
-			},
-			MaxTokens: 5,
-		}
-
-		start2 := time.Now()
-		resp2 := sendChatCompletion(t, addr, req2)
-		duration2 := time.Since(start2)
+		sendChatCompletion(t, ts, req)
+		waitForForwards(t, engine, 1)
+		before := engine.Forwards()
 
-		t.Logf("First request: %v, Second request: %v", duration1, duration2)
-
-		// Second request should be faster due to cache hit
-		// (This is a weak assertion due to variance)
-		if duration2 < duration1 {
-			t.Log("Cache hit: second request was faster")
-		}
+		// Identical request: the generated prefix is already finalized
+		// in the tree, so no new forward pass should be scheduled
+		sendChatCompletion(t, ts, req)
+		time.Sleep(100 * time.Millisecond)
 
-		if len(resp2.Choices) == 0 {
-			t.Fatal("Expected at least one choice")
+		if after := engine.Forwards(); after != before {
+			t.Errorf("Forwards went %d -> %d on cached request, want no change", before, after)
 		}
 	})
 
-	// Test 3: Concurrent requests
 	t.Run("ConcurrentRequests", func(t *testing.T) {
-		const numRequests = 5
-
-		results := make(chan *http.ChatCompletionResponse, numRequests)
-		errors := make(chan error, numRequests)
+		const numRequests = 8
 
+		var wg sync.WaitGroup
+		errs := make(chan error, numRequests)
 		for i := 0; i < numRequests; i++ {
+			wg.Add(1)
 			go func(idx int) {
-				req := http.ChatCompletionRequest{
+				defer wg.Done()
+				req := httpserver.ChatCompletionRequest{
 					Messages: []tokenizer.ChatMessage{
 						{Role: "user", Content: fmt.Sprintf("Request %d", idx)},
 					},
 					MaxTokens: 3,
 				}
-
-				resp, err := sendChatCompletionAsync(addr, req)
-				if err != nil {
-					errors <- err
-					return
+				if _, err := sendChatCompletionAsync(ts, req); err != nil {
+					errs <- err
 				}
-				results <- resp
 			}(i)
 		}
+		wg.Wait()
+		close(errs)
 
-		// Collect results
-		successCount := 0
-		for i := 0; i < numRequests; i++ {
-			select {
-			case <-results:
-				successCount++
-			case err := <-errors:
-				t.Logf("Request failed: %v", err)
-			case <-time.After(30 * time.Second):
-				t.Fatal("Timeout waiting for responses")
-			}
+		for err := range errs {
+			t.Errorf("Request failed: %v", err)
 		}
+	})
+}
 
-		if successCount < numRequests {
-			t.Errorf("Only %d/%d requests succeeded", successCount, numRequests)
-		}
+// TestStreamingSim covers the SSE streaming path
+func TestStreamingSim(t *testing.T) {
+	ts, _, _ := newSimStack(t, radix.SimConfig{VocabSize: 32000, Seed: 1})
+
+	body, _ := json.Marshal(httpserver.ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{
+			{Role: "user", Content: "Stream this back"},
+		},
+		MaxTokens: 5,
+		Stream:    true,
 	})
 
-	// Test 4: Multimodal (text + image)
-	t.Run("MultimodalRequest", func(t *testing.T) {
-		// Small 1x1 PNG red pixel
-		imageBase64 := "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8DwHwAFBQIAX8jx0gAAAABJRU5ErkJggg=="
+	resp, err := nethttp.Post(ts.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Streaming request failed: %v", err)
+	}
+	defer resp.Body.Close()
 
-		req := http.ChatCompletionRequest{
-			Messages: []tokenizer.ChatMessage{
-				{
-					Role:    "user",
-					Content: "What color is this image?",
-					Image:   imageBase64,
-				},
-			},
-			MaxTokens: 10,
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	var chunks []httpserver.ChatCompletionChunk
+	sawDone := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+		if payload == "[DONE]" {
+			sawDone = true
+			break
+		}
+		var chunk httpserver.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			t.Fatalf("Bad chunk %q: %v", payload, err)
 		}
+		chunks = append(chunks, chunk)
+	}
 
-		response := sendChatCompletion(t, addr, req)
+	if !sawDone {
+		t.Error("Stream did not terminate with [DONE]")
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Got %d chunks, want at least a delta and a finish chunk", len(chunks))
+	}
+	if chunks[0].Choices[0].Delta.Role != "assistant" {
+		t.Errorf("First chunk role = %q, want assistant", chunks[0].Choices[0].Delta.Role)
+	}
+	last := chunks[len(chunks)-1]
+	if last.Choices[0].FinishReason != "stop" {
+		t.Errorf("Last chunk finish_reason = %q, want stop", last.Choices[0].FinishReason)
+	}
+}
 
-		if len(response.Choices) == 0 {
-			t.Fatal("Expected at least one choice")
-		}
+// TestStreamCancellationSim verifies a client dropping mid-stream does
+// not wedge the server
+func TestStreamCancellationSim(t *testing.T) {
+	ts, _, _ := newSimStack(t, radix.SimConfig{VocabSize: 32000, Seed: 1})
 
-		t.Logf("Multimodal response: %s", response.Choices[0].Message.Content)
+	body, _ := json.Marshal(httpserver.ChatCompletionRequest{
+		Messages: []tokenizer.ChatMessage{
+			{Role: "user", Content: "A fairly long prompt so the stream has several chunks to emit"},
+		},
+		MaxTokens: 20,
+		Stream:    true,
 	})
 
-	// Test 5: LRU eviction
-	t.Run("LRUEviction", func(t *testing.T) {
-		// Generate many requests to fill LRU
-		const numRequests = 100
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost,
+		ts.URL+"/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
 
-		for i := 0; i < numRequests; i++ {
-			req := http.ChatCompletionRequest{
-				Messages: []tokenizer.ChatMessage{
-					{Role: "user", Content: fmt.Sprintf("Unique message %d", i)},
-				},
-				MaxTokens: 2,
-			}
-
-			sendChatCompletion(t, addr, req)
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Streaming request failed: %v", err)
+	}
+
+	// Read one chunk, then abandon the connection
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data: ") {
+			break
 		}
+	}
+	cancel()
+	resp.Body.Close()
 
-		// Check that tree hasn't grown unbounded
-		// (LRU should have evicted old entries)
-		t.Logf("Tree LRU size: (would check actual size here)")
-	})
-}
+	// The server must remain healthy and keep serving requests
+	health, err := nethttp.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("Health check failed after cancellation: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != nethttp.StatusOK {
+		t.Errorf("Health status = %d after cancellation", health.StatusCode)
+	}
 
-// TestRadixTreeBehavior tests Radix tree specific behaviors
-func TestRadixTreeBehavior(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
+	followUp := httpserver.ChatCompletionRequest{
+		Messages:  []tokenizer.ChatMessage{{Role: "user", Content: "Still there?"}},
+		MaxTokens: 3,
 	}
+	sendChatCompletion(t, ts, followUp)
+}
 
-	t.Skip("Requires MLX runtime")
+// TestEvictionSim verifies evicted prefixes are recomputed on reuse
+func TestEvictionSim(t *testing.T) {
+	ts, tree, engine := newSimStack(t, radix.SimConfig{VocabSize: 32000, Seed: 1})
 
-	tree := radix.NewTree()
-	engine := setupMLXEngine(t)
-	tok := tokenizer.NewTokenizer(32000)
-	model := loadModel(t)
+	req := httpserver.ChatCompletionRequest{
+		Messages:  []tokenizer.ChatMessage{{Role: "user", Content: "Evict me"}},
+		MaxTokens: 5,
+	}
+	sendChatCompletion(t, ts, req)
+	waitForForwards(t, engine, 1)
+	// Forwards are counted just before the finalizer unpins; give it a
+	// moment to land in the LRU list
+	time.Sleep(50 * time.Millisecond)
+
+	// Evict the finalized prefix
+	tree.EvictLRU(1)
+
+	// Re-sending the same request now requires a fresh forward pass
+	before := engine.Forwards()
+	sendChatCompletion(t, ts, req)
+	waitForForwards(t, engine, before+1)
+}
+
+// TestRadixTreeBehaviorSim exercises tree behaviors against the sim engine
+func TestRadixTreeBehaviorSim(t *testing.T) {
+	engine := radix.NewSimEngine(radix.SimConfig{VocabSize: 1000, Seed: 1})
+	model := "test-model"
 
 	t.Run("PrefixMatch", func(t *testing.T) {
-		// Insert [1,2,3,4,5]
-		node1, _ := tree.InsertPending([]uint32{1, 2, 3, 4, 5}, engine, model)
-		radix.FinalizeNode(node1, 100)
+		tree := radix.NewTree()
+
+		node, err := tree.InsertPending([]uint32{1, 2, 3, 4, 5}, engine, model)
+		if err != nil {
+			t.Fatalf("InsertPending failed: %v", err)
+		}
+		_, handle, err := engine.ForwardWithCache(model, node.Tokens, radix.RootCacheHandle)
+		if err != nil {
+			t.Fatalf("Forward failed: %v", err)
+		}
+		radix.FinalizeNode(node, handle)
 
-		// Query [1,2,3] should match
 		match := tree.Match([]uint32{1, 2, 3})
 		if match == nil {
-			t.Error("Expected match for prefix")
+			t.Fatal("Expected match for prefix")
 		}
-
-		if match.CacheHandle != 100 {
-			t.Errorf("Expected cache handle 100, got %d", match.CacheHandle)
+		if match.CacheHandle != handle {
+			t.Errorf("CacheHandle = %d, want %d", match.CacheHandle, handle)
 		}
 	})
 
 	t.Run("ThunderingHerd", func(t *testing.T) {
-		// Many concurrent requests for same tokens
+		tree := radix.NewTree()
 		const numGoroutines = 10
-		nodes := make(chan *radix.Node, numGoroutines)
 
+		nodes := make(chan *radix.Node, numGoroutines)
 		for i := 0; i < numGoroutines; i++ {
 			go func() {
 				node, _ := tree.InsertPending([]uint32{99, 98, 97}, engine, model)
@@ -239,200 +331,46 @@ func TestRadixTreeBehavior(t *testing.T) {
 			}()
 		}
 
-		// Collect nodes
-		var firstNode *radix.Node
-		uniqueNodes := make(map[*radix.Node]bool)
-
+		unique := make(map[*radix.Node]bool)
 		for i := 0; i < numGoroutines; i++ {
-			node := <-nodes
-			if firstNode == nil {
-				firstNode = node
-			}
-			uniqueNodes[node] = true
+			unique[<-nodes] = true
 		}
 
-		// All should get same node (thundering herd prevention)
-		if len(uniqueNodes) != 1 {
-			t.Errorf("Expected 1 unique node, got %d", len(uniqueNodes))
-		}
-
-		// refCount should be number of goroutines
-		if firstNode.refCount.Load() != numGoroutines {
-			t.Errorf("Expected refCount %d, got %d", numGoroutines, firstNode.refCount.Load())
+		// All callers should share one pending node
+		if len(unique) != 1 {
+			t.Errorf("Expected 1 unique node, got %d", len(unique))
 		}
 	})
 
 	t.Run("PoisonedNodeRetry", func(t *testing.T) {
-		// Create and poison node
+		tree := radix.NewTree()
+
 		node1, _ := tree.InsertPending([]uint32{77, 88}, engine, model)
 		radix.PoisonNode(node1, fmt.Errorf("test error"))
 
-		// Try again - should create new node
 		node2, _ := tree.InsertPending([]uint32{77, 88}, engine, model)
-
 		if node1 == node2 {
 			t.Error("Expected different node after poison")
 		}
-
-		if node2.err != nil {
-			t.Errorf("New node should not be poisoned: %v", node2.err)
+		if node2.IsReady() {
+			t.Error("Replacement node should start pending")
 		}
 	})
-}
-
-// TestMemoryManagement tests memory-related behaviors
-func TestMemoryManagement(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
-
-	t.Skip("Requires MLX runtime")
-
-	tree := radix.NewTree()
-	engine := setupMLXEngine(t)
-	model := loadModel(t)
 
 	t.Run("UnpinAndEvict", func(t *testing.T) {
-		// Create node
+		tree := radix.NewTree()
+
 		node, _ := tree.InsertPending([]uint32{1, 2, 3}, engine, model)
 		radix.FinalizeNode(node, 100)
-
-		// Unpin - should add to LRU
 		tree.Unpin(node)
 
-		if node.lruElem == nil {
-			t.Error("Expected node to be in LRU")
+		if tree.Match([]uint32{1, 2, 3}) == nil {
+			t.Fatal("Node should be matchable before eviction")
 		}
 
-		// Evict - should remove from tree
 		tree.EvictLRU(1)
-
-		// Verify node removed
-		// (Would need to expose tree internals or add Contains method)
-	})
-
-	t.Run("CascadingCleanup", func(t *testing.T) {
-		// Create tree: root -> [1] -> [2] -> [3]
-		n1, _ := tree.InsertPending([]uint32{1}, engine, model)
-		radix.FinalizeNode(n1, 100)
-
-		n2, _ := tree.InsertPending([]uint32{1, 2}, engine, model)
-		radix.FinalizeNode(n2, 200)
-
-		n3, _ := tree.InsertPending([]uint32{1, 2, 3}, engine, model)
-		radix.FinalizeNode(n3, 300)
-
-		// Poison middle node
-		radix.PoisonNode(n2, fmt.Errorf("error"))
-
-		// Prune should remove n2 and n3
-		tree.PrunePoisoned()
-
-		// Verify cleanup
-		// (Would need to expose tree internals)
+		if tree.Match([]uint32{1, 2, 3}) != nil {
+			t.Error("Node should be gone after eviction")
+		}
 	})
 }
-
-// Helper functions
-
-func setupMLXEngine(t *testing.T) radix.MLXEngine {
-	// In production, this would load the actual MLX engine
-	// For now, use mock
-	return &radix.MockMLXEngine{
-		ForwardFunc: func(model any, tokens []uint32, base uint64) ([]float32, uint64, error) {
-			return make([]float32, 32000), base + 1, nil
-		},
-	}
-}
-
-func loadModel(t *testing.T) any {
-	// In production, this would load actual model weights
-	return "test-model"
-}
-
-func startTestServer(server *http.Server) string {
-	mux := http.NewServeMux()
-	server.RegisterRoutes(mux)
-
-	hs := &http.Server{
-		Addr:    ":0", // Random available port
-		Handler: mux,
-	}
-
-	go func() {
-		hs.ListenAndServe()
-	}()
-
-	// Wait for server to start
-	time.Sleep(100 * time.Millisecond)
-
-	return hs.Addr
-}
-
-func stopTestServer(addr string) {
-	// Shutdown server
-	// (Implementation depends on actual server setup)
-}
-
-func sendChatCompletion(t *testing.T, addr string, req http.ChatCompletionRequest) *http.ChatCompletionResponse {
-	body, _ := json.Marshal(req)
-	resp, err := http.Post("http://"+addr+"/v1/chat/completions", "application/json", bytes.NewReader(body))
-	if err != nil {
-		t.Fatalf("Failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
-	}
-
-	var response http.ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		t.Fatalf("Failed to decode response: %v", err)
-	}
-
-	return &response
-}
-
-func sendChatCompletionAsync(addr string, req http.ChatCompletionRequest) (*http.ChatCompletionResponse, error) {
-	body, _ := json.Marshal(req)
-	resp, err := http.Post("http://"+addr+"/v1/chat/completions", "application/json", bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status %d", resp.StatusCode)
-	}
-
-	var response http.ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
-}
-
-// TestMain runs setup/teardown for integration tests
-func TestMain(m *testing.M) {
-	// Check if integration tests should run
-	if os.Getenv("INTEGRATION_TEST") == "" {
-		fmt.Println("Skipping integration tests (set INTEGRATION_TEST=1 to run)")
-		os.Exit(0)
-	}
-
-	// Setup MLX environment
-	// - Load model
-	// - Initialize runtime
-	// - Set up test data
-
-	// Run tests
-	code := m.Run()
-
-	// Teardown
-	// - Unload model
-	// - Clean up test data
-
-	os.Exit(code)
-}